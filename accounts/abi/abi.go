@@ -234,6 +234,24 @@ func (abi *ABI) ErrorByID(sigdata [4]byte) (*Error, error) {
 	return nil, fmt.Errorf("no error with id: %#x", sigdata[:])
 }
 
+// UnpackError decodes the revert data of a call into the custom error of the ABI
+// whose selector it starts with. The Error(string) and Panic(uint256) errors raised
+// by the compiler are decoded by UnpackRevert instead.
+func (abi *ABI) UnpackError(data []byte) (*CustomError, error) {
+	if len(data) < 4 {
+		return nil, errors.New("invalid data for unpacking")
+	}
+	def, err := abi.ErrorByID([4]byte(data[:4]))
+	if err != nil {
+		return nil, err
+	}
+	values, err := def.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	return &CustomError{Def: def, Values: values}, nil
+}
+
 // HasFallback returns an indicator whether a fallback function is included.
 func (abi *ABI) HasFallback() bool {
 	return abi.Fallback.Type == Fallback
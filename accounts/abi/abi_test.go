@@ -1104,6 +1104,56 @@ func TestABI_ErrorByID(t *testing.T) {
 	}
 }
 
+func TestUnpackError(t *testing.T) {
+	t.Parallel()
+	abi, err := JSON(strings.NewReader(`[
+		{"inputs":[{"internalType":"uint256","name":"available","type":"uint256"},{"internalType":"uint256","name":"required","type":"uint256"}],"name":"InsufficientBalance","type":"error"},
+		{"inputs":[{"internalType":"address","name":"","type":"address"}],"name":"Unauthorized","type":"error"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	insufficient, unauthorized := abi.Errors["InsufficientBalance"], abi.Errors["Unauthorized"]
+	data, err := insufficient.Inputs.Pack(big.NewInt(1), big.NewInt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	custom, err := abi.UnpackError(append(insufficient.ID[:4:4], data...))
+	if err != nil {
+		t.Fatalf("failed to unpack error: %v", err)
+	}
+	if want := "InsufficientBalance(available: 1, required: 2)"; custom.Error() != want {
+		t.Errorf("error mismatch: have %q, want %q", custom.Error(), want)
+	}
+	var fields struct {
+		Available *big.Int
+		Required  *big.Int
+	}
+	if err := custom.Copy(&fields); err != nil {
+		t.Fatalf("failed to copy error values: %v", err)
+	}
+	if fields.Available.Int64() != 1 || fields.Required.Int64() != 2 {
+		t.Errorf("values mismatch: have %v, want {1, 2}", fields)
+	}
+	// Unnamed parameters are named by position
+	data, _ = unauthorized.Inputs.Pack(common.Address{1})
+	custom, err = abi.UnpackError(append(unauthorized.ID[:4:4], data...))
+	if err != nil {
+		t.Fatalf("failed to unpack error: %v", err)
+	}
+	var account struct{ Arg0 common.Address }
+	if err := custom.Copy(&account); err != nil || account.Arg0 != (common.Address{1}) {
+		t.Errorf("values mismatch: have %v, want %v: %v", account.Arg0, common.Address{1}, err)
+	}
+	// Unknown selectors and revert reasons are not custom errors
+	if _, err := abi.UnpackError(common.FromHex("08c379a0")); err == nil {
+		t.Error("unpacked revert reason as a custom error")
+	}
+	if _, err := abi.UnpackError([]byte{1, 2}); err == nil {
+		t.Error("unpacked truncated data")
+	}
+}
+
 // TestDoubleDuplicateMethodNames checks that if transfer0 already exists, there won't be a name
 // conflict and that the second transfer method will be renamed transfer1.
 func TestDoubleDuplicateMethodNames(t *testing.T) {
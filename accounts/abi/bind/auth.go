@@ -163,6 +163,32 @@ func NewKeyedTransactorWithChainID(key *ecdsa.PrivateKey, chainID *big.Int) (*Tr
 	}, nil
 }
 
+// NewWalletTransactor is a utility method to easily create a transaction signer
+// from an account of a wallet. Wallets implementing accounts.ContextSigner, such
+// as remote MPC signers, are bounded by the context of the transaction, which
+// cancels pending signing requests when done.
+func NewWalletTransactor(wallet accounts.Wallet, account accounts.Account, chainID *big.Int) (*TransactOpts, error) {
+	if chainID == nil {
+		return nil, ErrNoChainID
+	}
+	return &TransactOpts{
+		From: account.Address,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != account.Address {
+				return nil, ErrNotAuthorized
+			}
+			return wallet.SignTx(account, tx, chainID)
+		},
+		ContextSigner: func(ctx context.Context, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != account.Address {
+				return nil, ErrNotAuthorized
+			}
+			return accounts.SignTxContext(ctx, wallet, account, tx, chainID)
+		},
+		Context: context.Background(),
+	}, nil
+}
+
 // NewClefTransactor is a utility method to easily create a transaction signer
 // with a clef backend.
 func NewClefTransactor(clef *external.ExternalSigner, account accounts.Account) *TransactOpts {
@@ -177,3 +203,21 @@ func NewClefTransactor(clef *external.ExternalSigner, account accounts.Account)
 		Context: context.Background(),
 	}
 }
+
+// NewWatchOnlyTransactor creates transaction options for an account without a
+// local key, such as a watch-only account. Transactions are assembled, with the
+// nonce and gas of the account, but returned unsigned and not sent, so that they
+// can be signed externally and sent with SendTransaction.
+func NewWatchOnlyTransactor(from common.Address) *TransactOpts {
+	return &TransactOpts{
+		From: from,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != from {
+				return nil, ErrNotAuthorized
+			}
+			return tx, nil
+		},
+		Context: context.Background(),
+		NoSend:  true,
+	}
+}
@@ -40,6 +40,10 @@ var (
 	// on a backend that doesn't implement BlockHashContractCaller.
 	ErrNoBlockHashState = errors.New("backend does not support block hash state")
 
+	// ErrNoOverrides is raised when attempting to perform a call with state or block
+	// overrides on a backend that doesn't implement OverrideContractCaller.
+	ErrNoOverrides = errors.New("backend does not support call overrides")
+
 	// ErrNoCodeAfterDeploy is returned by WaitDeployed if contract creation leaves
 	// an empty contract behind.
 	ErrNoCodeAfterDeploy = errors.New("no contract code after deployment")
@@ -79,6 +83,16 @@ type BlockHashContractCaller interface {
 	CallContractAtHash(ctx context.Context, call ethereum.CallMsg, blockHash common.Hash) ([]byte, error)
 }
 
+// OverrideContractCaller defines methods to perform contract calls against an
+// overridden state and block context. Call will try to discover this interface when
+// overrides are requested. If the backend does not support them, Call returns
+// ErrNoOverrides.
+type OverrideContractCaller interface {
+	// CallContractWithOverrides executes an Ethereum contract call against the state
+	// at the given block, after applying the state and block overrides.
+	CallContractWithOverrides(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int, state map[common.Address]ethereum.OverrideAccount, block *ethereum.BlockOverrides) ([]byte, error)
+}
+
 // ContractTransactor defines the methods needed to allow operating with a contract
 // on a write only basis. Besides the transacting method, the remainder are helpers
 // used when the user does not provide some needed values, but rather leaves it up
@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
@@ -43,6 +44,10 @@ var (
 // sign the transaction before submission.
 type SignerFn func(common.Address, *types.Transaction) (*types.Transaction, error)
 
+// ContextSignerFn is a signer function callback bounded by the context of the
+// transaction, for signers which may take long to authorize it.
+type ContextSignerFn func(context.Context, common.Address, *types.Transaction) (*types.Transaction, error)
+
 // CallOpts is the collection of options to fine tune a contract call request.
 type CallOpts struct {
 	Pending     bool            // Whether to operate on the pending state or the last known one
@@ -50,6 +55,9 @@ type CallOpts struct {
 	BlockNumber *big.Int        // Optional the block number on which the call should be performed
 	BlockHash   common.Hash     // Optional the block hash on which the call should be performed
 	Context     context.Context // Network context to support cancellation and timeouts (nil = no timeout)
+
+	StateOverrides map[common.Address]ethereum.OverrideAccount // Optional accounts overridden for the call
+	BlockOverrides *ethereum.BlockOverrides                    // Optional block fields overridden for the call
 }
 
 // TransactOpts is the collection of authorization data required to create a
@@ -57,7 +65,11 @@ type CallOpts struct {
 type TransactOpts struct {
 	From   common.Address // Ethereum account to send the transaction from
 	Nonce  *big.Int       // Nonce to use for the transaction execution (nil = use pending state)
-	Signer SignerFn       // Method to use for signing the transaction (mandatory)
+	Signer SignerFn       // Method to use for signing the transaction (mandatory unless ContextSigner is set)
+
+	// ContextSigner is the method to use for signing the transaction, bounded by
+	// Context. It takes precedence over Signer if set.
+	ContextSigner ContextSignerFn
 
 	Value     *big.Int // Funds to transfer along the transaction (nil = 0 = no funds)
 	GasPrice  *big.Int // Gas price to use for the transaction execution (nil = gas price oracle)
@@ -167,68 +179,146 @@ func (c *BoundContract) Call(opts *CallOpts, results *[]interface{}, method stri
 	if err != nil {
 		return err
 	}
+	output, err := c.CallRaw(opts, input)
+	if err != nil {
+		return err
+	}
+	if len(*results) == 0 {
+		res, err := c.abi.Unpack(method, output)
+		*results = res
+		return err
+	}
+	res := *results
+	return c.abi.UnpackIntoInterface(res[0], method, output)
+}
+
+// CallRaw invokes the (constant) contract with the given raw calldata as the
+// input, returning the raw output.
+func (c *BoundContract) CallRaw(opts *CallOpts, input []byte) ([]byte, error) {
+	// Don't crash on a lazy user
+	if opts == nil {
+		opts = new(CallOpts)
+	}
 	var (
 		msg    = ethereum.CallMsg{From: opts.From, To: &c.address, Data: input}
 		ctx    = ensureContext(opts.Context)
 		code   []byte
 		output []byte
+		err    error
 	)
-	if opts.Pending {
+	if opts.StateOverrides != nil || opts.BlockOverrides != nil {
+		oc, ok := c.caller.(OverrideContractCaller)
+		if !ok {
+			return nil, ErrNoOverrides
+		}
+		if opts.BlockHash != (common.Hash{}) {
+			return nil, errors.New("call overrides are not supported on block hash state")
+		}
+		blockNumber := opts.BlockNumber
+		if opts.Pending {
+			blockNumber = big.NewInt(-1) // rpc.PendingBlockNumber
+		}
+		output, err = oc.CallContractWithOverrides(ctx, msg, blockNumber, opts.StateOverrides, opts.BlockOverrides)
+		if err != nil {
+			return nil, c.unpackError(err)
+		}
+		if len(output) == 0 {
+			// Make sure we have a contract to operate on, unless its code is overridden.
+			if override, ok := opts.StateOverrides[c.address]; ok && override.Code != nil {
+				if len(override.Code) == 0 {
+					return nil, ErrNoCode
+				}
+			} else if code, err = c.caller.CodeAt(ctx, c.address, blockNumber); err != nil {
+				return nil, err
+			} else if len(code) == 0 {
+				return nil, ErrNoCode
+			}
+		}
+	} else if opts.Pending {
 		pb, ok := c.caller.(PendingContractCaller)
 		if !ok {
-			return ErrNoPendingState
+			return nil, ErrNoPendingState
 		}
 		output, err = pb.PendingCallContract(ctx, msg)
 		if err != nil {
-			return err
+			return nil, c.unpackError(err)
 		}
 		if len(output) == 0 {
 			// Make sure we have a contract to operate on, and bail out otherwise.
 			if code, err = pb.PendingCodeAt(ctx, c.address); err != nil {
-				return err
+				return nil, err
 			} else if len(code) == 0 {
-				return ErrNoCode
+				return nil, ErrNoCode
 			}
 		}
 	} else if opts.BlockHash != (common.Hash{}) {
 		bh, ok := c.caller.(BlockHashContractCaller)
 		if !ok {
-			return ErrNoBlockHashState
+			return nil, ErrNoBlockHashState
 		}
 		output, err = bh.CallContractAtHash(ctx, msg, opts.BlockHash)
 		if err != nil {
-			return err
+			return nil, c.unpackError(err)
 		}
 		if len(output) == 0 {
 			// Make sure we have a contract to operate on, and bail out otherwise.
 			if code, err = bh.CodeAtHash(ctx, c.address, opts.BlockHash); err != nil {
-				return err
+				return nil, err
 			} else if len(code) == 0 {
-				return ErrNoCode
+				return nil, ErrNoCode
 			}
 		}
 	} else {
 		output, err = c.caller.CallContract(ctx, msg, opts.BlockNumber)
 		if err != nil {
-			return err
+			return nil, c.unpackError(err)
 		}
 		if len(output) == 0 {
 			// Make sure we have a contract to operate on, and bail out otherwise.
 			if code, err = c.caller.CodeAt(ctx, c.address, opts.BlockNumber); err != nil {
-				return err
+				return nil, err
 			} else if len(code) == 0 {
-				return ErrNoCode
+				return nil, ErrNoCode
 			}
 		}
 	}
+	return output, nil
+}
 
-	if len(*results) == 0 {
-		res, err := c.abi.Unpack(method, output)
-		*results = res
+// unpackError decodes the revert data of a failed call into a custom error of the
+// contract ABI. Other errors are returned as is.
+func (c *BoundContract) unpackError(err error) error {
+	var derr interface{ ErrorData() interface{} }
+	if !errors.As(err, &derr) {
 		return err
 	}
-	res := *results
-	return c.abi.UnpackIntoInterface(res[0], method, output)
+	var data []byte
+	switch revert := derr.ErrorData().(type) {
+	case string:
+		data, _ = hexutil.Decode(revert)
+	case []byte:
+		data = revert
+	}
+	custom, uerr := c.abi.UnpackError(data)
+	if uerr != nil {
+		return err
+	}
+	return &revertError{err: err, custom: custom}
+}
+
+// revertError is a revert of a contract call with a custom error of the contract
+// ABI. It wraps both the error returned by the backend and the decoded error.
+type revertError struct {
+	err    error
+	custom *abi.CustomError
+}
+
+func (e *revertError) Error() string {
+	return "execution reverted: " + e.custom.Error()
+}
+
+func (e *revertError) Unwrap() []error {
+	return []error{e.err, e.custom}
 }
 
 // Transact invokes the (paid) contract method with params as input values.
@@ -372,7 +462,11 @@ func (c *BoundContract) estimateGasLimit(opts *TransactOpts, contract *common.Ad
 		Value:     value,
 		Data:      input,
 	}
-	return c.transactor.EstimateGas(ensureContext(opts.Context), msg)
+	gas, err := c.transactor.EstimateGas(ensureContext(opts.Context), msg)
+	if err != nil {
+		return 0, c.unpackError(err)
+	}
+	return gas, nil
 }
 
 func (c *BoundContract) getNonce(opts *TransactOpts) (uint64, error) {
@@ -413,10 +507,15 @@ func (c *BoundContract) transact(opts *TransactOpts, contract *common.Address, i
 		return nil, err
 	}
 	// Sign the transaction and schedule it for execution
-	if opts.Signer == nil {
+	var signedTx *types.Transaction
+	switch {
+	case opts.ContextSigner != nil:
+		signedTx, err = opts.ContextSigner(ensureContext(opts.Context), opts.From, rawTx)
+	case opts.Signer != nil:
+		signedTx, err = opts.Signer(opts.From, rawTx)
+	default:
 		return nil, errors.New("no signer to authorize the transaction with")
 	}
-	signedTx, err := opts.Signer(opts.From, rawTx)
 	if err != nil {
 		return nil, err
 	}
@@ -506,6 +605,105 @@ func (c *BoundContract) WatchLogs(opts *WatchOpts, name string, query ...[]inter
 	return logs, sub, nil
 }
 
+// logKey identifies a log within the chain.
+type logKey struct {
+	block common.Hash
+	index uint
+}
+
+// FilterAndWatchLogs delivers the past contract logs from the start block, then
+// continues with the new ones as they arrive. The subscription is opened before
+// the past logs are filtered so that no log is missed in between, and the new logs
+// already delivered by the filter are dropped. Logs removed by reorgs are delivered
+// again with their Removed flag set.
+func (c *BoundContract) FilterAndWatchLogs(opts *WatchOpts, name string, query ...[]interface{}) (chan types.Log, event.Subscription, error) {
+	// Don't crash on a lazy user
+	if opts == nil {
+		opts = new(WatchOpts)
+	}
+	if opts.Start == nil {
+		return c.WatchLogs(opts, name, query...)
+	}
+	// Append the event selector to the query parameters and construct the topic set
+	query = append([][]interface{}{{c.abi.Events[name].ID}}, query...)
+
+	topics, err := abi.MakeTopics(query...)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Subscribe to the new logs, then filter the past ones
+	var (
+		ctx    = ensureContext(opts.Context)
+		config = ethereum.FilterQuery{
+			Addresses: []common.Address{c.address},
+			Topics:    topics,
+		}
+		live = make(chan types.Log, 128)
+	)
+	sub, err := c.filterer.SubscribeFilterLogs(ctx, config, live)
+	if err != nil {
+		return nil, nil, err
+	}
+	config.FromBlock = new(big.Int).SetUint64(*opts.Start)
+	past, err := c.filterer.FilterLogs(ctx, config)
+	if err != nil {
+		sub.Unsubscribe()
+		return nil, nil, err
+	}
+	logs := make(chan types.Log, 128)
+	return logs, event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+
+		// Deliver the past logs, remembering them to drop the duplicates
+		var (
+			delivered = make(map[logKey]struct{}, len(past))
+			last      uint64 // highest block of the past logs
+		)
+		for _, log := range past {
+			delivered[logKey{log.BlockHash, log.Index}] = struct{}{}
+			last = max(last, log.BlockNumber)
+			select {
+			case logs <- log:
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+		for {
+			select {
+			case log := <-live:
+				// The logs of the blocks imported while filtering are delivered by
+				// both the filter and the subscription. A block is only imported
+				// again after being reorged out, which removes its logs first.
+				// Once the subscription delivers newer blocks, it has caught up with
+				// the filter and there are no duplicates left to drop.
+				if !log.Removed && log.BlockNumber > last {
+					delivered = nil
+				}
+				key := logKey{log.BlockHash, log.Index}
+				if _, ok := delivered[key]; ok {
+					if !log.Removed {
+						continue
+					}
+					delete(delivered, key)
+				}
+				select {
+				case logs <- log:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
 // UnpackLog unpacks a retrieved log into the provided output structure.
 func (c *BoundContract) UnpackLog(out interface{}, event string, log types.Log) error {
 	// Anonymous events are not supported.
@@ -23,6 +23,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -31,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/stretchr/testify/assert"
 )
@@ -374,6 +376,38 @@ func TestTransactGasFee(t *testing.T) {
 	assert.True(mt.suggestGasPriceCalled)
 }
 
+func TestTransactContextSigner(t *testing.T) {
+	t.Parallel()
+
+	mt := &mockTransactor{gasPrice: big.NewInt(5)}
+	bc := bind.NewBoundContract(common.Address{}, abi.ABI{}, nil, mt, nil)
+
+	// The context signer takes precedence and is bounded by the context
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "opts")
+	opts := &bind.TransactOpts{
+		Signer: func(common.Address, *types.Transaction) (*types.Transaction, error) {
+			return nil, errors.New("context-free signer used")
+		},
+		ContextSigner: func(ctx context.Context, addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if ctx.Value(ctxKey{}) != "opts" {
+				return nil, errors.New("wrong signing context")
+			}
+			return tx, ctx.Err()
+		},
+		Context: ctx,
+	}
+	if _, err := bc.Transact(opts, ""); err != nil {
+		t.Fatalf("transact failed: %v", err)
+	}
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	opts.Context = cctx
+	if _, err := bc.Transact(opts, ""); !errors.Is(err, context.Canceled) {
+		t.Fatalf("wrong error for cancelled signing: %v", err)
+	}
+}
+
 func unpackAndCheck(t *testing.T, bc *bind.BoundContract, expected map[string]interface{}, mockLog types.Log) {
 	received := make(map[string]interface{})
 	if err := bc.UnpackLogIntoMap(received, "received", mockLog); err != nil {
@@ -579,6 +613,62 @@ func TestCall(t *testing.T) {
 	}
 }
 
+type mockFilterer struct {
+	past []types.Log
+	live event.Feed
+}
+
+func (mf *mockFilterer) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return mf.past, nil
+}
+
+func (mf *mockFilterer) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return mf.live.Subscribe(ch), nil
+}
+
+func TestFilterAndWatchLogsReorg(t *testing.T) {
+	t.Parallel()
+	var (
+		id    = crypto.Keccak256Hash([]byte("Ping()"))
+		logA  = types.Log{Topics: []common.Hash{id}, BlockNumber: 1, BlockHash: common.Hash{1}, Index: 0}
+		logB  = types.Log{Topics: []common.Hash{id}, BlockNumber: 2, BlockHash: common.Hash{2}, Index: 1}
+		logC  = types.Log{Topics: []common.Hash{id}, BlockNumber: 3, BlockHash: common.Hash{3}, Index: 2}
+		mf    = &mockFilterer{past: []types.Log{logA, logB}}
+		start = uint64(0)
+	)
+	parsed, _ := abi.JSON(strings.NewReader(`[{"anonymous":false,"inputs":[],"name":"Ping","type":"event"}]`))
+	bc := bind.NewBoundContract(common.Address{}, parsed, nil, nil, mf)
+
+	logs, sub, err := bc.FilterAndWatchLogs(&bind.WatchOpts{Start: &start}, "Ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	removedB := logB
+	removedB.Removed = true
+	mf.live.Send(logB)     // Block imported while filtering, dropped
+	mf.live.Send(logC)     // New block
+	mf.live.Send(removedB) // Block reorged out
+	mf.live.Send(logB)     // Block reorged in again
+
+	for i, want := range []types.Log{logA, logB, logC, removedB, logB} {
+		select {
+		case log := <-logs:
+			if !reflect.DeepEqual(log, want) {
+				t.Fatalf("log %d mismatch: have %+v, want %+v", i, log, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("log %d not delivered", i)
+		}
+	}
+	select {
+	case log := <-logs:
+		t.Fatalf("unexpected log %+v", log)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
 // TestCrashers contains some strings which previously caused the abi codec to crash.
 func TestCrashers(t *testing.T) {
 	t.Parallel()
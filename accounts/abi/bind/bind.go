@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"go/format"
 	"regexp"
+	"sort"
 	"strings"
 	"text/template"
 	"unicode"
@@ -82,6 +83,50 @@ func isKeyWord(arg string) bool {
 // enforces compile time type safety and naming convention as opposed to having to
 // manually maintain hard coded strings that break on runtime.
 func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]string, pkg string, lang Lang, libs map[string]string, aliases map[string]string) (string, error) {
+	data, err := bindData(types, abis, bytecodes, fsigs, pkg, lang, libs, aliases)
+	if err != nil {
+		return "", err
+	}
+	return render(tmplSource[lang], data, lang)
+}
+
+// BindFiles generates the same wrappers as Bind, split into one file per contract
+// and a types.go file declaring the structs shared by the contracts, so that whole
+// projects can be bound into a single package. It returns the source of each file
+// by file name.
+func BindFiles(types []string, abis []string, bytecodes []string, fsigs []map[string]string, pkg string, lang Lang, libs map[string]string, aliases map[string]string) (map[string]string, error) {
+	data, err := bindData(types, abis, bytecodes, fsigs, pkg, lang, libs, aliases)
+	if err != nil {
+		return nil, err
+	}
+	return renderFiles(tmplSource[lang], data, lang)
+}
+
+// BindV2 generates a Go wrapper around a contract ABI using generics. Unlike the
+// bindings of Bind, it doesn't interact with the contract itself: it packs the
+// inputs and unpacks the outputs of the methods and events into typed values,
+// to be used with the generic helpers of this package, such as Call, Transact,
+// FilterEvents and WatchEvents.
+func BindV2(types []string, abis []string, bytecodes []string, pkg string, libs map[string]string, aliases map[string]string) (string, error) {
+	data, err := bindData(types, abis, bytecodes, nil, pkg, LangGo, libs, aliases)
+	if err != nil {
+		return "", err
+	}
+	return render(tmplSourceGoV2, data, LangGo)
+}
+
+// BindV2Files generates the same wrappers as BindV2, split into files like
+// BindFiles.
+func BindV2Files(types []string, abis []string, bytecodes []string, pkg string, libs map[string]string, aliases map[string]string) (map[string]string, error) {
+	data, err := bindData(types, abis, bytecodes, nil, pkg, LangGo, libs, aliases)
+	if err != nil {
+		return nil, err
+	}
+	return renderFiles(tmplSourceGoV2, data, LangGo)
+}
+
+// bindData parses the contract ABIs into the data of the binding templates.
+func bindData(types []string, abis []string, bytecodes []string, fsigs []map[string]string, pkg string, lang Lang, libs map[string]string, aliases map[string]string) (*tmplData, error) {
 	var (
 		// contracts is the map of each individual contract requested binding
 		contracts = make(map[string]*tmplContract)
@@ -96,7 +141,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 		// Parse the actual ABI to generate the binding for
 		evmABI, err := abi.JSON(strings.NewReader(abis[i]))
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		// Strip any whitespace from the JSON ABI
 		strippedABI := strings.Map(func(r rune) rune {
@@ -108,11 +153,12 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 
 		// Extract the call and transact methods; events, struct definitions; and sort them alphabetically
 		var (
-			calls     = make(map[string]*tmplMethod)
-			transacts = make(map[string]*tmplMethod)
-			events    = make(map[string]*tmplEvent)
-			fallback  *tmplMethod
-			receive   *tmplMethod
+			calls      = make(map[string]*tmplMethod)
+			transacts  = make(map[string]*tmplMethod)
+			events     = make(map[string]*tmplEvent)
+			customErrs = make(map[string]*tmplError)
+			fallback   *tmplMethod
+			receive    *tmplMethod
 
 			// identifiers are used to detect duplicated identifiers of functions
 			// and events. For all calls, transacts and events, abigen will generate
@@ -121,6 +167,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			callIdentifiers     = make(map[string]bool)
 			transactIdentifiers = make(map[string]bool)
 			eventIdentifiers    = make(map[string]bool)
+			errorIdentifiers    = make(map[string]bool)
 		)
 
 		for _, input := range evmABI.Constructor.Inputs {
@@ -147,7 +194,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 				})
 			}
 			if identifiers[normalizedName] {
-				return "", fmt.Errorf("duplicated identifier \"%s\"(normalized \"%s\"), use --alias for renaming", original.Name, normalizedName)
+				return nil, fmt.Errorf("duplicated identifier \"%s\"(normalized \"%s\"), use --alias for renaming", original.Name, normalizedName)
 			}
 			identifiers[normalizedName] = true
 
@@ -198,7 +245,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 				})
 			}
 			if eventIdentifiers[normalizedName] {
-				return "", fmt.Errorf("duplicated identifier \"%s\"(normalized \"%s\"), use --alias for renaming", original.Name, normalizedName)
+				return nil, fmt.Errorf("duplicated identifier \"%s\"(normalized \"%s\"), use --alias for renaming", original.Name, normalizedName)
 			}
 			eventIdentifiers[normalizedName] = true
 			normalized.Name = normalizedName
@@ -226,6 +273,24 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			// Append the event to the accumulator list
 			events[original.Name] = &tmplEvent{Original: original, Normalized: normalized}
 		}
+		for _, original := range evmABI.Errors {
+			// Normalize the error name, the inputs are already named by the abi package
+			normalized := original
+
+			normalizedName := methodNormalizer[lang](alias(aliases, original.Name))
+			if errorIdentifiers[normalizedName] {
+				return nil, fmt.Errorf("duplicated identifier \"%s\"(normalized \"%s\"), use --alias for renaming", original.Name, normalizedName)
+			}
+			errorIdentifiers[normalizedName] = true
+			normalized.Name = normalizedName
+
+			for _, input := range original.Inputs {
+				if hasStruct(input.Type) {
+					bindStructType[lang](input.Type, structs)
+				}
+			}
+			customErrs[original.Name] = &tmplError{Original: original, Normalized: normalized}
+		}
 		// Add two special fallback functions if they exist
 		if evmABI.HasFallback() {
 			fallback = &tmplMethod{Original: evmABI.Fallback}
@@ -243,6 +308,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			Fallback:    fallback,
 			Receive:     receive,
 			Events:      events,
+			Errors:      customErrs,
 			Libraries:   make(map[string]string),
 		}
 		// Function 4-byte signatures are stored in the same sequence
@@ -270,23 +336,128 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 		_, ok := isLib[types[i]]
 		contracts[types[i]].Library = ok
 	}
-	// Generate the contract template data content and render it
-	data := &tmplData{
+	// Check whether the libraries of the contracts can be deployed along them
+	for _, contract := range contracts {
+		contract.AutoLink = deployable(contract, contracts, make(map[string]bool))
+		unknown := make(map[string]bool)
+		for _, match := range libraryPlaceholder.FindAllStringSubmatch(contract.InputBin, -1) {
+			if _, ok := contract.Libraries[match[1]]; !ok && !unknown[match[1]] {
+				log.Warn("Bytecode links unknown library", "contract", contract.Type, "placeholder", match[0])
+				unknown[match[1]] = true
+			}
+		}
+	}
+	resolveStructNames(structs, contracts, lang)
+
+	// Generate the contract template data content
+	return &tmplData{
 		Package:   pkg,
 		Contracts: contracts,
 		Libraries: libs,
 		Structs:   structs,
+	}, nil
+}
+
+// deployable reports whether the contract can be deployed by the bindings, i.e.
+// it has bytecode, and the libraries it links to are bound and deployable too.
+// The path of libraries being checked guards against cyclic links.
+func deployable(contract *tmplContract, contracts map[string]*tmplContract, path map[string]bool) bool {
+	if contract.InputBin == "" || path[contract.Type] {
+		return false
+	}
+	path[contract.Type] = true
+	defer delete(path, contract.Type)
+
+	for _, name := range contract.Libraries {
+		lib, ok := contracts[name]
+		if !ok || !deployable(lib, contracts, path) {
+			return false
+		}
 	}
+	return true
+}
+
+// resolveStructNames renames the structs whose names collide with another struct
+// or a contract, e.g. different structs of the same name declared in different
+// source files, and updates the field types referencing them.
+func resolveStructNames(structs map[string]*tmplStruct, contracts map[string]*tmplContract, lang Lang) {
+	used := make(map[string]bool)
+	for _, contract := range contracts {
+		used[contract.Type] = true
+	}
+	// Rename in a deterministic order, the first struct of a name keeps it
+	ids := make([]string, 0, len(structs))
+	for id := range structs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		s := structs[id]
+		s.Name = abi.ResolveNameConflict(s.Name, func(name string) bool { return used[name] })
+		used[s.Name] = true
+	}
+	for _, s := range structs {
+		for _, field := range s.Fields {
+			field.Type = bindType[lang](field.SolKind, structs)
+		}
+	}
+}
+
+// sharedTypesFile is the file of the structs shared by the contracts of the
+// bindings generated by BindFiles and BindV2Files.
+const sharedTypesFile = "types.go"
+
+// renderFiles renders the template of a binding into a file per contract, named
+// after its type, and a file declaring the structs of all the contracts.
+func renderFiles(source string, data *tmplData, lang Lang) (map[string]string, error) {
+	files := make(map[string]string)
+	if len(data.Structs) > 0 {
+		shared := *data
+		shared.Contracts = nil
+		code, err := render(source, &shared, lang)
+		if err != nil {
+			return nil, err
+		}
+		files[sharedTypesFile] = code
+	}
+	names := make([]string, 0, len(data.Contracts))
+	for name := range data.Contracts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		single := *data
+		single.Contracts = map[string]*tmplContract{name: data.Contracts[name]}
+		single.OmitStructs = true
+		code, err := render(source, &single, lang)
+		if err != nil {
+			return nil, err
+		}
+		file := abi.ResolveNameConflict(strings.ToLower(data.Contracts[name].Type), func(file string) bool {
+			_, exists := files[file+".go"]
+			return exists || file+".go" == sharedTypesFile
+		})
+		files[file+".go"] = code
+	}
+	return files, nil
+}
+
+// render renders the template of a binding.
+func render(source string, data *tmplData, lang Lang) (string, error) {
 	buffer := new(bytes.Buffer)
 
 	funcs := map[string]interface{}{
 		"bindtype":      bindType[lang],
 		"bindtopictype": bindTopicType[lang],
+		"convert":       convertTypeGo,
+		"outputfields":  outputFields,
+		"methods":       sortedMethods,
 		"namedtype":     namedType[lang],
 		"capitalise":    capitalise,
 		"decapitalise":  decapitalise,
 	}
-	tmpl := template.Must(template.New("").Funcs(funcs).Parse(tmplSource[lang]))
+	tmpl := template.Must(template.New("").Funcs(funcs).Parse(source))
 	if err := tmpl.Execute(buffer, data); err != nil {
 		return "", err
 	}
@@ -357,18 +528,59 @@ var bindTopicType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct)
 // bindTopicTypeGo converts a Solidity topic type to a Go one. It is almost the same
 // functionality as for simple types, but dynamic types get converted to hashes.
 func bindTopicTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
+	// According to the solidity documentation, indexed event parameters that are
+	// not value types, i.e. strings, bytes, arrays and structs, are not stored
+	// directly but instead a keccak256-hash of their encoding is stored.
+	switch kind.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return "common.Hash"
+	default:
+		return bindTypeGo(kind, structs)
+	}
+}
+
+// convertTypeGo returns the Go expression converting a value unpacked by the abi
+// package to its bound type. Values are asserted to their type, except structs
+// that the abi package unpacks into anonymous structs, which are converted.
+func convertTypeGo(expr string, kind abi.Type, structs map[string]*tmplStruct) string {
 	bound := bindTypeGo(kind, structs)
+	if hasStruct(kind) {
+		return fmt.Sprintf("*abi.ConvertType(%s, new(%s)).(*%s)", expr, bound, bound)
+	}
+	return fmt.Sprintf("%s.(%s)", expr, bound)
+}
 
-	// todo(rjl493456442) according solidity documentation, indexed event
-	// parameters that are not value types i.e. arrays and structs are not
-	// stored directly but instead a keccak256-hash of an encoding is stored.
-	//
-	// We only convert strings and bytes to hash, still need to deal with
-	// array(both fixed-size and dynamic-size) and struct.
-	if bound == "string" || bound == "[]byte" {
-		bound = "common.Hash"
+// outputFields returns the field names of the struct holding the outputs of a
+// method, naming anonymous outputs by their position.
+func outputFields(args abi.Arguments) []string {
+	var (
+		names = make([]string, len(args))
+		used  = make(map[string]bool)
+	)
+	for i, arg := range args {
+		name := capitalise(arg.Name)
+		if name == "" {
+			name = fmt.Sprintf("Arg%d", i)
+		}
+		name = abi.ResolveNameConflict(name, func(s string) bool { return used[s] })
+		used[name] = true
+		names[i] = name
+	}
+	return names
+}
+
+// sortedMethods merges sets of methods, sorting them by name.
+func sortedMethods(sets ...map[string]*tmplMethod) []*tmplMethod {
+	var methods []*tmplMethod
+	for _, set := range sets {
+		for _, method := range set {
+			methods = append(methods, method)
+		}
 	}
-	return bound
+	sort.Slice(methods, func(i, j int) bool {
+		return methods[i].Original.Name < methods[j].Original.Name
+	})
+	return methods
 }
 
 // bindStructType is a set of type binders that convert Solidity tuple types to some supported
@@ -21,6 +21,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 	"testing"
@@ -1414,6 +1415,24 @@ var bindTests = []struct {
 			if res.Cmp(big.NewInt(3)) != 0 {
 				t.Fatalf("Add did not return the correct result: %d != %d", res, 3)
 			}
+
+			// Deploy the contract again, linked to the already deployed library
+			mathAddr, _, _, err := DeployMath(auth, sim)
+			if err != nil {
+				t.Fatalf("Failed to deploy library: %v", err)
+			}
+			_, _, linkedContract, err := DeployUseLibraryWithLibraries(auth, sim, UseLibraryLibraries{Math: mathAddr})
+			if err != nil {
+				t.Fatalf("Failed to deploy linked contract: %v", err)
+			}
+			sim.Commit()
+
+			if res, err = linkedContract.Add(nil, big.NewInt(3), big.NewInt(4)); err != nil {
+				t.Fatalf("Failed to call linked contract: %v", err)
+			}
+			if res.Cmp(big.NewInt(7)) != 0 {
+				t.Fatalf("Add did not return the correct result: %d != %d", res, 7)
+			}
 		`,
 		nil,
 		map[string]string{
@@ -1902,11 +1921,23 @@ var bindTests = []struct {
 			if err != nil {
 				t.Error(err)
 			}
-			if err := contract.Error(new(bind.CallOpts)); err == nil {
+			err = contract.Error(new(bind.CallOpts))
+			if err == nil {
 				t.Fatalf("expected contract to throw error")
 			}
-			// TODO (MariusVanDerWijden unpack error using abigen
-			// once that is implemented
+			if want := "execution reverted: MyError3(a: 1, b: 2, c: 3)"; err.Error() != want {
+				t.Errorf("error mismatch: have %q, want %q", err, want)
+			}
+			myErr, ok := bind.UnpackError[NewErrorsMyError3Error](err)
+			if !ok {
+				t.Fatalf("failed to unpack custom error: %v", err)
+			}
+			if myErr.A.Uint64() != 1 || myErr.B.Uint64() != 2 || myErr.C.Uint64() != 3 {
+				t.Errorf("custom error mismatch: have %+v, want {1, 2, 3}", myErr)
+			}
+			if _, ok := bind.UnpackError[NewErrorsMyError2Error](err); ok {
+				t.Errorf("unpacked custom error of the wrong type")
+			}
 	   `,
 		nil,
 		nil,
@@ -2121,6 +2152,12 @@ func TestGolangBindings(t *testing.T) {
 			}
 		})
 	}
+	runBindTests(t, gocmd, pkg)
+}
+
+// runBindTests converts a package of generated bindings to a module using the
+// current source for go-ethereum, and runs its tests.
+func runBindTests(t *testing.T, gocmd string, pkg string) {
 	// Convert the package to go modules and use the current source for go-ethereum
 	moder := exec.Command(gocmd, "mod", "init", "bindtest")
 	moder.Dir = pkg
@@ -2145,3 +2182,439 @@ func TestGolangBindings(t *testing.T) {
 		t.Fatalf("failed to run binding test: %v\n%s", err, out)
 	}
 }
+
+// Tests that the v2 bindings of all the test contracts can be generated.
+func TestBindV2(t *testing.T) {
+	for _, tt := range bindTests {
+		types := tt.types
+		if types == nil {
+			types = []string{tt.name}
+		}
+		if _, err := BindV2(types, tt.abi, tt.bytecode, "bindtest", tt.libs, tt.aliases); err != nil {
+			t.Errorf("%s: failed to generate v2 binding: %v", tt.name, err)
+		}
+	}
+}
+
+// Tests that nested structs and arrays of structs are bound to Go types, and that
+// indexed event parameters of composite types are bound to the hash in their topic.
+func TestBindNestedTypes(t *testing.T) {
+	abi := `[{"type":"event","name":"Nested","anonymous":false,"inputs":[
+		{"name":"o","type":"tuple","indexed":true,"internalType":"struct C.Outer","components":[
+			{"name":"inner","type":"tuple","internalType":"struct C.Inner","components":[{"name":"a","type":"uint256"},{"name":"b","type":"bytes32[]"}]},
+			{"name":"fixed","type":"tuple[2]","internalType":"struct C.Inner[2]","components":[{"name":"a","type":"uint256"},{"name":"b","type":"bytes32[]"}]}
+		]},
+		{"name":"ids","type":"uint256[]","indexed":true},
+		{"name":"inners","type":"tuple[][]","indexed":false,"internalType":"struct C.Inner[][]","components":[{"name":"a","type":"uint256"},{"name":"b","type":"bytes32[]"}]}
+	]}]`
+	v1, err := Bind([]string{"C"}, []string{abi}, []string{""}, nil, "bindtest", LangGo, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to generate binding: %v", err)
+	}
+	v2, err := BindV2([]string{"C"}, []string{abi}, []string{""}, "bindtest", nil, nil)
+	if err != nil {
+		t.Fatalf("failed to generate v2 binding: %v", err)
+	}
+	want := []string{
+		`type CInner struct {\s+A\s+\*big.Int\s+B\s+\[\]\[32\]byte\s+}`,
+		`type COuter struct {\s+Inner\s+CInner\s+Fixed\s+\[2\]CInner\s+}`,
+		`type CNested struct {\s+O\s+common.Hash\s+Ids\s+common.Hash\s+Inners\s+\[\]\[\]CInner\s+Raw`,
+	}
+	for _, code := range []string{v1, v2} {
+		for _, re := range want {
+			if !regexp.MustCompile(re).MatchString(code) {
+				t.Errorf("binding doesn't match %q:\n%s", re, code)
+			}
+		}
+	}
+}
+
+// Tests that the bindings of several contracts can be split into files, declaring
+// the structs shared by the contracts once, and that colliding names are resolved.
+func TestBindFiles(t *testing.T) {
+	var (
+		point = `{"name":"p","type":"tuple","internalType":"struct Point","components":[{"name":"x","type":"uint256"},{"name":"y","type":"uint256"}]}`
+		other = `{"name":"p","type":"tuple","internalType":"struct Point","components":[{"name":"z","type":"int8"}]}`
+		types = []string{"Alpha", "Beta", "Point"}
+		abis  = []string{
+			`[{"type":"function","name":"get","stateMutability":"view","inputs":[],"outputs":[` + point + `]}]`,
+			`[{"type":"function","name":"get","stateMutability":"view","inputs":[` + point + `],"outputs":[` + other + `]}]`,
+			`[{"type":"function","name":"f","stateMutability":"view","inputs":[` + other + `],"outputs":[]}]`,
+		}
+		bins = []string{"", "", ""}
+	)
+	v1, err := BindFiles(types, abis, bins, nil, "bindtest", LangGo, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to generate bindings: %v", err)
+	}
+	v2, err := BindV2Files(types, abis, bins, "bindtest", nil, nil)
+	if err != nil {
+		t.Fatalf("failed to generate v2 bindings: %v", err)
+	}
+	decl := regexp.MustCompile(`(?m)^type (\w+) struct`)
+	for _, files := range []map[string]string{v1, v2} {
+		if len(files) != 4 {
+			t.Errorf("have %d files, want 4", len(files))
+		}
+		// The colliding structs are renamed, the contract keeps its name
+		structs := decl.FindAllStringSubmatch(files["types.go"], -1)
+		if len(structs) != 2 || structs[0][1] != "Point0" || structs[1][1] != "Point1" {
+			t.Errorf("wrong shared structs %v", structs)
+		}
+		for _, file := range []string{"alpha.go", "beta.go", "point.go"} {
+			code, ok := files[file]
+			if !ok {
+				t.Errorf("missing file %s", file)
+				continue
+			}
+			for _, match := range decl.FindAllStringSubmatch(code, -1) {
+				if match[1] == "Point0" || match[1] == "Point1" {
+					t.Errorf("%s redeclares struct %s", file, match[1])
+				}
+			}
+		}
+		if !strings.Contains(files["point.go"], "type Point struct") {
+			t.Error("contract type renamed")
+		}
+		if !strings.Contains(files["beta.go"], "Point0") || !strings.Contains(files["beta.go"], "Point1") {
+			t.Error("beta bindings don't use the shared structs")
+		}
+	}
+}
+
+// bindV2Tests are tests of the v2 bindings of some of the contracts of bindTests.
+var bindV2Tests = []struct {
+	name    string
+	imports string
+	tester  string
+}{
+	{
+		`Getter`,
+		`
+			"math/big"
+
+			"github.com/ethereum/go-ethereum/accounts/abi/bind"
+			"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+			"github.com/ethereum/go-ethereum/common"
+			"github.com/ethereum/go-ethereum/core/types"
+			"github.com/ethereum/go-ethereum/crypto"
+		`,
+		`
+			// Generate a new random account and a funded simulator
+			key, _ := crypto.GenerateKey()
+			auth, _ := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+
+			sim := backends.NewSimulatedBackend(types.GenesisAlloc{auth.From: {Balance: big.NewInt(10000000000000000)}}, 10000000)
+			defer sim.Close()
+
+			// Deploy a getter contract and call its method with anonymous outputs
+			getter := NewGetter()
+			input, err := getter.PackConstructor()
+			if err != nil {
+				t.Fatalf("Failed to pack constructor: %v", err)
+			}
+			addr, _, err := bind.Deploy(auth, sim, common.FromHex(GetterMetaData.Bin), input)
+			if err != nil {
+				t.Fatalf("Failed to deploy getter contract: %v", err)
+			}
+			sim.Commit()
+
+			input, err = getter.PackGetter()
+			if err != nil {
+				t.Fatalf("Failed to pack call: %v", err)
+			}
+			if res, err := bind.Call(getter.Instance(sim, addr), nil, input, getter.UnpackGetter); err != nil {
+				t.Fatalf("Failed to call anonymous field retriever: %v", err)
+			} else if res.Arg0 != "Hi" || res.Arg1.Cmp(big.NewInt(1)) != 0 {
+				t.Fatalf("Retrieved value mismatch: have %v/%v, want %v/%v", res.Arg0, res.Arg1, "Hi", 1)
+			}
+		`,
+	},
+	{
+		`Tupler`,
+		`
+			"math/big"
+
+			"github.com/ethereum/go-ethereum/accounts/abi/bind"
+			"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+			"github.com/ethereum/go-ethereum/common"
+			"github.com/ethereum/go-ethereum/core/types"
+			"github.com/ethereum/go-ethereum/crypto"
+		`,
+		`
+			// Generate a new random account and a funded simulator
+			key, _ := crypto.GenerateKey()
+			auth, _ := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+
+			sim := backends.NewSimulatedBackend(types.GenesisAlloc{auth.From: {Balance: big.NewInt(10000000000000000)}}, 10000000)
+			defer sim.Close()
+
+			// Deploy a tuple tester contract and execute a structured call on it
+			tupler := NewTupler()
+			addr, _, err := bind.Deploy(auth, sim, common.FromHex(TuplerMetaData.Bin), nil)
+			if err != nil {
+				t.Fatalf("Failed to deploy tupler contract: %v", err)
+			}
+			sim.Commit()
+
+			input, _ := tupler.PackTuple()
+			if res, err := bind.Call(tupler.Instance(sim, addr), nil, input, tupler.UnpackTuple); err != nil {
+				t.Fatalf("Failed to call structure retriever: %v", err)
+			} else if res.A != "Hi" || res.B.Cmp(big.NewInt(1)) != 0 {
+				t.Fatalf("Retrieved value mismatch: have %v/%v, want %v/%v", res.A, res.B, "Hi", 1)
+			}
+		`,
+	},
+	{
+		`Eventer`,
+		`
+			"math/big"
+			"time"
+
+			"github.com/ethereum/go-ethereum/accounts/abi/bind"
+			"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+			"github.com/ethereum/go-ethereum/common"
+			"github.com/ethereum/go-ethereum/core/types"
+			"github.com/ethereum/go-ethereum/crypto"
+		`,
+		`
+			// Generate a new random account and a funded simulator
+			key, _ := crypto.GenerateKey()
+			auth, _ := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+
+			sim := backends.NewSimulatedBackend(types.GenesisAlloc{auth.From: {Balance: big.NewInt(10000000000000000)}}, 10000000)
+			defer sim.Close()
+
+			// Deploy an eventer contract
+			eventer := NewEventer()
+			addr, _, err := bind.Deploy(auth, sim, common.FromHex(EventerMetaData.Bin), nil)
+			if err != nil {
+				t.Fatalf("Failed to deploy eventer contract: %v", err)
+			}
+			sim.Commit()
+			instance := eventer.Instance(sim, addr)
+
+			// Inject a few events into the contract, gradually more in each block
+			for i := 1; i <= 3; i++ {
+				for j := 1; j <= i; j++ {
+					input, _ := eventer.PackRaiseSimpleEvent(common.Address{byte(j)}, [32]byte{byte(j)}, true, big.NewInt(int64(10*i+j)))
+					if _, err := bind.Transact(instance, auth, input); err != nil {
+						t.Fatalf("block %d, event %d: raise failed: %v", i, j, err)
+					}
+				}
+				sim.Commit()
+			}
+			// Test filtering for certain events and ensure they can be found
+			sit, err := bind.FilterEvents(instance, nil, eventer.UnpackSimpleEventEvent, []interface{}{common.Address{1}, common.Address{3}}, []interface{}{[32]byte{byte(1)}, [32]byte{byte(2)}, [32]byte{byte(3)}}, []interface{}{true})
+			if err != nil {
+				t.Fatalf("failed to filter for simple events: %v", err)
+			}
+			defer sit.Close()
+
+			for _, want := range []uint64{11, 21, 31, 33} {
+				if !sit.Next() {
+					t.Fatalf("simple log %d not found: %v", want, sit.Error())
+				}
+				if ev := sit.Value(); ev.Value.Uint64() != want || !ev.Flag || ev.Raw == nil {
+					t.Errorf("simple log content mismatch: have %v, want {%d, true}", ev, want)
+				}
+			}
+			if sit.Next() {
+				t.Errorf("unexpected simple event found: %+v", sit.Value())
+			}
+			if err = sit.Error(); err != nil {
+				t.Fatalf("simple event iteration failed: %v", err)
+			}
+			// Test raising and filtering for events with dynamic indexed components
+			input, _ := eventer.PackRaiseDynamicEvent("Hello", []byte("World"))
+			if _, err := bind.Transact(instance, auth, input); err != nil {
+				t.Fatalf("failed to raise dynamic event: %v", err)
+			}
+			sim.Commit()
+
+			dit, err := bind.FilterEvents(instance, nil, eventer.UnpackDynamicEventEvent, []interface{}{"Hi", "Hello", "Bye"}, []interface{}{[]byte("World")})
+			if err != nil {
+				t.Fatalf("failed to filter for dynamic events: %v", err)
+			}
+			defer dit.Close()
+
+			if !dit.Next() {
+				t.Fatalf("dynamic log not found: %v", dit.Error())
+			}
+			if ev := dit.Value(); ev.NonIndexedString != "Hello" || string(ev.NonIndexedBytes) != "World" || ev.IndexedString != common.HexToHash("0x06b3dfaec148fb1bb2b066f10ec285e7c9bf402ab32aa78a5d38e34566810cd2") || ev.IndexedBytes != common.HexToHash("0xf2208c967df089f60420785795c0a9ba8896b0f6f1867fa7f1f12ad6f79c1a18") {
+				t.Errorf("dynamic log content mismatch: have %v", ev)
+			}
+			if dit.Next() {
+				t.Errorf("unexpected dynamic event found: %+v", dit.Value())
+			}
+			// Test raising and filtering for events with fixed bytes components
+			var fblob [24]byte
+			copy(fblob[:], []byte("Fixed Bytes"))
+
+			input, _ = eventer.PackRaiseFixedBytesEvent(fblob)
+			if _, err := bind.Transact(instance, auth, input); err != nil {
+				t.Fatalf("failed to raise fixed bytes event: %v", err)
+			}
+			sim.Commit()
+
+			fit, err := bind.FilterEvents(instance, nil, eventer.UnpackFixedBytesEventEvent, []interface{}{fblob})
+			if err != nil {
+				t.Fatalf("failed to filter for fixed bytes events: %v", err)
+			}
+			defer fit.Close()
+
+			if !fit.Next() {
+				t.Fatalf("fixed bytes log not found: %v", fit.Error())
+			}
+			if ev := fit.Value(); ev.NonIndexedBytes != fblob || ev.IndexedBytes != fblob {
+				t.Errorf("fixed bytes log content mismatch: have %v, want {'%x', '%x'}", ev, fblob, fblob)
+			}
+			// Test subscribing to an event and raising it afterwards
+			ch := make(chan *EventerSimpleEvent, 16)
+			sub, err := bind.WatchEvents(instance, nil, eventer.UnpackSimpleEventEvent, ch)
+			if err != nil {
+				t.Fatalf("failed to subscribe to simple events: %v", err)
+			}
+			input, _ = eventer.PackRaiseSimpleEvent(common.Address{255}, [32]byte{255}, true, big.NewInt(255))
+			if _, err := bind.Transact(instance, auth, input); err != nil {
+				t.Fatalf("failed to raise subscribed simple event: %v", err)
+			}
+			sim.Commit()
+
+			select {
+			case event := <-ch:
+				if event.Value.Uint64() != 255 || event.Addr != (common.Address{255}) {
+					t.Errorf("simple log content mismatch: have %v, want 255", event)
+				}
+			case <-time.After(250 * time.Millisecond):
+				t.Fatalf("subscribed simple event didn't arrive")
+			}
+			sub.Unsubscribe()
+
+			// Test delivering the past events before the new ones
+			start := uint64(0)
+			all := make(chan *EventerSimpleEvent, 16)
+			sub, err = bind.FilterAndWatchEvents(instance, &bind.WatchOpts{Start: &start}, eventer.UnpackSimpleEventEvent, all)
+			if err != nil {
+				t.Fatalf("failed to subscribe to past simple events: %v", err)
+			}
+			defer sub.Unsubscribe()
+
+			input, _ = eventer.PackRaiseSimpleEvent(common.Address{254}, [32]byte{254}, true, big.NewInt(254))
+			if _, err := bind.Transact(instance, auth, input); err != nil {
+				t.Fatalf("failed to raise subscribed simple event: %v", err)
+			}
+			sim.Commit()
+
+			for _, want := range []uint64{11, 21, 22, 31, 32, 33, 255, 254} {
+				select {
+				case event := <-all:
+					if event.Value.Uint64() != want {
+						t.Errorf("simple log content mismatch: have %v, want %d", event, want)
+					}
+				case <-time.After(250 * time.Millisecond):
+					t.Fatalf("simple event %d didn't arrive", want)
+				}
+			}
+		`,
+	},
+	{
+		`UseLibrary`,
+		`
+			"math/big"
+
+			"github.com/ethereum/go-ethereum/accounts/abi/bind"
+			"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+			"github.com/ethereum/go-ethereum/common"
+			"github.com/ethereum/go-ethereum/core/types"
+			"github.com/ethereum/go-ethereum/crypto"
+		`,
+		`
+			// Generate a new random account and a funded simulator
+			key, _ := crypto.GenerateKey()
+			auth, _ := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+
+			sim := backends.NewSimulatedBackend(types.GenesisAlloc{auth.From: {Balance: big.NewInt(10000000000000000)}}, 10000000)
+			defer sim.Close()
+
+			// Deploy the library, then the contract linked to it
+			mathAddr, _, err := bind.Deploy(auth, sim, common.FromHex(MathMetaData.Bin), nil)
+			if err != nil {
+				t.Fatalf("Failed to deploy library: %v", err)
+			}
+			bin, err := LinkUseLibrary(UseLibraryLibraries{Math: mathAddr})
+			if err != nil {
+				t.Fatalf("Failed to link contract: %v", err)
+			}
+			addr, _, err := bind.Deploy(auth, sim, bin, nil)
+			if err != nil {
+				t.Fatalf("Failed to deploy linked contract: %v", err)
+			}
+			sim.Commit()
+
+			useLibrary := NewUseLibrary()
+			input, _ := useLibrary.PackAdd(big.NewInt(1), big.NewInt(2))
+			res, err := bind.Call(useLibrary.Instance(sim, addr), nil, input, useLibrary.UnpackAdd)
+			if err != nil {
+				t.Fatalf("Failed to call linked contract: %v", err)
+			}
+			if res.Cmp(big.NewInt(3)) != 0 {
+				t.Fatalf("Add did not return the correct result: %d != %d", res, 3)
+			}
+		`,
+	},
+}
+
+// Tests that v2 bindings can be successfully compiled and the requested tester
+// run against them.
+func TestGolangBindingsV2(t *testing.T) {
+	t.Parallel()
+	// Skip the test if no Go command can be found
+	gocmd := runtime.GOROOT() + "/bin/go"
+	if !common.FileExist(gocmd) {
+		t.Skip("go sdk not found for testing")
+	}
+	pkg := filepath.Join(t.TempDir(), "bindtest")
+	if err := os.MkdirAll(pkg, 0700); err != nil {
+		t.Fatalf("failed to create package: %v", err)
+	}
+	for _, tt := range bindV2Tests {
+		// Generate the binding of the contracts of bindTests
+		var (
+			types           = []string{tt.name}
+			abis, bytecodes []string
+			libs            map[string]string
+		)
+		for _, b := range bindTests {
+			if b.name == tt.name {
+				abis, bytecodes, libs = b.abi, b.bytecode, b.libs
+				if b.types != nil {
+					types = b.types
+				}
+			}
+		}
+		bind, err := BindV2(types, abis, bytecodes, "bindtest", libs, nil)
+		if err != nil {
+			t.Fatalf("%s: failed to generate binding: %v", tt.name, err)
+		}
+		if err = os.WriteFile(filepath.Join(pkg, strings.ToLower(tt.name)+".go"), []byte(bind), 0600); err != nil {
+			t.Fatalf("%s: failed to write binding: %v", tt.name, err)
+		}
+		code := fmt.Sprintf(`
+			package bindtest
+
+			import (
+				"testing"
+				%s
+			)
+
+			func Test%s(t *testing.T) {
+				%s
+			}
+		`, tt.imports, tt.name, tt.tester)
+		if err := os.WriteFile(filepath.Join(pkg, strings.ToLower(tt.name)+"_test.go"), []byte(code), 0600); err != nil {
+			t.Fatalf("%s: failed to write tests: %v", tt.name, err)
+		}
+	}
+	runBindTests(t, gocmd, pkg)
+}
@@ -0,0 +1,260 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// This file contains the generic helpers used by the v2 bindings, generated by
+// BindV2. The bindings only pack inputs and unpack outputs, these functions
+// interact with the contracts.
+
+// ContractEvent is implemented by the event types of the v2 bindings.
+type ContractEvent interface {
+	// ContractEventName returns the name of the event in the contract ABI.
+	ContractEventName() string
+}
+
+// Call invokes a (constant) contract method with the packed input, returning
+// the output decoded by unpack, e.g. the Unpack method of a v2 binding.
+func Call[T any](c *BoundContract, opts *CallOpts, input []byte, unpack func([]byte) (T, error)) (T, error) {
+	output, err := c.CallRaw(opts, input)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return unpack(output)
+}
+
+// Transact invokes a (paid) contract method with the packed input.
+func Transact(c *BoundContract, opts *TransactOpts, input []byte) (*types.Transaction, error) {
+	return c.RawTransact(opts, input)
+}
+
+// Deploy deploys a contract with the given bytecode, appending the packed input
+// of the constructor. It returns the address the contract will be deployed at.
+func Deploy(opts *TransactOpts, backend ContractBackend, bytecode []byte, input []byte) (common.Address, *types.Transaction, error) {
+	c := NewBoundContract(common.Address{}, abi.ABI{}, backend, backend, backend)
+	tx, err := c.transact(opts, nil, append(common.CopyBytes(bytecode), input...))
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	return crypto.CreateAddress(opts.From, tx.Nonce()), tx, nil
+}
+
+// UnpackEventValues checks that the log is an event of the given type and
+// unpacks the values of its arguments, in order of declaration. Indexed
+// arguments of dynamic or composite types are returned as the hash stored in
+// their topic.
+func UnpackEventValues(ev abi.Event, log *types.Log) ([]interface{}, error) {
+	// Anonymous events are not supported.
+	if len(log.Topics) == 0 {
+		return nil, errNoEventSignature
+	}
+	if log.Topics[0] != ev.ID {
+		return nil, errEventSignatureMismatch
+	}
+	data, err := ev.Inputs.Unpack(log.Data)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		values = make([]interface{}, 0, len(ev.Inputs))
+		topics = log.Topics[1:]
+	)
+	for _, arg := range ev.Inputs {
+		if !arg.Indexed {
+			values, data = append(values, data[0]), data[1:]
+			continue
+		}
+		if len(topics) == 0 {
+			return nil, errors.New("topic/field count mismatch")
+		}
+		topic := topics[0]
+		topics = topics[1:]
+
+		parsed := make(map[string]interface{})
+		if err := abi.ParseTopicsIntoMap(parsed, abi.Arguments{arg}, []common.Hash{topic}); err != nil {
+			return nil, err
+		}
+		values = append(values, parsed[arg.Name])
+	}
+	return values, nil
+}
+
+// EventIterator iterates over the events found by FilterEvents.
+type EventIterator[T any] struct {
+	event  *T
+	unpack func(*types.Log) (*T, error)
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Value returns the current event of the iterator.
+func (it *EventIterator[T]) Value() *T {
+	return it.event
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *EventIterator[T]) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			return it.unpackLog(log)
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		return it.unpackLog(log)
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+func (it *EventIterator[T]) unpackLog(log types.Log) bool {
+	event, err := it.unpack(&log)
+	if err != nil {
+		it.fail = err
+		return false
+	}
+	it.event = event
+	return true
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *EventIterator[T]) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *EventIterator[T]) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// FilterEvents filters the past events of type T emitted by the contract,
+// decoding them with unpack, e.g. the UnpackEvent method of a v2 binding. The
+// topics restrict the values of the indexed arguments, see abi.MakeTopics.
+func FilterEvents[T ContractEvent](c *BoundContract, opts *FilterOpts, unpack func(*types.Log) (*T, error), topics ...[]interface{}) (*EventIterator[T], error) {
+	var ev T
+	logs, sub, err := c.FilterLogs(opts, ev.ContractEventName(), topics...)
+	if err != nil {
+		return nil, err
+	}
+	return &EventIterator[T]{unpack: unpack, logs: logs, sub: sub}, nil
+}
+
+// WatchEvents subscribes to the future events of type T emitted by the contract,
+// sending them to sink after decoding them with unpack, e.g. the UnpackEvent
+// method of a v2 binding. The topics restrict the values of the indexed
+// arguments, see abi.MakeTopics.
+func WatchEvents[T ContractEvent](c *BoundContract, opts *WatchOpts, unpack func(*types.Log) (*T, error), sink chan<- *T, topics ...[]interface{}) (event.Subscription, error) {
+	var ev T
+	logs, sub, err := c.WatchLogs(opts, ev.ContractEventName(), topics...)
+	if err != nil {
+		return nil, err
+	}
+	return watchEvents(logs, sub, unpack, sink), nil
+}
+
+// FilterAndWatchEvents delivers the past events of type T emitted by the contract
+// from the start block, then continues with the new ones, see FilterAndWatchLogs.
+// The events of the logs removed by reorgs have their Raw.Removed flag set.
+func FilterAndWatchEvents[T ContractEvent](c *BoundContract, opts *WatchOpts, unpack func(*types.Log) (*T, error), sink chan<- *T, topics ...[]interface{}) (event.Subscription, error) {
+	var ev T
+	logs, sub, err := c.FilterAndWatchLogs(opts, ev.ContractEventName(), topics...)
+	if err != nil {
+		return nil, err
+	}
+	return watchEvents(logs, sub, unpack, sink), nil
+}
+
+// watchEvents forwards the logs of a subscription to sink, decoded with unpack.
+func watchEvents[T any](logs chan types.Log, sub event.Subscription, unpack func(*types.Log) (*T, error), sink chan<- *T) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event, err := unpack(&log)
+				if err != nil {
+					return err
+				}
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	})
+}
+
+// ContractError is implemented by the custom error types of the bindings.
+type ContractError interface {
+	// ContractErrorName returns the name of the error in the contract ABI.
+	ContractErrorName() string
+}
+
+// UnpackError returns the custom error of type T reverting a contract call or
+// transaction, if err is such a revert.
+func UnpackError[T ContractError](err error) (*T, bool) {
+	var custom *abi.CustomError
+	if !errors.As(err, &custom) {
+		return nil, false
+	}
+	var zero T
+	if custom.Def.Name != zero.ContractErrorName() {
+		return nil, false
+	}
+	v := new(T)
+	if err := custom.Copy(v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
@@ -0,0 +1,53 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// libraryPlaceholder matches the placeholders of the libraries in unlinked
+// bytecode, capturing their pattern.
+var libraryPlaceholder = regexp.MustCompile(`__\$([0-9a-f]{34})\$__`)
+
+// LibraryPattern returns the pattern of the placeholders "__$<pattern>$__" of a
+// library in unlinked bytecode, given its fully qualified name, i.e. the path of
+// its source file and its name separated by ":".
+func LibraryPattern(name string) string {
+	return crypto.Keccak256Hash([]byte(name)).Hex()[2:36]
+}
+
+// Link replaces the library placeholders of hex encoded bytecode with the
+// addresses of the deployed libraries, given by placeholder pattern. It fails if
+// the bytecode links a library missing from libs.
+func Link(bytecode string, libs map[string]common.Address) (string, error) {
+	linked := libraryPlaceholder.ReplaceAllStringFunc(bytecode, func(placeholder string) string {
+		if addr, ok := libs[placeholder[3:37]]; ok {
+			return hex.EncodeToString(addr[:])
+		}
+		return placeholder
+	})
+	if missing := libraryPlaceholder.FindString(linked); missing != "" {
+		return "", fmt.Errorf("bytecode links unknown library %s", missing)
+	}
+	return linked, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestLink(t *testing.T) {
+	t.Parallel()
+
+	var (
+		math    = LibraryPattern("contracts/Math.sol:Math")
+		strs    = LibraryPattern("contracts/Strings.sol:Strings")
+		mathBin = "__$" + math + "$__"
+		strsBin = "__$" + strs + "$__"
+		code    = "0x6073" + mathBin + "6000" + strsBin + "60" + mathBin
+	)
+	if len(math) != 34 || math == strs {
+		t.Fatalf("invalid patterns %s, %s", math, strs)
+	}
+	linked, err := Link(code, map[string]common.Address{
+		math: common.HexToAddress("0x00000000000000000000000000000000000000aa"),
+		strs: common.HexToAddress("0x00000000000000000000000000000000000000bb"),
+	})
+	if err != nil {
+		t.Fatalf("failed to link: %v", err)
+	}
+	want := "0x6073" + strings.Repeat("00", 19) + "aa" + "6000" + strings.Repeat("00", 19) + "bb" + "60" + strings.Repeat("00", 19) + "aa"
+	if linked != want {
+		t.Errorf("wrong linked bytecode: have %s, want %s", linked, want)
+	}
+	// Missing libraries must be reported
+	if _, err := Link(code, map[string]common.Address{math: {1}}); err == nil || !strings.Contains(err.Error(), strsBin) {
+		t.Errorf("missing library not reported: %v", err)
+	}
+}
@@ -24,6 +24,8 @@ type tmplData struct {
 	Contracts map[string]*tmplContract // List of contracts to generate into this file
 	Libraries map[string]string        // Map the bytecode's link pattern to the library name
 	Structs   map[string]*tmplStruct   // Contract struct type definitions
+
+	OmitStructs bool // Whether the structs are declared in another file of the package
 }
 
 // tmplContract contains the data needed to generate an individual contract binding.
@@ -38,8 +40,10 @@ type tmplContract struct {
 	Fallback    *tmplMethod            // Additional special fallback function
 	Receive     *tmplMethod            // Additional special receive function
 	Events      map[string]*tmplEvent  // Contract events accessors
+	Errors      map[string]*tmplError  // Contract custom errors
 	Libraries   map[string]string      // Same as tmplData, but filtered to only keep what the contract needs
 	Library     bool                   // Indicator whether the contract is a library
+	AutoLink    bool                   // Whether the libraries are bound too, so that they can be deployed first
 }
 
 // tmplMethod is a wrapper around an abi.Method that contains a few preprocessed
@@ -57,6 +61,13 @@ type tmplEvent struct {
 	Normalized abi.Event // Normalized version of the parsed fields
 }
 
+// tmplError is a wrapper around an abi.Error that contains a few preprocessed
+// and cached data fields.
+type tmplError struct {
+	Original   abi.Error // Original error as parsed by the abi package
+	Normalized abi.Error // Normalized version of the parsed error
+}
+
 // tmplField is a wrapper around a struct field with binding language
 // struct type definition and relative filed name.
 type tmplField struct {
@@ -113,6 +124,7 @@ var (
 )
 
 {{$structs := .Structs}}
+{{if not .OmitStructs}}
 {{range $structs}}
 	// {{.Name}} is an auto generated low-level Go binding around an user-defined struct.
 	type {{.Name}} struct {
@@ -120,6 +132,7 @@ var (
 	{{$field.Name}} {{$field.Type}}{{end}}
 	}
 {{end}}
+{{end}}
 
 {{range $contract := .Contracts}}
 	// {{.Type}}MetaData contains all meta data concerning the {{.Type}} contract.
@@ -150,25 +163,70 @@ var (
 		// Deprecated: Use {{.Type}}MetaData.Bin instead.
 		var {{.Type}}Bin = {{.Type}}MetaData.Bin
 
-		// Deploy{{.Type}} deploys a new Ethereum contract, binding an instance of {{.Type}} to it.
-		func Deploy{{.Type}}(auth *bind.TransactOpts, backend bind.ContractBackend {{range .Constructor.Inputs}}, {{.Name}} {{bindtype .Type $structs}}{{end}}) (common.Address, *types.Transaction, *{{.Type}}, error) {
-		  parsed, err := {{.Type}}MetaData.GetAbi()
-		  if err != nil {
-		    return common.Address{}, nil, nil, err
-		  }
-		  if parsed == nil {
-			return common.Address{}, nil, nil, errors.New("GetABI returned nil")
-		  }
-		  {{range $pattern, $name := .Libraries}}
-			{{decapitalise $name}}Addr, _, _, _ := Deploy{{capitalise $name}}(auth, backend)
-			{{$contract.Type}}Bin = strings.ReplaceAll({{$contract.Type}}Bin, "__${{$pattern}}$__", {{decapitalise $name}}Addr.String()[2:])
-		  {{end}}
-		  address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex({{.Type}}Bin), backend {{range .Constructor.Inputs}}, {{.Name}}{{end}})
-		  if err != nil {
-		    return common.Address{}, nil, nil, err
-		  }
-		  return address, tx, &{{.Type}}{ {{.Type}}Caller: {{.Type}}Caller{contract: contract}, {{.Type}}Transactor: {{.Type}}Transactor{contract: contract}, {{.Type}}Filterer: {{.Type}}Filterer{contract: contract} }, nil
-		}
+		{{if .Libraries}}
+			// {{.Type}}Libraries holds the addresses of the libraries the {{.Type}} bytecode is linked to.
+			type {{.Type}}Libraries struct {
+				{{range $pattern, $name := .Libraries}}{{capitalise $name}} common.Address
+				{{end}}
+			}
+
+			// Deploy{{.Type}}WithLibraries deploys a new Ethereum contract linked to already deployed libraries,
+			// binding an instance of {{.Type}} to it.
+			func Deploy{{.Type}}WithLibraries(auth *bind.TransactOpts, backend bind.ContractBackend, libs {{.Type}}Libraries {{range .Constructor.Inputs}}, {{.Name}} {{bindtype .Type $structs}}{{end}}) (common.Address, *types.Transaction, *{{.Type}}, error) {
+			  parsed, err := {{.Type}}MetaData.GetAbi()
+			  if err != nil {
+			    return common.Address{}, nil, nil, err
+			  }
+			  if parsed == nil {
+				return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+			  }
+			  bin, err := bind.Link({{.Type}}Bin, map[string]common.Address{
+				{{range $pattern, $name := .Libraries}}"{{$pattern}}": libs.{{capitalise $name}},
+				{{end}}
+			  })
+			  if err != nil {
+			    return common.Address{}, nil, nil, err
+			  }
+			  address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(bin), backend {{range .Constructor.Inputs}}, {{.Name}}{{end}})
+			  if err != nil {
+			    return common.Address{}, nil, nil, err
+			  }
+			  return address, tx, &{{.Type}}{ {{.Type}}Caller: {{.Type}}Caller{contract: contract}, {{.Type}}Transactor: {{.Type}}Transactor{contract: contract}, {{.Type}}Filterer: {{.Type}}Filterer{contract: contract} }, nil
+			}
+		{{end}}
+
+		{{if not .Libraries}}
+			// Deploy{{.Type}} deploys a new Ethereum contract, binding an instance of {{.Type}} to it.
+			func Deploy{{.Type}}(auth *bind.TransactOpts, backend bind.ContractBackend {{range .Constructor.Inputs}}, {{.Name}} {{bindtype .Type $structs}}{{end}}) (common.Address, *types.Transaction, *{{.Type}}, error) {
+			  parsed, err := {{.Type}}MetaData.GetAbi()
+			  if err != nil {
+			    return common.Address{}, nil, nil, err
+			  }
+			  if parsed == nil {
+				return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+			  }
+			  address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex({{.Type}}Bin), backend {{range .Constructor.Inputs}}, {{.Name}}{{end}})
+			  if err != nil {
+			    return common.Address{}, nil, nil, err
+			  }
+			  return address, tx, &{{.Type}}{ {{.Type}}Caller: {{.Type}}Caller{contract: contract}, {{.Type}}Transactor: {{.Type}}Transactor{contract: contract}, {{.Type}}Filterer: {{.Type}}Filterer{contract: contract} }, nil
+			}
+		{{else if .AutoLink}}
+			// Deploy{{.Type}} deploys the libraries {{.Type}} is linked to, then a new Ethereum contract,
+			// binding an instance of {{.Type}} to it.
+			func Deploy{{.Type}}(auth *bind.TransactOpts, backend bind.ContractBackend {{range .Constructor.Inputs}}, {{.Name}} {{bindtype .Type $structs}}{{end}}) (common.Address, *types.Transaction, *{{.Type}}, error) {
+			  var (
+			    libs {{.Type}}Libraries
+			    err  error
+			  )
+			  {{range $pattern, $name := .Libraries}}
+			    if libs.{{capitalise $name}}, _, _, err = Deploy{{capitalise $name}}(auth, backend); err != nil {
+			      return common.Address{}, nil, nil, err
+			    }
+			  {{end}}
+			  return Deploy{{.Type}}WithLibraries(auth, backend, libs {{range .Constructor.Inputs}}, {{.Name}}{{end}})
+			}
+		{{end}}
 	{{end}}
 
 	// {{.Type}} is an auto generated Go binding around an Ethereum contract.
@@ -567,5 +625,205 @@ var (
 		}
 
  	{{end}}
+	{{range .Errors}}
+		// {{$contract.Type}}{{.Normalized.Name}}Error represents a {{.Original.Name}} error raised by the {{$contract.Type}} contract.
+		//
+		// Solidity: {{.Original.String}}
+		type {{$contract.Type}}{{.Normalized.Name}}Error struct {
+			{{range .Normalized.Inputs}}{{capitalise .Name}} {{bindtype .Type $structs}}
+			{{end}}
+		}
+
+		// ContractErrorName returns the name of the error in the contract ABI.
+		func ({{$contract.Type}}{{.Normalized.Name}}Error) ContractErrorName() string {
+			return "{{.Original.Name}}"
+		}
+	{{end}}
+{{end}}
+`
+
+// tmplSourceGoV2 is the Go source template that the generated v2 Go contract
+// binding is based on.
+const tmplSourceGoV2 = `
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package {{.Package}}
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = bind.Deploy
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = abi.ConvertType
+)
+
+{{$structs := .Structs}}
+{{if not .OmitStructs}}
+{{range $structs}}
+	// {{.Name}} is an auto generated low-level Go binding around an user-defined struct.
+	type {{.Name}} struct {
+	{{range $field := .Fields}}
+	{{$field.Name}} {{$field.Type}}{{end}}
+	}
+{{end}}
+{{end}}
+
+{{range $contract := .Contracts}}
+	// {{.Type}}MetaData contains all meta data concerning the {{.Type}} contract.
+	var {{.Type}}MetaData = &bind.MetaData{
+		ABI: "{{.InputABI}}",
+		{{if .InputBin -}}
+		Bin: "0x{{.InputBin}}",
+		{{end}}
+	}
+
+	{{if and .InputBin .Libraries}}
+		// {{.Type}}Libraries holds the addresses of the libraries the {{.Type}} bytecode is linked to.
+		type {{.Type}}Libraries struct {
+			{{range $pattern, $name := .Libraries}}{{capitalise $name}} common.Address
+			{{end}}
+		}
+
+		// Link{{.Type}} returns the bytecode of the {{.Type}} contract linked to the deployed
+		// libraries, to be deployed with bind.Deploy.
+		func Link{{.Type}}(libs {{.Type}}Libraries) ([]byte, error) {
+			bin, err := bind.Link({{.Type}}MetaData.Bin, map[string]common.Address{
+				{{range $pattern, $name := .Libraries}}"{{$pattern}}": libs.{{capitalise $name}},
+				{{end}}
+			})
+			if err != nil {
+				return nil, err
+			}
+			return common.FromHex(bin), nil
+		}
+	{{end}}
+
+	// {{.Type}} is an auto generated Go binding around an Ethereum contract. It packs
+	// the inputs and unpacks the outputs of the contract methods and events, to be
+	// used with the generic helpers of the bind package.
+	type {{.Type}} struct {
+		abi abi.ABI
+	}
+
+	// New{{.Type}} creates a new binding of the {{.Type}} contract.
+	func New{{.Type}}() *{{.Type}} {
+		parsed, err := {{.Type}}MetaData.GetAbi()
+		if err != nil {
+			panic(errors.New("invalid ABI: " + err.Error()))
+		}
+		return &{{.Type}}{abi: *parsed}
+	}
+
+	// Instance binds an instance of the contract deployed at the given address, to
+	// interact with it through the backend.
+	func (_{{.Type}} *{{.Type}}) Instance(backend bind.ContractBackend, addr common.Address) *bind.BoundContract {
+		return bind.NewBoundContract(addr, _{{.Type}}.abi, backend, backend, backend)
+	}
+
+	{{if .InputBin}}
+	// PackConstructor packs the parameters of the constructor, to be appended to the
+	// bytecode of the contract when deploying it.
+	func (_{{.Type}} *{{.Type}}) PackConstructor({{range $i, $_ := .Constructor.Inputs}}{{if ne $i 0}}, {{end}}{{.Name}} {{bindtype .Type $structs}}{{end}}) ([]byte, error) {
+		return _{{.Type}}.abi.Pack(""{{range .Constructor.Inputs}}, {{.Name}}{{end}})
+	}
+	{{end}}
+
+	{{range methods .Calls .Transacts}}
+		// Pack{{.Normalized.Name}} packs the parameters of a call to the contract method 0x{{printf "%x" .Original.ID}}.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) Pack{{.Normalized.Name}}({{range $i, $_ := .Normalized.Inputs}}{{if ne $i 0}}, {{end}}{{.Name}} {{bindtype .Type $structs}}{{end}}) ([]byte, error) {
+			return _{{$contract.Type}}.abi.Pack("{{.Original.Name}}"{{range .Normalized.Inputs}}, {{.Name}}{{end}})
+		}
+
+		{{if eq (len .Normalized.Outputs) 1}}
+			{{$output := index .Normalized.Outputs 0}}
+			// Unpack{{.Normalized.Name}} unpacks the output of the contract method 0x{{printf "%x" .Original.ID}}.
+			//
+			// Solidity: {{.Original.String}}
+			func (_{{$contract.Type}} *{{$contract.Type}}) Unpack{{.Normalized.Name}}(data []byte) ({{bindtype $output.Type $structs}}, error) {
+				out, err := _{{$contract.Type}}.abi.Unpack("{{.Original.Name}}", data)
+				if err != nil {
+					return *new({{bindtype $output.Type $structs}}), err
+				}
+				return {{convert "out[0]" $output.Type $structs}}, nil
+			}
+		{{else if gt (len .Normalized.Outputs) 1}}
+			{{$fields := outputfields .Normalized.Outputs}}
+			// {{$contract.Type}}{{.Normalized.Name}}Output is the output of the contract method 0x{{printf "%x" .Original.ID}}.
+			type {{$contract.Type}}{{.Normalized.Name}}Output struct {
+				{{range $i, $_ := .Normalized.Outputs}}{{index $fields $i}} {{bindtype .Type $structs}}
+				{{end}}
+			}
+
+			// Unpack{{.Normalized.Name}} unpacks the outputs of the contract method 0x{{printf "%x" .Original.ID}}.
+			//
+			// Solidity: {{.Original.String}}
+			func (_{{$contract.Type}} *{{$contract.Type}}) Unpack{{.Normalized.Name}}(data []byte) ({{$contract.Type}}{{.Normalized.Name}}Output, error) {
+				out, err := _{{$contract.Type}}.abi.Unpack("{{.Original.Name}}", data)
+				if err != nil {
+					return {{$contract.Type}}{{.Normalized.Name}}Output{}, err
+				}
+				return {{$contract.Type}}{{.Normalized.Name}}Output{
+					{{range $i, $_ := .Normalized.Outputs}}{{index $fields $i}}: {{convert (printf "out[%d]" $i) .Type $structs}},
+					{{end}}
+				}, nil
+			}
+		{{end}}
+	{{end}}
+
+	{{range .Events}}
+		// {{$contract.Type}}{{.Normalized.Name}} represents a {{.Normalized.Name}} event raised by the {{$contract.Type}} contract.
+		type {{$contract.Type}}{{.Normalized.Name}} struct {
+			{{range .Normalized.Inputs}}{{capitalise .Name}} {{if .Indexed}}{{bindtopictype .Type $structs}}{{else}}{{bindtype .Type $structs}}{{end}}
+			{{end}}Raw *types.Log // Blockchain specific contextual infos
+		}
+
+		// ContractEventName returns the name of the event in the contract ABI.
+		func ({{$contract.Type}}{{.Normalized.Name}}) ContractEventName() string {
+			return "{{.Original.Name}}"
+		}
+
+		// Unpack{{.Normalized.Name}}Event unpacks a log of the event 0x{{printf "%x" .Original.ID}}.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) Unpack{{.Normalized.Name}}Event(log *types.Log) (*{{$contract.Type}}{{.Normalized.Name}}, error) {
+			{{if .Normalized.Inputs}}values{{else}}_{{end}}, err := bind.UnpackEventValues(_{{$contract.Type}}.abi.Events["{{.Original.Name}}"], log)
+			if err != nil {
+				return nil, err
+			}
+			return &{{$contract.Type}}{{.Normalized.Name}}{
+				{{range $i, $_ := .Normalized.Inputs}}{{capitalise .Name}}: {{if .Indexed}}values[{{$i}}].({{bindtopictype .Type $structs}}){{else}}{{convert (printf "values[%d]" $i) .Type $structs}}{{end}},
+				{{end}}Raw: log,
+			}, nil
+		}
+	{{end}}
+	{{range .Errors}}
+		// {{$contract.Type}}{{.Normalized.Name}}Error represents a {{.Original.Name}} error raised by the {{$contract.Type}} contract.
+		//
+		// Solidity: {{.Original.String}}
+		type {{$contract.Type}}{{.Normalized.Name}}Error struct {
+			{{range .Normalized.Inputs}}{{capitalise .Name}} {{bindtype .Type $structs}}
+			{{end}}
+		}
+
+		// ContractErrorName returns the name of the error in the contract ABI.
+		func ({{$contract.Type}}{{.Normalized.Name}}Error) ContractErrorName() string {
+			return "{{.Original.Name}}"
+		}
+	{{end}}
 {{end}}
 `
@@ -17,12 +17,16 @@
 package bind_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"math/big"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -137,3 +141,113 @@ func TestWaitDeployedCornerCases(t *testing.T) {
 	backend.Client().SendTransaction(ctx, tx)
 	cancel()
 }
+
+func TestFilterAndWatchLogs(t *testing.T) {
+	backend := simulated.NewBackend(
+		types.GenesisAlloc{
+			crypto.PubkeyToAddress(testKey.PublicKey): {Balance: big.NewInt(10000000000000000)},
+		},
+	)
+	defer backend.Close()
+
+	// Deploy a contract emitting a Ping() event on every call
+	parsed, _ := abi.JSON(strings.NewReader(`[{"anonymous":false,"inputs":[],"name":"Ping","type":"event"}]`))
+	code := "602780600b6000396000f3" + "7f" + parsed.Events["Ping"].ID.Hex()[2:] + "60006000a100"
+
+	auth, _ := bind.NewKeyedTransactorWithChainID(testKey, big.NewInt(1337))
+	_, _, contract, err := bind.DeployContract(auth, parsed, common.FromHex(code), backend.Client())
+	if err != nil {
+		t.Fatalf("failed to deploy contract: %v", err)
+	}
+	backend.Commit()
+
+	ping := func(n int) common.Hash {
+		for i := 0; i < n; i++ {
+			if _, err := contract.RawTransact(auth, nil); err != nil {
+				t.Fatalf("failed to call contract: %v", err)
+			}
+		}
+		return backend.Commit()
+	}
+	ping(2)
+	ping(1)
+
+	start := uint64(0)
+	logs, sub, err := contract.FilterAndWatchLogs(&bind.WatchOpts{Start: &start}, "Ping")
+	if err != nil {
+		t.Fatalf("failed to watch logs: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	next := func() types.Log {
+		t.Helper()
+		select {
+		case log := <-logs:
+			return log
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("log not delivered")
+		}
+		return types.Log{}
+	}
+	// The past logs must be delivered first, then the new ones
+	for i := 0; i < 3; i++ {
+		if log := next(); log.Removed || log.BlockNumber > 3 {
+			t.Fatalf("unexpected past log %d: %+v", i, log)
+		}
+	}
+	head := ping(1)
+	if log := next(); log.Removed || log.BlockHash != head {
+		t.Fatalf("unexpected new log: %+v", log)
+	}
+	// No log must be delivered twice
+	select {
+	case log := <-logs:
+		t.Fatalf("log delivered twice: %+v", log)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestCallOverrides(t *testing.T) {
+	backend := simulated.NewBackend(
+		types.GenesisAlloc{
+			crypto.PubkeyToAddress(testKey.PublicKey): {Balance: big.NewInt(10000000000000000)},
+		},
+	)
+	defer backend.Close()
+
+	var (
+		addr     = common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+		contract = bind.NewBoundContract(addr, abi.ABI{}, backend.Client(), backend.Client(), backend.Client())
+		// Code returning the block timestamp and its own balance
+		code = common.FromHex("426000524760205260406000f3")
+	)
+	if _, err := contract.CallRaw(nil, nil); !errors.Is(err, bind.ErrNoCode) {
+		t.Fatalf("call without code: have %v, want %v", err, bind.ErrNoCode)
+	}
+	opts := &bind.CallOpts{
+		StateOverrides: map[common.Address]ethereum.OverrideAccount{
+			addr: {Code: code, Balance: big.NewInt(1000)},
+		},
+		BlockOverrides: &ethereum.BlockOverrides{Time: 12345},
+	}
+	output, err := contract.CallRaw(opts, nil)
+	if err != nil {
+		t.Fatalf("failed to call with overrides: %v", err)
+	}
+	want := append(common.LeftPadBytes([]byte{0x30, 0x39}, 32), common.LeftPadBytes([]byte{0x03, 0xe8}, 32)...)
+	if !bytes.Equal(output, want) {
+		t.Errorf("wrong output: have %x, want %x", output, want)
+	}
+	// Overriding the code with empty code leaves no contract to call
+	opts.StateOverrides[addr] = ethereum.OverrideAccount{Code: []byte{}}
+	if _, err := contract.CallRaw(opts, nil); !errors.Is(err, bind.ErrNoCode) {
+		t.Fatalf("call with empty code: have %v, want %v", err, bind.ErrNoCode)
+	}
+	// Overrides can't apply to block hash state
+	opts.BlockHash = common.Hash{1}
+	if _, err := contract.CallRaw(opts, nil); err == nil {
+		t.Fatal("call with overrides at block hash succeeded")
+	}
+}
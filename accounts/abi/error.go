@@ -90,3 +90,25 @@ func (e *Error) Unpack(data []byte) (interface{}, error) {
 	}
 	return e.Inputs.Unpack(data[4:])
 }
+
+// CustomError is a custom error raised by a contract, decoded with its definition
+// in the contract ABI.
+type CustomError struct {
+	Def    *Error        // Definition of the error in the ABI
+	Values []interface{} // Values of the error parameters, in order of definition
+}
+
+// Error implements error, formatting the error as a call of its definition.
+func (e *CustomError) Error() string {
+	params := make([]string, len(e.Values))
+	for i, value := range e.Values {
+		params[i] = fmt.Sprintf("%s: %v", e.Def.Inputs[i].Name, value)
+	}
+	return fmt.Sprintf("%s(%s)", e.Def.Name, strings.Join(params, ", "))
+}
+
+// Copy copies the values of the error parameters into v, a pointer to a struct
+// with a field for every parameter.
+func (e *CustomError) Copy(v interface{}) error {
+	return e.Def.Inputs.Copy(v, e.Values)
+}
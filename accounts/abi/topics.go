@@ -33,69 +33,131 @@ func MakeTopics(query ...[]interface{}) ([][]common.Hash, error) {
 	topics := make([][]common.Hash, len(query))
 	for i, filter := range query {
 		for _, rule := range filter {
-			var topic common.Hash
+			topic, err := makeTopic(rule)
+			if err != nil {
+				return nil, err
+			}
+			topics[i] = append(topics[i], topic)
+		}
+	}
+	return topics, nil
+}
 
-			// Try to generate the topic based on simple types
-			switch rule := rule.(type) {
-			case common.Hash:
-				copy(topic[:], rule[:])
-			case common.Address:
-				copy(topic[common.HashLength-common.AddressLength:], rule[:])
-			case *big.Int:
-				copy(topic[:], math.U256Bytes(rule))
-			case bool:
-				if rule {
-					topic[common.HashLength-1] = 1
-				}
-			case int8:
-				copy(topic[:], genIntType(int64(rule), 1))
-			case int16:
-				copy(topic[:], genIntType(int64(rule), 2))
-			case int32:
-				copy(topic[:], genIntType(int64(rule), 4))
-			case int64:
-				copy(topic[:], genIntType(rule, 8))
-			case uint8:
-				blob := new(big.Int).SetUint64(uint64(rule)).Bytes()
-				copy(topic[common.HashLength-len(blob):], blob)
-			case uint16:
-				blob := new(big.Int).SetUint64(uint64(rule)).Bytes()
-				copy(topic[common.HashLength-len(blob):], blob)
-			case uint32:
-				blob := new(big.Int).SetUint64(uint64(rule)).Bytes()
-				copy(topic[common.HashLength-len(blob):], blob)
-			case uint64:
-				blob := new(big.Int).SetUint64(rule).Bytes()
-				copy(topic[common.HashLength-len(blob):], blob)
-			case string:
-				hash := crypto.Keccak256Hash([]byte(rule))
-				copy(topic[:], hash[:])
-			case []byte:
-				hash := crypto.Keccak256Hash(rule)
-				copy(topic[:], hash[:])
+// makeTopic converts the value of an indexed event parameter into its topic.
+func makeTopic(rule interface{}) (common.Hash, error) {
+	var topic common.Hash
 
-			default:
-				// todo(rjl493456442) according to solidity documentation, indexed event
-				// parameters that are not value types i.e. arrays and structs are not
-				// stored directly but instead a keccak256-hash of an encoding is stored.
-				//
-				// We only convert stringS and bytes to hash, still need to deal with
-				// array(both fixed-size and dynamic-size) and struct.
+	// Try to generate the topic based on simple types
+	switch rule := rule.(type) {
+	case common.Hash:
+		copy(topic[:], rule[:])
+	case common.Address:
+		copy(topic[common.HashLength-common.AddressLength:], rule[:])
+	case *big.Int:
+		copy(topic[:], math.U256Bytes(new(big.Int).Set(rule)))
+	case bool:
+		if rule {
+			topic[common.HashLength-1] = 1
+		}
+	case int8:
+		copy(topic[:], genIntType(int64(rule), 1))
+	case int16:
+		copy(topic[:], genIntType(int64(rule), 2))
+	case int32:
+		copy(topic[:], genIntType(int64(rule), 4))
+	case int64:
+		copy(topic[:], genIntType(rule, 8))
+	case uint8:
+		blob := new(big.Int).SetUint64(uint64(rule)).Bytes()
+		copy(topic[common.HashLength-len(blob):], blob)
+	case uint16:
+		blob := new(big.Int).SetUint64(uint64(rule)).Bytes()
+		copy(topic[common.HashLength-len(blob):], blob)
+	case uint32:
+		blob := new(big.Int).SetUint64(uint64(rule)).Bytes()
+		copy(topic[common.HashLength-len(blob):], blob)
+	case uint64:
+		blob := new(big.Int).SetUint64(rule).Bytes()
+		copy(topic[common.HashLength-len(blob):], blob)
+	case string:
+		hash := crypto.Keccak256Hash([]byte(rule))
+		copy(topic[:], hash[:])
+	case []byte:
+		hash := crypto.Keccak256Hash(rule)
+		copy(topic[:], hash[:])
+
+	default:
+		// Attempt to generate the topic from funky types
+		val := reflect.ValueOf(rule)
+		switch {
+		// static byte array
+		case val.Kind() == reflect.Array && val.Type().Elem().Kind() == reflect.Uint8:
+			reflect.Copy(reflect.ValueOf(topic[:val.Len()]), val)
 
-				// Attempt to generate the topic from funky types
-				val := reflect.ValueOf(rule)
-				switch {
-				// static byte array
-				case val.Kind() == reflect.Array && reflect.TypeOf(rule).Elem().Kind() == reflect.Uint8:
-					reflect.Copy(reflect.ValueOf(topic[:val.Len()]), val)
-				default:
-					return nil, fmt.Errorf("unsupported indexed type: %T", rule)
-				}
+		// Arrays and structs are not stored directly, but the keccak256 hash
+		// of their encoding is.
+		case val.Kind() == reflect.Array || val.Kind() == reflect.Slice || val.Kind() == reflect.Struct:
+			blob, err := encodeTopicValue(val)
+			if err != nil {
+				return topic, err
 			}
-			topics[i] = append(topics[i], topic)
+			topic = crypto.Keccak256Hash(blob)
+		default:
+			return topic, fmt.Errorf("unsupported indexed type: %T", rule)
 		}
 	}
-	return topics, nil
+	return topic, nil
+}
+
+// encodeTopicValue encodes a value within an indexed array or struct. As per the
+// Solidity documentation, value types are padded to 32 bytes, strings and bytes are
+// padded to a multiple of 32 bytes without their length, and arrays and structs
+// are the concatenation of the encoding of their members, without their length.
+func encodeTopicValue(val reflect.Value) ([]byte, error) {
+	switch val.Kind() {
+	case reflect.String:
+		return padTopicBytes([]byte(val.String())), nil
+	case reflect.Slice, reflect.Array:
+		if val.Type().Elem().Kind() == reflect.Uint8 {
+			if val.Kind() == reflect.Slice {
+				return padTopicBytes(val.Bytes()), nil
+			}
+			// Fixed byte arrays and addresses are value types
+			break
+		}
+		var blob []byte
+		for i := 0; i < val.Len(); i++ {
+			elem, err := encodeTopicValue(val.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			blob = append(blob, elem...)
+		}
+		return blob, nil
+	case reflect.Struct:
+		var blob []byte
+		for i := 0; i < val.NumField(); i++ {
+			if !val.Type().Field(i).IsExported() {
+				continue
+			}
+			field, err := encodeTopicValue(val.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			blob = append(blob, field...)
+		}
+		return blob, nil
+	}
+	topic, err := makeTopic(val.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return topic[:], nil
+}
+
+// padTopicBytes right pads a blob to a multiple of 32 bytes.
+func padTopicBytes(blob []byte) []byte {
+	return common.RightPadBytes(blob, (len(blob)+31)/32*32)
 }
 
 func genIntType(rule int64, size uint) []byte {
@@ -145,10 +207,8 @@ func parseTopicWithSetter(fields Arguments, topics []common.Hash, setter func(Ar
 		}
 		var reconstr interface{}
 		switch arg.Type.T {
-		case TupleTy:
-			return errors.New("tuple type in topic reconstruction")
-		case StringTy, BytesTy, SliceTy, ArrayTy:
-			// Array types (including strings and bytes) have their keccak256 hashes stored in the topic- not a hash
+		case StringTy, BytesTy, SliceTy, ArrayTy, TupleTy:
+			// Array types (including strings and bytes) and structs have their keccak256 hashes stored in the topic- not a hash
 			// whose bytes can be decoded to the actual value- so the best we can do is retrieve that hash
 			reconstr = topics[i]
 		case FunctionTy:
@@ -135,6 +135,40 @@ func TestMakeTopics(t *testing.T) {
 			[][]common.Hash{{crypto.Keccak256Hash([]byte{1, 2, 3})}},
 			false,
 		},
+		{
+			"support array types in topics",
+			args{[][]interface{}{
+				{[]*big.Int{big.NewInt(1), big.NewInt(2)}},
+				{[2][]bool{{true}, {false, true}}},
+				{[]common.Address{{1}}},
+			}},
+			[][]common.Hash{
+				{crypto.Keccak256Hash(common.LeftPadBytes([]byte{1}, 32), common.LeftPadBytes([]byte{2}, 32))},
+				{crypto.Keccak256Hash(common.LeftPadBytes([]byte{1}, 32), make([]byte, 32), common.LeftPadBytes([]byte{1}, 32))},
+				{crypto.Keccak256Hash(common.LeftPadBytes(common.Address{1}.Bytes(), 32))},
+			},
+			false,
+		},
+		{
+			"support struct types in topics",
+			args{[][]interface{}{{struct {
+				A *big.Int
+				B string
+				C [][4]byte
+			}{big.NewInt(1), "hello", [][4]byte{{1, 2, 3, 4}}}}}},
+			[][]common.Hash{{crypto.Keccak256Hash(
+				common.LeftPadBytes([]byte{1}, 32),
+				common.RightPadBytes([]byte("hello"), 32),
+				common.RightPadBytes([]byte{1, 2, 3, 4}, 32),
+			)}},
+			false,
+		},
+		{
+			"error on unsupported types in arrays",
+			args{[][]interface{}{{[]float64{1}}}},
+			nil,
+			true,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -329,19 +363,21 @@ func setupTopicsTests() []topicTest {
 			wantErr: true,
 		},
 		{
-			name: "error on tuple in topic reconstruction",
+			name: "tuple type",
 			args: args{
-				createObj: func() interface{} { return &tupleType },
-				resultObj: func() interface{} { return &tupleType },
-				resultMap: func() map[string]interface{} { return make(map[string]interface{}) },
+				createObj: func() interface{} { return &hashStruct{} },
+				resultObj: func() interface{} { return &hashStruct{common.Hash{1}} },
+				resultMap: func() map[string]interface{} {
+					return map[string]interface{}{"hashValue": common.Hash{1}}
+				},
 				fields: Arguments{Argument{
-					Name:    "tupletype",
+					Name:    "hashValue",
 					Type:    tupleType,
 					Indexed: true,
 				}},
-				topics: []common.Hash{{0}},
+				topics: []common.Hash{{1}},
 			},
-			wantErr: true,
+			wantErr: false,
 		},
 		{
 			name: "error on improper encoded function",
@@ -18,6 +18,7 @@
 package accounts
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 
@@ -153,6 +154,60 @@ type Wallet interface {
 	SignTxWithPassphrase(account Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
 }
 
+// ContextSigner is implemented by wallets whose signing requests may take long to
+// complete, such as remote signers running multiple rounds between several
+// parties. The context bounds the time waited for the signature, cancelling it
+// aborts the pending request. The context-free signing methods of such wallets
+// wait up to a default timeout.
+type ContextSigner interface {
+	// SignDataContext is identical to Wallet.SignData, but bounded by the context.
+	SignDataContext(ctx context.Context, account Account, mimeType string, data []byte) ([]byte, error)
+
+	// SignTextContext is identical to Wallet.SignText, but bounded by the context.
+	SignTextContext(ctx context.Context, account Account, text []byte) ([]byte, error)
+
+	// SignTxContext is identical to Wallet.SignTx, but bounded by the context.
+	SignTxContext(ctx context.Context, account Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// SignTxContext signs a transaction with the wallet, bounded by the context if the
+// wallet implements ContextSigner. Other wallets sign synchronously, the context
+// is only checked before signing.
+func SignTxContext(ctx context.Context, wallet Wallet, account Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if signer, ok := wallet.(ContextSigner); ok {
+		return signer.SignTxContext(ctx, account, tx, chainID)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return wallet.SignTx(account, tx, chainID)
+}
+
+// CheckReplayProtection returns an error if the signed transaction isn't replay
+// protected (EIP-155) for the given chain.
+func CheckReplayProtection(tx *types.Transaction, chainID *big.Int) error {
+	if !tx.Protected() {
+		return ErrUnprotectedTx
+	}
+	if chainID == nil || tx.ChainId().Cmp(chainID) != 0 {
+		return fmt.Errorf("%w: signed for chain %v, want %v", ErrUnprotectedTx, tx.ChainId(), chainID)
+	}
+	return nil
+}
+
+// SignDataContext signs data with the wallet, bounded by the context if the wallet
+// implements ContextSigner. Other wallets sign synchronously, the context is only
+// checked before signing.
+func SignDataContext(ctx context.Context, wallet Wallet, account Account, mimeType string, data []byte) ([]byte, error) {
+	if signer, ok := wallet.(ContextSigner); ok {
+		return signer.SignDataContext(ctx, account, mimeType, data)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return wallet.SignData(account, mimeType, data)
+}
+
 // Backend is a "wallet provider" that may contain a batch of accounts they can
 // sign transactions with and upon request, do so.
 type Backend interface {
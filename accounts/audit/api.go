@@ -0,0 +1,46 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package audit
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// maxRecords is the maximum number of records returned by a single query.
+const maxRecords = 1000
+
+// API exposes the audit log over RPC, in the "audit" namespace.
+type API struct {
+	log *Log
+}
+
+// NewAPI creates the RPC API of the audit log.
+func NewAPI(log *Log) *API {
+	return &API{log: log}
+}
+
+// Records returns up to count records of the audit log, starting with the
+// record with sequence number from. At most 1000 records are returned.
+func (api *API) Records(from hexutil.Uint64, count hexutil.Uint64) ([]*Record, error) {
+	return api.log.Records(uint64(from), int(min(uint64(count), maxRecords)))
+}
+
+// Verify checks the integrity of the audit log, returning the number of records.
+func (api *API) Verify() (hexutil.Uint64, error) {
+	n, err := api.log.Verify()
+	return hexutil.Uint64(n), err
+}
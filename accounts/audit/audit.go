@@ -0,0 +1,237 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package audit implements an append-only audit log of signing operations.
+//
+// The log is a file of JSON records, one per line, numbered sequentially. If hash
+// chaining is enabled, every record also commits to the hash of its predecessor,
+// so that records can't be removed or altered without breaking the chain.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Kinds of signing requests.
+const (
+	KindTransaction = "transaction"
+	KindText        = "text"
+	KindData        = "data"
+)
+
+// Decisions on signing requests.
+const (
+	DecisionSigned   = "signed"
+	DecisionRejected = "rejected"
+)
+
+// Record is an entry of the audit log.
+type Record struct {
+	Seq       uint64         `json:"seq"`
+	Time      time.Time      `json:"time"`
+	Account   common.Address `json:"account"`
+	Kind      string         `json:"kind"`
+	Digest    common.Hash    `json:"digest"`              // Hash signed, or requested to be signed
+	Transport string         `json:"transport,omitempty"` // Transport of the RPC request, empty for internal requests
+	Requester string         `json:"requester,omitempty"` // Remote address of the RPC client
+	Decision  string         `json:"decision"`
+	Error     string         `json:"error,omitempty"` // Reason of rejected requests
+
+	Prev common.Hash `json:"prev"` // Hash of the previous record, zero if not hash-chained
+	Hash common.Hash `json:"hash"` // Hash of the record, zero if not hash-chained
+}
+
+// hash computes the hash of the record, covering all its fields but Hash.
+func (r *Record) hash() common.Hash {
+	cpy := *r
+	cpy.Hash = common.Hash{}
+	enc, _ := json.Marshal(&cpy)
+	return crypto.Keccak256Hash(enc)
+}
+
+// Log is an append-only audit log file.
+type Log struct {
+	path    string
+	chained bool
+
+	mu   sync.Mutex
+	file *os.File
+	next uint64      // Sequence number of the next record
+	last common.Hash // Hash of the last record, zero if it's not hash-chained
+}
+
+// Open opens the audit log at path, creating it if it doesn't exist. If chained is
+// set, the records appended are hash-chained.
+func Open(path string, chained bool) (*Log, error) {
+	l := &Log{path: path, chained: chained}
+	// Resume the sequence and the hash chain of the existing records
+	err := l.iterate(func(r *Record) error {
+		l.next, l.last = r.Seq+1, r.Hash
+		return nil
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	l.file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Close closes the audit log file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.file.Close()
+}
+
+// Append appends a record to the log, setting its sequence number and, if the
+// log is hash-chained, its hashes. The record is synced to disk before returning.
+func (l *Log) Append(r *Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r.Seq = l.next
+	r.Prev, r.Hash = common.Hash{}, common.Hash{}
+	if l.chained {
+		r.Prev = l.last
+		r.Hash = r.hash()
+	}
+	enc, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := l.file.Write(append(enc, '\n')); err != nil {
+		return err
+	}
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+	l.next, l.last = r.Seq+1, r.Hash
+	return nil
+}
+
+// RecordSign records a signing operation requested through ctx, rejected if err
+// is set. Failures to write the log are logged, but don't fail the operation.
+func (l *Log) RecordSign(ctx context.Context, account common.Address, kind string, digest common.Hash, err error) {
+	r := &Record{
+		Time:     time.Now().UTC(),
+		Account:  account,
+		Kind:     kind,
+		Digest:   digest,
+		Decision: DecisionSigned,
+	}
+	if info := rpc.PeerInfoFromContext(ctx); info.Transport != "" {
+		r.Transport, r.Requester = info.Transport, info.RemoteAddr
+	}
+	if err != nil {
+		r.Decision, r.Error = DecisionRejected, err.Error()
+	}
+	if err := l.Append(r); err != nil {
+		log.Error("Failed to write signing audit log", "account", account, "kind", kind, "err", err)
+	}
+}
+
+// Records returns up to count records of the log, starting with the record with
+// sequence number from.
+func (l *Log) Records(from uint64, count int) ([]*Record, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var records []*Record
+	err := l.iterate(func(r *Record) error {
+		if r.Seq >= from && len(records) < count {
+			records = append(records, r)
+		}
+		return nil
+	})
+	return records, err
+}
+
+// Verify checks the sequence numbers and the hash chain of the log, returning the
+// number of records. Records appended without hash chaining are only checked for
+// their sequence numbers.
+func (l *Log) Verify() (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var (
+		n    uint64
+		last common.Hash
+	)
+	err := l.iterate(func(r *Record) error {
+		if r.Seq != n {
+			return fmt.Errorf("record %d: unexpected sequence number %d", n, r.Seq)
+		}
+		if r.Hash != (common.Hash{}) {
+			if r.Prev != last {
+				return fmt.Errorf("record %d: hash chain broken", r.Seq)
+			}
+			if r.hash() != r.Hash {
+				return fmt.Errorf("record %d: hash mismatch", r.Seq)
+			}
+		}
+		n, last = n+1, r.Hash
+		return nil
+	})
+	return n, err
+}
+
+// iterate calls fn with the records of the log file in order.
+func (l *Log) iterate(fn func(*Record) error) error {
+	f, err := os.Open(l.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	for line := 0; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(bytes.TrimSpace(data)) != 0 {
+				return fmt.Errorf("audit log %s: incomplete record on line %d", l.path, line+1)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		r := new(Record)
+		if err := json.Unmarshal(data, r); err != nil {
+			return fmt.Errorf("audit log %s: invalid record on line %d: %v", l.path, line+1, err)
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+}
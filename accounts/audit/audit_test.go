@@ -0,0 +1,100 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := Open(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.RecordSign(context.Background(), common.Address{1}, KindText, common.Hash{1}, nil)
+	l.Close()
+
+	// Reopen with hash chaining, the sequence continues
+	if l, err = Open(path, true); err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	l.RecordSign(context.Background(), common.Address{1}, KindTransaction, common.Hash{2}, errors.New("locked"))
+	l.RecordSign(context.Background(), common.Address{2}, KindTransaction, common.Hash{3}, nil)
+
+	records, err := l.Records(1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Seq != 1 || records[1].Seq != 2 {
+		t.Fatalf("wrong records %+v", records)
+	}
+	if r := records[0]; r.Decision != DecisionRejected || r.Error != "locked" || r.Digest != (common.Hash{2}) || r.Prev != (common.Hash{}) {
+		t.Fatalf("wrong rejected record %+v", r)
+	}
+	if r := records[1]; r.Decision != DecisionSigned || r.Prev != records[0].Hash || r.Hash == (common.Hash{}) {
+		t.Fatalf("wrong chained record %+v", r)
+	}
+	if records, _ := l.Records(0, 1); len(records) != 1 || records[0].Seq != 0 {
+		t.Fatalf("wrong limited records %+v", records)
+	}
+	if n, err := l.Verify(); err != nil || n != 3 {
+		t.Fatalf("verification failed: %d records, %v", n, err)
+	}
+}
+
+func TestLogTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := Open(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		l.RecordSign(context.Background(), common.Address{1}, KindData, common.Hash{byte(i)}, nil)
+	}
+	l.Close()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+
+	tests := map[string][]byte{
+		"altered": bytes.Replace(data, []byte(`"kind":"data"`), []byte(`"kind":"text"`), 1),
+		"removed": append(append([]byte{}, lines[0]...), lines[2]...),
+		"torn":    data[:len(data)-10],
+	}
+	for name, tampered := range tests {
+		if err := os.WriteFile(path, tampered, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if l, err := Open(path, true); err == nil {
+			if _, err := l.Verify(); err == nil {
+				t.Errorf("%s log verified", name)
+			}
+			l.Close()
+		}
+	}
+}
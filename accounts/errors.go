@@ -44,6 +44,10 @@ var ErrWalletAlreadyOpen = errors.New("wallet already open")
 // ErrWalletClosed is returned if a wallet is offline.
 var ErrWalletClosed = errors.New("wallet closed")
 
+// ErrUnprotectedTx is returned when signing or sending a transaction without
+// replay protection (EIP-155) from an account requiring it.
+var ErrUnprotectedTx = errors.New("transaction not replay-protected (EIP-155)")
+
 // AuthNeededError is returned by backends for signing requests where the user
 // is required to provide further authentication before signing can succeed.
 //
@@ -178,3 +178,66 @@ func LedgerLiveIterator(base DerivationPath) func() DerivationPath {
 		return path
 	}
 }
+
+// DerivationTemplate is a derivation path in which one component is the index of
+// the account, marked with an 'x' in its textual form. The default scheme is the
+// template m/44'/60'/0'/0/x, the Ledger Live scheme is m/44'/60'/x'/0/0.
+type DerivationTemplate struct {
+	Base  DerivationPath // Path of the first account
+	Index int            // Component incremented for each subsequent account
+}
+
+// ParseDerivationTemplate converts a user specified derivation path template to
+// the internal binary representation. Templates follow the format of derivation
+// paths, with exactly one component replaced by 'x' (or x' if hardened). If no
+// component is marked, the last one is the account index.
+func ParseDerivationTemplate(template string) (DerivationTemplate, error) {
+	var (
+		components = strings.Split(template, "/")
+		marked     = -1
+	)
+	for i, component := range components {
+		if strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(component), "'")) == "x" {
+			if marked >= 0 {
+				return DerivationTemplate{}, errors.New("multiple account index components")
+			}
+			marked = i
+			components[i] = strings.Replace(component, "x", "0", 1)
+		}
+	}
+	path, err := ParseDerivationPath(strings.Join(components, "/"))
+	if err != nil {
+		return DerivationTemplate{}, err
+	}
+	index := len(path) - 1
+	if marked >= 0 {
+		// Relative paths are appended to the root path, count from the end
+		index = len(path) - (len(components) - marked)
+	}
+	return DerivationTemplate{Base: path, Index: index}, nil
+}
+
+// String implements the stringer interface, converting a binary derivation path
+// template to its canonical representation.
+func (t DerivationTemplate) String() string {
+	components := strings.Split(t.Base.String(), "/")
+	if component := components[t.Index+1]; strings.HasSuffix(component, "'") {
+		components[t.Index+1] = "x'"
+	} else {
+		components[t.Index+1] = "x"
+	}
+	return strings.Join(components, "/")
+}
+
+// Iterator creates a path iterator, which progresses by increasing the account
+// index component of the template.
+func (t DerivationTemplate) Iterator() func() DerivationPath {
+	path := make(DerivationPath, len(t.Base))
+	copy(path[:], t.Base[:])
+	// Set it back by one, so the first call gives the first result
+	path[t.Index]--
+	return func() DerivationPath {
+		path[t.Index]++
+		return path
+	}
+}
@@ -118,3 +118,44 @@ func TestHdPathIteration(t *testing.T) {
 			"m/44'/60'/8'/0/0", "m/44'/60'/9'/0/0",
 		})
 }
+
+func TestHDTemplateParsing(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input  string
+		output string
+		index  int
+	}{
+		{"m/44'/60'/0'/0/x", "m/44'/60'/0'/0/x", 4},
+		{"m/44'/60'/x'/0/0", "m/44'/60'/x'/0/0", 2},
+		{"m/44'/60'/0'/x", "m/44'/60'/0'/x", 3},
+		{"m/44'/60'/0'/0/0", "m/44'/60'/0'/0/x", 4},
+		{"x", "m/44'/60'/0'/0/x", 4},
+		{"x/0", "m/44'/60'/0'/0/x/0", 4},
+		{" m / 44' / 60' / x ' / 0 / 0 ", "m/44'/60'/x'/0/0", 2},
+	}
+	for i, tt := range tests {
+		template, err := ParseDerivationTemplate(tt.input)
+		if err != nil {
+			t.Errorf("test %d: %q: unexpected error: %v", i, tt.input, err)
+			continue
+		}
+		if template.String() != tt.output || template.Index != tt.index {
+			t.Errorf("test %d: %q: template mismatch: have %v (index %d), want %v (index %d)", i, tt.input, template, template.Index, tt.output, tt.index)
+		}
+	}
+	for _, input := range []string{"", "m/x/x", "m/44'/60'/y/0", "/x"} {
+		if _, err := ParseDerivationTemplate(input); err == nil {
+			t.Errorf("%q: no error", input)
+		}
+	}
+}
+
+func TestHDTemplateIteration(t *testing.T) {
+	t.Parallel()
+	template, _ := ParseDerivationTemplate("m/44'/60'/x'/0/0")
+	testDerive(t, template.Iterator(), []string{"m/44'/60'/0'/0/0", "m/44'/60'/1'/0/0", "m/44'/60'/2'/0/0"})
+
+	template, _ = ParseDerivationTemplate("m/44'/60'/0'/x/7")
+	testDerive(t, template.Iterator(), []string{"m/44'/60'/0'/0/7", "m/44'/60'/0'/1/7", "m/44'/60'/0'/2/7"})
+}
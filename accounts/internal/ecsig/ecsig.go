@@ -0,0 +1,78 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package ecsig converts the ECDSA signatures produced by external signers, like
+// key management services and hardware security modules, into the [R || S || V]
+// format used by Ethereum.
+package ecsig
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1halfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+// FromDER converts a DER encoded ECDSA signature of hash, made by the key of
+// pubkey, into the [R || S || V] format.
+func FromDER(der []byte, hash []byte, pubkey *ecdsa.PublicKey) ([]byte, error) {
+	var sig struct{ R, S *big.Int }
+	if rest, err := asn1.Unmarshal(der, &sig); err != nil || len(rest) > 0 {
+		return nil, fmt.Errorf("invalid signature encoding: %v", err)
+	}
+	return fromRS(sig.R, sig.S, hash, pubkey)
+}
+
+// FromRS converts an ECDSA signature of hash in the [R || S] format, made by the
+// key of pubkey, into the [R || S || V] format.
+func FromRS(rs []byte, hash []byte, pubkey *ecdsa.PublicKey) ([]byte, error) {
+	if len(rs) != 64 {
+		return nil, fmt.Errorf("invalid signature length %d", len(rs))
+	}
+	return fromRS(new(big.Int).SetBytes(rs[:32]), new(big.Int).SetBytes(rs[32:]), hash, pubkey)
+}
+
+// fromRS normalizes S to the lower half of the curve order, as required by
+// Ethereum, and finds the recovery ID by recovering the public key.
+func fromRS(r, s *big.Int, hash []byte, pubkey *ecdsa.PublicKey) ([]byte, error) {
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(secp256k1N) >= 0 || s.Cmp(secp256k1N) >= 0 {
+		return nil, errors.New("invalid signature values")
+	}
+	if s.Cmp(secp256k1halfN) > 0 {
+		s = new(big.Int).Sub(secp256k1N, s)
+	}
+	out := make([]byte, crypto.SignatureLength)
+	r.FillBytes(out[:32])
+	s.FillBytes(out[32:64])
+
+	want := crypto.FromECDSAPub(pubkey)
+	for v := byte(0); v < 2; v++ {
+		out[crypto.RecoveryIDOffset] = v
+		if pub, err := crypto.Ecrecover(hash, out); err == nil && bytes.Equal(pub, want) {
+			return out, nil
+		}
+	}
+	return nil, errors.New("signature doesn't match the public key")
+}
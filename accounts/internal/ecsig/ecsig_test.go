@@ -0,0 +1,66 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ecsig
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestConvert(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	hash := crypto.Keccak256([]byte("foo"))
+
+	want, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, s := new(big.Int).SetBytes(want[:32]), new(big.Int).SetBytes(want[32:64])
+	highS := new(big.Int).Sub(secp256k1N, s)
+
+	for _, s := range []*big.Int{s, highS} {
+		der, _ := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+		if sig, err := FromDER(der, hash, &key.PublicKey); err != nil || !bytes.Equal(sig, want) {
+			t.Errorf("wrong signature from DER: %x, %v", sig, err)
+		}
+		rs := make([]byte, 64)
+		r.FillBytes(rs[:32])
+		s.FillBytes(rs[32:])
+		if sig, err := FromRS(rs, hash, &key.PublicKey); err != nil || !bytes.Equal(sig, want) {
+			t.Errorf("wrong signature from R || S: %x, %v", sig, err)
+		}
+		if _, err := FromRS(rs, hash, &other.PublicKey); err == nil {
+			t.Error("signature of another key accepted")
+		}
+	}
+	// Malformed signatures are rejected.
+	if _, err := FromDER([]byte{0x30, 0x00}, hash, &key.PublicKey); err == nil {
+		t.Error("invalid DER accepted")
+	}
+	if _, err := FromRS(want[:63], hash, &key.PublicKey); err == nil {
+		t.Error("short signature accepted")
+	}
+	der, _ := asn1.Marshal(struct{ R, S *big.Int }{r, secp256k1N})
+	if _, err := FromDER(der, hash, &key.PublicKey); err == nil {
+		t.Error("out of range S accepted")
+	}
+}
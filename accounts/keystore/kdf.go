@@ -0,0 +1,219 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// KDFScrypt is the name of the scrypt key derivation function, the default.
+	KDFScrypt = "scrypt"
+
+	// KDFArgon2id is the name of the argon2id key derivation function.
+	KDFArgon2id = "argon2id"
+
+	// DefaultArgon2Time, DefaultArgon2Memory and DefaultArgon2Threads are the
+	// argon2id parameters recommended by RFC 9106 for memory constrained
+	// environments, using 64MB memory.
+	DefaultArgon2Time    = 3
+	DefaultArgon2Memory  = 64 * 1024 // KiB
+	DefaultArgon2Threads = 4
+
+	// Limits of the parameters picked by CalibrateKDF.
+	minCalibratedScryptN = 1 << 12
+	maxCalibratedScryptN = 1 << 22
+	maxCalibratedTime    = 64
+)
+
+// KDFConfig is the key derivation function, and its parameters, deriving the
+// encryption keys of key files from passwords.
+type KDFConfig struct {
+	Name string // KDFScrypt or KDFArgon2id
+
+	ScryptN int // CPU/memory cost of scrypt
+	ScryptR int // Block size of scrypt
+	ScryptP int // Parallelization of scrypt
+
+	Argon2Time    uint32 // Number of passes of argon2id
+	Argon2Memory  uint32 // Memory used by argon2id, in KiB
+	Argon2Threads uint8  // Parallelism of argon2id
+}
+
+// ScryptKDF returns the scrypt configuration with the given N and P parameters.
+func ScryptKDF(scryptN, scryptP int) KDFConfig {
+	return KDFConfig{Name: KDFScrypt, ScryptN: scryptN, ScryptR: scryptR, ScryptP: scryptP}
+}
+
+// Argon2idKDF returns the argon2id configuration with the given parameters.
+func Argon2idKDF(time, memory uint32, threads uint8) KDFConfig {
+	return KDFConfig{Name: KDFArgon2id, Argon2Time: time, Argon2Memory: memory, Argon2Threads: threads}
+}
+
+// ParseKDFConfig parses a key derivation function configuration of the form
+// "scrypt:n=262144,r=8,p=1" or "argon2id:t=3,m=65536,p=4", where m is in KiB.
+// Parameters that are not given take the default values.
+func ParseKDFConfig(spec string) (KDFConfig, error) {
+	name, params, _ := strings.Cut(spec, ":")
+	name = strings.TrimSpace(name)
+
+	var fields map[string]uint64
+	switch name {
+	case KDFScrypt:
+		fields = map[string]uint64{"n": StandardScryptN, "r": scryptR, "p": StandardScryptP}
+	case KDFArgon2id:
+		fields = map[string]uint64{"t": DefaultArgon2Time, "m": DefaultArgon2Memory, "p": DefaultArgon2Threads}
+	default:
+		return KDFConfig{}, fmt.Errorf("unknown KDF %q", name)
+	}
+	if params = strings.TrimSpace(params); params != "" {
+		for _, param := range strings.Split(params, ",") {
+			key, value, ok := strings.Cut(param, "=")
+			key = strings.TrimSpace(key)
+			if _, known := fields[key]; !ok || !known {
+				return KDFConfig{}, fmt.Errorf("invalid %s parameter %q", name, param)
+			}
+			v, err := strconv.ParseUint(strings.TrimSpace(value), 0, 32)
+			if err != nil {
+				return KDFConfig{}, fmt.Errorf("invalid %s parameter %q: %v", name, param, err)
+			}
+			fields[key] = v
+		}
+	}
+	var config KDFConfig
+	if name == KDFScrypt {
+		config = ScryptKDF(int(fields["n"]), int(fields["p"]))
+		config.ScryptR = int(fields["r"])
+	} else {
+		if fields["p"] > 255 {
+			return KDFConfig{}, errors.New("argon2id parallelism must be at most 255")
+		}
+		config = Argon2idKDF(uint32(fields["t"]), uint32(fields["m"]), uint8(fields["p"]))
+	}
+	return config, config.validate()
+}
+
+// String returns the configuration in the format accepted by ParseKDFConfig.
+func (c KDFConfig) String() string {
+	if c.Name == KDFArgon2id {
+		return fmt.Sprintf("%s:t=%d,m=%d,p=%d", c.Name, c.Argon2Time, c.Argon2Memory, c.Argon2Threads)
+	}
+	return fmt.Sprintf("%s:n=%d,r=%d,p=%d", KDFScrypt, c.ScryptN, c.ScryptR, c.ScryptP)
+}
+
+// validate checks that the parameters are usable by the key derivation function.
+func (c KDFConfig) validate() error {
+	switch c.Name {
+	case KDFScrypt:
+		if c.ScryptN <= 1 || c.ScryptN&(c.ScryptN-1) != 0 {
+			return errors.New("scrypt N must be a power of two greater than 1")
+		}
+		if c.ScryptR <= 0 || c.ScryptP <= 0 || uint64(c.ScryptR)*uint64(c.ScryptP) >= 1<<30 {
+			return errors.New("scrypt r and p must be positive, with r*p < 2^30")
+		}
+	case KDFArgon2id:
+		if c.Argon2Time == 0 || c.Argon2Threads == 0 {
+			return errors.New("argon2id time and parallelism must be positive")
+		}
+		if c.Argon2Memory < 8*uint32(c.Argon2Threads) {
+			return errors.New("argon2id memory must be at least 8KiB per thread")
+		}
+	default:
+		return fmt.Errorf("unknown KDF %q", c.Name)
+	}
+	return nil
+}
+
+// deriveKey derives a key of length dkLen from the password and salt.
+func (c KDFConfig) deriveKey(auth, salt []byte, dkLen int) ([]byte, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	if c.Name == KDFArgon2id {
+		return argon2.IDKey(auth, salt, c.Argon2Time, c.Argon2Memory, c.Argon2Threads, uint32(dkLen)), nil
+	}
+	return scrypt.Key(auth, salt, c.ScryptN, c.ScryptR, c.ScryptP, dkLen)
+}
+
+// params returns the kdfparams of the key file.
+func (c KDFConfig) params(salt []byte, dkLen int) map[string]interface{} {
+	params := map[string]interface{}{
+		"dklen": dkLen,
+		"salt":  fmt.Sprintf("%x", salt),
+	}
+	if c.Name == KDFArgon2id {
+		params["t"] = c.Argon2Time
+		params["m"] = c.Argon2Memory
+		params["p"] = c.Argon2Threads
+	} else {
+		params["n"] = c.ScryptN
+		params["r"] = c.ScryptR
+		params["p"] = c.ScryptP
+	}
+	return params
+}
+
+// CalibrateKDF benchmarks the key derivation function on this machine, returning
+// the strongest parameters deriving a key within the target time. The memory of
+// scrypt grows with its cost, argon2id uses 64MB and increases the passes.
+func CalibrateKDF(name string, target time.Duration) (KDFConfig, error) {
+	var (
+		salt    = make([]byte, 32)
+		measure = func(c KDFConfig) (time.Duration, error) {
+			start := time.Now()
+			_, err := c.deriveKey([]byte("calibration"), salt, scryptDKLen)
+			return time.Since(start), err
+		}
+	)
+	switch name {
+	case KDFScrypt:
+		config := ScryptKDF(minCalibratedScryptN, 1)
+		for config.ScryptN < maxCalibratedScryptN {
+			elapsed, err := measure(config)
+			if err != nil {
+				return KDFConfig{}, err
+			}
+			// Doubling N doubles the time, stop before overshooting
+			if 2*elapsed > target {
+				break
+			}
+			config.ScryptN *= 2
+		}
+		return config, nil
+
+	case KDFArgon2id:
+		config := Argon2idKDF(1, DefaultArgon2Memory, uint8(min(runtime.NumCPU(), DefaultArgon2Threads)))
+		elapsed, err := measure(config)
+		if err != nil {
+			return KDFConfig{}, err
+		}
+		// The time grows linearly with the passes
+		if elapsed > 0 {
+			config.Argon2Time = uint32(max(1, min(int64(target/elapsed), maxCalibratedTime)))
+		}
+		return config, nil
+	}
+	return KDFConfig{}, fmt.Errorf("unknown KDF %q", name)
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseKDFConfig(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		spec string
+		want KDFConfig
+	}{
+		{"scrypt", ScryptKDF(StandardScryptN, StandardScryptP)},
+		{"scrypt:n=4096,p=6", ScryptKDF(LightScryptN, LightScryptP)},
+		{"scrypt: n=1024, r=16", KDFConfig{Name: KDFScrypt, ScryptN: 1024, ScryptR: 16, ScryptP: 1}},
+		{"argon2id", Argon2idKDF(DefaultArgon2Time, DefaultArgon2Memory, DefaultArgon2Threads)},
+		{"argon2id:t=1,m=1024,p=2", Argon2idKDF(1, 1024, 2)},
+	}
+	for _, tt := range tests {
+		have, err := ParseKDFConfig(tt.spec)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.spec, err)
+			continue
+		}
+		if have != tt.want {
+			t.Errorf("%q: have %v, want %v", tt.spec, have, tt.want)
+		}
+		if again, _ := ParseKDFConfig(have.String()); again != have {
+			t.Errorf("%q: string form %q doesn't round trip", tt.spec, have)
+		}
+	}
+	for _, spec := range []string{"", "pbkdf2", "scrypt:n=1000", "scrypt:x=1", "scrypt:n", "argon2id:t=0", "argon2id:p=256", "argon2id:m=8,p=2"} {
+		if _, err := ParseKDFConfig(spec); err == nil {
+			t.Errorf("%q: no error", spec)
+		}
+	}
+}
+
+// Tests that keys encrypted with argon2id can be decrypted.
+func TestKeyEncryptDecryptArgon2id(t *testing.T) {
+	t.Parallel()
+	key, err := newKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyjson, err := EncryptKeyWithKDF(key, "password", Argon2idKDF(1, 64, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored encryptedKeyJSONV3
+	if err := json.Unmarshal(keyjson, &stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored.Crypto.KDF != KDFArgon2id || ensureInt(stored.Crypto.KDFParams["m"]) != 64 {
+		t.Errorf("wrong kdf in key file: %s %v", stored.Crypto.KDF, stored.Crypto.KDFParams)
+	}
+	if _, err := DecryptKey(keyjson, "bad"); err == nil {
+		t.Error("json key decrypted with bad password")
+	}
+	decrypted, err := DecryptKey(keyjson, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted.Address != key.Address {
+		t.Errorf("address mismatch: have %x, want %x", decrypted.Address, key.Address)
+	}
+}
+
+func TestCalibrateKDF(t *testing.T) {
+	t.Parallel()
+	for _, name := range []string{KDFScrypt, KDFArgon2id} {
+		config, err := CalibrateKDF(name, 10*time.Millisecond)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if config.Name != name || config.validate() != nil {
+			t.Errorf("%s: invalid calibrated config %v", name, config)
+		}
+	}
+	if config, _ := CalibrateKDF(KDFScrypt, 0); config.ScryptN != minCalibratedScryptN {
+		t.Errorf("scrypt cost %d below the minimum", config.ScryptN)
+	}
+}
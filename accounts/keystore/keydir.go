@@ -0,0 +1,150 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	// ErrReadOnly is returned when modifying a key of a read-only directory.
+	ErrReadOnly = errors.New("key directory is read-only")
+
+	// ErrUnlockDenied is returned when the policy of the key directory doesn't
+	// allow the requested unlock.
+	ErrUnlockDenied = errors.New("unlock denied by key directory policy")
+)
+
+// KeyDir is an additional key directory of a keystore, such as a mounted secrets
+// volume. New keys are always stored in the primary directory of the keystore.
+type KeyDir struct {
+	Path      string
+	ReadOnly  bool          // Keys are never modified or deleted
+	NoUnlock  bool          // Keys can't be unlocked, only used with a passphrase
+	MaxUnlock time.Duration // Longest unlock of a key, 0 for no limit
+}
+
+// ParseKeyDir parses a key directory in the form "path[:option]...". The options
+// are "ro" for read-only directories, "nounlock" to forbid unlocking its keys, and
+// "maxunlock=<duration>" to limit the duration of unlocks.
+func ParseKeyDir(spec string) (KeyDir, error) {
+	var dir KeyDir
+	for {
+		i := strings.LastIndexByte(spec, ':')
+		if i < 0 {
+			break
+		}
+		opt := spec[i+1:]
+		switch {
+		case opt == "ro":
+			dir.ReadOnly = true
+		case opt == "nounlock":
+			dir.NoUnlock = true
+		case strings.HasPrefix(opt, "maxunlock="):
+			d, err := time.ParseDuration(strings.TrimPrefix(opt, "maxunlock="))
+			if err != nil || d <= 0 {
+				return KeyDir{}, fmt.Errorf("invalid unlock limit %q", opt)
+			}
+			dir.MaxUnlock = d
+		default:
+			// Not an option, the colon is part of the path (e.g. a drive letter)
+			dir.Path = spec
+			return dir, validateKeyDir(dir)
+		}
+		spec = spec[:i]
+	}
+	dir.Path = spec
+	return dir, validateKeyDir(dir)
+}
+
+func validateKeyDir(dir KeyDir) error {
+	if dir.Path == "" {
+		return errors.New("empty key directory path")
+	}
+	if dir.NoUnlock && dir.MaxUnlock != 0 {
+		return fmt.Errorf("key directory %s: nounlock conflicts with maxunlock", dir.Path)
+	}
+	return nil
+}
+
+// String returns the key directory in the format of ParseKeyDir.
+func (dir KeyDir) String() string {
+	s := dir.Path
+	if dir.ReadOnly {
+		s += ":ro"
+	}
+	if dir.NoUnlock {
+		s += ":nounlock"
+	}
+	if dir.MaxUnlock != 0 {
+		s += ":maxunlock=" + dir.MaxUnlock.String()
+	}
+	return s
+}
+
+// checkUnlock returns an error if the policy of the directory doesn't allow
+// unlocking its keys for the given duration, 0 being indefinitely.
+func (dir *KeyDir) checkUnlock(timeout time.Duration) error {
+	switch {
+	case dir.NoUnlock:
+		return fmt.Errorf("%w: keys of %s can't be unlocked", ErrUnlockDenied, dir.Path)
+	case dir.MaxUnlock != 0 && (timeout == 0 || timeout > dir.MaxUnlock):
+		return fmt.Errorf("%w: keys of %s can be unlocked for %v at most", ErrUnlockDenied, dir.Path, dir.MaxUnlock)
+	}
+	return nil
+}
+
+// keyDir is a key directory with its account cache.
+type keyDir struct {
+	KeyDir
+	cache *accountCache
+}
+
+// contains reports whether the key file of the account is in the directory.
+func (dir *keyDir) contains(a accounts.Account) bool {
+	return filepath.Dir(a.URL.Path) == dir.Path
+}
+
+// mergeAccounts merges the accounts of key directories, sorted by URL. Addresses
+// with keys in several directories only resolve to the keys of the first of them.
+func mergeAccounts(dirs []*keyDir) []accounts.Account {
+	var (
+		all  []accounts.Account
+		seen = make(map[common.Address]bool)
+	)
+	for _, dir := range dirs {
+		accs := dir.cache.accounts()
+		for _, a := range accs {
+			if !seen[a.Address] {
+				all = append(all, a)
+			}
+		}
+		for _, a := range accs {
+			seen[a.Address] = true
+		}
+	}
+	slices.SortFunc(all, byURL)
+	return all
+}
@@ -0,0 +1,147 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestParseKeyDir(t *testing.T) {
+	tests := []struct {
+		spec string
+		want KeyDir
+		err  bool
+	}{
+		{spec: "/keys", want: KeyDir{Path: "/keys"}},
+		{spec: "/keys:ro", want: KeyDir{Path: "/keys", ReadOnly: true}},
+		{spec: "/keys:ro:nounlock", want: KeyDir{Path: "/keys", ReadOnly: true, NoUnlock: true}},
+		{spec: "/keys:maxunlock=5m:ro", want: KeyDir{Path: "/keys", ReadOnly: true, MaxUnlock: 5 * time.Minute}},
+		{spec: `C:\keys:ro`, want: KeyDir{Path: `C:\keys`, ReadOnly: true}},
+		{spec: "/a:b:ro", want: KeyDir{Path: "/a:b", ReadOnly: true}},
+		{spec: ":ro", err: true},
+		{spec: "/keys:maxunlock=0s", err: true},
+		{spec: "/keys:nounlock:maxunlock=1m", err: true},
+	}
+	for _, tt := range tests {
+		dir, err := ParseKeyDir(tt.spec)
+		if (err != nil) != tt.err {
+			t.Errorf("%q: wrong error %v", tt.spec, err)
+			continue
+		}
+		if err == nil && dir != tt.want {
+			t.Errorf("%q: have %+v, want %+v", tt.spec, dir, tt.want)
+		}
+		if err == nil {
+			if again, _ := ParseKeyDir(dir.String()); again != dir {
+				t.Errorf("%q: string %q doesn't round-trip", tt.spec, dir.String())
+			}
+		}
+	}
+}
+
+// storeTestKey stores a key in a key directory, outside of the keystore under test.
+func storeTestKey(t *testing.T, dir string, key *ecdsa.PrivateKey) accounts.Account {
+	a, err := NewKeyStore(dir, veryLightScryptN, veryLightScryptP).ImportECDSA(key, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestKeyStoreDirs(t *testing.T) {
+	var (
+		primary  = t.TempDir()
+		secrets  = t.TempDir()
+		timed    = t.TempDir()
+		shared   = newKeyForTest(t)
+		secret   = newKeyForTest(t)
+		timedKey = newKeyForTest(t)
+	)
+	primaryShared := storeTestKey(t, primary, shared)
+	storeTestKey(t, secrets, shared) // shadowed by the primary directory
+	secretAcc := storeTestKey(t, secrets, secret)
+	timedAcc := storeTestKey(t, timed, timedKey)
+
+	ks := NewKeyStoreWithDirs(primary, ScryptKDF(veryLightScryptN, veryLightScryptP), []KeyDir{
+		{Path: secrets, ReadOnly: true, NoUnlock: true},
+		{Path: timed, MaxUnlock: time.Minute},
+	})
+	if accs := ks.Accounts(); len(accs) != 3 {
+		t.Fatalf("wrong accounts %v", accs)
+	}
+	if a, err := ks.Find(accounts.Account{Address: primaryShared.Address}); err != nil || a != primaryShared {
+		t.Fatalf("shared address not resolved to the primary directory: %v %v", a, err)
+	}
+	// Keys of read-only directories can't be modified
+	if err := ks.Delete(secretAcc, "pass"); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("wrong error deleting read-only key: %v", err)
+	}
+	if err := ks.Update(secretAcc, "pass", "new"); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("wrong error updating read-only key: %v", err)
+	}
+	if _, err := ks.ImportECDSA(secret, "pass"); err != ErrAccountAlreadyExists {
+		t.Fatalf("wrong error importing key of another directory: %v", err)
+	}
+	// Unlock policies apply by directory
+	if err := ks.Unlock(secretAcc, "pass"); !errors.Is(err, ErrUnlockDenied) {
+		t.Fatalf("wrong error unlocking key of nounlock directory: %v", err)
+	}
+	if _, err := ks.SignHashWithPassphrase(secretAcc, "pass", make([]byte, 32)); err != nil {
+		t.Fatalf("can't sign with passphrase: %v", err)
+	}
+	if err := ks.Unlock(timedAcc, "pass"); !errors.Is(err, ErrUnlockDenied) {
+		t.Fatalf("wrong error unlocking timed key indefinitely: %v", err)
+	}
+	if err := ks.TimedUnlock(timedAcc, "pass", time.Hour); !errors.Is(err, ErrUnlockDenied) {
+		t.Fatalf("wrong error unlocking timed key beyond the limit: %v", err)
+	}
+	if err := ks.TimedUnlock(timedAcc, "pass", time.Minute); err != nil {
+		t.Fatalf("can't unlock timed key: %v", err)
+	}
+	if err := ks.Unlock(primaryShared, "pass"); err != nil {
+		t.Fatalf("can't unlock primary key: %v", err)
+	}
+	// New keys go into the primary directory, keys of writable directories can be deleted
+	a, err := ks.NewAccount("pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(a.URL.Path) != ks.dirs[0].Path {
+		t.Fatalf("new key stored in %s", a.URL.Path)
+	}
+	if err := ks.Delete(timedAcc, "pass"); err != nil {
+		t.Fatalf("can't delete key of writable directory: %v", err)
+	}
+	if ks.HasAddress(timedAcc.Address) {
+		t.Fatal("deleted key still present")
+	}
+}
+
+func newKeyForTest(t *testing.T) *ecdsa.PrivateKey {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
@@ -29,6 +29,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 	"sync"
 	"time"
 
@@ -62,7 +63,8 @@ const walletRefreshCycle = 3 * time.Second
 type KeyStore struct {
 	storage  keyStore                     // Storage backend, might be cleartext or encrypted
 	cache    *accountCache                // In-memory account cache over the filesystem storage
-	changes  chan struct{}                // Channel receiving change notifications from the cache
+	dirs     []*keyDir                    // Key directories by precedence, starting with the primary one
+	changes  chan struct{}                // Channel receiving change notifications from the caches
 	unlocked map[common.Address]*unlocked // Currently unlocked account (decrypted private keys)
 
 	wallets     []accounts.Wallet       // Wallet wrappers around the individual key files
@@ -76,34 +78,75 @@ type KeyStore struct {
 
 type unlocked struct {
 	*Key
-	abort chan struct{}
+	abort   chan struct{}
+	expires time.Time    // Zero if unlocked indefinitely
+	scope   *UnlockScope // Signing operations allowed, nil if unrestricted
 }
 
 // NewKeyStore creates a keystore for the given directory.
 func NewKeyStore(keydir string, scryptN, scryptP int) *KeyStore {
+	return NewKeyStoreWithKDF(keydir, ScryptKDF(scryptN, scryptP))
+}
+
+// NewKeyStoreWithKDF creates a keystore for the given directory, encrypting new
+// keys using the given key derivation function.
+func NewKeyStoreWithKDF(keydir string, kdf KDFConfig) *KeyStore {
+	return NewKeyStoreWithDirs(keydir, kdf, nil)
+}
+
+// NewKeyStoreWithDirs creates a keystore for the given primary directory and
+// additional key directories. New keys are stored in the primary directory. If
+// the same address has keys in several directories, only the keys of the primary
+// directory, or else of the first additional one listing it, are used.
+func NewKeyStoreWithDirs(keydir string, kdf KDFConfig, extra []KeyDir) *KeyStore {
 	keydir, _ = filepath.Abs(keydir)
-	ks := &KeyStore{storage: &keyStorePassphrase{keydir, scryptN, scryptP, false}}
-	ks.init(keydir)
+	ks := &KeyStore{storage: &keyStorePassphrase{keydir, kdf, false}}
+	ks.init(keydir, extra)
 	return ks
 }
 
-func (ks *KeyStore) init(keydir string) {
+func (ks *KeyStore) init(keydir string, extra []KeyDir) {
 	// Lock the mutex since the account cache might call back with events
 	ks.mu.Lock()
 	defer ks.mu.Unlock()
 
-	// Initialize the set of unlocked keys and the account cache
+	// Initialize the set of unlocked keys and the account caches
 	ks.unlocked = make(map[common.Address]*unlocked)
 	ks.cache, ks.changes = newAccountCache(keydir)
-
+	ks.dirs = []*keyDir{{KeyDir: KeyDir{Path: keydir}, cache: ks.cache}}
+
+	if len(extra) > 0 {
+		changes := []chan struct{}{ks.changes}
+		for _, dir := range extra {
+			dir.Path, _ = filepath.Abs(dir.Path)
+			cache, notify := newAccountCache(dir.Path)
+			ks.dirs = append(ks.dirs, &keyDir{KeyDir: dir, cache: cache})
+			changes = append(changes, notify)
+		}
+		// Forward the notifications of all caches, without referencing ks
+		merged := make(chan struct{}, 1)
+		for _, notify := range changes {
+			go func(notify chan struct{}) {
+				for range notify {
+					select {
+					case merged <- struct{}{}:
+					default:
+					}
+				}
+			}(notify)
+		}
+		ks.changes = merged
+	}
 	// TODO: In order for this finalizer to work, there must be no references
 	// to ks. addressCache doesn't keep a reference but unlocked keys do,
 	// so the finalizer will not trigger until all timed unlocks have expired.
 	runtime.SetFinalizer(ks, func(m *KeyStore) {
-		m.cache.close()
+		for _, dir := range m.dirs {
+			dir.cache.close()
+		}
 	})
 	// Create the initial list of wallets from the cache
-	accs := ks.cache.accounts()
+	accs := ks.allAccounts()
 	ks.wallets = make([]accounts.Wallet, len(accs))
 	for i := 0; i < len(accs); i++ {
 		ks.wallets[i] = &keystoreWallet{account: accs[i], keystore: ks}
@@ -129,7 +172,7 @@ func (ks *KeyStore) Wallets() []accounts.Wallet {
 func (ks *KeyStore) refreshWallets() {
 	// Retrieve the current list of accounts
 	ks.mu.Lock()
-	accs := ks.cache.accounts()
+	accs := ks.allAccounts()
 
 	// Transform the current list of wallets into the new one
 	var (
@@ -217,12 +260,35 @@ func (ks *KeyStore) updater() {
 
 // HasAddress reports whether a key with the given address is present.
 func (ks *KeyStore) HasAddress(addr common.Address) bool {
-	return ks.cache.hasAddress(addr)
+	for _, dir := range ks.dirs {
+		if dir.cache.hasAddress(addr) {
+			return true
+		}
+	}
+	return false
 }
 
-// Accounts returns all key files present in the directory.
+// Accounts returns all key files present in the directories.
 func (ks *KeyStore) Accounts() []accounts.Account {
-	return ks.cache.accounts()
+	return ks.allAccounts()
+}
+
+// allAccounts returns the accounts of all key directories.
+func (ks *KeyStore) allAccounts() []accounts.Account {
+	if len(ks.dirs) == 1 {
+		return ks.cache.accounts()
+	}
+	return mergeAccounts(ks.dirs)
+}
+
+// dirOf returns the key directory holding the key file of an account.
+func (ks *KeyStore) dirOf(a accounts.Account) *keyDir {
+	for _, dir := range ks.dirs[1:] {
+		if dir.contains(a) {
+			return dir
+		}
+	}
+	return ks.dirs[0]
 }
 
 // Delete deletes the key matched by account if the passphrase is correct.
@@ -238,12 +304,16 @@ func (ks *KeyStore) Delete(a accounts.Account, passphrase string) error {
 	if err != nil {
 		return err
 	}
+	dir := ks.dirOf(a)
+	if dir.ReadOnly {
+		return ErrReadOnly
+	}
 	// The order is crucial here. The key is dropped from the
 	// cache after the file is gone so that a reload happening in
 	// between won't insert it into the cache again.
 	err = os.Remove(a.URL.Path)
 	if err == nil {
-		ks.cache.delete(a)
+		dir.cache.delete(a)
 		ks.refreshWallets()
 	}
 	return err
@@ -251,7 +321,15 @@ func (ks *KeyStore) Delete(a accounts.Account, passphrase string) error {
 
 // SignHash calculates a ECDSA signature for the given hash. The produced
 // signature is in the [R || S || V] format where V is 0 or 1.
+//
+// Keys unlocked with a scope can't sign raw hashes, see ScopedUnlock.
 func (ks *KeyStore) SignHash(a accounts.Account, hash []byte) ([]byte, error) {
+	return ks.signHash(a, "", hash)
+}
+
+// signHash signs the hash of data of the given type, if allowed by the scope of
+// the unlock.
+func (ks *KeyStore) signHash(a accounts.Account, mimeType string, hash []byte) ([]byte, error) {
 	// Look up the key to sign with and abort if it cannot be found
 	ks.mu.RLock()
 	defer ks.mu.RUnlock()
@@ -260,6 +338,9 @@ func (ks *KeyStore) SignHash(a accounts.Account, hash []byte) ([]byte, error) {
 	if !found {
 		return nil, ErrLocked
 	}
+	if err := unlockedKey.scope.checkData(mimeType); err != nil {
+		return nil, err
+	}
 	// Sign the hash using plain ECDSA operations
 	return crypto.Sign(hash, unlockedKey.PrivateKey)
 }
@@ -274,6 +355,9 @@ func (ks *KeyStore) SignTx(a accounts.Account, tx *types.Transaction, chainID *b
 	if !found {
 		return nil, ErrLocked
 	}
+	if err := unlockedKey.scope.checkTx(tx); err != nil {
+		return nil, err
+	}
 	// Depending on the presence of the chain ID, sign with 2718 or homestead
 	signer := types.LatestSignerForChainID(chainID)
 	return types.SignTx(tx, signer, unlockedKey.PrivateKey)
@@ -327,7 +411,25 @@ func (ks *KeyStore) Lock(addr common.Address) error {
 // If the account address is already unlocked for a duration, TimedUnlock extends or
 // shortens the active unlock timeout. If the address was previously unlocked
 // indefinitely the timeout is not altered.
+//
+// The unlock policy of the key directory of the account may deny the unlock.
 func (ks *KeyStore) TimedUnlock(a accounts.Account, passphrase string, timeout time.Duration) error {
+	return ks.ScopedUnlock(a, passphrase, timeout, nil)
+}
+
+// ScopedUnlock unlocks the given account like TimedUnlock, restricting the signing
+// operations allowed with the key to the scope. A nil scope allows all of them.
+//
+// An unlock with a scope replaces any previous unlock of the address, including
+// indefinite ones, so that the key is never usable beyond the requested scope.
+func (ks *KeyStore) ScopedUnlock(a accounts.Account, passphrase string, timeout time.Duration, scope *UnlockScope) error {
+	a, err := ks.Find(a)
+	if err != nil {
+		return err
+	}
+	if err := ks.dirOf(a).checkUnlock(timeout); err != nil {
+		return err
+	}
 	a, key, err := ks.getDecryptedKey(a, passphrase)
 	if err != nil {
 		return err
@@ -337,32 +439,68 @@ func (ks *KeyStore) TimedUnlock(a accounts.Account, passphrase string, timeout t
 	defer ks.mu.Unlock()
 	u, found := ks.unlocked[a.Address]
 	if found {
-		if u.abort == nil {
+		if u.abort == nil && u.scope == nil && scope == nil {
 			// The address was unlocked indefinitely, so unlocking
 			// it with a timeout would be confusing.
 			zeroKey(key.PrivateKey)
 			return nil
 		}
-		// Terminate the expire goroutine and replace it below.
-		close(u.abort)
+		if u.abort != nil {
+			// Terminate the expire goroutine and replace it below.
+			close(u.abort)
+		}
+		zeroKey(u.PrivateKey)
+	}
+	if scope != nil {
+		scope = &UnlockScope{
+			MimeTypes:    slices.Clone(scope.MimeTypes),
+			Transactions: scope.Transactions,
+			Recipients:   slices.Clone(scope.Recipients),
+		}
 	}
 	if timeout > 0 {
-		u = &unlocked{Key: key, abort: make(chan struct{})}
+		u = &unlocked{Key: key, abort: make(chan struct{}), expires: time.Now().Add(timeout), scope: scope}
 		go ks.expire(a.Address, u, timeout)
 	} else {
-		u = &unlocked{Key: key}
+		u = &unlocked{Key: key, scope: scope}
 	}
 	ks.unlocked[a.Address] = u
 	return nil
 }
 
-// Find resolves the given account into a unique entry in the keystore.
+// UnlockSessions returns the unlocked accounts, sorted by address.
+func (ks *KeyStore) UnlockSessions() []UnlockSession {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	sessions := make([]UnlockSession, 0, len(ks.unlocked))
+	for addr, u := range ks.unlocked {
+		session := UnlockSession{Address: addr, Scope: u.scope}
+		if !u.expires.IsZero() {
+			expires := u.expires
+			session.Expires = &expires
+		}
+		sessions = append(sessions, session)
+	}
+	slices.SortFunc(sessions, func(a, b UnlockSession) int {
+		return a.Address.Cmp(b.Address)
+	})
+	return sessions
+}
+
+// Find resolves the given account into a unique entry in the keystore. The key
+// directories are searched by precedence.
 func (ks *KeyStore) Find(a accounts.Account) (accounts.Account, error) {
-	ks.cache.maybeReload()
-	ks.cache.mu.Lock()
-	a, err := ks.cache.find(a)
-	ks.cache.mu.Unlock()
-	return a, err
+	for _, dir := range ks.dirs {
+		dir.cache.maybeReload()
+		dir.cache.mu.Lock()
+		found, err := dir.cache.find(a)
+		dir.cache.mu.Unlock()
+		if err != ErrNoMatch {
+			return found, err
+		}
+	}
+	return accounts.Account{}, ErrNoMatch
 }
 
 func (ks *KeyStore) getDecryptedKey(a accounts.Account, auth string) (accounts.Account, *Key, error) {
@@ -414,13 +552,11 @@ func (ks *KeyStore) Export(a accounts.Account, passphrase, newPassphrase string)
 	if err != nil {
 		return nil, err
 	}
-	var N, P int
+	kdf := ScryptKDF(StandardScryptN, StandardScryptP)
 	if store, ok := ks.storage.(*keyStorePassphrase); ok {
-		N, P = store.scryptN, store.scryptP
-	} else {
-		N, P = StandardScryptN, StandardScryptP
+		kdf = store.kdf
 	}
-	return EncryptKey(key, newPassphrase, N, P)
+	return EncryptKeyWithKDF(key, newPassphrase, kdf)
 }
 
 // Import stores the given encrypted JSON key into the key directory.
@@ -435,7 +571,7 @@ func (ks *KeyStore) Import(keyJSON []byte, passphrase, newPassphrase string) (ac
 	ks.importMu.Lock()
 	defer ks.importMu.Unlock()
 
-	if ks.cache.hasAddress(key.Address) {
+	if ks.HasAddress(key.Address) {
 		return accounts.Account{
 			Address: key.Address,
 		}, ErrAccountAlreadyExists
@@ -449,7 +585,7 @@ func (ks *KeyStore) ImportECDSA(priv *ecdsa.PrivateKey, passphrase string) (acco
 	defer ks.importMu.Unlock()
 
 	key := newKeyFromECDSA(priv)
-	if ks.cache.hasAddress(key.Address) {
+	if ks.HasAddress(key.Address) {
 		return accounts.Account{
 			Address: key.Address,
 		}, ErrAccountAlreadyExists
@@ -473,6 +609,9 @@ func (ks *KeyStore) Update(a accounts.Account, passphrase, newPassphrase string)
 	if err != nil {
 		return err
 	}
+	if ks.dirOf(a).ReadOnly {
+		return ErrReadOnly
+	}
 	return ks.storage.StoreKey(a.URL.Path, key, newPassphrase)
 }
 
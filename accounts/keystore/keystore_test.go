@@ -17,6 +17,8 @@
 package keystore
 
 import (
+	"errors"
+	"math/big"
 	"math/rand"
 	"os"
 	"runtime"
@@ -29,6 +31,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
 )
@@ -190,6 +193,70 @@ func TestOverrideUnlock(t *testing.T) {
 	}
 }
 
+func TestScopedUnlock(t *testing.T) {
+	t.Parallel()
+	_, ks := tmpKeyStore(t)
+
+	pass := "foo"
+	a1, err := ks.NewAccount(pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wallet := ks.Wallets()[0]
+	contract := common.Address{1}
+
+	// A key unlocked for clique seals can't sign anything else
+	if err := ks.ScopedUnlock(a1, pass, time.Minute, &UnlockScope{MimeTypes: []string{accounts.MimetypeClique}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wallet.SignData(a1, accounts.MimetypeClique, []byte("header")); err != nil {
+		t.Fatalf("can't sign clique header: %v", err)
+	}
+	if _, err := wallet.SignText(a1, []byte("text")); !errors.Is(err, ErrOutOfScope) {
+		t.Fatalf("wrong error signing text: %v", err)
+	}
+	if _, err := ks.SignHash(a1, testSigData); !errors.Is(err, ErrOutOfScope) {
+		t.Fatalf("wrong error signing raw hash: %v", err)
+	}
+	tx := types.NewTx(&types.LegacyTx{To: &contract, Gas: 21000, GasPrice: big.NewInt(1)})
+	if _, err := wallet.SignTx(a1, tx, big.NewInt(1)); !errors.Is(err, ErrOutOfScope) {
+		t.Fatalf("wrong error signing transaction: %v", err)
+	}
+	// Unlocking with a new scope replaces the previous one
+	if err := ks.ScopedUnlock(a1, pass, time.Minute, &UnlockScope{Transactions: true, Recipients: []common.Address{contract}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wallet.SignTx(a1, tx, big.NewInt(1)); err != nil {
+		t.Fatalf("can't sign transaction to contract: %v", err)
+	}
+	other := types.NewTx(&types.LegacyTx{To: &common.Address{2}, Gas: 21000, GasPrice: big.NewInt(1)})
+	if _, err := wallet.SignTx(a1, other, big.NewInt(1)); !errors.Is(err, ErrOutOfScope) {
+		t.Fatalf("wrong error signing transaction to other recipient: %v", err)
+	}
+	create := types.NewTx(&types.LegacyTx{Gas: 21000, GasPrice: big.NewInt(1)})
+	if _, err := wallet.SignTx(a1, create, big.NewInt(1)); !errors.Is(err, ErrOutOfScope) {
+		t.Fatalf("wrong error signing contract creation: %v", err)
+	}
+	if _, err := wallet.SignData(a1, accounts.MimetypeClique, []byte("header")); !errors.Is(err, ErrOutOfScope) {
+		t.Fatalf("wrong error signing clique header: %v", err)
+	}
+	// Signing with the passphrase is not restricted
+	if _, err := wallet.SignTxWithPassphrase(a1, pass, other, big.NewInt(1)); err != nil {
+		t.Fatalf("can't sign with passphrase: %v", err)
+	}
+	sessions := ks.UnlockSessions()
+	if len(sessions) != 1 || sessions[0].Expires == nil || sessions[0].Scope == nil || !sessions[0].Scope.Transactions {
+		t.Fatalf("wrong unlock sessions %+v", sessions)
+	}
+	// An unrestricted unlock lifts the scope
+	if err := ks.TimedUnlock(a1, pass, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.SignHash(a1, testSigData); err != nil {
+		t.Fatalf("can't sign raw hash after unrestricted unlock: %v", err)
+	}
+}
+
 // This test should fail under -race if signing races the expiration goroutine.
 func TestSignRace(t *testing.T) {
 	t.Parallel()
@@ -0,0 +1,185 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// DefaultDiscoveryGap is the number of consecutive unused accounts after which
+// DiscoverMnemonicPaths stops, the gap limit of BIP-44.
+const DefaultDiscoveryGap = 20
+
+var (
+	// ErrInvalidMnemonic is returned if a mnemonic has an unknown word or a wrong
+	// checksum.
+	ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
+	errInvalidHDKey = errors.New("derived key is invalid, use the next index")
+)
+
+// ImportMnemonic derives the keys of a BIP-39 mnemonic at the given paths and
+// stores them into the key directory, encrypting them with the passphrase. The
+// seed passphrase is the optional BIP-39 passphrase extending the mnemonic.
+// Accounts already in the key directory are returned but not stored again.
+func (ks *KeyStore) ImportMnemonic(mnemonic, seedPassphrase string, paths []accounts.DerivationPath, passphrase string) ([]accounts.Account, error) {
+	seed, err := mnemonicSeed(mnemonic, seedPassphrase)
+	if err != nil {
+		return nil, err
+	}
+	ks.importMu.Lock()
+	defer ks.importMu.Unlock()
+
+	imported := make([]accounts.Account, 0, len(paths))
+	for _, path := range paths {
+		priv, err := deriveHDKey(seed, path)
+		if err != nil {
+			return imported, fmt.Errorf("can't derive %v: %v", path, err)
+		}
+		key := newKeyFromECDSA(priv)
+		if ks.HasAddress(key.Address) {
+			zeroKey(priv)
+			imported = append(imported, accounts.Account{Address: key.Address})
+			continue
+		}
+		a, err := ks.importKey(key, passphrase)
+		zeroKey(priv)
+		if err != nil {
+			return imported, err
+		}
+		imported = append(imported, a)
+	}
+	return imported, nil
+}
+
+// MnemonicPaths returns the paths of the first count accounts of a derivation
+// template.
+func MnemonicPaths(template accounts.DerivationTemplate, count int) []accounts.DerivationPath {
+	var (
+		next  = template.Iterator()
+		paths = make([]accounts.DerivationPath, count)
+	)
+	for i := range paths {
+		paths[i] = append(accounts.DerivationPath{}, next()...)
+	}
+	return paths
+}
+
+// DiscoverMnemonicPaths derives the accounts of a BIP-39 mnemonic along the
+// derivation template and returns the paths of those reported as used, stopping
+// after gap consecutive unused accounts. If no account is used, the path of the
+// first one is returned.
+func DiscoverMnemonicPaths(mnemonic, seedPassphrase string, template accounts.DerivationTemplate, gap int, used func(common.Address) (bool, error)) ([]accounts.DerivationPath, error) {
+	seed, err := mnemonicSeed(mnemonic, seedPassphrase)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		next  = template.Iterator()
+		paths []accounts.DerivationPath
+	)
+	for unused := 0; unused < gap; {
+		path := append(accounts.DerivationPath{}, next()...)
+		priv, err := deriveHDKey(seed, path)
+		if err != nil {
+			return nil, fmt.Errorf("can't derive %v: %v", path, err)
+		}
+		address := crypto.PubkeyToAddress(priv.PublicKey)
+		zeroKey(priv)
+
+		ok, err := used(address)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			unused++
+			continue
+		}
+		paths = append(paths, path)
+		unused = 0
+	}
+	if len(paths) == 0 {
+		paths = append(paths, append(accounts.DerivationPath{}, template.Base...))
+	}
+	return paths, nil
+}
+
+// mnemonicSeed validates a BIP-39 mnemonic and derives its seed.
+func mnemonicSeed(mnemonic, seedPassphrase string) ([]byte, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, ErrInvalidMnemonic
+	}
+	return bip39.NewSeed(mnemonic, seedPassphrase), nil
+}
+
+// deriveHDKey derives the private key at a path from a BIP-32 seed.
+func deriveHDKey(seed []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	var (
+		curveN = crypto.S256().Params().N
+		mac    = hmac.New(sha512.New, []byte("Bitcoin seed"))
+	)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	key, chainCode := new(big.Int).SetBytes(sum[:32]), sum[32:]
+	if key.Sign() == 0 || key.Cmp(curveN) >= 0 {
+		return nil, errInvalidHDKey
+	}
+	for _, index := range path {
+		// Hardened children are derived from the private key, others from the
+		// compressed public key.
+		var data []byte
+		if index >= 0x80000000 {
+			data = append([]byte{0}, key.FillBytes(make([]byte, 32))...)
+		} else {
+			priv, err := crypto.ToECDSA(key.FillBytes(make([]byte, 32)))
+			if err != nil {
+				return nil, err
+			}
+			data = crypto.CompressPubkey(&priv.PublicKey)
+		}
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+
+		tweak := new(big.Int).SetBytes(sum[:32])
+		if tweak.Cmp(curveN) >= 0 {
+			return nil, errInvalidHDKey
+		}
+		key.Add(key, tweak).Mod(key, curveN)
+		if key.Sign() == 0 {
+			return nil, errInvalidHDKey
+		}
+		chainCode = sum[32:]
+	}
+	return crypto.ToECDSA(key.FillBytes(make([]byte, 32)))
+}
@@ -0,0 +1,131 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// Tests the BIP-32 derivation against the test vector 1 of the specification.
+func TestDeriveHDKey(t *testing.T) {
+	seed := hexutil.MustDecode("0x000102030405060708090a0b0c0d0e0f")
+	tests := []struct {
+		path string
+		key  string
+	}{
+		{"m", "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+		{"m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"m/0'/1/2'/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	}
+	for _, tt := range tests {
+		var path accounts.DerivationPath
+		if tt.path != "m" {
+			var err error
+			if path, err = accounts.ParseDerivationPath(tt.path); err != nil {
+				t.Fatal(err)
+			}
+		}
+		key, err := deriveHDKey(seed, path)
+		if err != nil {
+			t.Fatalf("%s: derivation failed: %v", tt.path, err)
+		}
+		if have := fmt.Sprintf("%x", crypto.FromECDSA(key)); have != tt.key {
+			t.Errorf("%s: key mismatch: have %s, want %s", tt.path, have, tt.key)
+		}
+	}
+}
+
+func TestImportMnemonic(t *testing.T) {
+	t.Parallel()
+	_, ks := tmpKeyStore(t)
+
+	template, _ := accounts.ParseDerivationTemplate("m/44'/60'/0'/0/x")
+	accs, err := ks.ImportMnemonic(testMnemonic, "", MnemonicPaths(template, 2), "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accs) != 2 {
+		t.Fatalf("wrong number of accounts: %d", len(accs))
+	}
+	if want := common.HexToAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94"); accs[0].Address != want {
+		t.Fatalf("wrong first account %v, want %v", accs[0].Address, want)
+	}
+	if !ks.HasAddress(accs[1].Address) {
+		t.Fatalf("second account not stored")
+	}
+	if err := ks.Unlock(accs[1], "foo"); err != nil {
+		t.Fatalf("can't unlock imported account: %v", err)
+	}
+	// Importing again returns the existing accounts.
+	again, err := ks.ImportMnemonic(testMnemonic, "", MnemonicPaths(template, 1), "bar")
+	if err != nil || again[0].Address != accs[0].Address {
+		t.Fatalf("reimport failed: %v %v", again, err)
+	}
+	if len(ks.Accounts()) != 2 {
+		t.Fatalf("wrong number of stored accounts: %d", len(ks.Accounts()))
+	}
+	// The seed passphrase gives different accounts.
+	other, err := ks.ImportMnemonic(testMnemonic, "TREZOR", MnemonicPaths(template, 1), "foo")
+	if err != nil || other[0].Address == accs[0].Address {
+		t.Fatalf("seed passphrase ignored: %v %v", other, err)
+	}
+	if _, err := ks.ImportMnemonic("abandon abandon abandon", "", MnemonicPaths(template, 1), "foo"); err != ErrInvalidMnemonic {
+		t.Fatalf("wrong error for invalid mnemonic: %v", err)
+	}
+}
+
+func TestDiscoverMnemonicPaths(t *testing.T) {
+	template, _ := accounts.ParseDerivationTemplate("m/44'/60'/0'/0/x")
+	seed, _ := mnemonicSeed(testMnemonic, "")
+
+	// Mark accounts 0, 2 and 5 as used, with a gap of 2 the last one is not found.
+	used := make(map[common.Address]bool)
+	for i, path := range MnemonicPaths(template, 6) {
+		if i == 0 || i == 2 || i == 5 {
+			key, _ := deriveHDKey(seed, path)
+			used[crypto.PubkeyToAddress(key.PublicKey)] = true
+		}
+	}
+	isUsed := func(addr common.Address) (bool, error) { return used[addr], nil }
+
+	paths, err := DiscoverMnemonicPaths(testMnemonic, "", template, 2, isUsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := MnemonicPaths(template, 6)
+	if want := []accounts.DerivationPath{all[0], all[2]}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("wrong paths with gap 2: %v, want %v", paths, want)
+	}
+	paths, _ = DiscoverMnemonicPaths(testMnemonic, "", template, DefaultDiscoveryGap, isUsed)
+	if want := []accounts.DerivationPath{all[0], all[2], all[5]}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("wrong paths with default gap: %v, want %v", paths, want)
+	}
+	// Without used accounts the first one is returned.
+	paths, _ = DiscoverMnemonicPaths(testMnemonic, "", template, 3, func(common.Address) (bool, error) { return false, nil })
+	if want := []accounts.DerivationPath{all[0]}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("wrong paths without used accounts: %v, want %v", paths, want)
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -71,8 +72,7 @@ const (
 
 type keyStorePassphrase struct {
 	keysDirPath string
-	scryptN     int
-	scryptP     int
+	kdf         KDFConfig
 	// skipKeyFileVerification disables the security-feature which does
 	// reads and decrypts any newly created keyfiles. This should be 'false' in all
 	// cases except tests -- setting this to 'true' is not recommended.
@@ -98,12 +98,18 @@ func (ks keyStorePassphrase) GetKey(addr common.Address, filename, auth string)
 
 // StoreKey generates a key, encrypts with 'auth' and stores in the given directory
 func StoreKey(dir, auth string, scryptN, scryptP int) (accounts.Account, error) {
-	_, a, err := storeNewKey(&keyStorePassphrase{dir, scryptN, scryptP, false}, rand.Reader, auth)
+	return StoreKeyWithKDF(dir, auth, ScryptKDF(scryptN, scryptP))
+}
+
+// StoreKeyWithKDF generates a key, encrypts with 'auth' using the given key
+// derivation function and stores in the given directory
+func StoreKeyWithKDF(dir, auth string, kdf KDFConfig) (accounts.Account, error) {
+	_, a, err := storeNewKey(&keyStorePassphrase{dir, kdf, false}, rand.Reader, auth)
 	return a, err
 }
 
 func (ks keyStorePassphrase) StoreKey(filename string, key *Key, auth string) error {
-	keyjson, err := EncryptKey(key, auth, ks.scryptN, ks.scryptP)
+	keyjson, err := EncryptKeyWithKDF(key, auth, ks.kdf)
 	if err != nil {
 		return err
 	}
@@ -138,14 +144,21 @@ func (ks keyStorePassphrase) JoinPath(filename string) string {
 
 // EncryptDataV3 encrypts the data given as 'data' with the password 'auth'.
 func EncryptDataV3(data, auth []byte, scryptN, scryptP int) (CryptoJSON, error) {
+	return EncryptDataWithKDF(data, auth, ScryptKDF(scryptN, scryptP))
+}
+
+// EncryptDataWithKDF encrypts the data given as 'data' with the password 'auth',
+// deriving the encryption key with the given key derivation function.
+func EncryptDataWithKDF(data, auth []byte, kdf KDFConfig) (CryptoJSON, error) {
 	salt := make([]byte, 32)
 	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
 		panic("reading from crypto/rand failed: " + err.Error())
 	}
-	derivedKey, err := scrypt.Key(auth, salt, scryptN, scryptR, scryptP, scryptDKLen)
+	derivedKey, err := kdf.deriveKey(auth, salt, scryptDKLen)
 	if err != nil {
 		return CryptoJSON{}, err
 	}
+	defer clear(derivedKey)
 	encryptKey := derivedKey[:16]
 
 	iv := make([]byte, aes.BlockSize) // 16
@@ -158,12 +171,6 @@ func EncryptDataV3(data, auth []byte, scryptN, scryptP int) (CryptoJSON, error)
 	}
 	mac := crypto.Keccak256(derivedKey[16:32], cipherText)
 
-	scryptParamsJSON := make(map[string]interface{}, 5)
-	scryptParamsJSON["n"] = scryptN
-	scryptParamsJSON["r"] = scryptR
-	scryptParamsJSON["p"] = scryptP
-	scryptParamsJSON["dklen"] = scryptDKLen
-	scryptParamsJSON["salt"] = hex.EncodeToString(salt)
 	cipherParamsJSON := cipherparamsJSON{
 		IV: hex.EncodeToString(iv),
 	}
@@ -172,8 +179,8 @@ func EncryptDataV3(data, auth []byte, scryptN, scryptP int) (CryptoJSON, error)
 		Cipher:       "aes-128-ctr",
 		CipherText:   hex.EncodeToString(cipherText),
 		CipherParams: cipherParamsJSON,
-		KDF:          keyHeaderKDF,
-		KDFParams:    scryptParamsJSON,
+		KDF:          kdf.Name,
+		KDFParams:    kdf.params(salt, scryptDKLen),
 		MAC:          hex.EncodeToString(mac),
 	}
 	return cryptoStruct, nil
@@ -182,8 +189,17 @@ func EncryptDataV3(data, auth []byte, scryptN, scryptP int) (CryptoJSON, error)
 // EncryptKey encrypts a key using the specified scrypt parameters into a json
 // blob that can be decrypted later on.
 func EncryptKey(key *Key, auth string, scryptN, scryptP int) ([]byte, error) {
+	return EncryptKeyWithKDF(key, auth, ScryptKDF(scryptN, scryptP))
+}
+
+// EncryptKeyWithKDF encrypts a key using the specified key derivation function
+// into a json blob that can be decrypted later on.
+func EncryptKeyWithKDF(key *Key, auth string, kdf KDFConfig) ([]byte, error) {
 	keyBytes := math.PaddedBigBytes(key.PrivateKey.D, 32)
-	cryptoStruct, err := EncryptDataV3(keyBytes, []byte(auth), scryptN, scryptP)
+	defer clear(keyBytes)
+	authArray := []byte(auth)
+	defer clear(authArray)
+	cryptoStruct, err := EncryptDataWithKDF(keyBytes, authArray, kdf)
 	if err != nil {
 		return nil, err
 	}
@@ -225,6 +241,7 @@ func DecryptKey(keyjson []byte, auth string) (*Key, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer clear(keyBytes)
 	key, err := crypto.ToECDSA(keyBytes)
 	if err != nil {
 		return nil, fmt.Errorf("invalid key: %w", err)
@@ -263,6 +280,7 @@ func DecryptDataV3(cryptoJson CryptoJSON, auth string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer clear(derivedKey)
 
 	calculatedMAC := crypto.Keccak256(derivedKey[16:32], cipherText)
 	if !bytes.Equal(calculatedMAC, mac) {
@@ -317,6 +335,7 @@ func decryptKeyV1(keyProtected *encryptedKeyJSONV1, auth string) (keyBytes []byt
 	if err != nil {
 		return nil, nil, err
 	}
+	defer clear(derivedKey)
 
 	calculatedMAC := crypto.Keccak256(derivedKey[16:32], cipherText)
 	if !bytes.Equal(calculatedMAC, mac) {
@@ -331,7 +350,9 @@ func decryptKeyV1(keyProtected *encryptedKeyJSONV1, auth string) (keyBytes []byt
 }
 
 func getKDFKey(cryptoJSON CryptoJSON, auth string) ([]byte, error) {
+	// The copy of the passphrase is zeroed once the key is derived
 	authArray := []byte(auth)
+	defer clear(authArray)
 	salt, err := hex.DecodeString(cryptoJSON.KDFParams["salt"].(string))
 	if err != nil {
 		return nil, err
@@ -343,6 +364,14 @@ func getKDFKey(cryptoJSON CryptoJSON, auth string) ([]byte, error) {
 		r := ensureInt(cryptoJSON.KDFParams["r"])
 		p := ensureInt(cryptoJSON.KDFParams["p"])
 		return scrypt.Key(authArray, salt, n, r, p, dkLen)
+	} else if cryptoJSON.KDF == KDFArgon2id {
+		t := ensureInt(cryptoJSON.KDFParams["t"])
+		m := ensureInt(cryptoJSON.KDFParams["m"])
+		p := ensureInt(cryptoJSON.KDFParams["p"])
+		if t <= 0 || m <= 0 || p <= 0 || p > 255 || dkLen <= 0 {
+			return nil, errors.New("invalid argon2id parameters")
+		}
+		return Argon2idKDF(uint32(t), uint32(m), uint8(p)).deriveKey(authArray, salt, dkLen)
 	} else if cryptoJSON.KDF == "pbkdf2" {
 		c := ensureInt(cryptoJSON.KDFParams["c"])
 		prf := cryptoJSON.KDFParams["prf"].(string)
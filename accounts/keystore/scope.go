@@ -0,0 +1,79 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrOutOfScope is returned when signing with an unlocked key outside of the
+// scope of its unlock.
+var ErrOutOfScope = errors.New("signing request out of unlock scope")
+
+// UnlockScope restricts the signing operations allowed with a key unlocked by
+// ScopedUnlock. Signing with a passphrase is not restricted.
+//
+// For example, a scope of MimeTypes ["application/x-clique-header"] only allows
+// signing clique seals, while a scope allowing Transactions to a list of
+// Recipients only allows calling these contracts.
+type UnlockScope struct {
+	MimeTypes    []string         `json:"mimeTypes,omitempty"`    // Types of data that may be signed, see accounts.Mimetype*
+	Transactions bool             `json:"transactions,omitempty"` // Whether transactions may be signed
+	Recipients   []common.Address `json:"recipients,omitempty"`   // Recipients transactions are restricted to, any if empty
+}
+
+// checkData returns an error if the scope doesn't allow signing data of the
+// given type. Hashes of unknown data have an empty type.
+func (s *UnlockScope) checkData(mimeType string) error {
+	if s == nil || (mimeType != "" && slices.Contains(s.MimeTypes, mimeType)) {
+		return nil
+	}
+	if mimeType == "" {
+		return fmt.Errorf("%w: raw hash", ErrOutOfScope)
+	}
+	return fmt.Errorf("%w: data of type %s", ErrOutOfScope, mimeType)
+}
+
+// checkTx returns an error if the scope doesn't allow signing the transaction.
+func (s *UnlockScope) checkTx(tx *types.Transaction) error {
+	switch {
+	case s == nil:
+		return nil
+	case !s.Transactions:
+		return fmt.Errorf("%w: transaction", ErrOutOfScope)
+	case len(s.Recipients) == 0:
+		return nil
+	case tx.To() == nil:
+		return fmt.Errorf("%w: contract creation", ErrOutOfScope)
+	case !slices.Contains(s.Recipients, *tx.To()):
+		return fmt.Errorf("%w: transaction to %v", ErrOutOfScope, tx.To())
+	}
+	return nil
+}
+
+// UnlockSession describes an unlocked account.
+type UnlockSession struct {
+	Address common.Address `json:"address"`
+	Expires *time.Time     `json:"expires,omitempty"` // Nil if unlocked indefinitely
+	Scope   *UnlockScope   `json:"scope,omitempty"`   // Nil if unrestricted
+}
@@ -0,0 +1,152 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package kms
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// awsClient is a client of the AWS KMS JSON API.
+type awsClient struct {
+	creds    aws.CredentialsProvider
+	region   string // default region, for keys not given as ARN
+	endpoint string // overrides the regional endpoint if set
+	signer   *v4.Signer
+	http     *http.Client
+}
+
+func newAWSClient(ctx context.Context) (*awsClient, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't load AWS configuration: %v", err)
+	}
+	return &awsClient{
+		creds:  cfg.Credentials,
+		region: cfg.Region,
+		signer: v4.NewSigner(),
+		http:   new(http.Client),
+	}, nil
+}
+
+// awsError is an error returned by AWS KMS.
+type awsError struct {
+	status  int
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+func (e *awsError) Error() string {
+	return fmt.Sprintf("AWS KMS error %d %s: %s", e.status, e.Type, e.Message)
+}
+
+// Temporary reports whether the request may succeed if repeated.
+func (e *awsError) Temporary() bool {
+	return e.status >= 500 || e.status == http.StatusTooManyRequests || strings.Contains(e.Type, "Throttling")
+}
+
+func (c *awsClient) publicKey(ctx context.Context, key string) ([]byte, error) {
+	var resp struct {
+		PublicKey []byte
+		KeySpec   string
+	}
+	if err := c.call(ctx, key, "GetPublicKey", map[string]string{"KeyId": key}, &resp); err != nil {
+		return nil, err
+	}
+	if resp.KeySpec != "ECC_SECG_P256K1" {
+		return nil, fmt.Errorf("unsupported key spec %q", resp.KeySpec)
+	}
+	return resp.PublicKey, nil
+}
+
+func (c *awsClient) sign(ctx context.Context, key string, digest []byte) ([]byte, error) {
+	req := struct {
+		KeyId            string
+		Message          []byte
+		MessageType      string
+		SigningAlgorithm string
+	}{key, digest, "DIGEST", "ECDSA_SHA_256"}
+	var resp struct {
+		Signature []byte
+	}
+	if err := c.call(ctx, key, "Sign", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+// call sends a signed request to the KMS endpoint of the key's region.
+func (c *awsClient) call(ctx context.Context, key, action string, req, resp interface{}) error {
+	region := c.region
+	if arn := strings.Split(key, ":"); len(arn) > 3 && arn[0] == "arn" {
+		region = arn[3]
+	}
+	if region == "" {
+		return errors.New("AWS region not configured")
+	}
+	endpoint := c.endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com/", region)
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	hreq.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	hreq.Header.Set("X-Amz-Target", "TrentService."+action)
+
+	creds, err := c.creds.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("can't retrieve AWS credentials: %v", err)
+	}
+	hash := sha256.Sum256(body)
+	if err := c.signer.SignHTTP(ctx, creds, hreq, hex.EncodeToString(hash[:]), "kms", region, time.Now()); err != nil {
+		return err
+	}
+	hresp, err := c.http.Do(hreq)
+	if err != nil {
+		return err
+	}
+	defer hresp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(hresp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if hresp.StatusCode != http.StatusOK {
+		aerr := &awsError{status: hresp.StatusCode}
+		json.Unmarshal(data, aerr)
+		return aerr
+	}
+	return json.Unmarshal(data, resp)
+}
@@ -0,0 +1,184 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	gcpEndpoint      = "https://cloudkms.googleapis.com/v1/"
+	gcpTokenEndpoint = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	gcpTokenEnv      = "GOOGLE_OAUTH_ACCESS_TOKEN"
+)
+
+// gcpClient is a client of the Google Cloud KMS REST API.
+type gcpClient struct {
+	endpoint      string
+	tokenEndpoint string
+	http          *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+func newGCPClient() *gcpClient {
+	c := &gcpClient{endpoint: gcpEndpoint, tokenEndpoint: gcpTokenEndpoint, http: new(http.Client)}
+	if token := os.Getenv(gcpTokenEnv); token != "" {
+		c.token = token
+		c.tokenEndpoint = ""
+	}
+	return c
+}
+
+// gcpError is an error returned by Cloud KMS.
+type gcpError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+}
+
+func (e *gcpError) Error() string {
+	return fmt.Sprintf("Cloud KMS error %d %s: %s", e.Code, e.Status, e.Message)
+}
+
+// Temporary reports whether the request may succeed if repeated.
+func (e *gcpError) Temporary() bool {
+	return e.Code >= 500 || e.Code == http.StatusTooManyRequests
+}
+
+func (c *gcpClient) publicKey(ctx context.Context, key string) ([]byte, error) {
+	var resp struct {
+		PEM       string `json:"pem"`
+		Algorithm string `json:"algorithm"`
+	}
+	if err := c.call(ctx, http.MethodGet, key+"/publicKey", nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Algorithm != "EC_SIGN_SECP256K1_SHA256" {
+		return nil, fmt.Errorf("unsupported key algorithm %q", resp.Algorithm)
+	}
+	block, _ := pem.Decode([]byte(resp.PEM))
+	if block == nil {
+		return nil, errors.New("invalid public key PEM")
+	}
+	return block.Bytes, nil
+}
+
+func (c *gcpClient) sign(ctx context.Context, key string, digest []byte) ([]byte, error) {
+	// The service signs the digest as is, the hash function only names the field.
+	var req struct {
+		Digest struct {
+			SHA256 []byte `json:"sha256"`
+		} `json:"digest"`
+	}
+	req.Digest.SHA256 = digest
+	var resp struct {
+		Signature []byte `json:"signature"`
+	}
+	if err := c.call(ctx, http.MethodPost, key+":asymmetricSign", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+// call sends an authenticated request to the API.
+func (c *gcpClient) call(ctx context.Context, method, path string, req, resp interface{}) error {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	var body io.Reader
+	if req != nil {
+		data, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	hreq, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, body)
+	if err != nil {
+		return err
+	}
+	hreq.Header.Set("Authorization", "Bearer "+token)
+	if req != nil {
+		hreq.Header.Set("Content-Type", "application/json")
+	}
+	hresp, err := c.http.Do(hreq)
+	if err != nil {
+		return err
+	}
+	defer hresp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(hresp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if hresp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Error gcpError `json:"error"`
+		}
+		json.Unmarshal(data, &errResp)
+		errResp.Error.Code = hresp.StatusCode
+		return &errResp.Error
+	}
+	return json.Unmarshal(data, resp)
+}
+
+// accessToken returns the access token of the requests, fetching the token of
+// the instance's service account if none is configured.
+func (c *gcpClient) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tokenEndpoint == "" || (c.token != "" && time.Until(c.tokenExpiry) > time.Minute) {
+		return c.token, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.tokenEndpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("can't get access token: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("can't get access token: %s", resp.Status)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("invalid access token response: %v", err)
+	}
+	c.token = token.AccessToken
+	c.tokenExpiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return c.token, nil
+}
@@ -0,0 +1,319 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package kms implements an accounts backend signing with secp256k1 keys held by a
+// cloud key management service, AWS KMS or Google Cloud KMS.
+//
+// The private keys never leave the service: the backend derives the account
+// address from the public key of each key and sends the hashes to sign to the
+// service. Keys are given as URLs:
+//
+//	awskms://<key ID, alias or ARN>
+//	gcpkms://projects/<project>/locations/<location>/keyRings/<ring>/cryptoKeys/<key>/cryptoKeyVersions/<version>
+//
+// AWS credentials and the region are loaded from the standard AWS configuration
+// sources, unless the key is given as an ARN, which contains the region. Google
+// Cloud requests use the access token in GOOGLE_OAUTH_ACCESS_TOKEN if set, or the
+// token of the instance's service account otherwise.
+package kms
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/internal/ecsig"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// URL schemes of the supported key management services.
+const (
+	AWSScheme = "awskms"
+	GCPScheme = "gcpkms"
+)
+
+const (
+	requestTimeout = 10 * time.Second       // timeout of a single request
+	signTimeout    = 30 * time.Second       // timeout of a signing operation, including retries
+	maxAttempts    = 4                      // number of attempts of failing requests
+	retryDelay     = 250 * time.Millisecond // delay before the first retry, doubled for every next one
+)
+
+var (
+	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidSecp256k1      = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// client is the API of a key management service.
+type client interface {
+	// publicKey returns the DER encoded public key of a key.
+	publicKey(ctx context.Context, key string) ([]byte, error)
+	// sign signs a digest, returning the DER encoded ECDSA signature.
+	sign(ctx context.Context, key string, digest []byte) ([]byte, error)
+}
+
+// Backend is an accounts backend with one wallet for every KMS key.
+type Backend struct {
+	wallets []accounts.Wallet
+}
+
+// NewBackend creates a backend for the given key URLs. The public key of every
+// key is retrieved from the service to derive its address.
+func NewBackend(ctx context.Context, urls []string) (*Backend, error) {
+	var (
+		aws *awsClient
+		gcp *gcpClient
+	)
+	clients := func(scheme string) (client, error) {
+		var err error
+		switch scheme {
+		case AWSScheme:
+			if aws == nil {
+				aws, err = newAWSClient(ctx)
+			}
+			return aws, err
+		case GCPScheme:
+			if gcp == nil {
+				gcp = newGCPClient()
+			}
+			return gcp, nil
+		default:
+			return nil, fmt.Errorf("unknown KMS scheme %q", scheme)
+		}
+	}
+	return newBackend(ctx, urls, clients)
+}
+
+func newBackend(ctx context.Context, urls []string, clients func(scheme string) (client, error)) (*Backend, error) {
+	b := new(Backend)
+	for _, url := range urls {
+		scheme, key, ok := strings.Cut(url, "://")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid KMS key URL %q", url)
+		}
+		c, err := clients(scheme)
+		if err != nil {
+			return nil, err
+		}
+		w, err := newWallet(ctx, accounts.URL{Scheme: scheme, Path: key}, c)
+		if err != nil {
+			return nil, fmt.Errorf("KMS key %s: %v", url, err)
+		}
+		log.Info("Loaded KMS signing key", "url", url, "address", w.account.Address)
+		b.wallets = append(b.wallets, w)
+	}
+	return b, nil
+}
+
+// Wallets implements accounts.Backend, returning the wallets of the keys.
+func (b *Backend) Wallets() []accounts.Wallet {
+	return b.wallets
+}
+
+// Subscribe implements accounts.Backend. The set of keys is fixed, so no events
+// are sent.
+func (b *Backend) Subscribe(sink chan<- accounts.WalletEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
+// wallet is the wallet of a single KMS key.
+type wallet struct {
+	url     accounts.URL
+	client  client
+	pubkey  *ecdsa.PublicKey
+	account accounts.Account
+}
+
+func newWallet(ctx context.Context, url accounts.URL, c client) (*wallet, error) {
+	var der []byte
+	err := retry(ctx, func(ctx context.Context) (err error) {
+		der, err = c.publicKey(ctx, url.Path)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	pubkey, err := parsePublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	w := &wallet{url: url, client: c, pubkey: pubkey}
+	w.account = accounts.Account{Address: crypto.PubkeyToAddress(*pubkey), URL: url}
+	return w, nil
+}
+
+// URL implements accounts.Wallet, returning the URL of the key.
+func (w *wallet) URL() accounts.URL {
+	return w.url
+}
+
+// Status implements accounts.Wallet. The keys are always available.
+func (w *wallet) Status() (string, error) {
+	return "ok", nil
+}
+
+// Open implements accounts.Wallet. KMS keys need no opening.
+func (w *wallet) Open(passphrase string) error {
+	return nil
+}
+
+// Close implements accounts.Wallet.
+func (w *wallet) Close() error {
+	return nil
+}
+
+// Accounts implements accounts.Wallet, returning the account of the key.
+func (w *wallet) Accounts() []accounts.Account {
+	return []accounts.Account{w.account}
+}
+
+// Contains implements accounts.Wallet, reporting whether the account is the one
+// of the key.
+func (w *wallet) Contains(account accounts.Account) bool {
+	return account.Address == w.account.Address && (account.URL == (accounts.URL{}) || account.URL == w.url)
+}
+
+// Derive implements accounts.Wallet, but is not supported by KMS keys.
+func (w *wallet) Derive(path accounts.DerivationPath, pin bool) (accounts.Account, error) {
+	return accounts.Account{}, accounts.ErrNotSupported
+}
+
+// SelfDerive implements accounts.Wallet, but is a noop for KMS keys.
+func (w *wallet) SelfDerive(bases []accounts.DerivationPath, chain ethereum.ChainStateReader) {
+}
+
+// SignData implements accounts.Wallet, signing keccak256(data).
+func (w *wallet) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	return w.signHash(account, crypto.Keccak256(data))
+}
+
+// SignDataWithPassphrase implements accounts.Wallet. KMS keys don't rely on
+// passphrases, so it is ignored.
+func (w *wallet) SignDataWithPassphrase(account accounts.Account, passphrase, mimeType string, data []byte) ([]byte, error) {
+	return w.SignData(account, mimeType, data)
+}
+
+// SignText implements accounts.Wallet, signing the hash of the given text with
+// the Ethereum signed message prefix.
+func (w *wallet) SignText(account accounts.Account, text []byte) ([]byte, error) {
+	return w.signHash(account, accounts.TextHash(text))
+}
+
+// SignTextWithPassphrase implements accounts.Wallet. KMS keys don't rely on
+// passphrases, so it is ignored.
+func (w *wallet) SignTextWithPassphrase(account accounts.Account, passphrase string, text []byte) ([]byte, error) {
+	return w.SignText(account, text)
+}
+
+// SignTx implements accounts.Wallet, signing the transaction with the key.
+func (w *wallet) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	signer := types.LatestSignerForChainID(chainID)
+	sig, err := w.signHash(account, signer.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, sig)
+}
+
+// SignTxWithPassphrase implements accounts.Wallet. KMS keys don't rely on
+// passphrases, so it is ignored.
+func (w *wallet) SignTxWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return w.SignTx(account, tx, chainID)
+}
+
+// signHash has the service sign a hash, returning the signature in the
+// [R || S || V] format, where V is 0 or 1.
+func (w *wallet) signHash(account accounts.Account, hash []byte) ([]byte, error) {
+	if !w.Contains(account) {
+		return nil, accounts.ErrUnknownAccount
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), signTimeout)
+	defer cancel()
+
+	var der []byte
+	err := retry(ctx, func(ctx context.Context) (err error) {
+		der, err = w.client.sign(ctx, w.url.Path, hash)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ecsig.FromDER(der, hash, w.pubkey)
+}
+
+// retryableError is implemented by service errors which may go away if the
+// request is repeated.
+type retryableError interface {
+	Temporary() bool
+}
+
+// retry runs a request until it succeeds, fails with a permanent error or the
+// attempts run out.
+func retry(ctx context.Context, request func(ctx context.Context) error) error {
+	delay := retryDelay
+	for attempt := 1; ; attempt++ {
+		rctx, cancel := context.WithTimeout(ctx, requestTimeout)
+		err := request(rctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		var rerr retryableError
+		if (errors.As(err, &rerr) && !rerr.Temporary()) || attempt == maxAttempts {
+			return err
+		}
+		log.Debug("KMS request failed, retrying", "attempt", attempt, "err", err)
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// parsePublicKey parses a DER encoded secp256k1 public key.
+func parsePublicKey(der []byte) (*ecdsa.PublicKey, error) {
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if rest, err := asn1.Unmarshal(der, &spki); err != nil || len(rest) > 0 {
+		return nil, fmt.Errorf("invalid public key encoding: %v", err)
+	}
+	var curve asn1.ObjectIdentifier
+	if !spki.Algorithm.Algorithm.Equal(oidPublicKeyECDSA) {
+		return nil, fmt.Errorf("unsupported key algorithm %v", spki.Algorithm.Algorithm)
+	}
+	if _, err := asn1.Unmarshal(spki.Algorithm.Parameters.FullBytes, &curve); err != nil || !curve.Equal(oidSecp256k1) {
+		return nil, errors.New("key is not on the secp256k1 curve")
+	}
+	return crypto.UnmarshalPubkey(spki.PublicKey.Bytes)
+}
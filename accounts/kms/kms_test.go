@@ -0,0 +1,224 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package kms
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testKey is a key held by a fake KMS.
+var secp256k1N = crypto.S256().Params().N
+
+type testKey struct {
+	key      *ecdsa.PrivateKey
+	highS    bool         // return signatures with S in the upper half of the curve order
+	failures atomic.Int32 // number of requests to fail with a temporary error
+}
+
+func newTestKey(t *testing.T) *testKey {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testKey{key: key}
+}
+
+func (k *testKey) publicKeyDER() []byte {
+	params, _ := asn1.Marshal(oidSecp256k1)
+	der, err := asn1.Marshal(struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}{
+		Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidPublicKeyECDSA, Parameters: asn1.RawValue{FullBytes: params}},
+		PublicKey: asn1.BitString{Bytes: crypto.FromECDSAPub(&k.key.PublicKey), BitLength: 65 * 8},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return der
+}
+
+func (k *testKey) signDER(digest []byte) []byte {
+	sig, err := crypto.Sign(digest, k.key)
+	if err != nil {
+		panic(err)
+	}
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64])
+	if k.highS {
+		s.Sub(secp256k1N, s)
+	}
+	der, _ := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	return der
+}
+
+// fail reports whether the request should fail, counting down the failures.
+func (k *testKey) fail() bool {
+	return k.failures.Add(-1) >= 0
+}
+
+func newAWSServer(t *testing.T, keys map[string]*testKey) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256") {
+			t.Errorf("request not signed")
+		}
+		var req struct {
+			KeyId   string
+			Message []byte
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		key := keys[req.KeyId]
+		switch {
+		case key == nil:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"__type": "NotFoundException", "message": "no such key"})
+		case key.fail():
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]string{"__type": "KMSInternalException"})
+		case r.Header.Get("X-Amz-Target") == "TrentService.GetPublicKey":
+			json.NewEncoder(w).Encode(map[string]interface{}{"PublicKey": key.publicKeyDER(), "KeySpec": "ECC_SECG_P256K1"})
+		case r.Header.Get("X-Amz-Target") == "TrentService.Sign":
+			json.NewEncoder(w).Encode(map[string]interface{}{"Signature": key.signDER(req.Message)})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+}
+
+func newGCPServer(t *testing.T, keys map[string]*testKey) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("wrong authorization %q", r.Header.Get("Authorization"))
+		}
+		path := strings.TrimPrefix(r.URL.Path, "/")
+		if name, ok := strings.CutSuffix(path, "/publicKey"); ok && keys[name] != nil {
+			block := &pem.Block{Type: "PUBLIC KEY", Bytes: keys[name].publicKeyDER()}
+			json.NewEncoder(w).Encode(map[string]string{"pem": string(pem.EncodeToMemory(block)), "algorithm": "EC_SIGN_SECP256K1_SHA256"})
+			return
+		}
+		if name, ok := strings.CutSuffix(path, ":asymmetricSign"); ok && keys[name] != nil {
+			var req struct {
+				Digest struct {
+					SHA256 []byte `json:"sha256"`
+				} `json:"digest"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			json.NewEncoder(w).Encode(map[string]interface{}{"signature": keys[name].signDER(req.Digest.SHA256)})
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]interface{}{"code": 404, "status": "NOT_FOUND"}})
+	}))
+}
+
+func newTestBackend(t *testing.T, urls []string, awsURL, gcpURL string) (*Backend, error) {
+	aws := &awsClient{
+		creds:    credentials.NewStaticCredentialsProvider("id", "secret", ""),
+		region:   "eu-west-1",
+		endpoint: awsURL,
+		signer:   v4.NewSigner(),
+		http:     new(http.Client),
+	}
+	gcp := &gcpClient{endpoint: gcpURL + "/", token: "test-token", http: new(http.Client)}
+	return newBackend(context.Background(), urls, func(scheme string) (client, error) {
+		if scheme == AWSScheme {
+			return aws, nil
+		}
+		return gcp, nil
+	})
+}
+
+func TestBackendSigning(t *testing.T) {
+	var (
+		awsKey   = newTestKey(t)
+		awsKeyHi = newTestKey(t)
+		gcpKey   = newTestKey(t)
+		gcpName  = "projects/p/locations/global/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1"
+	)
+	awsKeyHi.highS = true
+	awsKey.failures.Store(2)
+	awsSrv := newAWSServer(t, map[string]*testKey{"key-1": awsKey, "key-2": awsKeyHi})
+	defer awsSrv.Close()
+	gcpSrv := newGCPServer(t, map[string]*testKey{gcpName: gcpKey})
+	defer gcpSrv.Close()
+
+	backend, err := newTestBackend(t, []string{"awskms://key-1", "awskms://key-2", "gcpkms://" + gcpName}, awsSrv.URL, gcpSrv.URL)
+	if err != nil {
+		t.Fatal("can't create backend:", err)
+	}
+	keys := []*testKey{awsKey, awsKeyHi, gcpKey}
+	for i, w := range backend.Wallets() {
+		want := crypto.PubkeyToAddress(keys[i].key.PublicKey)
+		account := w.Accounts()[0]
+		if account.Address != want {
+			t.Fatalf("wallet %d: wrong address %v, want %v", i, account.Address, want)
+		}
+		// Transactions are signed by the key's address.
+		chainID := big.NewInt(1337)
+		tx := types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 1, Gas: 21000, To: &common.Address{1}})
+		signed, err := w.SignTx(account, tx, chainID)
+		if err != nil {
+			t.Fatalf("wallet %d: can't sign transaction: %v", i, err)
+		}
+		if sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed); err != nil || sender != want {
+			t.Fatalf("wallet %d: wrong sender %v (%v)", i, sender, err)
+		}
+		// Data signatures recover to the key, e.g. for clique seals.
+		data := []byte("header")
+		sig, err := w.SignData(account, accounts.MimetypeClique, data)
+		if err != nil {
+			t.Fatalf("wallet %d: can't sign data: %v", i, err)
+		}
+		if pub, err := crypto.SigToPub(crypto.Keccak256(data), sig); err != nil || crypto.PubkeyToAddress(*pub) != want {
+			t.Fatalf("wallet %d: data signature doesn't recover to the key", i)
+		}
+		// Other accounts are rejected.
+		if _, err := w.SignData(accounts.Account{Address: common.Address{1}}, accounts.MimetypeClique, data); err != accounts.ErrUnknownAccount {
+			t.Fatalf("wallet %d: wrong error for unknown account: %v", i, err)
+		}
+	}
+}
+
+func TestBackendErrors(t *testing.T) {
+	awsSrv := newAWSServer(t, nil)
+	defer awsSrv.Close()
+	gcpSrv := newGCPServer(t, nil)
+	defer gcpSrv.Close()
+
+	for _, url := range []string{"awskms://missing", "gcpkms://missing", "awskms://"} {
+		if _, err := newTestBackend(t, []string{url}, awsSrv.URL, gcpSrv.URL); err == nil {
+			t.Errorf("no error for %s", url)
+		}
+	}
+}
@@ -17,7 +17,9 @@
 package accounts
 
 import (
+	"context"
 	"reflect"
+	"slices"
 	"sort"
 	"sync"
 
@@ -35,6 +37,25 @@ const managerSubBufferSize = 50
 // is removed in favor of Clef.
 type Config struct {
 	InsecureUnlockAllowed bool // Whether account unlocking in insecure environment is allowed
+
+	StrictReplayProtection bool             // Whether all accounts may only sign replay-protected (EIP-155) transactions
+	ReplayProtected        []common.Address // Accounts that may only sign replay-protected transactions
+
+	Auditor SignAuditor // Recorder of the signing operations requested over RPC, if any
+}
+
+// SignAuditor records signing operations for later review, see package
+// accounts/audit for the file based implementation.
+type SignAuditor interface {
+	// RecordSign records a signing operation of the given kind, requested through
+	// ctx, which was rejected if err is set.
+	RecordSign(ctx context.Context, account common.Address, kind string, digest common.Hash, err error)
+}
+
+// ReplayProtectionRequired reports whether the account may only sign and send
+// replay-protected (EIP-155) transactions.
+func (c *Config) ReplayProtectionRequired(addr common.Address) bool {
+	return c.StrictReplayProtection || slices.Contains(c.ReplayProtected, addr)
 }
 
 // newBackendEvent lets the manager know it should
@@ -0,0 +1,337 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package mpc implements an accounts backend for signing services which hold the
+// keys in shares, such as MPC and threshold signature custody providers.
+//
+// Signing with such services is asynchronous: the hash to sign is submitted, then
+// the parties run their signing rounds, which may include the approval of the
+// request by policy engines or humans. The backend polls the service until the
+// signature is available, the service rejects the request, or the context of the
+// signing times out, in which case the request is cancelled.
+//
+// Providers are plugged in through the Provider interface. The RPC provider of
+// this package talks to services, or adapters in front of them, implementing the
+// mpc_ JSON-RPC methods.
+package mpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Scheme is the URL scheme of MPC wallets.
+const Scheme = "mpc"
+
+// MimetypeTransaction is the mime type of signing requests for transactions, whose
+// payload is the unsigned transaction in its binary encoding.
+const MimetypeTransaction = "application/x-ethereum-transaction"
+
+// cancelTimeout is the time allowed for cancelling a request once its signing
+// context is done.
+const cancelTimeout = 5 * time.Second
+
+// ErrRejected is returned when the provider rejects a signing request.
+var ErrRejected = errors.New("signing request rejected")
+
+// Request is a signing request submitted to a provider. Next to the hash to sign,
+// it carries the signed payload, allowing the provider to apply its policies.
+type Request struct {
+	Account  common.Address `json:"account"`
+	Hash     common.Hash    `json:"hash"`
+	MimeType string         `json:"mimeType"`
+	Payload  hexutil.Bytes  `json:"payload"`
+	ChainID  *hexutil.Big   `json:"chainId,omitempty"` // Set for transactions
+}
+
+// Provider is the API of a signing service holding keys in shares.
+type Provider interface {
+	// Accounts returns the addresses of the keys the provider signs with.
+	Accounts(ctx context.Context) ([]common.Address, error)
+
+	// Submit starts the signing of a request, returning its ID.
+	Submit(ctx context.Context, req *Request) (string, error)
+
+	// Poll returns the signature of a request in the [R || S || V] format, where V
+	// is 0/1 or 27/28. The signature is nil while the signing is in progress.
+	// Requests rejected by the provider return an error wrapping ErrRejected.
+	Poll(ctx context.Context, id string) ([]byte, error)
+
+	// Cancel aborts the signing of a pending request.
+	Cancel(ctx context.Context, id string) error
+}
+
+// Config are the settings of the signing requests.
+type Config struct {
+	PollInterval time.Duration // Interval between polls of a pending request
+	Timeout      time.Duration // Time waited for a signature, unless bounded by a context
+}
+
+// DefaultConfig are the default request settings. The timeout leaves time for
+// the manual approval of requests.
+var DefaultConfig = Config{
+	PollInterval: 500 * time.Millisecond,
+	Timeout:      2 * time.Minute,
+}
+
+// Backend is an accounts backend with a single wallet, containing the accounts of
+// a provider.
+type Backend struct {
+	wallet *wallet
+}
+
+// NewBackend creates a backend for the accounts of the provider. The URL
+// identifies the provider in the accounts of the wallet.
+func NewBackend(ctx context.Context, url accounts.URL, provider Provider, config Config) (*Backend, error) {
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultConfig.PollInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultConfig.Timeout
+	}
+	addrs, err := provider.Accounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	w := &wallet{url: url, provider: provider, config: config}
+	for _, addr := range addrs {
+		w.accounts = append(w.accounts, accounts.Account{Address: addr, URL: url})
+	}
+	log.Info("Loaded MPC signing accounts", "url", url, "accounts", len(addrs))
+	return &Backend{wallet: w}, nil
+}
+
+// Wallets implements accounts.Backend, returning the wallet of the provider.
+func (b *Backend) Wallets() []accounts.Wallet {
+	return []accounts.Wallet{b.wallet}
+}
+
+// Subscribe implements accounts.Backend. The set of accounts is fixed, so no
+// events are sent.
+func (b *Backend) Subscribe(sink chan<- accounts.WalletEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
+// wallet is the wallet of the accounts of a provider. Next to accounts.Wallet, it
+// implements accounts.ContextSigner.
+type wallet struct {
+	url      accounts.URL
+	provider Provider
+	config   Config
+	accounts []accounts.Account
+}
+
+// URL implements accounts.Wallet, returning the URL of the provider.
+func (w *wallet) URL() accounts.URL {
+	return w.url
+}
+
+// Status implements accounts.Wallet. The availability of the provider is only
+// known when signing.
+func (w *wallet) Status() (string, error) {
+	return "ok", nil
+}
+
+// Open implements accounts.Wallet. MPC wallets need no opening.
+func (w *wallet) Open(passphrase string) error {
+	return nil
+}
+
+// Close implements accounts.Wallet.
+func (w *wallet) Close() error {
+	return nil
+}
+
+// Accounts implements accounts.Wallet, returning the accounts of the provider.
+func (w *wallet) Accounts() []accounts.Account {
+	return append([]accounts.Account(nil), w.accounts...)
+}
+
+// Contains implements accounts.Wallet, reporting whether the provider signs for
+// the account.
+func (w *wallet) Contains(account accounts.Account) bool {
+	for _, a := range w.accounts {
+		if a.Address == account.Address && (account.URL == (accounts.URL{}) || account.URL == w.url) {
+			return true
+		}
+	}
+	return false
+}
+
+// Derive implements accounts.Wallet, but is not supported by MPC wallets.
+func (w *wallet) Derive(path accounts.DerivationPath, pin bool) (accounts.Account, error) {
+	return accounts.Account{}, accounts.ErrNotSupported
+}
+
+// SelfDerive implements accounts.Wallet, but is a noop for MPC wallets.
+func (w *wallet) SelfDerive(bases []accounts.DerivationPath, chain ethereum.ChainStateReader) {
+}
+
+// SignData implements accounts.Wallet, signing keccak256(data).
+func (w *wallet) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.config.Timeout)
+	defer cancel()
+	return w.SignDataContext(ctx, account, mimeType, data)
+}
+
+// SignDataContext implements accounts.ContextSigner, signing keccak256(data).
+func (w *wallet) SignDataContext(ctx context.Context, account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	req := &Request{Account: account.Address, Hash: crypto.Keccak256Hash(data), MimeType: mimeType, Payload: data}
+	return w.sign(ctx, account, req)
+}
+
+// SignDataWithPassphrase implements accounts.Wallet. MPC wallets don't rely on
+// passphrases, so it is ignored.
+func (w *wallet) SignDataWithPassphrase(account accounts.Account, passphrase, mimeType string, data []byte) ([]byte, error) {
+	return w.SignData(account, mimeType, data)
+}
+
+// SignText implements accounts.Wallet, signing the hash of the given text with
+// the Ethereum signed message prefix.
+func (w *wallet) SignText(account accounts.Account, text []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.config.Timeout)
+	defer cancel()
+	return w.SignTextContext(ctx, account, text)
+}
+
+// SignTextContext implements accounts.ContextSigner, signing the hash of the
+// given text with the Ethereum signed message prefix.
+func (w *wallet) SignTextContext(ctx context.Context, account accounts.Account, text []byte) ([]byte, error) {
+	req := &Request{Account: account.Address, Hash: common.BytesToHash(accounts.TextHash(text)), MimeType: accounts.MimetypeTextPlain, Payload: text}
+	return w.sign(ctx, account, req)
+}
+
+// SignTextWithPassphrase implements accounts.Wallet. MPC wallets don't rely on
+// passphrases, so it is ignored.
+func (w *wallet) SignTextWithPassphrase(account accounts.Account, passphrase string, text []byte) ([]byte, error) {
+	return w.SignText(account, text)
+}
+
+// SignTx implements accounts.Wallet, signing the transaction with the key of the
+// account.
+func (w *wallet) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.config.Timeout)
+	defer cancel()
+	return w.SignTxContext(ctx, account, tx, chainID)
+}
+
+// SignTxContext implements accounts.ContextSigner, signing the transaction with
+// the key of the account.
+func (w *wallet) SignTxContext(ctx context.Context, account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	payload, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	signer := types.LatestSignerForChainID(chainID)
+	req := &Request{
+		Account:  account.Address,
+		Hash:     signer.Hash(tx),
+		MimeType: MimetypeTransaction,
+		Payload:  payload,
+		ChainID:  (*hexutil.Big)(chainID),
+	}
+	sig, err := w.sign(ctx, account, req)
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, sig)
+}
+
+// SignTxWithPassphrase implements accounts.Wallet. MPC wallets don't rely on
+// passphrases, so it is ignored.
+func (w *wallet) SignTxWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return w.SignTx(account, tx, chainID)
+}
+
+// sign submits a request and polls for its signature until the context is done,
+// cancelling the request in that case. The signature is returned with V 0 or 1,
+// after checking it was made by the account.
+func (w *wallet) sign(ctx context.Context, account accounts.Account, req *Request) ([]byte, error) {
+	if !w.Contains(account) {
+		return nil, accounts.ErrUnknownAccount
+	}
+	id, err := w.provider.Submit(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug("Submitted MPC signing request", "id", id, "account", req.Account, "hash", req.Hash)
+
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		sig, err := w.provider.Poll(ctx, id)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			return nil, err
+		case err == nil && sig != nil:
+			return checkSignature(sig, req)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			w.cancel(id)
+			return nil, fmt.Errorf("signing request %s: %w", id, ctx.Err())
+		}
+	}
+}
+
+// cancel aborts a pending request, independently of the done signing context.
+func (w *wallet) cancel(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+	defer cancel()
+	if err := w.provider.Cancel(ctx, id); err != nil {
+		log.Warn("Failed to cancel MPC signing request", "id", id, "err", err)
+	}
+}
+
+// checkSignature normalizes the recovery ID of a signature to 0 or 1 and checks
+// the signature recovers to the account of the request.
+func checkSignature(sig []byte, req *Request) ([]byte, error) {
+	if len(sig) != crypto.SignatureLength {
+		return nil, fmt.Errorf("invalid signature length %d", len(sig))
+	}
+	sig = common.CopyBytes(sig)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64])
+	if !crypto.ValidateSignatureValues(sig[crypto.RecoveryIDOffset], r, s, true) {
+		return nil, errors.New("invalid signature values")
+	}
+	pubkey, err := crypto.SigToPub(req.Hash[:], sig)
+	if err != nil {
+		return nil, err
+	}
+	if addr := crypto.PubkeyToAddress(*pubkey); addr != req.Account {
+		return nil, fmt.Errorf("signature by %v, not the requested account %v", addr, req.Account)
+	}
+	return sig, nil
+}
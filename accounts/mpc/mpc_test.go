@@ -0,0 +1,256 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package mpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testProvider signs requests after a number of polls, standing in for the
+// rounds of the signing parties.
+type testProvider struct {
+	key    *ecdsa.PrivateKey
+	rounds int    // polls before a request is signed, never signed if negative
+	reject string // reason of rejecting all requests, if set
+
+	mu        sync.Mutex
+	requests  map[string]*Request
+	polls     map[string]int
+	cancelled map[string]bool
+}
+
+func newTestProvider(t *testing.T, rounds int) *testProvider {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testProvider{
+		key:       key,
+		rounds:    rounds,
+		requests:  make(map[string]*Request),
+		polls:     make(map[string]int),
+		cancelled: make(map[string]bool),
+	}
+}
+
+func (p *testProvider) address() common.Address {
+	return crypto.PubkeyToAddress(p.key.PublicKey)
+}
+
+func (p *testProvider) Accounts(ctx context.Context) ([]common.Address, error) {
+	return []common.Address{p.address()}, nil
+}
+
+func (p *testProvider) Submit(ctx context.Context, req *Request) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if req.Account != p.address() {
+		return "", errors.New("unknown account")
+	}
+	id := fmt.Sprintf("req-%d", len(p.requests))
+	p.requests[id] = req
+	return id, nil
+}
+
+func (p *testProvider) Poll(ctx context.Context, id string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	req, ok := p.requests[id]
+	if !ok || p.cancelled[id] {
+		return nil, errors.New("unknown request")
+	}
+	if p.reject != "" {
+		return nil, fmt.Errorf("%w: %s", ErrRejected, p.reject)
+	}
+	p.polls[id]++
+	if p.rounds < 0 || p.polls[id] <= p.rounds {
+		return nil, nil
+	}
+	sig, err := crypto.Sign(req.Hash[:], p.key)
+	if err != nil {
+		return nil, err
+	}
+	sig[crypto.RecoveryIDOffset] += 27 // providers commonly return V as 27/28
+	return sig, nil
+}
+
+func (p *testProvider) Cancel(ctx context.Context, id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.cancelled[id] = true
+	return nil
+}
+
+func newTestWallet(t *testing.T, provider Provider) (accounts.Wallet, accounts.Account) {
+	backend, err := NewBackend(context.Background(), accounts.URL{Scheme: Scheme, Path: "test"}, provider, Config{PollInterval: time.Millisecond, Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wallet := backend.Wallets()[0]
+	return wallet, wallet.Accounts()[0]
+}
+
+func TestSignTx(t *testing.T) {
+	provider := newTestProvider(t, 3)
+	wallet, account := newTestWallet(t, provider)
+	if account.Address != provider.address() {
+		t.Fatalf("wrong account %v", account.Address)
+	}
+	chainID := big.NewInt(1337)
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 1, Gas: 21000, GasFeeCap: big.NewInt(1), To: &common.Address{1}, Value: big.NewInt(1)})
+
+	signed, err := wallet.SignTx(account, tx, chainID)
+	if err != nil {
+		t.Fatalf("can't sign transaction: %v", err)
+	}
+	if sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed); err != nil || sender != account.Address {
+		t.Fatalf("wrong sender %v: %v", sender, err)
+	}
+	req := provider.requests["req-0"]
+	if req.MimeType != MimetypeTransaction || req.ChainID.ToInt().Cmp(chainID) != 0 {
+		t.Fatalf("wrong transaction request %+v", req)
+	}
+	var payload types.Transaction
+	if err := payload.UnmarshalBinary(req.Payload); err != nil || payload.Hash() != tx.Hash() {
+		t.Fatalf("wrong transaction payload: %v", err)
+	}
+}
+
+func TestSignTimeout(t *testing.T) {
+	provider := newTestProvider(t, -1)
+	wallet, account := newTestWallet(t, provider)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := wallet.(accounts.ContextSigner).SignTextContext(ctx, account, []byte("hello"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wrong error %v", err)
+	}
+	if !provider.cancelled["req-0"] {
+		t.Fatal("timed out request not cancelled")
+	}
+	// Without a context, the configured timeout applies
+	start := time.Now()
+	if _, err := wallet.SignText(account, []byte("hello")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wrong error %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Fatalf("signing aborted after %v", elapsed)
+	}
+}
+
+func TestSignRejected(t *testing.T) {
+	provider := newTestProvider(t, 0)
+	provider.reject = "policy violation"
+	wallet, account := newTestWallet(t, provider)
+
+	if _, err := wallet.SignData(account, accounts.MimetypeTextPlain, []byte("hello")); !errors.Is(err, ErrRejected) {
+		t.Fatalf("wrong error %v", err)
+	}
+	if _, err := wallet.SignData(accounts.Account{Address: common.Address{1}}, accounts.MimetypeTextPlain, nil); err != accounts.ErrUnknownAccount {
+		t.Fatalf("wrong error for unknown account %v", err)
+	}
+}
+
+func TestCheckSignature(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	req := &Request{Account: crypto.PubkeyToAddress(key.PublicKey), Hash: common.Hash{1}}
+	sig, _ := crypto.Sign(req.Hash[:], key)
+
+	if have, err := checkSignature(sig, req); err != nil || have[crypto.RecoveryIDOffset] > 1 {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	other, _ := crypto.GenerateKey()
+	sig, _ = crypto.Sign(req.Hash[:], other)
+	if _, err := checkSignature(sig, req); err == nil {
+		t.Fatal("signature of another key accepted")
+	}
+	if _, err := checkSignature(sig[:64], req); err == nil {
+		t.Fatal("short signature accepted")
+	}
+}
+
+// testService exposes a provider over the mpc_ RPC methods.
+type testService struct {
+	p *testProvider
+}
+
+func (s *testService) Accounts() ([]common.Address, error) {
+	return s.p.Accounts(context.Background())
+}
+
+func (s *testService) Submit(req Request) (string, error) {
+	return s.p.Submit(context.Background(), &req)
+}
+
+func (s *testService) Poll(id string) (*PollResult, error) {
+	sig, err := s.p.Poll(context.Background(), id)
+	switch {
+	case errors.Is(err, ErrRejected):
+		return &PollResult{Status: StatusRejected, Reason: s.p.reject}, nil
+	case err != nil:
+		return nil, err
+	case sig == nil:
+		return &PollResult{Status: StatusPending}, nil
+	}
+	return &PollResult{Status: StatusSigned, Signature: sig}, nil
+}
+
+func (s *testService) Cancel(id string) error {
+	return s.p.Cancel(context.Background(), id)
+}
+
+func TestRPCProvider(t *testing.T) {
+	provider := newTestProvider(t, 2)
+	server := rpc.NewServer()
+	if err := server.RegisterName("mpc", &testService{provider}); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	client := NewRPCProvider(rpc.DialInProc(server))
+	defer client.Close()
+	wallet, account := newTestWallet(t, client)
+
+	sig, err := wallet.SignText(account, []byte("hello"))
+	if err != nil {
+		t.Fatalf("can't sign text: %v", err)
+	}
+	if pubkey, err := crypto.SigToPub(accounts.TextHash([]byte("hello")), sig); err != nil || crypto.PubkeyToAddress(*pubkey) != account.Address {
+		t.Fatalf("wrong signature: %v", err)
+	}
+	provider.reject = "denied by approver"
+	if _, err := wallet.SignText(account, []byte("hello")); !errors.Is(err, ErrRejected) {
+		t.Fatalf("wrong error %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package mpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Statuses of a signing request reported by mpc_poll.
+const (
+	StatusPending  = "pending"
+	StatusSigned   = "signed"
+	StatusRejected = "rejected"
+)
+
+// PollResult is the result of mpc_poll.
+type PollResult struct {
+	Status    string        `json:"status"`
+	Signature hexutil.Bytes `json:"signature,omitempty"` // Set if signed
+	Reason    string        `json:"reason,omitempty"`    // Set if rejected
+}
+
+// RPCProvider is a provider implementing the methods:
+//
+//	mpc_accounts() -> [address]
+//	mpc_submit(request) -> id
+//	mpc_poll(id) -> PollResult
+//	mpc_cancel(id)
+type RPCProvider struct {
+	client *rpc.Client
+}
+
+// DialRPC connects to the JSON-RPC endpoint of a provider.
+func DialRPC(ctx context.Context, endpoint string) (*RPCProvider, error) {
+	client, err := rpc.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return NewRPCProvider(client), nil
+}
+
+// NewRPCProvider creates a provider using the given client.
+func NewRPCProvider(client *rpc.Client) *RPCProvider {
+	return &RPCProvider{client: client}
+}
+
+// Close closes the connection to the provider.
+func (p *RPCProvider) Close() {
+	p.client.Close()
+}
+
+// Accounts implements Provider.
+func (p *RPCProvider) Accounts(ctx context.Context) ([]common.Address, error) {
+	var addrs []common.Address
+	err := p.client.CallContext(ctx, &addrs, "mpc_accounts")
+	return addrs, err
+}
+
+// Submit implements Provider.
+func (p *RPCProvider) Submit(ctx context.Context, req *Request) (string, error) {
+	var id string
+	if err := p.client.CallContext(ctx, &id, "mpc_submit", req); err != nil {
+		return "", err
+	}
+	if id == "" {
+		return "", errors.New("no ID for signing request")
+	}
+	return id, nil
+}
+
+// Poll implements Provider.
+func (p *RPCProvider) Poll(ctx context.Context, id string) ([]byte, error) {
+	var res PollResult
+	if err := p.client.CallContext(ctx, &res, "mpc_poll", id); err != nil {
+		return nil, err
+	}
+	switch res.Status {
+	case StatusPending:
+		return nil, nil
+	case StatusSigned:
+		if len(res.Signature) == 0 {
+			return nil, fmt.Errorf("no signature for signed request %s", id)
+		}
+		return res.Signature, nil
+	case StatusRejected:
+		return nil, fmt.Errorf("%w: %s", ErrRejected, res.Reason)
+	default:
+		return nil, fmt.Errorf("unknown status %q of signing request %s", res.Status, id)
+	}
+}
+
+// Cancel implements Provider.
+func (p *RPCProvider) Cancel(ctx context.Context, id string) error {
+	return p.client.CallContext(ctx, nil, "mpc_cancel", id)
+}
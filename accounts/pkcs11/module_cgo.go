@@ -0,0 +1,339 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build cgo && !windows
+
+package pkcs11
+
+/*
+#cgo linux LDFLAGS: -ldl
+
+#include <dlfcn.h>
+#include <stdlib.h>
+#include <string.h>
+
+// The subset of the PKCS#11 API used by the backend. The functions are looked up
+// by name, which every PKCS#11 module exports.
+typedef unsigned long CK_ULONG;
+typedef CK_ULONG CK_RV;
+
+typedef struct {
+	CK_ULONG type;
+	void *pValue;
+	CK_ULONG ulValueLen;
+} CK_ATTRIBUTE;
+
+typedef struct {
+	CK_ULONG mechanism;
+	void *pParameter;
+	CK_ULONG ulParameterLen;
+} CK_MECHANISM;
+
+typedef struct {
+	void *handle;
+	CK_RV (*initialize)(void *);
+	CK_RV (*finalize)(void *);
+	CK_RV (*getSlotList)(unsigned char, CK_ULONG *, CK_ULONG *);
+	CK_RV (*openSession)(CK_ULONG, CK_ULONG, void *, void *, CK_ULONG *);
+	CK_RV (*closeSession)(CK_ULONG);
+	CK_RV (*login)(CK_ULONG, CK_ULONG, unsigned char *, CK_ULONG);
+	CK_RV (*findObjectsInit)(CK_ULONG, CK_ATTRIBUTE *, CK_ULONG);
+	CK_RV (*findObjects)(CK_ULONG, CK_ULONG *, CK_ULONG, CK_ULONG *);
+	CK_RV (*findObjectsFinal)(CK_ULONG);
+	CK_RV (*getAttributeValue)(CK_ULONG, CK_ULONG, CK_ATTRIBUTE *, CK_ULONG);
+	CK_RV (*signInit)(CK_ULONG, CK_MECHANISM *, CK_ULONG);
+	CK_RV (*sign)(CK_ULONG, unsigned char *, CK_ULONG, unsigned char *, CK_ULONG *);
+} p11_module;
+
+static const char *p11_load(p11_module *m, const char *path) {
+	m->handle = dlopen(path, RTLD_NOW | RTLD_LOCAL);
+	if (m->handle == NULL) {
+		return dlerror();
+	}
+#define P11_SYM(field, name) \
+	if ((*(void **)(&m->field) = dlsym(m->handle, name)) == NULL) { return "missing symbol " name; }
+	P11_SYM(initialize, "C_Initialize")
+	P11_SYM(finalize, "C_Finalize")
+	P11_SYM(getSlotList, "C_GetSlotList")
+	P11_SYM(openSession, "C_OpenSession")
+	P11_SYM(closeSession, "C_CloseSession")
+	P11_SYM(login, "C_Login")
+	P11_SYM(findObjectsInit, "C_FindObjectsInit")
+	P11_SYM(findObjects, "C_FindObjects")
+	P11_SYM(findObjectsFinal, "C_FindObjectsFinal")
+	P11_SYM(getAttributeValue, "C_GetAttributeValue")
+	P11_SYM(signInit, "C_SignInit")
+	P11_SYM(sign, "C_Sign")
+#undef P11_SYM
+	return NULL;
+}
+
+static void p11_unload(p11_module *m) { dlclose(m->handle); }
+static CK_RV p11_initialize(p11_module *m) { return m->initialize(NULL); }
+static CK_RV p11_finalize(p11_module *m) { return m->finalize(NULL); }
+static CK_RV p11_get_slot_list(p11_module *m, CK_ULONG *slots, CK_ULONG *count) { return m->getSlotList(1, slots, count); }
+static CK_RV p11_open_session(p11_module *m, CK_ULONG slot, CK_ULONG *session) { return m->openSession(slot, 4, NULL, NULL, session); }
+static CK_RV p11_close_session(p11_module *m, CK_ULONG session) { return m->closeSession(session); }
+static CK_RV p11_login(p11_module *m, CK_ULONG session, unsigned char *pin, CK_ULONG len) { return m->login(session, 1, pin, len); }
+static CK_RV p11_find_init(p11_module *m, CK_ULONG session, CK_ATTRIBUTE *tmpl, CK_ULONG n) { return m->findObjectsInit(session, tmpl, n); }
+static CK_RV p11_find(p11_module *m, CK_ULONG session, CK_ULONG *objs, CK_ULONG max, CK_ULONG *n) { return m->findObjects(session, objs, max, n); }
+static CK_RV p11_find_final(p11_module *m, CK_ULONG session) { return m->findObjectsFinal(session); }
+static CK_RV p11_get_attr(p11_module *m, CK_ULONG session, CK_ULONG obj, CK_ATTRIBUTE *tmpl, CK_ULONG n) { return m->getAttributeValue(session, obj, tmpl, n); }
+static CK_RV p11_sign_init(p11_module *m, CK_ULONG session, CK_ULONG obj) {
+	CK_MECHANISM mech = {0x1041, NULL, 0}; // CKM_ECDSA
+	return m->signInit(session, &mech, obj);
+}
+static CK_RV p11_sign(p11_module *m, CK_ULONG session, unsigned char *data, CK_ULONG len, unsigned char *sig, CK_ULONG *siglen) {
+	return m->sign(session, data, len, sig, siglen);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// PKCS#11 constants.
+const (
+	ckrOK                         = 0x000
+	ckrUserAlreadyLoggedIn        = 0x100
+	ckrCryptokiAlreadyInitialized = 0x191
+	ckoPublicKey                  = 2
+	ckoPrivateKey                 = 3
+	ckaClass                      = 0x000
+	ckaLabel                      = 0x003
+	ckaKeyType                    = 0x100
+	ckaID                         = 0x102
+	ckaECParams                   = 0x180
+	ckaECPoint                    = 0x181
+	ckkEC                         = 3
+	maxObjects                    = 64
+)
+
+// ckError is a PKCS#11 return value other than CKR_OK.
+type ckError C.CK_RV
+
+func (e ckError) Error() string {
+	return fmt.Sprintf("pkcs11 error %#x", uint(e))
+}
+
+// cgoModule is a PKCS#11 module loaded from a shared library. The calls are
+// serialized, so the module needs no locking support.
+type cgoModule struct {
+	mu       sync.Mutex
+	mod      *C.p11_module // allocated in C memory
+	pin      string
+	sessions map[uint]C.CK_ULONG // logged in sessions by slot
+}
+
+// openModule loads the PKCS#11 library at path.
+func openModule(path, pin string) (module, error) {
+	m := &cgoModule{
+		mod:      (*C.p11_module)(C.calloc(1, C.size_t(unsafe.Sizeof(C.p11_module{})))),
+		pin:      pin,
+		sessions: make(map[uint]C.CK_ULONG),
+	}
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+	if msg := C.p11_load(m.mod, cpath); msg != nil {
+		C.free(unsafe.Pointer(m.mod))
+		return nil, fmt.Errorf("can't load PKCS#11 module: %s", C.GoString(msg))
+	}
+	if rv := C.p11_initialize(m.mod); rv != ckrOK && rv != ckrCryptokiAlreadyInitialized {
+		C.p11_unload(m.mod)
+		C.free(unsafe.Pointer(m.mod))
+		return nil, fmt.Errorf("can't initialize PKCS#11 module: %w", ckError(rv))
+	}
+	return m, nil
+}
+
+func (m *cgoModule) keys() ([]hsmKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var count C.CK_ULONG
+	if rv := C.p11_get_slot_list(m.mod, nil, &count); rv != ckrOK {
+		return nil, ckError(rv)
+	}
+	if count == 0 {
+		return nil, nil
+	}
+	slots := (*C.CK_ULONG)(C.calloc(C.size_t(count), C.size_t(unsafe.Sizeof(C.CK_ULONG(0)))))
+	defer C.free(unsafe.Pointer(slots))
+	if rv := C.p11_get_slot_list(m.mod, slots, &count); rv != ckrOK {
+		return nil, ckError(rv)
+	}
+	var keys []hsmKey
+	for _, slot := range unsafe.Slice(slots, count) {
+		session, err := m.session(uint(slot))
+		if err != nil {
+			return nil, fmt.Errorf("slot %d: %v", slot, err)
+		}
+		objs, err := m.find(session, ckoPublicKey, nil)
+		if err != nil {
+			return nil, fmt.Errorf("slot %d: %v", slot, err)
+		}
+		for _, obj := range objs {
+			attrs, err := m.attributes(session, obj, ckaID, ckaLabel, ckaECParams, ckaECPoint)
+			if err != nil {
+				return nil, fmt.Errorf("slot %d: %v", slot, err)
+			}
+			keys = append(keys, hsmKey{
+				slot:   uint(slot),
+				id:     attrs[0],
+				label:  string(attrs[1]),
+				params: attrs[2],
+				point:  attrs[3],
+			})
+		}
+	}
+	return keys, nil
+}
+
+func (m *cgoModule) sign(key hsmKey, hash []byte) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, err := m.session(key.slot)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := m.find(session, ckoPrivateKey, key.id)
+	if err != nil {
+		return nil, err
+	}
+	if len(objs) == 0 {
+		return nil, errors.New("private key not found")
+	}
+	if rv := C.p11_sign_init(m.mod, session, objs[0]); rv != ckrOK {
+		return nil, ckError(rv)
+	}
+	data := C.CBytes(hash)
+	defer C.free(data)
+	var sig [2 * 66]byte // large enough for any curve up to P-521
+	csig := (*C.uchar)(C.malloc(C.size_t(len(sig))))
+	defer C.free(unsafe.Pointer(csig))
+	siglen := C.CK_ULONG(len(sig))
+	if rv := C.p11_sign(m.mod, session, (*C.uchar)(data), C.CK_ULONG(len(hash)), csig, &siglen); rv != ckrOK {
+		return nil, ckError(rv)
+	}
+	return C.GoBytes(unsafe.Pointer(csig), C.int(siglen)), nil
+}
+
+func (m *cgoModule) close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, session := range m.sessions {
+		C.p11_close_session(m.mod, session)
+	}
+	C.p11_finalize(m.mod)
+	C.p11_unload(m.mod)
+	C.free(unsafe.Pointer(m.mod))
+	return nil
+}
+
+// session returns the logged in session of a slot, opening it if needed.
+func (m *cgoModule) session(slot uint) (C.CK_ULONG, error) {
+	if session, ok := m.sessions[slot]; ok {
+		return session, nil
+	}
+	var session C.CK_ULONG
+	if rv := C.p11_open_session(m.mod, C.CK_ULONG(slot), &session); rv != ckrOK {
+		return 0, fmt.Errorf("can't open session: %w", ckError(rv))
+	}
+	if m.pin != "" {
+		pin := C.CString(m.pin)
+		defer C.free(unsafe.Pointer(pin))
+		if rv := C.p11_login(m.mod, session, (*C.uchar)(unsafe.Pointer(pin)), C.CK_ULONG(len(m.pin))); rv != ckrOK && rv != ckrUserAlreadyLoggedIn {
+			C.p11_close_session(m.mod, session)
+			return 0, fmt.Errorf("can't log in: %w", ckError(rv))
+		}
+	}
+	m.sessions[slot] = session
+	return session, nil
+}
+
+// find returns the EC key objects of a class, optionally filtered by their ID.
+func (m *cgoModule) find(session C.CK_ULONG, class uint, id []byte) ([]C.CK_ULONG, error) {
+	values := []struct {
+		typ   uint
+		value []byte
+	}{
+		{ckaClass, ulongBytes(class)},
+		{ckaKeyType, ulongBytes(ckkEC)},
+	}
+	if id != nil {
+		values = append(values, struct {
+			typ   uint
+			value []byte
+		}{ckaID, id})
+	}
+	tmpl := (*C.CK_ATTRIBUTE)(C.calloc(C.size_t(len(values)), C.size_t(unsafe.Sizeof(C.CK_ATTRIBUTE{}))))
+	defer C.free(unsafe.Pointer(tmpl))
+	attrs := unsafe.Slice(tmpl, len(values))
+	for i, v := range values {
+		attrs[i]._type = C.CK_ULONG(v.typ)
+		attrs[i].pValue = C.CBytes(v.value)
+		attrs[i].ulValueLen = C.CK_ULONG(len(v.value))
+		defer C.free(attrs[i].pValue)
+	}
+	if rv := C.p11_find_init(m.mod, session, tmpl, C.CK_ULONG(len(values))); rv != ckrOK {
+		return nil, ckError(rv)
+	}
+	defer C.p11_find_final(m.mod, session)
+
+	objs := (*C.CK_ULONG)(C.calloc(maxObjects, C.size_t(unsafe.Sizeof(C.CK_ULONG(0)))))
+	defer C.free(unsafe.Pointer(objs))
+	var n C.CK_ULONG
+	if rv := C.p11_find(m.mod, session, objs, maxObjects, &n); rv != ckrOK {
+		return nil, ckError(rv)
+	}
+	return append([]C.CK_ULONG(nil), unsafe.Slice(objs, n)...), nil
+}
+
+// attributes reads attributes of an object. Missing attributes are returned as nil.
+func (m *cgoModule) attributes(session, obj C.CK_ULONG, types ...uint) ([][]byte, error) {
+	result := make([][]byte, len(types))
+	attr := (*C.CK_ATTRIBUTE)(C.calloc(1, C.size_t(unsafe.Sizeof(C.CK_ATTRIBUTE{}))))
+	defer C.free(unsafe.Pointer(attr))
+	for i, typ := range types {
+		// Query the length first, then the value.
+		*attr = C.CK_ATTRIBUTE{_type: C.CK_ULONG(typ)}
+		if rv := C.p11_get_attr(m.mod, session, obj, attr, 1); rv != ckrOK || attr.ulValueLen == C.CK_ULONG(^C.CK_ULONG(0)) {
+			continue
+		}
+		attr.pValue = C.malloc(C.size_t(attr.ulValueLen))
+		rv := C.p11_get_attr(m.mod, session, obj, attr, 1)
+		if rv == ckrOK {
+			result[i] = C.GoBytes(attr.pValue, C.int(attr.ulValueLen))
+		}
+		C.free(attr.pValue)
+		if rv != ckrOK {
+			return nil, ckError(rv)
+		}
+	}
+	return result, nil
+}
+
+// ulongBytes encodes a CK_ULONG attribute value in native byte order.
+func ulongBytes(v uint) []byte {
+	n := C.CK_ULONG(v)
+	return C.GoBytes(unsafe.Pointer(&n), C.int(unsafe.Sizeof(n)))
+}
@@ -0,0 +1,248 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package pkcs11 implements an accounts backend for the secp256k1 keys stored on
+// hardware security modules, accessed through their PKCS#11 module.
+//
+// The backend enumerates the EC key pairs on all tokens of the module and exposes
+// every secp256k1 key as a wallet. Hashes are signed on the device with the
+// CKM_ECDSA mechanism, the private keys never leave it.
+package pkcs11
+
+import (
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/internal/ecsig"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Scheme is the protocol scheme prefixing account and wallet URLs.
+const Scheme = "pkcs11"
+
+var oidSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+
+// hsmKey is an EC key pair stored on a token.
+type hsmKey struct {
+	slot   uint
+	id     []byte // CKA_ID, shared by the public and private key objects
+	label  string
+	params []byte // CKA_EC_PARAMS, DER encoded curve
+	point  []byte // CKA_EC_POINT
+}
+
+// module is a loaded PKCS#11 module.
+type module interface {
+	// keys returns the EC public keys of all tokens.
+	keys() ([]hsmKey, error)
+	// sign signs a hash with the private key matching a public key, returning the
+	// signature as R || S.
+	sign(key hsmKey, hash []byte) ([]byte, error)
+	close() error
+}
+
+// Hub is an accounts.Backend holding a wallet for every secp256k1 key on the
+// tokens of a PKCS#11 module.
+type Hub struct {
+	module  module
+	wallets []accounts.Wallet
+}
+
+// NewHub loads the PKCS#11 module at path, logs into its tokens with the PIN if
+// one is given and enumerates the keys.
+func NewHub(path, pin string) (*Hub, error) {
+	m, err := openModule(path, pin)
+	if err != nil {
+		return nil, err
+	}
+	hub, err := newHub(m)
+	if err != nil {
+		m.close()
+		return nil, err
+	}
+	return hub, nil
+}
+
+func newHub(m module) (*Hub, error) {
+	keys, err := m.keys()
+	if err != nil {
+		return nil, fmt.Errorf("can't list PKCS#11 keys: %v", err)
+	}
+	hub := &Hub{module: m}
+	for _, key := range keys {
+		url := accounts.URL{Scheme: Scheme, Path: fmt.Sprintf("%d/%x", key.slot, key.id)}
+		pubkey, err := parsePublicKey(key.params, key.point)
+		if err != nil {
+			log.Debug("Skipping PKCS#11 key", "url", url, "label", key.label, "err", err)
+			continue
+		}
+		w := &wallet{hub: hub, url: url, key: key, pubkey: pubkey}
+		w.account = accounts.Account{Address: crypto.PubkeyToAddress(*pubkey), URL: url}
+		log.Info("Found PKCS#11 signing key", "url", url, "label", key.label, "address", w.account.Address)
+		hub.wallets = append(hub.wallets, w)
+	}
+	return hub, nil
+}
+
+// Wallets implements accounts.Backend, returning the wallets of the keys.
+func (hub *Hub) Wallets() []accounts.Wallet {
+	return hub.wallets
+}
+
+// Subscribe implements accounts.Backend. The keys are enumerated once, so no
+// events are sent.
+func (hub *Hub) Subscribe(sink chan<- accounts.WalletEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
+// Close closes the sessions and unloads the module.
+func (hub *Hub) Close() error {
+	return hub.module.close()
+}
+
+// wallet is the wallet of a single key on a token.
+type wallet struct {
+	hub     *Hub
+	url     accounts.URL
+	key     hsmKey
+	pubkey  *ecdsa.PublicKey
+	account accounts.Account
+
+	lock sync.Mutex // serializes signing requests to the key
+}
+
+// URL implements accounts.Wallet, returning the URL of the key.
+func (w *wallet) URL() accounts.URL {
+	return w.url
+}
+
+// Status implements accounts.Wallet.
+func (w *wallet) Status() (string, error) {
+	return "ok", nil
+}
+
+// Open implements accounts.Wallet. The tokens are logged into when the module
+// is loaded.
+func (w *wallet) Open(passphrase string) error {
+	return nil
+}
+
+// Close implements accounts.Wallet.
+func (w *wallet) Close() error {
+	return nil
+}
+
+// Accounts implements accounts.Wallet, returning the account of the key.
+func (w *wallet) Accounts() []accounts.Account {
+	return []accounts.Account{w.account}
+}
+
+// Contains implements accounts.Wallet, reporting whether the account is the one
+// of the key.
+func (w *wallet) Contains(account accounts.Account) bool {
+	return account.Address == w.account.Address && (account.URL == (accounts.URL{}) || account.URL == w.url)
+}
+
+// Derive implements accounts.Wallet, but is not supported by HSM keys.
+func (w *wallet) Derive(path accounts.DerivationPath, pin bool) (accounts.Account, error) {
+	return accounts.Account{}, accounts.ErrNotSupported
+}
+
+// SelfDerive implements accounts.Wallet, but is a noop for HSM keys.
+func (w *wallet) SelfDerive(bases []accounts.DerivationPath, chain ethereum.ChainStateReader) {
+}
+
+// SignData implements accounts.Wallet, signing keccak256(data).
+func (w *wallet) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	return w.signHash(account, crypto.Keccak256(data))
+}
+
+// SignDataWithPassphrase implements accounts.Wallet. The token PIN is given when
+// loading the module, so the passphrase is ignored.
+func (w *wallet) SignDataWithPassphrase(account accounts.Account, passphrase, mimeType string, data []byte) ([]byte, error) {
+	return w.SignData(account, mimeType, data)
+}
+
+// SignText implements accounts.Wallet, signing the hash of the given text with
+// the Ethereum signed message prefix.
+func (w *wallet) SignText(account accounts.Account, text []byte) ([]byte, error) {
+	return w.signHash(account, accounts.TextHash(text))
+}
+
+// SignTextWithPassphrase implements accounts.Wallet. The token PIN is given when
+// loading the module, so the passphrase is ignored.
+func (w *wallet) SignTextWithPassphrase(account accounts.Account, passphrase string, text []byte) ([]byte, error) {
+	return w.SignText(account, text)
+}
+
+// SignTx implements accounts.Wallet, signing the transaction on the device.
+func (w *wallet) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	signer := types.LatestSignerForChainID(chainID)
+	sig, err := w.signHash(account, signer.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, sig)
+}
+
+// SignTxWithPassphrase implements accounts.Wallet. The token PIN is given when
+// loading the module, so the passphrase is ignored.
+func (w *wallet) SignTxWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return w.SignTx(account, tx, chainID)
+}
+
+// signHash signs a hash on the device, returning the signature in the
+// [R || S || V] format, where V is 0 or 1.
+func (w *wallet) signHash(account accounts.Account, hash []byte) ([]byte, error) {
+	if !w.Contains(account) {
+		return nil, accounts.ErrUnknownAccount
+	}
+	w.lock.Lock()
+	raw, err := w.hub.module.sign(w.key, hash)
+	w.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return ecsig.FromRS(raw, hash, w.pubkey)
+}
+
+// parsePublicKey decodes the public key of a secp256k1 key pair from its EC
+// parameters and point.
+func parsePublicKey(params, point []byte) (*ecdsa.PublicKey, error) {
+	var curve asn1.ObjectIdentifier
+	if rest, err := asn1.Unmarshal(params, &curve); err != nil || len(rest) > 0 || !curve.Equal(oidSecp256k1) {
+		return nil, errors.New("key is not on the secp256k1 curve")
+	}
+	// The point should be DER encoded as an octet string, but some modules return
+	// the raw point.
+	var raw []byte
+	if rest, err := asn1.Unmarshal(point, &raw); err == nil && len(rest) == 0 {
+		point = raw
+	}
+	return crypto.UnmarshalPubkey(point)
+}
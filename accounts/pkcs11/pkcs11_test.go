@@ -0,0 +1,166 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pkcs11
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testModule is a PKCS#11 module with keys held in memory.
+var (
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1halfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+type testModule struct {
+	stored []hsmKey
+	privs  map[string]*ecdsa.PrivateKey // by key ID
+	highS  bool
+}
+
+func (m *testModule) addKey(t *testing.T, slot uint, id string, derPoint bool) *ecdsa.PrivateKey {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, _ := asn1.Marshal(oidSecp256k1)
+	point := crypto.FromECDSAPub(&key.PublicKey)
+	if derPoint {
+		point, _ = asn1.Marshal(point)
+	}
+	m.stored = append(m.stored, hsmKey{slot: slot, id: []byte(id), label: id, params: params, point: point})
+	m.privs[id] = key
+	return key
+}
+
+func (m *testModule) keys() ([]hsmKey, error) {
+	return m.stored, nil
+}
+
+func (m *testModule) sign(key hsmKey, hash []byte) ([]byte, error) {
+	priv := m.privs[string(key.id)]
+	if priv == nil {
+		return nil, errors.New("private key not found")
+	}
+	sig, err := crypto.Sign(hash, priv)
+	if err != nil {
+		return nil, err
+	}
+	if m.highS {
+		s := new(big.Int).SetBytes(sig[32:64])
+		s.Sub(secp256k1N, s).FillBytes(sig[32:64])
+	}
+	return sig[:64], nil
+}
+
+func (m *testModule) close() error {
+	return nil
+}
+
+func TestHubSigning(t *testing.T) {
+	for _, highS := range []bool{false, true} {
+		m := &testModule{privs: make(map[string]*ecdsa.PrivateKey), highS: highS}
+		keys := []*ecdsa.PrivateKey{
+			m.addKey(t, 0, "validator", true),
+			m.addKey(t, 1, "sealer", false),
+		}
+		// Keys on other curves are skipped.
+		p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		p256params, _ := asn1.Marshal(asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7})
+		m.stored = append(m.stored, hsmKey{slot: 0, id: []byte("p256"), params: p256params, point: elliptic.Marshal(elliptic.P256(), p256.X, p256.Y)})
+
+		hub, err := newHub(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wallets := hub.Wallets()
+		if len(wallets) != len(keys) {
+			t.Fatalf("wrong number of wallets: %d, want %d", len(wallets), len(keys))
+		}
+		for i, w := range wallets {
+			want := crypto.PubkeyToAddress(keys[i].PublicKey)
+			account := w.Accounts()[0]
+			if account.Address != want {
+				t.Fatalf("wallet %d: wrong address %v, want %v", i, account.Address, want)
+			}
+			chainID := big.NewInt(1337)
+			tx := types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 1, Gas: 21000, To: &common.Address{1}})
+			signed, err := w.SignTx(account, tx, chainID)
+			if err != nil {
+				t.Fatalf("wallet %d: can't sign transaction: %v", i, err)
+			}
+			if sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed); err != nil || sender != want {
+				t.Fatalf("wallet %d: wrong sender %v (%v)", i, sender, err)
+			}
+			data := []byte("header")
+			sig, err := w.SignData(account, accounts.MimetypeClique, data)
+			if err != nil {
+				t.Fatalf("wallet %d: can't sign data: %v", i, err)
+			}
+			if pub, err := crypto.SigToPub(crypto.Keccak256(data), sig); err != nil || crypto.PubkeyToAddress(*pub) != want {
+				t.Fatalf("wallet %d: data signature doesn't recover to the key", i)
+			}
+			if s := new(big.Int).SetBytes(sig[32:64]); s.Cmp(secp256k1halfN) > 0 {
+				t.Fatalf("wallet %d: signature not normalized", i)
+			}
+			if _, err := w.SignData(accounts.Account{Address: common.Address{1}}, accounts.MimetypeClique, data); err != accounts.ErrUnknownAccount {
+				t.Fatalf("wallet %d: wrong error for unknown account: %v", i, err)
+			}
+		}
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	params, _ := asn1.Marshal(oidSecp256k1)
+	point := crypto.FromECDSAPub(&key.PublicKey)
+	der, _ := asn1.Marshal(point)
+
+	for _, p := range [][]byte{point, der} {
+		pub, err := parsePublicKey(params, p)
+		if err != nil {
+			t.Fatalf("can't parse point %x: %v", p, err)
+		}
+		if !bytes.Equal(crypto.FromECDSAPub(pub), point) {
+			t.Fatalf("wrong public key")
+		}
+	}
+	if _, err := parsePublicKey(nil, point); err == nil {
+		t.Fatal("no error for missing curve")
+	}
+	if _, err := parsePublicKey(params, point[:40]); err == nil {
+		t.Fatal("no error for truncated point")
+	}
+}
+
+func TestNewHubMissingModule(t *testing.T) {
+	if _, err := NewHub("/nonexistent/libpkcs11.so", ""); err == nil {
+		t.Fatal("no error for missing module")
+	}
+}
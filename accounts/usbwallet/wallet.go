@@ -87,6 +87,7 @@ type wallet struct {
 
 	deriveNextPaths []accounts.DerivationPath // Next derivation paths for account auto-discovery (multiple bases supported)
 	deriveNextAddrs []common.Address          // Next derived account addresses for auto-discovery (multiple bases supported)
+	deriveIndices   []int                     // Account index component of the derivation paths, incremented during auto-discovery
 	deriveChain     ethereum.ChainStateReader // Blockchain state reader to discover used account with
 	deriveReq       chan chan struct{}        // Channel to request a self-derivation on
 	deriveQuit      chan chan error           // Channel to terminate the self-deriver with
@@ -343,6 +344,7 @@ func (w *wallet) selfDerive() {
 
 			nextPaths = append([]accounts.DerivationPath{}, w.deriveNextPaths...)
 			nextAddrs = append([]common.Address{}, w.deriveNextAddrs...)
+			indices   = w.deriveIndices
 
 			context = context.Background()
 		)
@@ -399,7 +401,7 @@ func (w *wallet) selfDerive() {
 				// Fetch the next potential account
 				if !empty {
 					nextAddrs[i] = common.Address{}
-					nextPaths[i][len(nextPaths[i])-1]++
+					nextPaths[i][indices[i]]++
 				}
 			}
 		}
@@ -510,15 +512,28 @@ func (w *wallet) Derive(path accounts.DerivationPath, pin bool) (accounts.Accoun
 // You can disable automatic account discovery by calling SelfDerive with a nil
 // chain state reader.
 func (w *wallet) SelfDerive(bases []accounts.DerivationPath, chain ethereum.ChainStateReader) {
+	templates := make([]accounts.DerivationTemplate, len(bases))
+	for i, base := range bases {
+		templates[i] = accounts.DerivationTemplate{Base: base, Index: len(base) - 1}
+	}
+	w.SelfDeriveTemplates(templates, chain)
+}
+
+// SelfDeriveTemplates is like SelfDerive, but increments the account index
+// component of the templates instead of the last component of the paths. This
+// allows discovering accounts of schemes such as Ledger Live's m/44'/60'/x'/0/0.
+func (w *wallet) SelfDeriveTemplates(templates []accounts.DerivationTemplate, chain ethereum.ChainStateReader) {
 	w.stateLock.Lock()
 	defer w.stateLock.Unlock()
 
-	w.deriveNextPaths = make([]accounts.DerivationPath, len(bases))
-	for i, base := range bases {
-		w.deriveNextPaths[i] = make(accounts.DerivationPath, len(base))
-		copy(w.deriveNextPaths[i][:], base[:])
+	w.deriveNextPaths = make([]accounts.DerivationPath, len(templates))
+	w.deriveIndices = make([]int, len(templates))
+	for i, template := range templates {
+		w.deriveNextPaths[i] = make(accounts.DerivationPath, len(template.Base))
+		copy(w.deriveNextPaths[i][:], template.Base[:])
+		w.deriveIndices[i] = template.Index
 	}
-	w.deriveNextAddrs = make([]common.Address, len(bases))
+	w.deriveNextAddrs = make([]common.Address, len(templates))
 	w.deriveChain = chain
 }
 
@@ -0,0 +1,367 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vault
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	tokenEnv  = "VAULT_TOKEN"  // token of the token auth method, if no file is given
+	caCertEnv = "VAULT_CACERT" // PEM file of the CA certificates of the server
+
+	defaultJWTPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+	minRenewDelay = time.Second // minimum delay between token renewals
+)
+
+// vaultError is an error returned by Vault.
+type vaultError struct {
+	Code   int
+	Errors []string `json:"errors"`
+}
+
+func (e *vaultError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("Vault error %d", e.Code)
+	}
+	return fmt.Sprintf("Vault error %d: %s", e.Code, strings.Join(e.Errors, ", "))
+}
+
+// authInfo is the auth section of Vault responses.
+type authInfo struct {
+	ClientToken   string `json:"client_token"`
+	LeaseDuration int64  `json:"lease_duration"`
+	Renewable     bool   `json:"renewable"`
+}
+
+// client is a client of the Vault HTTP API, logged in with the auth method of
+// its configuration. The token is renewed in the background until closed.
+type client struct {
+	addr string
+	auth Auth
+	http *http.Client
+
+	mu        sync.Mutex
+	token     string
+	ttl       time.Duration // TTL of the token at its last renewal, zero if it doesn't expire
+	renewable bool
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+func newClient(ctx context.Context, config *Config) (*client, error) {
+	c := &client{
+		addr: strings.TrimSuffix(config.Address, "/"),
+		auth: config.Auth,
+		http: new(http.Client),
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if file := os.Getenv(caCertEnv); file != "" {
+		pem, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("can't read Vault CA certificates: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", file)
+		}
+		c.http.Transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}
+	}
+	if err := c.login(ctx); err != nil {
+		return nil, err
+	}
+	go c.renewLoop()
+	return c, nil
+}
+
+// close stops the renewal of the token.
+func (c *client) close() {
+	close(c.quit)
+	<-c.done
+}
+
+// login obtains a token with the configured auth method.
+func (c *client) login(ctx context.Context) error {
+	var (
+		auth *authInfo
+		err  error
+	)
+	switch c.auth.Method {
+	case "", AuthToken:
+		auth, err = c.tokenLogin(ctx)
+	case AuthAppRole:
+		auth, err = c.appRoleLogin(ctx)
+	case AuthKubernetes:
+		auth, err = c.kubernetesLogin(ctx)
+	default:
+		return fmt.Errorf("unknown Vault auth method %q", c.auth.Method)
+	}
+	if err != nil {
+		return fmt.Errorf("Vault login failed: %w", err)
+	}
+	c.setToken(auth)
+	return nil
+}
+
+func (c *client) setToken(auth *authInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.token = auth.ClientToken
+	c.ttl = time.Duration(auth.LeaseDuration) * time.Second
+	c.renewable = auth.Renewable
+}
+
+// tokenLogin uses the token of the secret file, or of the environment, looking up
+// its TTL. Files are re-read on every login, so they can be rotated by an agent.
+func (c *client) tokenLogin(ctx context.Context) (*authInfo, error) {
+	token := os.Getenv(tokenEnv)
+	if c.auth.SecretFile != "" {
+		secret, err := readSecret(c.auth.SecretFile)
+		if err != nil {
+			return nil, err
+		}
+		token = secret
+	}
+	if token == "" {
+		return nil, errors.New("no Vault token")
+	}
+	var resp struct {
+		Data struct {
+			TTL       int64 `json:"ttl"`
+			Renewable bool  `json:"renewable"`
+		} `json:"data"`
+	}
+	if err := c.do(ctx, token, http.MethodGet, "auth/token/lookup-self", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &authInfo{ClientToken: token, LeaseDuration: resp.Data.TTL, Renewable: resp.Data.Renewable}, nil
+}
+
+func (c *client) appRoleLogin(ctx context.Context) (*authInfo, error) {
+	secretID, err := readSecret(c.auth.SecretFile)
+	if err != nil {
+		return nil, err
+	}
+	req := map[string]string{"role_id": c.auth.Role, "secret_id": secretID}
+	return c.authLogin(ctx, "approle", req)
+}
+
+func (c *client) kubernetesLogin(ctx context.Context) (*authInfo, error) {
+	path := c.auth.SecretFile
+	if path == "" {
+		path = defaultJWTPath
+	}
+	jwt, err := readSecret(path)
+	if err != nil {
+		return nil, err
+	}
+	req := map[string]string{"role": c.auth.Role, "jwt": jwt}
+	return c.authLogin(ctx, "kubernetes", req)
+}
+
+func (c *client) authLogin(ctx context.Context, method string, req interface{}) (*authInfo, error) {
+	mount := c.auth.Mount
+	if mount == "" {
+		mount = method
+	}
+	var resp struct {
+		Auth *authInfo `json:"auth"`
+	}
+	if err := c.do(ctx, "", http.MethodPost, "auth/"+mount+"/login", req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return nil, errors.New("no token in login response")
+	}
+	return resp.Auth, nil
+}
+
+// renewLoop renews the token when two thirds of its TTL have passed, logging in
+// again if it can't be renewed.
+func (c *client) renewLoop() {
+	defer close(c.done)
+
+	for {
+		c.mu.Lock()
+		ttl := c.ttl
+		c.mu.Unlock()
+		if ttl == 0 {
+			// The token doesn't expire
+			return
+		}
+		select {
+		case <-time.After(max(ttl*2/3, minRenewDelay)):
+		case <-c.quit:
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		if err := c.renew(ctx); err != nil {
+			log.Warn("Failed to renew Vault token, logging in again", "err", err)
+			if err := c.login(ctx); err != nil {
+				log.Error("Failed to log in to Vault", "err", err)
+			}
+		}
+		cancel()
+	}
+}
+
+// renew extends the TTL of the token.
+func (c *client) renew(ctx context.Context) error {
+	c.mu.Lock()
+	token, renewable := c.token, c.renewable
+	c.mu.Unlock()
+
+	if !renewable {
+		return errors.New("token not renewable")
+	}
+	var resp struct {
+		Auth *authInfo `json:"auth"`
+	}
+	if err := c.do(ctx, token, http.MethodPost, "auth/token/renew-self", struct{}{}, &resp); err != nil {
+		return err
+	}
+	if resp.Auth == nil {
+		return errors.New("no token in renewal response")
+	}
+	if resp.Auth.ClientToken == "" {
+		resp.Auth.ClientToken = token
+	}
+	c.setToken(resp.Auth)
+	log.Debug("Renewed Vault token", "ttl", time.Duration(resp.Auth.LeaseDuration)*time.Second)
+	return nil
+}
+
+// readKV reads a field of a secret of a KV version 2 engine.
+func (c *client) readKV(ctx context.Context, mount, path, field string) (string, error) {
+	var resp struct {
+		Data struct {
+			Data map[string]string `json:"data"`
+		} `json:"data"`
+	}
+	if err := c.call(ctx, http.MethodGet, mount+"/data/"+path, nil, &resp); err != nil {
+		return "", err
+	}
+	value, ok := resp.Data.Data[field]
+	if !ok {
+		return "", fmt.Errorf("no field %q in secret %s", field, path)
+	}
+	return value, nil
+}
+
+// decrypt decrypts a ciphertext with a key of a transit engine.
+func (c *client) decrypt(ctx context.Context, mount, key, ciphertext string) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	req := map[string]string{"ciphertext": ciphertext}
+	if err := c.call(ctx, http.MethodPost, mount+"/decrypt/"+key, req, &resp); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+}
+
+// call sends a request with the client token, logging in again and repeating the
+// request once if the token is no longer valid.
+func (c *client) call(ctx context.Context, method, path string, req, resp interface{}) error {
+	c.mu.Lock()
+	token := c.token
+	c.mu.Unlock()
+
+	err := c.do(ctx, token, method, path, req, resp)
+	var verr *vaultError
+	if errors.As(err, &verr) && verr.Code == http.StatusForbidden {
+		log.Debug("Vault token rejected, logging in again", "err", err)
+		if err := c.login(ctx); err != nil {
+			return err
+		}
+		c.mu.Lock()
+		token = c.token
+		c.mu.Unlock()
+		err = c.do(ctx, token, method, path, req, resp)
+	}
+	return err
+}
+
+// do sends a request to the API.
+func (c *client) do(ctx context.Context, token, method, path string, req, resp interface{}) error {
+	var body io.Reader
+	if req != nil {
+		data, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	hreq, err := http.NewRequestWithContext(ctx, method, c.addr+"/v1/"+path, body)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		hreq.Header.Set("X-Vault-Token", token)
+	}
+	if req != nil {
+		hreq.Header.Set("Content-Type", "application/json")
+	}
+	hresp, err := c.http.Do(hreq)
+	if err != nil {
+		return err
+	}
+	defer hresp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(hresp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if hresp.StatusCode != http.StatusOK {
+		verr := &vaultError{Code: hresp.StatusCode}
+		json.Unmarshal(data, verr)
+		return verr
+	}
+	return json.Unmarshal(data, resp)
+}
+
+// readSecret reads a secret from a file, trimming the trailing newline.
+func readSecret(path string) (string, error) {
+	if path == "" {
+		return "", errors.New("no secret file")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
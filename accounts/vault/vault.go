@@ -0,0 +1,293 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package vault implements an accounts backend signing with keys stored in a
+// HashiCorp Vault server, instead of an on-disk keystore.
+//
+// Every key is a secret of a KV version 2 engine, whose "key" field holds the
+// hex encoded private key. If a transit key is configured, the field holds the
+// private key encrypted by the transit engine instead, so that reading the secret
+// alone doesn't reveal the key. Keys are fetched for every signing operation and
+// wiped afterwards: revoking access in Vault stops the node from signing.
+//
+// The backend logs in with a token, AppRole or Kubernetes auth, and renews its
+// token in the background, logging in again if the token can't be renewed. The
+// CA certificates of the server can be given in the VAULT_CACERT file.
+package vault
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Scheme is the URL scheme of Vault keys.
+const Scheme = "vault"
+
+// Auth methods.
+const (
+	AuthToken      = "token"
+	AuthAppRole    = "approle"
+	AuthKubernetes = "kubernetes"
+)
+
+// keyField is the field of the secrets holding the keys.
+const keyField = "key"
+
+const (
+	requestTimeout = 10 * time.Second // timeout of a single request
+	signTimeout    = 30 * time.Second // timeout of a signing operation
+)
+
+// Auth configures the login to Vault.
+type Auth struct {
+	Method     string // AuthToken, AuthAppRole or AuthKubernetes
+	Mount      string // Mount path of the auth method, defaults to its name
+	Role       string // Role ID of AppRole auth, role of Kubernetes auth
+	SecretFile string // File of the token, AppRole secret ID or Kubernetes service account token
+}
+
+// Config is the configuration of the Vault backend.
+type Config struct {
+	Address string   // URL of the Vault server
+	Auth    Auth     // Login to the server
+	Mount   string   // Mount path of the KV version 2 engine, "secret" by default
+	Transit string   // "<mount>/<key>" of the transit key encrypting the keys, if any
+	Keys    []string // Paths of the keys within the KV engine
+}
+
+// Backend is an accounts backend with one wallet for every key stored in Vault.
+type Backend struct {
+	client  *client
+	wallets []accounts.Wallet
+}
+
+// NewBackend logs in to Vault and loads the configured keys to derive their
+// addresses. The token of the backend is renewed until it is closed.
+func NewBackend(ctx context.Context, config Config) (*Backend, error) {
+	if config.Address == "" {
+		return nil, errors.New("no Vault address")
+	}
+	if config.Mount == "" {
+		config.Mount = "secret"
+	}
+	var transitMount, transitKey string
+	if config.Transit != "" {
+		var ok bool
+		if transitMount, transitKey, ok = cutLast(config.Transit, "/"); !ok {
+			return nil, fmt.Errorf("invalid transit key %q, want <mount>/<key>", config.Transit)
+		}
+	}
+	c, err := newClient(ctx, &config)
+	if err != nil {
+		return nil, err
+	}
+	b := &Backend{client: c}
+	for _, path := range config.Keys {
+		w := &wallet{
+			client:       c,
+			mount:        config.Mount,
+			transitMount: transitMount,
+			transitKey:   transitKey,
+			url:          accounts.URL{Scheme: Scheme, Path: path},
+		}
+		key, err := w.loadKey(ctx)
+		if err != nil {
+			c.close()
+			return nil, fmt.Errorf("Vault key %s: %v", path, err)
+		}
+		w.account = accounts.Account{Address: crypto.PubkeyToAddress(key.PublicKey), URL: w.url}
+		zeroKey(key)
+
+		log.Info("Loaded Vault signing key", "path", path, "address", w.account.Address)
+		b.wallets = append(b.wallets, w)
+	}
+	return b, nil
+}
+
+// Close stops the renewal of the Vault token.
+func (b *Backend) Close() {
+	b.client.close()
+}
+
+// Wallets implements accounts.Backend, returning the wallets of the keys.
+func (b *Backend) Wallets() []accounts.Wallet {
+	return b.wallets
+}
+
+// Subscribe implements accounts.Backend. The set of keys is fixed, so no events
+// are sent.
+func (b *Backend) Subscribe(sink chan<- accounts.WalletEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
+// wallet is the wallet of a single key stored in Vault.
+type wallet struct {
+	client       *client
+	mount        string
+	transitMount string // Empty if the key is stored in plain
+	transitKey   string
+	url          accounts.URL
+	account      accounts.Account
+}
+
+// loadKey fetches the private key from Vault, decrypting it if needed.
+func (w *wallet) loadKey(ctx context.Context) (*ecdsa.PrivateKey, error) {
+	value, err := w.client.readKV(ctx, w.mount, w.url.Path, keyField)
+	if err != nil {
+		return nil, err
+	}
+	if w.transitKey != "" {
+		plain, err := w.client.decrypt(ctx, w.transitMount, w.transitKey, value)
+		if err != nil {
+			return nil, err
+		}
+		value = string(plain)
+		clear(plain)
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(value), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid key: %v", err)
+	}
+	return key, nil
+}
+
+// URL implements accounts.Wallet, returning the URL of the key.
+func (w *wallet) URL() accounts.URL {
+	return w.url
+}
+
+// Status implements accounts.Wallet. The keys are always available.
+func (w *wallet) Status() (string, error) {
+	return "ok", nil
+}
+
+// Open implements accounts.Wallet. Vault keys need no opening.
+func (w *wallet) Open(passphrase string) error {
+	return nil
+}
+
+// Close implements accounts.Wallet.
+func (w *wallet) Close() error {
+	return nil
+}
+
+// Accounts implements accounts.Wallet, returning the account of the key.
+func (w *wallet) Accounts() []accounts.Account {
+	return []accounts.Account{w.account}
+}
+
+// Contains implements accounts.Wallet, reporting whether the account is the one
+// of the key.
+func (w *wallet) Contains(account accounts.Account) bool {
+	return account.Address == w.account.Address && (account.URL == (accounts.URL{}) || account.URL == w.url)
+}
+
+// Derive implements accounts.Wallet, but is not supported by Vault keys.
+func (w *wallet) Derive(path accounts.DerivationPath, pin bool) (accounts.Account, error) {
+	return accounts.Account{}, accounts.ErrNotSupported
+}
+
+// SelfDerive implements accounts.Wallet, but is a noop for Vault keys.
+func (w *wallet) SelfDerive(bases []accounts.DerivationPath, chain ethereum.ChainStateReader) {
+}
+
+// SignData implements accounts.Wallet, signing keccak256(data).
+func (w *wallet) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	return w.signHash(account, crypto.Keccak256(data))
+}
+
+// SignDataWithPassphrase implements accounts.Wallet. Vault keys don't rely on
+// passphrases, so it is ignored.
+func (w *wallet) SignDataWithPassphrase(account accounts.Account, passphrase, mimeType string, data []byte) ([]byte, error) {
+	return w.SignData(account, mimeType, data)
+}
+
+// SignText implements accounts.Wallet, signing the hash of the given text with
+// the Ethereum signed message prefix.
+func (w *wallet) SignText(account accounts.Account, text []byte) ([]byte, error) {
+	return w.signHash(account, accounts.TextHash(text))
+}
+
+// SignTextWithPassphrase implements accounts.Wallet. Vault keys don't rely on
+// passphrases, so it is ignored.
+func (w *wallet) SignTextWithPassphrase(account accounts.Account, passphrase string, text []byte) ([]byte, error) {
+	return w.SignText(account, text)
+}
+
+// SignTx implements accounts.Wallet, signing the transaction with the key.
+func (w *wallet) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	signer := types.LatestSignerForChainID(chainID)
+	sig, err := w.signHash(account, signer.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, sig)
+}
+
+// SignTxWithPassphrase implements accounts.Wallet. Vault keys don't rely on
+// passphrases, so it is ignored.
+func (w *wallet) SignTxWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return w.SignTx(account, tx, chainID)
+}
+
+// signHash fetches the key from Vault to sign a hash, wiping it afterwards.
+func (w *wallet) signHash(account accounts.Account, hash []byte) ([]byte, error) {
+	if !w.Contains(account) {
+		return nil, accounts.ErrUnknownAccount
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), signTimeout)
+	defer cancel()
+
+	key, err := w.loadKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't load Vault key %s: %v", w.url.Path, err)
+	}
+	defer zeroKey(key)
+
+	// The secret may have been replaced since the address was derived
+	if crypto.PubkeyToAddress(key.PublicKey) != w.account.Address {
+		return nil, fmt.Errorf("Vault key %s changed, no longer the key of %v", w.url.Path, w.account.Address)
+	}
+	return crypto.Sign(hash, key)
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i > 0 && i < len(s)-len(sep) {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// zeroKey zeroes a private key in memory.
+func zeroKey(k *ecdsa.PrivateKey) {
+	clear(k.D.Bits())
+}
@@ -0,0 +1,237 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vault
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testVault is a fake Vault server with a KV version 2 engine mounted at "secret",
+// a transit engine mounted at "transit" and AppRole auth.
+type testVault struct {
+	mu       sync.Mutex
+	secrets  map[string]string // Key field of the secrets by path
+	tokens   map[string]bool   // Valid tokens
+	lease    int64             // Lease duration of the tokens issued by login
+	logins   int
+	renewals int
+}
+
+func newTestVault(t *testing.T) (*testVault, string) {
+	v := &testVault{secrets: make(map[string]string), tokens: map[string]bool{"root": true}}
+	server := httptest.NewServer(v)
+	t.Cleanup(server.Close)
+	return v, server.URL
+}
+
+func (v *testVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var req map[string]string
+	json.NewDecoder(r.Body).Decode(&req)
+	reply := func(resp interface{}) {
+		json.NewEncoder(w).Encode(resp)
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	if path == "auth/approle/login" {
+		if req["role_id"] != "validator" || req["secret_id"] != "s3cr3t" {
+			http.Error(w, `{"errors":["invalid role or secret ID"]}`, http.StatusBadRequest)
+			return
+		}
+		v.logins++
+		token := fmt.Sprintf("token-%d", v.logins)
+		v.tokens[token] = true
+		reply(map[string]interface{}{"auth": authInfo{ClientToken: token, LeaseDuration: v.lease, Renewable: true}})
+		return
+	}
+	token := r.Header.Get("X-Vault-Token")
+	if !v.tokens[token] {
+		http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+		return
+	}
+	switch {
+	case path == "auth/token/lookup-self":
+		reply(map[string]interface{}{"data": map[string]interface{}{"ttl": 0}})
+	case path == "auth/token/renew-self":
+		v.renewals++
+		reply(map[string]interface{}{"auth": authInfo{ClientToken: token, LeaseDuration: v.lease, Renewable: true}})
+	case strings.HasPrefix(path, "secret/data/"):
+		key, ok := v.secrets[strings.TrimPrefix(path, "secret/data/")]
+		if !ok {
+			http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+			return
+		}
+		reply(map[string]interface{}{"data": map[string]interface{}{"data": map[string]string{keyField: key}}})
+	case path == "transit/decrypt/validators":
+		// The fake transit engine doesn't encrypt, it only encodes
+		plain, ok := strings.CutPrefix(req["ciphertext"], "vault:v1:")
+		if !ok {
+			http.Error(w, `{"errors":["invalid ciphertext"]}`, http.StatusBadRequest)
+			return
+		}
+		reply(map[string]interface{}{"data": map[string]string{"plaintext": plain}})
+	default:
+		http.Error(w, `{"errors":["unsupported path"]}`, http.StatusNotFound)
+	}
+}
+
+func (v *testVault) setKey(path string, key *ecdsa.PrivateKey, transit bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	value := hex.EncodeToString(crypto.FromECDSA(key))
+	if transit {
+		value = "vault:v1:" + base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	v.secrets[path] = value
+}
+
+func writeSecret(t *testing.T, secret string) string {
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte(secret+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func checkSignTx(t *testing.T, w accounts.Wallet, account accounts.Account) {
+	t.Helper()
+
+	tx := types.NewTransaction(0, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	signed, err := w.SignTx(account, tx, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("signing failed: %v", err)
+	}
+	if from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), signed); err != nil || from != account.Address {
+		t.Fatalf("wrong sender %v, want %v: %v", from, account.Address, err)
+	}
+}
+
+func TestBackend(t *testing.T) {
+	vault, addr := newTestVault(t)
+	key, _ := crypto.GenerateKey()
+	vault.setKey("validators/0", key, false)
+
+	b, err := NewBackend(context.Background(), Config{
+		Address: addr,
+		Auth:    Auth{Method: AuthToken, SecretFile: writeSecret(t, "root")},
+		Keys:    []string{"validators/0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	wallets := b.Wallets()
+	if len(wallets) != 1 {
+		t.Fatalf("%d wallets, want 1", len(wallets))
+	}
+	account := wallets[0].Accounts()[0]
+	if account.Address != crypto.PubkeyToAddress(key.PublicKey) || account.URL.String() != "vault://validators/0" {
+		t.Fatalf("wrong account %v", account)
+	}
+	checkSignTx(t, wallets[0], account)
+
+	// Replacing the secret must not make the wallet sign with another key
+	other, _ := crypto.GenerateKey()
+	vault.setKey("validators/0", other, false)
+	if _, err := wallets[0].SignText(account, []byte("hello")); err == nil {
+		t.Fatal("signed with replaced key")
+	}
+	// Neither must it sign once the key is deleted
+	vault.mu.Lock()
+	delete(vault.secrets, "validators/0")
+	vault.mu.Unlock()
+	if _, err := wallets[0].SignText(account, []byte("hello")); err == nil {
+		t.Fatal("signed with deleted key")
+	}
+}
+
+func TestBackendTransit(t *testing.T) {
+	vault, addr := newTestVault(t)
+	vault.lease = 1
+	key, _ := crypto.GenerateKey()
+	vault.setKey("validators/0", key, true)
+
+	b, err := NewBackend(context.Background(), Config{
+		Address: addr,
+		Auth:    Auth{Method: AuthAppRole, Role: "validator", SecretFile: writeSecret(t, "s3cr3t")},
+		Transit: "transit/validators",
+		Keys:    []string{"validators/0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	w := b.Wallets()[0]
+	account := w.Accounts()[0]
+	if account.Address != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("wrong address %v", account.Address)
+	}
+	checkSignTx(t, w, account)
+
+	// The token must be renewed before it expires
+	time.Sleep(1500 * time.Millisecond)
+	vault.mu.Lock()
+	renewals := vault.renewals
+	vault.mu.Unlock()
+	if renewals == 0 {
+		t.Fatal("token not renewed")
+	}
+	// A revoked token must be replaced by logging in again
+	vault.mu.Lock()
+	clear(vault.tokens)
+	vault.mu.Unlock()
+	checkSignTx(t, w, account)
+	vault.mu.Lock()
+	defer vault.mu.Unlock()
+	if vault.logins < 2 {
+		t.Fatal("not logged in again after revocation")
+	}
+}
+
+func TestBackendLoginFailure(t *testing.T) {
+	_, addr := newTestVault(t)
+	_, err := NewBackend(context.Background(), Config{
+		Address: addr,
+		Auth:    Auth{Method: AuthAppRole, Role: "validator", SecretFile: writeSecret(t, "wrong")},
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid role or secret ID") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
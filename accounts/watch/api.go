@@ -0,0 +1,60 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package watch
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// APIs returns the RPC APIs of the address book, in the "addressbook" namespace.
+// Editing the address book is only available on the authenticated endpoints.
+func APIs(book *AddressBook) []rpc.API {
+	return []rpc.API{{
+		Namespace: "addressbook",
+		Service:   &API{book: book},
+	}, {
+		Namespace:     "addressbook",
+		Service:       &EditAPI{book: book},
+		Authenticated: true,
+	}}
+}
+
+// API exposes the contents of the address book over RPC.
+type API struct {
+	book *AddressBook
+}
+
+// List returns the watch-only accounts with their labels.
+func (api *API) List() []Entry {
+	return api.book.Entries()
+}
+
+// EditAPI exposes the modification of the address book over RPC.
+type EditAPI struct {
+	book *AddressBook
+}
+
+// Add adds a watch-only account, or changes the label of an existing one.
+func (api *EditAPI) Add(addr common.Address, label string) error {
+	return api.book.Add(addr, label)
+}
+
+// Remove removes a watch-only account.
+func (api *EditAPI) Remove(addr common.Address) error {
+	return api.book.Remove(addr)
+}
@@ -0,0 +1,288 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package watch implements an address book of watch-only accounts.
+//
+// Watch-only accounts have no keys. They are listed by the account manager like
+// any other account, so that they can be tracked and used to assemble transactions
+// to be signed elsewhere, but all signing requests fail with ErrWatchOnly.
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Scheme is the URL scheme of watch-only accounts.
+const Scheme = "watch"
+
+// ErrWatchOnly is returned when signing with a watch-only account.
+var ErrWatchOnly = errors.New("watch-only account can't sign")
+
+// Entry is an account of the address book.
+type Entry struct {
+	Address common.Address `json:"address"`
+	Label   string         `json:"label,omitempty"`
+}
+
+// AddressBook is a backend of watch-only accounts, persisted to a JSON file.
+type AddressBook struct {
+	path string // File of the address book, empty if not persisted
+
+	mu      sync.RWMutex
+	entries []Entry // Entries sorted by address
+	wallets []accounts.Wallet
+
+	feed  event.Feed
+	scope event.SubscriptionScope
+}
+
+// NewAddressBook loads the address book stored at path, which is created when
+// adding the first entry. An empty path keeps the address book in memory.
+func NewAddressBook(path string) (*AddressBook, error) {
+	b := &AddressBook{path: path}
+	if path == "" {
+		return b, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		b.insert(e)
+	}
+	return b, nil
+}
+
+// insert adds or relabels an entry, returning whether the address is new.
+func (b *AddressBook) insert(e Entry) bool {
+	i, found := slices.BinarySearchFunc(b.entries, e.Address, func(e Entry, addr common.Address) int {
+		return e.Address.Cmp(addr)
+	})
+	if found {
+		b.entries[i].Label = e.Label
+		return false
+	}
+	b.entries = slices.Insert(b.entries, i, e)
+	b.wallets = slices.Insert(b.wallets, i, accounts.Wallet(newWallet(e.Address)))
+	return true
+}
+
+// Add adds a watch-only account to the address book, or changes the label of an
+// existing one.
+func (b *AddressBook) Add(addr common.Address, label string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	prev := slices.Clone(b.entries)
+	added := b.insert(Entry{Address: addr, Label: label})
+	if err := b.save(); err != nil {
+		b.restore(prev)
+		return err
+	}
+	if added {
+		b.feed.Send(accounts.WalletEvent{Wallet: b.wallets[b.index(addr)], Kind: accounts.WalletArrived})
+	}
+	return nil
+}
+
+// Remove removes a watch-only account from the address book.
+func (b *AddressBook) Remove(addr common.Address) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	i := b.index(addr)
+	if i < 0 {
+		return accounts.ErrUnknownAccount
+	}
+	prev, wallet := slices.Clone(b.entries), b.wallets[i]
+	b.entries = slices.Delete(b.entries, i, i+1)
+	b.wallets = slices.Delete(b.wallets, i, i+1)
+	if err := b.save(); err != nil {
+		b.restore(prev)
+		return err
+	}
+	b.feed.Send(accounts.WalletEvent{Wallet: wallet, Kind: accounts.WalletDropped})
+	return nil
+}
+
+// restore resets the entries after a failure to persist them.
+func (b *AddressBook) restore(entries []Entry) {
+	b.entries, b.wallets = nil, nil
+	for _, e := range entries {
+		b.insert(e)
+	}
+}
+
+func (b *AddressBook) index(addr common.Address) int {
+	i, found := slices.BinarySearchFunc(b.entries, addr, func(e Entry, addr common.Address) int {
+		return e.Address.Cmp(addr)
+	})
+	if !found {
+		return -1
+	}
+	return i
+}
+
+// save writes the address book to its file.
+func (b *AddressBook) save() error {
+	if b.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(b.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(b.path), 0700); err != nil {
+		return err
+	}
+	tmp := b.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, b.path)
+}
+
+// Entries returns the accounts of the address book, sorted by address.
+func (b *AddressBook) Entries() []Entry {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return slices.Clone(b.entries)
+}
+
+// Label returns the label of an account, and whether it is in the address book.
+func (b *AddressBook) Label(addr common.Address) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if i := b.index(addr); i >= 0 {
+		return b.entries[i].Label, true
+	}
+	return "", false
+}
+
+// Wallets implements accounts.Backend, returning a wallet per watch-only account.
+func (b *AddressBook) Wallets() []accounts.Wallet {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return slices.Clone(b.wallets)
+}
+
+// Subscribe implements accounts.Backend, notifying of added and removed accounts.
+func (b *AddressBook) Subscribe(sink chan<- accounts.WalletEvent) event.Subscription {
+	return b.scope.Track(b.feed.Subscribe(sink))
+}
+
+// wallet is the wallet of a watch-only account.
+type wallet struct {
+	account accounts.Account
+}
+
+func newWallet(addr common.Address) *wallet {
+	return &wallet{account: accounts.Account{
+		Address: addr,
+		URL:     accounts.URL{Scheme: Scheme, Path: addr.Hex()},
+	}}
+}
+
+// URL implements accounts.Wallet.
+func (w *wallet) URL() accounts.URL {
+	return w.account.URL
+}
+
+// Status implements accounts.Wallet.
+func (w *wallet) Status() (string, error) {
+	return "Watch-only", nil
+}
+
+// Open implements accounts.Wallet, watch-only wallets need no opening.
+func (w *wallet) Open(passphrase string) error {
+	return nil
+}
+
+// Close implements accounts.Wallet.
+func (w *wallet) Close() error {
+	return nil
+}
+
+// Accounts implements accounts.Wallet, returning the watch-only account.
+func (w *wallet) Accounts() []accounts.Account {
+	return []accounts.Account{w.account}
+}
+
+// Contains implements accounts.Wallet.
+func (w *wallet) Contains(account accounts.Account) bool {
+	return account.Address == w.account.Address && (account.URL == (accounts.URL{}) || account.URL == w.account.URL)
+}
+
+// Derive implements accounts.Wallet, but is not supported by watch-only wallets.
+func (w *wallet) Derive(path accounts.DerivationPath, pin bool) (accounts.Account, error) {
+	return accounts.Account{}, accounts.ErrNotSupported
+}
+
+// SelfDerive implements accounts.Wallet, but is a noop for watch-only wallets.
+func (w *wallet) SelfDerive(bases []accounts.DerivationPath, chain ethereum.ChainStateReader) {
+}
+
+// SignData implements accounts.Wallet, always failing with ErrWatchOnly.
+func (w *wallet) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	return nil, ErrWatchOnly
+}
+
+// SignDataWithPassphrase implements accounts.Wallet, always failing with ErrWatchOnly.
+func (w *wallet) SignDataWithPassphrase(account accounts.Account, passphrase, mimeType string, data []byte) ([]byte, error) {
+	return nil, ErrWatchOnly
+}
+
+// SignText implements accounts.Wallet, always failing with ErrWatchOnly.
+func (w *wallet) SignText(account accounts.Account, text []byte) ([]byte, error) {
+	return nil, ErrWatchOnly
+}
+
+// SignTextWithPassphrase implements accounts.Wallet, always failing with ErrWatchOnly.
+func (w *wallet) SignTextWithPassphrase(account accounts.Account, passphrase string, hash []byte) ([]byte, error) {
+	return nil, ErrWatchOnly
+}
+
+// SignTx implements accounts.Wallet, always failing with ErrWatchOnly.
+func (w *wallet) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return nil, ErrWatchOnly
+}
+
+// SignTxWithPassphrase implements accounts.Wallet, always failing with ErrWatchOnly.
+func (w *wallet) SignTxWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return nil, ErrWatchOnly
+}
@@ -0,0 +1,149 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package watch
+
+import (
+	"errors"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestAddressBook(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "addressbook.json")
+	book, err := NewAddressBook(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	am := accounts.NewManager(&accounts.Config{}, book)
+	defer am.Close()
+
+	events := make(chan accounts.WalletEvent, 4)
+	sub := am.Subscribe(events)
+	defer sub.Unsubscribe()
+
+	var (
+		treasury = common.HexToAddress("0x2222222222222222222222222222222222222222")
+		cold     = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	)
+	if err := book.Add(treasury, "treasury"); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.Add(cold, "cold"); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.Add(cold, "cold storage"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case ev := <-events:
+			if ev.Kind != accounts.WalletArrived {
+				t.Fatalf("event %d: kind %v, want arrival", i, ev.Kind)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d not received", i)
+		}
+	}
+	if accs := am.Accounts(); !reflect.DeepEqual(accs, []common.Address{cold, treasury}) {
+		t.Fatalf("wrong accounts %v", accs)
+	}
+	if label, ok := book.Label(cold); !ok || label != "cold storage" {
+		t.Fatalf("wrong label %q", label)
+	}
+
+	// Signing fails, as there is no key
+	wallet, err := am.Find(accounts.Account{Address: cold})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := types.NewTransaction(0, treasury, big.NewInt(1), 21000, big.NewInt(1), nil)
+	if _, err := wallet.SignTx(accounts.Account{Address: cold}, tx, big.NewInt(1)); !errors.Is(err, ErrWatchOnly) {
+		t.Fatalf("signing error %v, want %v", err, ErrWatchOnly)
+	}
+
+	// Removing an account drops its wallet, and the book is persisted
+	if err := book.Remove(treasury); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.Remove(treasury); !errors.Is(err, accounts.ErrUnknownAccount) {
+		t.Fatalf("removing unknown account: %v", err)
+	}
+	select {
+	case ev := <-events:
+		if ev.Kind != accounts.WalletDropped {
+			t.Fatalf("kind %v, want drop", ev.Kind)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("drop event not received")
+	}
+	reloaded, err := NewAddressBook(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries := reloaded.Entries(); !reflect.DeepEqual(entries, []Entry{{Address: cold, Label: "cold storage"}}) {
+		t.Fatalf("wrong entries after reload %v", entries)
+	}
+}
+
+// This test checks that the address book can only be edited over the
+// authenticated endpoints.
+func TestAPIs(t *testing.T) {
+	book, err := NewAddressBook(filepath.Join(t.TempDir(), "addressbook.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var open, all []rpc.API
+	for _, api := range APIs(book) {
+		if !api.Authenticated {
+			open = append(open, api)
+		}
+		all = append(all, api)
+	}
+	call := func(apis []rpc.API, method string, args ...interface{}) error {
+		srv := rpc.NewServer()
+		defer srv.Stop()
+		for _, api := range apis {
+			if err := srv.RegisterName(api.Namespace, api.Service); err != nil {
+				t.Fatal(err)
+			}
+		}
+		client := rpc.DialInProc(srv)
+		defer client.Close()
+		return client.Call(nil, method, args...)
+	}
+	addr := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	if err := call(open, "addressbook_add", addr, "cold"); err == nil {
+		t.Fatal("address book edited over an unauthenticated endpoint")
+	}
+	if err := call(open, "addressbook_list"); err != nil {
+		t.Fatalf("can't list the address book: %v", err)
+	}
+	if err := call(all, "addressbook_add", addr, "cold"); err != nil {
+		t.Fatalf("can't edit the address book: %v", err)
+	}
+	if entries := book.Entries(); len(entries) != 1 || entries[0].Address != addr {
+		t.Fatalf("wrong entries: %v", entries)
+	}
+}
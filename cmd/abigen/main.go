@@ -21,13 +21,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/common/compiler"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/urfave/cli/v2"
@@ -43,6 +43,10 @@ var (
 		Name:  "bin",
 		Usage: "Path to the Ethereum contract bytecode (generate deploy method)",
 	}
+	libsFlag = &cli.StringFlag{
+		Name:  "libs",
+		Usage: "Comma separated fully qualified names of the libraries linked by the bytecode, e.g. contracts/Math.sol:Math",
+	}
 	typeFlag = &cli.StringFlag{
 		Name:  "type",
 		Usage: "Struct name for the binding (default = package name)",
@@ -63,6 +67,10 @@ var (
 		Name:  "out",
 		Usage: "Output file for the generated binding (default = stdout)",
 	}
+	outDirFlag = &cli.StringFlag{
+		Name:  "outdir",
+		Usage: "Output directory for the generated bindings, with a file per contract and a types.go file of the shared structs",
+	}
 	langFlag = &cli.StringFlag{
 		Name:  "lang",
 		Usage: "Destination language for the bindings (go)",
@@ -72,6 +80,10 @@ var (
 		Name:  "alias",
 		Usage: "Comma separated aliases for function and event renaming, e.g. original1=alias1, original2=alias2",
 	}
+	v2Flag = &cli.BoolFlag{
+		Name:  "v2",
+		Usage: "Generates v2 bindings, packing and unpacking the contract data to be used with the generic helpers of the bind package",
+	}
 )
 
 var app = flags.NewApp("Ethereum ABI wrapper code generator")
@@ -81,19 +93,23 @@ func init() {
 	app.Flags = []cli.Flag{
 		abiFlag,
 		binFlag,
+		libsFlag,
 		typeFlag,
 		jsonFlag,
 		excFlag,
 		pkgFlag,
 		outFlag,
+		outDirFlag,
 		langFlag,
 		aliasFlag,
+		v2Flag,
 	}
 	app.Action = abigen
 }
 
 func abigen(c *cli.Context) error {
-	utils.CheckExclusive(c, abiFlag, jsonFlag) // Only one source can be selected.
+	utils.CheckExclusive(c, abiFlag, jsonFlag)   // Only one source can be selected.
+	utils.CheckExclusive(c, outFlag, outDirFlag) // Only one destination can be selected.
 
 	if c.String(pkgFlag.Name) == "" {
 		utils.Fatalf("No destination package specified (--pkg)")
@@ -142,6 +158,14 @@ func abigen(c *cli.Context) error {
 		}
 		bins = append(bins, string(bin))
 
+		// Name the libraries linked by the bytecode, to be passed to the deploy method
+		if c.IsSet(libsFlag.Name) {
+			for _, name := range strings.Split(c.String(libsFlag.Name), ",") {
+				nameParts := strings.Split(strings.TrimSpace(name), ":")
+				libs[bind.LibraryPattern(strings.TrimSpace(name))] = nameParts[len(nameParts)-1]
+			}
+		}
+
 		kind := c.String(typeFlag.Name)
 		if kind == "" {
 			kind = c.String(pkgFlag.Name)
@@ -195,12 +219,8 @@ func abigen(c *cli.Context) error {
 			sigs = append(sigs, contract.Hashes)
 			types = append(types, typeName)
 
-			// Derive the library placeholder which is a 34 character prefix of the
-			// hex encoding of the keccak256 hash of the fully qualified library name.
-			// Note that the fully qualified library name is the path of its source
-			// file and the library name separated by ":".
-			libPattern := crypto.Keccak256Hash([]byte(name)).String()[2:36] // the first 2 chars are 0x
-			libs[libPattern] = typeName
+			// Derive the library placeholder from the fully qualified library name
+			libs[bind.LibraryPattern(name)] = typeName
 		}
 	}
 	// Extract all aliases from the flags
@@ -215,8 +235,41 @@ func abigen(c *cli.Context) error {
 			aliases[match[1]] = match[2]
 		}
 	}
+	// Generate the contract bindings into a file each if requested
+	if c.IsSet(outDirFlag.Name) {
+		var (
+			files map[string]string
+			err   error
+		)
+		if c.Bool(v2Flag.Name) {
+			files, err = bind.BindV2Files(types, abis, bins, c.String(pkgFlag.Name), libs, aliases)
+		} else {
+			files, err = bind.BindFiles(types, abis, bins, sigs, c.String(pkgFlag.Name), lang, libs, aliases)
+		}
+		if err != nil {
+			utils.Fatalf("Failed to generate ABI bindings: %v", err)
+		}
+		dir := c.String(outDirFlag.Name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			utils.Fatalf("Failed to create output directory: %v", err)
+		}
+		for name, code := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(code), 0600); err != nil {
+				utils.Fatalf("Failed to write ABI binding: %v", err)
+			}
+		}
+		return nil
+	}
 	// Generate the contract binding
-	code, err := bind.Bind(types, abis, bins, sigs, c.String(pkgFlag.Name), lang, libs, aliases)
+	var (
+		code string
+		err  error
+	)
+	if c.Bool(v2Flag.Name) {
+		code, err = bind.BindV2(types, abis, bins, c.String(pkgFlag.Name), libs, aliases)
+	} else {
+		code, err = bind.Bind(types, abis, bins, sigs, c.String(pkgFlag.Name), lang, libs, aliases)
+	}
 	if err != nil {
 		utils.Fatalf("Failed to generate ABI binding: %v", err)
 	}
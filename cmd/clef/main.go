@@ -18,6 +18,7 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"crypto/rand"
 	"crypto/sha256"
@@ -54,6 +55,7 @@ import (
 	"github.com/ethereum/go-ethereum/signer/fourbyte"
 	"github.com/ethereum/go-ethereum/signer/rules"
 	"github.com/ethereum/go-ethereum/signer/storage"
+	"github.com/ethereum/go-ethereum/signer/webhook"
 	"github.com/mattn/go-colorable"
 	"github.com/mattn/go-isatty"
 	"github.com/urfave/cli/v2"
@@ -126,6 +128,23 @@ var (
 		Name:  "rules",
 		Usage: "Path to the rule file to auto-authorize requests with",
 	}
+	rulesRPCFlag = &cli.StringFlag{
+		Name:  "rules.rpc",
+		Usage: "RPC endpoint of a node the rules can query chain state and txpool contents from",
+	}
+	webhookFlag = &cli.StringFlag{
+		Name:  "webhook",
+		Usage: "HTTPS endpoint of a webhook to forward approval requests to, instead of the UI",
+	}
+	webhookSecretFlag = &cli.StringFlag{
+		Name:  "webhook.secret",
+		Usage: "File containing the secret authenticating the webhook requests and responses",
+	}
+	webhookTimeoutFlag = &cli.DurationFlag{
+		Name:  "webhook.timeout",
+		Usage: "Time to wait for a webhook decision before denying a request",
+		Value: webhook.DefaultTimeout,
+	}
 	stdiouiFlag = &cli.BoolFlag{
 		Name: "stdio-ui",
 		Usage: "Use STDIN/STDOUT as a channel for an external UI. " +
@@ -281,6 +300,10 @@ func init() {
 		customDBFlag,
 		auditLogFlag,
 		ruleFlag,
+		rulesRPCFlag,
+		webhookFlag,
+		webhookSecretFlag,
+		webhookTimeoutFlag,
 		stdiouiFlag,
 		testFlag,
 		advancedMode,
@@ -640,6 +663,25 @@ func signer(c *cli.Context) error {
 		log.Info("Using CLI as UI-channel")
 		ui = core.NewCommandlineUI()
 	}
+	if endpoint := c.String(webhookFlag.Name); endpoint != "" {
+		secretFile := c.String(webhookSecretFlag.Name)
+		if secretFile == "" {
+			utils.Fatalf("Flag --%s is required by --%s", webhookSecretFlag.Name, webhookFlag.Name)
+		}
+		secret, err := os.ReadFile(secretFile)
+		if err != nil {
+			utils.Fatalf("Failed to read webhook secret: %v", err)
+		}
+		ui, err = webhook.New(ui, webhook.Config{
+			URL:     endpoint,
+			Secret:  bytes.TrimSpace(secret),
+			Timeout: c.Duration(webhookTimeoutFlag.Name),
+		})
+		if err != nil {
+			utils.Fatalf("Failed to configure webhook: %v", err)
+		}
+		log.Info("Using webhook for approvals", "url", endpoint)
+	}
 	// 4bytedb data
 	fourByteLocal := c.String(customDBFlag.Name)
 	db, err := fourbyte.NewWithFile(fourByteLocal)
@@ -687,6 +729,13 @@ func signer(c *cli.Context) error {
 						utils.Fatalf(err.Error())
 					}
 					ruleEngine.Init(string(ruleJS))
+					if endpoint := c.String(rulesRPCFlag.Name); endpoint != "" {
+						client, err := rpc.Dial(endpoint)
+						if err != nil {
+							utils.Fatalf("Could not connect to the rules RPC endpoint: %v", err)
+						}
+						ruleEngine.SetChainBackend(client)
+					}
 					ui = ruleEngine
 					log.Info("Rule engine configured", "file", c.String(ruleFlag.Name))
 				}
@@ -47,6 +47,7 @@ var (
 			discv4ResolveCommand,
 			discv4ResolveJSONCommand,
 			discv4CrawlCommand,
+			discv4TopologyCommand,
 			discv4TestCommand,
 			discv4ListenCommand,
 		},
@@ -93,6 +94,18 @@ var (
 		Action: discv4Crawl,
 		Flags:  flags.Merge(discoveryNodeFlags, []cli.Flag{crawlTimeoutFlag, crawlParallelismFlag}),
 	}
+	discv4TopologyCommand = &cli.Command{
+		Name:      "topology",
+		Usage:     "Crawls the DHT and writes a topology snapshot of the nodes found",
+		Action:    discv4Topology,
+		ArgsUsage: "<output file>",
+		Flags: flags.Merge(discoveryNodeFlags, []cli.Flag{
+			crawlTimeoutFlag,
+			crawlParallelismFlag,
+			topologyProbeFlag,
+			topologyMaxNodesFlag,
+		}),
+	}
 	discv4TestCommand = &cli.Command{
 		Name:   "test",
 		Usage:  "Runs tests against a node",
@@ -269,6 +282,13 @@ func discv4Crawl(ctx *cli.Context) error {
 	return nil
 }
 
+func discv4Topology(ctx *cli.Context) error {
+	disc, _ := startV4(ctx)
+	defer disc.Close()
+
+	return writeTopology(ctx, disc.RandomNodes())
+}
+
 // discv4Test runs the protocol test suite.
 func discv4Test(ctx *cli.Context) error {
 	// Configure test package globals.
@@ -36,6 +36,7 @@ var (
 			discv5PingCommand,
 			discv5ResolveCommand,
 			discv5CrawlCommand,
+			discv5TopologyCommand,
 			discv5TestCommand,
 			discv5ListenCommand,
 		},
@@ -60,6 +61,18 @@ var (
 			crawlTimeoutFlag,
 		}),
 	}
+	discv5TopologyCommand = &cli.Command{
+		Name:      "topology",
+		Usage:     "Crawls the DHT and writes a topology snapshot of the nodes found",
+		Action:    discv5Topology,
+		ArgsUsage: "<output file>",
+		Flags: flags.Merge(discoveryNodeFlags, []cli.Flag{
+			crawlTimeoutFlag,
+			crawlParallelismFlag,
+			topologyProbeFlag,
+			topologyMaxNodesFlag,
+		}),
+	}
 	discv5TestCommand = &cli.Command{
 		Name:   "test",
 		Usage:  "Runs protocol tests against a node",
@@ -120,6 +133,13 @@ func discv5Crawl(ctx *cli.Context) error {
 	return nil
 }
 
+func discv5Topology(ctx *cli.Context) error {
+	disc, _ := startV5(ctx)
+	defer disc.Close()
+
+	return writeTopology(ctx, disc.RandomNodes())
+}
+
 // discv5Test runs the protocol test suite.
 func discv5Test(ctx *cli.Context) error {
 	suite := &v5test.Suite{
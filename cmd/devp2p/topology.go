@@ -0,0 +1,67 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/crawl"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	topologyProbeFlag = &cli.BoolFlag{
+		Name:  "probe",
+		Usage: "Probe the nodes for their client name, capabilities and eth status",
+	}
+	topologyMaxNodesFlag = &cli.IntFlag{
+		Name:  "max-nodes",
+		Usage: "Stop after finding this many nodes (0 = no limit)",
+	}
+)
+
+// writeTopology crawls the network through the given iterator and writes the
+// topology snapshot to the file given as argument.
+func writeTopology(ctx *cli.Context, it enode.Iterator) error {
+	if ctx.NArg() < 1 {
+		return errors.New("need output file as argument")
+	}
+	config := crawl.Config{
+		Timeout:     ctx.Duration(crawlTimeoutFlag.Name),
+		MaxNodes:    ctx.Int(topologyMaxNodesFlag.Name),
+		Probe:       ctx.Bool(topologyProbeFlag.Name),
+		Parallelism: ctx.Int(crawlParallelismFlag.Name),
+	}
+	log.Info("Crawling network", "timeout", config.Timeout, "probe", config.Probe)
+	snap := crawl.Run(context.Background(), it, config)
+	log.Info("Crawl done", "nodes", len(snap.Nodes), "elapsed", snap.Duration)
+
+	out, err := json.MarshalIndent(snap, "", jsonIndent)
+	if err != nil {
+		return err
+	}
+	if file := ctx.Args().First(); file != "-" {
+		return os.WriteFile(file, out, 0644)
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
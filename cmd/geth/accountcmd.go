@@ -19,6 +19,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
@@ -29,6 +30,21 @@ import (
 )
 
 var (
+	mnemonicPathFlag = &cli.StringFlag{
+		Name:  "mnemonic.path",
+		Usage: "Derivation path template of the imported accounts, with x marking the account index",
+		Value: "m/44'/60'/0'/0/x",
+	}
+	mnemonicCountFlag = &cli.IntFlag{
+		Name:  "mnemonic.count",
+		Usage: "Number of accounts imported from the mnemonic",
+		Value: 1,
+	}
+	mnemonicPassphraseFileFlag = &cli.StringFlag{
+		Name:  "mnemonic.passphrasefile",
+		Usage: "File containing the optional BIP-39 passphrase of the mnemonic",
+	}
+
 	walletCommand = &cli.Command{
 		Name:      "wallet",
 		Usage:     "Manage Ethereum presale wallets",
@@ -51,6 +67,8 @@ passwordfile as argument containing the wallet password in plaintext.`,
 					utils.KeyStoreDirFlag,
 					utils.PasswordFileFlag,
 					utils.LightKDFFlag,
+					utils.KeyStoreKDFFlag,
+					utils.KeyStoreKDFTargetFlag,
 				},
 				Description: `
 	geth wallet [options] /path/to/my/presale.wallet
@@ -93,6 +111,7 @@ Make sure you backup your keys regularly.`,
 				Flags: []cli.Flag{
 					utils.DataDirFlag,
 					utils.KeyStoreDirFlag,
+					utils.KeyStoreExtraFlag,
 				},
 				Description: `
 Print a short summary of all accounts`,
@@ -106,6 +125,8 @@ Print a short summary of all accounts`,
 					utils.KeyStoreDirFlag,
 					utils.PasswordFileFlag,
 					utils.LightKDFFlag,
+					utils.KeyStoreKDFFlag,
+					utils.KeyStoreKDFTargetFlag,
 				},
 				Description: `
     geth account new
@@ -130,7 +151,10 @@ password to file or expose in any other way.
 				Flags: []cli.Flag{
 					utils.DataDirFlag,
 					utils.KeyStoreDirFlag,
+					utils.KeyStoreExtraFlag,
 					utils.LightKDFFlag,
+					utils.KeyStoreKDFFlag,
+					utils.KeyStoreKDFTargetFlag,
 				},
 				Description: `
     geth account update <address>
@@ -160,6 +184,8 @@ changing your password is only possible interactively.
 					utils.KeyStoreDirFlag,
 					utils.PasswordFileFlag,
 					utils.LightKDFFlag,
+					utils.KeyStoreKDFFlag,
+					utils.KeyStoreKDFTargetFlag,
 				},
 				ArgsUsage: "<keyFile>",
 				Description: `
@@ -182,6 +208,60 @@ Note:
 As you can directly copy your encrypted accounts to another ethereum instance,
 this import mechanism is not needed when you transfer an account between
 nodes.
+`,
+			},
+			{
+				Name:   "import-mnemonic",
+				Usage:  "Import the accounts of a BIP-39 mnemonic",
+				Action: accountImportMnemonic,
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.KeyStoreDirFlag,
+					utils.PasswordFileFlag,
+					utils.LightKDFFlag,
+					utils.KeyStoreKDFFlag,
+					utils.KeyStoreKDFTargetFlag,
+					mnemonicPathFlag,
+					mnemonicCountFlag,
+					mnemonicPassphraseFileFlag,
+				},
+				ArgsUsage: "<mnemonicFile>",
+				Description: `
+    geth account import-mnemonic [options] <mnemonicfile>
+
+Derives the accounts of the BIP-39 mnemonic in <mnemonicfile> and stores them
+as new accounts. Prints the addresses and their derivation paths.
+
+The accounts are derived along the --mnemonic.path template, in which x marks
+the account index, e.g. m/44'/60'/x'/0/0 for the Ledger Live scheme. The first
+--mnemonic.count accounts are imported. To import the accounts which have been
+used on chain, call personal_importMnemonic on a running node instead.
+
+The accounts are saved in encrypted format, you are prompted for a password.
+For non-interactive use the password can be specified with the --password flag.
+`,
+			},
+			{
+				Name:   "seal-passwords",
+				Usage:  "Create a password bundle sealed to the node key",
+				Action: accountSealPasswords,
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.NodeKeyFileFlag,
+					utils.PasswordFileFlag,
+				},
+				ArgsUsage: "<bundleFile>",
+				Description: `
+    geth account seal-passwords [options] <bundlefile>
+
+Encrypts the passwords unlocking accounts to the node key, writing them to
+<bundlefile>. The bundle replaces the plaintext password file of --password
+when starting the node with --password.bundle and --unlock, only the node
+holding the node key can open it.
+
+You are prompted for the passwords, in the order of the accounts of --unlock.
+The passwords of an existing password file can be sealed with the --password
+flag, the plaintext file should be deleted afterwards.
 `,
 			},
 		},
@@ -285,16 +365,11 @@ func accountCreate(ctx *cli.Context) error {
 	if isEphemeral {
 		utils.Fatalf("Can't use ephemeral directory as keystore path")
 	}
-	scryptN := keystore.StandardScryptN
-	scryptP := keystore.StandardScryptP
-	if cfg.Node.UseLightweightKDF {
-		scryptN = keystore.LightScryptN
-		scryptP = keystore.LightScryptP
-	}
+	kdf := utils.MakeKeyStoreKDF(&cfg.Node)
 
 	password := utils.GetPassPhraseWithList("Your new account is locked with a password. Please give a password. Do not forget this password.", true, 0, utils.MakePasswordList(ctx))
 
-	account, err := keystore.StoreKey(keydir, password, scryptN, scryptP)
+	account, err := keystore.StoreKeyWithKDF(keydir, password, kdf)
 
 	if err != nil {
 		utils.Fatalf("Failed to create account: %v", err)
@@ -382,3 +457,86 @@ func accountImport(ctx *cli.Context) error {
 	fmt.Printf("Address: {%x}\n", acct.Address)
 	return nil
 }
+
+// accountSealPasswords writes a password bundle sealed to the node key.
+func accountSealPasswords(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("bundle file must be given as the only argument")
+	}
+	cfg := loadBaseConfig(ctx)
+
+	fromFile := utils.MakePasswordList(ctx)
+	defer utils.WipePasswords(fromFile)
+
+	passwords := fromFile
+	if len(passwords) == 0 {
+		fmt.Println("Please give the passwords in the order of the unlocked accounts, an empty password ends the list.")
+		for i := 0; ; i++ {
+			password := utils.GetPassPhrase(fmt.Sprintf("Password #%d:", i), false)
+			if password == "" {
+				break
+			}
+			passwords = append(passwords, password)
+		}
+	}
+	if len(passwords) == 0 {
+		utils.Fatalf("No passwords to seal")
+	}
+	plaintext := []byte(strings.Join(passwords, "\n"))
+	defer clear(plaintext)
+
+	key := cfg.Node.NodeKey()
+	bundle, err := utils.SealPasswords(&key.PublicKey, plaintext)
+	if err != nil {
+		utils.Fatalf("Failed to seal passwords: %v", err)
+	}
+	if err := os.WriteFile(ctx.Args().First(), bundle, 0600); err != nil {
+		utils.Fatalf("Failed to write password bundle: %v", err)
+	}
+	fmt.Printf("Passwords sealed to node key %v\n", crypto.PubkeyToAddress(key.PublicKey))
+	return nil
+}
+
+// accountImportMnemonic imports the accounts derived from a BIP-39 mnemonic.
+func accountImportMnemonic(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		utils.Fatalf("mnemonic file must be given as the only argument")
+	}
+	mnemonic, err := os.ReadFile(ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Failed to read the mnemonic: %v", err)
+	}
+	var seedPassphrase string
+	if file := ctx.String(mnemonicPassphraseFileFlag.Name); file != "" {
+		text, err := os.ReadFile(file)
+		if err != nil {
+			utils.Fatalf("Failed to read the mnemonic passphrase: %v", err)
+		}
+		seedPassphrase = strings.TrimRight(string(text), "\r\n")
+	}
+	template, err := accounts.ParseDerivationTemplate(ctx.String(mnemonicPathFlag.Name))
+	if err != nil {
+		utils.Fatalf("Invalid derivation path template: %v", err)
+	}
+	count := ctx.Int(mnemonicCountFlag.Name)
+	if count <= 0 {
+		utils.Fatalf("Account count must be positive")
+	}
+	am := makeAccountManager(ctx)
+	backends := am.Backends(keystore.KeyStoreType)
+	if len(backends) == 0 {
+		utils.Fatalf("Keystore is not available")
+	}
+	ks := backends[0].(*keystore.KeyStore)
+	passphrase := utils.GetPassPhraseWithList("Your new accounts are locked with a password. Please give a password. Do not forget this password.", true, 0, utils.MakePasswordList(ctx))
+
+	paths := keystore.MnemonicPaths(template, count)
+	accts, err := ks.ImportMnemonic(string(mnemonic), seedPassphrase, paths, passphrase)
+	if err != nil {
+		utils.Fatalf("Could not import the accounts: %v", err)
+	}
+	for i, acct := range accts {
+		fmt.Printf("Account #%d: {%x} %s\n", i, acct.Address, paths[i])
+	}
+	return nil
+}
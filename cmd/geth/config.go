@@ -18,6 +18,7 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -29,8 +30,13 @@ import (
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/external"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/accounts/kms"
+	"github.com/ethereum/go-ethereum/accounts/mpc"
+	"github.com/ethereum/go-ethereum/accounts/pkcs11"
 	"github.com/ethereum/go-ethereum/accounts/scwallet"
 	"github.com/ethereum/go-ethereum/accounts/usbwallet"
+	"github.com/ethereum/go-ethereum/accounts/vault"
+	"github.com/ethereum/go-ethereum/accounts/watch"
 	"github.com/ethereum/go-ethereum/beacon/blsync"
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/common"
@@ -157,6 +163,9 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 	if err := setAccountManagerBackends(stack.Config(), stack.AccountManager(), stack.KeyStoreDir()); err != nil {
 		utils.Fatalf("Failed to set account manager backends: %v", err)
 	}
+	if err := setAddressBook(stack); err != nil {
+		utils.Fatalf("Failed to load the address book: %v", err)
+	}
 
 	utils.SetEthConfig(ctx, stack, &cfg.Eth)
 	if ctx.IsSet(utils.EthStatsURLFlag.Name) {
@@ -202,6 +211,19 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 	if ctx.IsSet(utils.GraphQLEnabledFlag.Name) {
 		utils.RegisterGraphQLService(stack, backend, filterSystem, &cfg.Node)
 	}
+	// Configure the health endpoints if requested.
+	if ctx.IsSet(utils.HealthEnabledFlag.Name) {
+		utils.RegisterHealthService(ctx, stack, backend)
+	}
+	// Configure the gRPC server if requested.
+	if ctx.IsSet(utils.GRPCEnabledFlag.Name) {
+		utils.RegisterGRPCService(ctx, stack)
+	}
+	// Restrict peers to the permissioned ones if requested.
+	utils.RegisterNodePermissions(ctx, stack)
+	// Link up with the sentries or validators of a sentry setup.
+	utils.RegisterSentryRelay(stack)
+
 	// Add the Ethereum Stats daemon if requested.
 	if cfg.Ethstats.URL != "" {
 		utils.RegisterEthStatsService(stack, backend, cfg.Ethstats.URL)
@@ -329,14 +351,22 @@ func deprecated(field string) bool {
 	}
 }
 
-func setAccountManagerBackends(conf *node.Config, am *accounts.Manager, keydir string) error {
-	scryptN := keystore.StandardScryptN
-	scryptP := keystore.StandardScryptP
-	if conf.UseLightweightKDF {
-		scryptN = keystore.LightScryptN
-		scryptP = keystore.LightScryptP
+// setAddressBook loads the watch-only accounts of the node's address book into
+// the account manager, and exposes the address book over RPC, if enabled.
+func setAddressBook(stack *node.Node) error {
+	if !stack.Config().AddressBook {
+		return nil
 	}
+	book, err := watch.NewAddressBook(stack.ResolvePath("addressbook.json"))
+	if err != nil {
+		return err
+	}
+	stack.AccountManager().AddBackend(book)
+	stack.RegisterAPIs(watch.APIs(book))
+	return nil
+}
 
+func setAccountManagerBackends(conf *node.Config, am *accounts.Manager, keydir string) error {
 	// Assemble the supported backends
 	if len(conf.ExternalSigner) > 0 {
 		log.Info("Using external signer", "url", conf.ExternalSigner)
@@ -352,7 +382,63 @@ func setAccountManagerBackends(conf *node.Config, am *accounts.Manager, keydir s
 	// If/when we implement some form of lockfile for USB and keystore wallets,
 	// we can have both, but it's very confusing for the user to see the same
 	// accounts in both externally and locally, plus very racey.
-	am.AddBackend(keystore.NewKeyStore(keydir, scryptN, scryptP))
+	am.AddBackend(keystore.NewKeyStoreWithDirs(keydir, utils.MakeKeyStoreKDF(conf), utils.MakeKeyStoreDirs(conf)))
+	if len(conf.KMSKeys) > 0 {
+		// Load the KMS keys, their private keys stay with the service
+		kmsBackend, err := kms.NewBackend(context.Background(), conf.KMSKeys)
+		if err != nil {
+			return fmt.Errorf("error loading KMS keys: %v", err)
+		}
+		am.AddBackend(kmsBackend)
+	}
+	if len(conf.MPCEndpoint) > 0 {
+		// Connect to the MPC signing service, keys are only held in shares
+		provider, err := mpc.DialRPC(context.Background(), conf.MPCEndpoint)
+		if err != nil {
+			return fmt.Errorf("error connecting to MPC signing service: %v", err)
+		}
+		url := accounts.URL{Scheme: mpc.Scheme, Path: conf.MPCEndpoint}
+		mpcBackend, err := mpc.NewBackend(context.Background(), url, provider, mpc.Config{Timeout: conf.MPCTimeout})
+		if err != nil {
+			return fmt.Errorf("error loading MPC accounts: %v", err)
+		}
+		am.AddBackend(mpcBackend)
+	}
+	if len(conf.VaultAddress) > 0 {
+		// Connect to Vault, keys are only fetched for signing
+		vaultBackend, err := vault.NewBackend(context.Background(), vault.Config{
+			Address: conf.VaultAddress,
+			Auth: vault.Auth{
+				Method:     conf.VaultAuthMethod,
+				Mount:      conf.VaultAuthMount,
+				Role:       conf.VaultAuthRole,
+				SecretFile: conf.VaultSecretFile,
+			},
+			Mount:   conf.VaultMount,
+			Transit: conf.VaultTransit,
+			Keys:    conf.VaultKeys,
+		})
+		if err != nil {
+			return fmt.Errorf("error loading Vault keys: %v", err)
+		}
+		am.AddBackend(vaultBackend)
+	}
+	if len(conf.PKCS11Module) > 0 {
+		// Load the HSM keys, signing happens on the device
+		var pin string
+		if conf.PKCS11PINFile != "" {
+			blob, err := os.ReadFile(conf.PKCS11PINFile)
+			if err != nil {
+				return fmt.Errorf("error reading PKCS#11 PIN file: %v", err)
+			}
+			pin = strings.TrimRight(string(blob), "\r\n")
+		}
+		hub, err := pkcs11.NewHub(conf.PKCS11Module, pin)
+		if err != nil {
+			return fmt.Errorf("error loading PKCS#11 module: %v", err)
+		}
+		am.AddBackend(hub)
+	}
 	if conf.USB {
 		// Start a USB hub for Ledger hardware wallets
 		if ledgerhub, err := usbwallet.NewLedgerHub(); err != nil {
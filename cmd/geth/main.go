@@ -25,6 +25,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/cmd/utils"
@@ -57,12 +58,29 @@ var (
 		utils.IdentityFlag,
 		utils.UnlockedAccountFlag,
 		utils.PasswordFileFlag,
+		utils.PasswordBundleFlag,
 		utils.BootnodesFlag,
+		utils.BootnodesDNSFlag,
 		utils.MinFreeDiskSpaceFlag,
 		utils.KeyStoreDirFlag,
 		utils.ExternalSignerFlag,
+		utils.KMSKeysFlag,
+		utils.MPCEndpointFlag,
+		utils.MPCTimeoutFlag,
+		utils.PKCS11ModuleFlag,
+		utils.PKCS11PINFileFlag,
+		utils.VaultAddressFlag,
+		utils.VaultKeysFlag,
+		utils.VaultMountFlag,
+		utils.VaultTransitFlag,
+		utils.VaultAuthMethodFlag,
+		utils.VaultAuthMountFlag,
+		utils.VaultAuthRoleFlag,
+		utils.VaultSecretFileFlag,
+		utils.AddressBookFlag,
 		utils.NoUSBFlag, // deprecated
 		utils.USBFlag,
+		utils.USBDerivationPathsFlag,
 		utils.SmartCardDaemonPathFlag,
 		utils.OverrideCancun,
 		utils.OverrideVerkle,
@@ -78,11 +96,18 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPropagationFanoutFlag,
+		utils.TxPropagationMaxSizeFlag,
+		utils.TxPropagationSendQueueFlag,
+		utils.TxPropagationAnnounceQueueFlag,
+		utils.TxPropagationValidatorsFlag,
+		utils.TxPropagationSyncLimitFlag,
 		utils.BlobPoolDataDirFlag,
 		utils.BlobPoolDataCapFlag,
 		utils.BlobPoolPriceBumpFlag,
 		utils.SyncModeFlag,
 		utils.SyncTargetFlag,
+		utils.SyncCheckpointFlag,
 		utils.ExitWhenSyncedFlag,
 		utils.GCModeFlag,
 		utils.SnapshotFlag,
@@ -95,6 +120,9 @@ var (
 		utils.LightMaxPeersFlag, // deprecated
 		utils.LightNoPruneFlag,  // deprecated
 		utils.LightKDFFlag,
+		utils.KeyStoreExtraFlag,
+		utils.KeyStoreKDFFlag,
+		utils.KeyStoreKDFTargetFlag,
 		utils.LightNoSyncServeFlag, // deprecated
 		utils.EthRequiredBlocksFlag,
 		utils.LegacyWhitelistFlag, // deprecated
@@ -115,6 +143,22 @@ var (
 		utils.DiscoveryPortFlag,
 		utils.MaxPeersFlag,
 		utils.MaxPendingPeersFlag,
+		utils.EthMaxMessageSizeFlag,
+		utils.SnapMaxMessageSizeFlag,
+		utils.MessageRateLimitsFlag,
+		utils.DialRedialIntervalFlag,
+		utils.DialMaxBackoffFlag,
+		utils.DialMaxResolveDelayFlag,
+		utils.DialPriorityFlag,
+		utils.DialKeepStaticFlag,
+		utils.BandwidthPeerInFlag,
+		utils.BandwidthPeerOutFlag,
+		utils.BandwidthInFlag,
+		utils.BandwidthOutFlag,
+		utils.P2PWebSocketAddrFlag,
+		utils.P2PWebSocketCertFlag,
+		utils.P2PWebSocketKeyFlag,
+		utils.P2PWebSocketPreferFlag,
 		utils.MiningEnabledFlag, // deprecated
 		utils.MinerGasLimitFlag,
 		utils.MinerGasPriceFlag,
@@ -122,13 +166,29 @@ var (
 		utils.MinerExtraDataFlag,
 		utils.MinerRecommitIntervalFlag,
 		utils.MinerPendingFeeRecipientFlag,
+		utils.BundlerFlag,
+		utils.BundlerEntryPointsFlag,
+		utils.BundlerAccountFlag,
+		utils.BundlerPoolSizeFlag,
 		utils.MinerNewPayloadTimeoutFlag, // deprecated
 		utils.NATFlag,
+		utils.AdvertiseIPFlag,
+		utils.AdvertiseTCPFlag,
+		utils.AdvertiseUDPFlag,
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV4Flag,
 		utils.DiscoveryV5Flag,
 		utils.LegacyDiscoveryV5Flag, // deprecated
+		utils.DiscoveryTopicsFlag,
 		utils.NetrestrictFlag,
+		utils.PermissionFileFlag,
+		utils.PermissionSignerFlag,
+		utils.PermissionContractFlag,
+		utils.SentryNodesFlag,
+		utils.SentryValidatorsFlag,
+		utils.PeerClassFlag,
+		utils.StaticNodesFileFlag,
+		utils.TrustedNodesFileFlag,
 		utils.NodeKeyFileFlag,
 		utils.NodeKeyHexFlag,
 		utils.DNSDiscoveryFlag,
@@ -144,6 +204,7 @@ var (
 		utils.GpoBlocksFlag,
 		utils.GpoPercentileFlag,
 		utils.GpoMaxGasPriceFlag,
+		utils.GpoMinGasPriceFlag,
 		utils.GpoIgnoreGasPriceFlag,
 		configFileFlag,
 		utils.LogDebugFlag,
@@ -172,23 +233,68 @@ var (
 		utils.GraphQLEnabledFlag,
 		utils.GraphQLCORSDomainFlag,
 		utils.GraphQLVirtualHostsFlag,
+		utils.HealthEnabledFlag,
+		utils.HealthMinPeersFlag,
+		utils.HealthMaxBlockAgeFlag,
+		utils.GRPCEnabledFlag,
+		utils.GRPCListenAddrFlag,
+		utils.GRPCPortFlag,
+		utils.GRPCJWTSecretFlag,
+		utils.GRPCTLSCertFlag,
+		utils.GRPCTLSKeyFlag,
 		utils.HTTPApiFlag,
 		utils.HTTPPathPrefixFlag,
+		utils.HTTPCompressionThresholdFlag,
+		utils.HTTPJWTSecretFlag,
 		utils.WSEnabledFlag,
 		utils.WSListenAddrFlag,
 		utils.WSPortFlag,
 		utils.WSApiFlag,
 		utils.WSAllowedOriginsFlag,
 		utils.WSPathPrefixFlag,
+		utils.WSJWTSecretFlag,
+		utils.WSCompressionFlag,
+		utils.WSCompressionLevelFlag,
+		utils.WSCompressionThresholdFlag,
+		utils.WSSubscriptionPolicyFlag,
+		utils.WSSubscriptionBufferFlag,
+		utils.WSPingIntervalFlag,
+		utils.WSPongTimeoutFlag,
+		utils.WSWriteTimeoutFlag,
+		utils.WSIdleTimeoutFlag,
+		utils.WSMaxConnectionsFlag,
+		utils.WSMaxSubscriptionsFlag,
+		utils.WSMessageRateFlag,
+		utils.WSMessageBurstFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
 		utils.InsecureUnlockAllowedFlag,
+		utils.RequireEIP155Flag,
+		utils.SigningAuditLogFlag,
+		utils.SigningAuditHashChainFlag,
 		utils.RPCGlobalGasCapFlag,
 		utils.RPCGlobalEVMTimeoutFlag,
 		utils.RPCGlobalTxFeeCapFlag,
 		utils.AllowUnprotectedTxs,
 		utils.BatchRequestLimit,
+		utils.RPCRateLimitFlag,
+		utils.RPCRateLimitBurstFlag,
+		utils.RPCRateLimitConcurrentFlag,
+		utils.RPCRateLimitGetLogsFlag,
+		utils.RPCCacheFlag,
+		utils.RPCTrustedProxiesFlag,
+		utils.RPCProxyProtocolFlag,
+		utils.RPCAuditLogFlag,
+		utils.RPCAuditNamespacesFlag,
+		utils.RPCCacheDepthFlag,
+		utils.RPCLogsRangeLimitFlag,
+		utils.RPCLogsResultLimitFlag,
+		utils.RPCLogsSplitBudgetFlag,
+		utils.RPCPersistFiltersFlag,
+		utils.RPCProofReexecFlag,
 		utils.BatchResponseMaxSize,
+		utils.BatchTimeout,
+		utils.RPCMethodTimeoutsFlag,
 	}
 
 	metricsFlags = []cli.Flag{
@@ -356,6 +462,12 @@ func geth(ctx *cli.Context) error {
 	return nil
 }
 
+// templateDeriver is implemented by the wallets able to discover accounts along
+// derivation path templates.
+type templateDeriver interface {
+	SelfDeriveTemplates(templates []accounts.DerivationTemplate, chain ethereum.ChainStateReader)
+}
+
 // startNode boots up the system node and all registered protocols, after which
 // it unlocks any requested accounts, and starts the RPC/IPC interfaces and the
 // miner.
@@ -372,6 +484,15 @@ func startNode(ctx *cli.Context, stack *node.Node, isConsole bool) {
 	events := make(chan accounts.WalletEvent, 16)
 	stack.AccountManager().Subscribe(events)
 
+	var templates []accounts.DerivationTemplate
+	for _, path := range stack.Config().USBDerivationPaths {
+		template, err := accounts.ParseDerivationTemplate(path)
+		if err != nil {
+			utils.Fatalf("Invalid derivation path template %q: %v", path, err)
+		}
+		templates = append(templates, template)
+	}
+
 	// Create a client to interact with local geth node.
 	rpcClient := stack.Attach()
 	ethClient := ethclient.NewClient(rpcClient)
@@ -394,6 +515,12 @@ func startNode(ctx *cli.Context, stack *node.Node, isConsole bool) {
 				status, _ := event.Wallet.Status()
 				log.Info("New wallet appeared", "url", event.Wallet.URL(), "status", status)
 
+				// Hardware wallets can be configured to discover accounts along custom
+				// derivation schemes, otherwise fall back to the default paths.
+				if wallet, ok := event.Wallet.(templateDeriver); ok && len(templates) > 0 {
+					wallet.SelfDeriveTemplates(templates, ethClient)
+					break
+				}
 				var derivationPaths []accounts.DerivationPath
 				if event.Wallet.URL().Scheme == "ledger" {
 					derivationPaths = append(derivationPaths, accounts.LegacyLedgerBaseDerivationPath)
@@ -458,7 +585,8 @@ func unlockAccounts(ctx *cli.Context, stack *node.Node) {
 		return
 	}
 	ks := backends[0].(*keystore.KeyStore)
-	passwords := utils.MakePasswordList(ctx)
+	passwords := utils.MakeUnlockPasswords(ctx, stack.Config())
+	defer utils.WipePasswords(passwords)
 	for i, account := range unlocks {
 		unlockAccount(ks, account, i, passwords)
 	}
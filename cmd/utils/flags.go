@@ -37,6 +37,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/accounts/mpc"
+	"github.com/ethereum/go-ethereum/accounts/vault"
 	bparams "github.com/ethereum/go-ethereum/beacon/params"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/fdlimit"
@@ -47,16 +49,20 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/bundler"
 	"github.com/ethereum/go-ethereum/eth/catalyst"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/eth/filters"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethdb/remotedb"
+	"github.com/ethereum/go-ethereum/ethgrpc"
 	"github.com/ethereum/go-ethereum/ethstats"
 	"github.com/ethereum/go-ethereum/graphql"
+	"github.com/ethereum/go-ethereum/health"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/log"
@@ -69,6 +75,8 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/nat"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
+	"github.com/ethereum/go-ethereum/p2p/permission"
+	"github.com/ethereum/go-ethereum/p2p/sentry"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/triedb"
@@ -125,6 +133,11 @@ var (
 		Usage:    "Enable monitoring and management of USB hardware wallets",
 		Category: flags.AccountCategory,
 	}
+	USBDerivationPathsFlag = &cli.StringFlag{
+		Name:     "usb.derivationpaths",
+		Usage:    "Comma separated derivation path templates to discover hardware wallet accounts with, the account index marked by 'x' (e.g. m/44'/60'/x'/0/0)",
+		Category: flags.AccountCategory,
+	}
 	SmartCardDaemonPathFlag = &cli.StringFlag{
 		Name:     "pcscdpath",
 		Usage:    "Path to the smartcard daemon (pcscd) socket file",
@@ -233,6 +246,21 @@ var (
 		Usage:    "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
 		Category: flags.AccountCategory,
 	}
+	KeyStoreExtraFlag = &cli.StringFlag{
+		Name:     "keystore.extra",
+		Usage:    "Comma separated additional key directories, as path[:ro][:nounlock][:maxunlock=<duration>]",
+		Category: flags.AccountCategory,
+	}
+	KeyStoreKDFFlag = &cli.StringFlag{
+		Name:     "keystore.kdf",
+		Usage:    "Key derivation function encrypting new keys, e.g. \"scrypt:n=262144,p=1\" or \"argon2id:t=3,m=65536,p=4\"",
+		Category: flags.AccountCategory,
+	}
+	KeyStoreKDFTargetFlag = &cli.DurationFlag{
+		Name:     "keystore.kdf.target",
+		Usage:    "Benchmark the key derivation function to pick parameters unlocking keys in the given time",
+		Category: flags.AccountCategory,
+	}
 	EthRequiredBlocksFlag = &cli.StringFlag{
 		Name:     "eth.requiredblocks",
 		Usage:    "Comma separated block number-to-hash mappings to require for peering (<number>=<hash>)",
@@ -260,6 +288,11 @@ var (
 		Value:    &defaultSyncMode,
 		Category: flags.StateCategory,
 	}
+	SyncCheckpointFlag = &cli.StringFlag{
+		Name:     "sync.checkpoint",
+		Usage:    "Trusted checkpoint to snap sync from, as <number>:<hash> (for chains without a consensus client)",
+		Category: flags.StateCategory,
+	}
 	GCModeFlag = &cli.StringFlag{
 		Name:     "gcmode",
 		Usage:    `Blockchain garbage collection mode, only relevant in state.scheme=hash ("full", "archive")`,
@@ -400,6 +433,37 @@ var (
 		Value:    ethconfig.Defaults.TxPool.Lifetime,
 		Category: flags.TxPoolCategory,
 	}
+	// Transaction propagation settings
+	TxPropagationFanoutFlag = &cli.IntFlag{
+		Name:     "txpropagation.fanout",
+		Usage:    "Number of peers sent full transactions, others get announcements (0 = square root of the peer count)",
+		Category: flags.TxPoolCategory,
+	}
+	TxPropagationMaxSizeFlag = &cli.Uint64Flag{
+		Name:     "txpropagation.maxsize",
+		Usage:    "Maximum size of transactions sent in full, larger ones are only announced (0 = default)",
+		Category: flags.TxPoolCategory,
+	}
+	TxPropagationSendQueueFlag = &cli.IntFlag{
+		Name:     "txpropagation.sendqueue",
+		Usage:    "Maximum number of transactions queued for sending per peer (0 = default)",
+		Category: flags.TxPoolCategory,
+	}
+	TxPropagationAnnounceQueueFlag = &cli.IntFlag{
+		Name:     "txpropagation.announcequeue",
+		Usage:    "Maximum number of transaction announcements queued per peer (0 = default)",
+		Category: flags.TxPoolCategory,
+	}
+	TxPropagationValidatorsFlag = &cli.StringFlag{
+		Name:     "txpropagation.validators",
+		Usage:    "Comma separated enode URLs or IDs of validator peers receiving transactions first",
+		Category: flags.TxPoolCategory,
+	}
+	TxPropagationSyncLimitFlag = &cli.IntFlag{
+		Name:     "txpropagation.synclimit",
+		Usage:    "Maximum number of pending transactions announced to newly connected peers (0 = default)",
+		Category: flags.TxPoolCategory,
+	}
 	// Blob transaction pool settings
 	BlobPoolDataDirFlag = &cli.StringFlag{
 		Name:     "blobpool.datadir",
@@ -507,6 +571,28 @@ var (
 		Usage:    "0x prefixed public address for the pending block producer (not used for actual block production)",
 		Category: flags.MinerCategory,
 	}
+	BundlerFlag = &cli.BoolFlag{
+		Name:     "bundler",
+		Usage:    "Enable the ERC-4337 bundler, including user operations in the built blocks",
+		Category: flags.MinerCategory,
+	}
+	BundlerEntryPointsFlag = &cli.StringFlag{
+		Name:     "bundler.entrypoints",
+		Usage:    "Comma separated EntryPoint contracts of the accepted user operations",
+		Value:    bundler.EntryPointV06.Hex(),
+		Category: flags.MinerCategory,
+	}
+	BundlerAccountFlag = &cli.StringFlag{
+		Name:     "bundler.account",
+		Usage:    "Unlocked account signing the bundles and receiving the user operation fees",
+		Category: flags.MinerCategory,
+	}
+	BundlerPoolSizeFlag = &cli.IntFlag{
+		Name:     "bundler.poolsize",
+		Usage:    "Maximum number of pending user operations",
+		Value:    bundler.DefaultConfig.PoolSize,
+		Category: flags.MinerCategory,
+	}
 
 	// Account settings
 	UnlockedAccountFlag = &cli.StringFlag{
@@ -521,17 +607,111 @@ var (
 		TakesFile: true,
 		Category:  flags.AccountCategory,
 	}
+	PasswordBundleFlag = &cli.PathFlag{
+		Name:      "password.bundle",
+		Usage:     "Password bundle sealed to the node key, unlocking accounts without a plaintext password file",
+		TakesFile: true,
+		Category:  flags.AccountCategory,
+	}
 	ExternalSignerFlag = &cli.StringFlag{
 		Name:     "signer",
 		Usage:    "External signer (url or path to ipc file)",
 		Value:    "",
 		Category: flags.AccountCategory,
 	}
+	KMSKeysFlag = &cli.StringFlag{
+		Name:     "kms.keys",
+		Usage:    "Comma separated URLs of signing keys held by AWS KMS (awskms://<key>) or Google Cloud KMS (gcpkms://<key version name>)",
+		Category: flags.AccountCategory,
+	}
+	MPCEndpointFlag = &cli.StringFlag{
+		Name:     "mpc.endpoint",
+		Usage:    "JSON-RPC endpoint of an MPC/threshold signing service (mpc_ namespace)",
+		Category: flags.AccountCategory,
+	}
+	MPCTimeoutFlag = &cli.DurationFlag{
+		Name:     "mpc.timeout",
+		Usage:    "Maximum time waited for a signature by the MPC signing service",
+		Value:    mpc.DefaultConfig.Timeout,
+		Category: flags.AccountCategory,
+	}
+	PKCS11ModuleFlag = &cli.StringFlag{
+		Name:     "pkcs11.module",
+		Usage:    "Path of a PKCS#11 module providing signing keys stored on an HSM",
+		Category: flags.AccountCategory,
+	}
+	PKCS11PINFileFlag = &cli.StringFlag{
+		Name:     "pkcs11.pinfile",
+		Usage:    "File containing the PIN of the PKCS#11 tokens",
+		Category: flags.AccountCategory,
+	}
+	VaultAddressFlag = &cli.StringFlag{
+		Name:     "vault.addr",
+		Usage:    "URL of a HashiCorp Vault server storing signing keys",
+		Category: flags.AccountCategory,
+	}
+	VaultKeysFlag = &cli.StringFlag{
+		Name:     "vault.keys",
+		Usage:    "Comma separated paths of the Vault KV secrets holding signing keys",
+		Category: flags.AccountCategory,
+	}
+	VaultMountFlag = &cli.StringFlag{
+		Name:     "vault.mount",
+		Usage:    "Mount path of the Vault KV version 2 engine storing the keys",
+		Value:    "secret",
+		Category: flags.AccountCategory,
+	}
+	VaultTransitFlag = &cli.StringFlag{
+		Name:     "vault.transit",
+		Usage:    "Vault transit key encrypting the stored keys (<mount>/<key>)",
+		Category: flags.AccountCategory,
+	}
+	VaultAuthMethodFlag = &cli.StringFlag{
+		Name:     "vault.auth",
+		Usage:    "Vault auth method (token, approle, kubernetes)",
+		Value:    vault.AuthToken,
+		Category: flags.AccountCategory,
+	}
+	VaultAuthMountFlag = &cli.StringFlag{
+		Name:     "vault.auth.mount",
+		Usage:    "Mount path of the Vault auth method, defaults to its name",
+		Category: flags.AccountCategory,
+	}
+	VaultAuthRoleFlag = &cli.StringFlag{
+		Name:     "vault.auth.role",
+		Usage:    "Role ID of AppRole auth, or role of Kubernetes auth",
+		Category: flags.AccountCategory,
+	}
+	VaultSecretFileFlag = &cli.StringFlag{
+		Name:     "vault.auth.secretfile",
+		Usage:    "File containing the Vault token, AppRole secret ID or Kubernetes service account token",
+		Category: flags.AccountCategory,
+	}
+	AddressBookFlag = &cli.BoolFlag{
+		Name:     "addressbook",
+		Usage:    "Enable the address book of watch-only accounts (addressbook_ RPC namespace)",
+		Category: flags.AccountCategory,
+	}
 	InsecureUnlockAllowedFlag = &cli.BoolFlag{
 		Name:     "allow-insecure-unlock",
 		Usage:    "Allow insecure account unlocking when account-related RPCs are exposed by http",
 		Category: flags.AccountCategory,
 	}
+	RequireEIP155Flag = &cli.StringFlag{
+		Name:     "accounts.require-eip155",
+		Usage:    "Comma separated accounts that may only sign and send replay-protected (EIP-155) transactions, or \"all\"",
+		Category: flags.AccountCategory,
+	}
+	SigningAuditLogFlag = &cli.PathFlag{
+		Name:     "audit.log",
+		Usage:    "File recording the signing operations requested over RPC (relative to the data directory)",
+		Category: flags.AccountCategory,
+	}
+	SigningAuditHashChainFlag = &cli.BoolFlag{
+		Name:     "audit.hashchain",
+		Usage:    "Hash-chain the records of the signing audit log",
+		Category: flags.AccountCategory,
+	}
 
 	// EVM settings
 	VMEnableDebugFlag = &cli.BoolFlag{
@@ -670,6 +850,17 @@ var (
 		Value:    "",
 		Category: flags.APICategory,
 	}
+	HTTPCompressionThresholdFlag = &cli.IntFlag{
+		Name:     "http.compression.threshold",
+		Usage:    "Minimum size in bytes of HTTP-RPC responses to compress",
+		Value:    node.DefaultConfig.HTTPCompressionThreshold,
+		Category: flags.APICategory,
+	}
+	HTTPJWTSecretFlag = &flags.DirectoryFlag{
+		Name:     "http.jwtsecret",
+		Usage:    "Path to a JWT secret required to authenticate HTTP-RPC requests",
+		Category: flags.APICategory,
+	}
 	GraphQLEnabledFlag = &cli.BoolFlag{
 		Name:     "graphql",
 		Usage:    "Enable GraphQL on the HTTP-RPC server. Note that GraphQL can only be started if an HTTP server is started as well.",
@@ -687,6 +878,55 @@ var (
 		Value:    strings.Join(node.DefaultConfig.GraphQLVirtualHosts, ","),
 		Category: flags.APICategory,
 	}
+	HealthEnabledFlag = &cli.BoolFlag{
+		Name:     "health",
+		Usage:    "Enable the /health and /ready endpoints on the HTTP-RPC server. Note that they can only be served if an HTTP server is started as well.",
+		Category: flags.APICategory,
+	}
+	HealthMinPeersFlag = &cli.IntFlag{
+		Name:     "health.minpeers",
+		Usage:    "Minimum number of connected peers for the node to be reported as ready",
+		Value:    health.DefaultConfig.MinPeers,
+		Category: flags.APICategory,
+	}
+	HealthMaxBlockAgeFlag = &cli.DurationFlag{
+		Name:     "health.maxblockage",
+		Usage:    "Maximum age of the head block for the node to be reported as ready (0 = disabled)",
+		Value:    health.DefaultConfig.MaxBlockAge,
+		Category: flags.APICategory,
+	}
+	GRPCEnabledFlag = &cli.BoolFlag{
+		Name:     "grpc",
+		Usage:    "Enable the gRPC server exposing the core eth and debug methods",
+		Category: flags.APICategory,
+	}
+	GRPCListenAddrFlag = &cli.StringFlag{
+		Name:     "grpc.addr",
+		Usage:    "gRPC server listening interface",
+		Value:    ethgrpc.DefaultConfig.Host,
+		Category: flags.APICategory,
+	}
+	GRPCPortFlag = &cli.IntFlag{
+		Name:     "grpc.port",
+		Usage:    "gRPC server listening port",
+		Value:    ethgrpc.DefaultConfig.Port,
+		Category: flags.APICategory,
+	}
+	GRPCJWTSecretFlag = &flags.DirectoryFlag{
+		Name:     "grpc.jwtsecret",
+		Usage:    "Path to a JWT secret required to authenticate gRPC calls",
+		Category: flags.APICategory,
+	}
+	GRPCTLSCertFlag = &cli.StringFlag{
+		Name:     "grpc.tls.cert",
+		Usage:    "TLS certificate file of the gRPC server",
+		Category: flags.APICategory,
+	}
+	GRPCTLSKeyFlag = &cli.StringFlag{
+		Name:     "grpc.tls.key",
+		Usage:    "TLS key file of the gRPC server",
+		Category: flags.APICategory,
+	}
 	WSEnabledFlag = &cli.BoolFlag{
 		Name:     "ws",
 		Usage:    "Enable the WS-RPC server",
@@ -722,6 +962,83 @@ var (
 		Value:    "",
 		Category: flags.APICategory,
 	}
+	WSCompressionFlag = &cli.BoolFlag{
+		Name:     "ws.compression",
+		Usage:    "Enable permessage-deflate compression on WS-RPC connections",
+		Category: flags.APICategory,
+	}
+	WSCompressionLevelFlag = &cli.IntFlag{
+		Name:     "ws.compression.level",
+		Usage:    "Compression level (1-9) of compressed WS-RPC messages",
+		Value:    node.DefaultConfig.WSCompressionLevel,
+		Category: flags.APICategory,
+	}
+	WSCompressionThresholdFlag = &cli.IntFlag{
+		Name:     "ws.compression.threshold",
+		Usage:    "Minimum size in bytes of WS-RPC messages to compress",
+		Value:    node.DefaultConfig.WSCompressionThreshold,
+		Category: flags.APICategory,
+	}
+	WSSubscriptionPolicyFlag = &cli.StringFlag{
+		Name:     "ws.subscription.policy",
+		Usage:    "Handling of WS-RPC subscribers not keeping up with notifications (none, buffer, drop-oldest, disconnect)",
+		Value:    "none",
+		Category: flags.APICategory,
+	}
+	WSSubscriptionBufferFlag = &cli.IntFlag{
+		Name:     "ws.subscription.buffer",
+		Usage:    "Number of notifications queued per WS-RPC subscription before the subscription policy applies",
+		Value:    node.DefaultConfig.WSSubscriptionBuffer,
+		Category: flags.APICategory,
+	}
+	WSPingIntervalFlag = &cli.DurationFlag{
+		Name:     "ws.ping.interval",
+		Usage:    "Time without outgoing messages after which a ping is sent on WS-RPC connections",
+		Value:    node.DefaultConfig.WSPingInterval,
+		Category: flags.APICategory,
+	}
+	WSPongTimeoutFlag = &cli.DurationFlag{
+		Name:     "ws.pong.timeout",
+		Usage:    "Time to wait for the pong answering a ping before closing a WS-RPC connection",
+		Value:    node.DefaultConfig.WSPongTimeout,
+		Category: flags.APICategory,
+	}
+	WSWriteTimeoutFlag = &cli.DurationFlag{
+		Name:     "ws.write.timeout",
+		Usage:    "Deadline of writing a message to a WS-RPC connection",
+		Value:    node.DefaultConfig.WSWriteTimeout,
+		Category: flags.APICategory,
+	}
+	WSIdleTimeoutFlag = &cli.DurationFlag{
+		Name:     "ws.idle.timeout",
+		Usage:    "Time after which WS-RPC connections without any messages are closed (0 = keep open)",
+		Category: flags.APICategory,
+	}
+	WSMaxConnectionsFlag = &cli.IntFlag{
+		Name:     "ws.connections.max",
+		Usage:    "Maximum number of concurrent WS-RPC connections (0 = no limit)",
+		Category: flags.APICategory,
+	}
+	WSMaxSubscriptionsFlag = &cli.IntFlag{
+		Name:     "ws.subscriptions.max",
+		Usage:    "Maximum number of active subscriptions per WS-RPC connection (0 = no limit)",
+		Category: flags.APICategory,
+	}
+	WSMessageRateFlag = &cli.Float64Flag{
+		Name:     "ws.messages.rate",
+		Usage:    "Sustained number of messages per second accepted from a WS-RPC connection (0 = no limit)",
+		Category: flags.APICategory,
+	}
+	WSMessageBurstFlag = &cli.IntFlag{
+		Name:     "ws.messages.burst",
+		Usage:    "Number of messages accepted at once from a WS-RPC connection above the sustained rate",
+		Category: flags.APICategory,
+	}
+	WSJWTSecretFlag = &flags.DirectoryFlag{
+		Name:     "ws.jwtsecret",
+		Usage:    "Path to a JWT secret required to authenticate WS-RPC connections",
+		Category: flags.APICategory,
+	}
 	ExecFlag = &cli.StringFlag{
 		Name:     "exec",
 		Usage:    "Execute JavaScript statement",
@@ -737,12 +1054,98 @@ var (
 		Usage:    "Allow for unprotected (non EIP155 signed) transactions to be submitted via RPC",
 		Category: flags.APICategory,
 	}
+	RPCRateLimitFlag = &cli.Float64Flag{
+		Name:     "rpc.ratelimit",
+		Usage:    "Maximum number of HTTP/WS-RPC calls per second per client (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitBurstFlag = &cli.IntFlag{
+		Name:     "rpc.ratelimit.burst",
+		Usage:    "Maximum burst of HTTP/WS-RPC calls per client",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitConcurrentFlag = &cli.IntFlag{
+		Name:     "rpc.ratelimit.concurrent",
+		Usage:    "Maximum number of in-flight HTTP/WS-RPC calls per client (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitGetLogsFlag = &cli.Float64Flag{
+		Name:     "rpc.ratelimit.getlogs",
+		Usage:    "Maximum number of eth_getLogs calls per second per client (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	RPCAuditLogFlag = &cli.StringFlag{
+		Name:     "rpc.auditlog",
+		Usage:    "File to append a JSON record of every HTTP/WS-RPC call to (secrets are redacted)",
+		Category: flags.APICategory,
+	}
+	RPCAuditNamespacesFlag = &cli.StringFlag{
+		Name:     "rpc.auditlog.namespaces",
+		Usage:    "Comma separated list of API namespaces recorded in the RPC audit log (default = all)",
+		Category: flags.APICategory,
+	}
+	RPCTrustedProxiesFlag = &cli.StringFlag{
+		Name:     "rpc.trustedproxies",
+		Usage:    "Comma separated list of addresses or CIDR ranges of proxies whose X-Forwarded-For headers identify HTTP/WS-RPC clients",
+		Category: flags.APICategory,
+	}
+	RPCProxyProtocolFlag = &cli.BoolFlag{
+		Name:     "rpc.proxyprotocol",
+		Usage:    "Accept PROXY protocol headers on HTTP/WS-RPC connections from trusted proxies",
+		Category: flags.APICategory,
+	}
+	RPCCacheFlag = &cli.IntFlag{
+		Name:     "rpc.cache",
+		Usage:    "Megabytes of memory allocated to caching HTTP/WS-RPC results of immutable chain data (0 = disabled)",
+		Category: flags.APICategory,
+	}
+	RPCCacheDepthFlag = &cli.Uint64Flag{
+		Name:     "rpc.cache.depth",
+		Usage:    "Number of blocks after which non-finalized blocks are considered immutable by the RPC cache (0 = finalized only)",
+		Category: flags.APICategory,
+	}
+	RPCLogsRangeLimitFlag = &cli.Uint64Flag{
+		Name:     "rpc.logs.range-limit",
+		Usage:    "Maximum number of blocks queried by a single eth_getLogs request (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	RPCLogsResultLimitFlag = &cli.IntFlag{
+		Name:     "rpc.logs.result-limit",
+		Usage:    "Maximum number of logs returned by a single eth_getLogs request (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	RPCLogsSplitBudgetFlag = &cli.Uint64Flag{
+		Name:     "rpc.logs.split-budget",
+		Usage:    "Maximum number of blocks of eth_getLogs ranges which are split into chunks of the range limit instead of being rejected",
+		Category: flags.APICategory,
+	}
+	RPCPersistFiltersFlag = &cli.BoolFlag{
+		Name:     "rpc.filters.persist",
+		Usage:    "Keep installed log and block filters across restarts, replaying missed changes on the first poll",
+		Category: flags.APICategory,
+	}
+	RPCProofReexecFlag = &cli.Uint64Flag{
+		Name:     "rpc.proof.reexec",
+		Usage:    "Maximum number of blocks re-executed to regenerate pruned state for eth_getProof (0 = disabled)",
+		Value:    ethconfig.Defaults.RPCProofReexec,
+		Category: flags.APICategory,
+	}
 	BatchRequestLimit = &cli.IntFlag{
 		Name:     "rpc.batch-request-limit",
 		Usage:    "Maximum number of requests in a batch",
 		Value:    node.DefaultConfig.BatchRequestLimit,
 		Category: flags.APICategory,
 	}
+	BatchTimeout = &cli.DurationFlag{
+		Name:     "rpc.batch-timeout",
+		Usage:    "Maximum time spent executing the calls of a batch (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	RPCMethodTimeoutsFlag = &cli.StringFlag{
+		Name:     "rpc.method-timeouts",
+		Usage:    "Comma separated list of per-method execution deadlines (e.g. eth_call=5s,debug_traceTransaction=30s)",
+		Category: flags.APICategory,
+	}
 	BatchResponseMaxSize = &cli.IntFlag{
 		Name:     "rpc.batch-response-max-size",
 		Usage:    "Maximum number of bytes returned from a batched call",
@@ -768,6 +1171,86 @@ var (
 		Value:    node.DefaultConfig.P2P.MaxPendingPeers,
 		Category: flags.NetworkingCategory,
 	}
+	EthMaxMessageSizeFlag = &cli.Uint64Flag{
+		Name:     "p2p.eth.maxmsgsize",
+		Usage:    "Maximum size of eth protocol messages in bytes, at most 16MB (0 = default)",
+		Category: flags.NetworkingCategory,
+	}
+	SnapMaxMessageSizeFlag = &cli.Uint64Flag{
+		Name:     "p2p.snap.maxmsgsize",
+		Usage:    "Maximum size of snap protocol messages in bytes, at most 16MB (0 = default)",
+		Category: flags.NetworkingCategory,
+	}
+	MessageRateLimitsFlag = &cli.StringFlag{
+		Name:     "p2p.ratelimits",
+		Usage:    "Comma separated per-peer message rate limits in messages per second (e.g. eth/getBlockHeaders=100,snap/getTrieNodes=20)",
+		Category: flags.NetworkingCategory,
+	}
+	DialRedialIntervalFlag = &cli.DurationFlag{
+		Name:     "dial.interval",
+		Usage:    "Minimum time between dials to the same node (defaults used if set to 0)",
+		Category: flags.NetworkingCategory,
+	}
+	DialMaxBackoffFlag = &cli.DurationFlag{
+		Name:     "dial.maxbackoff",
+		Usage:    "Maximum redial backoff of static nodes failing to connect (0 = no backoff)",
+		Category: flags.NetworkingCategory,
+	}
+	DialMaxResolveDelayFlag = &cli.DurationFlag{
+		Name:     "dial.maxresolvedelay",
+		Usage:    "Maximum backoff of endpoint lookups for static nodes (defaults used if set to 0)",
+		Category: flags.NetworkingCategory,
+	}
+	DialPriorityFlag = &cli.StringFlag{
+		Name:     "dial.priority",
+		Usage:    "Comma separated order of dial candidate sources: static, bootnodes, discovery (default: static,discovery)",
+		Category: flags.NetworkingCategory,
+	}
+	DialKeepStaticFlag = &cli.BoolFlag{
+		Name:     "dial.keepstatic",
+		Usage:    "Redial static nodes immediately on disconnect and exempt them from the dial slot limit",
+		Category: flags.NetworkingCategory,
+	}
+	BandwidthPeerInFlag = &cli.IntFlag{
+		Name:     "bandwidth.peer.in",
+		Usage:    "Maximum download rate per peer in bytes per second (0 = unlimited)",
+		Category: flags.NetworkingCategory,
+	}
+	BandwidthPeerOutFlag = &cli.IntFlag{
+		Name:     "bandwidth.peer.out",
+		Usage:    "Maximum upload rate per peer in bytes per second (0 = unlimited)",
+		Category: flags.NetworkingCategory,
+	}
+	BandwidthInFlag = &cli.IntFlag{
+		Name:     "bandwidth.in",
+		Usage:    "Maximum download rate of all peers combined in bytes per second (0 = unlimited)",
+		Category: flags.NetworkingCategory,
+	}
+	BandwidthOutFlag = &cli.IntFlag{
+		Name:     "bandwidth.out",
+		Usage:    "Maximum upload rate of all peers combined in bytes per second (0 = unlimited)",
+		Category: flags.NetworkingCategory,
+	}
+	P2PWebSocketAddrFlag = &cli.StringFlag{
+		Name:     "p2p.ws.addr",
+		Usage:    "Network listening address of the devp2p WebSocket transport (disabled if empty)",
+		Category: flags.NetworkingCategory,
+	}
+	P2PWebSocketCertFlag = &cli.StringFlag{
+		Name:     "p2p.ws.cert",
+		Usage:    "TLS certificate file of the devp2p WebSocket transport",
+		Category: flags.NetworkingCategory,
+	}
+	P2PWebSocketKeyFlag = &cli.StringFlag{
+		Name:     "p2p.ws.key",
+		Usage:    "TLS key file of the devp2p WebSocket transport",
+		Category: flags.NetworkingCategory,
+	}
+	P2PWebSocketPreferFlag = &cli.BoolFlag{
+		Name:     "p2p.ws.prefer",
+		Usage:    "Dial peers over WebSocket whenever they announce a WebSocket endpoint",
+		Category: flags.NetworkingCategory,
+	}
 	ListenPortFlag = &cli.IntFlag{
 		Name:     "port",
 		Usage:    "Network listening port",
@@ -780,6 +1263,21 @@ var (
 		Value:    "",
 		Category: flags.NetworkingCategory,
 	}
+	BootnodesDNSFlag = &cli.StringFlag{
+		Name:     "bootnodes.dns",
+		Usage:    "Comma separated enrtree:// URLs of DNS node lists for P2P discovery bootstrap",
+		Category: flags.NetworkingCategory,
+	}
+	StaticNodesFileFlag = &cli.StringFlag{
+		Name:     "staticnodes",
+		Usage:    "JSON file of static node enode URLs, reloaded on change",
+		Category: flags.NetworkingCategory,
+	}
+	TrustedNodesFileFlag = &cli.StringFlag{
+		Name:     "trustednodes",
+		Usage:    "JSON file of trusted node enode URLs, reloaded on change",
+		Category: flags.NetworkingCategory,
+	}
 	NodeKeyFileFlag = &cli.StringFlag{
 		Name:     "nodekey",
 		Usage:    "P2P node key file",
@@ -796,6 +1294,21 @@ var (
 		Value:    "any",
 		Category: flags.NetworkingCategory,
 	}
+	AdvertiseIPFlag = &cli.StringFlag{
+		Name:     "nat.advertise.ip",
+		Usage:    "External IP announced in the node record, overriding NAT and discovery detection",
+		Category: flags.NetworkingCategory,
+	}
+	AdvertiseTCPFlag = &cli.IntFlag{
+		Name:     "nat.advertise.tcp",
+		Usage:    "External TCP port announced in the node record, instead of the listening port",
+		Category: flags.NetworkingCategory,
+	}
+	AdvertiseUDPFlag = &cli.IntFlag{
+		Name:     "nat.advertise.udp",
+		Usage:    "External UDP port announced in the node record, instead of the discovery port",
+		Category: flags.NetworkingCategory,
+	}
 	NoDiscoverFlag = &cli.BoolFlag{
 		Name:     "nodiscover",
 		Usage:    "Disables the peer discovery mechanism (manual peer addition)",
@@ -814,6 +1327,41 @@ var (
 		Usage:    "Enables the experimental RLPx V5 (Topic Discovery) mechanism",
 		Category: flags.NetworkingCategory,
 	}
+	DiscoveryTopicsFlag = &cli.StringFlag{
+		Name:     "discovery.topics",
+		Usage:    "Comma separated topics to advertise and find peers for via V5 discovery",
+		Category: flags.NetworkingCategory,
+	}
+	PermissionFileFlag = &cli.StringFlag{
+		Name:     "permission.file",
+		Usage:    "Signed policy file of the nodes permitted to connect (requires --permission.signer)",
+		Category: flags.NetworkingCategory,
+	}
+	PermissionSignerFlag = &cli.StringFlag{
+		Name:     "permission.signer",
+		Usage:    "Address of the network operator signing the permission policy file",
+		Category: flags.NetworkingCategory,
+	}
+	PermissionContractFlag = &cli.StringFlag{
+		Name:     "permission.contract",
+		Usage:    "Address of the allowlist contract deciding which nodes may connect (static and trusted peers are exempt)",
+		Category: flags.NetworkingCategory,
+	}
+	SentryNodesFlag = &cli.StringFlag{
+		Name:     "sentry.nodes",
+		Usage:    "Comma separated enode URLs of the sentries to run behind as a validator (connects to no other nodes)",
+		Category: flags.NetworkingCategory,
+	}
+	SentryValidatorsFlag = &cli.StringFlag{
+		Name:     "sentry.validators",
+		Usage:    "Comma separated enode URLs of the validators to serve as a sentry",
+		Category: flags.NetworkingCategory,
+	}
+	PeerClassFlag = &cli.StringSliceFlag{
+		Name:     "peerclass",
+		Usage:    "Reserved peer slots in \"name:inbound:outbound:members\" format, members being comma separated enode URLs or ENR attributes (attr:key or attr:key=value). This flag can be given multiple times.",
+		Category: flags.NetworkingCategory,
+	}
 	NetrestrictFlag = &cli.StringFlag{
 		Name:     "netrestrict",
 		Usage:    "Restricts network communication to the given IP networks (CIDR masks)",
@@ -864,6 +1412,11 @@ var (
 		Value:    ethconfig.Defaults.GPO.MaxPrice.Int64(),
 		Category: flags.GasPriceCategory,
 	}
+	GpoMinGasPriceFlag = &cli.Int64Flag{
+		Name:     "gpo.minprice",
+		Usage:    "Minimum transaction priority fee (or gasprice before London fork) to be recommended by gpo",
+		Category: flags.GasPriceCategory,
+	}
 	GpoIgnoreGasPriceFlag = &cli.Int64Flag{
 		Name:     "gpo.ignoreprice",
 		Usage:    "Gas price below which gpo will ignore transactions",
@@ -1122,6 +1675,19 @@ func setNAT(ctx *cli.Context, cfg *p2p.Config) {
 		}
 		cfg.NAT = natif
 	}
+	if ctx.IsSet(AdvertiseIPFlag.Name) {
+		ip := net.ParseIP(ctx.String(AdvertiseIPFlag.Name))
+		if ip == nil {
+			Fatalf("Option %s: invalid IP address %q", AdvertiseIPFlag.Name, ctx.String(AdvertiseIPFlag.Name))
+		}
+		cfg.AdvertiseIP = ip
+	}
+	if ctx.IsSet(AdvertiseTCPFlag.Name) {
+		cfg.AdvertiseTCP = ctx.Int(AdvertiseTCPFlag.Name)
+	}
+	if ctx.IsSet(AdvertiseUDPFlag.Name) {
+		cfg.AdvertiseUDP = ctx.Int(AdvertiseUDPFlag.Name)
+	}
 }
 
 // SplitAndTrim splits input separated by a comma
@@ -1179,6 +1745,13 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(HTTPPathPrefixFlag.Name) {
 		cfg.HTTPPathPrefix = ctx.String(HTTPPathPrefixFlag.Name)
 	}
+
+	if ctx.IsSet(HTTPCompressionThresholdFlag.Name) {
+		cfg.HTTPCompressionThreshold = ctx.Int(HTTPCompressionThresholdFlag.Name)
+	}
+	if ctx.IsSet(HTTPJWTSecretFlag.Name) {
+		cfg.HTTPJWTSecret = ctx.String(HTTPJWTSecretFlag.Name)
+	}
 	if ctx.IsSet(AllowUnprotectedTxs.Name) {
 		cfg.AllowUnprotectedTxs = ctx.Bool(AllowUnprotectedTxs.Name)
 	}
@@ -1190,6 +1763,68 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(BatchResponseMaxSize.Name) {
 		cfg.BatchResponseMaxSize = ctx.Int(BatchResponseMaxSize.Name)
 	}
+
+	if ctx.IsSet(BatchTimeout.Name) {
+		cfg.BatchTimeout = ctx.Duration(BatchTimeout.Name)
+	}
+	if ctx.IsSet(RPCMethodTimeoutsFlag.Name) {
+		cfg.RPCMethodTimeouts = make(map[string]time.Duration)
+		for _, entry := range SplitAndTrim(ctx.String(RPCMethodTimeoutsFlag.Name)) {
+			method, value, ok := strings.Cut(entry, "=")
+			if !ok {
+				Fatalf("Option %q: invalid entry %q, expected method=duration", RPCMethodTimeoutsFlag.Name, entry)
+			}
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				Fatalf("Option %q: invalid timeout for %s: %v", RPCMethodTimeoutsFlag.Name, method, err)
+			}
+			cfg.RPCMethodTimeouts[method] = timeout
+		}
+	}
+	if ctx.IsSet(RPCCacheFlag.Name) {
+		cfg.RPCCacheSize = ctx.Int(RPCCacheFlag.Name)
+	}
+	if ctx.IsSet(RPCTrustedProxiesFlag.Name) {
+		cfg.RPCTrustedProxies = SplitAndTrim(ctx.String(RPCTrustedProxiesFlag.Name))
+	}
+	if ctx.IsSet(RPCProxyProtocolFlag.Name) {
+		cfg.RPCProxyProtocol = ctx.Bool(RPCProxyProtocolFlag.Name)
+	}
+	if ctx.IsSet(RPCAuditLogFlag.Name) {
+		cfg.RPCAuditLog = ctx.String(RPCAuditLogFlag.Name)
+	}
+	if ctx.IsSet(RPCAuditNamespacesFlag.Name) {
+		cfg.RPCAuditNamespaces = SplitAndTrim(ctx.String(RPCAuditNamespacesFlag.Name))
+	}
+	setRPCRateLimit(ctx, cfg)
+}
+
+// setRPCRateLimit configures the per-client RPC request quotas from the set
+// command line flags.
+func setRPCRateLimit(ctx *cli.Context, cfg *node.Config) {
+	if !ctx.IsSet(RPCRateLimitFlag.Name) && !ctx.IsSet(RPCRateLimitConcurrentFlag.Name) && !ctx.IsSet(RPCRateLimitGetLogsFlag.Name) {
+		return
+	}
+	if cfg.RPCRateLimit == nil {
+		cfg.RPCRateLimit = new(rpc.RateLimit)
+	}
+	if ctx.IsSet(RPCRateLimitFlag.Name) {
+		cfg.RPCRateLimit.RequestsPerSecond = ctx.Float64(RPCRateLimitFlag.Name)
+	}
+	if ctx.IsSet(RPCRateLimitBurstFlag.Name) {
+		cfg.RPCRateLimit.Burst = ctx.Int(RPCRateLimitBurstFlag.Name)
+	}
+	if ctx.IsSet(RPCRateLimitConcurrentFlag.Name) {
+		cfg.RPCRateLimit.MaxConcurrent = ctx.Int(RPCRateLimitConcurrentFlag.Name)
+	}
+	if ctx.IsSet(RPCRateLimitGetLogsFlag.Name) {
+		if cfg.RPCRateLimit.Methods == nil {
+			cfg.RPCRateLimit.Methods = make(map[string]rpc.MethodRateLimit)
+		}
+		cfg.RPCRateLimit.Methods["eth_getLogs"] = rpc.MethodRateLimit{
+			RequestsPerSecond: ctx.Float64(RPCRateLimitGetLogsFlag.Name),
+		}
+	}
 }
 
 // setGraphQL creates the GraphQL listener interface string from the set
@@ -1229,6 +1864,65 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(WSPathPrefixFlag.Name) {
 		cfg.WSPathPrefix = ctx.String(WSPathPrefixFlag.Name)
 	}
+
+	if ctx.IsSet(WSCompressionFlag.Name) {
+		cfg.WSCompression = ctx.Bool(WSCompressionFlag.Name)
+	}
+
+	if ctx.IsSet(WSCompressionLevelFlag.Name) {
+		cfg.WSCompressionLevel = ctx.Int(WSCompressionLevelFlag.Name)
+	}
+
+	if ctx.IsSet(WSCompressionThresholdFlag.Name) {
+		cfg.WSCompressionThreshold = ctx.Int(WSCompressionThresholdFlag.Name)
+	}
+
+	if ctx.IsSet(WSSubscriptionPolicyFlag.Name) {
+		policy, err := rpc.ParseBackpressurePolicy(ctx.String(WSSubscriptionPolicyFlag.Name))
+		if err != nil {
+			Fatalf("Option %q: %v", WSSubscriptionPolicyFlag.Name, err)
+		}
+		cfg.WSSubscriptionPolicy = policy
+	}
+
+	if ctx.IsSet(WSSubscriptionBufferFlag.Name) {
+		cfg.WSSubscriptionBuffer = ctx.Int(WSSubscriptionBufferFlag.Name)
+	}
+
+	if ctx.IsSet(WSPingIntervalFlag.Name) {
+		cfg.WSPingInterval = ctx.Duration(WSPingIntervalFlag.Name)
+	}
+
+	if ctx.IsSet(WSPongTimeoutFlag.Name) {
+		cfg.WSPongTimeout = ctx.Duration(WSPongTimeoutFlag.Name)
+	}
+
+	if ctx.IsSet(WSWriteTimeoutFlag.Name) {
+		cfg.WSWriteTimeout = ctx.Duration(WSWriteTimeoutFlag.Name)
+	}
+
+	if ctx.IsSet(WSIdleTimeoutFlag.Name) {
+		cfg.WSIdleTimeout = ctx.Duration(WSIdleTimeoutFlag.Name)
+	}
+
+	if ctx.IsSet(WSMaxConnectionsFlag.Name) {
+		cfg.WSMaxConnections = ctx.Int(WSMaxConnectionsFlag.Name)
+	}
+
+	if ctx.IsSet(WSMaxSubscriptionsFlag.Name) {
+		cfg.WSMaxSubscriptions = ctx.Int(WSMaxSubscriptionsFlag.Name)
+	}
+
+	if ctx.IsSet(WSMessageRateFlag.Name) {
+		cfg.WSMessageRate = ctx.Float64(WSMessageRateFlag.Name)
+	}
+
+	if ctx.IsSet(WSMessageBurstFlag.Name) {
+		cfg.WSMessageBurst = ctx.Int(WSMessageBurstFlag.Name)
+	}
+	if ctx.IsSet(WSJWTSecretFlag.Name) {
+		cfg.WSJWTSecret = ctx.String(WSJWTSecretFlag.Name)
+	}
 }
 
 // setIPC creates an IPC path configuration from the set command line flags,
@@ -1317,6 +2011,43 @@ func MakeAddress(ks *keystore.KeyStore, account string) (accounts.Account, error
 	return accs[index], nil
 }
 
+// MakeKeyStoreDirs returns the additional key directories of the keystore.
+func MakeKeyStoreDirs(cfg *node.Config) []keystore.KeyDir {
+	var dirs []keystore.KeyDir
+	for _, spec := range cfg.KeyStoreExtraDirs {
+		dir, err := keystore.ParseKeyDir(spec)
+		if err != nil {
+			Fatalf("Invalid keystore directory: %v", err)
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// MakeKeyStoreKDF returns the key derivation function encrypting new keys, as
+// configured or calibrated for the configured unlock time.
+func MakeKeyStoreKDF(cfg *node.Config) keystore.KDFConfig {
+	kdf := keystore.ScryptKDF(keystore.StandardScryptN, keystore.StandardScryptP)
+	if cfg.UseLightweightKDF {
+		kdf = keystore.ScryptKDF(keystore.LightScryptN, keystore.LightScryptP)
+	}
+	if cfg.KeyStoreKDF != "" {
+		var err error
+		if kdf, err = keystore.ParseKDFConfig(cfg.KeyStoreKDF); err != nil {
+			Fatalf("Invalid keystore KDF: %v", err)
+		}
+	}
+	if cfg.KeyStoreKDFTarget > 0 {
+		calibrated, err := keystore.CalibrateKDF(kdf.Name, cfg.KeyStoreKDFTarget)
+		if err != nil {
+			Fatalf("Failed to calibrate keystore KDF: %v", err)
+		}
+		log.Info("Calibrated keystore KDF", "kdf", calibrated, "target", cfg.KeyStoreKDFTarget)
+		kdf = calibrated
+	}
+	return kdf
+}
+
 // setEtherbase retrieves the etherbase from the directly specified command line flags.
 func setEtherbase(ctx *cli.Context, cfg *ethconfig.Config) {
 	if ctx.IsSet(MinerEtherbaseFlag.Name) {
@@ -1339,6 +2070,7 @@ func setEtherbase(ctx *cli.Context, cfg *ethconfig.Config) {
 }
 
 // MakePasswordList reads password lines from the file specified by the global --password flag.
+// The passwords can be zeroed with WipePasswords after use.
 func MakePasswordList(ctx *cli.Context) []string {
 	path := ctx.Path(PasswordFileFlag.Name)
 	if path == "" {
@@ -1348,12 +2080,29 @@ func MakePasswordList(ctx *cli.Context) []string {
 	if err != nil {
 		Fatalf("Failed to read password file: %v", err)
 	}
-	lines := strings.Split(string(text), "\n")
-	// Sanitise DOS line endings.
-	for i := range lines {
-		lines[i] = strings.TrimRight(lines[i], "\r")
+	return splitPasswords(text)
+}
+
+// setPermissionPolicy loads the signed policy of the nodes permitted to connect,
+// if configured. The last loaded policy version is recorded in the data directory
+// to reject older policies.
+func setPermissionPolicy(ctx *cli.Context, cfg *node.Config) {
+	if !ctx.IsSet(PermissionFileFlag.Name) {
+		return
+	}
+	if ctx.IsSet(PermissionContractFlag.Name) {
+		Fatalf("Flags %v can't be used at the same time", []string{PermissionFileFlag.Name, PermissionContractFlag.Name})
+	}
+	signer := ctx.String(PermissionSignerFlag.Name)
+	if !common.IsHexAddress(signer) {
+		Fatalf("Option %q requires a valid %q", PermissionFileFlag.Name, PermissionSignerFlag.Name)
 	}
-	return lines
+	versionPath := cfg.ResolvePath("permission-version")
+	policy, err := permission.LoadPolicy(ctx.String(PermissionFileFlag.Name), common.HexToAddress(signer), versionPath)
+	if err != nil {
+		Fatalf("Option %q: %v", PermissionFileFlag.Name, err)
+	}
+	cfg.P2P.Permissions = policy
 }
 
 func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
@@ -1362,6 +2111,9 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 	setListenAddress(ctx, cfg)
 	setBootstrapNodes(ctx, cfg)
 	setBootstrapNodesV5(ctx, cfg)
+	if ctx.IsSet(BootnodesDNSFlag.Name) {
+		cfg.BootstrapDNS = SplitAndTrim(ctx.String(BootnodesDNSFlag.Name))
+	}
 
 	if ctx.IsSet(MaxPeersFlag.Name) {
 		cfg.MaxPeers = ctx.Int(MaxPeersFlag.Name)
@@ -1372,6 +2124,48 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 	if ctx.IsSet(MaxPendingPeersFlag.Name) {
 		cfg.MaxPendingPeers = ctx.Int(MaxPendingPeersFlag.Name)
 	}
+	if ctx.IsSet(DialRedialIntervalFlag.Name) {
+		cfg.RedialInterval = ctx.Duration(DialRedialIntervalFlag.Name)
+	}
+	if ctx.IsSet(DialMaxBackoffFlag.Name) {
+		cfg.MaxRedialBackoff = ctx.Duration(DialMaxBackoffFlag.Name)
+	}
+	if ctx.IsSet(DialMaxResolveDelayFlag.Name) {
+		cfg.MaxResolveDelay = ctx.Duration(DialMaxResolveDelayFlag.Name)
+	}
+	if ctx.IsSet(DialPriorityFlag.Name) {
+		cfg.DialPriority = SplitAndTrim(ctx.String(DialPriorityFlag.Name))
+	}
+	if ctx.IsSet(DialKeepStaticFlag.Name) {
+		cfg.KeepStaticPeers = ctx.Bool(DialKeepStaticFlag.Name)
+	}
+	if ctx.IsSet(BandwidthPeerInFlag.Name) {
+		cfg.MaxPeerIngress = ctx.Int(BandwidthPeerInFlag.Name)
+	}
+	if ctx.IsSet(BandwidthPeerOutFlag.Name) {
+		cfg.MaxPeerEgress = ctx.Int(BandwidthPeerOutFlag.Name)
+	}
+	if ctx.IsSet(BandwidthInFlag.Name) {
+		cfg.MaxIngress = ctx.Int(BandwidthInFlag.Name)
+	}
+	if ctx.IsSet(BandwidthOutFlag.Name) {
+		cfg.MaxEgress = ctx.Int(BandwidthOutFlag.Name)
+	}
+	if ctx.IsSet(P2PWebSocketAddrFlag.Name) {
+		cfg.WebSocketAddr = ctx.String(P2PWebSocketAddrFlag.Name)
+	}
+	if ctx.IsSet(P2PWebSocketCertFlag.Name) {
+		cfg.WebSocketCert = ctx.String(P2PWebSocketCertFlag.Name)
+	}
+	if ctx.IsSet(P2PWebSocketKeyFlag.Name) {
+		cfg.WebSocketKey = ctx.String(P2PWebSocketKeyFlag.Name)
+	}
+	if (cfg.WebSocketCert == "") != (cfg.WebSocketKey == "") {
+		Fatalf("Both --%s and --%s must be set to enable TLS", P2PWebSocketCertFlag.Name, P2PWebSocketKeyFlag.Name)
+	}
+	if ctx.IsSet(P2PWebSocketPreferFlag.Name) {
+		cfg.PreferWebSocket = ctx.Bool(P2PWebSocketPreferFlag.Name)
+	}
 	if ctx.IsSet(NoDiscoverFlag.Name) {
 		cfg.NoDiscovery = true
 	}
@@ -1381,6 +2175,13 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 	cfg.DiscoveryV4 = ctx.Bool(DiscoveryV4Flag.Name)
 	cfg.DiscoveryV5 = ctx.Bool(DiscoveryV5Flag.Name)
 
+	if ctx.IsSet(DiscoveryTopicsFlag.Name) {
+		if !cfg.DiscoveryV5 {
+			Fatalf("Option %q requires %q", DiscoveryTopicsFlag.Name, DiscoveryV5Flag.Name)
+		}
+		cfg.DiscoveryTopics = SplitAndTrim(ctx.String(DiscoveryTopicsFlag.Name))
+	}
+
 	if netrestrict := ctx.String(NetrestrictFlag.Name); netrestrict != "" {
 		list, err := netutil.ParseNetlist(netrestrict)
 		if err != nil {
@@ -1389,6 +2190,22 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 		cfg.NetRestrict = list
 	}
 
+	if ctx.IsSet(SentryNodesFlag.Name) {
+		if ctx.IsSet(SentryValidatorsFlag.Name) {
+			Fatalf("Flags %v can't be used at the same time", []string{SentryNodesFlag.Name, SentryValidatorsFlag.Name})
+		}
+		cfg.Sentries = mustParseSentryNodes(SentryNodesFlag.Name, ctx.String(SentryNodesFlag.Name))
+	}
+	if ctx.IsSet(SentryValidatorsFlag.Name) {
+		cfg.SentryValidators = mustParseSentryNodes(SentryValidatorsFlag.Name, ctx.String(SentryValidatorsFlag.Name))
+	}
+	if ctx.IsSet(PeerClassFlag.Name) {
+		cfg.PeerClasses = nil
+		for _, value := range ctx.StringSlice(PeerClassFlag.Name) {
+			cfg.PeerClasses = append(cfg.PeerClasses, mustParsePeerClass(value))
+		}
+	}
+
 	if ctx.Bool(DeveloperFlag.Name) {
 		// --dev mode can't use p2p networking.
 		cfg.MaxPeers = 0
@@ -1408,11 +2225,18 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setWS(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 	SetDataDir(ctx, cfg)
+	setPermissionPolicy(ctx, cfg)
 	setSmartCard(ctx, cfg)
 
 	if ctx.IsSet(JWTSecretFlag.Name) {
 		cfg.JWTSecret = ctx.String(JWTSecretFlag.Name)
 	}
+	if ctx.IsSet(StaticNodesFileFlag.Name) {
+		cfg.StaticNodesFile = ctx.String(StaticNodesFileFlag.Name)
+	}
+	if ctx.IsSet(TrustedNodesFileFlag.Name) {
+		cfg.TrustedNodesFile = ctx.String(TrustedNodesFileFlag.Name)
+	}
 
 	if ctx.IsSet(EnablePersonal.Name) {
 		cfg.EnablePersonal = true
@@ -1421,6 +2245,48 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(ExternalSignerFlag.Name) {
 		cfg.ExternalSigner = ctx.String(ExternalSignerFlag.Name)
 	}
+	if ctx.IsSet(KMSKeysFlag.Name) {
+		cfg.KMSKeys = SplitAndTrim(ctx.String(KMSKeysFlag.Name))
+	}
+	if ctx.IsSet(MPCEndpointFlag.Name) {
+		cfg.MPCEndpoint = ctx.String(MPCEndpointFlag.Name)
+	}
+	if ctx.IsSet(MPCTimeoutFlag.Name) {
+		cfg.MPCTimeout = ctx.Duration(MPCTimeoutFlag.Name)
+	}
+	if ctx.IsSet(PKCS11ModuleFlag.Name) {
+		cfg.PKCS11Module = ctx.String(PKCS11ModuleFlag.Name)
+	}
+	if ctx.IsSet(PKCS11PINFileFlag.Name) {
+		cfg.PKCS11PINFile = ctx.String(PKCS11PINFileFlag.Name)
+	}
+	if ctx.IsSet(VaultAddressFlag.Name) {
+		cfg.VaultAddress = ctx.String(VaultAddressFlag.Name)
+	}
+	if ctx.IsSet(VaultKeysFlag.Name) {
+		cfg.VaultKeys = SplitAndTrim(ctx.String(VaultKeysFlag.Name))
+	}
+	if ctx.IsSet(VaultMountFlag.Name) {
+		cfg.VaultMount = ctx.String(VaultMountFlag.Name)
+	}
+	if ctx.IsSet(VaultTransitFlag.Name) {
+		cfg.VaultTransit = ctx.String(VaultTransitFlag.Name)
+	}
+	if ctx.IsSet(VaultAuthMethodFlag.Name) {
+		cfg.VaultAuthMethod = ctx.String(VaultAuthMethodFlag.Name)
+	}
+	if ctx.IsSet(VaultAuthMountFlag.Name) {
+		cfg.VaultAuthMount = ctx.String(VaultAuthMountFlag.Name)
+	}
+	if ctx.IsSet(VaultAuthRoleFlag.Name) {
+		cfg.VaultAuthRole = ctx.String(VaultAuthRoleFlag.Name)
+	}
+	if ctx.IsSet(VaultSecretFileFlag.Name) {
+		cfg.VaultSecretFile = ctx.String(VaultSecretFileFlag.Name)
+	}
+	if ctx.IsSet(AddressBookFlag.Name) {
+		cfg.AddressBook = ctx.Bool(AddressBookFlag.Name)
+	}
 
 	if ctx.IsSet(KeyStoreDirFlag.Name) {
 		cfg.KeyStoreDir = ctx.String(KeyStoreDirFlag.Name)
@@ -1431,15 +2297,59 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(LightKDFFlag.Name) {
 		cfg.UseLightweightKDF = ctx.Bool(LightKDFFlag.Name)
 	}
+	if ctx.IsSet(KeyStoreExtraFlag.Name) {
+		cfg.KeyStoreExtraDirs = SplitAndTrim(ctx.String(KeyStoreExtraFlag.Name))
+		for _, spec := range cfg.KeyStoreExtraDirs {
+			if _, err := keystore.ParseKeyDir(spec); err != nil {
+				Fatalf("Option %q: %v", KeyStoreExtraFlag.Name, err)
+			}
+		}
+	}
+	if ctx.IsSet(KeyStoreKDFFlag.Name) {
+		cfg.KeyStoreKDF = ctx.String(KeyStoreKDFFlag.Name)
+		if _, err := keystore.ParseKDFConfig(cfg.KeyStoreKDF); err != nil {
+			Fatalf("Option %q: %v", KeyStoreKDFFlag.Name, err)
+		}
+	}
+	if ctx.IsSet(KeyStoreKDFTargetFlag.Name) {
+		cfg.KeyStoreKDFTarget = ctx.Duration(KeyStoreKDFTargetFlag.Name)
+	}
 	if ctx.IsSet(NoUSBFlag.Name) || cfg.NoUSB {
 		log.Warn("Option nousb is deprecated and USB is deactivated by default. Use --usb to enable")
 	}
 	if ctx.IsSet(USBFlag.Name) {
 		cfg.USB = ctx.Bool(USBFlag.Name)
 	}
+	if ctx.IsSet(USBDerivationPathsFlag.Name) {
+		cfg.USBDerivationPaths = SplitAndTrim(ctx.String(USBDerivationPathsFlag.Name))
+		for _, path := range cfg.USBDerivationPaths {
+			if _, err := accounts.ParseDerivationTemplate(path); err != nil {
+				Fatalf("Option %q: invalid derivation path template %q: %v", USBDerivationPathsFlag.Name, path, err)
+			}
+		}
+	}
 	if ctx.IsSet(InsecureUnlockAllowedFlag.Name) {
 		cfg.InsecureUnlockAllowed = ctx.Bool(InsecureUnlockAllowedFlag.Name)
 	}
+	if ctx.IsSet(SigningAuditLogFlag.Name) {
+		cfg.SigningAuditLog = ctx.Path(SigningAuditLogFlag.Name)
+	}
+	if ctx.IsSet(SigningAuditHashChainFlag.Name) {
+		cfg.SigningAuditHashChain = ctx.Bool(SigningAuditHashChainFlag.Name)
+	}
+	if ctx.IsSet(RequireEIP155Flag.Name) {
+		cfg.StrictReplayProtection, cfg.ReplayProtectedAccounts = false, nil
+		for _, account := range SplitAndTrim(ctx.String(RequireEIP155Flag.Name)) {
+			switch {
+			case account == "all":
+				cfg.StrictReplayProtection = true
+			case common.IsHexAddress(account):
+				cfg.ReplayProtectedAccounts = append(cfg.ReplayProtectedAccounts, common.HexToAddress(account))
+			default:
+				Fatalf("Option %q: invalid account %q", RequireEIP155Flag.Name, account)
+			}
+		}
+	}
 	if ctx.IsSet(DBEngineFlag.Name) {
 		dbEngine := ctx.String(DBEngineFlag.Name)
 		if dbEngine != "leveldb" && dbEngine != "pebble" {
@@ -1502,6 +2412,9 @@ func setGPO(ctx *cli.Context, cfg *gasprice.Config) {
 	if ctx.IsSet(GpoMaxGasPriceFlag.Name) {
 		cfg.MaxPrice = big.NewInt(ctx.Int64(GpoMaxGasPriceFlag.Name))
 	}
+	if ctx.IsSet(GpoMinGasPriceFlag.Name) {
+		cfg.MinPrice = big.NewInt(ctx.Int64(GpoMinGasPriceFlag.Name))
+	}
 	if ctx.IsSet(GpoIgnoreGasPriceFlag.Name) {
 		cfg.IgnorePrice = big.NewInt(ctx.Int64(GpoIgnoreGasPriceFlag.Name))
 	}
@@ -1572,6 +2485,30 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	}
 }
 
+func setBundler(ctx *cli.Context, cfg *bundler.Config) {
+	if !ctx.Bool(BundlerFlag.Name) {
+		return
+	}
+	cfg.Enabled = true
+	if ctx.IsSet(BundlerEntryPointsFlag.Name) {
+		cfg.EntryPoints = nil
+		for _, addr := range SplitAndTrim(ctx.String(BundlerEntryPointsFlag.Name)) {
+			if !common.IsHexAddress(addr) {
+				Fatalf("-%s: invalid entry point address %q", BundlerEntryPointsFlag.Name, addr)
+			}
+			cfg.EntryPoints = append(cfg.EntryPoints, common.HexToAddress(addr))
+		}
+	}
+	addr := ctx.String(BundlerAccountFlag.Name)
+	if !common.IsHexAddress(addr) {
+		Fatalf("-%s: invalid or missing bundler account %q", BundlerAccountFlag.Name, addr)
+	}
+	cfg.Account = common.HexToAddress(addr)
+	if ctx.IsSet(BundlerPoolSizeFlag.Name) {
+		cfg.PoolSize = ctx.Int(BundlerPoolSizeFlag.Name)
+	}
+}
+
 func setRequiredBlocks(ctx *cli.Context, cfg *ethconfig.Config) {
 	requiredBlocks := ctx.String(EthRequiredBlocksFlag.Name)
 	if requiredBlocks == "" {
@@ -1652,6 +2589,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setGPO(ctx, &cfg.GPO)
 	setTxPool(ctx, &cfg.TxPool)
 	setMiner(ctx, &cfg.Miner)
+	setBundler(ctx, &cfg.Bundler)
 	setRequiredBlocks(ctx, cfg)
 	setLes(ctx, cfg)
 
@@ -1680,6 +2618,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	} else if ctx.IsSet(SyncModeFlag.Name) {
 		cfg.SyncMode = *flags.GlobalTextMarshaler(ctx, SyncModeFlag.Name).(*downloader.SyncMode)
 	}
+	if ctx.IsSet(SyncCheckpointFlag.Name) {
+		if cfg.SyncMode != downloader.SnapSync {
+			Fatalf("Option %q requires --%s=snap", SyncCheckpointFlag.Name, SyncModeFlag.Name)
+		}
+		cfg.CheckpointNumber, cfg.CheckpointHash = mustParseCheckpoint(ctx.String(SyncCheckpointFlag.Name))
+	}
 	if ctx.IsSet(NetworkIdFlag.Name) {
 		cfg.NetworkId = ctx.Uint64(NetworkIdFlag.Name)
 	}
@@ -1783,6 +2727,30 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(RPCGlobalTxFeeCapFlag.Name) {
 		cfg.RPCTxFeeCap = ctx.Float64(RPCGlobalTxFeeCapFlag.Name)
 	}
+	if ctx.IsSet(RPCCacheDepthFlag.Name) {
+		cfg.RPCCacheDepth = ctx.Uint64(RPCCacheDepthFlag.Name)
+	}
+	if ctx.IsSet(RPCLogsRangeLimitFlag.Name) {
+		cfg.LogQueryRangeLimit = ctx.Uint64(RPCLogsRangeLimitFlag.Name)
+	}
+	if ctx.IsSet(RPCLogsResultLimitFlag.Name) {
+		cfg.LogQueryResultLimit = ctx.Int(RPCLogsResultLimitFlag.Name)
+	}
+	if ctx.IsSet(RPCLogsSplitBudgetFlag.Name) {
+		cfg.LogQuerySplitBudget = ctx.Uint64(RPCLogsSplitBudgetFlag.Name)
+	}
+	if ctx.IsSet(RPCPersistFiltersFlag.Name) {
+		cfg.PersistFilters = ctx.Bool(RPCPersistFiltersFlag.Name)
+	}
+	if ctx.IsSet(RPCProofReexecFlag.Name) {
+		cfg.RPCProofReexec = ctx.Uint64(RPCProofReexecFlag.Name)
+	}
+	setTxPropagation(ctx, cfg)
+	setMessageLimits(ctx, cfg)
+	// Sentries forward transactions to their validators first.
+	for _, n := range stack.Config().P2P.SentryValidators {
+		cfg.TxValidatorPeers = append(cfg.TxValidatorPeers, n.ID())
+	}
 	if ctx.IsSet(NoDiscoverFlag.Name) {
 		cfg.EthDiscoveryURLs, cfg.SnapDiscoveryURLs = []string{}, []string{}
 	} else if ctx.IsSet(DNSDiscoveryFlag.Name) {
@@ -1830,11 +2798,13 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 			passphrase string
 			err        error
 		)
-		if list := MakePasswordList(ctx); len(list) > 0 {
+		passwords := MakeUnlockPasswords(ctx, stack.Config())
+		defer WipePasswords(passwords)
+		if len(passwords) > 0 {
 			// Just take the first value. Although the function returns a possible multiple values and
 			// some usages iterate through them as attempts, that doesn't make sense in this setting,
 			// when we're definitely concerned with only one account.
-			passphrase = list[0]
+			passphrase = passwords[0]
 		}
 
 		// Unlock the developer account by local keystore.
@@ -1923,6 +2893,122 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	}
 }
 
+// setMessageLimits configures the protocol message limits from the command line
+// flags.
+func setMessageLimits(ctx *cli.Context, cfg *ethconfig.Config) {
+	if ctx.IsSet(EthMaxMessageSizeFlag.Name) {
+		cfg.EthMaxMessageSize = uint32(min(ctx.Uint64(EthMaxMessageSizeFlag.Name), math.MaxUint32))
+	}
+	if ctx.IsSet(SnapMaxMessageSizeFlag.Name) {
+		cfg.SnapMaxMessageSize = uint32(min(ctx.Uint64(SnapMaxMessageSizeFlag.Name), math.MaxUint32))
+	}
+	if ctx.IsSet(MessageRateLimitsFlag.Name) {
+		cfg.MessageRateLimits = make(map[string]float64)
+		for _, entry := range SplitAndTrim(ctx.String(MessageRateLimitsFlag.Name)) {
+			name, value, ok := strings.Cut(entry, "=")
+			if !ok {
+				Fatalf("Invalid message rate limit %q, want <protocol>/<message>=<rate>", entry)
+			}
+			limit, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				Fatalf("Invalid message rate limit %q: %v", entry, err)
+			}
+			cfg.MessageRateLimits[name] = limit
+		}
+	}
+}
+
+// setTxPropagation configures the transaction propagation policy from the
+// command line flags.
+func setTxPropagation(ctx *cli.Context, cfg *ethconfig.Config) {
+	if ctx.IsSet(TxPropagationFanoutFlag.Name) {
+		cfg.TxBroadcastFanout = ctx.Int(TxPropagationFanoutFlag.Name)
+	}
+	if ctx.IsSet(TxPropagationMaxSizeFlag.Name) {
+		cfg.TxBroadcastMaxSize = ctx.Uint64(TxPropagationMaxSizeFlag.Name)
+	}
+	if ctx.IsSet(TxPropagationSendQueueFlag.Name) {
+		cfg.TxSendQueue = ctx.Int(TxPropagationSendQueueFlag.Name)
+	}
+	if ctx.IsSet(TxPropagationAnnounceQueueFlag.Name) {
+		cfg.TxAnnounceQueue = ctx.Int(TxPropagationAnnounceQueueFlag.Name)
+	}
+	if ctx.IsSet(TxPropagationSyncLimitFlag.Name) {
+		cfg.TxSyncLimit = ctx.Int(TxPropagationSyncLimitFlag.Name)
+	}
+	if ctx.IsSet(TxPropagationValidatorsFlag.Name) {
+		cfg.TxValidatorPeers = nil
+		for _, s := range SplitAndTrim(ctx.String(TxPropagationValidatorsFlag.Name)) {
+			if n, err := enode.Parse(enode.ValidSchemes, s); err == nil {
+				cfg.TxValidatorPeers = append(cfg.TxValidatorPeers, n.ID())
+				continue
+			}
+			id, err := enode.ParseID(s)
+			if err != nil {
+				Fatalf("Option %q: invalid validator %q", TxPropagationValidatorsFlag.Name, s)
+			}
+			cfg.TxValidatorPeers = append(cfg.TxValidatorPeers, id)
+		}
+	}
+}
+
+func mustParseSentryNodes(flag, urls string) []*enode.Node {
+	var nodes []*enode.Node
+	for _, url := range SplitAndTrim(urls) {
+		n, err := enode.Parse(enode.ValidSchemes, url)
+		if err != nil {
+			Fatalf("Option %q: invalid enode %q: %v", flag, url, err)
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+// mustParsePeerClass parses a peer class given as <name>:<inbound>:<outbound>:<members>.
+func mustParsePeerClass(value string) p2p.PeerClass {
+	parts := strings.SplitN(value, ":", 4)
+	if len(parts) != 4 {
+		Fatalf("Invalid peer class %q, want <name>:<inbound>:<outbound>:<members>", value)
+	}
+	class := p2p.PeerClass{Name: parts[0]}
+	var err error
+	if class.Inbound, err = strconv.Atoi(parts[1]); err != nil {
+		Fatalf("Invalid inbound slots of peer class %q: %v", class.Name, err)
+	}
+	if class.Outbound, err = strconv.Atoi(parts[2]); err != nil {
+		Fatalf("Invalid outbound slots of peer class %q: %v", class.Name, err)
+	}
+	for _, member := range SplitAndTrim(parts[3]) {
+		if attr, ok := strings.CutPrefix(member, "attr:"); ok {
+			class.Attrs = append(class.Attrs, attr)
+			continue
+		}
+		n, err := enode.Parse(enode.ValidSchemes, member)
+		if err != nil {
+			Fatalf("Invalid member %q of peer class %q: %v", member, class.Name, err)
+		}
+		class.Nodes = append(class.Nodes, n)
+	}
+	return class
+}
+
+// mustParseCheckpoint parses a trusted checkpoint given as <number>:<hash>.
+func mustParseCheckpoint(value string) (uint64, common.Hash) {
+	numStr, hashStr, ok := strings.Cut(value, ":")
+	if !ok {
+		Fatalf("Invalid checkpoint %q, want <number>:<hash>", value)
+	}
+	number, err := strconv.ParseUint(numStr, 0, 64)
+	if err != nil {
+		Fatalf("Invalid checkpoint number %q: %v", numStr, err)
+	}
+	var hash common.Hash
+	if err := hash.UnmarshalText([]byte(hashStr)); err != nil {
+		Fatalf("Invalid checkpoint hash %q: %v", hashStr, err)
+	}
+	return number, hash
+}
+
 // SetDNSDiscoveryDefaults configures DNS discovery with the given URL if
 // no URLs are set.
 func SetDNSDiscoveryDefaults(cfg *ethconfig.Config, genesis common.Hash) {
@@ -1954,6 +3040,34 @@ func RegisterEthStatsService(stack *node.Node, backend ethapi.Backend, url strin
 	}
 }
 
+// RegisterNodePermissions restricts the peers of the node to the ones allowed by
+// the allowlist contract, if configured. The contract is called through the
+// node's own RPC API, so unless it is part of the genesis state, the node must be
+// given static or trusted peers to sync from until it has the contract.
+func RegisterNodePermissions(ctx *cli.Context, stack *node.Node) {
+	if !ctx.IsSet(PermissionContractFlag.Name) {
+		return
+	}
+	addr := ctx.String(PermissionContractFlag.Name)
+	if !common.IsHexAddress(addr) {
+		Fatalf("Option %q: invalid address %q", PermissionContractFlag.Name, addr)
+	}
+	client := ethclient.NewClient(stack.Attach())
+	stack.Server().Permissions = permission.NewContract(client, common.HexToAddress(addr))
+}
+
+// RegisterSentryRelay adds the relay protocol of validators and sentries to the
+// node if it is part of a sentry setup.
+func RegisterSentryRelay(stack *node.Node) {
+	cfg := stack.Config().P2P
+	switch {
+	case len(cfg.Sentries) > 0:
+		stack.RegisterProtocols(sentry.New(sentry.RoleValidator, stack.Server(), cfg.Sentries).Protocols())
+	case len(cfg.SentryValidators) > 0:
+		stack.RegisterProtocols(sentry.New(sentry.RoleSentry, stack.Server(), cfg.SentryValidators).Protocols())
+	}
+}
+
 // RegisterGraphQLService adds the GraphQL API to the node.
 func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, filterSystem *filters.FilterSystem, cfg *node.Config) {
 	err := graphql.New(stack, backend, filterSystem, cfg.GraphQLCors, cfg.GraphQLVirtualHosts)
@@ -1962,10 +3076,40 @@ func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, filterSyst
 	}
 }
 
+// RegisterHealthService adds the health and readiness endpoints to the node.
+func RegisterHealthService(ctx *cli.Context, stack *node.Node, backend ethapi.Backend) {
+	cfg := health.Config{
+		MinPeers:    ctx.Int(HealthMinPeersFlag.Name),
+		MaxBlockAge: ctx.Duration(HealthMaxBlockAgeFlag.Name),
+	}
+	if err := health.New(stack, backend, cfg); err != nil {
+		Fatalf("Failed to register the health service: %v", err)
+	}
+}
+
+// RegisterGRPCService adds the gRPC server to the node. Servers listening on
+// public interfaces require a JWT secret or TLS.
+func RegisterGRPCService(ctx *cli.Context, stack *node.Node) {
+	cfg := ethgrpc.Config{
+		Host:      ctx.String(GRPCListenAddrFlag.Name),
+		Port:      ctx.Int(GRPCPortFlag.Name),
+		JWTSecret: ctx.String(GRPCJWTSecretFlag.Name),
+		TLSCert:   ctx.String(GRPCTLSCertFlag.Name),
+		TLSKey:    ctx.String(GRPCTLSKeyFlag.Name),
+	}
+	if err := ethgrpc.New(stack, cfg); err != nil {
+		Fatalf("Failed to register the gRPC service: %v", err)
+	}
+}
+
 // RegisterFilterAPI adds the eth log filtering RPC API to the node.
 func RegisterFilterAPI(stack *node.Node, backend ethapi.Backend, ethcfg *ethconfig.Config) *filters.FilterSystem {
 	filterSystem := filters.NewFilterSystem(backend, filters.Config{
-		LogCacheSize: ethcfg.FilterLogCacheSize,
+		LogCacheSize:   ethcfg.FilterLogCacheSize,
+		LogRangeLimit:  ethcfg.LogQueryRangeLimit,
+		LogResultLimit: ethcfg.LogQueryResultLimit,
+		LogSplitBudget: ethcfg.LogQuerySplitBudget,
+		Persist:        ethcfg.PersistFilters,
 	})
 	stack.RegisterAPIs([]rpc.API{{
 		Namespace: "eth",
@@ -0,0 +1,133 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"unsafe"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/urfave/cli/v2"
+)
+
+const passwordBundleVersion = 1
+
+// passwordBundleMAC is the shared information of the ECIES message tag, binding
+// the ciphertext to its use.
+var passwordBundleMAC = []byte("geth password bundle")
+
+// passwordBundle is the file format of a password bundle: newline separated
+// passwords, sealed to the public key of the recipient with ECIES.
+type passwordBundle struct {
+	Version   int            `json:"version"`
+	Recipient common.Address `json:"recipient"` // Address of the sealing key, to identify it
+	Sealed    hexutil.Bytes  `json:"sealed"`
+}
+
+// SealPasswords encrypts newline separated passwords to the public key, returning
+// the password bundle.
+func SealPasswords(pub *ecdsa.PublicKey, passwords []byte) ([]byte, error) {
+	sealed, err := ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(pub), passwords, nil, passwordBundleMAC)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(&passwordBundle{
+		Version:   passwordBundleVersion,
+		Recipient: crypto.PubkeyToAddress(*pub),
+		Sealed:    sealed,
+	}, "", "  ")
+}
+
+// OpenPasswords decrypts a password bundle with the key it was sealed to,
+// returning the newline separated passwords.
+func OpenPasswords(key *ecdsa.PrivateKey, bundle []byte) ([]byte, error) {
+	var b passwordBundle
+	if err := json.Unmarshal(bundle, &b); err != nil {
+		return nil, fmt.Errorf("invalid password bundle: %v", err)
+	}
+	if b.Version != passwordBundleVersion {
+		return nil, fmt.Errorf("unsupported password bundle version %d", b.Version)
+	}
+	if addr := crypto.PubkeyToAddress(key.PublicKey); addr != b.Recipient {
+		return nil, fmt.Errorf("password bundle sealed to key %v, not %v", b.Recipient, addr)
+	}
+	passwords, err := ecies.ImportECDSA(key).Decrypt(b.Sealed, nil, passwordBundleMAC)
+	if err != nil {
+		return nil, errors.New("can't decrypt password bundle")
+	}
+	return passwords, nil
+}
+
+// MakeUnlockPasswords returns the passwords unlocking the accounts of the node,
+// either decrypted from the password bundle of the --password.bundle flag with
+// the node key, or read from the plaintext --password file. The passwords should
+// be zeroed with WipePasswords after use.
+func MakeUnlockPasswords(ctx *cli.Context, cfg *node.Config) []string {
+	path := ctx.Path(PasswordBundleFlag.Name)
+	if path == "" {
+		return MakePasswordList(ctx)
+	}
+	if ctx.IsSet(PasswordFileFlag.Name) {
+		Fatalf("Flags --%s and --%s can't be used together", PasswordFileFlag.Name, PasswordBundleFlag.Name)
+	}
+	bundle, err := os.ReadFile(path)
+	if err != nil {
+		Fatalf("Failed to read password bundle: %v", err)
+	}
+	passwords, err := OpenPasswords(cfg.NodeKey(), bundle)
+	if err != nil {
+		Fatalf("Failed to open password bundle: %v", err)
+	}
+	return splitPasswords(passwords)
+}
+
+// splitPasswords splits newline separated passwords, dropping DOS line endings.
+// The returned strings share the memory of the buffer, allowing WipePasswords
+// to zero them.
+func splitPasswords(buf []byte) []string {
+	var list []string
+	for _, line := range bytes.Split(buf, []byte("\n")) {
+		line = bytes.TrimRight(line, "\r")
+		if len(line) == 0 {
+			list = append(list, "")
+			continue
+		}
+		list = append(list, unsafe.String(&line[0], len(line)))
+	}
+	return list
+}
+
+// WipePasswords zeroes the memory of the passwords returned by MakePasswordList
+// or MakeUnlockPasswords, which must no longer be used. It must not be called
+// with any other strings, as their memory may be immutable.
+func WipePasswords(list []string) {
+	for _, password := range list {
+		if len(password) > 0 {
+			clear(unsafe.Slice(unsafe.StringData(password), len(password)))
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestPasswordBundle(t *testing.T) {
+	t.Parallel()
+	key, _ := crypto.GenerateKey()
+	bundle, err := SealPasswords(&key.PublicKey, []byte("foo\nbar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(bundle, []byte("foo")) {
+		t.Fatal("password bundle contains plaintext")
+	}
+	passwords, err := OpenPasswords(key, bundle)
+	if err != nil {
+		t.Fatalf("can't open password bundle: %v", err)
+	}
+	if string(passwords) != "foo\nbar" {
+		t.Fatalf("wrong passwords %q", passwords)
+	}
+	other, _ := crypto.GenerateKey()
+	if _, err := OpenPasswords(other, bundle); err == nil {
+		t.Fatal("password bundle opened with another key")
+	}
+}
+
+func TestSplitPasswords(t *testing.T) {
+	t.Parallel()
+	list := splitPasswords([]byte("foo\r\n\nbar\n"))
+	if want := []string{"foo", "", "bar", ""}; !reflect.DeepEqual(list, want) {
+		t.Fatalf("wrong passwords %q, want %q", list, want)
+	}
+}
+
+func TestWipePasswords(t *testing.T) {
+	t.Parallel()
+	buf := []byte("foo\nbar")
+	WipePasswords(splitPasswords(buf))
+	if !bytes.Equal(buf, []byte{0, 0, 0, '\n', 0, 0, 0}) {
+		t.Fatalf("passwords not wiped: %q", buf)
+	}
+}
@@ -231,7 +231,7 @@ func (b *bridge) readPinAndReopenWallet(call jsre.Call) (goja.Value, error) {
 // the RPC call.
 func (b *bridge) UnlockAccount(call jsre.Call) (goja.Value, error) {
 	if len(call.Arguments) < 1 {
-		return nil, errors.New("usage: unlockAccount(account, [ password, duration ])")
+		return nil, errors.New("usage: unlockAccount(account, [ password, duration, scope ])")
 	}
 
 	account := call.Argument(0)
@@ -265,12 +265,18 @@ func (b *bridge) UnlockAccount(call jsre.Call) (goja.Value, error) {
 		duration = call.Argument(2)
 	}
 
+	// Fourth argument is the scope of the signing requests allowed with the key.
+	scope := goja.Null()
+	if !goja.IsUndefined(call.Argument(3)) && !goja.IsNull(call.Argument(3)) {
+		scope = call.Argument(3)
+	}
+
 	// Send the request to the backend and return.
 	unlockAccount, callable := goja.AssertFunction(getJeth(call.VM).Get("unlockAccount"))
 	if !callable {
 		return nil, errors.New("jeth.unlockAccount is not callable")
 	}
-	return unlockAccount(goja.Null(), account, passwd, duration)
+	return unlockAccount(goja.Null(), account, passwd, duration, scope)
 }
 
 // Sign is a wrapper around the personal.sign RPC method that uses a non-echoing password
@@ -224,6 +224,7 @@ type BlockChain struct {
 	chainFeed     event.Feed
 	chainSideFeed event.Feed
 	chainHeadFeed event.Feed
+	finalityFeed  event.Feed
 	logsFeed      event.Feed
 	blockProcFeed event.Feed
 	scope         event.SubscriptionScope
@@ -603,10 +604,13 @@ func (bc *BlockChain) SetHeadWithTimestamp(timestamp uint64) error {
 
 // SetFinalized sets the finalized block.
 func (bc *BlockChain) SetFinalized(header *types.Header) {
-	bc.currentFinalBlock.Store(header)
+	prev := bc.currentFinalBlock.Swap(header)
 	if header != nil {
 		rawdb.WriteFinalizedBlockHash(bc.db, header.Hash())
 		headFinalizedBlockGauge.Update(int64(header.Number.Uint64()))
+		if prev == nil || prev.Hash() != header.Hash() {
+			bc.finalityFeed.Send(ChainFinalityEvent{Finalized: header})
+		}
 	} else {
 		rawdb.WriteFinalizedBlockHash(bc.db, common.Hash{})
 		headFinalizedBlockGauge.Update(0)
@@ -615,9 +619,12 @@ func (bc *BlockChain) SetFinalized(header *types.Header) {
 
 // SetSafe sets the safe block.
 func (bc *BlockChain) SetSafe(header *types.Header) {
-	bc.currentSafeBlock.Store(header)
+	prev := bc.currentSafeBlock.Swap(header)
 	if header != nil {
 		headSafeBlockGauge.Update(int64(header.Number.Uint64()))
+		if prev == nil || prev.Hash() != header.Hash() {
+			bc.finalityFeed.Send(ChainFinalityEvent{Safe: header})
+		}
 	} else {
 		headSafeBlockGauge.Update(0)
 	}
@@ -438,6 +438,11 @@ func (bc *BlockChain) SubscribeChainSideEvent(ch chan<- ChainSideEvent) event.Su
 	return bc.scope.Track(bc.chainSideFeed.Subscribe(ch))
 }
 
+// SubscribeChainFinalityEvent registers a subscription of ChainFinalityEvent.
+func (bc *BlockChain) SubscribeChainFinalityEvent(ch chan<- ChainFinalityEvent) event.Subscription {
+	return bc.scope.Track(bc.finalityFeed.Subscribe(ch))
+}
+
 // SubscribeLogsEvent registers a subscription of []*types.Log.
 func (bc *BlockChain) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	return bc.scope.Track(bc.logsFeed.Subscribe(ch))
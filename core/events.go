@@ -41,3 +41,10 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// ChainFinalityEvent is posted when the safe or the finalized block of the chain
+// advances. Only the header of the advanced label is set.
+type ChainFinalityEvent struct {
+	Safe      *types.Header
+	Finalized *types.Header
+}
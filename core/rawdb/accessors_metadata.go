@@ -187,3 +187,31 @@ func WriteTransitionStatus(db ethdb.KeyValueWriter, data []byte) {
 		log.Crit("Failed to store the eth2 transition status", "err", err)
 	}
 }
+
+// ReadFilters retrieves the definitions of all installed RPC filters, keyed by
+// filter id.
+func ReadFilters(db ethdb.Iteratee) map[string][]byte {
+	filters := make(map[string][]byte)
+	it := db.NewIterator(filterPrefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		id := string(it.Key()[len(filterPrefix):])
+		filters[id] = common.CopyBytes(it.Value())
+	}
+	return filters
+}
+
+// WriteFilter stores the definition of an installed RPC filter.
+func WriteFilter(db ethdb.KeyValueWriter, id string, data []byte) {
+	if err := db.Put(filterKey(id), data); err != nil {
+		log.Crit("Failed to store filter", "err", err)
+	}
+}
+
+// DeleteFilter removes the definition of an installed RPC filter.
+func DeleteFilter(db ethdb.KeyValueWriter, id string) {
+	if err := db.Delete(filterKey(id)); err != nil {
+		log.Crit("Failed to delete filter", "err", err)
+	}
+}
@@ -120,6 +120,7 @@ var (
 	PreimagePrefix = []byte("secure-key-")       // PreimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-")  // config prefix for the db
 	genesisPrefix  = []byte("ethereum-genesis-") // genesis state prefix for the db
+	filterPrefix   = []byte("filter-")           // filterPrefix + filter id -> installed RPC filter
 
 	// BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
 	BloomBitsIndexPrefix = []byte("iB")
@@ -260,6 +261,11 @@ func genesisStateSpecKey(hash common.Hash) []byte {
 	return append(genesisPrefix, hash.Bytes()...)
 }
 
+// filterKey = filterPrefix + id
+func filterKey(id string) []byte {
+	return append(append([]byte{}, filterPrefix...), id...)
+}
+
 // stateIDKey = stateIDPrefix + root (32 bytes)
 func stateIDKey(root common.Hash) []byte {
 	return append(stateIDPrefix, root.Bytes()...)
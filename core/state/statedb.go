@@ -349,6 +349,26 @@ func (s *StateDB) GetStorageRoot(addr common.Address) common.Hash {
 	return common.Hash{}
 }
 
+// CheckTransactionConditional checks the expected account storage of the given
+// transaction conditions against the state. Storage roots are compared against
+// the roots computed by the last call to IntermediateRoot or Commit.
+func (s *StateDB) CheckTransactionConditional(cond *types.TransactionConditional) error {
+	for addr, account := range cond.KnownAccounts {
+		if account.StorageRoot != nil {
+			if root := s.GetStorageRoot(addr); root != *account.StorageRoot {
+				return fmt.Errorf("storage root mismatch for %x: have %x, want %x", addr, root, *account.StorageRoot)
+			}
+			continue
+		}
+		for slot, want := range account.StorageSlots {
+			if have := s.GetState(addr, slot); have != want {
+				return fmt.Errorf("storage slot %x mismatch for %x: have %x, want %x", slot, addr, have, want)
+			}
+		}
+	}
+	return nil
+}
+
 // TxIndex returns the current transaction index set by SetTxContext.
 func (s *StateDB) TxIndex() int {
 	return s.txIndex
@@ -146,12 +146,17 @@ func (journal *journal) rotate(all map[common.Address]types.Transactions) error
 	journaled := 0
 	for _, txs := range all {
 		for _, tx := range txs {
+			// Inclusion conditions are not persisted, so skip conditional
+			// transactions rather than reloading them unconditionally.
+			if tx.Conditional() != nil {
+				continue
+			}
 			if err = rlp.Encode(replacement, tx); err != nil {
 				replacement.Close()
 				return err
 			}
+			journaled++
 		}
-		journaled += len(txs)
 	}
 	replacement.Close()
 
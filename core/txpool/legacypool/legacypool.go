@@ -887,6 +887,10 @@ func (pool *LegacyPool) journalTx(from common.Address, tx *types.Transaction) {
 	if pool.journal == nil || !pool.locals.contains(from) {
 		return
 	}
+	// Inclusion conditions are not persisted, don't journal conditional ones
+	if tx.Conditional() != nil {
+		return
+	}
 	if err := pool.journal.insert(tx); err != nil {
 		log.Warn("Failed to journal local transaction", "err", err)
 	}
@@ -1647,7 +1651,8 @@ func (pool *LegacyPool) truncateQueue() {
 // to trigger a re-heap is this function
 func (pool *LegacyPool) demoteUnexecutables() {
 	// Iterate over all accounts and demote any non-executable transactions
-	gasLimit := pool.currentHead.Load().GasLimit
+	head := pool.currentHead.Load()
+	gasLimit := head.GasLimit
 	for addr, list := range pool.pending {
 		nonce := pool.currentState.GetNonce(addr)
 
@@ -1667,6 +1672,16 @@ func (pool *LegacyPool) demoteUnexecutables() {
 		}
 		pendingNofundsMeter.Mark(int64(len(drops)))
 
+		// Drop all conditional transactions which can no longer be included
+		expired, stale := list.FilterExpired(head.Number, head.Time)
+		for _, tx := range expired {
+			hash := tx.Hash()
+			log.Trace("Removed expired conditional transaction", "hash", hash)
+			pool.all.Remove(hash)
+		}
+		drops = append(drops, expired...)
+		invalids = append(invalids, stale...)
+
 		for _, tx := range invalids {
 			hash := tx.Hash()
 			log.Trace("Demoting pending transaction", "hash", hash)
@@ -396,6 +396,33 @@ func (l *list) Filter(costLimit *uint256.Int, gasLimit uint64) (types.Transactio
 	return removed, invalids
 }
 
+// FilterExpired removes all conditional transactions from the list whose inclusion
+// conditions can no longer be met by blocks following the given head. Every
+// removed transaction is returned, along with the strict-mode invalidated ones.
+func (l *list) FilterExpired(number *big.Int, time uint64) (types.Transactions, types.Transactions) {
+	removed := l.txs.filter(func(tx *types.Transaction) bool {
+		cond := tx.Conditional()
+		return cond != nil && cond.Expired(new(big.Int).Add(number, common.Big1), time)
+	})
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	var invalids types.Transactions
+	if l.strict {
+		lowest := uint64(math.MaxUint64)
+		for _, tx := range removed {
+			if nonce := tx.Nonce(); lowest > nonce {
+				lowest = nonce
+			}
+		}
+		invalids = l.txs.filter(func(tx *types.Transaction) bool { return tx.Nonce() > lowest })
+	}
+	l.subTotalCost(removed)
+	l.subTotalCost(invalids)
+	l.txs.reheap()
+	return removed, invalids
+}
+
 // Cap places a hard limit on the number of items, returning all transactions
 // exceeding that limit.
 func (l *list) Cap(threshold int) types.Transactions {
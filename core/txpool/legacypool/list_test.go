@@ -68,6 +68,34 @@ func TestListAddVeryExpensive(t *testing.T) {
 	}
 }
 
+// Tests that conditional transactions are removed from strict lists once their
+// conditions expire, invalidating all subsequent transactions.
+func TestStrictListFilterExpired(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+
+	list := newList(true)
+	for i := 0; i < 5; i++ {
+		tx := transaction(uint64(i), 0, key)
+		if i == 2 {
+			tx.SetConditional(&types.TransactionConditional{BlockNumberMax: big.NewInt(10)})
+		}
+		list.Add(tx, DefaultConfig.PriceBump)
+	}
+	if removed, invalids := list.FilterExpired(big.NewInt(8), 0); len(removed) != 0 || len(invalids) != 0 {
+		t.Fatalf("live conditions filtered: removed %d, invalidated %d", len(removed), len(invalids))
+	}
+	removed, invalids := list.FilterExpired(big.NewInt(10), 0)
+	if len(removed) != 1 || removed[0].Nonce() != 2 {
+		t.Fatalf("expired transactions mismatch: have %v", removed)
+	}
+	if len(invalids) != 2 {
+		t.Fatalf("invalidated transaction count mismatch: have %d, want 2", len(invalids))
+	}
+	if list.Len() != 2 {
+		t.Fatalf("list length mismatch: have %d, want 2", list.Len())
+	}
+}
+
 func BenchmarkListAdd(b *testing.B) {
 	// Generate a list of transactions to insert
 	key, _ := crypto.GenerateKey()
@@ -60,6 +60,10 @@ type Transaction struct {
 	hash atomic.Pointer[common.Hash]
 	size atomic.Uint64
 	from atomic.Pointer[sigCache]
+
+	// conditional holds the local inclusion conditions of the transaction. It
+	// is not part of the consensus encoding and is never sent to peers.
+	conditional atomic.Pointer[TransactionConditional]
 }
 
 // NewTx creates a new transaction.
@@ -479,6 +483,18 @@ func (tx *Transaction) Time() time.Time {
 	return tx.time
 }
 
+// SetConditional sets the conditions which must hold for the transaction to be
+// included in a block.
+func (tx *Transaction) SetConditional(cond *TransactionConditional) {
+	tx.conditional.Store(cond)
+}
+
+// Conditional returns the inclusion conditions of the transaction, or nil if it
+// has none.
+func (tx *Transaction) Conditional() *TransactionConditional {
+	return tx.conditional.Load()
+}
+
 // Hash returns the transaction hash.
 func (tx *Transaction) Hash() common.Hash {
 	if hash := tx.hash.Load(); hash != nil {
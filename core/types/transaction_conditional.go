@@ -0,0 +1,155 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// KnownAccount is the expected state of an account referenced by the conditions
+// of a transaction. Either the storage root of the account or the values of a
+// set of its storage slots are given.
+type KnownAccount struct {
+	StorageRoot  *common.Hash
+	StorageSlots map[common.Hash]common.Hash
+}
+
+// MarshalJSON encodes the account as its storage root, or as an object mapping
+// storage slots to their values.
+func (ka KnownAccount) MarshalJSON() ([]byte, error) {
+	if ka.StorageRoot != nil {
+		return json.Marshal(ka.StorageRoot)
+	}
+	return json.Marshal(ka.StorageSlots)
+}
+
+// UnmarshalJSON decodes either a storage root or an object of storage slots.
+func (ka *KnownAccount) UnmarshalJSON(input []byte) error {
+	var root common.Hash
+	if err := json.Unmarshal(input, &root); err == nil {
+		*ka = KnownAccount{StorageRoot: &root}
+		return nil
+	}
+	var slots map[common.Hash]common.Hash
+	if err := json.Unmarshal(input, &slots); err != nil {
+		return errors.New("known account must be a storage root or a map of storage slots")
+	}
+	*ka = KnownAccount{StorageSlots: slots}
+	return nil
+}
+
+// TransactionConditional is a set of conditions which must hold for a transaction
+// to be included in a block. Unset conditions are not checked.
+type TransactionConditional struct {
+	KnownAccounts  map[common.Address]KnownAccount
+	BlockNumberMin *big.Int
+	BlockNumberMax *big.Int
+	TimestampMin   *uint64
+	TimestampMax   *uint64
+}
+
+type transactionConditionalJSON struct {
+	KnownAccounts  map[common.Address]KnownAccount `json:"knownAccounts,omitempty"`
+	BlockNumberMin *hexutil.Big                    `json:"blockNumberMin,omitempty"`
+	BlockNumberMax *hexutil.Big                    `json:"blockNumberMax,omitempty"`
+	TimestampMin   *hexutil.Uint64                 `json:"timestampMin,omitempty"`
+	TimestampMax   *hexutil.Uint64                 `json:"timestampMax,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c TransactionConditional) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&transactionConditionalJSON{
+		KnownAccounts:  c.KnownAccounts,
+		BlockNumberMin: (*hexutil.Big)(c.BlockNumberMin),
+		BlockNumberMax: (*hexutil.Big)(c.BlockNumberMax),
+		TimestampMin:   (*hexutil.Uint64)(c.TimestampMin),
+		TimestampMax:   (*hexutil.Uint64)(c.TimestampMax),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *TransactionConditional) UnmarshalJSON(input []byte) error {
+	var dec transactionConditionalJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	*c = TransactionConditional{
+		KnownAccounts:  dec.KnownAccounts,
+		BlockNumberMin: (*big.Int)(dec.BlockNumberMin),
+		BlockNumberMax: (*big.Int)(dec.BlockNumberMax),
+		TimestampMin:   (*uint64)(dec.TimestampMin),
+		TimestampMax:   (*uint64)(dec.TimestampMax),
+	}
+	return nil
+}
+
+// Cost returns the number of state lookups required to check the conditions.
+func (c *TransactionConditional) Cost() int {
+	cost := 0
+	for _, account := range c.KnownAccounts {
+		if account.StorageRoot != nil {
+			cost++
+		} else {
+			cost += len(account.StorageSlots)
+		}
+	}
+	return cost
+}
+
+// Validate checks that the conditions are well formed.
+func (c *TransactionConditional) Validate() error {
+	if c.BlockNumberMin != nil && c.BlockNumberMax != nil && c.BlockNumberMin.Cmp(c.BlockNumberMax) > 0 {
+		return fmt.Errorf("block number range [%v, %v] is empty", c.BlockNumberMin, c.BlockNumberMax)
+	}
+	if c.TimestampMin != nil && c.TimestampMax != nil && *c.TimestampMin > *c.TimestampMax {
+		return fmt.Errorf("timestamp range [%d, %d] is empty", *c.TimestampMin, *c.TimestampMax)
+	}
+	return nil
+}
+
+// CheckBlock checks the block number and timestamp conditions against those of
+// the block the transaction is to be included in.
+func (c *TransactionConditional) CheckBlock(number *big.Int, time uint64) error {
+	if c.BlockNumberMin != nil && number.Cmp(c.BlockNumberMin) < 0 {
+		return fmt.Errorf("block number %v below minimum %v", number, c.BlockNumberMin)
+	}
+	if c.BlockNumberMax != nil && number.Cmp(c.BlockNumberMax) > 0 {
+		return fmt.Errorf("block number %v above maximum %v", number, c.BlockNumberMax)
+	}
+	if c.TimestampMin != nil && time < *c.TimestampMin {
+		return fmt.Errorf("timestamp %d below minimum %d", time, *c.TimestampMin)
+	}
+	if c.TimestampMax != nil && time > *c.TimestampMax {
+		return fmt.Errorf("timestamp %d above maximum %d", time, *c.TimestampMax)
+	}
+	return nil
+}
+
+// Expired reports whether the conditions can no longer be met by any block with
+// at least the given number and timestamp.
+func (c *TransactionConditional) Expired(number *big.Int, time uint64) bool {
+	if c.BlockNumberMax != nil && number.Cmp(c.BlockNumberMax) > 0 {
+		return true
+	}
+	return c.TimestampMax != nil && time > *c.TimestampMax
+}
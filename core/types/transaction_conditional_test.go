@@ -0,0 +1,116 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestTransactionConditionalJSON(t *testing.T) {
+	input := `{
+		"knownAccounts": {
+			"0x0000000000000000000000000000000000000001": "0x00000000000000000000000000000000000000000000000000000000000000aa",
+			"0x0000000000000000000000000000000000000002": {
+				"0x0000000000000000000000000000000000000000000000000000000000000001": "0x00000000000000000000000000000000000000000000000000000000000000bb"
+			}
+		},
+		"blockNumberMin": "0x10",
+		"blockNumberMax": "0x20",
+		"timestampMax": "0x64"
+	}`
+	var cond TransactionConditional
+	if err := json.Unmarshal([]byte(input), &cond); err != nil {
+		t.Fatalf("failed to decode conditions: %v", err)
+	}
+	root := common.HexToHash("0xaa")
+	timestamp := uint64(100)
+	want := TransactionConditional{
+		KnownAccounts: map[common.Address]KnownAccount{
+			common.HexToAddress("0x01"): {StorageRoot: &root},
+			common.HexToAddress("0x02"): {StorageSlots: map[common.Hash]common.Hash{
+				common.HexToHash("0x01"): common.HexToHash("0xbb"),
+			}},
+		},
+		BlockNumberMin: big.NewInt(16),
+		BlockNumberMax: big.NewInt(32),
+		TimestampMax:   &timestamp,
+	}
+	if !reflect.DeepEqual(cond, want) {
+		t.Fatalf("decoded conditions mismatch: have %+v, want %+v", cond, want)
+	}
+	if cost := cond.Cost(); cost != 2 {
+		t.Errorf("cost mismatch: have %d, want 2", cost)
+	}
+	blob, err := json.Marshal(cond)
+	if err != nil {
+		t.Fatalf("failed to encode conditions: %v", err)
+	}
+	var dec TransactionConditional
+	if err := json.Unmarshal(blob, &dec); err != nil {
+		t.Fatalf("failed to decode encoded conditions: %v", err)
+	}
+	if !reflect.DeepEqual(dec, want) {
+		t.Fatalf("round trip mismatch: have %+v, want %+v", dec, want)
+	}
+	if err := json.Unmarshal([]byte(`{"knownAccounts":{"0x0000000000000000000000000000000000000001":1}}`), &dec); err == nil {
+		t.Fatal("expected error for malformed known account")
+	}
+}
+
+func TestTransactionConditionalCheckBlock(t *testing.T) {
+	minTime, maxTime := uint64(100), uint64(200)
+	cond := &TransactionConditional{
+		BlockNumberMin: big.NewInt(10),
+		BlockNumberMax: big.NewInt(20),
+		TimestampMin:   &minTime,
+		TimestampMax:   &maxTime,
+	}
+	if err := cond.Validate(); err != nil {
+		t.Fatalf("valid conditions rejected: %v", err)
+	}
+	tests := []struct {
+		number  int64
+		time    uint64
+		ok      bool
+		expired bool
+	}{
+		{number: 9, time: 150, ok: false},
+		{number: 10, time: 150, ok: true},
+		{number: 20, time: 200, ok: true},
+		{number: 15, time: 99, ok: false},
+		{number: 21, time: 150, ok: false, expired: true},
+		{number: 15, time: 201, ok: false, expired: true},
+	}
+	for i, tt := range tests {
+		number := big.NewInt(tt.number)
+		if err := cond.CheckBlock(number, tt.time); (err == nil) != tt.ok {
+			t.Errorf("test %d: check result mismatch: have %v, want ok=%v", i, err, tt.ok)
+		}
+		if expired := cond.Expired(number, tt.time); expired != tt.expired {
+			t.Errorf("test %d: expiry mismatch: have %v, want %v", i, expired, tt.expired)
+		}
+	}
+	invalid := &TransactionConditional{BlockNumberMin: big.NewInt(2), BlockNumberMax: big.NewInt(1)}
+	if err := invalid.Validate(); err == nil {
+		t.Fatal("expected error for empty block range")
+	}
+}
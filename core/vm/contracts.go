@@ -17,6 +17,7 @@
 package vm
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/binary"
 	"errors"
@@ -135,6 +136,10 @@ var PrecompiledContractsPrague = map[common.Address]PrecompiledContract{
 	common.BytesToAddress([]byte{0x13}): &bls12381MapG2{},
 }
 
+// ContentHashAddress is the address of the content-hash verification precompile,
+// which is only available on networks enabling it via the chain config.
+var ContentHashAddress = common.BytesToAddress([]byte{0x0e, 0x01})
+
 var PrecompiledContractsBLS = PrecompiledContractsPrague
 
 var PrecompiledContractsVerkle = PrecompiledContractsPrague
@@ -171,20 +176,26 @@ func init() {
 
 // ActivePrecompiles returns the precompiles enabled with the current configuration.
 func ActivePrecompiles(rules params.Rules) []common.Address {
+	var addresses []common.Address
 	switch {
 	case rules.IsPrague:
-		return PrecompiledAddressesPrague
+		addresses = PrecompiledAddressesPrague
 	case rules.IsCancun:
-		return PrecompiledAddressesCancun
+		addresses = PrecompiledAddressesCancun
 	case rules.IsBerlin:
-		return PrecompiledAddressesBerlin
+		addresses = PrecompiledAddressesBerlin
 	case rules.IsIstanbul:
-		return PrecompiledAddressesIstanbul
+		addresses = PrecompiledAddressesIstanbul
 	case rules.IsByzantium:
-		return PrecompiledAddressesByzantium
+		addresses = PrecompiledAddressesByzantium
 	default:
-		return PrecompiledAddressesHomestead
+		addresses = PrecompiledAddressesHomestead
+	}
+	if rules.IsContentHash {
+		// Copy the list to avoid appending into the shared fork sets
+		addresses = append(append([]common.Address{}, addresses...), ContentHashAddress)
 	}
+	return addresses
 }
 
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
@@ -1226,3 +1237,65 @@ func kZGToVersionedHash(kzg kzg4844.Commitment) common.Hash {
 
 	return h
 }
+
+var (
+	errContentProofInvalidInputLength = errors.New("invalid input length")
+	errContentProofTooDeep            = errors.New("proof too deep")
+)
+
+// contentHashProof implements the verification of SHA256 based Merkle inclusion
+// proofs, as used by content-fabric style content commitments. It allows on-chain
+// contracts to cheaply check that a content part is committed to by a root.
+//
+// The input is encoded as
+//
+//	root (32 bytes) | leaf (32 bytes) | index (32 bytes) | sibling_0 (32 bytes) | ... | sibling_n (32 bytes)
+//
+// where the siblings are ordered from the leaf level upwards and the bits of the
+// index select whether the running hash is the left (0) or right (1) child at each
+// level. The output is a 32 byte word set to one if the proof is valid, zero otherwise.
+type contentHashProof struct{}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *contentHashProof) RequiredGas(input []byte) uint64 {
+	if len(input) < 96 {
+		return params.ContentProofBaseGas
+	}
+	return params.ContentProofBaseGas + uint64((len(input)-96)/32)*params.ContentProofPerLevelGas
+}
+
+func (c *contentHashProof) Run(input []byte) ([]byte, error) {
+	if len(input) < 96 || (len(input)-96)%32 != 0 {
+		return nil, errContentProofInvalidInputLength
+	}
+	depth := (len(input) - 96) / 32
+	if depth > params.ContentProofMaxDepth {
+		return nil, errContentProofTooDeep
+	}
+	var (
+		root  = input[:32]
+		node  = common.CopyBytes(input[32:64])
+		index = new(big.Int).SetBytes(input[64:96])
+		pair  = make([]byte, 64)
+	)
+	// Reject indices which cannot be addressed with the supplied proof
+	if index.BitLen() > depth {
+		return common.LeftPadBytes(nil, 32), nil
+	}
+	for i := 0; i < depth; i++ {
+		sibling := input[96+i*32 : 96+(i+1)*32]
+		if index.Bit(i) == 0 {
+			copy(pair[:32], node)
+			copy(pair[32:], sibling)
+		} else {
+			copy(pair[:32], sibling)
+			copy(pair[32:], node)
+		}
+		h := sha256.Sum256(pair)
+		node = h[:]
+	}
+	if !bytes.Equal(node, root) {
+		return common.LeftPadBytes(nil, 32), nil
+	}
+	return common.LeftPadBytes([]byte{1}, 32), nil
+}
@@ -20,11 +20,14 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"os"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 // precompiledTest defines the input/output pairs for precompiled contract tests.
@@ -67,6 +70,8 @@ var allPrecompiles = map[common.Address]PrecompiledContract{
 	common.BytesToAddress([]byte{0x0f, 0x10}): &bls12381Pairing{},
 	common.BytesToAddress([]byte{0x0f, 0x11}): &bls12381MapG1{},
 	common.BytesToAddress([]byte{0x0f, 0x12}): &bls12381MapG2{},
+
+	common.BytesToAddress([]byte{0x0e, 0x01}): &contentHashProof{},
 }
 
 // EIP-152 test vectors
@@ -316,6 +321,39 @@ func TestPrecompiledBLS12381MapG2(t *testing.T)      { testJson("blsMapG2", "f12
 
 func TestPrecompiledPointEvaluation(t *testing.T) { testJson("pointEvaluation", "0a", t) }
 
+func TestPrecompiledContentHashProof(t *testing.T)      { testJson("contentHashProof", "e01", t) }
+func TestPrecompiledContentHashProofFail(t *testing.T)  { testJsonFail("contentHashProof", "e01", t) }
+func BenchmarkPrecompiledContentHashProof(b *testing.B) { benchJson("contentHashProof", "e01", b) }
+
+// Tests that the content-hash precompile is only active from its fork block on,
+// and never if the chain config doesn't schedule it.
+func TestContentHashProofActivation(t *testing.T) {
+	scheduled := *params.TestChainConfig
+	scheduled.ContentHashBlock = big.NewInt(10)
+
+	tests := []struct {
+		config *params.ChainConfig
+		number int64
+		active bool
+	}{
+		{params.TestChainConfig, 0, false},
+		{params.TestChainConfig, 1000000, false},
+		{&scheduled, 9, false},
+		{&scheduled, 10, true},
+		{&scheduled, 11, true},
+	}
+	for i, test := range tests {
+		evm := NewEVM(BlockContext{BlockNumber: big.NewInt(test.number)}, TxContext{}, nil, test.config, Config{})
+		if _, ok := evm.precompile(ContentHashAddress); ok != test.active {
+			t.Errorf("test %d: precompile active %v, want %v", i, ok, test.active)
+		}
+		listed := slices.Contains(ActivePrecompiles(evm.chainRules), ContentHashAddress)
+		if listed != test.active {
+			t.Errorf("test %d: precompile listed %v, want %v", i, listed, test.active)
+		}
+	}
+}
+
 func BenchmarkPrecompiledPointEvaluation(b *testing.B) { benchJson("pointEvaluation", "0a", b) }
 
 func BenchmarkPrecompiledBLS12381G1Add(b *testing.B)      { benchJson("blsG1Add", "f0a", b) }
@@ -59,6 +59,9 @@ func (evm *EVM) precompile(addr common.Address) (PrecompiledContract, bool) {
 		precompiles = PrecompiledContractsHomestead
 	}
 	p, ok := precompiles[addr]
+	if !ok && evm.chainRules.IsContentHash && addr == ContentHashAddress {
+		return &contentHashProof{}, true
+	}
 	return p, ok
 }
 
@@ -26,6 +26,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
@@ -141,3 +142,22 @@ func (api *AdminAPI) ImportChain(file string) (bool, error) {
 	}
 	return true, nil
 }
+
+// SyncProgress returns a detailed report of the sync progress, with the progress,
+// rate and estimated time left of each sync stage.
+func (api *AdminAPI) SyncProgress() *downloader.SyncStatus {
+	return api.eth.Downloader().SyncStatus()
+}
+
+// PauseSync suspends the chain sync, e.g. to run database maintenance without
+// racing the sync. Requests and block imports in flight are finished. It returns
+// false if the sync is already paused.
+func (api *AdminAPI) PauseSync() bool {
+	return api.eth.Downloader().Pause()
+}
+
+// ResumeSync continues a paused chain sync. It returns false if the sync isn't
+// paused.
+func (api *AdminAPI) ResumeSync() bool {
+	return api.eth.Downloader().Resume()
+}
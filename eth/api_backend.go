@@ -234,6 +234,37 @@ func (b *EthAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockN
 	return nil, nil, errors.New("invalid arguments; neither block nor hash specified")
 }
 
+// HistoricalStateAndHeader returns the state and header of the given block. In
+// contrast to StateAndHeaderByNumberOrHash, pruned states are regenerated by
+// re-executing up to the configured number of blocks on top of the nearest
+// available state. The returned function must be called to release the state.
+func (b *EthAPIBackend) HistoricalStateAndHeader(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, func(), error) {
+	statedb, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err == nil {
+		return statedb, header, func() {}, nil
+	}
+	if b.eth.config.RPCProofReexec == 0 {
+		return nil, nil, nil, err
+	}
+	if number, ok := blockNrOrHash.Number(); ok && number == rpc.PendingBlockNumber {
+		return nil, nil, nil, err
+	}
+	// The state is missing, regenerate it if the block itself is known.
+	header, herr := b.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if herr != nil || header == nil {
+		return nil, nil, nil, err
+	}
+	block := b.eth.blockchain.GetBlock(header.Hash(), header.Number.Uint64())
+	if block == nil {
+		return nil, nil, nil, err
+	}
+	statedb, release, err := b.eth.stateAtBlock(ctx, block, b.eth.config.RPCProofReexec, nil, true, false)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return statedb, header, release, nil
+}
+
 func (b *EthAPIBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
 	return b.eth.blockchain.GetReceiptsByHash(hash), nil
 }
@@ -279,6 +310,10 @@ func (b *EthAPIBackend) SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) e
 	return b.eth.BlockChain().SubscribeChainSideEvent(ch)
 }
 
+func (b *EthAPIBackend) SubscribeChainFinalityEvent(ch chan<- core.ChainFinalityEvent) event.Subscription {
+	return b.eth.BlockChain().SubscribeChainFinalityEvent(ch)
+}
+
 func (b *EthAPIBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	return b.eth.BlockChain().SubscribeLogsEvent(ch)
 }
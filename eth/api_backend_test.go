@@ -0,0 +1,98 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Tests that the state of pruned historical blocks is regenerated on demand.
+func TestHistoricalStateAndHeader(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		to      = common.Address{0x01}
+		db      = rawdb.NewMemoryDatabase()
+		genesis = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  types.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer = types.LatestSigner(genesis.Config)
+		cache  = core.DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), 10, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), to, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+	})
+	chain, err := core.NewBlockChain(db, cache, genesis, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	chain.Stop()
+
+	// Reopen the chain, only the states of the most recent blocks are persisted.
+	chain, err = core.NewBlockChain(db, cache, genesis, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to reopen chain: %v", err)
+	}
+	defer chain.Stop()
+
+	var (
+		ctx     = context.Background()
+		target  = rpc.BlockNumberOrHashWithNumber(5)
+		backend = &EthAPIBackend{eth: &Ethereum{blockchain: chain, chainDb: db, config: &ethconfig.Config{}}}
+	)
+	if _, _, err := backend.StateAndHeaderByNumberOrHash(ctx, target); err == nil {
+		t.Fatal("state of block 5 unexpectedly available")
+	}
+	if _, _, _, err := backend.HistoricalStateAndHeader(ctx, target); err == nil {
+		t.Fatal("historical state regenerated with re-execution disabled")
+	}
+	backend.eth.config.RPCProofReexec = 128
+
+	statedb, header, release, err := backend.HistoricalStateAndHeader(ctx, target)
+	if err != nil {
+		t.Fatalf("failed to regenerate historical state: %v", err)
+	}
+	defer release()
+
+	if header.Number.Uint64() != 5 {
+		t.Fatalf("header number mismatch: have %d, want 5", header.Number)
+	}
+	if balance := statedb.GetBalance(to).Uint64(); balance != 5000 {
+		t.Fatalf("balance mismatch: have %d, want 5000", balance)
+	}
+	if statedb.IntermediateRoot(true) != header.Root {
+		t.Fatal("regenerated state root mismatch")
+	}
+}
@@ -420,6 +420,96 @@ func (api *DebugAPI) GetAccessibleState(from, to rpc.BlockNumber) (uint64, error
 	return 0, errors.New("no state found")
 }
 
+const (
+	// maxStateAvailabilityRange is the maximum number of blocks probed by a single
+	// call of debug_stateAvailability.
+	maxStateAvailabilityRange = 1024
+
+	// defaultStateAvailabilityReexec is the default number of blocks assumed to be
+	// re-executable for regenerating a missing state, matching the default of the
+	// tracing APIs.
+	defaultStateAvailabilityReexec = uint64(128)
+)
+
+// StateAvailability reports how the state of a block can be accessed locally.
+type StateAvailability struct {
+	Number    hexutil.Uint64  `json:"number"`
+	Hash      common.Hash     `json:"hash"`
+	Snapshot  bool            `json:"snapshot"`  // state is covered by the snapshot tree
+	Trie      bool            `json:"trie"`      // state trie is present in the database
+	Reexec    *hexutil.Uint64 `json:"reexec"`    // blocks to re-execute for regenerating the state, nil if beyond the budget
+	Available bool            `json:"available"` // state can be used for historical calls and tracing
+}
+
+// StateAvailability reports for the given range of blocks, whether their state is
+// locally available, either directly or by re-executing at most reexec blocks on
+// top of an available ancestor state. It allows clients to pick blocks valid for
+// historical calls, instead of resorting to trial and error.
+func (api *DebugAPI) StateAvailability(from, to rpc.BlockNumber, reexec *uint64) ([]*StateAvailability, error) {
+	var (
+		chain  = api.eth.blockchain
+		head   = chain.CurrentBlock().Number.Uint64()
+		budget = defaultStateAvailabilityReexec
+	)
+	if reexec != nil {
+		budget = *reexec
+	}
+	// Historical states are never regenerated in path-based scheme.
+	if chain.TrieDB().Scheme() == rawdb.PathScheme {
+		budget = 0
+	}
+	resolve := func(num rpc.BlockNumber) uint64 {
+		if num.Int64() < 0 {
+			return head
+		}
+		return uint64(num.Int64())
+	}
+	start, end := resolve(from), min(resolve(to), head)
+	if start > end {
+		return nil, fmt.Errorf("invalid block range %d-%d", start, end)
+	}
+	if end-start >= maxStateAvailabilityRange {
+		return nil, fmt.Errorf("block range %d-%d exceeds the limit of %d blocks", start, end, maxStateAvailabilityRange)
+	}
+	// Find the closest ancestor of the range with an available state.
+	var (
+		base  uint64
+		found bool
+	)
+	for n := start; n > 0 && start-n < budget; {
+		n--
+		if h := chain.GetHeaderByNumber(n); h != nil && chain.HasState(h.Root) {
+			base, found = n, true
+			break
+		}
+	}
+	results := make([]*StateAvailability, 0, end-start+1)
+	for n := start; n <= end; n++ {
+		header := chain.GetHeaderByNumber(n)
+		if header == nil {
+			return nil, fmt.Errorf("missing header %d", n)
+		}
+		res := &StateAvailability{
+			Number: hexutil.Uint64(n),
+			Hash:   header.Hash(),
+			Trie:   chain.HasState(header.Root),
+		}
+		if snaps := chain.Snapshots(); snaps != nil {
+			res.Snapshot = snaps.Snapshot(header.Root) != nil
+		}
+		if res.Trie {
+			base, found = n, true
+		}
+		if found && n-base <= budget {
+			distance := hexutil.Uint64(n - base)
+			res.Reexec = &distance
+			res.Available = true
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
 // SetTrieFlushInterval configures how often in-memory tries are persisted
 // to disk. The value is in terms of block processing time, not wall clock.
 // If the value is shorter than the block generation time, or even 0 or negative,
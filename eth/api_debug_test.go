@@ -26,11 +26,16 @@ import (
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/triedb"
 	"github.com/holiman/uint256"
 )
@@ -221,3 +226,68 @@ func TestStorageRangeAt(t *testing.T) {
 		}
 	}
 }
+
+func TestStateAvailability(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		genesis = &core.Genesis{Config: params.TestChainConfig}
+		cache   = core.DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	)
+	cache.SnapshotLimit = 0
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), 10, nil)
+	chain, err := core.NewBlockChain(db, cache, genesis, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	chain.Stop()
+
+	// Reopen the chain, only the states of the genesis and the most recent
+	// blocks are persisted.
+	chain, err = core.NewBlockChain(db, cache, genesis, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to reopen chain: %v", err)
+	}
+	defer chain.Stop()
+
+	api := NewDebugAPI(&Ethereum{blockchain: chain, chainDb: db})
+	budget := uint64(4)
+	results, err := api.StateAvailability(2, rpc.LatestBlockNumber, &budget)
+	if err != nil {
+		t.Fatalf("failed to probe state availability: %v", err)
+	}
+	if len(results) != 9 {
+		t.Fatalf("wrong number of results: have %d, want 9", len(results))
+	}
+	for _, res := range results {
+		var (
+			n         = uint64(res.Number)
+			trie      = n >= 9
+			available = n <= 4 || trie
+		)
+		if res.Hash != chain.GetHeaderByNumber(n).Hash() {
+			t.Errorf("block %d: wrong hash", n)
+		}
+		if res.Trie != trie || res.Available != available {
+			t.Errorf("block %d: wrong availability: trie %t available %t, want trie %t available %t", n, res.Trie, res.Available, trie, available)
+		}
+		// Blocks without state are regenerated from the genesis state.
+		var distance uint64
+		if !trie {
+			distance = n
+		}
+		switch {
+		case !available && res.Reexec != nil:
+			t.Errorf("block %d: unexpected re-execution distance %d", n, *res.Reexec)
+		case available && (res.Reexec == nil || uint64(*res.Reexec) != distance):
+			t.Errorf("block %d: wrong re-execution distance %v, want %d", n, res.Reexec, distance)
+		}
+	}
+	if _, err := api.StateAvailability(5, 2, nil); err == nil {
+		t.Fatal("expected error for inverted range")
+	}
+}
@@ -19,9 +19,11 @@ package eth
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"runtime"
+	"strings"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts"
@@ -37,6 +39,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/bundler"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
@@ -53,6 +56,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/dnsdisc"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/msglimit"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -73,6 +77,8 @@ type Ethereum struct {
 	handler            *handler
 	ethDialCandidates  enode.Iterator
 	snapDialCandidates enode.Iterator
+	ethLimits          msglimit.Limits
+	snapLimits         msglimit.Limits
 
 	// DB interfaces
 	chainDb ethdb.Database // Block chain database
@@ -88,6 +94,7 @@ type Ethereum struct {
 	APIBackend *EthAPIBackend
 
 	miner    *miner.Miner
+	bundler  *bundler.Bundler
 	gasPrice *big.Int
 
 	networkID     uint64
@@ -107,6 +114,15 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if !config.SyncMode.IsValid() {
 		return nil, fmt.Errorf("invalid sync mode %d", config.SyncMode)
 	}
+	// Full sync from a checkpoint would backfill and execute the entire chain
+	// below it, defeating the purpose of the checkpoint.
+	if config.CheckpointHash != (common.Hash{}) && config.SyncMode != downloader.SnapSync {
+		return nil, errors.New("checkpoint sync requires snap sync")
+	}
+	ethLimits, snapLimits, err := messageLimits(config)
+	if err != nil {
+		return nil, err
+	}
 	if config.Miner.GasPrice == nil || config.Miner.GasPrice.Sign() <= 0 {
 		log.Warn("Sanitizing invalid miner gas price", "provided", config.Miner.GasPrice, "updated", ethconfig.Defaults.Miner.GasPrice)
 		config.Miner.GasPrice = new(big.Int).Set(ethconfig.Defaults.Miner.GasPrice)
@@ -163,6 +179,8 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		bloomIndexer:      core.NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms),
 		p2pServer:         stack.Server(),
 		shutdownTracker:   shutdowncheck.NewShutdownTracker(chainDb),
+		ethLimits:         ethLimits,
+		snapLimits:        snapLimits,
 	}
 	bcVersion := rawdb.ReadDatabaseVersion(chainDb)
 	var dbVer = "<nil>"
@@ -244,15 +262,22 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	// Permit the downloader to use the trie cache allowance during fast sync
 	cacheLimit := cacheConfig.TrieCleanLimit + cacheConfig.TrieDirtyLimit + cacheConfig.SnapshotLimit
 	if eth.handler, err = newHandler(&handlerConfig{
-		NodeID:         eth.p2pServer.Self().ID(),
-		Database:       chainDb,
-		Chain:          eth.blockchain,
-		TxPool:         eth.txPool,
-		Network:        networkID,
-		Sync:           config.SyncMode,
-		BloomCache:     uint64(cacheLimit),
-		EventMux:       eth.eventMux,
-		RequiredBlocks: config.RequiredBlocks,
+		NodeID:           eth.p2pServer.Self().ID(),
+		Database:         chainDb,
+		Chain:            eth.blockchain,
+		TxPool:           eth.txPool,
+		Network:          networkID,
+		Sync:             config.SyncMode,
+		BloomCache:       uint64(cacheLimit),
+		EventMux:         eth.eventMux,
+		RequiredBlocks:   config.RequiredBlocks,
+		CheckpointNumber: config.CheckpointNumber,
+		CheckpointHash:   config.CheckpointHash,
+
+		TxBroadcastFanout:  config.TxBroadcastFanout,
+		TxBroadcastMaxSize: config.TxBroadcastMaxSize,
+		TxSyncLimit:        config.TxSyncLimit,
+		TxValidatorPeers:   config.TxValidatorPeers,
 	}); err != nil {
 		return nil, err
 	}
@@ -260,6 +285,13 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	eth.miner = miner.New(eth, config.Miner, eth.engine)
 	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
 
+	if config.Bundler.Enabled {
+		if eth.bundler, err = bundler.New(config.Bundler, eth.blockchain, eth.accountManager); err != nil {
+			return nil, err
+		}
+		eth.miner.SetBundleBuilder(eth.bundler)
+	}
+
 	eth.APIBackend = &EthAPIBackend{stack.Config().ExtRPCEnabled(), stack.Config().AllowUnprotectedTxs, eth, nil}
 	if eth.APIBackend.allowUnprotectedTxs {
 		log.Info("Unprotected transactions allowed")
@@ -286,6 +318,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 
 	// Register the backend on the node
 	stack.RegisterAPIs(eth.APIs())
+	stack.SetRPCCachePolicy(ethapi.NewCachePolicy(eth.APIBackend, config.RPCCacheDepth))
 	stack.RegisterProtocols(eth.Protocols())
 	stack.RegisterLifecycle(eth)
 
@@ -320,6 +353,10 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	// Append the ERC-4337 methods if the bundler is enabled
+	if s.bundler != nil {
+		apis = append(apis, rpc.API{Namespace: "eth", Service: bundler.NewAPI(s.bundler)})
+	}
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -363,13 +400,49 @@ func (s *Ethereum) BloomIndexer() *core.ChainIndexer   { return s.bloomIndexer }
 // Protocols returns all the currently configured
 // network protocols to start.
 func (s *Ethereum) Protocols() []p2p.Protocol {
-	protos := eth.MakeProtocols((*ethHandler)(s.handler), s.networkID, s.ethDialCandidates)
+	queues := eth.QueueLimits{Txs: s.config.TxSendQueue, TxAnns: s.config.TxAnnounceQueue}
+	protos := eth.MakeProtocols((*ethHandler)(s.handler), s.networkID, s.ethDialCandidates, queues, s.ethLimits)
 	if s.config.SnapshotCache > 0 {
-		protos = append(protos, snap.MakeProtocols((*snapHandler)(s.handler), s.snapDialCandidates)...)
+		protos = append(protos, snap.MakeProtocols((*snapHandler)(s.handler), s.snapDialCandidates, s.snapLimits)...)
 	}
 	return protos
 }
 
+// messageLimits assembles the protocol message limits from the configuration.
+func messageLimits(config *ethconfig.Config) (msglimit.Limits, msglimit.Limits, error) {
+	var (
+		ethLimits  = msglimit.Limits{MaxSize: config.EthMaxMessageSize, Rates: make(map[uint64]float64)}
+		snapLimits = msglimit.Limits{MaxSize: config.SnapMaxMessageSize, Rates: make(map[uint64]float64)}
+	)
+	for name, limit := range config.MessageRateLimits {
+		proto, msg, _ := strings.Cut(name, "/")
+		var (
+			code uint64
+			ok   bool
+		)
+		switch proto {
+		case eth.ProtocolName:
+			if code, ok = eth.MessageNames.Code(msg); ok {
+				ethLimits.Rates[code] = limit
+			}
+		case snap.ProtocolName:
+			if code, ok = snap.MessageNames.Code(msg); ok {
+				snapLimits.Rates[code] = limit
+			}
+		}
+		if !ok {
+			return ethLimits, snapLimits, fmt.Errorf("rate limit of unknown message %q", name)
+		}
+	}
+	if err := ethLimits.Validate(eth.MessageNames); err != nil {
+		return ethLimits, snapLimits, fmt.Errorf("invalid eth message limits: %v", err)
+	}
+	if err := snapLimits.Validate(snap.MessageNames); err != nil {
+		return ethLimits, snapLimits, fmt.Errorf("invalid snap message limits: %v", err)
+	}
+	return ethLimits, snapLimits, nil
+}
+
 // Start implements node.Lifecycle, starting all internal goroutines needed by the
 // Ethereum protocol implementation.
 func (s *Ethereum) Start() error {
@@ -405,6 +478,9 @@ func (s *Ethereum) Stop() error {
 	// Then stop everything else.
 	s.bloomIndexer.Close()
 	close(s.closeBloomHandler)
+	if s.bundler != nil {
+		s.bundler.Close()
+	}
 	s.txPool.Close()
 	s.blockchain.Stop()
 	s.engine.Close()
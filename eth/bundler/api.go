@@ -0,0 +1,274 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bundler
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/gasestimator"
+)
+
+// receiptLookback is the number of recent blocks searched for the inclusion of
+// an operation.
+const receiptLookback = 1024
+
+// Error codes of the ERC-4337 RPC methods.
+const (
+	errCodeInvalidFields      = -32602
+	errCodeSimulateValidation = -32500
+	errCodeTimeRange          = -32503
+	errCodeThrottled          = -32504
+	errCodeSignature          = -32507
+)
+
+type rpcError struct {
+	code    int
+	message string
+}
+
+func (e *rpcError) Error() string  { return e.message }
+func (e *rpcError) ErrorCode() int { return e.code }
+
+// API offers the ERC-4337 bundler methods in the eth namespace.
+type API struct {
+	b *Bundler
+}
+
+// NewAPI creates the bundler API.
+func NewAPI(b *Bundler) *API {
+	return &API{b}
+}
+
+// SupportedEntryPoints returns the entry points of the accepted operations.
+func (api *API) SupportedEntryPoints() []common.Address {
+	return api.b.EntryPoints()
+}
+
+// SendUserOperation validates an operation and adds it to the pool, returning its
+// hash.
+func (api *API) SendUserOperation(op UserOperation, entryPoint common.Address) (common.Hash, error) {
+	return api.b.Add(&op, entryPoint)
+}
+
+// UserOperationGasEstimate are the gas limits estimated for an operation.
+type UserOperationGasEstimate struct {
+	PreVerificationGas   hexutil.Uint64 `json:"preVerificationGas"`
+	VerificationGasLimit hexutil.Uint64 `json:"verificationGasLimit"`
+	CallGasLimit         hexutil.Uint64 `json:"callGasLimit"`
+}
+
+// EstimateUserOperationGas estimates the gas limits of an operation against the
+// latest state. The signature of the operation may be a dummy one, its gas
+// limits and fees are ignored. The call gas is estimated against the deployed
+// account, it can't account for the deployment by the init code.
+func (api *API) EstimateUserOperationGas(ctx context.Context, op UserOperation, entryPoint common.Address) (*UserOperationGasEstimate, error) {
+	b := api.b
+	if !b.supports(entryPoint) {
+		return nil, errUnsupportedEntryPoint
+	}
+	if op.Nonce == nil {
+		return nil, &rpcError{code: errCodeInvalidFields, message: "missing nonce"}
+	}
+	header := b.chain.CurrentBlock()
+	statedb, err := b.chain.StateAt(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	// Simulate the validation with the gas of the block, without fees.
+	op.CallGasLimit = new(big.Int)
+	op.VerificationGasLimit = new(big.Int).SetUint64(header.GasLimit)
+	op.MaxFeePerGas, op.MaxPriorityFeePerGas = new(big.Int), new(big.Int)
+	op.PreVerificationGas = new(big.Int)
+	op.PreVerificationGas.SetUint64(op.minPreVerificationGas())
+
+	result, err := b.simulateValidation(header, statedb, &op, entryPoint)
+	if err != nil {
+		return nil, err
+	}
+	estimate := &UserOperationGasEstimate{PreVerificationGas: hexutil.Uint64(op.PreVerificationGas.Uint64())}
+	if used := new(big.Int).Sub(result.PreOpGas, op.PreVerificationGas); used.Sign() > 0 {
+		// Leave a margin for the gas forwarding rules
+		estimate.VerificationGasLimit = hexutil.Uint64(used.Uint64() * 3 / 2)
+	}
+	call := &core.Message{
+		From:              entryPoint,
+		To:                &op.Sender,
+		Value:             new(big.Int),
+		GasLimit:          header.GasLimit,
+		GasPrice:          new(big.Int),
+		GasFeeCap:         new(big.Int),
+		GasTipCap:         new(big.Int),
+		Data:              op.CallData,
+		SkipAccountChecks: true,
+	}
+	opts := &gasestimator.Options{Config: b.chain.Config(), Chain: b.chain, Header: header, State: statedb}
+	gas, revert, err := gasestimator.Estimate(ctx, call, opts, header.GasLimit)
+	if err != nil {
+		if len(revert) > 0 {
+			return nil, &rpcError{code: errCodeSimulateValidation, message: "execution reverted: " + hexutil.Encode(revert)}
+		}
+		return nil, err
+	}
+	estimate.CallGasLimit = hexutil.Uint64(max(gas, minCallGas))
+	return estimate, nil
+}
+
+// RPCUserOperation is an operation with the location of its inclusion, which
+// is empty while it is pending.
+type RPCUserOperation struct {
+	UserOperation   *UserOperation `json:"userOperation"`
+	EntryPoint      common.Address `json:"entryPoint"`
+	BlockNumber     *hexutil.Big   `json:"blockNumber"`
+	BlockHash       *common.Hash   `json:"blockHash"`
+	TransactionHash *common.Hash   `json:"transactionHash"`
+}
+
+// GetUserOperationByHash returns a pending operation, or one included in the
+// recent blocks.
+func (api *API) GetUserOperationByHash(hash common.Hash) (*RPCUserOperation, error) {
+	if op, entryPoint := api.b.Get(hash); op != nil {
+		return &RPCUserOperation{UserOperation: op, EntryPoint: entryPoint}, nil
+	}
+	inc := api.b.findIncluded(hash)
+	if inc == nil {
+		return nil, nil
+	}
+	block := api.b.chain.GetBlockByHash(inc.receipt.BlockHash)
+	if block == nil {
+		return nil, nil
+	}
+	tx := block.Transaction(inc.receipt.TxHash)
+	if tx == nil {
+		return nil, errors.New("bundle transaction not found")
+	}
+	ops, err := decodeHandleOps(tx.Data())
+	if err != nil {
+		return nil, err
+	}
+	for i := range ops {
+		if ops[i].Hash(inc.entryPoint, api.b.chainID) == hash {
+			return &RPCUserOperation{
+				UserOperation:   &ops[i],
+				EntryPoint:      inc.entryPoint,
+				BlockNumber:     (*hexutil.Big)(inc.receipt.BlockNumber),
+				BlockHash:       &inc.receipt.BlockHash,
+				TransactionHash: &inc.receipt.TxHash,
+			}, nil
+		}
+	}
+	return nil, errors.New("operation not found in its bundle")
+}
+
+// UserOperationReceipt is the outcome of an included operation.
+type UserOperationReceipt struct {
+	UserOpHash    common.Hash    `json:"userOpHash"`
+	EntryPoint    common.Address `json:"entryPoint"`
+	Sender        common.Address `json:"sender"`
+	Nonce         *hexutil.Big   `json:"nonce"`
+	Paymaster     common.Address `json:"paymaster"`
+	ActualGasCost *hexutil.Big   `json:"actualGasCost"`
+	ActualGasUsed *hexutil.Big   `json:"actualGasUsed"`
+	Success       bool           `json:"success"`
+	Reason        hexutil.Bytes  `json:"reason,omitempty"`
+	Logs          []*types.Log   `json:"logs"`
+	Receipt       *types.Receipt `json:"receipt"`
+}
+
+// GetUserOperationReceipt returns the receipt of an operation included in the
+// recent blocks, nil if it is pending or unknown.
+func (api *API) GetUserOperationReceipt(hash common.Hash) (*UserOperationReceipt, error) {
+	if op, _ := api.b.Get(hash); op != nil {
+		return nil, nil
+	}
+	inc := api.b.findIncluded(hash)
+	if inc == nil {
+		return nil, nil
+	}
+	event := inc.receipt.Logs[inc.event]
+	values, err := entryPointABI.Events["UserOperationEvent"].Inputs.NonIndexed().Unpack(event.Data)
+	if err != nil {
+		return nil, err
+	}
+	receipt := &UserOperationReceipt{
+		UserOpHash:    hash,
+		EntryPoint:    inc.entryPoint,
+		Sender:        common.BytesToAddress(event.Topics[2].Bytes()),
+		Paymaster:     common.BytesToAddress(event.Topics[3].Bytes()),
+		Nonce:         (*hexutil.Big)(values[0].(*big.Int)),
+		Success:       values[1].(bool),
+		ActualGasCost: (*hexutil.Big)(values[2].(*big.Int)),
+		ActualGasUsed: (*hexutil.Big)(values[3].(*big.Int)),
+		Logs:          []*types.Log{},
+		Receipt:       inc.receipt,
+	}
+	// The logs of the operation are those since the previous operation of the
+	// bundle, the entry point logs the revert reason of failed calls.
+	for i := inc.event - 1; i >= 0; i-- {
+		l := inc.receipt.Logs[i]
+		if l.Address == inc.entryPoint {
+			if len(l.Topics) > 0 && (l.Topics[0] == userOperationEventID || l.Topics[0] == beforeExecutionID) {
+				break
+			}
+			if len(l.Topics) > 1 && l.Topics[0] == userOperationRevertReasonID && l.Topics[1] == hash {
+				if values, err := entryPointABI.Events["UserOperationRevertReason"].Inputs.NonIndexed().Unpack(l.Data); err == nil {
+					receipt.Reason = values[1].([]byte)
+				}
+			}
+			continue
+		}
+		receipt.Logs = append([]*types.Log{l}, receipt.Logs...)
+	}
+	return receipt, nil
+}
+
+// inclusion locates the UserOperationEvent of an included operation.
+type inclusion struct {
+	entryPoint common.Address
+	receipt    *types.Receipt
+	event      int // Index of the event in the receipt logs
+}
+
+// findIncluded searches the recent canonical blocks for the inclusion of an
+// operation.
+func (b *Bundler) findIncluded(hash common.Hash) *inclusion {
+	head := b.chain.CurrentBlock().Number.Uint64()
+	for n := head; n+receiptLookback > head; n-- {
+		header := b.chain.GetHeaderByNumber(n)
+		if header == nil {
+			break
+		}
+		if types.BloomLookup(header.Bloom, userOperationEventID) && types.BloomLookup(header.Bloom, hash) {
+			for _, receipt := range b.chain.GetReceiptsByHash(header.Hash()) {
+				for i, l := range receipt.Logs {
+					if len(l.Topics) == 4 && l.Topics[0] == userOperationEventID && l.Topics[1] == hash && b.supports(l.Address) {
+						return &inclusion{entryPoint: l.Address, receipt: receipt, event: i}
+					}
+				}
+			}
+		}
+		if n == 0 {
+			break
+		}
+	}
+	return nil
+}
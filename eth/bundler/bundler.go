@@ -0,0 +1,544 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package bundler implements an ERC-4337 bundler: a mempool of user operations
+// validated against the EntryPoint contract, which the miner bundles into a
+// handleOps transaction at the top of the blocks it builds.
+//
+// Operations are validated by simulation only, the storage and opcode rules of
+// ERC-7562 are not enforced. Operations invalidated by others are dropped when
+// a bundle is built.
+package bundler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	minCallGas        = params.CallValueTransferGas // Lowest call gas limit, a call transferring value
+	priceBump         = 10                          // Fee increase, in percent, required to replace an operation
+	validityMargin    = 30                          // Seconds an operation must remain valid for when added
+	bundleOverheadGas = 50_000                      // EntryPoint gas of a bundle besides its operations
+	chainHeadChanSize = 10
+)
+
+// Config are the configuration parameters of the bundler.
+type Config struct {
+	Enabled     bool             `toml:",omitempty"`
+	EntryPoints []common.Address `toml:",omitempty"` // Supported EntryPoint contracts
+	Account     common.Address   `toml:",omitempty"` // Account signing the bundles and receiving the fees of the operations
+	PoolSize    int              // Maximum number of pending operations
+	SenderLimit int              // Maximum number of pending operations of a sender
+}
+
+// DefaultConfig contains the default settings of the bundler.
+var DefaultConfig = Config{
+	EntryPoints: []common.Address{EntryPointV06},
+	PoolSize:    4096,
+	SenderLimit: 4,
+}
+
+var (
+	errUnsupportedEntryPoint = &rpcError{code: errCodeInvalidFields, message: "unsupported entry point"}
+	errKnownOperation        = &rpcError{code: errCodeInvalidFields, message: "already known"}
+	errReplaceUnderpriced    = &rpcError{code: errCodeInvalidFields, message: "replacement operation underpriced"}
+	errPoolFull              = &rpcError{code: errCodeThrottled, message: "user operation pool is full"}
+	errSenderLimit           = &rpcError{code: errCodeThrottled, message: "too many pending operations of the sender"}
+	errSignature             = &rpcError{code: errCodeSignature, message: "invalid user operation signature"}
+)
+
+// poolOp is a pending operation.
+type poolOp struct {
+	op         *UserOperation
+	entryPoint common.Address
+	hash       common.Hash
+}
+
+// Bundler keeps the pending user operations and builds the bundles including
+// them.
+type Bundler struct {
+	config  Config
+	chain   *core.BlockChain
+	am      *accounts.Manager
+	chainID *big.Int
+
+	// call executes a message on top of a state, it is replaced in tests.
+	call func(header *types.Header, statedb *state.StateDB, msg *core.Message) (*core.ExecutionResult, error)
+
+	mu      sync.RWMutex
+	ops     map[common.Hash]*poolOp
+	senders map[common.Address][]*poolOp // Pending operations of the senders, sorted by nonce
+
+	headSub event.Subscription
+	wg      sync.WaitGroup
+}
+
+// New creates a bundler of the operations sent to the entry points of the
+// configuration, whose bundles are signed by the configured account.
+func New(config Config, chain *core.BlockChain, am *accounts.Manager) (*Bundler, error) {
+	if len(config.EntryPoints) == 0 {
+		return nil, errors.New("no entry points configured")
+	}
+	if config.Account == (common.Address{}) {
+		return nil, errors.New("no bundler account configured")
+	}
+	if config.PoolSize <= 0 {
+		config.PoolSize = DefaultConfig.PoolSize
+	}
+	if config.SenderLimit <= 0 {
+		config.SenderLimit = DefaultConfig.SenderLimit
+	}
+	b := &Bundler{
+		config:  config,
+		chain:   chain,
+		am:      am,
+		chainID: chain.Config().ChainID,
+		ops:     make(map[common.Hash]*poolOp),
+		senders: make(map[common.Address][]*poolOp),
+	}
+	b.call = b.evmCall
+
+	heads := make(chan core.ChainHeadEvent, chainHeadChanSize)
+	b.headSub = chain.SubscribeChainHeadEvent(heads)
+	b.wg.Add(1)
+	go b.loop(heads)
+
+	log.Info("Started ERC-4337 bundler", "entrypoints", config.EntryPoints, "account", config.Account)
+	return b, nil
+}
+
+// Close stops tracking the chain.
+func (b *Bundler) Close() {
+	b.headSub.Unsubscribe()
+	b.wg.Wait()
+}
+
+// loop removes the operations included by new chain heads from the pool.
+func (b *Bundler) loop(heads chan core.ChainHeadEvent) {
+	defer b.wg.Done()
+
+	for {
+		select {
+		case head := <-heads:
+			b.removeIncluded(b.chain.GetReceiptsByHash(head.Block.Hash()))
+		case <-b.headSub.Err():
+			return
+		}
+	}
+}
+
+// EntryPoints returns the supported entry points.
+func (b *Bundler) EntryPoints() []common.Address {
+	return b.config.EntryPoints
+}
+
+func (b *Bundler) supports(entryPoint common.Address) bool {
+	for _, ep := range b.config.EntryPoints {
+		if ep == entryPoint {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns a pending operation and its entry point, nil if unknown.
+func (b *Bundler) Get(hash common.Hash) (*UserOperation, common.Address) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if p := b.ops[hash]; p != nil {
+		return p.op, p.entryPoint
+	}
+	return nil, common.Address{}
+}
+
+// Pending returns the number of pending operations.
+func (b *Bundler) Pending() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return len(b.ops)
+}
+
+// Add validates an operation against the latest state and adds it to the pool,
+// returning its hash. An operation with the nonce of a pending one replaces it
+// if it pays higher fees.
+func (b *Bundler) Add(op *UserOperation, entryPoint common.Address) (common.Hash, error) {
+	if !b.supports(entryPoint) {
+		return common.Hash{}, errUnsupportedEntryPoint
+	}
+	if err := checkFields(op); err != nil {
+		return common.Hash{}, err
+	}
+	hash := op.Hash(entryPoint, b.chainID)
+
+	b.mu.RLock()
+	err := b.checkAdmission(op, hash)
+	b.mu.RUnlock()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	header := b.chain.CurrentBlock()
+	statedb, err := b.chain.StateAt(header.Root)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	result, err := b.simulateValidation(header, statedb, op, entryPoint)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := checkValidation(header, result); err != nil {
+		return common.Hash{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// The pool might have changed during the simulation
+	if err := b.checkAdmission(op, hash); err != nil {
+		return common.Hash{}, err
+	}
+	for _, p := range b.senders[op.Sender] {
+		if p.op.Nonce.Cmp(op.Nonce) == 0 {
+			log.Debug("Replacing user operation", "old", p.hash, "new", hash)
+			b.remove(p.hash)
+			break
+		}
+	}
+	p := &poolOp{op: op, entryPoint: entryPoint, hash: hash}
+	b.ops[hash] = p
+
+	pending := append(b.senders[op.Sender], p)
+	sort.Slice(pending, func(i, j int) bool { return pending[i].op.Nonce.Cmp(pending[j].op.Nonce) < 0 })
+	b.senders[op.Sender] = pending
+
+	log.Debug("Added user operation", "hash", hash, "sender", op.Sender, "nonce", op.Nonce)
+	return hash, nil
+}
+
+// checkFields checks the gas limits and fees of an operation.
+func checkFields(op *UserOperation) error {
+	for _, v := range []*big.Int{op.Nonce, op.CallGasLimit, op.VerificationGasLimit, op.PreVerificationGas, op.MaxFeePerGas, op.MaxPriorityFeePerGas} {
+		if v == nil || v.Sign() < 0 || v.BitLen() > 256 {
+			return &rpcError{code: errCodeInvalidFields, message: "missing or invalid numeric field"}
+		}
+	}
+	if op.CallGasLimit.Cmp(new(big.Int).SetUint64(minCallGas)) < 0 {
+		return &rpcError{code: errCodeInvalidFields, message: fmt.Sprintf("callGasLimit below %d", minCallGas)}
+	}
+	if !op.PreVerificationGas.IsUint64() || op.PreVerificationGas.Uint64() < op.minPreVerificationGas() {
+		return &rpcError{code: errCodeInvalidFields, message: fmt.Sprintf("preVerificationGas below %d", op.minPreVerificationGas())}
+	}
+	if op.MaxPriorityFeePerGas.Cmp(op.MaxFeePerGas) > 0 {
+		return &rpcError{code: errCodeInvalidFields, message: "maxPriorityFeePerGas above maxFeePerGas"}
+	}
+	if len(op.PaymasterAndData) > 0 && len(op.PaymasterAndData) < common.AddressLength {
+		return &rpcError{code: errCodeInvalidFields, message: "paymasterAndData too short"}
+	}
+	return nil
+}
+
+// checkAdmission checks whether an operation fits into the pool, the lock must
+// be held.
+func (b *Bundler) checkAdmission(op *UserOperation, hash common.Hash) error {
+	if b.ops[hash] != nil {
+		return errKnownOperation
+	}
+	pending := b.senders[op.Sender]
+	for _, p := range pending {
+		if p.op.Nonce.Cmp(op.Nonce) == 0 {
+			// Both fees must be bumped for the replacement
+			if !bumped(p.op.MaxFeePerGas, op.MaxFeePerGas) || !bumped(p.op.MaxPriorityFeePerGas, op.MaxPriorityFeePerGas) {
+				return errReplaceUnderpriced
+			}
+			return nil
+		}
+	}
+	if len(pending) >= b.config.SenderLimit {
+		return errSenderLimit
+	}
+	if len(b.ops) >= b.config.PoolSize {
+		return errPoolFull
+	}
+	return nil
+}
+
+// bumped reports whether the new fee exceeds the old one by the price bump.
+func bumped(oldFee, newFee *big.Int) bool {
+	threshold := new(big.Int).Mul(oldFee, big.NewInt(100+priceBump))
+	return new(big.Int).Mul(newFee, big.NewInt(100)).Cmp(threshold) >= 0
+}
+
+// remove deletes an operation from the pool, the lock must be held.
+func (b *Bundler) remove(hash common.Hash) {
+	p := b.ops[hash]
+	if p == nil {
+		return
+	}
+	delete(b.ops, hash)
+
+	pending := b.senders[p.op.Sender]
+	for i, q := range pending {
+		if q == p {
+			pending = append(pending[:i:i], pending[i+1:]...)
+			break
+		}
+	}
+	if len(pending) == 0 {
+		delete(b.senders, p.op.Sender)
+	} else {
+		b.senders[p.op.Sender] = pending
+	}
+}
+
+// removeIncluded removes the operations executed by the entry points in a block,
+// and those using the same nonce, from the pool.
+func (b *Bundler) removeIncluded(receipts types.Receipts) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, receipt := range receipts {
+		for _, l := range receipt.Logs {
+			if len(l.Topics) != 4 || l.Topics[0] != userOperationEventID || !b.supports(l.Address) || len(l.Data) < 32 {
+				continue
+			}
+			var (
+				sender = common.BytesToAddress(l.Topics[2].Bytes())
+				nonce  = new(big.Int).SetBytes(l.Data[:32])
+			)
+			b.remove(l.Topics[1])
+			for _, p := range b.senders[sender] {
+				if p.op.Nonce.Cmp(nonce) == 0 {
+					b.remove(p.hash)
+					break
+				}
+			}
+		}
+	}
+}
+
+// simulateValidation runs the validation of an operation by the entry point.
+func (b *Bundler) simulateValidation(header *types.Header, statedb *state.StateDB, op *UserOperation, entryPoint common.Address) (*validationResult, error) {
+	data, err := entryPointABI.Pack("simulateValidation", *op)
+	if err != nil {
+		return nil, &rpcError{code: errCodeInvalidFields, message: err.Error()}
+	}
+	msg := &core.Message{
+		To:                &entryPoint,
+		Value:             new(big.Int),
+		GasLimit:          header.GasLimit,
+		GasPrice:          new(big.Int),
+		GasFeeCap:         new(big.Int),
+		GasTipCap:         new(big.Int),
+		Data:              data,
+		SkipAccountChecks: true,
+	}
+	if statedb.GetCodeSize(entryPoint) == 0 {
+		return nil, fmt.Errorf("entry point %v is not deployed", entryPoint)
+	}
+	result, err := b.call(header, statedb.Copy(), msg)
+	if err != nil {
+		return nil, err
+	}
+	if !errors.Is(result.Err, vm.ErrExecutionReverted) {
+		return nil, fmt.Errorf("validation simulation didn't revert (err: %v)", result.Err)
+	}
+	validation, err := decodeValidationResult(result.Revert())
+	if failed := new(FailedOpError); errors.As(err, &failed) {
+		return nil, &rpcError{code: errCodeSimulateValidation, message: failed.Reason}
+	}
+	return validation, err
+}
+
+// checkValidation checks the signature and the validity range of a validation
+// result.
+func checkValidation(header *types.Header, result *validationResult) error {
+	if result.SigFailed {
+		return errSignature
+	}
+	if result.ValidAfter > header.Time {
+		return &rpcError{code: errCodeTimeRange, message: "user operation not valid yet"}
+	}
+	if result.ValidUntil != 0 && result.ValidUntil < header.Time+validityMargin {
+		return &rpcError{code: errCodeTimeRange, message: "user operation expires too soon"}
+	}
+	return nil
+}
+
+// evmCall executes a message on top of a state, without checking the fees.
+func (b *Bundler) evmCall(header *types.Header, statedb *state.StateDB, msg *core.Message) (*core.ExecutionResult, error) {
+	var (
+		blockContext = core.NewEVMBlockContext(header, b.chain, nil)
+		evm          = vm.NewEVM(blockContext, core.NewEVMTxContext(msg), statedb, b.chain.Config(), vm.Config{NoBaseFee: true})
+	)
+	return core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(math.MaxUint64))
+}
+
+// BuildBundles implements miner.BundleBuilder, returning a handleOps transaction
+// for every entry point with pending operations. The bundles are simulated in
+// order on top of the state, each one on the changes of the previous ones, and
+// the operations rejected by the entry point are dropped. Signing the bundles
+// is bounded by the context.
+func (b *Bundler) BuildBundles(ctx context.Context, header *types.Header, statedb *state.StateDB) []*types.Transaction {
+	account := accounts.Account{Address: b.config.Account}
+	wallet, err := b.am.Find(account)
+	if err != nil {
+		log.Debug("Bundler account unavailable", "account", account.Address, "err", err)
+		return nil
+	}
+	var (
+		nonce   = statedb.GetNonce(account.Address)
+		gasLeft = header.GasLimit
+		bundles []*types.Transaction
+	)
+	for _, entryPoint := range b.config.EntryPoints {
+		ops := b.candidates(entryPoint, header.BaseFee, gasLeft)
+		for len(ops) > 0 {
+			userOps := make([]UserOperation, len(ops))
+			for i, p := range ops {
+				userOps[i] = *p.op
+			}
+			data, err := entryPointABI.Pack("handleOps", userOps, account.Address)
+			if err != nil {
+				log.Error("Failed to pack bundle", "err", err)
+				break
+			}
+			gas := bundleGas(ops, data)
+			msg := &core.Message{
+				From:              account.Address,
+				To:                &entryPoint,
+				Value:             new(big.Int),
+				GasLimit:          gas,
+				GasPrice:          new(big.Int),
+				GasFeeCap:         new(big.Int),
+				GasTipCap:         new(big.Int),
+				Data:              data,
+				SkipAccountChecks: true,
+			}
+			snapshot := statedb.Snapshot()
+			result, err := b.call(header, statedb, msg)
+			if err != nil || result.Failed() {
+				statedb.RevertToSnapshot(snapshot)
+			}
+			if err != nil {
+				log.Debug("Failed to simulate bundle", "entrypoint", entryPoint, "err", err)
+				break
+			}
+			if result.Failed() {
+				// Drop the operation rejected by the entry point and retry
+				failed := new(FailedOpError)
+				if !errors.As(decodeFailedOp(result.Revert()), &failed) || failed.Index >= uint64(len(ops)) {
+					log.Debug("Bundle reverted", "entrypoint", entryPoint, "err", result.Err)
+					break
+				}
+				log.Debug("Dropping rejected user operation", "hash", ops[failed.Index].hash, "reason", failed.Reason)
+				b.mu.Lock()
+				b.remove(ops[failed.Index].hash)
+				b.mu.Unlock()
+
+				ops = append(ops[:failed.Index:failed.Index], ops[failed.Index+1:]...)
+				continue
+			}
+			tx, err := accounts.SignTxContext(ctx, wallet, account, newBundleTx(b.chainID, nonce, header.BaseFee, gas, entryPoint, data), b.chainID)
+			if err != nil {
+				log.Debug("Failed to sign bundle", "account", account.Address, "err", err)
+				return bundles
+			}
+			// Keep the changes of the bundle for simulating the next ones
+			statedb.Finalise(b.chain.Config().IsEIP158(header.Number))
+
+			bundles = append(bundles, tx)
+			nonce++
+			gasLeft -= gas
+			break
+		}
+	}
+	return bundles
+}
+
+// candidates returns the operations of an entry point to bundle, the first
+// pending operation of every sender paying at least the base fee, by decreasing
+// gas price, up to the gas limit.
+func (b *Bundler) candidates(entryPoint common.Address, baseFee *big.Int, gasLimit uint64) []*poolOp {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var ops []*poolOp
+	for _, pending := range b.senders {
+		p := pending[0]
+		if p.entryPoint != entryPoint || (baseFee != nil && p.op.MaxFeePerGas.Cmp(baseFee) < 0) {
+			continue
+		}
+		ops = append(ops, p)
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].op.gasPrice(baseFee).Cmp(ops[j].op.gasPrice(baseFee)) > 0
+	})
+	var (
+		gas      = new(big.Int).SetUint64(params.TxGas + bundleOverheadGas)
+		limit    = new(big.Int).SetUint64(gasLimit)
+		selected []*poolOp
+	)
+	for _, p := range ops {
+		if next := new(big.Int).Add(gas, p.op.requiredGas()); next.Cmp(limit) <= 0 {
+			selected = append(selected, p)
+			gas = next
+		}
+	}
+	return selected
+}
+
+// bundleGas returns the gas limit of a bundle, covering the maximum gas of its
+// operations.
+func bundleGas(ops []*poolOp, data []byte) uint64 {
+	gas := params.TxGas + bundleOverheadGas + uint64(len(data))*params.TxDataNonZeroGasEIP2028
+	for _, p := range ops {
+		gas += p.op.requiredGas().Uint64()
+	}
+	return gas
+}
+
+// newBundleTx creates the transaction of a bundle. It pays no tip, as it is
+// included by the miner building the block.
+func newBundleTx(chainID *big.Int, nonce uint64, baseFee *big.Int, gas uint64, entryPoint common.Address, data []byte) *types.Transaction {
+	if baseFee == nil {
+		return types.NewTx(&types.LegacyTx{Nonce: nonce, GasPrice: new(big.Int), Gas: gas, To: &entryPoint, Data: data})
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: new(big.Int),
+		GasFeeCap: new(big.Int).Set(baseFee),
+		Gas:       gas,
+		To:        &entryPoint,
+		Data:      data,
+	})
+}
@@ -0,0 +1,402 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bundler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Signatures making the fake entry point reject an operation.
+var (
+	sigInvalid  = []byte("invalid")  // validation reports a signature failure
+	sigRejected = []byte("rejected") // validation reverts with FailedOp
+	sigDropped  = []byte("dropped")  // handleOps reverts with FailedOp
+	sigExpiring = []byte("expiring") // validation reports an expiring operation
+)
+
+// testEntryPoint is a second entry point deployed in the test chain.
+var testEntryPoint = common.Address{0xe9}
+
+// fakeEntryPoint executes the EntryPoint calls of the bundler.
+func fakeEntryPoint(t *testing.T) func(*types.Header, *state.StateDB, *core.Message) (*core.ExecutionResult, error) {
+	type returnInfo struct {
+		PreOpGas         *big.Int
+		Prefund          *big.Int
+		SigFailed        bool
+		ValidAfter       *big.Int
+		ValidUntil       *big.Int
+		PaymasterContext []byte
+	}
+	type stakeInfo struct {
+		Stake           *big.Int
+		UnstakeDelaySec *big.Int
+	}
+	revert := func(name string, args ...interface{}) (*core.ExecutionResult, error) {
+		e := entryPointABI.Errors[name]
+		data, err := e.Inputs.Pack(args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &core.ExecutionResult{Err: vm.ErrExecutionReverted, ReturnData: append(e.ID[:4:4], data...)}, nil
+	}
+	return func(header *types.Header, statedb *state.StateDB, msg *core.Message) (*core.ExecutionResult, error) {
+		method, err := entryPointABI.MethodById(msg.Data)
+		if err != nil {
+			return nil, err
+		}
+		switch method.Name {
+		case "simulateValidation":
+			values, err := method.Inputs.Unpack(msg.Data[4:])
+			if err != nil {
+				return nil, err
+			}
+			var op UserOperation
+			abiConvert(values[0], &op)
+			info := returnInfo{PreOpGas: big.NewInt(60000), Prefund: new(big.Int), ValidAfter: new(big.Int), ValidUntil: new(big.Int)}
+			switch {
+			case bytes.Equal(op.Signature, sigRejected):
+				return revert("FailedOp", new(big.Int), "AA23 reverted (or OOG)")
+			case bytes.Equal(op.Signature, sigInvalid):
+				info.SigFailed = true
+			case bytes.Equal(op.Signature, sigExpiring):
+				info.ValidUntil.SetUint64(header.Time + 1)
+			}
+			stake := stakeInfo{new(big.Int), new(big.Int)}
+			return revert("ValidationResult", info, stake, stake, stake)
+
+		case "handleOps":
+			ops, err := decodeHandleOps(msg.Data)
+			if err != nil {
+				return nil, err
+			}
+			for i, op := range ops {
+				if bytes.Equal(op.Signature, sigDropped) {
+					return revert("FailedOp", big.NewInt(int64(i)), "AA25 invalid account nonce")
+				}
+			}
+			// Count the executed bundles in the storage of the entry point
+			count := statedb.GetState(*msg.To, common.Hash{}).Big()
+			statedb.SetState(*msg.To, common.Hash{}, common.BigToHash(count.Add(count, common.Big1)))
+			return &core.ExecutionResult{UsedGas: 100000}, nil
+		}
+		return nil, errors.New("unexpected call")
+	}
+}
+
+func newTestBundler(t *testing.T) (*Bundler, common.Address) {
+	key, _ := crypto.GenerateKey()
+	account := crypto.PubkeyToAddress(key.PublicKey)
+
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	if _, err := ks.ImportECDSA(key, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Unlock(accounts.Account{Address: account}, ""); err != nil {
+		t.Fatal(err)
+	}
+	am := accounts.NewManager(&accounts.Config{}, ks)
+	t.Cleanup(func() { am.Close() })
+
+	gspec := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			account:        {Balance: big.NewInt(params.Ether)},
+			EntryPointV06:  {Code: []byte{0x00}}, // executed by fakeEntryPoint
+			testEntryPoint: {Code: []byte{0x00}},
+		},
+		BaseFee:  big.NewInt(params.InitialBaseFee),
+		GasLimit: 30_000_000,
+	}
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(chain.Stop)
+
+	b, err := New(Config{EntryPoints: []common.Address{EntryPointV06}, Account: account, SenderLimit: 2}, chain, am)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(b.Close)
+	b.call = fakeEntryPoint(t)
+	return b, account
+}
+
+func newTestOperation(sender byte, nonce int64, fee int64, signature []byte) *UserOperation {
+	op := &UserOperation{
+		Sender:               common.Address{sender},
+		Nonce:                big.NewInt(nonce),
+		InitCode:             []byte{},
+		CallData:             []byte{0xb6, 0x1d, 0x27, 0xf6},
+		CallGasLimit:         big.NewInt(50000),
+		VerificationGasLimit: big.NewInt(100000),
+		MaxFeePerGas:         big.NewInt(fee),
+		MaxPriorityFeePerGas: big.NewInt(fee / 10),
+		PaymasterAndData:     []byte{},
+		Signature:            signature,
+	}
+	op.PreVerificationGas = new(big.Int).SetUint64(op.minPreVerificationGas())
+	return op
+}
+
+// Tests the operation hash against the packing of its fields as words.
+func TestUserOperationHash(t *testing.T) {
+	op := newTestOperation(1, 7, params.GWei, []byte{1, 2, 3})
+	op.InitCode = []byte{4, 5}
+	op.PaymasterAndData = common.Address{9}.Bytes()
+
+	word := func(v *big.Int) []byte { return common.LeftPadBytes(v.Bytes(), 32) }
+	packed := bytes.Join([][]byte{
+		common.LeftPadBytes(op.Sender.Bytes(), 32), word(op.Nonce),
+		crypto.Keccak256(op.InitCode), crypto.Keccak256(op.CallData),
+		word(op.CallGasLimit), word(op.VerificationGasLimit), word(op.PreVerificationGas),
+		word(op.MaxFeePerGas), word(op.MaxPriorityFeePerGas), crypto.Keccak256(op.PaymasterAndData),
+	}, nil)
+	chainID := big.NewInt(1337)
+	want := crypto.Keccak256Hash(crypto.Keccak256(packed), common.LeftPadBytes(EntryPointV06.Bytes(), 32), word(chainID))
+
+	if have := op.Hash(EntryPointV06, chainID); have != want {
+		t.Fatalf("hash mismatch: have %x, want %x", have, want)
+	}
+	if op.Hash(EntryPointV06, big.NewInt(1)) == want {
+		t.Fatal("hash doesn't commit to the chain")
+	}
+	if op.Paymaster() != (common.Address{9}) {
+		t.Fatalf("wrong paymaster %v", op.Paymaster())
+	}
+	// The verification gas of paymaster operations counts thrice
+	if have, want := op.requiredGas().Int64(), 3*op.VerificationGasLimit.Int64()+op.CallGasLimit.Int64()+op.PreVerificationGas.Int64(); have != want {
+		t.Fatalf("wrong required gas %d, want %d", have, want)
+	}
+}
+
+func TestUserOperationJSON(t *testing.T) {
+	op := newTestOperation(1, 7, params.GWei, []byte{1, 2, 3})
+	enc, err := json.Marshal(op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dec UserOperation
+	if err := json.Unmarshal(enc, &dec); err != nil {
+		t.Fatal(err)
+	}
+	if dec.Hash(EntryPointV06, common.Big1) != op.Hash(EntryPointV06, common.Big1) {
+		t.Fatalf("operation changed by encoding: %s", enc)
+	}
+	if err := json.Unmarshal([]byte(`{"sender":"0x0000000000000000000000000000000000000001"}`), &dec); err == nil {
+		t.Fatal("no error for missing fields")
+	}
+}
+
+func TestAddOperation(t *testing.T) {
+	b, _ := newTestBundler(t)
+
+	op := newTestOperation(1, 0, params.GWei, nil)
+	hash, err := b.Add(op, EntryPointV06)
+	if err != nil {
+		t.Fatalf("can't add operation: %v", err)
+	}
+	if have, ep := b.Get(hash); have != op || ep != EntryPointV06 {
+		t.Fatal("operation not pending")
+	}
+	tests := []struct {
+		op         *UserOperation
+		entryPoint common.Address
+		code       int
+	}{
+		{op, EntryPointV06, errCodeInvalidFields},                                           // known
+		{newTestOperation(1, 0, params.GWei+1, nil), EntryPointV06, errCodeInvalidFields},   // underpriced replacement
+		{newTestOperation(2, 0, params.GWei, nil), common.Address{1}, errCodeInvalidFields}, // unsupported entry point
+		{newTestOperation(2, 0, params.GWei, sigInvalid), EntryPointV06, errCodeSignature},
+		{newTestOperation(2, 0, params.GWei, sigRejected), EntryPointV06, errCodeSimulateValidation},
+		{newTestOperation(2, 0, params.GWei, sigExpiring), EntryPointV06, errCodeTimeRange},
+	}
+	for i, tt := range tests {
+		_, err := b.Add(tt.op, tt.entryPoint)
+		if rpcErr, ok := err.(rpc.Error); !ok || rpcErr.ErrorCode() != tt.code {
+			t.Errorf("test %d: wrong error %v, want code %d", i, err, tt.code)
+		}
+	}
+	// Low pre-verification gas is rejected
+	low := newTestOperation(2, 0, params.GWei, nil)
+	low.PreVerificationGas.Sub(low.PreVerificationGas, common.Big1)
+	if _, err := b.Add(low, EntryPointV06); err == nil {
+		t.Error("no error for low pre-verification gas")
+	}
+	// Replacing with bumped fees removes the old operation
+	replacement := newTestOperation(1, 0, params.GWei*2, nil)
+	if _, err := b.Add(replacement, EntryPointV06); err != nil {
+		t.Fatalf("can't replace operation: %v", err)
+	}
+	if have, _ := b.Get(hash); have != nil || b.Pending() != 1 {
+		t.Fatal("replaced operation still pending")
+	}
+	// The operations of a sender are limited
+	if _, err := b.Add(newTestOperation(1, 1, params.GWei, nil), EntryPointV06); err != nil {
+		t.Fatalf("can't add second operation: %v", err)
+	}
+	if _, err := b.Add(newTestOperation(1, 2, params.GWei, nil), EntryPointV06); err != errSenderLimit {
+		t.Fatalf("wrong error above sender limit: %v", err)
+	}
+}
+
+func TestBuildBundles(t *testing.T) {
+	b, account := newTestBundler(t)
+
+	var (
+		cheap   = newTestOperation(1, 0, 2*params.GWei, nil)
+		dropped = newTestOperation(2, 0, 5*params.GWei, sigDropped)
+		rich    = newTestOperation(3, 0, 10*params.GWei, nil)
+		next    = newTestOperation(3, 1, 10*params.GWei, nil) // second operation of a sender
+		poor    = newTestOperation(4, 0, params.GWei/2, nil)  // below the base fee
+	)
+	for _, op := range []*UserOperation{cheap, dropped, rich, next, poor} {
+		if _, err := b.Add(op, EntryPointV06); err != nil {
+			t.Fatal(err)
+		}
+	}
+	header := b.chain.CurrentBlock()
+	statedb, _ := b.chain.StateAt(header.Root)
+
+	bundles := b.BuildBundles(context.Background(), header, statedb)
+	if len(bundles) != 1 {
+		t.Fatalf("wrong number of bundles: %d", len(bundles))
+	}
+	tx := bundles[0]
+	if sender, err := types.Sender(types.LatestSigner(b.chain.Config()), tx); err != nil || sender != account {
+		t.Fatalf("bundle not signed by the bundler account: %v %v", sender, err)
+	}
+	if *tx.To() != EntryPointV06 || tx.GasFeeCap().Cmp(header.BaseFee) != 0 {
+		t.Fatalf("wrong bundle transaction: to %v, fee cap %v", tx.To(), tx.GasFeeCap())
+	}
+	ops, err := decodeHandleOps(tx.Data())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 2 || ops[0].Sender != rich.Sender || ops[1].Sender != cheap.Sender {
+		t.Fatalf("wrong bundled operations: %v", ops)
+	}
+	if have, _ := b.Get(dropped.Hash(EntryPointV06, b.chainID)); have != nil {
+		t.Fatal("rejected operation still pending")
+	}
+	if b.Pending() != 4 {
+		t.Fatalf("wrong number of pending operations: %d", b.Pending())
+	}
+}
+
+// Tests that the bundles of several entry points are simulated on the changes
+// of the previous ones.
+func TestBuildBundlesSequential(t *testing.T) {
+	b, _ := newTestBundler(t)
+	b.config.EntryPoints = []common.Address{EntryPointV06, testEntryPoint}
+
+	var simulated []common.Hash
+	call := b.call
+	b.call = func(header *types.Header, statedb *state.StateDB, msg *core.Message) (*core.ExecutionResult, error) {
+		if method, _ := entryPointABI.MethodById(msg.Data); method != nil && method.Name == "handleOps" {
+			simulated = append(simulated, statedb.GetState(EntryPointV06, common.Hash{}))
+		}
+		return call(header, statedb, msg)
+	}
+	var (
+		first   = newTestOperation(1, 0, params.GWei, nil)
+		dropped = newTestOperation(2, 0, params.GWei, sigDropped)
+		second  = newTestOperation(3, 0, params.GWei, nil)
+	)
+	for _, add := range []struct {
+		op         *UserOperation
+		entryPoint common.Address
+	}{{first, EntryPointV06}, {dropped, testEntryPoint}, {second, testEntryPoint}} {
+		if _, err := b.Add(add.op, add.entryPoint); err != nil {
+			t.Fatal(err)
+		}
+	}
+	header := b.chain.CurrentBlock()
+	statedb, _ := b.chain.StateAt(header.Root)
+
+	bundles := b.BuildBundles(context.Background(), header, statedb)
+	if len(bundles) != 2 || bundles[0].Nonce() != 0 || bundles[1].Nonce() != 1 {
+		t.Fatalf("wrong bundles: %v", bundles)
+	}
+	// The bundles of the second entry point, also the rejected one, are simulated
+	// after the first bundle.
+	want := []common.Hash{{}, common.BigToHash(common.Big1), common.BigToHash(common.Big1)}
+	if len(simulated) != len(want) {
+		t.Fatalf("wrong number of simulations: %d", len(simulated))
+	}
+	for i := range want {
+		if simulated[i] != want[i] {
+			t.Fatalf("simulation %d on wrong state: have %x, want %x", i, simulated[i], want[i])
+		}
+	}
+	if count := statedb.GetState(testEntryPoint, common.Hash{}); count != common.BigToHash(common.Big1) {
+		t.Fatalf("bundle not applied to the state: %x", count)
+	}
+}
+
+func TestRemoveIncluded(t *testing.T) {
+	b, _ := newTestBundler(t)
+
+	var (
+		included = newTestOperation(1, 0, params.GWei, nil)
+		other    = newTestOperation(2, 3, params.GWei, nil) // nonce used by another bundler
+		pending  = newTestOperation(3, 0, params.GWei, nil)
+	)
+	for _, op := range []*UserOperation{included, other, pending} {
+		if _, err := b.Add(op, EntryPointV06); err != nil {
+			t.Fatal(err)
+		}
+	}
+	event := func(hash common.Hash, sender common.Address, nonce int64) *types.Log {
+		data, _ := entryPointABI.Events["UserOperationEvent"].Inputs.NonIndexed().Pack(big.NewInt(nonce), true, big.NewInt(1), big.NewInt(1))
+		return &types.Log{
+			Address: EntryPointV06,
+			Topics:  []common.Hash{userOperationEventID, hash, common.BytesToHash(sender.Bytes()), {}},
+			Data:    data,
+		}
+	}
+	receipt := &types.Receipt{Logs: []*types.Log{
+		event(included.Hash(EntryPointV06, b.chainID), included.Sender, 0),
+		event(common.Hash{1}, other.Sender, 3),
+	}}
+	b.removeIncluded(types.Receipts{receipt})
+
+	if b.Pending() != 1 {
+		t.Fatalf("wrong number of pending operations: %d", b.Pending())
+	}
+	if op, _ := b.Get(pending.Hash(EntryPointV06, b.chainID)); op == nil {
+		t.Fatal("unrelated operation removed")
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bundler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EntryPointV06 is the address of the version 0.6 EntryPoint contract, deployed
+// at the same address on all chains.
+var EntryPointV06 = common.HexToAddress("0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789")
+
+const userOperationTuple = `{"name":"%s","type":"tuple%s","components":[
+	{"name":"sender","type":"address"},
+	{"name":"nonce","type":"uint256"},
+	{"name":"initCode","type":"bytes"},
+	{"name":"callData","type":"bytes"},
+	{"name":"callGasLimit","type":"uint256"},
+	{"name":"verificationGasLimit","type":"uint256"},
+	{"name":"preVerificationGas","type":"uint256"},
+	{"name":"maxFeePerGas","type":"uint256"},
+	{"name":"maxPriorityFeePerGas","type":"uint256"},
+	{"name":"paymasterAndData","type":"bytes"},
+	{"name":"signature","type":"bytes"}]}`
+
+const stakeInfoTuple = `{"name":"%s","type":"tuple","components":[
+	{"name":"stake","type":"uint256"},
+	{"name":"unstakeDelaySec","type":"uint256"}]}`
+
+// entryPointABI is the part of the EntryPoint interface used by the bundler.
+var entryPointABI = mustParseABI(`[
+	{"type":"function","name":"handleOps","inputs":[` + fmt.Sprintf(userOperationTuple, "ops", "[]") + `,{"name":"beneficiary","type":"address"}],"outputs":[]},
+	{"type":"function","name":"simulateValidation","inputs":[` + fmt.Sprintf(userOperationTuple, "userOp", "") + `],"outputs":[]},
+	{"type":"error","name":"FailedOp","inputs":[{"name":"opIndex","type":"uint256"},{"name":"reason","type":"string"}]},
+	{"type":"error","name":"ValidationResult","inputs":[
+		{"name":"returnInfo","type":"tuple","components":[
+			{"name":"preOpGas","type":"uint256"},
+			{"name":"prefund","type":"uint256"},
+			{"name":"sigFailed","type":"bool"},
+			{"name":"validAfter","type":"uint48"},
+			{"name":"validUntil","type":"uint48"},
+			{"name":"paymasterContext","type":"bytes"}]},
+		` + fmt.Sprintf(stakeInfoTuple, "senderInfo") + `,
+		` + fmt.Sprintf(stakeInfoTuple, "factoryInfo") + `,
+		` + fmt.Sprintf(stakeInfoTuple, "paymasterInfo") + `]},
+	{"type":"event","name":"UserOperationEvent","inputs":[
+		{"name":"userOpHash","type":"bytes32","indexed":true},
+		{"name":"sender","type":"address","indexed":true},
+		{"name":"paymaster","type":"address","indexed":true},
+		{"name":"nonce","type":"uint256","indexed":false},
+		{"name":"success","type":"bool","indexed":false},
+		{"name":"actualGasCost","type":"uint256","indexed":false},
+		{"name":"actualGasUsed","type":"uint256","indexed":false}]},
+	{"type":"event","name":"UserOperationRevertReason","inputs":[
+		{"name":"userOpHash","type":"bytes32","indexed":true},
+		{"name":"sender","type":"address","indexed":true},
+		{"name":"nonce","type":"uint256","indexed":false},
+		{"name":"revertReason","type":"bytes","indexed":false}]},
+	{"type":"event","name":"BeforeExecution","inputs":[]}
+]`)
+
+var (
+	userOperationEventID        = entryPointABI.Events["UserOperationEvent"].ID
+	userOperationRevertReasonID = entryPointABI.Events["UserOperationRevertReason"].ID
+	beforeExecutionID           = entryPointABI.Events["BeforeExecution"].ID
+)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// FailedOpError is the revert of the EntryPoint rejecting an operation of a
+// bundle or a simulation. The reason starts with an error code, e.g. "AA21
+// didn't pay prefund".
+type FailedOpError struct {
+	Index  uint64
+	Reason string
+}
+
+func (e *FailedOpError) Error() string {
+	return fmt.Sprintf("operation %d rejected: %s", e.Index, e.Reason)
+}
+
+// validationResult is the outcome of simulateValidation, which always reverts.
+type validationResult struct {
+	PreOpGas   *big.Int
+	Prefund    *big.Int
+	SigFailed  bool
+	ValidAfter uint64
+	ValidUntil uint64
+}
+
+// decodeValidationResult decodes the revert data of simulateValidation. Reverts
+// other than ValidationResult are returned as errors.
+func decodeValidationResult(revert []byte) (*validationResult, error) {
+	if err := decodeFailedOp(revert); err != nil {
+		return nil, err
+	}
+	method := entryPointABI.Errors["ValidationResult"]
+	if len(revert) < 4 || !bytes.Equal(revert[:4], method.ID[:4]) {
+		return nil, fmt.Errorf("unexpected simulation revert %x", revert)
+	}
+	values, err := method.Inputs.Unpack(revert[4:])
+	if err != nil {
+		return nil, err
+	}
+	var info struct {
+		PreOpGas         *big.Int
+		Prefund          *big.Int
+		SigFailed        bool
+		ValidAfter       *big.Int
+		ValidUntil       *big.Int
+		PaymasterContext []byte
+	}
+	if err := abiConvert(values[0], &info); err != nil {
+		return nil, err
+	}
+	return &validationResult{
+		PreOpGas:   info.PreOpGas,
+		Prefund:    info.Prefund,
+		SigFailed:  info.SigFailed,
+		ValidAfter: info.ValidAfter.Uint64(),
+		ValidUntil: info.ValidUntil.Uint64(),
+	}, nil
+}
+
+// decodeFailedOp returns the FailedOpError of the revert data, or nil if the
+// revert is not a FailedOp.
+func decodeFailedOp(revert []byte) error {
+	method := entryPointABI.Errors["FailedOp"]
+	if len(revert) < 4 || !bytes.Equal(revert[:4], method.ID[:4]) {
+		return nil
+	}
+	values, err := method.Inputs.Unpack(revert[4:])
+	if err != nil {
+		return err
+	}
+	return &FailedOpError{Index: values[0].(*big.Int).Uint64(), Reason: values[1].(string)}
+}
+
+// decodeHandleOps returns the operations of handleOps call data.
+func decodeHandleOps(data []byte) ([]UserOperation, error) {
+	method := entryPointABI.Methods["handleOps"]
+	if len(data) < 4 || !bytes.Equal(data[:4], method.ID) {
+		return nil, errors.New("not a handleOps call")
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	var ops []UserOperation
+	return ops, abiConvert(values[0], &ops)
+}
+
+// abiConvert converts the anonymous struct of an unpacked tuple to a matching
+// named type.
+func abiConvert(value interface{}, out interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("can't convert %T: %v", value, r)
+		}
+	}()
+	abi.ConvertType(value, out)
+	return nil
+}
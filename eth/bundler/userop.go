@@ -0,0 +1,163 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bundler
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+//go:generate go run github.com/fjl/gencodec -type UserOperation -field-override userOperationMarshaling -out gen_userop_json.go
+
+// UserOperation is an ERC-4337 user operation, in the format of the version 0.6
+// EntryPoint contract.
+type UserOperation struct {
+	Sender               common.Address `json:"sender" gencodec:"required"`
+	Nonce                *big.Int       `json:"nonce" gencodec:"required"`
+	InitCode             []byte         `json:"initCode" gencodec:"required"`
+	CallData             []byte         `json:"callData" gencodec:"required"`
+	CallGasLimit         *big.Int       `json:"callGasLimit" gencodec:"required"`
+	VerificationGasLimit *big.Int       `json:"verificationGasLimit" gencodec:"required"`
+	PreVerificationGas   *big.Int       `json:"preVerificationGas" gencodec:"required"`
+	MaxFeePerGas         *big.Int       `json:"maxFeePerGas" gencodec:"required"`
+	MaxPriorityFeePerGas *big.Int       `json:"maxPriorityFeePerGas" gencodec:"required"`
+	PaymasterAndData     []byte         `json:"paymasterAndData" gencodec:"required"`
+	Signature            []byte         `json:"signature" gencodec:"required"`
+}
+
+type userOperationMarshaling struct {
+	Nonce                *hexutil.Big
+	InitCode             hexutil.Bytes
+	CallData             hexutil.Bytes
+	CallGasLimit         *hexutil.Big
+	VerificationGasLimit *hexutil.Big
+	PreVerificationGas   *hexutil.Big
+	MaxFeePerGas         *hexutil.Big
+	MaxPriorityFeePerGas *hexutil.Big
+	PaymasterAndData     hexutil.Bytes
+	Signature            hexutil.Bytes
+}
+
+// Gas overheads of including an operation in a bundle, used to compute the
+// minimal pre-verification gas. They match the reference bundler.
+const (
+	bundleFixedGas   = 21000 // Transaction gas, shared by the operations of a bundle
+	perUserOpGas     = 18300 // EntryPoint overhead of an operation
+	perUserOpWordGas = 4     // EntryPoint overhead per word of an operation
+	bundleSizeHint   = 1     // Operations the fixed gas is shared with
+)
+
+var (
+	hashArguments = abi.Arguments{
+		{Type: abiType("address")}, {Type: abiType("uint256")}, {Type: abiType("bytes32")},
+		{Type: abiType("bytes32")}, {Type: abiType("uint256")}, {Type: abiType("uint256")},
+		{Type: abiType("uint256")}, {Type: abiType("uint256")}, {Type: abiType("uint256")},
+		{Type: abiType("bytes32")},
+	}
+	opHashArguments = abi.Arguments{{Type: abiType("bytes32")}, {Type: abiType("address")}, {Type: abiType("uint256")}}
+)
+
+func abiType(name string) abi.Type {
+	typ, err := abi.NewType(name, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
+
+// Hash returns the hash identifying the operation, which is signed by the owner
+// of the account. It commits to the entry point and the chain.
+func (op *UserOperation) Hash(entryPoint common.Address, chainID *big.Int) common.Hash {
+	packed, err := hashArguments.Pack(
+		op.Sender, op.Nonce, crypto.Keccak256Hash(op.InitCode), crypto.Keccak256Hash(op.CallData),
+		op.CallGasLimit, op.VerificationGasLimit, op.PreVerificationGas, op.MaxFeePerGas,
+		op.MaxPriorityFeePerGas, crypto.Keccak256Hash(op.PaymasterAndData),
+	)
+	if err != nil {
+		panic(err) // all fields are static types
+	}
+	enc, err := opHashArguments.Pack(crypto.Keccak256Hash(packed), entryPoint, chainID)
+	if err != nil {
+		panic(err)
+	}
+	return crypto.Keccak256Hash(enc)
+}
+
+// Paymaster returns the address of the paymaster sponsoring the operation, or
+// the zero address if the sender pays for it.
+func (op *UserOperation) Paymaster() common.Address {
+	if len(op.PaymasterAndData) < common.AddressLength {
+		return common.Address{}
+	}
+	return common.BytesToAddress(op.PaymasterAndData[:common.AddressLength])
+}
+
+// requiredGas returns the maximum gas the operation may use, which the sender or
+// the paymaster prefunds. The verification gas limit applies to up to three
+// calls when a paymaster is used: validation and the two postOp attempts.
+func (op *UserOperation) requiredGas() *big.Int {
+	mul := big.NewInt(1)
+	if op.Paymaster() != (common.Address{}) {
+		mul.SetInt64(3)
+	}
+	gas := new(big.Int).Mul(op.VerificationGasLimit, mul)
+	gas.Add(gas, op.CallGasLimit)
+	return gas.Add(gas, op.PreVerificationGas)
+}
+
+// gasPrice returns the gas price paid by the operation in a block with the given
+// base fee.
+func (op *UserOperation) gasPrice(baseFee *big.Int) *big.Int {
+	if baseFee == nil || op.MaxFeePerGas.Cmp(op.MaxPriorityFeePerGas) == 0 {
+		return op.MaxFeePerGas
+	}
+	return math.BigMin(op.MaxFeePerGas, new(big.Int).Add(baseFee, op.MaxPriorityFeePerGas))
+}
+
+// minPreVerificationGas returns the lowest pre-verification gas covering the
+// calldata and EntryPoint overheads of the operation, which are not metered by
+// the EntryPoint. Missing gas fields are counted as non-zero words.
+func (op *UserOperation) minPreVerificationGas() uint64 {
+	filled := *op
+	for _, field := range []**big.Int{&filled.Nonce, &filled.CallGasLimit, &filled.VerificationGasLimit, &filled.PreVerificationGas, &filled.MaxFeePerGas, &filled.MaxPriorityFeePerGas} {
+		if *field == nil {
+			*field = new(big.Int).SetUint64(params.TxGas)
+		}
+	}
+	packed, err := entryPointABI.Methods["handleOps"].Inputs[:1].Pack([]UserOperation{filled})
+	if err != nil {
+		return math.MaxUint64
+	}
+	// Drop the offset and length of the array
+	packed = packed[2*32:]
+
+	var calldata uint64
+	for _, b := range packed {
+		if b == 0 {
+			calldata += params.TxDataZeroGas
+		} else {
+			calldata += params.TxDataNonZeroGasEIP2028
+		}
+	}
+	return bundleFixedGas/bundleSizeHint + calldata + perUserOpGas + perUserOpWordGas*((uint64(len(packed))+31)/32)
+}
@@ -20,7 +20,9 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"errors"
+	"fmt"
 	"math/big"
+	"slices"
 	"sync"
 	"time"
 
@@ -83,6 +85,19 @@ type SimulatedBeacon struct {
 	engineAPI          *ConsensusAPI
 	curForkchoiceState engine.ForkchoiceStateV1
 	lastBlockTime      uint64
+
+	clockOffset    time.Duration // Shift of the block timestamps from the wall clock
+	nextBlockTime  uint64        // Timestamp of the next block if set, zero otherwise
+	snapshots      []snapshot    // Snapshots of the chain, in order of creation
+	nextSnapshotID int
+}
+
+// snapshot is a state of the chain recorded by Snapshot.
+type snapshot struct {
+	id            int
+	head          common.Hash
+	clockOffset   time.Duration
+	nextBlockTime uint64
 }
 
 // NewSimulatedBeacon constructs a new simulated beacon chain.
@@ -212,9 +227,19 @@ func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal, timestamp u
 		return err
 	}
 	c.lastBlockTime = payload.Timestamp
+	c.nextBlockTime = 0
 	return nil
 }
 
+// timestamp returns the timestamp of the next block, the one set by
+// SetNextBlockTimestamp if any, or the shifted wall clock.
+func (c *SimulatedBeacon) timestamp() uint64 {
+	if c.nextBlockTime != 0 {
+		return c.nextBlockTime
+	}
+	return uint64(time.Now().Add(c.clockOffset).Unix())
+}
+
 // loop runs the block production loop for non-zero period configuration
 func (c *SimulatedBeacon) loop() {
 	timer := time.NewTimer(0)
@@ -224,7 +249,7 @@ func (c *SimulatedBeacon) loop() {
 			return
 		case <-timer.C:
 			withdrawals := c.withdrawals.gatherPending(10)
-			if err := c.sealBlock(withdrawals, uint64(time.Now().Unix())); err != nil {
+			if err := c.sealBlock(withdrawals, c.timestamp()); err != nil {
 				log.Warn("Error performing sealing work", "err", err)
 			} else {
 				timer.Reset(time.Second * time.Duration(c.period))
@@ -261,7 +286,7 @@ func (c *SimulatedBeacon) setCurrentState(headHash, finalizedHash common.Hash) {
 // Commit seals a block on demand.
 func (c *SimulatedBeacon) Commit() common.Hash {
 	withdrawals := c.withdrawals.gatherPending(10)
-	if err := c.sealBlock(withdrawals, uint64(time.Now().Unix())); err != nil {
+	if err := c.sealBlock(withdrawals, c.timestamp()); err != nil {
 		log.Warn("Error performing sealing work", "err", err)
 	}
 	return c.eth.BlockChain().CurrentBlock().Hash()
@@ -292,8 +317,11 @@ func (c *SimulatedBeacon) Fork(parentHash common.Hash) error {
 	return c.eth.BlockChain().SetHead(parent.NumberU64())
 }
 
-// AdjustTime creates a new block with an adjusted timestamp.
+// AdjustTime creates a new block with an adjusted timestamp. The timestamps of
+// the following blocks are shifted by the same adjustment.
 func (c *SimulatedBeacon) AdjustTime(adjustment time.Duration) error {
+	// Ensure no pending transactions.
+	c.eth.TxPool().Sync()
 	if len(c.eth.TxPool().Pending(txpool.PendingFilter{})) != 0 {
 		return errors.New("could not adjust time on non-empty block")
 	}
@@ -302,7 +330,64 @@ func (c *SimulatedBeacon) AdjustTime(adjustment time.Duration) error {
 		return errors.New("parent not found")
 	}
 	withdrawals := c.withdrawals.gatherPending(10)
-	return c.sealBlock(withdrawals, parent.Time+uint64(adjustment))
+	if err := c.sealBlock(withdrawals, parent.Time+uint64(adjustment/time.Second)); err != nil {
+		return err
+	}
+	c.clockOffset += adjustment
+	return nil
+}
+
+// SetNextBlockTimestamp sets the timestamp of the next block, the timestamps of
+// the following blocks continuing from it.
+func (c *SimulatedBeacon) SetNextBlockTimestamp(timestamp uint64) error {
+	if timestamp <= c.lastBlockTime {
+		return fmt.Errorf("timestamp %d not after the last block timestamp %d", timestamp, c.lastBlockTime)
+	}
+	c.nextBlockTime = timestamp
+	c.clockOffset = time.Until(time.Unix(int64(timestamp), 0))
+	return nil
+}
+
+// Snapshot records the current state of the chain, returning the ID to restore
+// it with Revert.
+func (c *SimulatedBeacon) Snapshot() int {
+	id := c.nextSnapshotID
+	c.nextSnapshotID++
+
+	c.snapshots = append(c.snapshots, snapshot{
+		id:            id,
+		head:          c.eth.BlockChain().CurrentBlock().Hash(),
+		clockOffset:   c.clockOffset,
+		nextBlockTime: c.nextBlockTime,
+	})
+	return id
+}
+
+// Revert restores the chain to a snapshot, discarding the blocks sealed and the
+// transactions added since. The snapshot and the ones taken after it are
+// discarded too.
+func (c *SimulatedBeacon) Revert(id int) error {
+	i := slices.IndexFunc(c.snapshots, func(s snapshot) bool { return s.id == id })
+	if i < 0 {
+		return fmt.Errorf("unknown snapshot %d", id)
+	}
+	s := c.snapshots[i]
+	head := c.eth.BlockChain().GetHeaderByHash(s.head)
+	if head == nil {
+		return errors.New("snapshot head not found")
+	}
+	if err := c.eth.BlockChain().SetHead(head.Number.Uint64()); err != nil {
+		return err
+	}
+	// Drop the pending transactions, and the ones of the discarded blocks that
+	// the pool reinjects after the rewind
+	c.eth.TxPool().Sync()
+	c.Rollback()
+
+	c.snapshots = c.snapshots[:i]
+	c.lastBlockTime = head.Time
+	c.clockOffset, c.nextBlockTime = s.clockOffset, s.nextBlockTime
+	return nil
 }
 
 func RegisterSimulatedBeaconAPIs(stack *node.Node, sim *SimulatedBeacon) {
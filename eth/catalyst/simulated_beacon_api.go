@@ -18,7 +18,6 @@ package catalyst
 
 import (
 	"context"
-	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
@@ -43,7 +42,7 @@ func (a *api) loop() {
 			return
 		case w := <-a.sim.withdrawals.pending:
 			withdrawals := append(a.sim.withdrawals.gatherPending(9), w)
-			if err := a.sim.sealBlock(withdrawals, uint64(time.Now().Unix())); err != nil {
+			if err := a.sim.sealBlock(withdrawals, a.sim.timestamp()); err != nil {
 				log.Warn("Error performing sealing work", "err", err)
 			}
 		case <-newTxs:
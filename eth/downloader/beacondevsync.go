@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -40,12 +41,22 @@ func (d *Downloader) BeaconDevSync(mode SyncMode, hash common.Hash, stop chan st
 	log.Warn("This is unhealthy for a live node!")
 	log.Warn("----------------------------------")
 
+	header, err := d.fetchSyncTarget(hash, stop)
+	if err != nil {
+		return err
+	}
+	return d.BeaconSync(mode, header, header)
+}
+
+// fetchSyncTarget retrieves the header of a block from the network, retrying until
+// a peer delivers it or stop is closed.
+func (d *Downloader) fetchSyncTarget(hash common.Hash, stop chan struct{}) (*types.Header, error) {
 	log.Info("Waiting for peers to retrieve sync target")
 	for {
 		// If the node is going down, unblock
 		select {
 		case <-stop:
-			return errors.New("stop requested")
+			return nil, errors.New("stop requested")
 		default:
 		}
 		// Pick a random peer to sync from and keep retrying if none are yet
@@ -76,6 +87,6 @@ func (d *Downloader) BeaconDevSync(mode SyncMode, hash common.Hash, stop chan st
 			time.Sleep(time.Second)
 			continue
 		}
-		return d.BeaconSync(mode, headers[0], headers[0])
+		return headers[0], nil
 	}
 }
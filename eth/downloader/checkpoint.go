@@ -0,0 +1,150 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var errCheckpointMismatch = errors.New("checkpoint number mismatch")
+
+// checkpointRetargetInterval is the time between two attempts to move the sync
+// target of a checkpoint sync to the head of the best peer.
+var checkpointRetargetInterval = 10 * time.Second
+
+// CheckpointSync syncs the chain to a trusted checkpoint block, for networks
+// without a consensus client providing sync targets. The checkpoint header is
+// retrieved from the network, and the chain is synced backwards from it like in
+// beacon sync. With snap sync, the state at the checkpoint becomes available as
+// soon as it is downloaded, while older headers and blocks are backfilled.
+//
+// As there is no consensus client announcing new heads either, the sync target is
+// then periodically moved to the head of the best peer, so the node keeps up with
+// the chain. The mode function is consulted for every new target, as the sync
+// mode changes once snap sync completes. CheckpointSync blocks until stop is
+// closed or the downloader is terminated.
+func (d *Downloader) CheckpointSync(mode func() SyncMode, hash common.Hash, number uint64, stop chan struct{}) error {
+	target := d.blockchain.CurrentBlock()
+	if target.Number.Uint64() >= number {
+		// The checkpoint was reached before, only follow the chain.
+		if !d.blockchain.HasBlock(hash, number) {
+			return fmt.Errorf("%w: local chain doesn't contain block #%d %x", errCheckpointMismatch, number, hash)
+		}
+	} else {
+		log.Info("Syncing to trusted checkpoint", "number", number, "hash", hash)
+
+		header, err := d.fetchSyncTarget(hash, stop)
+		if err != nil {
+			return err
+		}
+		if header.Number.Uint64() != number {
+			return fmt.Errorf("%w: block %x is #%d, configured #%d", errCheckpointMismatch, hash, header.Number, number)
+		}
+		if err := d.BeaconSync(mode(), header, header); err != nil {
+			return err
+		}
+		target = header
+	}
+
+	timer := time.NewTimer(checkpointRetargetInterval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-d.quitCh:
+			return nil
+		case <-timer.C:
+		}
+		current := mode()
+		if head := d.followPeer(current, target, stop); head != target {
+			target = head
+		} else {
+			// The sync cycle may have terminated right before the target was last
+			// extended, in which case announcing it again restarts backfilling.
+			local := d.blockchain.CurrentBlock()
+			if current == SnapSync {
+				local = d.blockchain.CurrentSnapBlock()
+			}
+			if local.Number.Cmp(target.Number) < 0 {
+				d.BeaconExtend(current, target)
+			}
+		}
+		timer.Reset(checkpointRetargetInterval)
+	}
+}
+
+// followPeer retrieves the headers following target from the peer with the highest
+// total difficulty, and extends the sync target with them one by one, as a consensus
+// client would announce them. Extending keeps the running sync cycle going, only if
+// the peer's chain forked off below target, the sync is restarted on the new chain.
+// The last header the sync target was moved to is returned.
+func (d *Downloader) followPeer(mode SyncMode, target *types.Header, stop chan struct{}) *types.Header {
+	var (
+		best   *peerConnection
+		bestTd *big.Int
+	)
+	for _, p := range d.peers.AllPeers() {
+		if _, td := p.peer.Head(); best == nil || (td != nil && (bestTd == nil || td.Cmp(bestTd) > 0)) {
+			best, bestTd = p, td
+		}
+	}
+	if best == nil {
+		return target
+	}
+	for {
+		headers, _, err := d.fetchHeadersByNumber(best, target.Number.Uint64()+1, MaxHeaderFetch, 0, false, stop)
+		if err != nil {
+			best.log.Debug("Failed to retrieve headers to follow", "err", err)
+			return target
+		}
+		for i, header := range headers {
+			if header.Number.Uint64() != target.Number.Uint64()+1 {
+				best.log.Debug("Invalid headers to follow", "number", header.Number, "want", target.Number.Uint64()+1)
+				return target
+			}
+			if header.ParentHash == target.Hash() && d.BeaconExtend(mode, header) == nil {
+				target = header
+				continue
+			}
+			// The peer's chain doesn't extend the sync target, restart from its
+			// latest header instead.
+			head := headers[len(headers)-1]
+			if head.Number.Uint64() != target.Number.Uint64()+uint64(len(headers)-i) {
+				best.log.Debug("Invalid headers to follow", "number", head.Number, "want", target.Number.Uint64()+uint64(len(headers)-i))
+				return target
+			}
+			log.Debug("Restarting checkpoint sync", "number", head.Number, "hash", head.Hash())
+			if err := d.BeaconSync(mode, head, nil); err != nil {
+				log.Warn("Failed to move checkpoint sync target", "number", head.Number, "hash", head.Hash(), "err", err)
+				return target
+			}
+			target = head
+			break
+		}
+		if len(headers) < MaxHeaderFetch {
+			return target
+		}
+	}
+}
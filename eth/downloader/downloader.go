@@ -69,8 +69,9 @@ var (
 	errNoPivotHeader           = errors.New("pivot header is not found")
 )
 
-// peerDropFn is a callback type for dropping a peer detected as malicious.
-type peerDropFn func(id string)
+// peerDropFn is a callback type for dropping a peer detected as malicious. The
+// penalty is one of the p2p.Penalty constants, lowering the peer's score.
+type peerDropFn func(id string, penalty int)
 
 // badBlockFn is a callback for the async beacon sync to notify the caller that
 // the origin header requested to sync to, produced a chain with a bad block.
@@ -93,9 +94,13 @@ type Downloader struct {
 	stateDB ethdb.Database // Database to state sync into (and deduplicate via)
 
 	// Statistics
-	syncStatsChainOrigin uint64       // Origin block number where syncing started at
-	syncStatsChainHeight uint64       // Highest block number known when syncing started
-	syncStatsLock        sync.RWMutex // Lock protecting the sync stats fields
+	syncStatsChainOrigin uint64         // Origin block number where syncing started at
+	syncStatsChainHeight uint64         // Highest block number known when syncing started
+	syncStatsLock        sync.RWMutex   // Lock protecting the sync stats fields
+	syncStatsBodies      atomic.Uint64  // Number of block bodies downloaded
+	syncStatsReceipts    atomic.Uint64  // Number of block receipts downloaded
+	syncStatsSamples     []statusSample // Recent samples of the sync progress, for rate calculation
+	syncStatsSamplesLock sync.Mutex     // Lock protecting the progress samples
 
 	blockchain BlockChain
 
@@ -115,6 +120,8 @@ type Downloader struct {
 	// Skeleton sync
 	skeleton *skeleton // Header skeleton to backfill the chain with (eth2 mode)
 
+	pauser syncPauser // Operator requested suspension of the sync
+
 	// State sync
 	pivotHeader *types.Header // Pivot block header to dynamically push the syncing state root
 	pivotLock   sync.RWMutex  // Lock protecting pivot header reads from updates
@@ -208,8 +215,13 @@ func New(stateDb ethdb.Database, mux *event.TypeMux, chain BlockChain, dropPeer
 		stateSyncStart: make(chan *stateSync),
 		syncStartBlock: chain.CurrentSnapBlock().Number.Uint64(),
 	}
+	// Seed the progress samples, so the rates are measured from startup until
+	// enough samples are collected.
+	dl.syncStatsSamples = []statusSample{{time: time.Now()}}
+
 	// Create the post-merge skeleton syncer and start the process
 	dl.skeleton = newSkeleton(stateDb, dl.peers, dropPeer, newBeaconBackfiller(dl, success))
+	dl.skeleton.pauser = &dl.pauser
 
 	go dl.stateFetcher()
 	return dl
@@ -243,6 +255,9 @@ func (d *Downloader) Progress() ethereum.SyncProgress {
 		StartingBlock:       d.syncStatsChainOrigin,
 		CurrentBlock:        current,
 		HighestBlock:        d.syncStatsChainHeight,
+		SyncedHeaders:       d.skeleton.pulled.Load(),
+		SyncedBodies:        d.syncStatsBodies.Load(),
+		SyncedReceipts:      d.syncStatsReceipts.Load(),
 		SyncedAccounts:      progress.AccountSynced,
 		SyncedAccountBytes:  uint64(progress.AccountBytes),
 		SyncedBytecodes:     progress.BytecodeSynced,
@@ -751,6 +766,10 @@ func (d *Downloader) importBlockResults(results []*fetchResult) error {
 		return errCancelContentProcessing
 	default:
 	}
+	// Don't start the import while the sync is paused
+	if err := d.waitResumed(); err != nil {
+		return err
+	}
 	// Retrieve a batch of results to import
 	first, last := results[0].Header, results[len(results)-1].Header
 	log.Debug("Inserting downloaded chain", "items", len(results),
@@ -972,6 +991,10 @@ func (d *Downloader) commitSnapSyncData(results []*fetchResult, stateSync *state
 		}
 	default:
 	}
+	// Don't start the import while the sync is paused
+	if err := d.waitResumed(); err != nil {
+		return err
+	}
 	// Retrieve the batch of results to import
 	first, last := results[0].Header, results[len(results)-1].Header
 	log.Debug("Inserting snap-sync blocks", "items", len(results),
@@ -17,6 +17,7 @@
 package downloader
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"sync"
@@ -110,7 +111,7 @@ func (dl *downloadTester) newPeer(id string, version uint, blocks []*types.Block
 }
 
 // dropPeer simulates a hard peer removal from the connection pool.
-func (dl *downloadTester) dropPeer(id string) {
+func (dl *downloadTester) dropPeer(id string, penalty int) {
 	dl.lock.Lock()
 	defer dl.lock.Unlock()
 
@@ -639,6 +640,35 @@ func checkProgress(t *testing.T, d *Downloader, stage string, want ethereum.Sync
 	}
 }
 
+// Tests that the sync status reports the rates and ETAs of the sync stages, and
+// that stalled stages are reported with a zero rate.
+func TestSyncStatusRates(t *testing.T) {
+	tester := newTester(t)
+	defer tester.terminate()
+
+	d := tester.downloader
+	start := d.syncStatsSamples[0].time
+	if status := d.syncStatus(start); status.Headers.Done != 0 || status.Headers.Rate != 0 {
+		t.Fatalf("pristine status has headers progress: %+v", status.Headers)
+	}
+	d.skeleton.pulled.Add(50)
+	d.skeleton.left.Store(150)
+	d.syncStatsBodies.Add(20)
+
+	status := d.syncStatus(start.Add(10 * time.Second))
+	if status.Headers.Rate != 5 || status.Headers.ETA != 30 {
+		t.Fatalf("wrong headers status: %+v", status.Headers)
+	}
+	if status.Bodies.Rate != 2 || status.Bodies.ETA != 0 {
+		t.Fatalf("wrong bodies status: %+v", status.Bodies)
+	}
+	// Without progress for longer than the rate window, the rates drop to zero.
+	status = d.syncStatus(start.Add(10*time.Second + statusRateWindow))
+	if status.Headers.Done != 50 || status.Headers.Rate != 0 || status.Headers.ETA != 0 {
+		t.Fatalf("wrong stalled headers status: %+v", status.Headers)
+	}
+}
+
 // Tests that peers below a pre-configured checkpoint block are prevented from
 // being fast-synced from, avoiding potential cheap eclipse attacks.
 func TestBeaconSync68Full(t *testing.T) { testBeaconSync(t, eth.ETH68, FullSync) }
@@ -685,6 +715,110 @@ func testBeaconSync(t *testing.T, protocol uint, mode SyncMode) {
 	}
 }
 
+// Tests that the chain can be synced from a trusted checkpoint and is followed
+// afterwards, and that a checkpoint with a wrong number is rejected.
+func TestCheckpointSync68Full(t *testing.T) { testCheckpointSync(t, eth.ETH68, FullSync) }
+func TestCheckpointSync68Snap(t *testing.T) { testCheckpointSync(t, eth.ETH68, SnapSync) }
+
+func testCheckpointSync(t *testing.T, protocol uint, mode SyncMode) {
+	defer func(interval time.Duration) { checkpointRetargetInterval = interval }(checkpointRetargetInterval)
+	checkpointRetargetInterval = 100 * time.Millisecond
+
+	var synced atomic.Bool
+	success := make(chan struct{}, 1)
+	tester := newTesterWithNotification(t, func() {
+		synced.Store(true)
+		select {
+		case success <- struct{}{}:
+		default:
+		}
+	})
+	defer tester.terminate()
+
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	tester.newPeer("peer", protocol, chain.blocks[1:])
+
+	// The checkpoint must be recent enough for the peer to serve its state.
+	// Once it is reached, the node switches to full sync like the handler does.
+	checkpoint := chain.blocks[len(chain.blocks)-10]
+	syncMode := func() SyncMode {
+		if synced.Load() {
+			return FullSync
+		}
+		return mode
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+
+	err := tester.downloader.CheckpointSync(syncMode, checkpoint.Hash(), checkpoint.NumberU64()+1, stop)
+	if !errors.Is(err, errCheckpointMismatch) {
+		t.Fatalf("wrong error for mismatching checkpoint number: %v", err)
+	}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- tester.downloader.CheckpointSync(syncMode, checkpoint.Hash(), checkpoint.NumberU64(), stop)
+	}()
+	select {
+	case <-success:
+		if hash := tester.chain.GetCanonicalHash(checkpoint.NumberU64()); hash != checkpoint.Hash() {
+			t.Fatalf("checkpoint not synced: have %x, want %x", hash, checkpoint.Hash())
+		}
+	case err := <-errc:
+		t.Fatalf("Checkpoint sync failed: %v", err)
+	case <-time.NewTimer(time.Second * 3).C:
+		t.Fatalf("Failed to sync chain in three seconds")
+	}
+	// The sync target moves on to the head of the peer.
+	want := chain.blocks[len(chain.blocks)-1]
+	for deadline := time.Now().Add(3 * time.Second); tester.chain.CurrentBlock().Hash() != want.Hash(); {
+		if time.Now().After(deadline) {
+			t.Fatalf("head not followed: have %d, want %d", tester.chain.CurrentBlock().Number, want.NumberU64())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Tests that a paused sync doesn't make progress until it is resumed.
+func TestPauseSync(t *testing.T) {
+	success := make(chan struct{})
+	tester := newTesterWithNotification(t, func() {
+		close(success)
+	})
+	defer tester.terminate()
+
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	tester.newPeer("peer", eth.ETH68, chain.blocks[1:])
+
+	if !tester.downloader.Pause() {
+		t.Fatal("pause failed")
+	}
+	if tester.downloader.Pause() {
+		t.Fatal("pause succeeded on paused sync")
+	}
+	if err := tester.downloader.BeaconSync(FullSync, chain.blocks[len(chain.blocks)-1].Header(), nil); err != nil {
+		t.Fatalf("Failed to beacon sync chain: %v", err)
+	}
+	select {
+	case <-success:
+		t.Fatal("paused sync completed")
+	case <-time.After(200 * time.Millisecond):
+	}
+	if pulled := tester.downloader.skeleton.pulled.Load(); pulled != 0 {
+		t.Fatalf("paused sync downloaded %d headers", pulled)
+	}
+	if !tester.downloader.Resume() {
+		t.Fatal("resume failed")
+	}
+	select {
+	case <-success:
+		if bs := int(tester.chain.CurrentBlock().Number.Uint64()) + 1; bs != len(chain.blocks) {
+			t.Fatalf("synchronised blocks mismatch: have %v, want %v", bs, len(chain.blocks))
+		}
+	case <-time.NewTimer(time.Second * 3).C:
+		t.Fatalf("Failed to sync chain in three seconds")
+	}
+}
+
 // Tests that synchronisation progress (origin block number, current block number
 // and highest block number) is tracked and updated correctly.
 func TestSyncProgress68Full(t *testing.T) { testSyncProgress(t, eth.ETH68, FullSync) }
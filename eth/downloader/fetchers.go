@@ -28,11 +28,28 @@ import (
 // handles all the cancellation, interruption and timeout mechanisms of a data
 // retrieval to allow blocking API calls.
 func (d *Downloader) fetchHeadersByHash(p *peerConnection, hash common.Hash, amount int, skip int, reverse bool) ([]*types.Header, []common.Hash, error) {
+	return d.requestHeaders(p, d.cancelCh, func(sink chan *eth.Response) (*eth.Request, error) {
+		return p.peer.RequestHeadersByHash(hash, amount, skip, reverse, sink)
+	})
+}
+
+// fetchHeadersByNumber is a blocking version of Peer.RequestHeadersByNumber, see
+// fetchHeadersByHash. As it is used outside of sync cycles, it is interrupted by
+// the given cancel channel instead of the one of the running sync.
+func (d *Downloader) fetchHeadersByNumber(p *peerConnection, number uint64, amount int, skip int, reverse bool, cancel chan struct{}) ([]*types.Header, []common.Hash, error) {
+	return d.requestHeaders(p, cancel, func(sink chan *eth.Response) (*eth.Request, error) {
+		return p.peer.RequestHeadersByNumber(number, amount, skip, reverse, sink)
+	})
+}
+
+// requestHeaders sends a header request created by the given function and waits
+// for its response.
+func (d *Downloader) requestHeaders(p *peerConnection, cancel chan struct{}, request func(sink chan *eth.Response) (*eth.Request, error)) ([]*types.Header, []common.Hash, error) {
 	// Create the response sink and send the network request
 	start := time.Now()
 	resCh := make(chan *eth.Response)
 
-	req, err := p.peer.RequestHeadersByHash(hash, amount, skip, reverse, resCh)
+	req, err := request(resCh)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -45,7 +62,7 @@ func (d *Downloader) fetchHeadersByHash(p *peerConnection, hash common.Hash, amo
 	defer timeoutTimer.Stop()
 
 	select {
-	case <-d.cancelCh:
+	case <-cancel:
 		return nil, nil, errCanceled
 
 	case <-timeoutTimer.C:
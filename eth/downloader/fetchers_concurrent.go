@@ -25,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/prque"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
 )
 
 // timeoutGracePeriod is the amount of time to allow for a peer to deliver a
@@ -126,12 +127,14 @@ func (d *Downloader) concurrentFetch(queue typedQueue) error {
 	// Prepare the queue and fetch block parts until the block header fetcher's done
 	finished := false
 	for {
-		// If there's nothing more to fetch, wait or terminate
+		// If there's nothing more to fetch, wait or terminate. While the sync
+		// is paused, only wait for the requests in flight.
+		resumed := d.pauser.paused()
 		if queue.pending() == 0 {
 			if len(pending) == 0 && finished {
 				return nil
 			}
-		} else {
+		} else if resumed == nil {
 			// Send a download request to all idle peers, until throttled
 			var (
 				idles []*peerConnection
@@ -148,7 +151,7 @@ func (d *Downloader) concurrentFetch(queue typedQueue) error {
 						// permitted it, consider the peer malicious attempting to
 						// stall the sync.
 						peer.log.Warn("Peer stalling, dropping", "waited", common.PrettyDuration(waited))
-						d.dropPeer(peer.id)
+						d.dropPeer(peer.id, p2p.PenaltyTimeout)
 					}
 				}
 			}
@@ -205,6 +208,9 @@ func (d *Downloader) concurrentFetch(queue typedQueue) error {
 			// be dropped when they arrive
 			return errCanceled
 
+		case <-resumed:
+			// Sync resumed, loop back to the entry point for task assignment
+
 		case event := <-peering:
 			// A peer joined or left, the tasks queue and allocations need to be
 			// checked for potential assignment or reassignment
@@ -298,7 +304,7 @@ func (d *Downloader) concurrentFetch(queue typedQueue) error {
 			if fails > 2 {
 				queue.updateCapacity(peer, 0, 0)
 			} else {
-				d.dropPeer(peer.id)
+				d.dropPeer(peer.id, p2p.PenaltyTimeout)
 			}
 
 		case res := <-responses:
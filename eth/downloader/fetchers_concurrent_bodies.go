@@ -92,6 +92,7 @@ func (q *bodyQueue) deliver(peer *peerConnection, packet *eth.Response) (int, er
 	hashsets := packet.Meta.([][]common.Hash) // {txs hashes, uncle hashes, withdrawal hashes}
 
 	accepted, err := q.queue.DeliverBodies(peer.id, txs, hashsets[0], uncles, hashsets[1], withdrawals, hashsets[2])
+	q.syncStatsBodies.Add(uint64(accepted))
 	switch {
 	case err == nil && len(txs) == 0:
 		peer.log.Trace("Requested bodies delivered")
@@ -0,0 +1,113 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// syncPauser suspends the sync loops on request of the operator. Paused loops
+// don't send new requests or import new blocks, but finish the ones in flight.
+type syncPauser struct {
+	lock    sync.Mutex
+	resumed chan struct{} // Closed on resume, nil if not paused
+}
+
+// paused returns a channel which is closed when the sync is resumed, or nil if
+// the sync isn't paused. Waiting on the nil channel blocks forever, so it can be
+// selected on unconditionally.
+func (p *syncPauser) paused() <-chan struct{} {
+	if p == nil {
+		return nil
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.resumed
+}
+
+func (p *syncPauser) pause() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.resumed != nil {
+		return false
+	}
+	p.resumed = make(chan struct{})
+	return true
+}
+
+func (p *syncPauser) resume() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.resumed == nil {
+		return false
+	}
+	close(p.resumed)
+	p.resumed = nil
+	return true
+}
+
+// Pause suspends the sync, e.g. for maintenance on the database. Requests and
+// block imports in flight are finished, but no new ones are started until the
+// sync is resumed. It returns false if the sync is already paused.
+func (d *Downloader) Pause() bool {
+	if !d.pauser.pause() {
+		return false
+	}
+	d.SnapSyncer.SetPaused(true)
+	log.Info("Chain sync paused")
+	return true
+}
+
+// Resume continues a paused sync. It returns false if the sync isn't paused.
+func (d *Downloader) Resume() bool {
+	if !d.pauser.resume() {
+		return false
+	}
+	d.SnapSyncer.SetPaused(false)
+	log.Info("Chain sync resumed")
+	return true
+}
+
+// Paused reports whether the sync is paused.
+func (d *Downloader) Paused() bool {
+	return d.pauser.paused() != nil
+}
+
+// waitResumed blocks while the sync is paused.
+func (d *Downloader) waitResumed() error {
+	resumed := d.pauser.paused()
+	if resumed == nil {
+		return nil
+	}
+	d.cancelLock.RLock()
+	cancel := d.cancelCh
+	d.cancelLock.RUnlock()
+
+	select {
+	case <-resumed:
+		return nil
+	case <-cancel:
+		return errCanceled
+	case <-d.quitCh:
+		return errCancelContentProcessing
+	}
+}
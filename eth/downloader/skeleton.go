@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"math/rand"
 	"sort"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -30,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
 )
 
 // scratchHeaders is the number of headers to store in a scratch space to allow
@@ -206,10 +208,13 @@ type skeleton struct {
 	idles map[string]*peerConnection // Set of idle peers in the current sync cycle
 	drop  peerDropFn                 // Drops a peer for misbehaving
 
+	pauser *syncPauser // Suspends header requests on operator request (nil if not pausable)
+
 	progress *skeletonProgress // Sync progress tracker for resumption and metrics
 	started  time.Time         // Timestamp when the skeleton syncer was created
 	logged   time.Time         // Timestamp when progress was last logged to the user
-	pulled   uint64            // Number of headers downloaded in this run
+	pulled   atomic.Uint64     // Number of headers downloaded in this run
+	left     atomic.Uint64     // Number of headers left to download in the current sync cycle
 
 	scratchSpace  []*types.Header // Scratch space to accumulate headers in (first = recent)
 	scratchOwners []string        // Peer IDs owning chunks of the scratch space (pend or delivered)
@@ -439,12 +444,17 @@ func (s *skeleton) sync(head *types.Header) (*types.Header, error) {
 		s.syncStarting()
 	}
 	for {
-		// Something happened, try to assign new tasks to any idle peers
-		if !linked {
+		// Something happened, try to assign new tasks to any idle peers, unless
+		// the sync is paused
+		resumed := s.pauser.paused()
+		if !linked && resumed == nil {
 			s.assignTasks(responses, requestFails, cancel)
 		}
 		// Wait for something to happen
 		select {
+		case <-resumed:
+			// Sync resumed, loop back to assign tasks
+
 		case event := <-peering:
 			// A peer joined or left, the tasks queue and allocations need to be
 			// checked for potential assignment or reassignment
@@ -793,7 +803,7 @@ func (s *skeleton) executeTask(peer *peerConnection, req *headerRequest) {
 		// gone stale and monitor them. However, in that case too, we need a way
 		// to protect against malicious peers never responding, so it would need
 		// a second, hard-timeout mechanism.
-		s.drop(peer.id)
+		s.drop(peer.id, p2p.PenaltyTimeout)
 
 	case res := <-resCh:
 		// Headers successfully retrieved, update the metrics
@@ -955,7 +965,7 @@ func (s *skeleton) processResponse(res *headerResponse) (linked bool, merged boo
 			for i := 0; i < requestHeaders; i++ {
 				s.scratchSpace[i] = nil
 			}
-			s.drop(s.scratchOwners[0])
+			s.drop(s.scratchOwners[0], p2p.PenaltyInvalidMessage)
 			s.scratchOwners[0] = ""
 			break
 		}
@@ -967,7 +977,7 @@ func (s *skeleton) processResponse(res *headerResponse) (linked bool, merged boo
 				consumed++
 
 				rawdb.WriteSkeletonHeader(batch, header)
-				s.pulled++
+				s.pulled.Add(1)
 
 				s.progress.Subchains[0].Tail--
 				s.progress.Subchains[0].Next = header.ParentHash
@@ -1104,14 +1114,15 @@ func (s *skeleton) processResponse(res *headerResponse) (linked bool, merged boo
 	if linked {
 		left = 0
 	}
+	s.left.Store(left)
 	if time.Since(s.logged) > 8*time.Second || left == 0 {
 		s.logged = time.Now()
 
-		if s.pulled == 0 {
+		if pulled := s.pulled.Load(); pulled == 0 {
 			log.Info("Beacon sync starting", "left", left)
 		} else {
-			eta := float64(time.Since(s.started)) / float64(s.pulled) * float64(left)
-			log.Info("Syncing beacon headers", "downloaded", s.pulled, "left", left, "eta", common.PrettyDuration(eta))
+			eta := float64(time.Since(s.started)) / float64(pulled) * float64(left)
+			log.Info("Syncing beacon headers", "downloaded", pulled, "left", left, "eta", common.PrettyDuration(eta))
 		}
 	}
 	return linked, merged
@@ -842,7 +842,7 @@ func TestSkeletonSyncRetrievals(t *testing.T) {
 		}
 		// Create a peer dropper to track malicious peers
 		dropped := make(map[string]int)
-		drop := func(peer string) {
+		drop := func(peer string, penalty int) {
 			if p := peerset.Peer(peer); p != nil {
 				p.peer.(*skeletonTestPeer).dropped.Add(1)
 			}
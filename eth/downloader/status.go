@@ -0,0 +1,132 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import (
+	"time"
+)
+
+const (
+	statusRateWindow = time.Minute // time span over which the stage rates are measured
+	maxStatusSamples = 64          // maximum number of progress samples kept
+	numSyncStages    = 8           // number of stages in a sync status report
+)
+
+// SyncStatus is a detailed report of the sync progress, broken down by stage. The
+// rates tell apart a slow sync from a stuck one.
+type SyncStatus struct {
+	Mode          string `json:"mode"`
+	Syncing       bool   `json:"syncing"`
+	Paused        bool   `json:"paused"`
+	StartingBlock uint64 `json:"startingBlock"`
+	CurrentBlock  uint64 `json:"currentBlock"`
+	HighestBlock  uint64 `json:"highestBlock"`
+
+	Headers         StageStatus `json:"headers"`
+	Bodies          StageStatus `json:"bodies"`
+	Receipts        StageStatus `json:"receipts"`
+	Accounts        StageStatus `json:"accounts"`
+	Storage         StageStatus `json:"storage"`
+	Bytecodes       StageStatus `json:"bytecodes"`
+	HealedTrienodes StageStatus `json:"healedTrienodes"`
+	HealedBytecodes StageStatus `json:"healedBytecodes"`
+}
+
+// StageStatus is the progress of a sync stage.
+type StageStatus struct {
+	Done      uint64  `json:"done"`                // Items downloaded since the node started
+	Remaining uint64  `json:"remaining,omitempty"` // Items left to download, zero if unknown
+	Rate      float64 `json:"rate"`                // Items downloaded per second, over the last minute
+	ETA       uint64  `json:"eta,omitempty"`       // Estimated seconds until the stage is done
+}
+
+// statusSample is a snapshot of the stage counters, used to compute the rates.
+type statusSample struct {
+	time time.Time
+	done [numSyncStages]uint64
+}
+
+// SyncStatus returns a detailed report of the sync progress.
+func (d *Downloader) SyncStatus() *SyncStatus {
+	return d.syncStatus(time.Now())
+}
+
+func (d *Downloader) syncStatus(now time.Time) *SyncStatus {
+	var (
+		progress      = d.Progress()
+		snap, pending = d.SnapSyncer.Progress()
+	)
+	status := &SyncStatus{
+		Mode:          d.getMode().String(),
+		Syncing:       d.synchronising.Load(),
+		Paused:        d.Paused(),
+		StartingBlock: progress.StartingBlock,
+		CurrentBlock:  progress.CurrentBlock,
+		HighestBlock:  progress.HighestBlock,
+
+		Headers:         StageStatus{Done: d.skeleton.pulled.Load(), Remaining: d.skeleton.left.Load()},
+		Bodies:          StageStatus{Done: d.syncStatsBodies.Load(), Remaining: uint64(d.queue.PendingBodies())},
+		Receipts:        StageStatus{Done: d.syncStatsReceipts.Load(), Remaining: uint64(d.queue.PendingReceipts())},
+		Accounts:        StageStatus{Done: snap.AccountSynced},
+		Storage:         StageStatus{Done: snap.StorageSynced},
+		Bytecodes:       StageStatus{Done: snap.BytecodeSynced},
+		HealedTrienodes: StageStatus{Done: snap.TrienodeHealSynced, Remaining: pending.TrienodeHeal},
+		HealedBytecodes: StageStatus{Done: snap.BytecodeHealSynced, Remaining: pending.BytecodeHeal},
+	}
+	// The number of accounts isn't known upfront, estimate it from the share of
+	// the account hash space covered so far.
+	if coverage := snap.AccountCoverage; coverage > 0 && coverage < 1 {
+		status.Accounts.Remaining = uint64(float64(snap.AccountSynced)/coverage) - snap.AccountSynced
+	}
+	d.updateStageRates(now, [numSyncStages]*StageStatus{
+		&status.Headers, &status.Bodies, &status.Receipts, &status.Accounts,
+		&status.Storage, &status.Bytecodes, &status.HealedTrienodes, &status.HealedBytecodes,
+	})
+	return status
+}
+
+// updateStageRates computes the rates and ETAs of the stages against the oldest
+// progress sample within the rate window, and records the current progress.
+func (d *Downloader) updateStageRates(now time.Time, stages [numSyncStages]*StageStatus) {
+	d.syncStatsSamplesLock.Lock()
+	defer d.syncStatsSamplesLock.Unlock()
+
+	// Drop the samples outside the window, but keep the newest of them as the
+	// base if there is nothing more recent.
+	samples := d.syncStatsSamples
+	for len(samples) > 1 && (now.Sub(samples[1].time) >= statusRateWindow || len(samples) >= maxStatusSamples) {
+		samples = samples[1:]
+	}
+	if len(samples) > 0 {
+		base := samples[0]
+		if elapsed := now.Sub(base.time).Seconds(); elapsed > 0 {
+			for i, stage := range stages {
+				if stage.Done > base.done[i] {
+					stage.Rate = float64(stage.Done-base.done[i]) / elapsed
+				}
+				if stage.Rate > 0 && stage.Remaining > 0 {
+					stage.ETA = uint64(float64(stage.Remaining) / stage.Rate)
+				}
+			}
+		}
+	}
+	sample := statusSample{time: now}
+	for i, stage := range stages {
+		sample.done[i] = stage.Done
+	}
+	d.syncStatsSamples = append(samples, sample)
+}
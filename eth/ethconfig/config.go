@@ -29,10 +29,12 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
+	"github.com/ethereum/go-ethereum/eth/bundler"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/miner"
+	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -66,7 +68,9 @@ var Defaults = Config{
 	RPCGasCap:          50000000,
 	RPCEVMTimeout:      5 * time.Second,
 	GPO:                FullNodeGPO,
+	Bundler:            bundler.DefaultConfig,
 	RPCTxFeeCap:        1, // 1 ether
+	RPCProofReexec:     128,
 }
 
 //go:generate go run github.com/fjl/gencodec -type Config -formats toml -out gen_config.go
@@ -82,11 +86,34 @@ type Config struct {
 	NetworkId uint64
 	SyncMode  downloader.SyncMode
 
+	// Trusted checkpoint to start syncing from, for networks without a consensus
+	// client. The chain is snap synced to this block, older headers are backfilled
+	// and the chain of the peers is followed afterwards. Requires snap sync.
+	CheckpointNumber uint64      `toml:",omitempty"`
+	CheckpointHash   common.Hash `toml:",omitempty"`
+
 	// This can be set to list of enrtree:// URLs which will be queried for
 	// nodes to connect to.
 	EthDiscoveryURLs  []string
 	SnapDiscoveryURLs []string
 
+	// Transaction propagation tuning, zero values select the defaults. If validator
+	// peers are set and any of them is connected, full transactions are sent to them
+	// first and only announced to all other peers.
+	TxBroadcastFanout  int        `toml:",omitempty"` // Number of peers sent full transactions, the square root of the peer count if zero
+	TxBroadcastMaxSize uint64     `toml:",omitempty"` // Transactions larger than this are only announced
+	TxSendQueue        int        `toml:",omitempty"` // Per-peer queue of transactions to broadcast
+	TxAnnounceQueue    int        `toml:",omitempty"` // Per-peer queue of transaction announcements
+	TxValidatorPeers   []enode.ID `toml:",omitempty"` // Peers receiving full transactions first (validators-first routing)
+	TxSyncLimit        int        `toml:",omitempty"` // Number of pending transactions announced to newly connected peers
+
+	// Protocol message limits, zero values select the defaults. The rate limits map
+	// message names like "eth/getBlockHeaders" or "snap/getTrieNodes" to the maximum
+	// number of such messages accepted from a peer per second.
+	EthMaxMessageSize  uint32             `toml:",omitempty"`
+	SnapMaxMessageSize uint32             `toml:",omitempty"`
+	MessageRateLimits  map[string]float64 `toml:",omitempty"`
+
 	NoPruning  bool // Whether to disable pruning and flush everything to disk
 	NoPrefetch bool // Whether to disable prefetching and only load state on demand
 
@@ -128,6 +155,17 @@ type Config struct {
 	// This is the number of blocks for which logs will be cached in the filter system.
 	FilterLogCacheSize int
 
+	// Limits of log queries. Ranges exceeding the range limit are answered with
+	// an error unless they fit the split budget, in which case they are queried
+	// in chunks. Zero limits are disabled.
+	LogQueryRangeLimit  uint64 `toml:",omitempty"`
+	LogQueryResultLimit int    `toml:",omitempty"`
+	LogQuerySplitBudget uint64 `toml:",omitempty"`
+
+	// PersistFilters keeps installed log and block filters across restarts, so
+	// polling clients can resume them.
+	PersistFilters bool `toml:",omitempty"`
+
 	// Mining options
 	Miner miner.Config
 
@@ -138,6 +176,9 @@ type Config struct {
 	// Gas Price Oracle options
 	GPO gasprice.Config
 
+	// ERC-4337 bundler options
+	Bundler bundler.Config
+
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
@@ -161,6 +202,16 @@ type Config struct {
 	// send-transaction variants. The unit is ether.
 	RPCTxFeeCap float64
 
+	// RPCCacheDepth is the number of blocks below the head after which blocks
+	// are considered immutable by the RPC response cache, in addition to the
+	// finalized ones. Zero only caches calls referencing finalized blocks.
+	RPCCacheDepth uint64 `toml:",omitempty"`
+
+	// RPCProofReexec is the maximum number of blocks re-executed to regenerate
+	// pruned historical state when serving eth_getProof. Zero only serves proofs
+	// for states present in the database.
+	RPCProofReexec uint64 `toml:",omitempty"`
+
 	// OverrideCancun (TODO: remove after the fork)
 	OverrideCancun *uint64 `toml:",omitempty"`
 
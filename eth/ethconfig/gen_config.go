@@ -9,9 +9,11 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/txpool/blobpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
+	"github.com/ethereum/go-ethereum/eth/bundler"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/miner"
+	"github.com/ethereum/go-ethereum/p2p/enode"
 )
 
 // MarshalTOML marshals as TOML.
@@ -20,8 +22,19 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		Genesis                 *core.Genesis `toml:",omitempty"`
 		NetworkId               uint64
 		SyncMode                downloader.SyncMode
+		CheckpointNumber        uint64      `toml:",omitempty"`
+		CheckpointHash          common.Hash `toml:",omitempty"`
 		EthDiscoveryURLs        []string
 		SnapDiscoveryURLs       []string
+		TxBroadcastFanout       int                `toml:",omitempty"`
+		TxBroadcastMaxSize      uint64             `toml:",omitempty"`
+		TxSendQueue             int                `toml:",omitempty"`
+		TxAnnounceQueue         int                `toml:",omitempty"`
+		TxValidatorPeers        []enode.ID         `toml:",omitempty"`
+		TxSyncLimit             int                `toml:",omitempty"`
+		EthMaxMessageSize       uint32             `toml:",omitempty"`
+		SnapMaxMessageSize      uint32             `toml:",omitempty"`
+		MessageRateLimits       map[string]float64 `toml:",omitempty"`
 		NoPruning               bool
 		NoPrefetch              bool
 		TxLookupLimit           uint64                 `toml:",omitempty"`
@@ -45,10 +58,15 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		SnapshotCache           int
 		Preimages               bool
 		FilterLogCacheSize      int
+		LogQueryRangeLimit      uint64 `toml:",omitempty"`
+		LogQueryResultLimit     int    `toml:",omitempty"`
+		LogQuerySplitBudget     uint64 `toml:",omitempty"`
+		PersistFilters          bool   `toml:",omitempty"`
 		Miner                   miner.Config
 		TxPool                  legacypool.Config
 		BlobPool                blobpool.Config
 		GPO                     gasprice.Config
+		Bundler                 bundler.Config
 		EnablePreimageRecording bool
 		EnableWitnessCollection bool `toml:"-"`
 		VMTrace                 string
@@ -57,6 +75,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		RPCGasCap               uint64
 		RPCEVMTimeout           time.Duration
 		RPCTxFeeCap             float64
+		RPCCacheDepth           uint64  `toml:",omitempty"`
+		RPCProofReexec          uint64  `toml:",omitempty"`
 		OverrideCancun          *uint64 `toml:",omitempty"`
 		OverrideVerkle          *uint64 `toml:",omitempty"`
 	}
@@ -64,8 +84,19 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.Genesis = c.Genesis
 	enc.NetworkId = c.NetworkId
 	enc.SyncMode = c.SyncMode
+	enc.CheckpointNumber = c.CheckpointNumber
+	enc.CheckpointHash = c.CheckpointHash
 	enc.EthDiscoveryURLs = c.EthDiscoveryURLs
 	enc.SnapDiscoveryURLs = c.SnapDiscoveryURLs
+	enc.TxBroadcastFanout = c.TxBroadcastFanout
+	enc.TxBroadcastMaxSize = c.TxBroadcastMaxSize
+	enc.TxSendQueue = c.TxSendQueue
+	enc.TxAnnounceQueue = c.TxAnnounceQueue
+	enc.TxValidatorPeers = c.TxValidatorPeers
+	enc.TxSyncLimit = c.TxSyncLimit
+	enc.EthMaxMessageSize = c.EthMaxMessageSize
+	enc.SnapMaxMessageSize = c.SnapMaxMessageSize
+	enc.MessageRateLimits = c.MessageRateLimits
 	enc.NoPruning = c.NoPruning
 	enc.NoPrefetch = c.NoPrefetch
 	enc.TxLookupLimit = c.TxLookupLimit
@@ -89,10 +120,15 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.SnapshotCache = c.SnapshotCache
 	enc.Preimages = c.Preimages
 	enc.FilterLogCacheSize = c.FilterLogCacheSize
+	enc.LogQueryRangeLimit = c.LogQueryRangeLimit
+	enc.LogQueryResultLimit = c.LogQueryResultLimit
+	enc.LogQuerySplitBudget = c.LogQuerySplitBudget
+	enc.PersistFilters = c.PersistFilters
 	enc.Miner = c.Miner
 	enc.TxPool = c.TxPool
 	enc.BlobPool = c.BlobPool
 	enc.GPO = c.GPO
+	enc.Bundler = c.Bundler
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
 	enc.EnableWitnessCollection = c.EnableWitnessCollection
 	enc.VMTrace = c.VMTrace
@@ -101,6 +137,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.RPCGasCap = c.RPCGasCap
 	enc.RPCEVMTimeout = c.RPCEVMTimeout
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
+	enc.RPCCacheDepth = c.RPCCacheDepth
+	enc.RPCProofReexec = c.RPCProofReexec
 	enc.OverrideCancun = c.OverrideCancun
 	enc.OverrideVerkle = c.OverrideVerkle
 	return &enc, nil
@@ -112,8 +150,19 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		Genesis                 *core.Genesis `toml:",omitempty"`
 		NetworkId               *uint64
 		SyncMode                *downloader.SyncMode
+		CheckpointNumber        *uint64      `toml:",omitempty"`
+		CheckpointHash          *common.Hash `toml:",omitempty"`
 		EthDiscoveryURLs        []string
 		SnapDiscoveryURLs       []string
+		TxBroadcastFanout       *int               `toml:",omitempty"`
+		TxBroadcastMaxSize      *uint64            `toml:",omitempty"`
+		TxSendQueue             *int               `toml:",omitempty"`
+		TxAnnounceQueue         *int               `toml:",omitempty"`
+		TxValidatorPeers        []enode.ID         `toml:",omitempty"`
+		TxSyncLimit             *int               `toml:",omitempty"`
+		EthMaxMessageSize       *uint32            `toml:",omitempty"`
+		SnapMaxMessageSize      *uint32            `toml:",omitempty"`
+		MessageRateLimits       map[string]float64 `toml:",omitempty"`
 		NoPruning               *bool
 		NoPrefetch              *bool
 		TxLookupLimit           *uint64                `toml:",omitempty"`
@@ -137,10 +186,15 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		SnapshotCache           *int
 		Preimages               *bool
 		FilterLogCacheSize      *int
+		LogQueryRangeLimit      *uint64 `toml:",omitempty"`
+		LogQueryResultLimit     *int    `toml:",omitempty"`
+		LogQuerySplitBudget     *uint64 `toml:",omitempty"`
+		PersistFilters          *bool   `toml:",omitempty"`
 		Miner                   *miner.Config
 		TxPool                  *legacypool.Config
 		BlobPool                *blobpool.Config
 		GPO                     *gasprice.Config
+		Bundler                 *bundler.Config
 		EnablePreimageRecording *bool
 		EnableWitnessCollection *bool `toml:"-"`
 		VMTrace                 *string
@@ -149,6 +203,8 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		RPCGasCap               *uint64
 		RPCEVMTimeout           *time.Duration
 		RPCTxFeeCap             *float64
+		RPCCacheDepth           *uint64 `toml:",omitempty"`
+		RPCProofReexec          *uint64 `toml:",omitempty"`
 		OverrideCancun          *uint64 `toml:",omitempty"`
 		OverrideVerkle          *uint64 `toml:",omitempty"`
 	}
@@ -165,12 +221,45 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.SyncMode != nil {
 		c.SyncMode = *dec.SyncMode
 	}
+	if dec.CheckpointNumber != nil {
+		c.CheckpointNumber = *dec.CheckpointNumber
+	}
+	if dec.CheckpointHash != nil {
+		c.CheckpointHash = *dec.CheckpointHash
+	}
 	if dec.EthDiscoveryURLs != nil {
 		c.EthDiscoveryURLs = dec.EthDiscoveryURLs
 	}
 	if dec.SnapDiscoveryURLs != nil {
 		c.SnapDiscoveryURLs = dec.SnapDiscoveryURLs
 	}
+	if dec.TxBroadcastFanout != nil {
+		c.TxBroadcastFanout = *dec.TxBroadcastFanout
+	}
+	if dec.TxBroadcastMaxSize != nil {
+		c.TxBroadcastMaxSize = *dec.TxBroadcastMaxSize
+	}
+	if dec.TxSendQueue != nil {
+		c.TxSendQueue = *dec.TxSendQueue
+	}
+	if dec.TxAnnounceQueue != nil {
+		c.TxAnnounceQueue = *dec.TxAnnounceQueue
+	}
+	if dec.TxValidatorPeers != nil {
+		c.TxValidatorPeers = dec.TxValidatorPeers
+	}
+	if dec.TxSyncLimit != nil {
+		c.TxSyncLimit = *dec.TxSyncLimit
+	}
+	if dec.EthMaxMessageSize != nil {
+		c.EthMaxMessageSize = *dec.EthMaxMessageSize
+	}
+	if dec.SnapMaxMessageSize != nil {
+		c.SnapMaxMessageSize = *dec.SnapMaxMessageSize
+	}
+	if dec.MessageRateLimits != nil {
+		c.MessageRateLimits = dec.MessageRateLimits
+	}
 	if dec.NoPruning != nil {
 		c.NoPruning = *dec.NoPruning
 	}
@@ -240,6 +329,18 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.FilterLogCacheSize != nil {
 		c.FilterLogCacheSize = *dec.FilterLogCacheSize
 	}
+	if dec.LogQueryRangeLimit != nil {
+		c.LogQueryRangeLimit = *dec.LogQueryRangeLimit
+	}
+	if dec.LogQueryResultLimit != nil {
+		c.LogQueryResultLimit = *dec.LogQueryResultLimit
+	}
+	if dec.LogQuerySplitBudget != nil {
+		c.LogQuerySplitBudget = *dec.LogQuerySplitBudget
+	}
+	if dec.PersistFilters != nil {
+		c.PersistFilters = *dec.PersistFilters
+	}
 	if dec.Miner != nil {
 		c.Miner = *dec.Miner
 	}
@@ -252,6 +353,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.GPO != nil {
 		c.GPO = *dec.GPO
 	}
+	if dec.Bundler != nil {
+		c.Bundler = *dec.Bundler
+	}
 	if dec.EnablePreimageRecording != nil {
 		c.EnablePreimageRecording = *dec.EnablePreimageRecording
 	}
@@ -276,6 +380,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.RPCTxFeeCap != nil {
 		c.RPCTxFeeCap = *dec.RPCTxFeeCap
 	}
+	if dec.RPCCacheDepth != nil {
+		c.RPCCacheDepth = *dec.RPCCacheDepth
+	}
+	if dec.RPCProofReexec != nil {
+		c.RPCProofReexec = *dec.RPCProofReexec
+	}
 	if dec.OverrideCancun != nil {
 		c.OverrideCancun = dec.OverrideCancun
 	}
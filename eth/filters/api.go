@@ -39,6 +39,7 @@ var (
 	errInvalidBlockRange      = errors.New("invalid block range params")
 	errPendingLogsUnsupported = errors.New("pending logs are not supported")
 	errExceedMaxTopics        = errors.New("exceed max topics")
+	errFilterActive           = errors.New("filter is already attached")
 )
 
 // The maximum number of topic criteria allowed, vm.LOG4 - vm.LOG0
@@ -57,7 +58,9 @@ type filter struct {
 	txs      []*types.Transaction
 	crit     FilterCriteria
 	logs     []*types.Log
-	s        *Subscription // associated subscription in event system
+	last     uint64        // number of the last block received, or delivered if dormant
+	s        *Subscription // associated subscription in event system, nil if dormant
+	record   *filterRecord // persisted definition, nil if not persisted
 }
 
 // FilterAPI offers support to create and manage filters. This will allow external clients to retrieve various
@@ -68,6 +71,9 @@ type FilterAPI struct {
 	filtersMu sync.Mutex
 	filters   map[rpc.ID]*filter
 	timeout   time.Duration
+
+	reattachMu sync.Mutex // serializes the reattachment of persisted filters
+	storeMu    sync.Mutex // serializes the database writes of persisted filters
 }
 
 // NewFilterAPI returns a new FilterAPI instance.
@@ -78,6 +84,9 @@ func NewFilterAPI(system *FilterSystem) *FilterAPI {
 		filters: make(map[rpc.ID]*filter),
 		timeout: system.cfg.Timeout,
 	}
+	if system.cfg.Persist {
+		api.restoreFilters()
+	}
 	go api.timeoutLoop(system.cfg.Timeout)
 
 	return api
@@ -86,7 +95,10 @@ func NewFilterAPI(system *FilterSystem) *FilterAPI {
 // timeoutLoop runs at the interval set by 'timeout' and deletes filters
 // that have not been recently used. It is started when the API is created.
 func (api *FilterAPI) timeoutLoop(timeout time.Duration) {
-	var toUninstall []*Subscription
+	var (
+		toUninstall []*Subscription
+		toForget    = make(map[rpc.ID]*filter)
+	)
 	ticker := time.NewTicker(timeout)
 	defer ticker.Stop()
 	for {
@@ -95,14 +107,22 @@ func (api *FilterAPI) timeoutLoop(timeout time.Duration) {
 		for id, f := range api.filters {
 			select {
 			case <-f.deadline.C:
-				toUninstall = append(toUninstall, f.s)
+				if f.s != nil {
+					toUninstall = append(toUninstall, f.s)
+				}
 				delete(api.filters, id)
+				toForget[id] = f
 			default:
 				continue
 			}
 		}
 		api.filtersMu.Unlock()
 
+		for id, f := range toForget {
+			api.forgetFilter(id, f)
+			delete(toForget, id)
+		}
+
 		// Unsubscribes are processed outside the lock to avoid the following scenario:
 		// event loop attempts broadcasting events to still active filters while
 		// Unsubscribe is waiting for it to process the uninstall request.
@@ -192,35 +212,52 @@ func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool)
 
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
 // It is part of the filter package since polling goes with eth_getFilterChanges.
-func (api *FilterAPI) NewBlockFilter() rpc.ID {
+func (api *FilterAPI) NewBlockFilter(ctx context.Context) rpc.ID {
 	var (
-		headers   = make(chan *types.Header)
-		headerSub = api.events.SubscribeNewHeads(headers)
+		id = rpc.NewID()
+		f  = &filter{typ: BlocksSubscription, deadline: time.NewTimer(api.timeout), hashes: make([]common.Hash, 0)}
 	)
+	if api.persisted(f) {
+		f.record = &filterRecord{owner: filterOwner(ctx)}
+		f.last = api.sys.backend.CurrentHeader().Number.Uint64()
+		api.storeFilter(id, f, f.last)
+	}
+	api.subscribeBlocks(id, f)
 
 	api.filtersMu.Lock()
-	api.filters[headerSub.ID] = &filter{typ: BlocksSubscription, deadline: time.NewTimer(api.timeout), hashes: make([]common.Hash, 0), s: headerSub}
+	api.filters[id] = f
 	api.filtersMu.Unlock()
 
+	return id
+}
+
+// subscribeBlocks subscribes the block filter registered under the given id to
+// new chain heads.
+func (api *FilterAPI) subscribeBlocks(id rpc.ID, f *filter) {
+	var (
+		headers   = make(chan *types.Header)
+		headerSub = api.events.SubscribeNewHeads(headers)
+	)
+	f.s = headerSub
+
 	go func() {
 		for {
 			select {
 			case h := <-headers:
 				api.filtersMu.Lock()
-				if f, found := api.filters[headerSub.ID]; found {
-					f.hashes = append(f.hashes, h.Hash())
-				}
+				f.hashes = append(f.hashes, h.Hash())
+				f.last = h.Number.Uint64()
 				api.filtersMu.Unlock()
 			case <-headerSub.Err():
 				api.filtersMu.Lock()
-				delete(api.filters, headerSub.ID)
+				if api.filters[id] == f {
+					delete(api.filters, id)
+				}
 				api.filtersMu.Unlock()
 				return
 			}
 		}
 	}()
-
-	return headerSub.ID
 }
 
 // NewHeads send a notification each time a new (header) block is appended to the chain.
@@ -250,6 +287,45 @@ func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 	return rpcSub, nil
 }
 
+// NewSafeHeads send a notification each time the safe block of the chain advances.
+func (api *FilterAPI) NewSafeHeads(ctx context.Context) (*rpc.Subscription, error) {
+	return api.finalityHeads(ctx, api.events.SubscribeSafeHeads)
+}
+
+// NewFinalizedHeads send a notification each time the finalized block of the
+// chain advances.
+func (api *FilterAPI) NewFinalizedHeads(ctx context.Context) (*rpc.Subscription, error) {
+	return api.finalityHeads(ctx, api.events.SubscribeFinalizedHeads)
+}
+
+// finalityHeads forwards the headers of a safe or finalized head subscription to
+// an RPC subscription.
+func (api *FilterAPI) finalityHeads(ctx context.Context, subscribe func(chan *types.Header) *Subscription) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		headers := make(chan *types.Header)
+		headersSub := subscribe(headers)
+		defer headersSub.Unsubscribe()
+
+		for {
+			select {
+			case h := <-headers:
+				notifier.Notify(rpcSub.ID, h)
+			case <-rpcSub.Err():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
 func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
@@ -300,36 +376,58 @@ type FilterCriteria ethereum.FilterQuery
 // again but with the removed property set to true.
 //
 // In case "fromBlock" > "toBlock" an error is returned.
-func (api *FilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
-	logs := make(chan []*types.Log)
-	logsSub, err := api.events.SubscribeLogs(ethereum.FilterQuery(crit), logs)
-	if err != nil {
+func (api *FilterAPI) NewFilter(ctx context.Context, crit FilterCriteria) (rpc.ID, error) {
+	var (
+		id = rpc.NewID()
+		f  = &filter{typ: LogsSubscription, crit: crit, deadline: time.NewTimer(api.timeout), logs: make([]*types.Log, 0)}
+	)
+	if err := api.subscribeLogs(id, f); err != nil {
 		return "", err
 	}
-
+	if api.persisted(f) {
+		f.record = &filterRecord{owner: filterOwner(ctx)}
+		api.storeFilter(id, f, api.sys.backend.CurrentHeader().Number.Uint64())
+	}
 	api.filtersMu.Lock()
-	api.filters[logsSub.ID] = &filter{typ: LogsSubscription, crit: crit, deadline: time.NewTimer(api.timeout), logs: make([]*types.Log, 0), s: logsSub}
+	api.filters[id] = f
 	api.filtersMu.Unlock()
 
+	return id, nil
+}
+
+// subscribeLogs subscribes the log filter registered under the given id to the
+// logs matching its criteria.
+func (api *FilterAPI) subscribeLogs(id rpc.ID, f *filter) error {
+	logs := make(chan []*types.Log)
+	logsSub, err := api.events.SubscribeLogs(ethereum.FilterQuery(f.crit), logs)
+	if err != nil {
+		return err
+	}
+	f.s = logsSub
+
 	go func() {
 		for {
 			select {
 			case l := <-logs:
 				api.filtersMu.Lock()
-				if f, found := api.filters[logsSub.ID]; found {
-					f.logs = append(f.logs, l...)
+				f.logs = append(f.logs, l...)
+				for _, log := range l {
+					if !log.Removed {
+						f.last = max(f.last, log.BlockNumber)
+					}
 				}
 				api.filtersMu.Unlock()
 			case <-logsSub.Err():
 				api.filtersMu.Lock()
-				delete(api.filters, logsSub.ID)
+				if api.filters[id] == f {
+					delete(api.filters, id)
+				}
 				api.filtersMu.Unlock()
 				return
 			}
 		}
 	}()
-
-	return logsSub.ID, nil
+	return nil
 }
 
 // GetLogs returns logs matching the given argument that are stored within the state.
@@ -358,23 +456,78 @@ func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*type
 		filter = api.sys.NewRangeFilter(begin, end, crit.Addresses, crit.Topics)
 	}
 	// Run the filter and return all the logs
-	logs, err := filter.Logs(ctx)
+	logs, err := api.sys.limitedLogs(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 	return returnLogs(logs), err
 }
 
+// GetLogsPage returns a page of the logs matching the given argument, allowing
+// clients to walk large result sets in bounded steps. The cursor of a page is
+// passed to the next request with otherwise unchanged criteria to continue after
+// the last returned log. A page without cursor concludes the result set.
+func (api *FilterAPI) GetLogsPage(ctx context.Context, crit FilterCriteria, pageSize *hexutil.Uint, cursor *hexutil.Bytes) (*LogPage, error) {
+	if len(crit.Topics) > maxTopics {
+		return nil, errExceedMaxTopics
+	}
+	limit := defaultLogPageSize
+	if pageSize != nil {
+		limit = int(*pageSize)
+	}
+	if limit < 1 || limit > maxLogPageSize {
+		return nil, fmt.Errorf("page size must be between 1 and %d", maxLogPageSize)
+	}
+	var after *logCursor
+	if cursor != nil {
+		var err error
+		if after, err = decodeLogCursor(*cursor); err != nil {
+			return nil, err
+		}
+	}
+	var filter *Filter
+	if crit.BlockHash != nil {
+		filter = api.sys.NewBlockFilter(*crit.BlockHash, crit.Addresses, crit.Topics)
+	} else {
+		begin := rpc.LatestBlockNumber.Int64()
+		if crit.FromBlock != nil {
+			begin = crit.FromBlock.Int64()
+		}
+		end := rpc.LatestBlockNumber.Int64()
+		if crit.ToBlock != nil {
+			end = crit.ToBlock.Int64()
+		}
+		if begin > 0 && end > 0 && begin > end {
+			return nil, errInvalidBlockRange
+		}
+		filter = api.sys.NewRangeFilter(begin, end, crit.Addresses, crit.Topics)
+	}
+	logs, next, err := filter.pagedLogs(ctx, limit, after)
+	if err != nil {
+		return nil, err
+	}
+	page := &LogPage{Logs: returnLogs(logs)}
+	if next != nil {
+		page.Cursor = next.encode()
+	}
+	return page, nil
+}
+
 // UninstallFilter removes the filter with the given filter id.
-func (api *FilterAPI) UninstallFilter(id rpc.ID) bool {
+func (api *FilterAPI) UninstallFilter(ctx context.Context, id rpc.ID) bool {
+	owner := filterOwner(ctx)
+
 	api.filtersMu.Lock()
 	f, found := api.filters[id]
-	if found {
+	if found = found && f.ownedBy(owner); found {
 		delete(api.filters, id)
 	}
 	api.filtersMu.Unlock()
 	if found {
-		f.s.Unsubscribe()
+		api.forgetFilter(id, f)
+		if f.s != nil {
+			f.s.Unsubscribe()
+		}
 	}
 
 	return found
@@ -387,7 +540,7 @@ func (api *FilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*types.Lo
 	f, found := api.filters[id]
 	api.filtersMu.Unlock()
 
-	if !found || f.typ != LogsSubscription {
+	if !found || f.typ != LogsSubscription || !f.ownedBy(filterOwner(ctx)) {
 		return nil, errFilterNotFound
 	}
 
@@ -409,7 +562,7 @@ func (api *FilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*types.Lo
 		filter = api.sys.NewRangeFilter(begin, end, f.crit.Addresses, f.crit.Topics)
 	}
 	// Run the filter and return all the logs
-	logs, err := filter.Logs(ctx)
+	logs, err := api.sys.limitedLogs(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -421,14 +574,39 @@ func (api *FilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*types.Lo
 //
 // For pending transaction and block filters the result is []common.Hash.
 // (pending)Log filters return []Log.
-func (api *FilterAPI) GetFilterChanges(id rpc.ID) (interface{}, error) {
+//
+// Persisted filters restored after a restart are reattached on their first poll,
+// returning the changes since the last poll before the restart.
+func (api *FilterAPI) GetFilterChanges(ctx context.Context, id rpc.ID) (interface{}, error) {
+	owner := filterOwner(ctx)
+
+	api.filtersMu.Lock()
+	f, found := api.filters[id]
+	api.filtersMu.Unlock()
+
+	if found && !f.ownedBy(owner) {
+		return []interface{}{}, errFilterNotFound
+	}
+	if found && f.s == nil {
+		if err := api.reattach(context.Background(), id, nil); err != nil && err != errFilterActive {
+			return []interface{}{}, err
+		}
+	}
+	// The delivered block of a persisted filter is recorded once the lock is
+	// released, the deferred calls run in reverse order.
+	var store func()
+	defer func() {
+		if store != nil {
+			store()
+		}
+	}()
 	api.filtersMu.Lock()
 	defer api.filtersMu.Unlock()
 
 	chainConfig := api.sys.backend.ChainConfig()
 	latest := api.sys.backend.CurrentHeader()
 
-	if f, found := api.filters[id]; found {
+	if f, found := api.filters[id]; found && f.ownedBy(owner) {
 		if !f.deadline.Stop() {
 			// timer expired but filter is not yet removed in timeout loop
 			// receive timer value and reset timer
@@ -440,6 +618,8 @@ func (api *FilterAPI) GetFilterChanges(id rpc.ID) (interface{}, error) {
 		case BlocksSubscription:
 			hashes := f.hashes
 			f.hashes = nil
+			last := f.last
+			store = func() { api.storeFilter(id, f, last) }
 			return returnHashes(hashes), nil
 		case PendingTransactionsSubscription:
 			if f.fullTx {
@@ -460,6 +640,12 @@ func (api *FilterAPI) GetFilterChanges(id rpc.ID) (interface{}, error) {
 		case LogsSubscription:
 			logs := f.logs
 			f.logs = nil
+			// Logs of the head block may still be in flight, so it's only
+			// considered delivered once followed by another block.
+			if api.persisted(f) && latest.Number.Uint64() > 0 {
+				last := max(f.last, latest.Number.Uint64()-1)
+				store = func() { api.storeFilter(id, f, last) }
+			}
 			return returnLogs(logs), nil
 		}
 	}
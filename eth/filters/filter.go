@@ -108,9 +108,28 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 		return f.blockLogs(ctx, header)
 	}
 
+	if err := f.resolveRange(ctx); err != nil {
+		return nil, err
+	}
+
+	logChan, errChan := f.rangeLogsAsync(ctx)
+	var logs []*types.Log
+	for {
+		select {
+		case log := <-logChan:
+			logs = append(logs, log)
+		case err := <-errChan:
+			return logs, err
+		}
+	}
+}
+
+// resolveRange converts the special block numbers delimiting the range of the
+// filter into the numbers of the blocks they currently reference.
+func (f *Filter) resolveRange(ctx context.Context) error {
 	// Disallow pending logs.
 	if f.begin == rpc.PendingBlockNumber.Int64() || f.end == rpc.PendingBlockNumber.Int64() {
-		return nil, errPendingLogsUnsupported
+		return errPendingLogsUnsupported
 	}
 
 	resolveSpecial := func(number int64) (int64, error) {
@@ -142,20 +161,38 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	var err error
 	// range query need to resolve the special begin/end block number
 	if f.begin, err = resolveSpecial(f.begin); err != nil {
-		return nil, err
+		return err
 	}
 	if f.end, err = resolveSpecial(f.end); err != nil {
-		return nil, err
+		return err
 	}
+	return nil
+}
+
+// rangeLogsUntil retrieves the logs matching the range filter, passing them to fn
+// until it returns false, at which point the retrieval is aborted.
+func (f *Filter) rangeLogsUntil(ctx context.Context, fn func(*types.Log) bool) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	logChan, errChan := f.rangeLogsAsync(ctx)
-	var logs []*types.Log
 	for {
 		select {
 		case log := <-logChan:
-			logs = append(logs, log)
+			if fn(log) {
+				continue
+			}
+			// Abort the retrieval and wait for it to wind down.
+			cancel()
+			for {
+				select {
+				case <-logChan:
+				case <-errChan:
+					return nil
+				}
+			}
 		case err := <-errChan:
-			return logs, err
+			return err
 		}
 	}
 }
@@ -238,7 +275,11 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64, logChan chan *type
 				return err
 			}
 			for _, log := range found {
-				logChan <- log
+				select {
+				case logChan <- log:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
 			}
 
 		case <-ctx.Done():
@@ -42,6 +42,12 @@ import (
 type Config struct {
 	LogCacheSize int           // maximum number of cached blocks (default: 32)
 	Timeout      time.Duration // how long filters stay active (default: 5min)
+
+	LogRangeLimit  uint64 // maximum number of blocks queried at once by getLogs (0 = unlimited)
+	LogResultLimit int    // maximum number of logs returned by getLogs (0 = unlimited)
+	LogSplitBudget uint64 // maximum number of blocks of ranges split into chunks of LogRangeLimit
+
+	Persist bool // whether installed log and block filters are kept across restarts
 }
 
 func (cfg Config) withDefaults() Config {
@@ -67,6 +73,7 @@ type Backend interface {
 	SubscribeNewTxsEvent(chan<- core.NewTxsEvent) event.Subscription
 	SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription
 	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
+	SubscribeChainFinalityEvent(ch chan<- core.ChainFinalityEvent) event.Subscription
 	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
 
 	BloomStatus() (uint64, uint64)
@@ -154,6 +161,10 @@ const (
 	PendingTransactionsSubscription
 	// BlocksSubscription queries hashes for blocks that are imported
 	BlocksSubscription
+	// SafeBlocksSubscription queries headers of blocks that become safe
+	SafeBlocksSubscription
+	// FinalizedBlocksSubscription queries headers of blocks that become finalized
+	FinalizedBlocksSubscription
 	// LastIndexSubscription keeps track of the last index
 	LastIndexSubscription
 )
@@ -168,6 +179,8 @@ const (
 	logsChanSize = 10
 	// chainEvChanSize is the size of channel listening to ChainEvent.
 	chainEvChanSize = 10
+	// finalityEvChanSize is the size of channel listening to ChainFinalityEvent.
+	finalityEvChanSize = 10
 )
 
 type subscription struct {
@@ -193,14 +206,16 @@ type EventSystem struct {
 	logsSub   event.Subscription // Subscription for new log event
 	rmLogsSub event.Subscription // Subscription for removed log event
 	chainSub  event.Subscription // Subscription for new chain event
+	finalSub  event.Subscription // Subscription for safe and finalized block event
 
 	// Channels
-	install   chan *subscription         // install filter for event notification
-	uninstall chan *subscription         // remove filter for event notification
-	txsCh     chan core.NewTxsEvent      // Channel to receive new transactions event
-	logsCh    chan []*types.Log          // Channel to receive new log event
-	rmLogsCh  chan core.RemovedLogsEvent // Channel to receive removed log event
-	chainCh   chan core.ChainEvent       // Channel to receive new chain event
+	install   chan *subscription           // install filter for event notification
+	uninstall chan *subscription           // remove filter for event notification
+	txsCh     chan core.NewTxsEvent        // Channel to receive new transactions event
+	logsCh    chan []*types.Log            // Channel to receive new log event
+	rmLogsCh  chan core.RemovedLogsEvent   // Channel to receive removed log event
+	chainCh   chan core.ChainEvent         // Channel to receive new chain event
+	finalCh   chan core.ChainFinalityEvent // Channel to receive safe and finalized block event
 }
 
 // NewEventSystem creates a new manager that listens for event on the given mux,
@@ -219,6 +234,7 @@ func NewEventSystem(sys *FilterSystem) *EventSystem {
 		logsCh:    make(chan []*types.Log, logsChanSize),
 		rmLogsCh:  make(chan core.RemovedLogsEvent, rmLogsChanSize),
 		chainCh:   make(chan core.ChainEvent, chainEvChanSize),
+		finalCh:   make(chan core.ChainFinalityEvent, finalityEvChanSize),
 	}
 
 	// Subscribe events
@@ -226,9 +242,10 @@ func NewEventSystem(sys *FilterSystem) *EventSystem {
 	m.logsSub = m.backend.SubscribeLogsEvent(m.logsCh)
 	m.rmLogsSub = m.backend.SubscribeRemovedLogsEvent(m.rmLogsCh)
 	m.chainSub = m.backend.SubscribeChainEvent(m.chainCh)
+	m.finalSub = m.backend.SubscribeChainFinalityEvent(m.finalCh)
 
 	// Make sure none of the subscriptions are empty
-	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil {
+	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil || m.finalSub == nil {
 		log.Crit("Subscribe for event system failed")
 	}
 
@@ -353,6 +370,32 @@ func (es *EventSystem) SubscribeNewHeads(headers chan *types.Header) *Subscripti
 	return es.subscribe(sub)
 }
 
+// SubscribeSafeHeads creates a subscription that writes the header of a block
+// that becomes the safe block of the chain.
+func (es *EventSystem) SubscribeSafeHeads(headers chan *types.Header) *Subscription {
+	return es.subscribeFinality(SafeBlocksSubscription, headers)
+}
+
+// SubscribeFinalizedHeads creates a subscription that writes the header of a
+// block that becomes the finalized block of the chain.
+func (es *EventSystem) SubscribeFinalizedHeads(headers chan *types.Header) *Subscription {
+	return es.subscribeFinality(FinalizedBlocksSubscription, headers)
+}
+
+func (es *EventSystem) subscribeFinality(typ Type, headers chan *types.Header) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       typ,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		txs:       make(chan []*types.Transaction),
+		headers:   headers,
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
 // SubscribePendingTxs creates a subscription that writes transactions for
 // transactions that enter the transaction pool.
 func (es *EventSystem) SubscribePendingTxs(txs chan []*types.Transaction) *Subscription {
@@ -395,6 +438,19 @@ func (es *EventSystem) handleChainEvent(filters filterIndex, ev core.ChainEvent)
 	}
 }
 
+func (es *EventSystem) handleFinalityEvent(filters filterIndex, ev core.ChainFinalityEvent) {
+	if ev.Safe != nil {
+		for _, f := range filters[SafeBlocksSubscription] {
+			f.headers <- ev.Safe
+		}
+	}
+	if ev.Finalized != nil {
+		for _, f := range filters[FinalizedBlocksSubscription] {
+			f.headers <- ev.Finalized
+		}
+	}
+}
+
 // eventLoop (un)installs filters and processes mux events.
 func (es *EventSystem) eventLoop() {
 	// Ensure all subscriptions get cleaned up
@@ -403,6 +459,7 @@ func (es *EventSystem) eventLoop() {
 		es.logsSub.Unsubscribe()
 		es.rmLogsSub.Unsubscribe()
 		es.chainSub.Unsubscribe()
+		es.finalSub.Unsubscribe()
 	}()
 
 	index := make(filterIndex)
@@ -420,6 +477,8 @@ func (es *EventSystem) eventLoop() {
 			es.handleLogs(index, ev.Logs)
 		case ev := <-es.chainCh:
 			es.handleChainEvent(index, ev)
+		case ev := <-es.finalCh:
+			es.handleFinalityEvent(index, ev)
 
 		case f := <-es.install:
 			index[f.typ][f.id] = f
@@ -438,6 +497,8 @@ func (es *EventSystem) eventLoop() {
 			return
 		case <-es.chainSub.Err():
 			return
+		case <-es.finalSub.Err():
+			return
 		}
 	}
 }
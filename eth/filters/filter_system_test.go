@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
@@ -46,6 +47,7 @@ type testBackend struct {
 	logsFeed        event.Feed
 	rmLogsFeed      event.Feed
 	chainFeed       event.Feed
+	finalityFeed    event.Feed
 	pendingBlock    *types.Block
 	pendingReceipts types.Receipts
 }
@@ -137,6 +139,10 @@ func (b *testBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subsc
 	return b.chainFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeChainFinalityEvent(ch chan<- core.ChainFinalityEvent) event.Subscription {
+	return b.finalityFeed.Subscribe(ch)
+}
+
 func (b *testBackend) BloomStatus() (uint64, uint64) {
 	return params.BloomBitsBlocks, b.sections
 }
@@ -238,6 +244,59 @@ func TestBlockSubscription(t *testing.T) {
 	<-sub1.Err()
 }
 
+// TestFinalitySubscription tests that safe and finalized head subscriptions only
+// receive the headers of their own label.
+func TestFinalitySubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{})
+		api          = NewFilterAPI(sys)
+		genesis      = &core.Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		_, chain, _ = core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), 4, func(i int, gen *core.BlockGen) {})
+
+		safeCh      = make(chan *types.Header)
+		safeSub     = api.events.SubscribeSafeHeads(safeCh)
+		finalizedCh = make(chan *types.Header)
+		finalSub    = api.events.SubscribeFinalizedHeads(finalizedCh)
+	)
+	defer safeSub.Unsubscribe()
+	defer finalSub.Unsubscribe()
+
+	events := []core.ChainFinalityEvent{
+		{Safe: chain[1].Header()},
+		{Finalized: chain[0].Header()},
+		{Safe: chain[3].Header()},
+		{Finalized: chain[2].Header()},
+	}
+	go func() {
+		for _, ev := range events {
+			backend.finalityFeed.Send(ev)
+		}
+	}()
+	var safe, finalized []common.Hash
+	for len(safe) < 2 || len(finalized) < 2 {
+		select {
+		case h := <-safeCh:
+			safe = append(safe, h.Hash())
+		case h := <-finalizedCh:
+			finalized = append(finalized, h.Hash())
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for headers: %d safe, %d finalized", len(safe), len(finalized))
+		}
+	}
+	if want := []common.Hash{chain[1].Hash(), chain[3].Hash()}; !reflect.DeepEqual(safe, want) {
+		t.Errorf("safe heads mismatch: have %x, want %x", safe, want)
+	}
+	if want := []common.Hash{chain[0].Hash(), chain[2].Hash()}; !reflect.DeepEqual(finalized, want) {
+		t.Errorf("finalized heads mismatch: have %x, want %x", finalized, want)
+	}
+}
+
 // TestPendingTxFilter tests whether pending tx filters retrieve all pending transactions that are posted to the event mux.
 func TestPendingTxFilter(t *testing.T) {
 	t.Parallel()
@@ -265,7 +324,7 @@ func TestPendingTxFilter(t *testing.T) {
 
 	timeout := time.Now().Add(1 * time.Second)
 	for {
-		results, err := api.GetFilterChanges(fid0)
+		results, err := api.GetFilterChanges(context.Background(), fid0)
 		if err != nil {
 			t.Fatalf("Unable to retrieve logs: %v", err)
 		}
@@ -322,7 +381,7 @@ func TestPendingTxFilterFullTx(t *testing.T) {
 
 	timeout := time.Now().Add(1 * time.Second)
 	for {
-		results, err := api.GetFilterChanges(fid0)
+		results, err := api.GetFilterChanges(context.Background(), fid0)
 		if err != nil {
 			t.Fatalf("Unable to retrieve logs: %v", err)
 		}
@@ -383,12 +442,12 @@ func TestLogFilterCreation(t *testing.T) {
 	)
 
 	for i, test := range testCases {
-		id, err := api.NewFilter(test.crit)
+		id, err := api.NewFilter(context.Background(), test.crit)
 		if err != nil && test.success {
 			t.Errorf("expected filter creation for case %d to success, got %v", i, err)
 		}
 		if err == nil {
-			api.UninstallFilter(id)
+			api.UninstallFilter(context.Background(), id)
 			if !test.success {
 				t.Errorf("expected testcase %d to fail with an error", i)
 			}
@@ -417,7 +476,7 @@ func TestInvalidLogFilterCreation(t *testing.T) {
 	}
 
 	for i, test := range testCases {
-		if _, err := api.NewFilter(test); err == nil {
+		if _, err := api.NewFilter(context.Background(), test); err == nil {
 			t.Errorf("Expected NewFilter for case #%d to fail", i)
 		}
 	}
@@ -520,7 +579,7 @@ func TestLogFilter(t *testing.T) {
 
 	// create all filters
 	for i := range testCases {
-		testCases[i].id, _ = api.NewFilter(testCases[i].crit)
+		testCases[i].id, _ = api.NewFilter(context.Background(), testCases[i].crit)
 	}
 
 	// raise events
@@ -533,7 +592,7 @@ func TestLogFilter(t *testing.T) {
 		var fetched []*types.Log
 		timeout := time.Now().Add(1 * time.Second)
 		for { // fetch all expected logs
-			results, err := api.GetFilterChanges(tt.id)
+			results, err := api.GetFilterChanges(context.Background(), tt.id)
 			if err != nil {
 				t.Fatalf("test %d: unable to fetch logs: %v", i, err)
 			}
@@ -608,7 +667,7 @@ func TestPendingTxFilterDeadlock(t *testing.T) {
 		subs[i] = f.s
 		// Wait for at least one tx to arrive in filter
 		for {
-			hashes, err := api.GetFilterChanges(fid)
+			hashes, err := api.GetFilterChanges(context.Background(), fid)
 			if err != nil {
 				t.Fatalf("Filter should exist: %v\n", err)
 			}
@@ -628,3 +687,134 @@ func TestPendingTxFilterDeadlock(t *testing.T) {
 		}
 	}
 }
+
+// TestPersistentBlockFilter tests that persisted block filters survive a restart
+// of the filter API, replaying the blocks missed in between.
+func TestPersistentBlockFilter(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		_, sys  = newTestFilterSystem(t, db, Config{Persist: true})
+		genesis = &core.Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		_, chain, _ = core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), 8, func(i int, gen *core.BlockGen) {})
+	)
+	advance := func(n int) {
+		for _, block := range chain[:n] {
+			rawdb.WriteBlock(db, block)
+			rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+			rawdb.WriteHeadBlockHash(db, block.Hash())
+		}
+	}
+	hashes := func(from, to int) []common.Hash {
+		var hashes []common.Hash
+		for _, block := range chain[from-1 : to] {
+			hashes = append(hashes, block.Hash())
+		}
+		return hashes
+	}
+	advance(3)
+	api := NewFilterAPI(sys)
+	id := api.NewBlockFilter(context.Background())
+	if _, err := api.GetFilterChanges(context.Background(), id); err != nil {
+		t.Fatalf("failed to poll filter: %v", err)
+	}
+	if err := api.ReattachFilter(context.Background(), id, nil); err != errFilterActive {
+		t.Fatalf("reattaching active filter: have %v, want %v", err, errFilterActive)
+	}
+	// Restart with a few blocks imported in between, the first poll should
+	// deliver the missed blocks.
+	advance(6)
+	api = NewFilterAPI(sys)
+	changes, err := api.GetFilterChanges(context.Background(), id)
+	if err != nil {
+		t.Fatalf("failed to poll restored filter: %v", err)
+	}
+	if want := hashes(4, 6); !reflect.DeepEqual(changes, want) {
+		t.Fatalf("restored filter changes mismatch: have %x, want %x", changes, want)
+	}
+	// Restart again and reattach from an explicit block.
+	advance(8)
+	api = NewFilterAPI(sys)
+	from := hexutil.Uint64(2)
+	if err := api.ReattachFilter(context.Background(), id, &from); err != nil {
+		t.Fatalf("failed to reattach filter: %v", err)
+	}
+	changes, err = api.GetFilterChanges(context.Background(), id)
+	if err != nil {
+		t.Fatalf("failed to poll reattached filter: %v", err)
+	}
+	if want := hashes(2, 8); !reflect.DeepEqual(changes, want) {
+		t.Fatalf("reattached filter changes mismatch: have %x, want %x", changes, want)
+	}
+	// Uninstalling should drop the filter from the database.
+	if !api.UninstallFilter(context.Background(), id) {
+		t.Fatal("failed to uninstall filter")
+	}
+	if stored := rawdb.ReadFilters(db); len(stored) != 0 {
+		t.Fatalf("uninstalled filter still persisted: %d filters", len(stored))
+	}
+	if err := api.ReattachFilter(context.Background(), id, nil); err != errFilterNotFound {
+		t.Fatalf("reattaching uninstalled filter: have %v, want %v", err, errFilterNotFound)
+	}
+}
+
+// TestPersistentFilterOwner tests that persisted filters are only accessible to
+// the client which installed them, also after a restart.
+func TestPersistentFilterOwner(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		_, sys = newTestFilterSystem(t, db, Config{Persist: true})
+		gspec  = &core.Genesis{Config: params.TestChainConfig, BaseFee: big.NewInt(params.InitialBaseFee)}
+	)
+	_, chain, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 1, func(i int, gen *core.BlockGen) {})
+	rawdb.WriteBlock(db, chain[0])
+	rawdb.WriteCanonicalHash(db, chain[0].Hash(), chain[0].NumberU64())
+	rawdb.WriteHeadBlockHash(db, chain[0].Hash())
+
+	dial := func(api *FilterAPI, info rpc.PeerInfo) *rpc.Client {
+		server := rpc.NewServer()
+		t.Cleanup(server.Stop)
+		if err := server.RegisterName("eth", api); err != nil {
+			t.Fatal(err)
+		}
+		client := rpc.DialInProcAs(server, info)
+		t.Cleanup(client.Close)
+		return client
+	}
+	var (
+		owner = rpc.PeerInfo{Transport: "http", RemoteAddr: "10.0.0.1:1000", APIKey: "owner"}
+		other = rpc.PeerInfo{Transport: "http", RemoteAddr: "10.0.0.1:1000", APIKey: "other"}
+		api   = NewFilterAPI(sys)
+		id    rpc.ID
+	)
+	if err := dial(api, owner).Call(&id, "eth_newBlockFilter"); err != nil {
+		t.Fatalf("failed to install filter: %v", err)
+	}
+	// Restart, the filter is only reattached and polled by its owner.
+	api = NewFilterAPI(sys)
+	var changes []common.Hash
+	if err := dial(api, other).Call(nil, "eth_reattachFilter", id, nil); err == nil || err.Error() != errFilterNotFound.Error() {
+		t.Fatalf("reattaching foreign filter: have %v, want %v", err, errFilterNotFound)
+	}
+	if err := dial(api, other).Call(&changes, "eth_getFilterChanges", id); err == nil || err.Error() != errFilterNotFound.Error() {
+		t.Fatalf("polling foreign filter: have %v, want %v", err, errFilterNotFound)
+	}
+	var uninstalled bool
+	if err := dial(api, other).Call(&uninstalled, "eth_uninstallFilter", id); err != nil || uninstalled {
+		t.Fatalf("uninstalled foreign filter: %v %v", uninstalled, err)
+	}
+	// A new connection of the owner, from another port, can access it.
+	owner.RemoteAddr = "10.0.0.1:2000"
+	if err := dial(api, owner).Call(&changes, "eth_getFilterChanges", id); err != nil {
+		t.Fatalf("failed to poll own filter: %v", err)
+	}
+	if err := dial(api, owner).Call(&uninstalled, "eth_uninstallFilter", id); err != nil || !uninstalled {
+		t.Fatalf("failed to uninstall own filter: %v %v", uninstalled, err)
+	}
+}
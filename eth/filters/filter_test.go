@@ -19,19 +19,23 @@ package filters
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"math/big"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/triedb"
@@ -387,3 +391,141 @@ func TestFilters(t *testing.T) {
 		}
 	})
 }
+
+// insertLogChain inserts a chain of the given length into db, with every block
+// containing a transaction emitting three logs.
+func insertLogChain(t *testing.T, db ethdb.Database, blocks int) {
+	var (
+		key, _    = crypto.GenerateKey()
+		addr      = crypto.PubkeyToAddress(key.PublicKey)
+		signer    = types.NewLondonSigner(big.NewInt(1))
+		contract  = common.Address{0xfe}
+		threeLogs = common.FromHex("600060006000a0600060006000a0600060006000a000") // LOG0 three times
+		gspec     = &core.Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   types.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}, contract: {Code: threeLogs}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+	)
+	_, err := gspec.Commit(db, triedb.NewDatabase(db, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, _ := core.GenerateChain(gspec.Config, gspec.ToBlock(), ethash.NewFaker(), db, blocks, func(i int, gen *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTx(&types.LegacyTx{
+			Nonce:    uint64(i),
+			GasPrice: gen.BaseFee(),
+			Gas:      50000,
+			To:       &contract,
+		}), signer, key)
+		gen.AddTx(tx)
+	})
+	bc, err := core.NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+	if _, err := bc.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetLogsPage(t *testing.T) {
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		_, sys       = newTestFilterSystem(t, db, Config{})
+		api          = NewFilterAPI(sys)
+		logsPerBlock = 3
+		blocks       = 10
+	)
+	insertLogChain(t, db, blocks)
+
+	var (
+		crit     = FilterCriteria{FromBlock: big.NewInt(1)}
+		pageSize = hexutil.Uint(4)
+		cursor   *hexutil.Bytes
+		walked   []*types.Log
+	)
+	for pages := 0; ; pages++ {
+		if pages > blocks*logsPerBlock {
+			t.Fatal("paging did not terminate")
+		}
+		page, err := api.GetLogsPage(context.Background(), crit, &pageSize, cursor)
+		if err != nil {
+			t.Fatalf("page %d: %v", pages, err)
+		}
+		if len(page.Logs) > int(pageSize) {
+			t.Fatalf("page %d: have %d logs, want at most %d", pages, len(page.Logs), pageSize)
+		}
+		walked = append(walked, page.Logs...)
+		if page.Cursor == nil {
+			break
+		}
+		cursor = &page.Cursor
+	}
+	all, err := api.GetLogs(context.Background(), crit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != blocks*logsPerBlock {
+		t.Fatalf("have %d logs, want %d", len(all), blocks*logsPerBlock)
+	}
+	have, _ := json.Marshal(walked)
+	want, _ := json.Marshal(all)
+	if string(have) != string(want) {
+		t.Fatalf("paged logs mismatch:\nhave %s\nwant %s", have, want)
+	}
+
+	// Cursors of blocks which are no longer canonical must be rejected.
+	stale := (&logCursor{Number: 2, Hash: common.Hash{0x01}}).encode()
+	if _, err := api.GetLogsPage(context.Background(), crit, &pageSize, &stale); err != errCursorReorged {
+		t.Fatalf("stale cursor: have %v, want %v", err, errCursorReorged)
+	}
+}
+
+func TestGetLogsLimits(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	insertLogChain(t, db, 10)
+
+	for i, tc := range []struct {
+		cfg      Config
+		from, to int64
+		logs     int
+		fits     *LogRange
+		err      bool
+	}{
+		// Requests within the limits
+		{cfg: Config{LogRangeLimit: 4, LogResultLimit: 12}, from: 1, to: 4, logs: 12},
+		// Ranges exceeding the range limit report the largest fitting range
+		{cfg: Config{LogRangeLimit: 4}, from: 1, to: 10, err: true, fits: &LogRange{1, 4}},
+		{cfg: Config{LogRangeLimit: 4}, from: 3, to: 10, err: true, fits: &LogRange{3, 6}},
+		// Ranges within the split budget are split into chunks
+		{cfg: Config{LogRangeLimit: 4, LogSplitBudget: 10}, from: 1, to: 10, logs: 30},
+		{cfg: Config{LogRangeLimit: 4, LogSplitBudget: 6}, from: 1, to: 10, err: true, fits: &LogRange{1, 6}},
+		// Results exceeding the result limit report the blocks fitting completely
+		{cfg: Config{LogResultLimit: 10}, from: 1, to: 10, err: true, fits: &LogRange{1, 3}},
+		{cfg: Config{LogRangeLimit: 2, LogResultLimit: 10, LogSplitBudget: 10}, from: 1, to: 10, err: true, fits: &LogRange{1, 3}},
+		{cfg: Config{LogResultLimit: 2}, from: 5, to: 10, err: true},
+	} {
+		_, sys := newTestFilterSystem(t, db, tc.cfg)
+		api := NewFilterAPI(sys)
+
+		logs, err := api.GetLogs(context.Background(), FilterCriteria{FromBlock: big.NewInt(tc.from), ToBlock: big.NewInt(tc.to)})
+		if !tc.err {
+			if err != nil {
+				t.Fatalf("test %d: unexpected error: %v", i, err)
+			}
+			if len(logs) != tc.logs {
+				t.Fatalf("test %d: have %d logs, want %d", i, len(logs), tc.logs)
+			}
+			continue
+		}
+		var limitErr *logLimitError
+		if !errors.As(err, &limitErr) {
+			t.Fatalf("test %d: have error %v, want limit error", i, err)
+		}
+		if !reflect.DeepEqual(limitErr.fits, tc.fits) {
+			t.Fatalf("test %d: fitting range mismatch: have %v, want %v", i, limitErr.fits, tc.fits)
+		}
+	}
+}
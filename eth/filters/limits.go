@@ -0,0 +1,123 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// errcodeLimitExceeded is the JSON-RPC error code of queries exceeding the limits
+// of the server.
+const errcodeLimitExceeded = -32005
+
+// LogRange is a block range of a log query.
+type LogRange struct {
+	FromBlock hexutil.Uint64 `json:"fromBlock"`
+	ToBlock   hexutil.Uint64 `json:"toBlock"`
+}
+
+// logLimitError is returned if a log query exceeds the configured limits. If a
+// smaller range starting at the same block satisfies the limits, it is attached
+// as error data, so clients can query it and resume from there.
+type logLimitError struct {
+	message string
+	fits    *LogRange
+}
+
+func (e *logLimitError) Error() string { return e.message }
+
+func (e *logLimitError) ErrorCode() int { return errcodeLimitExceeded }
+
+func (e *logLimitError) ErrorData() interface{} {
+	if e.fits == nil {
+		return nil
+	}
+	return e.fits
+}
+
+// limitedLogs runs the filter, enforcing the configured block range and result
+// limits. Ranges exceeding the range limit are split into chunks of acceptable
+// size and processed in sequence, if they are within the split budget.
+func (sys *FilterSystem) limitedLogs(ctx context.Context, f *Filter) ([]*types.Log, error) {
+	var (
+		rangeLimit  = sys.cfg.LogRangeLimit
+		resultLimit = sys.cfg.LogResultLimit
+	)
+	if rangeLimit == 0 && resultLimit == 0 {
+		return f.Logs(ctx)
+	}
+	if f.block != nil {
+		logs, err := f.Logs(ctx)
+		if err == nil && resultLimit > 0 && len(logs) > resultLimit {
+			return nil, &logLimitError{message: fmt.Sprintf("query returned more than %d results", resultLimit)}
+		}
+		return logs, err
+	}
+	if err := f.resolveRange(ctx); err != nil {
+		return nil, err
+	}
+	if f.begin > f.end {
+		return nil, nil
+	}
+	var (
+		begin = uint64(f.begin)
+		end   = uint64(f.end)
+		chunk = end - begin + 1
+	)
+	if rangeLimit > 0 && chunk > rangeLimit {
+		if budget := sys.cfg.LogSplitBudget; chunk > budget {
+			fits := max(rangeLimit, budget)
+			return nil, &logLimitError{
+				message: fmt.Sprintf("block range %d-%d exceeds the limit of %d blocks", begin, end, fits),
+				fits:    &LogRange{FromBlock: hexutil.Uint64(begin), ToBlock: hexutil.Uint64(begin + fits - 1)},
+			}
+		}
+		chunk = rangeLimit
+	}
+	var logs []*types.Log
+	for from := begin; from <= end; from += chunk {
+		var (
+			to       = min(end, from+chunk-1)
+			sub      = sys.NewRangeFilter(int64(from), int64(to), f.addresses, f.topics)
+			overflow *types.Log
+		)
+		err := sub.rangeLogsUntil(ctx, func(log *types.Log) bool {
+			if resultLimit > 0 && len(logs) >= resultLimit {
+				overflow = log
+				return false
+			}
+			logs = append(logs, log)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		if overflow != nil {
+			// The blocks preceding the one of the first excess log fit.
+			err := &logLimitError{message: fmt.Sprintf("query returned more than %d results", resultLimit)}
+			if overflow.BlockNumber > begin {
+				err.fits = &LogRange{FromBlock: hexutil.Uint64(begin), ToBlock: hexutil.Uint64(overflow.BlockNumber - 1)}
+			}
+			return nil, err
+		}
+	}
+	return logs, nil
+}
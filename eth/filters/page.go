@@ -0,0 +1,148 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// defaultLogPageSize is the number of logs returned per page if the request
+	// does not specify a page size.
+	defaultLogPageSize = 1000
+
+	// maxLogPageSize is the maximum number of logs returned per page.
+	maxLogPageSize = 10000
+)
+
+var (
+	errInvalidCursor = errors.New("invalid cursor")
+	errCursorReorged = errors.New("cursor invalidated by chain reorganisation")
+)
+
+// LogPage is a page of the logs matching a filter. If more logs might follow,
+// the cursor is set and can be passed to the next request to resume after the
+// last log of the page.
+type LogPage struct {
+	Logs   []*types.Log  `json:"logs"`
+	Cursor hexutil.Bytes `json:"cursor,omitempty"`
+}
+
+// logCursor identifies the position of the last log of a page.
+type logCursor struct {
+	Number uint64
+	Hash   common.Hash
+	Index  uint
+}
+
+// newLogCursor returns the cursor pointing at the given log.
+func newLogCursor(log *types.Log) *logCursor {
+	return &logCursor{Number: log.BlockNumber, Hash: log.BlockHash, Index: log.Index}
+}
+
+// decodeLogCursor decodes a cursor returned by a previous request.
+func decodeLogCursor(blob []byte) (*logCursor, error) {
+	cursor := new(logCursor)
+	if err := rlp.DecodeBytes(blob, cursor); err != nil {
+		return nil, errInvalidCursor
+	}
+	return cursor, nil
+}
+
+// encode serializes the cursor into its opaque representation.
+func (c *logCursor) encode() hexutil.Bytes {
+	blob, _ := rlp.EncodeToBytes(c)
+	return blob
+}
+
+// before reports whether the cursor is positioned before the given log. A nil
+// cursor precedes all logs.
+func (c *logCursor) before(log *types.Log) bool {
+	if c == nil {
+		return true
+	}
+	return log.BlockNumber > c.Number || (log.BlockNumber == c.Number && log.Index > c.Index)
+}
+
+// pagedLogs retrieves at most limit logs matching the filter criteria which are
+// positioned after the cursor. If further logs follow, the cursor of the last
+// returned log is returned as well. Logs beyond the page are not collected.
+func (f *Filter) pagedLogs(ctx context.Context, limit int, after *logCursor) ([]*types.Log, *logCursor, error) {
+	var logs []*types.Log
+
+	// Single blocks are bounded in size, filter them as a whole.
+	if f.block != nil {
+		if after != nil && after.Hash != *f.block {
+			return nil, nil, errInvalidCursor
+		}
+		found, err := f.Logs(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, log := range found {
+			if !after.before(log) {
+				continue
+			}
+			if len(logs) == limit {
+				return logs, newLogCursor(logs[len(logs)-1]), nil
+			}
+			logs = append(logs, log)
+		}
+		return logs, nil, nil
+	}
+	if err := f.resolveRange(ctx); err != nil {
+		return nil, nil, err
+	}
+	// Resume from the block of the cursor, provided it is still canonical.
+	if after != nil {
+		if int64(after.Number) < f.begin || int64(after.Number) > f.end {
+			return nil, nil, errInvalidCursor
+		}
+		header, err := f.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(after.Number))
+		if err != nil {
+			return nil, nil, err
+		}
+		if header == nil || header.Hash() != after.Hash {
+			return nil, nil, errCursorReorged
+		}
+		f.begin = int64(after.Number)
+	}
+	var next *logCursor
+	err := f.rangeLogsUntil(ctx, func(log *types.Log) bool {
+		if !after.before(log) {
+			return true
+		}
+		if len(logs) < limit {
+			logs = append(logs, log)
+			return true
+		}
+		// The page is full and more logs follow.
+		next = newLogCursor(logs[len(logs)-1])
+		return false
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return logs, next, nil
+}
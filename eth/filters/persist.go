@@ -0,0 +1,300 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxBlockReplay is the maximum number of blocks replayed when reattaching a
+// persisted block filter.
+const maxBlockReplay = 8192
+
+// storedFilter is the definition of an installed log or block filter persisted
+// in the database, so polling clients can resume it after a restart.
+type storedFilter struct {
+	Type  Type                 `json:"type"`
+	Crit  ethereum.FilterQuery `json:"criteria"`
+	Last  uint64               `json:"last"`  // number of the last block delivered to the client
+	Owner common.Hash          `json:"owner"` // identity of the client which installed the filter
+}
+
+// filterRecord tracks the persisted definition of a filter. The owner is fixed,
+// the other fields are protected by the store lock of the API.
+type filterRecord struct {
+	owner   common.Hash // identity of the client which installed the filter
+	last    uint64      // last delivered block recorded in the database
+	written bool        // whether the filter is recorded in the database
+	deleted bool        // whether the filter was uninstalled, blocking further writes
+}
+
+// filterOwner identifies the client of a request by the credentials of its
+// connection: its API key, the scopes of its token, or the host it connects from
+// if it has neither. As persisted filters outlive the connections, they are only
+// accessible to the client which installed them, identified this way.
+func filterOwner(ctx context.Context) common.Hash {
+	var (
+		info = rpc.PeerInfoFromContext(ctx)
+		id   string
+	)
+	switch {
+	case info.APIKey != "":
+		id = "key:" + info.APIKey
+	case info.Scopes != nil:
+		id = "scopes:" + strings.Join(info.Scopes, ",")
+	default:
+		host := info.RemoteAddr
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		id = "host:" + host
+	}
+	return crypto.Keccak256Hash([]byte(id))
+}
+
+// ownedBy reports whether the filter is accessible to the given client, which is
+// always the case for filters that aren't persisted.
+func (f *filter) ownedBy(owner common.Hash) bool {
+	return f.record == nil || f.record.owner == owner
+}
+
+// persisted reports whether the given filter is kept across restarts.
+func (api *FilterAPI) persisted(f *filter) bool {
+	return api.sys.cfg.Persist && (f.typ == LogsSubscription || f.typ == BlocksSubscription)
+}
+
+// restoreFilters loads the persisted filters as dormant ones, without an event
+// subscription. They are subject to the regular timeout unless reattached.
+func (api *FilterAPI) restoreFilters() {
+	db := api.sys.backend.ChainDb()
+	for id, blob := range rawdb.ReadFilters(db) {
+		var stored storedFilter
+		if err := json.Unmarshal(blob, &stored); err != nil {
+			log.Warn("Dropping invalid persisted filter", "id", id, "err", err)
+			rawdb.DeleteFilter(db, id)
+			continue
+		}
+		api.filters[rpc.ID(id)] = &filter{
+			typ:      stored.Type,
+			crit:     FilterCriteria(stored.Crit),
+			last:     stored.Last,
+			deadline: time.NewTimer(api.timeout),
+			record:   &filterRecord{owner: stored.Owner, last: stored.Last, written: true},
+		}
+	}
+	if len(api.filters) > 0 {
+		log.Info("Restored persisted filters", "count", len(api.filters))
+	}
+}
+
+// storeFilter persists the definition of a filter, recording the given block as
+// the last one delivered to the client. The filter is only written when it is
+// installed and when the delivered block moves, not after it was uninstalled.
+// It must not be called with the filters lock held.
+func (api *FilterAPI) storeFilter(id rpc.ID, f *filter, last uint64) {
+	r := f.record
+	if r == nil {
+		return
+	}
+	api.storeMu.Lock()
+	defer api.storeMu.Unlock()
+
+	if r.deleted || (r.written && last <= r.last) {
+		return
+	}
+	blob, err := json.Marshal(&storedFilter{Type: f.typ, Crit: ethereum.FilterQuery(f.crit), Last: last, Owner: r.owner})
+	if err != nil {
+		log.Error("Failed to encode filter", "id", id, "err", err)
+		return
+	}
+	rawdb.WriteFilter(api.sys.backend.ChainDb(), string(id), blob)
+	r.written, r.last = true, last
+}
+
+// forgetFilter removes the persisted definition of an uninstalled filter. It must
+// not be called with the filters lock held.
+func (api *FilterAPI) forgetFilter(id rpc.ID, f *filter) {
+	r := f.record
+	if r == nil {
+		return
+	}
+	api.storeMu.Lock()
+	defer api.storeMu.Unlock()
+
+	r.deleted = true
+	if r.written {
+		rawdb.DeleteFilter(api.sys.backend.ChainDb(), string(id))
+	}
+}
+
+// ReattachFilter resumes a persisted filter restored after a restart. The changes
+// since the block following the last one delivered by eth_getFilterChanges are
+// replayed, unless a different starting block is requested. Polling a restored
+// filter reattaches it implicitly. Only the client which installed the filter
+// can reattach it.
+func (api *FilterAPI) ReattachFilter(ctx context.Context, id rpc.ID, fromBlock *hexutil.Uint64) error {
+	api.filtersMu.Lock()
+	f, found := api.filters[id]
+	api.filtersMu.Unlock()
+
+	if !found || !f.ownedBy(filterOwner(ctx)) {
+		return errFilterNotFound
+	}
+	var from *uint64
+	if fromBlock != nil {
+		from = (*uint64)(fromBlock)
+	}
+	return api.reattach(ctx, id, from)
+}
+
+// reattach subscribes a dormant filter to events and queues the changes of the
+// replayed blocks for the next poll.
+func (api *FilterAPI) reattach(ctx context.Context, id rpc.ID, from *uint64) error {
+	api.reattachMu.Lock()
+	defer api.reattachMu.Unlock()
+
+	api.filtersMu.Lock()
+	dormant, found := api.filters[id]
+	api.filtersMu.Unlock()
+
+	if !found {
+		return errFilterNotFound
+	}
+	if dormant.s != nil {
+		return errFilterActive
+	}
+	start := dormant.last + 1
+	if from != nil {
+		start = *from
+	}
+	f := &filter{typ: dormant.typ, crit: dormant.crit, deadline: time.NewTimer(api.timeout), record: dormant.record}
+
+	// Subscribe before replaying, so no block is missed in between. Changes
+	// received from both are deduplicated.
+	switch f.typ {
+	case BlocksSubscription:
+		api.subscribeBlocks(id, f)
+	case LogsSubscription:
+		if err := api.subscribeLogs(id, f); err != nil {
+			return err
+		}
+	default:
+		return errFilterNotFound
+	}
+	head := api.sys.backend.CurrentHeader().Number.Uint64()
+	if err := api.replay(ctx, f, start, head); err != nil {
+		f.deadline.Stop()
+		f.s.Unsubscribe()
+		return err
+	}
+	dormant.deadline.Stop()
+
+	api.filtersMu.Lock()
+	api.filters[id] = f
+	api.filtersMu.Unlock()
+
+	log.Debug("Reattached persisted filter", "id", id, "from", start, "head", head)
+	return nil
+}
+
+// replay queues the changes of the given block range for the next poll of the
+// filter, ahead of the changes received from the subscription.
+func (api *FilterAPI) replay(ctx context.Context, f *filter, start, end uint64) error {
+	switch f.typ {
+	case BlocksSubscription:
+		if start > end {
+			return nil
+		}
+		if end-start >= maxBlockReplay {
+			return fmt.Errorf("replay of blocks %d-%d exceeds the limit of %d blocks", start, end, maxBlockReplay)
+		}
+		var hashes []common.Hash
+		for n := start; n <= end; n++ {
+			header, err := api.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(n))
+			if err != nil {
+				return err
+			}
+			if header == nil {
+				return fmt.Errorf("block #%d not found", n)
+			}
+			hashes = append(hashes, header.Hash())
+		}
+		api.filtersMu.Lock()
+		defer api.filtersMu.Unlock()
+
+		seen := make(map[common.Hash]struct{}, len(hashes))
+		for _, hash := range hashes {
+			seen[hash] = struct{}{}
+		}
+		for _, hash := range f.hashes {
+			if _, ok := seen[hash]; !ok {
+				hashes = append(hashes, hash)
+			}
+		}
+		f.hashes = hashes
+		f.last = max(f.last, end)
+
+	case LogsSubscription:
+		if f.crit.BlockHash != nil {
+			return nil
+		}
+		if from := f.crit.FromBlock; from != nil && from.Sign() > 0 {
+			start = max(start, from.Uint64())
+		}
+		if to := f.crit.ToBlock; to != nil && to.Sign() > 0 {
+			end = min(end, to.Uint64())
+		}
+		if start > end {
+			return nil
+		}
+		logs, err := api.sys.limitedLogs(ctx, api.sys.NewRangeFilter(int64(start), int64(end), f.crit.Addresses, f.crit.Topics))
+		if err != nil {
+			return err
+		}
+		api.filtersMu.Lock()
+		defer api.filtersMu.Unlock()
+
+		type logKey struct {
+			block common.Hash
+			index uint
+		}
+		seen := make(map[logKey]struct{}, len(logs))
+		for _, l := range logs {
+			seen[logKey{l.BlockHash, l.Index}] = struct{}{}
+		}
+		for _, l := range f.logs {
+			if _, ok := seen[logKey{l.BlockHash, l.Index}]; !ok || l.Removed {
+				logs = append(logs, l)
+			}
+		}
+		f.logs = logs
+	}
+	return nil
+}
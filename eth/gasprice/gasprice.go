@@ -47,6 +47,7 @@ type Config struct {
 	MaxBlockHistory  uint64
 	Default          *big.Int `toml:",omitempty"`
 	MaxPrice         *big.Int `toml:",omitempty"`
+	MinPrice         *big.Int `toml:",omitempty"`
 	IgnorePrice      *big.Int `toml:",omitempty"`
 }
 
@@ -67,6 +68,7 @@ type Oracle struct {
 	lastHead    common.Hash
 	lastPrice   *big.Int
 	maxPrice    *big.Int
+	minPrice    *big.Int
 	ignorePrice *big.Int
 	cacheLock   sync.RWMutex
 	fetchLock   sync.Mutex
@@ -98,6 +100,17 @@ func NewOracle(backend OracleBackend, params Config) *Oracle {
 		maxPrice = DefaultMaxPrice
 		log.Warn("Sanitizing invalid gasprice oracle price cap", "provided", params.MaxPrice, "updated", maxPrice)
 	}
+	minPrice := params.MinPrice
+	if minPrice != nil && minPrice.Sign() <= 0 {
+		minPrice = nil
+	}
+	if minPrice != nil {
+		if minPrice.Cmp(maxPrice) > 0 {
+			minPrice = maxPrice
+			log.Warn("Sanitizing invalid gasprice oracle price floor", "provided", params.MinPrice, "updated", minPrice)
+		}
+		log.Info("Gasprice oracle is using price floor", "floor", minPrice)
+	}
 	ignorePrice := params.IgnorePrice
 	if ignorePrice == nil || ignorePrice.Int64() <= 0 {
 		ignorePrice = DefaultIgnorePrice
@@ -133,6 +146,7 @@ func NewOracle(backend OracleBackend, params Config) *Oracle {
 		backend:          backend,
 		lastPrice:        params.Default,
 		maxPrice:         maxPrice,
+		minPrice:         minPrice,
 		ignorePrice:      ignorePrice,
 		checkBlocks:      blocks,
 		percentile:       percent,
@@ -215,6 +229,9 @@ func (oracle *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
 	if price.Cmp(oracle.maxPrice) > 0 {
 		price = new(big.Int).Set(oracle.maxPrice)
 	}
+	if oracle.minPrice != nil && price.Cmp(oracle.minPrice) < 0 {
+		price = new(big.Int).Set(oracle.minPrice)
+	}
 	oracle.cacheLock.Lock()
 	oracle.lastHead = headHash
 	oracle.lastPrice = price
@@ -262,3 +262,34 @@ func TestSuggestTipCap(t *testing.T) {
 		}
 	}
 }
+
+func TestSuggestTipCapBounds(t *testing.T) {
+	var cases = []struct {
+		min, max *big.Int
+		expect   *big.Int
+	}{
+		{nil, nil, big.NewInt(params.GWei * 30)},
+		{big.NewInt(params.GWei * 10), nil, big.NewInt(params.GWei * 30)},                          // Floor below the sample
+		{big.NewInt(params.GWei * 40), nil, big.NewInt(params.GWei * 40)},                          // Floor above the sample
+		{nil, big.NewInt(params.GWei * 20), big.NewInt(params.GWei * 20)},                          // Ceiling below the sample
+		{big.NewInt(params.GWei * 40), big.NewInt(params.GWei * 35), big.NewInt(params.GWei * 35)}, // Floor capped by ceiling
+	}
+	for i, c := range cases {
+		backend := newTestBackend(t, big.NewInt(0), nil, false)
+		oracle := NewOracle(backend, Config{
+			Blocks:     3,
+			Percentile: 60,
+			Default:    big.NewInt(params.GWei),
+			MinPrice:   c.min,
+			MaxPrice:   c.max,
+		})
+		got, err := oracle.SuggestTipCap(context.Background())
+		backend.teardown()
+		if err != nil {
+			t.Fatalf("case %d: failed to retrieve recommended gas price: %v", i, err)
+		}
+		if got.Cmp(c.expect) != 0 {
+			t.Errorf("case %d: gas price mismatch, want %d, got %d", i, c.expect, got)
+		}
+	}
+}
@@ -53,6 +53,10 @@ const (
 	// All transactions with a higher size will be announced and need to be fetched
 	// by the peer.
 	txMaxBroadcastSize = 4096
+
+	// txSyncLimit is the max number of pending transactions announced to a newly
+	// connected peer.
+	txSyncLimit = 4096
 )
 
 var syncChallengeTimeout = 15 * time.Second // Time allowance for a node to reply to the sync progress challenge
@@ -93,6 +97,14 @@ type handlerConfig struct {
 	BloomCache     uint64                 // Megabytes to alloc for snap sync bloom
 	EventMux       *event.TypeMux         // Legacy event mux, deprecate for `feed`
 	RequiredBlocks map[uint64]common.Hash // Hard coded map of required block hashes for sync challenges
+
+	CheckpointNumber uint64      // Number of the trusted checkpoint block to sync to
+	CheckpointHash   common.Hash // Hash of the trusted checkpoint block, sync from the network if zero
+
+	TxBroadcastFanout  int        // Number of peers sent full transactions, sqrt of the peer count if zero
+	TxBroadcastMaxSize uint64     // Transactions larger than this are only announced, txMaxBroadcastSize if zero
+	TxValidatorPeers   []enode.ID // Peers receiving full transactions first
+	TxSyncLimit        int        // Pending transactions announced to new peers, txSyncLimit if zero
 }
 
 type handler struct {
@@ -108,6 +120,11 @@ type handler struct {
 	chain    *core.BlockChain
 	maxPeers int
 
+	txFanout       int
+	txMaxBroadcast uint64
+	txValidators   map[enode.ID]struct{}
+	txSyncLimit    int
+
 	downloader *downloader.Downloader
 	txFetcher  *fetcher.TxFetcher
 	peers      *peerSet
@@ -118,6 +135,9 @@ type handler struct {
 
 	requiredBlocks map[uint64]common.Hash
 
+	checkpointNumber uint64
+	checkpointHash   common.Hash
+
 	// channels for fetcher, syncer, txsyncLoop
 	quitSync chan struct{}
 
@@ -134,18 +154,33 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		config.EventMux = new(event.TypeMux) // Nicety initialization for tests
 	}
 	h := &handler{
-		nodeID:         config.NodeID,
-		networkID:      config.Network,
-		forkFilter:     forkid.NewFilter(config.Chain),
-		eventMux:       config.EventMux,
-		database:       config.Database,
-		txpool:         config.TxPool,
-		chain:          config.Chain,
-		peers:          newPeerSet(),
-		requiredBlocks: config.RequiredBlocks,
-		quitSync:       make(chan struct{}),
-		handlerDoneCh:  make(chan struct{}),
-		handlerStartCh: make(chan struct{}),
+		nodeID:           config.NodeID,
+		networkID:        config.Network,
+		forkFilter:       forkid.NewFilter(config.Chain),
+		eventMux:         config.EventMux,
+		database:         config.Database,
+		txpool:           config.TxPool,
+		chain:            config.Chain,
+		peers:            newPeerSet(),
+		requiredBlocks:   config.RequiredBlocks,
+		checkpointNumber: config.CheckpointNumber,
+		checkpointHash:   config.CheckpointHash,
+		quitSync:         make(chan struct{}),
+		handlerDoneCh:    make(chan struct{}),
+		handlerStartCh:   make(chan struct{}),
+		txFanout:         config.TxBroadcastFanout,
+		txMaxBroadcast:   config.TxBroadcastMaxSize,
+		txValidators:     make(map[enode.ID]struct{}, len(config.TxValidatorPeers)),
+		txSyncLimit:      config.TxSyncLimit,
+	}
+	if h.txMaxBroadcast == 0 {
+		h.txMaxBroadcast = txMaxBroadcastSize
+	}
+	if h.txSyncLimit == 0 {
+		h.txSyncLimit = txSyncLimit
+	}
+	for _, id := range config.TxValidatorPeers {
+		h.txValidators[id] = struct{}{}
 	}
 	if config.Sync == downloader.FullSync {
 		// The database seems empty as the current block is the genesis. Yet the snap
@@ -192,7 +227,10 @@ func newHandler(config *handlerConfig) (*handler, error) {
 	addTxs := func(txs []*types.Transaction) []error {
 		return h.txpool.Add(txs, false, false)
 	}
-	h.txFetcher = fetcher.NewTxFetcher(h.txpool.Has, addTxs, fetchTx, h.removePeer)
+	dropTxPeer := func(peer string) {
+		h.removePeer(peer, p2p.PenaltyInvalidMessage)
+	}
+	h.txFetcher = fetcher.NewTxFetcher(h.txpool.Has, addTxs, fetchTx, dropTxPeer)
 	return h, nil
 }
 
@@ -272,8 +310,8 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 			}
 		}
 	}
-	// Ignore maxPeers if this is a trusted peer
-	if !peer.Peer.Info().Network.Trusted {
+	// Ignore maxPeers if this is a trusted peer or uses a reserved slot
+	if info := peer.Peer.Info().Network; !info.Trusted && !info.Reserved {
 		if reject || h.peers.len() >= h.maxPeers {
 			return p2p.DiscTooManyPeers
 		}
@@ -348,7 +386,7 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 				res.Done <- nil
 			case <-timeout.C:
 				peer.Log().Warn("Required block challenge timed out, dropping", "addr", peer.RemoteAddr(), "type", peer.Name())
-				h.removePeer(peer.ID())
+				h.removePeer(peer.ID(), p2p.PenaltyTimeout)
 			}
 		}(number, hash, req)
 	}
@@ -380,10 +418,13 @@ func (h *handler) runSnapExtension(peer *snap.Peer, handler snap.Handler) error
 	return handler(peer)
 }
 
-// removePeer requests disconnection of a peer.
-func (h *handler) removePeer(id string) {
+// removePeer requests disconnection of a peer. It is called by the sync and
+// fetch mechanisms on misbehaviour, so the peer's score is lowered by the given
+// penalty as well.
+func (h *handler) removePeer(id string, penalty int) {
 	peer := h.peers.peer(id)
 	if peer != nil {
+		peer.Peer.Penalize(penalty)
 		peer.Peer.Disconnect(p2p.DiscUselessPeer)
 	}
 }
@@ -434,6 +475,28 @@ func (h *handler) Start(maxPeers int) {
 	// start peer handler tracker
 	h.wg.Add(1)
 	go h.protoTracker()
+
+	// start syncing to the trusted checkpoint, if any
+	if h.checkpointHash != (common.Hash{}) {
+		h.wg.Add(1)
+		go h.checkpointSync()
+	}
+}
+
+// checkpointSync syncs the chain to the configured trusted checkpoint, and keeps
+// following the chain of the peers afterwards.
+func (h *handler) checkpointSync() {
+	defer h.wg.Done()
+
+	mode := func() downloader.SyncMode {
+		if h.snapSync.Load() {
+			return downloader.SnapSync
+		}
+		return downloader.FullSync
+	}
+	if err := h.downloader.CheckpointSync(mode, h.checkpointHash, h.checkpointNumber, h.quitSync); err != nil {
+		log.Error("Failed to sync to checkpoint", "number", h.checkpointNumber, "hash", h.checkpointHash, "err", err)
+	}
 }
 
 func (h *handler) Stop() {
@@ -476,6 +539,13 @@ func (h *handler) BroadcastTransactions(txs types.Transactions) {
 		direct = big.NewInt(1)
 	}
 	total := new(big.Int).Exp(direct, big.NewInt(2), nil) // Stabilise total peer count a bit based on sqrt peers
+	if h.txFanout > 0 {
+		direct = big.NewInt(int64(h.txFanout))
+		total = big.NewInt(int64(max(h.txFanout, h.peers.len())))
+	}
+	// In validators-first mode, connected validators receive all transactions
+	// directly, while the rest of the peers only get announcements.
+	validatorsFirst := h.peers.anyPeer(h.txValidators)
 
 	var (
 		signer = types.LatestSignerForChainID(h.chain.Config().ChainID) // Don't care about chain status, we just need *a* sender
@@ -483,11 +553,15 @@ func (h *handler) BroadcastTransactions(txs types.Transactions) {
 		hash   = make([]byte, 32)
 	)
 	for _, tx := range txs {
+		// Conditional transactions were submitted for local inclusion only
+		if tx.Conditional() != nil {
+			continue
+		}
 		var maybeDirect bool
 		switch {
 		case tx.Type() == types.BlobTxType:
 			blobTxs++
-		case tx.Size() > txMaxBroadcastSize:
+		case tx.Size() > h.txMaxBroadcast:
 			largeTxs++
 		default:
 			maybeDirect = true
@@ -501,7 +575,10 @@ func (h *handler) BroadcastTransactions(txs types.Transactions) {
 		// `sha(self, peer, sender) mod peers < sqrt(peers)`.
 		for _, peer := range h.peers.peersWithoutTransaction(tx.Hash()) {
 			var broadcast bool
-			if maybeDirect {
+			if validatorsFirst {
+				_, validator := h.txValidators[peer.Node().ID()]
+				broadcast = maybeDirect && validator
+			} else if maybeDirect {
 				hasher.Reset()
 				hasher.Write(h.nodeID.Bytes())
 				hasher.Write(peer.Node().ID().Bytes())
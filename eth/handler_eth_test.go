@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"crypto/ecdsa"
 	"fmt"
 	"math/big"
 	"testing"
@@ -29,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/event"
@@ -367,6 +369,91 @@ func testSendTransactions(t *testing.T, protocol uint) {
 	}
 }
 
+// This test checks that the pending transactions announced to new peers are limited,
+// preferring the next transactions of as many accounts as possible.
+func TestSendTransactionsLimit(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestHandler()
+	defer handler.close()
+	handler.handler.txSyncLimit = 10
+
+	// Fill the pool with transactions of three accounts paying different tips.
+	var (
+		key1, _  = crypto.GenerateKey()
+		key2, _  = crypto.GenerateKey()
+		keys     = []*ecdsa.PrivateKey{testKey, key1, key2}
+		counts   = []int{100, 5, 5}
+		expected = make(map[common.Hash]struct{})
+		insert   []*types.Transaction
+	)
+	for i, key := range keys {
+		for nonce := 0; nonce < counts[i]; nonce++ {
+			tx := types.NewTransaction(uint64(nonce), common.Address{}, big.NewInt(0), 100000, big.NewInt(int64(i)), nil)
+			tx, _ = types.SignTx(tx, types.HomesteadSigner{}, key)
+			insert = append(insert, tx)
+
+			// Three transactions of every account fit, plus the fourth of the
+			// account paying the highest tip.
+			if nonce < 3 || (i == 2 && nonce == 3) {
+				expected[tx.Hash()] = struct{}{}
+			}
+		}
+	}
+	go handler.txpool.Add(insert, false, false)
+	time.Sleep(250 * time.Millisecond)
+
+	p2pSrc, p2pSink := p2p.MsgPipe()
+	defer p2pSrc.Close()
+	defer p2pSink.Close()
+
+	src := eth.NewPeer(eth.ETH68, p2p.NewPeerPipe(enode.ID{1}, "", nil, p2pSrc), p2pSrc, handler.txpool)
+	sink := eth.NewPeer(eth.ETH68, p2p.NewPeerPipe(enode.ID{2}, "", nil, p2pSink), p2pSink, handler.txpool)
+	defer src.Close()
+	defer sink.Close()
+
+	go handler.handler.runEthPeer(src, func(peer *eth.Peer) error {
+		return eth.Handle((*ethHandler)(handler.handler), peer)
+	})
+	var (
+		genesis = handler.chain.Genesis()
+		head    = handler.chain.CurrentBlock()
+		td      = handler.chain.GetTd(head.Hash(), head.Number.Uint64())
+	)
+	if err := sink.Handshake(1, td, head.Hash(), genesis.Hash(), forkid.NewIDWithChain(handler.chain), forkid.NewFilter(handler.chain)); err != nil {
+		t.Fatalf("failed to run protocol handshake")
+	}
+	backend := new(testEthHandler)
+	anns := make(chan []common.Hash)
+	annSub := backend.txAnnounces.Subscribe(anns)
+	defer annSub.Unsubscribe()
+
+	go eth.Handle(backend, sink)
+
+	seen := make(map[common.Hash]struct{})
+	timeout := time.After(time.Second)
+	for len(seen) < len(expected) {
+		select {
+		case hashes := <-anns:
+			for _, hash := range hashes {
+				seen[hash] = struct{}{}
+			}
+		case <-timeout:
+			t.Fatalf("announced %d transactions, want %d", len(seen), len(expected))
+		}
+	}
+	select {
+	case hashes := <-anns:
+		t.Fatalf("announced %d transactions beyond the limit", len(hashes))
+	case <-time.After(100 * time.Millisecond):
+	}
+	for hash := range seen {
+		if _, ok := expected[hash]; !ok {
+			t.Errorf("unexpected transaction announced: %x", hash)
+		}
+	}
+}
+
 // Tests that transactions get propagated to all attached peers, either via direct
 // broadcasts or via announcements/retrievals.
 func TestTransactionPropagation68(t *testing.T) { testTransactionPropagation(t, eth.ETH68) }
@@ -438,3 +525,77 @@ func testTransactionPropagation(t *testing.T, protocol uint) {
 		}
 	}
 }
+
+// Tests that in validators-first mode, transactions are sent directly to the
+// validator peers and only announced to the others.
+func TestValidatorsFirstPropagation(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestHandler()
+	defer handler.close()
+	handler.handler.txValidators = map[enode.ID]struct{}{{1}: {}}
+
+	var (
+		genesis = handler.chain.Genesis()
+		head    = handler.chain.CurrentBlock()
+		td      = handler.chain.GetTd(head.Hash(), head.Number.Uint64())
+		anns    = make([]chan []common.Hash, 2)
+		bcasts  = make([]chan []*types.Transaction, 2)
+	)
+	for i := range anns {
+		p2pSrc, p2pSink := p2p.MsgPipe()
+		defer p2pSrc.Close()
+		defer p2pSink.Close()
+
+		src := eth.NewPeer(eth.ETH68, p2p.NewPeerPipe(enode.ID{byte(i + 1)}, "", nil, p2pSrc), p2pSrc, handler.txpool)
+		sink := eth.NewPeer(eth.ETH68, p2p.NewPeerPipe(enode.ID{0}, "", nil, p2pSink), p2pSink, handler.txpool)
+		defer src.Close()
+		defer sink.Close()
+
+		go handler.handler.runEthPeer(src, func(peer *eth.Peer) error {
+			return eth.Handle((*ethHandler)(handler.handler), peer)
+		})
+		if err := sink.Handshake(1, td, head.Hash(), genesis.Hash(), forkid.NewIDWithChain(handler.chain), forkid.NewFilter(handler.chain)); err != nil {
+			t.Fatalf("failed to run protocol handshake")
+		}
+		backend := new(testEthHandler)
+		anns[i] = make(chan []common.Hash, 16)
+		annSub := backend.txAnnounces.Subscribe(anns[i])
+		defer annSub.Unsubscribe()
+		bcasts[i] = make(chan []*types.Transaction, 16)
+		bcastSub := backend.txBroadcasts.Subscribe(bcasts[i])
+		defer bcastSub.Unsubscribe()
+
+		go eth.Handle(backend, sink)
+	}
+	// Wait for both peers to be registered before adding the transaction.
+	for start := time.Now(); handler.handler.peers.len() < 2; time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > 2*time.Second {
+			t.Fatal("peers not registered")
+		}
+	}
+	tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil)
+	tx, _ = types.SignTx(tx, types.HomesteadSigner{}, testKey)
+	handler.txpool.Add([]*types.Transaction{tx}, false, false)
+
+	select {
+	case txs := <-bcasts[0]:
+		if len(txs) != 1 || txs[0].Hash() != tx.Hash() {
+			t.Fatalf("wrong transactions sent to validator: %v", txs)
+		}
+	case <-anns[0]:
+		t.Fatal("transaction announced to validator")
+	case <-time.After(2 * time.Second):
+		t.Fatal("transaction not sent to validator")
+	}
+	select {
+	case hashes := <-anns[1]:
+		if len(hashes) != 1 || hashes[0] != tx.Hash() {
+			t.Fatalf("wrong announcement: %v", hashes)
+		}
+	case <-bcasts[1]:
+		t.Fatal("transaction sent directly to non-validator")
+	case <-time.After(2 * time.Second):
+		t.Fatal("transaction not announced to non-validator")
+	}
+}
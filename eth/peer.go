@@ -24,7 +24,8 @@ import (
 // ethPeerInfo represents a short summary of the `eth` sub-protocol metadata known
 // about a connected peer.
 type ethPeerInfo struct {
-	Version uint `json:"version"` // Ethereum protocol version negotiated
+	Version uint           `json:"version"` // Ethereum protocol version negotiated
+	Stats   *eth.PeerStats `json:"stats"`   // Traffic exchanged with the peer
 }
 
 // ethPeer is a wrapper around eth.Peer to maintain a few extra metadata.
@@ -37,6 +38,7 @@ type ethPeer struct {
 func (p *ethPeer) info() *ethPeerInfo {
 	return &ethPeerInfo{
 		Version: p.Version(),
+		Stats:   p.Stats(),
 	}
 }
 
@@ -25,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/eth/protocols/snap"
 	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
 )
 
 var (
@@ -206,6 +207,22 @@ func (ps *peerSet) peersWithoutTransaction(hash common.Hash) []*ethPeer {
 	return list
 }
 
+// anyPeer reports whether any of the given nodes is connected.
+func (ps *peerSet) anyPeer(ids map[enode.ID]struct{}) bool {
+	if len(ids) == 0 {
+		return false
+	}
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	for _, p := range ps.peers {
+		if _, ok := ids[p.Node().ID()]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 // len returns if the current number of `eth` peers in the set. Since the `snap`
 // peers are tied to the existence of an `eth` connection, that will always be a
 // subset of `eth`.
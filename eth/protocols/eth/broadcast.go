@@ -77,9 +77,9 @@ func (p *Peer) broadcastTransactions() {
 			}
 			// New batch of transactions to be broadcast, queue them (with cap)
 			queue = append(queue, hashes...)
-			if len(queue) > maxQueuedTxs {
+			if len(queue) > p.queues.Txs {
 				// Fancy copy and resize to ensure buffer doesn't grow indefinitely
-				queue = queue[:copy(queue, queue[len(queue)-maxQueuedTxs:])]
+				queue = queue[:copy(queue, queue[len(queue)-p.queues.Txs:])]
 			}
 
 		case <-done:
@@ -148,9 +148,9 @@ func (p *Peer) announceTransactions() {
 			}
 			// New batch of transactions to be broadcast, queue them (with cap)
 			queue = append(queue, hashes...)
-			if len(queue) > maxQueuedTxAnns {
+			if len(queue) > p.queues.TxAnns {
 				// Fancy copy and resize to ensure buffer doesn't grow indefinitely
-				queue = queue[:copy(queue, queue[len(queue)-maxQueuedTxAnns:])]
+				queue = queue[:copy(queue, queue[len(queue)-p.queues.TxAnns:])]
 			}
 
 		case <-done:
@@ -148,8 +148,14 @@ func (p *Peer) dispatchResponse(res *Response, metadata func() interface{}) erro
 
 	select {
 	case p.resDispatch <- resOp:
-		// Ensure the response is accepted by the dispatcher
+		// Ensure the response is accepted by the dispatcher. Responses nobody is
+		// waiting for are useless, ones of the wrong type are invalid.
 		if err := <-resOp.fail; err != nil {
+			penalty := p2p.PenaltyUselessMessage
+			if errors.Is(err, errMismatchingResponseType) {
+				penalty = p2p.PenaltyInvalidMessage
+			}
+			p.Penalize(penalty)
 			return nil
 		}
 		// Request was accepted, run any postprocessing step to generate metadata
@@ -240,6 +246,7 @@ func (p *Peer) dispatcher() {
 				// with the matching request. Signal to the delivery routine that
 				// it can wait for a handler response and dispatch the data.
 				res.Time = res.recv.Sub(res.Req.Sent)
+				p.stats.response(res.code, res.Time)
 				resOp.fail <- nil
 
 				// Stop tracking the request, the response dispatcher will deliver
@@ -28,6 +28,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/p2p/msglimit"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -90,7 +91,7 @@ type TxPool interface {
 }
 
 // MakeProtocols constructs the P2P protocol definitions for `eth`.
-func MakeProtocols(backend Backend, network uint64, dnsdisc enode.Iterator) []p2p.Protocol {
+func MakeProtocols(backend Backend, network uint64, dnsdisc enode.Iterator, queues QueueLimits, limits msglimit.Limits) []p2p.Protocol {
 	protocols := make([]p2p.Protocol, 0, len(ProtocolVersions))
 	for _, version := range ProtocolVersions {
 		version := version // Closure
@@ -100,7 +101,8 @@ func MakeProtocols(backend Backend, network uint64, dnsdisc enode.Iterator) []p2
 			Version: version,
 			Length:  protocolLengths[version],
 			Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
-				peer := NewPeer(version, p, rw, backend.TxPool())
+				peer := NewPeerWithLimits(version, p, rw, backend.TxPool(), queues)
+				peer.limits = msglimit.New(limits, maxMessageSize, MessageNames)
 				defer peer.Close()
 
 				return backend.RunPeer(peer, func(peer *Peer) error {
@@ -185,10 +187,11 @@ func handleMessage(backend Backend, peer *Peer) error {
 	if err != nil {
 		return err
 	}
-	if msg.Size > maxMessageSize {
-		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
-	}
 	defer msg.Discard()
+	if err := peer.limits.Check(msg.Code, msg.Size); err != nil {
+		return err
+	}
+	peer.stats.message(msg.Code, msg.Size)
 
 	var handlers = eth68
 
@@ -204,8 +207,14 @@ func handleMessage(backend Backend, peer *Peer) error {
 			metrics.GetOrRegisterHistogramLazy(h, nil, sampler).Update(time.Since(start).Microseconds())
 		}(time.Now())
 	}
-	if handler := handlers[msg.Code]; handler != nil {
-		return handler(backend, msg, peer)
+	handler := handlers[msg.Code]
+	if handler == nil {
+		peer.stats.invalidMessages.Inc(1)
+		return fmt.Errorf("%w: %v", errInvalidMsgCode, msg.Code)
+	}
+	if err := handler(backend, msg, peer); err != nil {
+		peer.stats.invalidMessages.Inc(1)
+		return err
 	}
-	return fmt.Errorf("%w: %v", errInvalidMsgCode, msg.Code)
+	return nil
 }
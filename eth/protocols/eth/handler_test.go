@@ -499,3 +499,41 @@ func testGetBlockReceipts(t *testing.T, protocol uint) {
 		t.Errorf("receipts mismatch: %v", err)
 	}
 }
+
+// Tests that the traffic statistics of a peer are recorded.
+func TestPeerStats(t *testing.T) {
+	t.Parallel()
+
+	backend := newTestBackend(10)
+	defer backend.close()
+
+	peer, errc := newTestPeer("peer", ETH68, backend)
+	defer peer.close()
+
+	p2p.Send(peer.app, GetBlockHeadersMsg, &GetBlockHeadersPacket{
+		RequestId: 1,
+		GetBlockHeadersRequest: &GetBlockHeadersRequest{
+			Origin: HashOrNumber{Number: 1},
+			Amount: 5,
+		},
+	})
+	if msg, err := peer.app.ReadMsg(); err != nil || msg.Code != BlockHeadersMsg {
+		t.Fatalf("unexpected reply: %v %v", msg, err)
+	} else {
+		msg.Discard()
+	}
+	stats := peer.Stats()
+	if stats.HeadersServed != 5 {
+		t.Errorf("wrong number of headers served: have %d, want 5", stats.HeadersServed)
+	}
+	if m := stats.Messages["getBlockHeaders"]; m == nil || m.Count != 1 || m.Bytes == 0 {
+		t.Errorf("wrong message stats: %+v", m)
+	}
+
+	// Invalid messages are counted before the peer is dropped.
+	p2p.Send(peer.app, 0x1f, []byte{})
+	<-errc
+	if stats := peer.Stats(); stats.InvalidMessages != 1 {
+		t.Errorf("wrong number of invalid messages: have %d, want 1", stats.InvalidMessages)
+	}
+}
@@ -427,6 +427,7 @@ func handleTransactions(backend Backend, msg Decoder, peer *Peer) error {
 		}
 		peer.markTransaction(tx.Hash())
 	}
+	peer.stats.txsReceived.Inc(int64(len(txs)))
 	return backend.Handle(peer, &txs)
 }
 
@@ -448,6 +449,7 @@ func handlePooledTransactions(backend Backend, msg Decoder, peer *Peer) error {
 		peer.markTransaction(tx.Hash())
 	}
 	requestTracker.Fulfil(peer.id, peer.version, PooledTransactionsMsg, txs.RequestId)
+	peer.stats.txsReceived.Inc(int64(len(txs.PooledTransactionsResponse)))
 
 	return backend.Handle(peer, &txs.PooledTransactionsResponse)
 }
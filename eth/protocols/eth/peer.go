@@ -25,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/msglimit"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
@@ -57,6 +58,9 @@ type Peer struct {
 	knownTxs    *knownCache        // Set of transaction hashes known to be known by this peer
 	txBroadcast chan []common.Hash // Channel used to queue transaction propagation requests
 	txAnnounce  chan []common.Hash // Channel used to queue transaction announcement requests
+	queues      QueueLimits        // Limits of the transaction propagation queues
+	limits      *msglimit.Limiter  // Limits of the received messages
+	stats       *peerStats         // Traffic statistics of the peer
 
 	reqDispatch chan *request  // Dispatch channel to send requests and track then until fulfillment
 	reqCancel   chan *cancel   // Dispatch channel to cancel pending requests and untrack them
@@ -66,9 +70,28 @@ type Peer struct {
 	lock sync.RWMutex  // Mutex protecting the internal fields
 }
 
+// QueueLimits configures the transaction propagation queues of a peer. Zero
+// values select the defaults.
+type QueueLimits struct {
+	Txs    int // Transactions queued for broadcast before dropping older ones
+	TxAnns int // Transaction announcements queued before dropping older ones
+}
+
 // NewPeer creates a wrapper for a network connection and negotiated  protocol
 // version.
 func NewPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter, txpool TxPool) *Peer {
+	return NewPeerWithLimits(version, p, rw, txpool, QueueLimits{})
+}
+
+// NewPeerWithLimits creates a peer like NewPeer, with custom limits of the
+// transaction propagation queues.
+func NewPeerWithLimits(version uint, p *p2p.Peer, rw p2p.MsgReadWriter, txpool TxPool, queues QueueLimits) *Peer {
+	if queues.Txs <= 0 {
+		queues.Txs = maxQueuedTxs
+	}
+	if queues.TxAnns <= 0 {
+		queues.TxAnns = maxQueuedTxAnns
+	}
 	peer := &Peer{
 		id:          p.ID().String(),
 		Peer:        p,
@@ -77,6 +100,9 @@ func NewPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter, txpool TxPool) *Pe
 		knownTxs:    newKnownCache(maxKnownTxs),
 		txBroadcast: make(chan []common.Hash),
 		txAnnounce:  make(chan []common.Hash),
+		queues:      queues,
+		limits:      msglimit.New(msglimit.Limits{}, maxMessageSize, MessageNames),
+		stats:       newPeerStats(p.ID().String()),
 		reqDispatch: make(chan *request),
 		reqCancel:   make(chan *cancel),
 		resDispatch: make(chan *response),
@@ -96,6 +122,7 @@ func NewPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter, txpool TxPool) *Pe
 // clean it up!
 func (p *Peer) Close() {
 	close(p.term)
+	p.stats.close()
 }
 
 // ID retrieves the peer's unique identifier.
@@ -198,6 +225,7 @@ func (p *Peer) AsyncSendPooledTransactionHashes(hashes []common.Hash) {
 func (p *Peer) ReplyPooledTransactionsRLP(id uint64, hashes []common.Hash, txs []rlp.RawValue) error {
 	// Mark all the transactions as known, but ensure we don't overflow our limits
 	p.knownTxs.Add(hashes...)
+	p.stats.txsServed.Inc(int64(len(txs)))
 
 	// Not packed into PooledTransactionsResponse to avoid RLP decoding
 	return p2p.Send(p.rw, PooledTransactionsMsg, &PooledTransactionsRLPPacket{
@@ -208,6 +236,7 @@ func (p *Peer) ReplyPooledTransactionsRLP(id uint64, hashes []common.Hash, txs [
 
 // ReplyBlockHeadersRLP is the response to GetBlockHeaders.
 func (p *Peer) ReplyBlockHeadersRLP(id uint64, headers []rlp.RawValue) error {
+	p.stats.headersServed.Inc(int64(len(headers)))
 	return p2p.Send(p.rw, BlockHeadersMsg, &BlockHeadersRLPPacket{
 		RequestId:               id,
 		BlockHeadersRLPResponse: headers,
@@ -216,6 +245,7 @@ func (p *Peer) ReplyBlockHeadersRLP(id uint64, headers []rlp.RawValue) error {
 
 // ReplyBlockBodiesRLP is the response to GetBlockBodies.
 func (p *Peer) ReplyBlockBodiesRLP(id uint64, bodies []rlp.RawValue) error {
+	p.stats.bodiesServed.Inc(int64(len(bodies)))
 	// Not packed into BlockBodiesResponse to avoid RLP decoding
 	return p2p.Send(p.rw, BlockBodiesMsg, &BlockBodiesRLPPacket{
 		RequestId:              id,
@@ -225,6 +255,7 @@ func (p *Peer) ReplyBlockBodiesRLP(id uint64, bodies []rlp.RawValue) error {
 
 // ReplyReceiptsRLP is the response to GetReceipts.
 func (p *Peer) ReplyReceiptsRLP(id uint64, receipts []rlp.RawValue) error {
+	p.stats.receiptsServed.Inc(int64(len(receipts)))
 	return p2p.Send(p.rw, ReceiptsMsg, &ReceiptsRLPPacket{
 		RequestId:           id,
 		ReceiptsRLPResponse: receipts,
@@ -0,0 +1,187 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p/msglimit"
+)
+
+// peerMetricsPrefix is the prefix of the per-peer metrics.
+const peerMetricsPrefix = "eth/peers/"
+
+// MessageNames are the names of the protocol messages used in the statistics and
+// the limits.
+var MessageNames = msglimit.Names{
+	StatusMsg:                     "status",
+	NewBlockHashesMsg:             "newBlockHashes",
+	TransactionsMsg:               "transactions",
+	GetBlockHeadersMsg:            "getBlockHeaders",
+	BlockHeadersMsg:               "blockHeaders",
+	GetBlockBodiesMsg:             "getBlockBodies",
+	BlockBodiesMsg:                "blockBodies",
+	NewBlockMsg:                   "newBlock",
+	NewPooledTransactionHashesMsg: "newPooledTransactionHashes",
+	GetPooledTransactionsMsg:      "getPooledTransactions",
+	PooledTransactionsMsg:         "pooledTransactions",
+	GetReceiptsMsg:                "getReceipts",
+	ReceiptsMsg:                   "receipts",
+}
+
+// PeerStats is a summary of the traffic exchanged with a peer, used to identify
+// peers that take more than they give, or misbehave.
+type PeerStats struct {
+	HeadersServed   int64                    `json:"headersServed"`
+	BodiesServed    int64                    `json:"bodiesServed"`
+	ReceiptsServed  int64                    `json:"receiptsServed"`
+	TxsServed       int64                    `json:"txsServed"`
+	TxsReceived     int64                    `json:"txsReceived"`
+	InvalidMessages int64                    `json:"invalidMessages"`
+	Messages        map[string]*MessageStats `json:"messages"` // received messages by type
+}
+
+// MessageStats counts the messages of a type received from a peer.
+type MessageStats struct {
+	Count uint64 `json:"count"`
+	Bytes uint64 `json:"bytes"`
+
+	// Latency is the average time in milliseconds it took the peer to answer our
+	// requests. It is only set for response messages.
+	Latency float64 `json:"latency,omitempty"`
+}
+
+// peerStats tracks the statistics of a peer. The counters are also exported as
+// metrics if metrics collection is enabled.
+type peerStats struct {
+	headersServed   metrics.Counter
+	bodiesServed    metrics.Counter
+	receiptsServed  metrics.Counter
+	txsServed       metrics.Counter
+	txsReceived     metrics.Counter
+	invalidMessages metrics.Counter
+	latency         metrics.Timer
+
+	registry metrics.Registry // nil if metrics are disabled
+	names    []string
+
+	mu       sync.Mutex
+	messages map[uint64]*messageStats
+}
+
+type messageStats struct {
+	count, bytes uint64
+	responses    uint64        // responses matched to requests
+	latency      time.Duration // total response time
+}
+
+func newPeerStats(id string) *peerStats {
+	s := &peerStats{messages: make(map[uint64]*messageStats)}
+	if metrics.Enabled {
+		s.registry = metrics.NewPrefixedChildRegistry(metrics.DefaultRegistry, peerMetricsPrefix+id[:16]+"/")
+	}
+	s.headersServed = s.counter("headers/served")
+	s.bodiesServed = s.counter("bodies/served")
+	s.receiptsServed = s.counter("receipts/served")
+	s.txsServed = s.counter("txs/served")
+	s.txsReceived = s.counter("txs/received")
+	s.invalidMessages = s.counter("invalid")
+	s.latency = metrics.NilTimer{}
+	if s.registry != nil {
+		s.latency = metrics.NewRegisteredTimer("latency", s.registry)
+		s.names = append(s.names, "latency")
+	}
+	return s
+}
+
+func (s *peerStats) counter(name string) metrics.Counter {
+	if s.registry == nil {
+		return metrics.NewCounterForced()
+	}
+	s.names = append(s.names, name)
+	return metrics.NewRegisteredCounterForced(name, s.registry)
+}
+
+// close removes the metrics of the peer.
+func (s *peerStats) close() {
+	if s.registry == nil {
+		return
+	}
+	for _, name := range s.names {
+		s.registry.Unregister(name)
+	}
+}
+
+// message records a received message.
+func (s *peerStats) message(code uint64, size uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := s.messages[code]
+	if m == nil {
+		m = new(messageStats)
+		s.messages[code] = m
+	}
+	m.count++
+	m.bytes += uint64(size)
+}
+
+// response records the time it took to answer a request.
+func (s *peerStats) response(code uint64, elapsed time.Duration) {
+	s.latency.Update(elapsed)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := s.messages[code]
+	if m == nil {
+		m = new(messageStats)
+		s.messages[code] = m
+	}
+	m.responses++
+	m.latency += elapsed
+}
+
+// snapshot returns the current statistics.
+func (s *peerStats) snapshot() *PeerStats {
+	stats := &PeerStats{
+		HeadersServed:   s.headersServed.Snapshot().Count(),
+		BodiesServed:    s.bodiesServed.Snapshot().Count(),
+		ReceiptsServed:  s.receiptsServed.Snapshot().Count(),
+		TxsServed:       s.txsServed.Snapshot().Count(),
+		TxsReceived:     s.txsReceived.Snapshot().Count(),
+		InvalidMessages: s.invalidMessages.Snapshot().Count(),
+		Messages:        make(map[string]*MessageStats),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for code, m := range s.messages {
+		ms := &MessageStats{Count: m.count, Bytes: m.bytes}
+		if m.responses > 0 {
+			ms.Latency = float64(m.latency/time.Duration(m.responses)) / float64(time.Millisecond)
+		}
+		stats.Messages[MessageNames.Name(code)] = ms
+	}
+	return stats
+}
+
+// Stats returns the traffic statistics of the peer.
+func (p *Peer) Stats() *PeerStats {
+	return p.stats.snapshot()
+}
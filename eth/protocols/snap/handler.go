@@ -29,6 +29,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/p2p/msglimit"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/trie/trienode"
 )
@@ -82,7 +83,7 @@ type Backend interface {
 }
 
 // MakeProtocols constructs the P2P protocol definitions for `snap`.
-func MakeProtocols(backend Backend, dnsdisc enode.Iterator) []p2p.Protocol {
+func MakeProtocols(backend Backend, dnsdisc enode.Iterator, limits msglimit.Limits) []p2p.Protocol {
 	// Filter the discovery iterator for nodes advertising snap support.
 	dnsdisc = enode.Filter(dnsdisc, func(n *enode.Node) bool {
 		var snap enrEntry
@@ -98,7 +99,9 @@ func MakeProtocols(backend Backend, dnsdisc enode.Iterator) []p2p.Protocol {
 			Version: version,
 			Length:  protocolLengths[version],
 			Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
-				return backend.RunPeer(NewPeer(version, p, rw), func(peer *Peer) error {
+				peer := NewPeer(version, p, rw)
+				peer.limits = msglimit.New(limits, maxMessageSize, MessageNames)
+				return backend.RunPeer(peer, func(peer *Peer) error {
 					return Handle(backend, peer)
 				})
 			},
@@ -135,10 +138,10 @@ func HandleMessage(backend Backend, peer *Peer) error {
 	if err != nil {
 		return err
 	}
-	if msg.Size > maxMessageSize {
-		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
-	}
 	defer msg.Discard()
+	if err := peer.limits.Check(msg.Code, msg.Size); err != nil {
+		return err
+	}
 	start := time.Now()
 	// Track the amount of time it takes to serve the request and run the handler
 	if metrics.Enabled {
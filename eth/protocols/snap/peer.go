@@ -20,6 +20,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/msglimit"
 )
 
 // Peer is a collection of relevant information we have about a `snap` peer.
@@ -29,6 +30,7 @@ type Peer struct {
 	*p2p.Peer                   // The embedded P2P package peer
 	rw        p2p.MsgReadWriter // Input/output streams for snap
 	version   uint              // Protocol version negotiated
+	limits    *msglimit.Limiter // Limits of the received messages
 
 	logger log.Logger // Contextual logger with the peer id injected
 }
@@ -42,6 +44,7 @@ func NewPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter) *Peer {
 		Peer:    p,
 		rw:      rw,
 		version: version,
+		limits:  msglimit.New(msglimit.Limits{}, maxMessageSize, MessageNames),
 		logger:  log.New("peer", id[:8]),
 	}
 }
@@ -52,6 +55,7 @@ func NewFakePeer(version uint, id string, rw p2p.MsgReadWriter) *Peer {
 		id:      id,
 		rw:      rw,
 		version: version,
+		limits:  msglimit.New(msglimit.Limits{}, maxMessageSize, MessageNames),
 		logger:  log.New("peer", id[:8]),
 	}
 }
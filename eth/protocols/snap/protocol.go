@@ -22,6 +22,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/p2p/msglimit"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
@@ -56,8 +57,19 @@ const (
 	TrieNodesMsg        = 0x07
 )
 
+// MessageNames are the names of the protocol messages used in the limits.
+var MessageNames = msglimit.Names{
+	GetAccountRangeMsg:  "getAccountRange",
+	AccountRangeMsg:     "accountRange",
+	GetStorageRangesMsg: "getStorageRanges",
+	StorageRangesMsg:    "storageRanges",
+	GetByteCodesMsg:     "getByteCodes",
+	ByteCodesMsg:        "byteCodes",
+	GetTrieNodesMsg:     "getTrieNodes",
+	TrieNodesMsg:        "trieNodes",
+}
+
 var (
-	errMsgTooLarge    = errors.New("message too long")
 	errDecode         = errors.New("invalid message")
 	errInvalidMsgCode = errors.New("invalid message code")
 	errBadRequest     = errors.New("bad request")
@@ -395,6 +395,9 @@ type SyncProgress struct {
 	TrienodeHealBytes  common.StorageSize // Number of state trie bytes persisted to disk
 	BytecodeHealSynced uint64             // Number of bytecodes downloaded
 	BytecodeHealBytes  common.StorageSize // Number of bytecodes persisted to disk
+
+	// Fraction of the account hash space already downloaded, not persisted
+	AccountCoverage float64 `json:"-"`
 }
 
 // SyncPending is analogous to SyncProgress, but it's used to report on pending
@@ -451,6 +454,7 @@ type Syncer struct {
 	snapped bool           // Flag to signal that snap phase is done
 	healer  *healTask      // Current state healing task being executed
 	update  chan struct{}  // Notification channel for possible sync progression
+	paused  bool           // Flag whether new requests are suspended
 
 	peers    map[string]SyncPeer // Currently active peers to download from
 	peerJoin *event.Feed         // Event feed to react to peers joining
@@ -688,15 +692,21 @@ func (s *Syncer) Sync(root common.Hash, cancel chan struct{}) error {
 		if len(s.tasks) == 0 && s.healer.scheduler.Pending() == 0 {
 			return nil
 		}
-		// Assign all the data retrieval tasks to any free peers
-		s.assignAccountTasks(accountResps, accountReqFails, cancel)
-		s.assignBytecodeTasks(bytecodeResps, bytecodeReqFails, cancel)
-		s.assignStorageTasks(storageResps, storageReqFails, cancel)
+		// Assign all the data retrieval tasks to any free peers, unless paused
+		s.lock.Lock()
+		paused := s.paused
+		s.lock.Unlock()
+
+		if !paused {
+			s.assignAccountTasks(accountResps, accountReqFails, cancel)
+			s.assignBytecodeTasks(bytecodeResps, bytecodeReqFails, cancel)
+			s.assignStorageTasks(storageResps, storageReqFails, cancel)
 
-		if len(s.tasks) == 0 {
-			// Sync phase done, run heal phase
-			s.assignTrienodeHealTasks(trienodeHealResps, trienodeHealReqFails, cancel)
-			s.assignBytecodeHealTasks(bytecodeHealResps, bytecodeHealReqFails, cancel)
+			if len(s.tasks) == 0 {
+				// Sync phase done, run heal phase
+				s.assignTrienodeHealTasks(trienodeHealResps, trienodeHealReqFails, cancel)
+				s.assignBytecodeHealTasks(bytecodeHealResps, bytecodeHealReqFails, cancel)
+			}
 		}
 		// Update sync progress
 		s.lock.Lock()
@@ -711,6 +721,7 @@ func (s *Syncer) Sync(root common.Hash, cancel chan struct{}) error {
 			TrienodeHealBytes:  s.trienodeHealBytes,
 			BytecodeHealSynced: s.bytecodeHealSynced,
 			BytecodeHealBytes:  s.bytecodeHealBytes,
+			AccountCoverage:    s.accountCoverage(),
 		}
 		s.lock.Unlock()
 		// Wait for something to happen
@@ -938,6 +949,20 @@ func (s *Syncer) Progress() (*SyncProgress, *SyncPending) {
 	return s.extProgress, pending
 }
 
+// SetPaused suspends or resumes the sync. While paused, no new requests are sent,
+// but the responses of the ones in flight are still processed.
+func (s *Syncer) SetPaused(paused bool) {
+	s.lock.Lock()
+	s.paused = paused
+	s.lock.Unlock()
+
+	// Wake the sync loop up to assign tasks on resume
+	select {
+	case s.update <- struct{}{}:
+	default:
+	}
+}
+
 // cleanAccountTasks removes account range retrieval tasks that have already been
 // completed.
 func (s *Syncer) cleanAccountTasks() {
@@ -3083,6 +3108,22 @@ func (s *Syncer) report(force bool) {
 	s.reportHealProgress(force)
 }
 
+// accountFills returns the size of the account hash space already downloaded.
+func (s *Syncer) accountFills() *big.Int {
+	accountGaps := new(big.Int)
+	for _, task := range s.tasks {
+		accountGaps.Add(accountGaps, new(big.Int).Sub(task.Last.Big(), task.Next.Big()))
+	}
+	return new(big.Int).Sub(hashSpace, accountGaps)
+}
+
+// accountCoverage returns the fraction of the account hash space already
+// downloaded.
+func (s *Syncer) accountCoverage() float64 {
+	fills, _ := new(big.Float).Quo(new(big.Float).SetInt(s.accountFills()), new(big.Float).SetInt(hashSpace)).Float64()
+	return fills
+}
+
 // reportSyncProgress calculates various status reports and provides it to the user.
 func (s *Syncer) reportSyncProgress(force bool) {
 	// Don't report all the events, just occasionally
@@ -3094,11 +3135,7 @@ func (s *Syncer) reportSyncProgress(force bool) {
 	if synced == 0 {
 		return
 	}
-	accountGaps := new(big.Int)
-	for _, task := range s.tasks {
-		accountGaps.Add(accountGaps, new(big.Int).Sub(task.Last.Big(), task.Next.Big()))
-	}
-	accountFills := new(big.Int).Sub(hashSpace, accountGaps)
+	accountFills := s.accountFills()
 	if accountFills.BitLen() == 0 {
 		return
 	}
@@ -17,17 +17,43 @@
 package eth
 
 import (
+	"slices"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 )
 
-// syncTransactions starts sending all currently pending transactions to the given peer.
+// syncTransactions announces the currently pending transactions to the given peer.
+// The announcement is limited to txSyncLimit hashes, so new connections don't cause
+// a traffic burst. The limit is spent on the executable transactions of as many
+// accounts as possible: the next transaction of every account first, ordered by
+// tip, then their second ones and so on.
 func (h *handler) syncTransactions(p *eth.Peer) {
+	pending := h.txpool.Pending(txpool.PendingFilter{OnlyPlainTxs: true})
+	batches := make([][]*txpool.LazyTransaction, 0, len(pending))
+	for _, batch := range pending {
+		if len(batch) > 0 {
+			batches = append(batches, batch)
+		}
+	}
+	slices.SortFunc(batches, func(a, b []*txpool.LazyTransaction) int {
+		return b[0].GasTipCap.Cmp(a[0].GasTipCap)
+	})
 	var hashes []common.Hash
-	for _, batch := range h.txpool.Pending(txpool.PendingFilter{OnlyPlainTxs: true}) {
-		for _, tx := range batch {
-			hashes = append(hashes, tx.Hash)
+	for depth := 0; len(batches) > 0 && len(hashes) < h.txSyncLimit; depth++ {
+		// Drop the accounts without transactions at this depth.
+		batches = slices.DeleteFunc(batches, func(batch []*txpool.LazyTransaction) bool {
+			return len(batch) <= depth
+		})
+		for _, batch := range batches {
+			tx := batch[depth]
+			if tx.Tx != nil && tx.Tx.Conditional() != nil {
+				continue
+			}
+			if hashes = append(hashes, tx.Hash); len(hashes) == h.txSyncLimit {
+				break
+			}
 		}
 	}
 	if len(hashes) == 0 {
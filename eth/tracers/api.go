@@ -18,12 +18,14 @@ package tracers
 
 import (
 	"bufio"
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"runtime"
+	"slices"
 	"sync"
 	"time"
 
@@ -65,6 +67,16 @@ const (
 	// for tracing. The creation of trace state will be paused if the unused
 	// trace states exceed this limit.
 	maximumPendingTraceStates = 128
+
+	// parallelTraceTxThreshold is the number of transactions in a block above
+	// which the block is traced concurrently even for low-overhead tracers. Below
+	// it, the cost of copying the prestate for every transaction outweighs the
+	// gain of spreading the tracing over multiple threads.
+	parallelTraceTxThreshold = 32
+
+	// maxTraceTransactions is the maximum number of transactions traced by a
+	// single call of debug_traceTransactions.
+	maxTraceTransactions = 1024
 )
 
 var errTxNotFound = errors.New("transaction not found")
@@ -593,13 +605,13 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 		return nil, err
 	}
 	defer release()
-	// JS tracers have high overhead. In this case run a parallel
-	// process that generates states in one thread and traces txes
-	// in separate worker threads.
-	if config != nil && config.Tracer != nil && *config.Tracer != "" {
-		if isJS := DefaultDirectory.IsJS(*config.Tracer); isJS {
-			return api.traceBlockParallel(ctx, block, statedb, config)
-		}
+	// Every transaction is traced by a fresh tracer instance, so once the
+	// prestate of a transaction is known it can be traced independently of
+	// the others. JS tracers have high overhead, and large blocks are costly
+	// to trace with any tracer. In these cases run a parallel process that
+	// generates states in one thread and traces txes in separate worker threads.
+	if api.shouldTraceParallel(block, config) {
+		return api.traceBlockParallel(ctx, block, statedb, config)
 	}
 	// Native tracers have low overhead
 	var (
@@ -631,9 +643,24 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	return results, nil
 }
 
-// traceBlockParallel is for tracers that have a high overhead (read JS tracers). One thread
-// runs along and executes txes without tracing enabled to generate their prestate.
-// Worker threads take the tasks and the prestate and trace them.
+// shouldTraceParallel reports whether the transactions of the given block should
+// be traced concurrently using the provided trace configuration.
+func (api *API) shouldTraceParallel(block *types.Block, config *TraceConfig) bool {
+	if runtime.NumCPU() < 2 || len(block.Transactions()) < 2 {
+		return false
+	}
+	if config != nil && config.Tracer != nil && *config.Tracer != "" {
+		if DefaultDirectory.IsJS(*config.Tracer) {
+			return true
+		}
+	}
+	return len(block.Transactions()) >= parallelTraceTxThreshold
+}
+
+// traceBlockParallel is for tracers that have a high overhead (read JS tracers) or
+// blocks with many transactions. One thread runs along and executes txes without
+// tracing enabled to generate their prestate. Worker threads take the tasks and
+// the prestate and trace them.
 func (api *API) traceBlockParallel(ctx context.Context, block *types.Block, statedb *state.StateDB, config *TraceConfig) ([]*txTraceResult, error) {
 	// Execute all the transaction contained within the block concurrently
 	var (
@@ -886,6 +913,172 @@ func (api *API) TraceTransaction(ctx context.Context, hash common.Hash, config *
 	return api.traceTx(ctx, tx, msg, txctx, vmctx, statedb, config)
 }
 
+// txTraceBatch is the set of transactions of a single block traced by a call of
+// debug_traceTransactions.
+type txTraceBatch struct {
+	number  uint64
+	hash    common.Hash
+	targets map[int][]int // transaction indexes in the block -> positions in the result
+}
+
+// TraceTransactions traces a list of mined transactions, which may belong to
+// different blocks. The transactions are grouped by block, so the prestate of each
+// block is only generated once, and the state of a traced block is reused as the
+// base for regenerating the state of its descendant. The results are returned in
+// the order of the requested hashes.
+func (api *API) TraceTransactions(ctx context.Context, hashes []common.Hash, config *TraceConfig) ([]*txTraceResult, error) {
+	if len(hashes) > maxTraceTransactions {
+		return nil, fmt.Errorf("too many transactions requested: %d, limit %d", len(hashes), maxTraceTransactions)
+	}
+	var (
+		results = make([]*txTraceResult, len(hashes))
+		batches = make(map[common.Hash]*txTraceBatch)
+	)
+	for i, hash := range hashes {
+		found, _, blockHash, blockNumber, index, err := api.backend.GetTransaction(ctx, hash)
+		if err != nil {
+			return nil, ethapi.NewTxIndexingError()
+		}
+		results[i] = &txTraceResult{TxHash: hash}
+		switch {
+		case !found:
+			results[i].Error = errTxNotFound.Error()
+		case blockNumber == 0:
+			results[i].Error = "genesis is not traceable"
+		default:
+			batch := batches[blockHash]
+			if batch == nil {
+				batch = &txTraceBatch{number: blockNumber, hash: blockHash, targets: make(map[int][]int)}
+				batches[blockHash] = batch
+			}
+			batch.targets[int(index)] = append(batch.targets[int(index)], i)
+		}
+	}
+	sorted := make([]*txTraceBatch, 0, len(batches))
+	for _, batch := range batches {
+		sorted = append(sorted, batch)
+	}
+	slices.SortFunc(sorted, func(a, b *txTraceBatch) int {
+		return cmp.Compare(a.number, b.number)
+	})
+	reexec := defaultTraceReexec
+	if config != nil && config.Reexec != nil {
+		reexec = *config.Reexec
+	}
+	var (
+		base     *state.StateDB   // prestate of the previously traced block
+		baseHash common.Hash      // hash of the previously traced block
+		release  StateReleaseFunc // release function of the base state
+	)
+	defer func() {
+		if release != nil {
+			release()
+		}
+	}()
+	for _, batch := range sorted {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(batch.number), batch.hash)
+		if err != nil {
+			return nil, err
+		}
+		parent, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(batch.number-1), block.ParentHash())
+		if err != nil {
+			return nil, err
+		}
+		// The prestate of the previous block can only serve as the base if it's
+		// the grandparent state of this one.
+		if baseHash != parent.Hash() {
+			base = nil
+		}
+		statedb, rel, err := api.backend.StateAtBlock(ctx, parent, reexec, base, true, false)
+		if err != nil {
+			return nil, err
+		}
+		if release != nil {
+			release()
+		}
+		base, baseHash, release = statedb.Copy(), block.Hash(), rel
+
+		api.traceBatch(ctx, block, statedb, batch, results, config)
+	}
+	return results, nil
+}
+
+// traceBatch traces the requested transactions of a block on top of its prestate,
+// executing the remaining transactions before them without tracing. Once a trace
+// fails, the state of the block is unknown and the following transactions of the
+// batch are reported as failed too.
+func (api *API) traceBatch(ctx context.Context, block *types.Block, statedb *state.StateDB, batch *txTraceBatch, results []*txTraceResult, config *TraceConfig) {
+	var (
+		txs      = block.Transactions()
+		signer   = types.MakeSigner(api.backend.ChainConfig(), block.Number(), block.Time())
+		blockCtx = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
+		last     int
+		failed   error
+	)
+	for index := range batch.targets {
+		last = max(last, index)
+	}
+	if beaconRoot := block.BeaconRoot(); beaconRoot != nil {
+		vmenv := vm.NewEVM(blockCtx, vm.TxContext{}, statedb, api.backend.ChainConfig(), vm.Config{})
+		core.ProcessBeaconBlockRoot(*beaconRoot, vmenv, statedb)
+	}
+	for i := 0; i <= last && i < len(txs); i++ {
+		tx := txs[i]
+		positions, traced := batch.targets[i]
+		if failed != nil {
+			for _, pos := range positions {
+				results[pos].Error = failed.Error()
+			}
+			continue
+		}
+		msg, err := core.TransactionToMessage(tx, signer, block.BaseFee())
+		if err != nil {
+			failed = err
+		} else if traced {
+			txctx := &Context{
+				BlockHash:   block.Hash(),
+				BlockNumber: block.Number(),
+				TxIndex:     i,
+				TxHash:      tx.Hash(),
+			}
+			var res interface{}
+			if res, err = api.traceTx(ctx, tx, msg, txctx, blockCtx, statedb, config); err == nil {
+				for _, pos := range positions {
+					results[pos].Result = res
+				}
+				continue
+			}
+			failed = err
+		} else {
+			// Generate the next state snapshot fast without tracing
+			statedb.SetTxContext(tx.Hash(), i)
+			vmenv := vm.NewEVM(blockCtx, core.NewEVMTxContext(msg), statedb, api.backend.ChainConfig(), vm.Config{})
+			if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.GasLimit)); err != nil {
+				failed = fmt.Errorf("transaction %#x failed: %w", tx.Hash(), err)
+			}
+			statedb.Finalise(vmenv.ChainConfig().IsEIP158(block.Number()))
+		}
+		if failed != nil {
+			log.Debug("Batch tracing failed", "block", block.NumberU64(), "index", i, "err", failed)
+			for _, pos := range positions {
+				results[pos].Error = failed.Error()
+			}
+		}
+	}
+	// Transactions beyond the end of the block can only stem from an index
+	// inconsistent with the block.
+	for index, positions := range batch.targets {
+		for _, pos := range positions {
+			if results[pos].Result == nil && results[pos].Error == "" {
+				results[pos].Error = fmt.Sprintf("transaction index %d out of range for block %#x", index, block.Hash())
+			}
+		}
+	}
+}
+
 // TraceCall lets you trace a given eth_call. It collects the structured logs
 // created during the execution of EVM if the given transaction was added on
 // top of the provided block and returns them as a JSON object.
@@ -1023,6 +1216,10 @@ func APIs(backend Backend) []rpc.API {
 			Namespace: "debug",
 			Service:   NewAPI(backend),
 		},
+		{
+			Namespace: "trace",
+			Service:   NewTraceAPI(backend),
+		},
 	}
 }
 
@@ -17,6 +17,7 @@
 package tracers
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"encoding/json"
@@ -24,6 +25,7 @@ import (
 	"fmt"
 	"math/big"
 	"reflect"
+	"runtime"
 	"slices"
 	"sync/atomic"
 	"testing"
@@ -459,6 +461,85 @@ func TestTraceTransaction(t *testing.T) {
 	}
 }
 
+func TestTraceTransactions(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts
+	accounts := newAccounts(2)
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			accounts[1].addr: {Balance: big.NewInt(params.Ether)},
+		},
+	}
+	var (
+		nonce  uint64
+		hashes [][]common.Hash
+		signer = types.HomesteadSigner{}
+	)
+	backend := newTestBackend(t, 4, genesis, func(i int, b *core.BlockGen) {
+		// Three transfers from account[0] to account[1] per block
+		var block []common.Hash
+		for j := 0; j < 3; j++ {
+			tx, _ := types.SignTx(types.NewTx(&types.LegacyTx{
+				Nonce:    nonce,
+				To:       &accounts[1].addr,
+				Value:    big.NewInt(1000),
+				Gas:      params.TxGas,
+				GasPrice: b.BaseFee(),
+				Data:     nil}),
+				signer, accounts[0].key)
+			b.AddTx(tx)
+			block = append(block, tx.Hash())
+			nonce++
+		}
+		hashes = append(hashes, block)
+	})
+	defer backend.chain.Stop()
+	api := NewAPI(backend)
+
+	// Trace transactions across blocks, out of order and with duplicates
+	var (
+		tracer = "callTracer"
+		config = &TraceConfig{Tracer: &tracer}
+		query  = []common.Hash{hashes[3][2], hashes[0][1], common.Hash{42}, hashes[3][0], hashes[0][1], hashes[1][2]}
+	)
+	results, err := api.TraceTransactions(context.Background(), query, config)
+	if err != nil {
+		t.Fatalf("failed to trace transactions: %v", err)
+	}
+	if len(results) != len(query) {
+		t.Fatalf("wrong number of results: have %d, want %d", len(results), len(query))
+	}
+	for i, res := range results {
+		if res.TxHash != query[i] {
+			t.Errorf("result %d: wrong hash: have %x, want %x", i, res.TxHash, query[i])
+		}
+		if query[i] == (common.Hash{42}) {
+			if res.Error != errTxNotFound.Error() {
+				t.Errorf("result %d: wrong error for unknown transaction: %q", i, res.Error)
+			}
+			continue
+		}
+		if res.Error != "" {
+			t.Fatalf("result %d: tracing failed: %v", i, res.Error)
+		}
+		// The traces must match the ones of individual calls
+		want, err := api.TraceTransaction(context.Background(), query[i], config)
+		if err != nil {
+			t.Fatalf("result %d: failed to trace transaction: %v", i, err)
+		}
+		if !bytes.Equal(res.Result.(json.RawMessage), want.(json.RawMessage)) {
+			t.Errorf("result %d: trace mismatch: have %s, want %s", i, res.Result, want)
+		}
+	}
+	// Too many transactions are rejected
+	if _, err := api.TraceTransactions(context.Background(), make([]common.Hash, maxTraceTransactions+1), nil); err == nil {
+		t.Fatal("expected error for too many transactions")
+	}
+}
+
 func TestTraceBlock(t *testing.T) {
 	t.Parallel()
 
@@ -549,6 +630,70 @@ func TestTraceBlock(t *testing.T) {
 	}
 }
 
+func TestTraceBlockParallel(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts
+	accounts := newAccounts(2)
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			accounts[1].addr: {Balance: big.NewInt(params.Ether)},
+		},
+	}
+	var (
+		signer = types.HomesteadSigner{}
+		txs    = 2 * parallelTraceTxThreshold
+		hashes []common.Hash
+	)
+	backend := newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		// Fill the block with enough transfers to trigger concurrent tracing
+		for j := 0; j < txs; j++ {
+			tx, _ := types.SignTx(types.NewTx(&types.LegacyTx{
+				Nonce:    uint64(j),
+				To:       &accounts[1].addr,
+				Value:    big.NewInt(1000),
+				Gas:      params.TxGas,
+				GasPrice: b.BaseFee(),
+				Data:     nil}),
+				signer, accounts[0].key)
+			b.AddTx(tx)
+			hashes = append(hashes, tx.Hash())
+		}
+	})
+	defer backend.chain.Stop()
+	api := NewAPI(backend)
+
+	block, _ := backend.BlockByNumber(context.Background(), 1)
+	if !api.shouldTraceParallel(block, nil) && runtime.NumCPU() > 1 {
+		t.Fatalf("block with %d transactions not traced in parallel", txs)
+	}
+	results, err := api.TraceBlockByNumber(context.Background(), 1, nil)
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	if len(results) != txs {
+		t.Fatalf("result count mismatch: have %d, want %d", len(results), txs)
+	}
+	for i, res := range results {
+		if res.TxHash != hashes[i] {
+			t.Fatalf("tx %d: hash mismatch: have %x, want %x", i, res.TxHash, hashes[i])
+		}
+		if res.Error != "" {
+			t.Fatalf("tx %d: unexpected trace error: %v", i, res.Error)
+		}
+		want, err := api.TraceTransaction(context.Background(), hashes[i], nil)
+		if err != nil {
+			t.Fatalf("tx %d: failed to trace transaction: %v", i, err)
+		}
+		have, _ := json.Marshal(res.Result)
+		if string(have) != string(want.(json.RawMessage)) {
+			t.Errorf("tx %d: result mismatch\nhave: %s\nwant: %s", i, have, want)
+		}
+	}
+}
+
 func TestTracingWithOverrides(t *testing.T) {
 	t.Parallel()
 	// Initialize test accounts
@@ -1025,6 +1170,67 @@ func newTestMergedBackend(t *testing.T, n int, gspec *core.Genesis, generator fu
 	return backend
 }
 
+func TestIntermediateRoots(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts
+	accounts := newAccounts(2)
+	genesis := &core.Genesis{
+		Config: params.AllDevChainProtocolChanges,
+		Alloc: types.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			accounts[1].addr: {Balance: big.NewInt(params.Ether)},
+		},
+	}
+	var (
+		genBlocks = 2
+		txs       = 3
+		signer    = types.LatestSigner(genesis.Config)
+	)
+	backend := newTestMergedBackend(t, genBlocks, genesis, func(i int, b *core.BlockGen) {
+		for j := 0; j < txs; j++ {
+			tx, _ := types.SignTx(types.NewTx(&types.LegacyTx{
+				Nonce:    uint64(i*txs + j),
+				To:       &accounts[1].addr,
+				Value:    big.NewInt(1000),
+				Gas:      params.TxGas,
+				GasPrice: b.BaseFee(),
+				Data:     nil}),
+				signer, accounts[0].key)
+			b.AddTx(tx)
+		}
+	})
+	defer backend.chain.Stop()
+	api := NewAPI(backend)
+
+	block, _ := backend.BlockByNumber(context.Background(), rpc.BlockNumber(genBlocks))
+	roots, err := api.IntermediateRoots(context.Background(), block.Hash(), nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve intermediate roots: %v", err)
+	}
+	if len(roots) != txs {
+		t.Fatalf("root count mismatch: have %d, want %d", len(roots), txs)
+	}
+	for i := 1; i < len(roots); i++ {
+		if roots[i] == roots[i-1] {
+			t.Errorf("root %d unchanged after transaction execution", i)
+		}
+	}
+	// Without block rewards, the root after the last transaction must match
+	// the one committed to in the header.
+	if roots[len(roots)-1] != block.Root() {
+		t.Errorf("final root mismatch: have %x, want %x", roots[len(roots)-1], block.Root())
+	}
+	// Genesis has no transactions to execute
+	if _, err := api.IntermediateRoots(context.Background(), backend.chain.Genesis().Hash(), nil); err == nil {
+		t.Error("expected error for genesis block")
+	}
+	// Unknown blocks should be rejected
+	if _, err := api.IntermediateRoots(context.Background(), common.Hash{0x1}, nil); err == nil {
+		t.Error("expected error for unknown block")
+	}
+}
+
 func TestTraceBlockWithBasefee(t *testing.T) {
 	t.Parallel()
 	accounts := newAccounts(1)
@@ -0,0 +1,293 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// flatTracer is the name of the native tracer producing call traces in the
+	// flat format used by OpenEthereum.
+	flatTracer = "flatCallTracer"
+
+	// maxTraceFilterBlocks is the maximum number of blocks a single trace_filter
+	// request may span, since every block in the range needs to be re-executed.
+	maxTraceFilterBlocks = 1000
+)
+
+// flatTracerConfig makes the flat call tracer report errors using the messages
+// of OpenEthereum instead of the ones of the EVM.
+var flatTracerConfig = json.RawMessage(`{"convertParityErrors":true}`)
+
+// TraceAPI implements the trace namespace known from OpenEthereum, returning the
+// call traces of transactions in the flat format expected by indexers and block
+// explorers written against it.
+type TraceAPI struct {
+	api *API
+}
+
+// NewTraceAPI creates a new API definition for the OpenEthereum-compatible
+// tracing methods of the Ethereum service.
+func NewTraceAPI(backend Backend) *TraceAPI {
+	return &TraceAPI{api: NewAPI(backend)}
+}
+
+// TraceCallResult is the result of a trace_call request.
+type TraceCallResult struct {
+	Output    hexutil.Bytes     `json:"output"`
+	StateDiff interface{}       `json:"stateDiff"`
+	Trace     []json.RawMessage `json:"trace"`
+	VmTrace   interface{}       `json:"vmTrace"`
+}
+
+// TraceFilterArgs are the criteria of a trace_filter request. Traces match if
+// they originate from one of the given from addresses and target one of the given
+// to addresses, with empty lists matching any address.
+type TraceFilterArgs struct {
+	FromBlock   *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock     *rpc.BlockNumber `json:"toBlock"`
+	FromAddress []common.Address `json:"fromAddress"`
+	ToAddress   []common.Address `json:"toAddress"`
+	After       *uint64          `json:"after"`
+	Count       *uint64          `json:"count"`
+}
+
+// flatTraceConfig returns the trace configuration running the flat call tracer.
+func flatTraceConfig() *TraceConfig {
+	tracer := flatTracer
+	return &TraceConfig{Tracer: &tracer, TracerConfig: flatTracerConfig}
+}
+
+// Block returns the traces of all the transactions within the given block.
+func (api *TraceAPI) Block(ctx context.Context, number rpc.BlockNumber) ([]json.RawMessage, error) {
+	block, err := api.api.blockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return api.blockTraces(ctx, block)
+}
+
+// Transaction returns the traces of the given transaction.
+func (api *TraceAPI) Transaction(ctx context.Context, hash common.Hash) ([]json.RawMessage, error) {
+	res, err := api.api.TraceTransaction(ctx, hash, flatTraceConfig())
+	if err != nil {
+		return nil, err
+	}
+	return decodeFlatTraces(res)
+}
+
+// Call executes the given call on top of the requested block and returns its
+// traces. Only the "trace" trace type is supported, the state difference and
+// VM trace are always omitted.
+func (api *TraceAPI) Call(ctx context.Context, args ethapi.TransactionArgs, traceTypes []string, blockNrOrHash *rpc.BlockNumberOrHash) (*TraceCallResult, error) {
+	for _, typ := range traceTypes {
+		if typ != "trace" {
+			return nil, fmt.Errorf("unsupported trace type %q", typ)
+		}
+	}
+	if blockNrOrHash == nil {
+		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &latest
+	}
+	res, err := api.api.TraceCall(ctx, args, *blockNrOrHash, &TraceCallConfig{TraceConfig: *flatTraceConfig()})
+	if err != nil {
+		return nil, err
+	}
+	traces, err := decodeFlatTraces(res)
+	if err != nil {
+		return nil, err
+	}
+	result := &TraceCallResult{Output: hexutil.Bytes{}, Trace: traces}
+	if len(traces) > 0 {
+		var top struct {
+			Result *struct {
+				Output hexutil.Bytes `json:"output"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(traces[0], &top); err != nil {
+			return nil, err
+		}
+		if top.Result != nil && top.Result.Output != nil {
+			result.Output = top.Result.Output
+		}
+	}
+	return result, nil
+}
+
+// Filter returns the traces of the given block range matching the criteria.
+func (api *TraceAPI) Filter(ctx context.Context, args TraceFilterArgs) ([]json.RawMessage, error) {
+	from, err := api.resolveNumber(ctx, args.FromBlock)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.resolveNumber(ctx, args.ToBlock)
+	if err != nil {
+		return nil, err
+	}
+	if from > to {
+		return nil, fmt.Errorf("invalid block range %d-%d", from, to)
+	}
+	if to-from >= maxTraceFilterBlocks {
+		return nil, fmt.Errorf("block range %d-%d exceeds the limit of %d blocks", from, to, maxTraceFilterBlocks)
+	}
+	var (
+		skip    uint64
+		results = []json.RawMessage{}
+	)
+	if args.After != nil {
+		skip = *args.After
+	}
+	for number := from; number <= to; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block, err := api.api.blockByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		traces, err := api.blockTraces(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		for _, trace := range traces {
+			match, err := matchFlatTrace(trace, args.FromAddress, args.ToAddress)
+			if err != nil {
+				return nil, err
+			}
+			if !match {
+				continue
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			results = append(results, trace)
+			if args.Count != nil && uint64(len(results)) >= *args.Count {
+				return results, nil
+			}
+		}
+	}
+	return results, nil
+}
+
+// resolveNumber converts the given block number, which may be a tag, into the
+// number of the block it references. A missing number defaults to the latest.
+func (api *TraceAPI) resolveNumber(ctx context.Context, number *rpc.BlockNumber) (uint64, error) {
+	if number == nil {
+		latest := rpc.LatestBlockNumber
+		number = &latest
+	}
+	if *number >= 0 {
+		return uint64(*number), nil
+	}
+	header, err := api.api.backend.HeaderByNumber(ctx, *number)
+	if err != nil {
+		return 0, err
+	}
+	if header == nil {
+		return 0, fmt.Errorf("block #%d not found", *number)
+	}
+	return header.Number.Uint64(), nil
+}
+
+// blockTraces traces all the transactions of the given block and concatenates
+// their flat traces.
+func (api *TraceAPI) blockTraces(ctx context.Context, block *types.Block) ([]json.RawMessage, error) {
+	traces := []json.RawMessage{}
+	if block.NumberU64() == 0 || len(block.Transactions()) == 0 {
+		return traces, nil
+	}
+	results, err := api.api.traceBlock(ctx, block, flatTraceConfig())
+	if err != nil {
+		return nil, err
+	}
+	for _, res := range results {
+		if res.Error != "" {
+			return nil, fmt.Errorf("tracing transaction %#x failed: %s", res.TxHash, res.Error)
+		}
+		txTraces, err := decodeFlatTraces(res.Result)
+		if err != nil {
+			return nil, err
+		}
+		traces = append(traces, txTraces...)
+	}
+	return traces, nil
+}
+
+// decodeFlatTraces splits the result of the flat call tracer into the individual
+// traces it contains.
+func decodeFlatTraces(res interface{}) ([]json.RawMessage, error) {
+	blob, ok := res.(json.RawMessage)
+	if !ok {
+		return nil, fmt.Errorf("unexpected tracer result type %T", res)
+	}
+	var traces []json.RawMessage
+	if err := json.Unmarshal(blob, &traces); err != nil {
+		return nil, err
+	}
+	return traces, nil
+}
+
+// matchFlatTrace reports whether the sender and the recipient of the trace are
+// contained in the given address lists. Contract creations match the address
+// of the created contract as recipient and self-destructs match the destroyed
+// contract as sender and the beneficiary as recipient.
+func matchFlatTrace(trace json.RawMessage, from, to []common.Address) (bool, error) {
+	if len(from) == 0 && len(to) == 0 {
+		return true, nil
+	}
+	var frame struct {
+		Action struct {
+			From          *common.Address `json:"from"`
+			To            *common.Address `json:"to"`
+			Address       *common.Address `json:"address"`
+			RefundAddress *common.Address `json:"refundAddress"`
+		} `json:"action"`
+		Result *struct {
+			Address *common.Address `json:"address"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(trace, &frame); err != nil {
+		return false, err
+	}
+	sender, recipient := frame.Action.From, frame.Action.To
+	if frame.Action.Address != nil {
+		sender, recipient = frame.Action.Address, frame.Action.RefundAddress
+	} else if recipient == nil && frame.Result != nil {
+		recipient = frame.Result.Address
+	}
+	return matchAddress(sender, from) && matchAddress(recipient, to), nil
+}
+
+// matchAddress reports whether addr is contained in the list, with an empty list
+// matching every address.
+func matchAddress(addr *common.Address, list []common.Address) bool {
+	if len(list) == 0 {
+		return true
+	}
+	return addr != nil && slices.Contains(list, *addr)
+}
@@ -0,0 +1,177 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	_ "github.com/ethereum/go-ethereum/eth/tracers/native"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// flatTrace is the subset of the flat trace fields checked by the tests.
+type flatTrace struct {
+	Action struct {
+		From     common.Address `json:"from"`
+		To       common.Address `json:"to"`
+		CallType string         `json:"callType"`
+	} `json:"action"`
+	Subtraces           int    `json:"subtraces"`
+	TraceAddress        []int  `json:"traceAddress"`
+	TransactionPosition uint64 `json:"transactionPosition"`
+	Type                string `json:"type"`
+}
+
+func decodeTraces(t *testing.T, traces []json.RawMessage) []flatTrace {
+	t.Helper()
+
+	decoded := make([]flatTrace, len(traces))
+	for i, trace := range traces {
+		if err := json.Unmarshal(trace, &decoded[i]); err != nil {
+			t.Fatalf("failed to decode trace %d: %v", i, err)
+		}
+	}
+	return decoded
+}
+
+func TestTraceNamespace(t *testing.T) {
+	t.Parallel()
+
+	var (
+		key, _   = crypto.GenerateKey()
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		receiver = common.HexToAddress("0x1111")
+		proxy    = common.HexToAddress("0x2222")
+		callee   = common.HexToAddress("0x3333")
+		signer   = types.HomesteadSigner{}
+	)
+	// The proxy forwards every call to the callee, yielding a nested trace.
+	code := append(common.FromHex("60006000600060006000"), append(append([]byte{0x73}, callee.Bytes()...), 0x5a, 0xf1, 0x00)...)
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			sender: {Balance: big.NewInt(params.Ether)},
+			proxy:  {Code: code},
+		},
+	}
+	var hashes []common.Hash
+	backend := tracers.NewTestBackend(t, 2, genesis, func(i int, b *core.BlockGen) {
+		if i != 1 {
+			return
+		}
+		transfer, _ := types.SignTx(types.NewTx(&types.LegacyTx{
+			Nonce:    0,
+			To:       &receiver,
+			Value:    big.NewInt(1000),
+			Gas:      params.TxGas,
+			GasPrice: b.BaseFee(),
+		}), signer, key)
+		call, _ := types.SignTx(types.NewTx(&types.LegacyTx{
+			Nonce:    1,
+			To:       &proxy,
+			Gas:      100000,
+			GasPrice: b.BaseFee(),
+		}), signer, key)
+		b.AddTx(transfer)
+		b.AddTx(call)
+		hashes = append(hashes, transfer.Hash(), call.Hash())
+	})
+	var (
+		api = tracers.NewTraceAPI(backend)
+		ctx = context.Background()
+	)
+	// Trace the whole block containing both transactions
+	traces, err := api.Block(ctx, rpc.BlockNumber(2))
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	have := decodeTraces(t, traces)
+	if len(have) != 3 {
+		t.Fatalf("block trace count mismatch: have %d, want 3", len(have))
+	}
+	if have[0].Action.To != receiver || have[0].TransactionPosition != 0 || have[0].Subtraces != 0 {
+		t.Errorf("transfer trace mismatch: %+v", have[0])
+	}
+	if have[1].Action.To != proxy || have[1].TransactionPosition != 1 || have[1].Subtraces != 1 {
+		t.Errorf("proxy trace mismatch: %+v", have[1])
+	}
+	if have[2].Action.From != proxy || have[2].Action.To != callee || len(have[2].TraceAddress) != 1 || have[2].Type != "call" {
+		t.Errorf("nested trace mismatch: %+v", have[2])
+	}
+	// Blocks without transactions have no traces
+	if traces, err := api.Block(ctx, rpc.BlockNumber(1)); err != nil || len(traces) != 0 {
+		t.Errorf("empty block traces mismatch: have %d traces, err %v", len(traces), err)
+	}
+	// Trace a single transaction
+	traces, err = api.Transaction(ctx, hashes[1])
+	if err != nil {
+		t.Fatalf("failed to trace transaction: %v", err)
+	}
+	if len(traces) != 2 {
+		t.Errorf("transaction trace count mismatch: have %d, want 2", len(traces))
+	}
+	// Filter the traces of the chain
+	from, to := rpc.BlockNumber(0), rpc.LatestBlockNumber
+	one := uint64(1)
+	tests := []struct {
+		args tracers.TraceFilterArgs
+		want []common.Address // Expected recipients of the matching traces
+	}{
+		{tracers.TraceFilterArgs{FromBlock: &from, ToBlock: &to}, []common.Address{receiver, proxy, callee}},
+		{tracers.TraceFilterArgs{FromBlock: &from, ToBlock: &to, FromAddress: []common.Address{sender}}, []common.Address{receiver, proxy}},
+		{tracers.TraceFilterArgs{FromBlock: &from, ToBlock: &to, ToAddress: []common.Address{callee}}, []common.Address{callee}},
+		{tracers.TraceFilterArgs{FromBlock: &from, ToBlock: &to, FromAddress: []common.Address{sender}, ToAddress: []common.Address{callee}}, nil},
+		{tracers.TraceFilterArgs{FromBlock: &from, ToBlock: &to, After: &one, Count: &one}, []common.Address{proxy}},
+	}
+	for i, tt := range tests {
+		traces, err := api.Filter(ctx, tt.args)
+		if err != nil {
+			t.Fatalf("test %d: failed to filter traces: %v", i, err)
+		}
+		have := decodeTraces(t, traces)
+		if len(have) != len(tt.want) {
+			t.Errorf("test %d: trace count mismatch: have %d, want %d", i, len(have), len(tt.want))
+			continue
+		}
+		for j, trace := range have {
+			if trace.Action.To != tt.want[j] {
+				t.Errorf("test %d, trace %d: recipient mismatch: have %x, want %x", i, j, trace.Action.To, tt.want[j])
+			}
+		}
+	}
+	// Trace a call on top of the latest block
+	res, err := api.Call(ctx, ethapi.TransactionArgs{From: &sender, To: &proxy}, []string{"trace"}, nil)
+	if err != nil {
+		t.Fatalf("failed to trace call: %v", err)
+	}
+	if len(res.Trace) != 2 || len(res.Output) != 0 {
+		t.Errorf("call trace mismatch: have %d traces, output %x", len(res.Trace), res.Output)
+	}
+	if _, err := api.Call(ctx, ethapi.TransactionArgs{From: &sender, To: &proxy}, []string{"vmTrace"}, nil); err == nil {
+		t.Error("expected unsupported trace type to be rejected")
+	}
+}
@@ -530,6 +530,25 @@ func (ec *Client) CallContract(ctx context.Context, msg ethereum.CallMsg, blockN
 	return hex, nil
 }
 
+// CallContractWithOverrides executes a message call transaction like CallContract,
+// against a state and block context modified by the given overrides. Either of
+// state and block can be nil. A blockNumber of rpc.PendingBlockNumber selects the
+// pending state.
+func (ec *Client) CallContractWithOverrides(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int, state map[common.Address]ethereum.OverrideAccount, block *ethereum.BlockOverrides) ([]byte, error) {
+	args := []interface{}{toCallArg(msg), toBlockNumArg(blockNumber)}
+	if state != nil || block != nil {
+		args = append(args, state)
+	}
+	if block != nil {
+		args = append(args, block)
+	}
+	var hex hexutil.Bytes
+	if err := ec.c.CallContext(ctx, &hex, "eth_call", args...); err != nil {
+		return nil, err
+	}
+	return hex, nil
+}
+
 // CallContractAtHash is almost the same as CallContract except that it selects
 // the block by block hash instead of block height.
 func (ec *Client) CallContractAtHash(ctx context.Context, msg ethereum.CallMsg, blockHash common.Hash) ([]byte, error) {
@@ -685,6 +704,10 @@ type rpcProgress struct {
 	CurrentBlock  hexutil.Uint64
 	HighestBlock  hexutil.Uint64
 
+	SyncedHeaders  hexutil.Uint64
+	SyncedBodies   hexutil.Uint64
+	SyncedReceipts hexutil.Uint64
+
 	PulledStates hexutil.Uint64
 	KnownStates  hexutil.Uint64
 
@@ -712,6 +735,9 @@ func (p *rpcProgress) toSyncProgress() *ethereum.SyncProgress {
 		StartingBlock:          uint64(p.StartingBlock),
 		CurrentBlock:           uint64(p.CurrentBlock),
 		HighestBlock:           uint64(p.HighestBlock),
+		SyncedHeaders:          uint64(p.SyncedHeaders),
+		SyncedBodies:           uint64(p.SyncedBodies),
+		SyncedReceipts:         uint64(p.SyncedReceipts),
 		PulledStates:           uint64(p.PulledStates),
 		KnownStates:            uint64(p.KnownStates),
 		SyncedAccounts:         uint64(p.SyncedAccounts),
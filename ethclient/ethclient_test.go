@@ -294,6 +294,9 @@ func TestEthClient(t *testing.T) {
 		"TransactionSender": {
 			func(t *testing.T) { testTransactionSender(t, client) },
 		},
+		"BlockReceipts": {
+			func(t *testing.T) { testBlockReceipts(t, chain, client) },
+		},
 	}
 
 	t.Parallel()
@@ -420,6 +423,44 @@ func testTransactionInBlock(t *testing.T, client *rpc.Client) {
 	}
 }
 
+func testBlockReceipts(t *testing.T, chain []*types.Block, client *rpc.Client) {
+	ec := NewClient(client)
+
+	// Retrieve the receipts of the block containing the test transactions.
+	for _, blockNrOrHash := range []rpc.BlockNumberOrHash{
+		rpc.BlockNumberOrHashWithNumber(2),
+		rpc.BlockNumberOrHashWithHash(chain[2].Hash(), false),
+	} {
+		receipts, err := ec.BlockReceipts(context.Background(), blockNrOrHash)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(receipts) != 2 {
+			t.Fatalf("receipt count mismatch: have %d, want 2", len(receipts))
+		}
+		for i, tx := range []*types.Transaction{testTx1, testTx2} {
+			if receipts[i].TxHash != tx.Hash() {
+				t.Errorf("receipt %d: tx hash mismatch: have %x, want %x", i, receipts[i].TxHash, tx.Hash())
+			}
+			if receipts[i].BlockHash != chain[2].Hash() || receipts[i].TransactionIndex != uint(i) {
+				t.Errorf("receipt %d: position mismatch: have %x/%d", i, receipts[i].BlockHash, receipts[i].TransactionIndex)
+			}
+		}
+	}
+	// Blocks without transactions have no receipts.
+	receipts, err := ec.BlockReceipts(context.Background(), rpc.BlockNumberOrHashWithNumber(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(receipts) != 0 {
+		t.Fatalf("receipt count mismatch: have %d, want 0", len(receipts))
+	}
+	// Unknown blocks are reported as not found.
+	if _, err := ec.BlockReceipts(context.Background(), rpc.BlockNumberOrHashWithNumber(10)); err != ethereum.NotFound {
+		t.Fatalf("error mismatch: have %v, want %v", err, ethereum.NotFound)
+	}
+}
+
 func testChainID(t *testing.T, client *rpc.Client) {
 	ec := NewClient(client)
 	id, err := ec.ChainID(context.Background())
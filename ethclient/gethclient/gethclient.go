@@ -166,6 +166,17 @@ func (ec *Client) CallContractWithBlockOverrides(ctx context.Context, msg ethere
 	return hex, err
 }
 
+// SendTransactionConditional injects a signed transaction into the pending pool
+// of a geth node, to be included only in a block satisfying the given conditions.
+// Conditional transactions are not propagated to the network by the node.
+func (ec *Client) SendTransactionConditional(ctx context.Context, tx *types.Transaction, cond *types.TransactionConditional) error {
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return ec.c.CallContext(ctx, nil, "eth_sendRawTransactionConditional", hexutil.Encode(data), cond)
+}
+
 // GCStats retrieves the current garbage collection stats from a geth node.
 func (ec *Client) GCStats(ctx context.Context) (*debug.GCStats, error) {
 	var result debug.GCStats
@@ -199,6 +210,36 @@ func (ec *Client) SubscribeFullPendingTransactions(ctx context.Context, ch chan<
 	return ec.c.EthSubscribe(ctx, ch, "newPendingTransactions", true)
 }
 
+// PendingTransaction is a pending transaction along with its sender, as delivered
+// by SubscribePendingTransactionsWithSender.
+type PendingTransaction struct {
+	*types.Transaction
+	From common.Address
+}
+
+// UnmarshalJSON decodes a full pending transaction notification.
+func (tx *PendingTransaction) UnmarshalJSON(input []byte) error {
+	var sender struct {
+		From common.Address `json:"from"`
+	}
+	if err := json.Unmarshal(input, &sender); err != nil {
+		return err
+	}
+	tx.Transaction = new(types.Transaction)
+	if err := tx.Transaction.UnmarshalJSON(input); err != nil {
+		return err
+	}
+	tx.From = sender.From
+	return nil
+}
+
+// SubscribePendingTransactionsWithSender subscribes to new pending transactions,
+// delivering them along with their sender so it does not have to be recovered
+// from the signature.
+func (ec *Client) SubscribePendingTransactionsWithSender(ctx context.Context, ch chan<- *PendingTransaction) (*rpc.ClientSubscription, error) {
+	return ec.c.EthSubscribe(ctx, ch, "newPendingTransactions", true)
+}
+
 // SubscribePendingTransactions subscribes to new pending transaction hashes.
 func (ec *Client) SubscribePendingTransactions(ctx context.Context, ch chan<- common.Hash) (*rpc.ClientSubscription, error) {
 	return ec.c.EthSubscribe(ctx, ch, "newPendingTransactions")
@@ -255,96 +296,7 @@ func toCallArg(msg ethereum.CallMsg) interface{} {
 }
 
 // OverrideAccount specifies the state of an account to be overridden.
-type OverrideAccount struct {
-	// Nonce sets nonce of the account. Note: the nonce override will only
-	// be applied when it is set to a non-zero value.
-	Nonce uint64
+type OverrideAccount = ethereum.OverrideAccount
 
-	// Code sets the contract code. The override will be applied
-	// when the code is non-nil, i.e. setting empty code is possible
-	// using an empty slice.
-	Code []byte
-
-	// Balance sets the account balance.
-	Balance *big.Int
-
-	// State sets the complete storage. The override will be applied
-	// when the given map is non-nil. Using an empty map wipes the
-	// entire contract storage during the call.
-	State map[common.Hash]common.Hash
-
-	// StateDiff allows overriding individual storage slots.
-	StateDiff map[common.Hash]common.Hash
-}
-
-func (a OverrideAccount) MarshalJSON() ([]byte, error) {
-	type acc struct {
-		Nonce     hexutil.Uint64              `json:"nonce,omitempty"`
-		Code      string                      `json:"code,omitempty"`
-		Balance   *hexutil.Big                `json:"balance,omitempty"`
-		State     interface{}                 `json:"state,omitempty"`
-		StateDiff map[common.Hash]common.Hash `json:"stateDiff,omitempty"`
-	}
-
-	output := acc{
-		Nonce:     hexutil.Uint64(a.Nonce),
-		Balance:   (*hexutil.Big)(a.Balance),
-		StateDiff: a.StateDiff,
-	}
-	if a.Code != nil {
-		output.Code = hexutil.Encode(a.Code)
-	}
-	if a.State != nil {
-		output.State = a.State
-	}
-	return json.Marshal(output)
-}
-
-// BlockOverrides specifies the  set of header fields to override.
-type BlockOverrides struct {
-	// Number overrides the block number.
-	Number *big.Int
-	// Difficulty overrides the block difficulty.
-	Difficulty *big.Int
-	// Time overrides the block timestamp. Time is applied only when
-	// it is non-zero.
-	Time uint64
-	// GasLimit overrides the block gas limit. GasLimit is applied only when
-	// it is non-zero.
-	GasLimit uint64
-	// Coinbase overrides the block coinbase. Coinbase is applied only when
-	// it is different from the zero address.
-	Coinbase common.Address
-	// Random overrides the block extra data which feeds into the RANDOM opcode.
-	// Random is applied only when it is a non-zero hash.
-	Random common.Hash
-	// BaseFee overrides the block base fee.
-	BaseFee *big.Int
-}
-
-func (o BlockOverrides) MarshalJSON() ([]byte, error) {
-	type override struct {
-		Number     *hexutil.Big    `json:"number,omitempty"`
-		Difficulty *hexutil.Big    `json:"difficulty,omitempty"`
-		Time       hexutil.Uint64  `json:"time,omitempty"`
-		GasLimit   hexutil.Uint64  `json:"gasLimit,omitempty"`
-		Coinbase   *common.Address `json:"coinbase,omitempty"`
-		Random     *common.Hash    `json:"random,omitempty"`
-		BaseFee    *hexutil.Big    `json:"baseFee,omitempty"`
-	}
-
-	output := override{
-		Number:     (*hexutil.Big)(o.Number),
-		Difficulty: (*hexutil.Big)(o.Difficulty),
-		Time:       hexutil.Uint64(o.Time),
-		GasLimit:   hexutil.Uint64(o.GasLimit),
-		BaseFee:    (*hexutil.Big)(o.BaseFee),
-	}
-	if o.Coinbase != (common.Address{}) {
-		output.Coinbase = &o.Coinbase
-	}
-	if o.Random != (common.Hash{}) {
-		output.Random = &o.Random
-	}
-	return json.Marshal(output)
-}
+// BlockOverrides specifies the set of header fields to override.
+type BlockOverrides = ethereum.BlockOverrides
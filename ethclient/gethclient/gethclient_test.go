@@ -138,6 +138,9 @@ func TestGethClient(t *testing.T) {
 		}, {
 			"TestSubscribePendingTxs",
 			func(t *testing.T) { testSubscribeFullPendingTransactions(t, client) },
+		}, {
+			"TestSendTransactionConditional",
+			func(t *testing.T) { testSendTransactionConditional(t, client) },
 		}, {
 			"TestCallContract",
 			func(t *testing.T) { testCallContract(t, client) },
@@ -389,12 +392,68 @@ func testSubscribePendingTransactions(t *testing.T, client *rpc.Client) {
 	}
 }
 
+func testSendTransactionConditional(t *testing.T, client *rpc.Client) {
+	ec := New(client)
+	ethcl := ethclient.NewClient(client)
+	chainID, err := ethcl.ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, err := ethcl.PendingNonceAt(context.Background(), testAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := types.LatestSignerForChainID(chainID)
+	tx, err := types.SignNewTx(testKey, signer, &types.LegacyTx{
+		Nonce:    nonce,
+		To:       &common.Address{2},
+		Value:    big.NewInt(1),
+		Gas:      22000,
+		GasPrice: big.NewInt(params.InitialBaseFee),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Conditions which do not hold must be rejected
+	wrongValue := &types.TransactionConditional{
+		KnownAccounts: map[common.Address]types.KnownAccount{
+			testAddr: {StorageSlots: map[common.Hash]common.Hash{testSlot: {}}},
+		},
+	}
+	if err := ec.SendTransactionConditional(context.Background(), tx, wrongValue); err == nil {
+		t.Fatal("transaction with mismatching storage condition accepted")
+	}
+	expired := &types.TransactionConditional{BlockNumberMax: big.NewInt(1)}
+	if err := ec.SendTransactionConditional(context.Background(), tx, expired); err == nil {
+		t.Fatal("transaction with expired block condition accepted")
+	}
+	// Conditions matching the current state must be accepted
+	cond := &types.TransactionConditional{
+		KnownAccounts: map[common.Address]types.KnownAccount{
+			testAddr: {StorageSlots: map[common.Hash]common.Hash{testSlot: testValue}},
+		},
+		BlockNumberMin: big.NewInt(2),
+	}
+	if err := ec.SendTransactionConditional(context.Background(), tx, cond); err != nil {
+		t.Fatalf("failed to send conditional transaction: %v", err)
+	}
+	pending, _, err := ethcl.TransactionByHash(context.Background(), tx.Hash())
+	if err != nil {
+		t.Fatalf("conditional transaction not pooled: %v", err)
+	}
+	if pending.Hash() != tx.Hash() {
+		t.Fatalf("pooled transaction mismatch: have %x, want %x", pending.Hash(), tx.Hash())
+	}
+}
+
 func testSubscribeFullPendingTransactions(t *testing.T, client *rpc.Client) {
 	ec := New(client)
 	ethcl := ethclient.NewClient(client)
 	// Subscribe to Transactions
 	ch := make(chan *types.Transaction)
 	ec.SubscribeFullPendingTransactions(context.Background(), ch)
+	senderCh := make(chan *PendingTransaction, 1)
+	ec.SubscribePendingTransactionsWithSender(context.Background(), senderCh)
 	// Send a transaction
 	chainID, err := ethcl.ChainID(context.Background())
 	if err != nil {
@@ -421,6 +480,13 @@ func testSubscribeFullPendingTransactions(t *testing.T, client *rpc.Client) {
 	if tx.Hash() != signedTx.Hash() {
 		t.Fatalf("Invalid tx hash received, got %v, want %v", tx.Hash(), signedTx.Hash())
 	}
+	pending := <-senderCh
+	if pending.Hash() != signedTx.Hash() {
+		t.Fatalf("Invalid tx hash received, got %v, want %v", pending.Hash(), signedTx.Hash())
+	}
+	if pending.From != testAddr {
+		t.Fatalf("Invalid sender received, got %v, want %v", pending.From, testAddr)
+	}
 }
 
 func testCallContract(t *testing.T, client *rpc.Client) {
@@ -42,6 +42,7 @@ type Client interface {
 	ethereum.ChainReader
 	ethereum.ChainStateReader
 	ethereum.ContractCaller
+	ethereum.OverrideContractCaller
 	ethereum.GasEstimator
 	ethereum.GasPricer
 	ethereum.GasPricer1559
@@ -182,11 +183,35 @@ func (n *Backend) Fork(parentHash common.Hash) error {
 }
 
 // AdjustTime changes the block timestamp and creates a new block.
-// It can only be called on empty blocks.
+// It can only be called on empty blocks. The timestamps of the following blocks
+// are shifted by the same adjustment.
 func (n *Backend) AdjustTime(adjustment time.Duration) error {
 	return n.beacon.AdjustTime(adjustment)
 }
 
+// SetNextBlockTimestamp sets the timestamp of the next committed block, which
+// must be after the current head. The timestamps of the following blocks
+// continue from it.
+func (n *Backend) SetNextBlockTimestamp(t time.Time) error {
+	if t.Unix() < 0 {
+		return errors.New("negative timestamp")
+	}
+	return n.beacon.SetNextBlockTimestamp(uint64(t.Unix()))
+}
+
+// Snapshot records the current state of the chain, returning an ID to restore it
+// with Revert.
+func (n *Backend) Snapshot() int {
+	return n.beacon.Snapshot()
+}
+
+// Revert restores the chain to the state recorded by Snapshot, discarding the
+// blocks committed and the transactions sent since, and restoring the time
+// adjustments. The snapshot and the ones taken after it can't be used anymore.
+func (n *Backend) Revert(id int) error {
+	return n.beacon.Revert(id)
+}
+
 // Client returns a client that accesses the simulated chain.
 func (n *Backend) Client() Client {
 	return n.client
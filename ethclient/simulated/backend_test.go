@@ -106,9 +106,98 @@ func TestAdjustTime(t *testing.T) {
 	block2, _ := client.BlockByNumber(context.Background(), nil)
 	prevTime := block1.Time()
 	newTime := block2.Time()
-	if newTime-prevTime != uint64(time.Minute) {
+	if newTime-prevTime != uint64(time.Minute.Seconds()) {
 		t.Errorf("adjusted time not equal to 60 seconds. prev: %v, new: %v", prevTime, newTime)
 	}
+	// The following blocks must keep the adjustment
+	sim.Commit()
+	block3, _ := client.BlockByNumber(context.Background(), nil)
+	if block3.Time() < uint64(time.Now().Add(time.Minute).Unix())-1 {
+		t.Errorf("adjustment lost by the next block. time: %v", block3.Time())
+	}
+}
+
+func TestSetNextBlockTimestamp(t *testing.T) {
+	sim := NewBackend(types.GenesisAlloc{})
+	defer sim.Close()
+
+	client := sim.Client()
+	head, _ := client.HeaderByNumber(context.Background(), nil)
+	if err := sim.SetNextBlockTimestamp(time.Unix(int64(head.Time), 0)); err == nil {
+		t.Fatal("timestamp of the head accepted")
+	}
+	next := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	if err := sim.SetNextBlockTimestamp(next); err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+	head, _ = client.HeaderByNumber(context.Background(), nil)
+	if head.Time != uint64(next.Unix()) {
+		t.Errorf("wrong timestamp %v, want %v", head.Time, next.Unix())
+	}
+	// The following blocks continue from the timestamp
+	sim.Commit()
+	head, _ = client.HeaderByNumber(context.Background(), nil)
+	if head.Time <= uint64(next.Unix()) || head.Time > uint64(next.Add(time.Minute).Unix()) {
+		t.Errorf("wrong timestamp %v after %v", head.Time, next.Unix())
+	}
+}
+
+func TestSnapshotRevert(t *testing.T) {
+	sim := simTestBackend(testAddr)
+	defer sim.Close()
+
+	client := sim.Client()
+	ctx := context.Background()
+	genesis, _ := client.HeaderByNumber(ctx, nil)
+
+	// Commit a transaction and shift the time after a snapshot
+	id := sim.Snapshot()
+	tx, err := newTx(sim, testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+	if err := sim.SetNextBlockTimestamp(time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+	// Take a second snapshot, and leave a transaction pending
+	second := sim.Snapshot()
+	if tx, err = newTx(sim, testKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.Revert(id); err != nil {
+		t.Fatal(err)
+	}
+	// The chain, the pending transactions and the time must be restored
+	if head, _ := client.HeaderByNumber(ctx, nil); head.Hash() != genesis.Hash() {
+		t.Fatalf("wrong head %d after revert", head.Number)
+	}
+	if nonce, _ := client.PendingNonceAt(ctx, testAddr); nonce != 0 {
+		t.Errorf("wrong pending nonce %d after revert", nonce)
+	}
+	sim.Commit()
+	block, _ := client.BlockByNumber(ctx, nil)
+	if block.NumberU64() != 1 || len(block.Transactions()) != 0 {
+		t.Errorf("block %d with %d transactions after revert", block.NumberU64(), len(block.Transactions()))
+	}
+	if block.Time() > uint64(time.Now().Add(time.Minute).Unix()) {
+		t.Errorf("time adjustment not reverted")
+	}
+	// The reverted snapshots can't be used anymore
+	if err := sim.Revert(id); err == nil {
+		t.Error("reverted snapshot reused")
+	}
+	if err := sim.Revert(second); err == nil {
+		t.Error("later snapshot reused")
+	}
 }
 
 func TestSendTransaction(t *testing.T) {
@@ -0,0 +1,487 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethgrpc
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ethServer implements the Eth service of the schema.
+type ethServer struct {
+	UnimplementedEthServer
+}
+
+// debugServer implements the Debug service of the schema.
+type debugServer struct {
+	UnimplementedDebugServer
+}
+
+func (*ethServer) ChainId(ctx context.Context, req *ChainIdRequest) (*ChainIdResponse, error) {
+	var id hexutil.Big
+	if err := clientFrom(ctx).CallContext(ctx, &id, "eth_chainId"); err != nil {
+		return nil, toStatus(err)
+	}
+	return &ChainIdResponse{ChainId: id.ToInt().Uint64()}, nil
+}
+
+func (*ethServer) BlockNumber(ctx context.Context, req *BlockNumberRequest) (*BlockNumberResponse, error) {
+	var number hexutil.Uint64
+	if err := clientFrom(ctx).CallContext(ctx, &number, "eth_blockNumber"); err != nil {
+		return nil, toStatus(err)
+	}
+	return &BlockNumberResponse{Number: uint64(number)}, nil
+}
+
+func (*ethServer) GetBalance(ctx context.Context, req *AccountRequest) (*GetBalanceResponse, error) {
+	addr, block, err := parseAccountRequest(req.Address, req.Block)
+	if err != nil {
+		return nil, err
+	}
+	var balance hexutil.Big
+	if err := clientFrom(ctx).CallContext(ctx, &balance, "eth_getBalance", addr, block); err != nil {
+		return nil, toStatus(err)
+	}
+	return &GetBalanceResponse{Balance: balance.ToInt().Bytes()}, nil
+}
+
+func (*ethServer) GetTransactionCount(ctx context.Context, req *AccountRequest) (*GetTransactionCountResponse, error) {
+	addr, block, err := parseAccountRequest(req.Address, req.Block)
+	if err != nil {
+		return nil, err
+	}
+	var nonce hexutil.Uint64
+	if err := clientFrom(ctx).CallContext(ctx, &nonce, "eth_getTransactionCount", addr, block); err != nil {
+		return nil, toStatus(err)
+	}
+	return &GetTransactionCountResponse{Nonce: uint64(nonce)}, nil
+}
+
+func (*ethServer) GetCode(ctx context.Context, req *AccountRequest) (*GetCodeResponse, error) {
+	addr, block, err := parseAccountRequest(req.Address, req.Block)
+	if err != nil {
+		return nil, err
+	}
+	var code hexutil.Bytes
+	if err := clientFrom(ctx).CallContext(ctx, &code, "eth_getCode", addr, block); err != nil {
+		return nil, toStatus(err)
+	}
+	return &GetCodeResponse{Code: code}, nil
+}
+
+func (*ethServer) GetStorageAt(ctx context.Context, req *GetStorageAtRequest) (*GetStorageAtResponse, error) {
+	addr, block, err := parseAccountRequest(req.Address, req.Block)
+	if err != nil {
+		return nil, err
+	}
+	slot, err := parseHash(req.Slot, "slot")
+	if err != nil {
+		return nil, err
+	}
+	var value hexutil.Bytes
+	if err := clientFrom(ctx).CallContext(ctx, &value, "eth_getStorageAt", addr, slot, block); err != nil {
+		return nil, toStatus(err)
+	}
+	return &GetStorageAtResponse{Value: value}, nil
+}
+
+func (*ethServer) Call(ctx context.Context, req *CallRequest) (*CallResponse, error) {
+	args, block, err := parseCallRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	var result hexutil.Bytes
+	if err := clientFrom(ctx).CallContext(ctx, &result, "eth_call", args, block); err != nil {
+		return nil, toStatus(err)
+	}
+	return &CallResponse{Data: result}, nil
+}
+
+func (*ethServer) EstimateGas(ctx context.Context, req *CallRequest) (*EstimateGasResponse, error) {
+	args, block, err := parseCallRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	var gas hexutil.Uint64
+	if err := clientFrom(ctx).CallContext(ctx, &gas, "eth_estimateGas", args, block); err != nil {
+		return nil, toStatus(err)
+	}
+	return &EstimateGasResponse{Gas: uint64(gas)}, nil
+}
+
+func (*ethServer) SendRawTransaction(ctx context.Context, req *SendRawTransactionRequest) (*TransactionHash, error) {
+	var hash common.Hash
+	if err := clientFrom(ctx).CallContext(ctx, &hash, "eth_sendRawTransaction", hexutil.Bytes(req.Data)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &TransactionHash{Hash: hash.Bytes()}, nil
+}
+
+func (*ethServer) GetHeader(ctx context.Context, req *BlockRequest) (*Header, error) {
+	block, err := parseBlock(req.Block)
+	if err != nil {
+		return nil, err
+	}
+	var head *types.Header
+	if hash, ok := block.Hash(); ok {
+		err = clientFrom(ctx).CallContext(ctx, &head, "eth_getHeaderByHash", hash)
+	} else {
+		number, _ := block.Number()
+		err = clientFrom(ctx).CallContext(ctx, &head, "eth_getHeaderByNumber", number)
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if head == nil {
+		return nil, status.Error(codes.NotFound, "header not found")
+	}
+	return newHeader(head)
+}
+
+func (*ethServer) GetTransactionReceipt(ctx context.Context, req *TransactionHash) (*Receipt, error) {
+	hash, err := parseHash(req.Hash, "transaction hash")
+	if err != nil {
+		return nil, err
+	}
+	var r *types.Receipt
+	if err := clientFrom(ctx).CallContext(ctx, &r, "eth_getTransactionReceipt", hash); err != nil {
+		return nil, toStatus(err)
+	}
+	if r == nil {
+		return nil, status.Error(codes.NotFound, "receipt not found")
+	}
+	return newReceipt(r), nil
+}
+
+func (*ethServer) GetLogs(ctx context.Context, req *LogFilter) (*GetLogsResponse, error) {
+	filter, err := parseLogFilter(req)
+	if err != nil {
+		return nil, err
+	}
+	var logs []*types.Log
+	if err := clientFrom(ctx).CallContext(ctx, &logs, "eth_getLogs", filter); err != nil {
+		return nil, toStatus(err)
+	}
+	res := &GetLogsResponse{Logs: make([]*Log, len(logs))}
+	for i, l := range logs {
+		res.Logs[i] = newLog(l)
+	}
+	return res, nil
+}
+
+func (*ethServer) SubscribeNewHeads(req *SubscribeNewHeadsRequest, stream Eth_SubscribeNewHeadsServer) error {
+	ctx := stream.Context()
+	ch := make(chan *types.Header, subscriptionBuffer)
+	sub, err := clientFrom(ctx).EthSubscribe(ctx, ch, "newHeads")
+	if err != nil {
+		return toStatus(err)
+	}
+	return forward(ctx, ch, sub, newHeader, stream.Send)
+}
+
+func (*ethServer) SubscribeLogs(req *LogFilter, stream Eth_SubscribeLogsServer) error {
+	filter, err := parseLogFilter(req)
+	if err != nil {
+		return err
+	}
+	ctx := stream.Context()
+	ch := make(chan types.Log, subscriptionBuffer)
+	sub, err := clientFrom(ctx).EthSubscribe(ctx, ch, "logs", filter)
+	if err != nil {
+		return toStatus(err)
+	}
+	return forward(ctx, ch, sub, func(l types.Log) (*Log, error) {
+		return newLog(&l), nil
+	}, stream.Send)
+}
+
+func (*ethServer) SubscribePendingTransactions(req *SubscribePendingTransactionsRequest, stream Eth_SubscribePendingTransactionsServer) error {
+	ctx := stream.Context()
+	ch := make(chan common.Hash, subscriptionBuffer)
+	sub, err := clientFrom(ctx).EthSubscribe(ctx, ch, "newPendingTransactions")
+	if err != nil {
+		return toStatus(err)
+	}
+	return forward(ctx, ch, sub, func(hash common.Hash) (*TransactionHash, error) {
+		return &TransactionHash{Hash: hash.Bytes()}, nil
+	}, stream.Send)
+}
+
+func (*debugServer) GetRawHeader(ctx context.Context, req *BlockRequest) (*RawData, error) {
+	return rawBlockData(ctx, "debug_getRawHeader", req.Block)
+}
+
+func (*debugServer) GetRawBlock(ctx context.Context, req *BlockRequest) (*RawData, error) {
+	return rawBlockData(ctx, "debug_getRawBlock", req.Block)
+}
+
+// rawBlockData calls a debug method returning raw data of a block.
+func rawBlockData(ctx context.Context, method string, selector string) (*RawData, error) {
+	block, err := parseBlock(selector)
+	if err != nil {
+		return nil, err
+	}
+	var data hexutil.Bytes
+	if err := clientFrom(ctx).CallContext(ctx, &data, method, block); err != nil {
+		return nil, toStatus(err)
+	}
+	return &RawData{Data: data}, nil
+}
+
+func (*debugServer) GetRawReceipts(ctx context.Context, req *BlockRequest) (*RawReceipts, error) {
+	block, err := parseBlock(req.Block)
+	if err != nil {
+		return nil, err
+	}
+	var receipts []hexutil.Bytes
+	if err := clientFrom(ctx).CallContext(ctx, &receipts, "debug_getRawReceipts", block); err != nil {
+		return nil, toStatus(err)
+	}
+	res := &RawReceipts{Receipts: make([][]byte, len(receipts))}
+	for i, r := range receipts {
+		res.Receipts[i] = r
+	}
+	return res, nil
+}
+
+func (*debugServer) GetRawTransaction(ctx context.Context, req *TransactionHash) (*RawData, error) {
+	hash, err := parseHash(req.Hash, "transaction hash")
+	if err != nil {
+		return nil, err
+	}
+	var data hexutil.Bytes
+	if err := clientFrom(ctx).CallContext(ctx, &data, "debug_getRawTransaction", hash); err != nil {
+		return nil, toStatus(err)
+	}
+	if data == nil {
+		return nil, status.Error(codes.NotFound, "transaction not found")
+	}
+	return &RawData{Data: data}, nil
+}
+
+func (*debugServer) TraceTransaction(ctx context.Context, req *TraceTransactionRequest) (*TraceResponse, error) {
+	hash, err := parseHash(req.Hash, "transaction hash")
+	if err != nil {
+		return nil, err
+	}
+	config := make(map[string]interface{})
+	if req.Tracer != "" {
+		config["tracer"] = req.Tracer
+	}
+	if req.TracerConfig != "" {
+		if !json.Valid([]byte(req.TracerConfig)) {
+			return nil, status.Error(codes.InvalidArgument, "invalid tracer config")
+		}
+		config["tracerConfig"] = json.RawMessage(req.TracerConfig)
+	}
+	if req.Timeout != "" {
+		config["timeout"] = req.Timeout
+	}
+	var result json.RawMessage
+	if err := clientFrom(ctx).CallContext(ctx, &result, "debug_traceTransaction", hash, config); err != nil {
+		return nil, toStatus(err)
+	}
+	return &TraceResponse{Result: string(result)}, nil
+}
+
+// parseBlock parses a block selector, see api.proto.
+func parseBlock(s string) (rpc.BlockNumberOrHash, error) {
+	if s == "" {
+		return rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), nil
+	}
+	var block rpc.BlockNumberOrHash
+	if err := block.UnmarshalJSON([]byte(strconv.Quote(s))); err != nil {
+		return block, status.Errorf(codes.InvalidArgument, "invalid block %q: %v", s, err)
+	}
+	return block, nil
+}
+
+func parseAddress(b []byte, name string) (common.Address, error) {
+	if len(b) != common.AddressLength {
+		return common.Address{}, status.Errorf(codes.InvalidArgument, "invalid %s length %d", name, len(b))
+	}
+	return common.BytesToAddress(b), nil
+}
+
+func parseHash(b []byte, name string) (common.Hash, error) {
+	if len(b) != common.HashLength {
+		return common.Hash{}, status.Errorf(codes.InvalidArgument, "invalid %s length %d", name, len(b))
+	}
+	return common.BytesToHash(b), nil
+}
+
+func parseAccountRequest(address []byte, block string) (common.Address, rpc.BlockNumberOrHash, error) {
+	addr, err := parseAddress(address, "address")
+	if err != nil {
+		return common.Address{}, rpc.BlockNumberOrHash{}, err
+	}
+	bn, err := parseBlock(block)
+	return addr, bn, err
+}
+
+// parseCallRequest converts a call request into the arguments of eth_call.
+func parseCallRequest(req *CallRequest) (map[string]interface{}, rpc.BlockNumberOrHash, error) {
+	args := make(map[string]interface{})
+	if len(req.From) > 0 {
+		from, err := parseAddress(req.From, "sender")
+		if err != nil {
+			return nil, rpc.BlockNumberOrHash{}, err
+		}
+		args["from"] = from
+	}
+	if len(req.To) > 0 {
+		to, err := parseAddress(req.To, "recipient")
+		if err != nil {
+			return nil, rpc.BlockNumberOrHash{}, err
+		}
+		args["to"] = to
+	}
+	if req.Gas != 0 {
+		args["gas"] = hexutil.Uint64(req.Gas)
+	}
+	for name, value := range map[string][]byte{
+		"gasPrice":             req.GasPrice,
+		"maxFeePerGas":         req.MaxFeePerGas,
+		"maxPriorityFeePerGas": req.MaxPriorityFeePerGas,
+		"value":                req.Value,
+	} {
+		if len(value) > 0 {
+			args[name] = (*hexutil.Big)(new(big.Int).SetBytes(value))
+		}
+	}
+	if len(req.Data) > 0 {
+		args["input"] = hexutil.Bytes(req.Data)
+	}
+	block, err := parseBlock(req.Block)
+	return args, block, err
+}
+
+// parseLogFilter converts a log filter into the criteria of eth_getLogs.
+func parseLogFilter(req *LogFilter) (map[string]interface{}, error) {
+	filter := make(map[string]interface{})
+	if len(req.BlockHash) > 0 {
+		hash, err := parseHash(req.BlockHash, "block hash")
+		if err != nil {
+			return nil, err
+		}
+		filter["blockHash"] = hash
+	}
+	if req.FromBlock != "" {
+		filter["fromBlock"] = req.FromBlock
+	}
+	if req.ToBlock != "" {
+		filter["toBlock"] = req.ToBlock
+	}
+	addresses := make([]common.Address, len(req.Addresses))
+	for i, b := range req.Addresses {
+		addr, err := parseAddress(b, "address")
+		if err != nil {
+			return nil, err
+		}
+		addresses[i] = addr
+	}
+	filter["address"] = addresses
+
+	topics := make([][]common.Hash, len(req.Topics))
+	for i, alternatives := range req.Topics {
+		for _, b := range alternatives.Topics {
+			topic, err := parseHash(b, "topic")
+			if err != nil {
+				return nil, err
+			}
+			topics[i] = append(topics[i], topic)
+		}
+	}
+	filter["topics"] = topics
+	return filter, nil
+}
+
+func newHeader(h *types.Header) (*Header, error) {
+	enc, err := rlp.EncodeToBytes(h)
+	if err != nil {
+		return nil, err
+	}
+	res := &Header{
+		Hash:       h.Hash().Bytes(),
+		ParentHash: h.ParentHash.Bytes(),
+		Number:     h.Number.Uint64(),
+		Timestamp:  h.Time,
+		Coinbase:   h.Coinbase.Bytes(),
+		StateRoot:  h.Root.Bytes(),
+		GasLimit:   h.GasLimit,
+		GasUsed:    h.GasUsed,
+		Rlp:        enc,
+	}
+	if h.BaseFee != nil {
+		res.BaseFee = h.BaseFee.Bytes()
+	}
+	return res, nil
+}
+
+func newLog(l *types.Log) *Log {
+	res := &Log{
+		Address:          l.Address.Bytes(),
+		Topics:           make([][]byte, len(l.Topics)),
+		Data:             l.Data,
+		BlockNumber:      l.BlockNumber,
+		BlockHash:        l.BlockHash.Bytes(),
+		TransactionHash:  l.TxHash.Bytes(),
+		TransactionIndex: uint32(l.TxIndex),
+		LogIndex:         uint32(l.Index),
+		Removed:          l.Removed,
+	}
+	for i, topic := range l.Topics {
+		res.Topics[i] = topic.Bytes()
+	}
+	return res
+}
+
+func newReceipt(r *types.Receipt) *Receipt {
+	res := &Receipt{
+		TransactionHash:   r.TxHash.Bytes(),
+		TransactionIndex:  uint32(r.TransactionIndex),
+		BlockHash:         r.BlockHash.Bytes(),
+		Type:              uint32(r.Type),
+		Status:            r.Status,
+		CumulativeGasUsed: r.CumulativeGasUsed,
+		GasUsed:           r.GasUsed,
+		Logs:              make([]*Log, len(r.Logs)),
+	}
+	if r.BlockNumber != nil {
+		res.BlockNumber = r.BlockNumber.Uint64()
+	}
+	if r.EffectiveGasPrice != nil {
+		res.EffectiveGasPrice = r.EffectiveGasPrice.Bytes()
+	}
+	if r.ContractAddress != (common.Address{}) {
+		res.ContractAddress = r.ContractAddress.Bytes()
+	}
+	for i, l := range r.Logs {
+		res.Logs[i] = newLog(l)
+	}
+	return res
+}
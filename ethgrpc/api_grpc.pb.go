@@ -0,0 +1,976 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Schema of the gRPC interface of geth. Clients can be generated for any
+// language supported by protoc.
+//
+// Conventions:
+//   - addresses are 20 bytes, hashes and storage slots 32 bytes
+//   - quantities which may exceed 64 bits (balances, fees, values) are unsigned
+//     big-endian integers without leading zero bytes
+//   - blocks are selected by a string as in the JSON-RPC API: "latest", "safe",
+//     "finalized", "pending", "earliest", a hex number like "0x10" or a block
+//     hash. An empty selector means "latest".
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: api.proto
+
+package ethgrpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Eth_ChainId_FullMethodName                      = "/geth.v1.Eth/ChainId"
+	Eth_BlockNumber_FullMethodName                  = "/geth.v1.Eth/BlockNumber"
+	Eth_GetBalance_FullMethodName                   = "/geth.v1.Eth/GetBalance"
+	Eth_GetTransactionCount_FullMethodName          = "/geth.v1.Eth/GetTransactionCount"
+	Eth_GetCode_FullMethodName                      = "/geth.v1.Eth/GetCode"
+	Eth_GetStorageAt_FullMethodName                 = "/geth.v1.Eth/GetStorageAt"
+	Eth_Call_FullMethodName                         = "/geth.v1.Eth/Call"
+	Eth_EstimateGas_FullMethodName                  = "/geth.v1.Eth/EstimateGas"
+	Eth_SendRawTransaction_FullMethodName           = "/geth.v1.Eth/SendRawTransaction"
+	Eth_GetHeader_FullMethodName                    = "/geth.v1.Eth/GetHeader"
+	Eth_GetTransactionReceipt_FullMethodName        = "/geth.v1.Eth/GetTransactionReceipt"
+	Eth_GetLogs_FullMethodName                      = "/geth.v1.Eth/GetLogs"
+	Eth_SubscribeNewHeads_FullMethodName            = "/geth.v1.Eth/SubscribeNewHeads"
+	Eth_SubscribeLogs_FullMethodName                = "/geth.v1.Eth/SubscribeLogs"
+	Eth_SubscribePendingTransactions_FullMethodName = "/geth.v1.Eth/SubscribePendingTransactions"
+)
+
+// EthClient is the client API for Eth service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type EthClient interface {
+	ChainId(ctx context.Context, in *ChainIdRequest, opts ...grpc.CallOption) (*ChainIdResponse, error)
+	BlockNumber(ctx context.Context, in *BlockNumberRequest, opts ...grpc.CallOption) (*BlockNumberResponse, error)
+	GetBalance(ctx context.Context, in *AccountRequest, opts ...grpc.CallOption) (*GetBalanceResponse, error)
+	GetTransactionCount(ctx context.Context, in *AccountRequest, opts ...grpc.CallOption) (*GetTransactionCountResponse, error)
+	GetCode(ctx context.Context, in *AccountRequest, opts ...grpc.CallOption) (*GetCodeResponse, error)
+	GetStorageAt(ctx context.Context, in *GetStorageAtRequest, opts ...grpc.CallOption) (*GetStorageAtResponse, error)
+	Call(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*CallResponse, error)
+	EstimateGas(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*EstimateGasResponse, error)
+	SendRawTransaction(ctx context.Context, in *SendRawTransactionRequest, opts ...grpc.CallOption) (*TransactionHash, error)
+	GetHeader(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*Header, error)
+	GetTransactionReceipt(ctx context.Context, in *TransactionHash, opts ...grpc.CallOption) (*Receipt, error)
+	GetLogs(ctx context.Context, in *LogFilter, opts ...grpc.CallOption) (*GetLogsResponse, error)
+	// Subscriptions stream events until the client cancels the call.
+	SubscribeNewHeads(ctx context.Context, in *SubscribeNewHeadsRequest, opts ...grpc.CallOption) (Eth_SubscribeNewHeadsClient, error)
+	SubscribeLogs(ctx context.Context, in *LogFilter, opts ...grpc.CallOption) (Eth_SubscribeLogsClient, error)
+	SubscribePendingTransactions(ctx context.Context, in *SubscribePendingTransactionsRequest, opts ...grpc.CallOption) (Eth_SubscribePendingTransactionsClient, error)
+}
+
+type ethClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEthClient(cc grpc.ClientConnInterface) EthClient {
+	return &ethClient{cc}
+}
+
+func (c *ethClient) ChainId(ctx context.Context, in *ChainIdRequest, opts ...grpc.CallOption) (*ChainIdResponse, error) {
+	out := new(ChainIdResponse)
+	err := c.cc.Invoke(ctx, Eth_ChainId_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ethClient) BlockNumber(ctx context.Context, in *BlockNumberRequest, opts ...grpc.CallOption) (*BlockNumberResponse, error) {
+	out := new(BlockNumberResponse)
+	err := c.cc.Invoke(ctx, Eth_BlockNumber_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ethClient) GetBalance(ctx context.Context, in *AccountRequest, opts ...grpc.CallOption) (*GetBalanceResponse, error) {
+	out := new(GetBalanceResponse)
+	err := c.cc.Invoke(ctx, Eth_GetBalance_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ethClient) GetTransactionCount(ctx context.Context, in *AccountRequest, opts ...grpc.CallOption) (*GetTransactionCountResponse, error) {
+	out := new(GetTransactionCountResponse)
+	err := c.cc.Invoke(ctx, Eth_GetTransactionCount_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ethClient) GetCode(ctx context.Context, in *AccountRequest, opts ...grpc.CallOption) (*GetCodeResponse, error) {
+	out := new(GetCodeResponse)
+	err := c.cc.Invoke(ctx, Eth_GetCode_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ethClient) GetStorageAt(ctx context.Context, in *GetStorageAtRequest, opts ...grpc.CallOption) (*GetStorageAtResponse, error) {
+	out := new(GetStorageAtResponse)
+	err := c.cc.Invoke(ctx, Eth_GetStorageAt_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ethClient) Call(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*CallResponse, error) {
+	out := new(CallResponse)
+	err := c.cc.Invoke(ctx, Eth_Call_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ethClient) EstimateGas(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*EstimateGasResponse, error) {
+	out := new(EstimateGasResponse)
+	err := c.cc.Invoke(ctx, Eth_EstimateGas_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ethClient) SendRawTransaction(ctx context.Context, in *SendRawTransactionRequest, opts ...grpc.CallOption) (*TransactionHash, error) {
+	out := new(TransactionHash)
+	err := c.cc.Invoke(ctx, Eth_SendRawTransaction_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ethClient) GetHeader(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*Header, error) {
+	out := new(Header)
+	err := c.cc.Invoke(ctx, Eth_GetHeader_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ethClient) GetTransactionReceipt(ctx context.Context, in *TransactionHash, opts ...grpc.CallOption) (*Receipt, error) {
+	out := new(Receipt)
+	err := c.cc.Invoke(ctx, Eth_GetTransactionReceipt_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ethClient) GetLogs(ctx context.Context, in *LogFilter, opts ...grpc.CallOption) (*GetLogsResponse, error) {
+	out := new(GetLogsResponse)
+	err := c.cc.Invoke(ctx, Eth_GetLogs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ethClient) SubscribeNewHeads(ctx context.Context, in *SubscribeNewHeadsRequest, opts ...grpc.CallOption) (Eth_SubscribeNewHeadsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Eth_ServiceDesc.Streams[0], Eth_SubscribeNewHeads_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ethSubscribeNewHeadsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Eth_SubscribeNewHeadsClient interface {
+	Recv() (*Header, error)
+	grpc.ClientStream
+}
+
+type ethSubscribeNewHeadsClient struct {
+	grpc.ClientStream
+}
+
+func (x *ethSubscribeNewHeadsClient) Recv() (*Header, error) {
+	m := new(Header)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *ethClient) SubscribeLogs(ctx context.Context, in *LogFilter, opts ...grpc.CallOption) (Eth_SubscribeLogsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Eth_ServiceDesc.Streams[1], Eth_SubscribeLogs_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ethSubscribeLogsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Eth_SubscribeLogsClient interface {
+	Recv() (*Log, error)
+	grpc.ClientStream
+}
+
+type ethSubscribeLogsClient struct {
+	grpc.ClientStream
+}
+
+func (x *ethSubscribeLogsClient) Recv() (*Log, error) {
+	m := new(Log)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *ethClient) SubscribePendingTransactions(ctx context.Context, in *SubscribePendingTransactionsRequest, opts ...grpc.CallOption) (Eth_SubscribePendingTransactionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Eth_ServiceDesc.Streams[2], Eth_SubscribePendingTransactions_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ethSubscribePendingTransactionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Eth_SubscribePendingTransactionsClient interface {
+	Recv() (*TransactionHash, error)
+	grpc.ClientStream
+}
+
+type ethSubscribePendingTransactionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *ethSubscribePendingTransactionsClient) Recv() (*TransactionHash, error) {
+	m := new(TransactionHash)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// EthServer is the server API for Eth service.
+// All implementations must embed UnimplementedEthServer
+// for forward compatibility
+type EthServer interface {
+	ChainId(context.Context, *ChainIdRequest) (*ChainIdResponse, error)
+	BlockNumber(context.Context, *BlockNumberRequest) (*BlockNumberResponse, error)
+	GetBalance(context.Context, *AccountRequest) (*GetBalanceResponse, error)
+	GetTransactionCount(context.Context, *AccountRequest) (*GetTransactionCountResponse, error)
+	GetCode(context.Context, *AccountRequest) (*GetCodeResponse, error)
+	GetStorageAt(context.Context, *GetStorageAtRequest) (*GetStorageAtResponse, error)
+	Call(context.Context, *CallRequest) (*CallResponse, error)
+	EstimateGas(context.Context, *CallRequest) (*EstimateGasResponse, error)
+	SendRawTransaction(context.Context, *SendRawTransactionRequest) (*TransactionHash, error)
+	GetHeader(context.Context, *BlockRequest) (*Header, error)
+	GetTransactionReceipt(context.Context, *TransactionHash) (*Receipt, error)
+	GetLogs(context.Context, *LogFilter) (*GetLogsResponse, error)
+	// Subscriptions stream events until the client cancels the call.
+	SubscribeNewHeads(*SubscribeNewHeadsRequest, Eth_SubscribeNewHeadsServer) error
+	SubscribeLogs(*LogFilter, Eth_SubscribeLogsServer) error
+	SubscribePendingTransactions(*SubscribePendingTransactionsRequest, Eth_SubscribePendingTransactionsServer) error
+	mustEmbedUnimplementedEthServer()
+}
+
+// UnimplementedEthServer must be embedded to have forward compatible implementations.
+type UnimplementedEthServer struct {
+}
+
+func (UnimplementedEthServer) ChainId(context.Context, *ChainIdRequest) (*ChainIdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChainId not implemented")
+}
+func (UnimplementedEthServer) BlockNumber(context.Context, *BlockNumberRequest) (*BlockNumberResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BlockNumber not implemented")
+}
+func (UnimplementedEthServer) GetBalance(context.Context, *AccountRequest) (*GetBalanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
+func (UnimplementedEthServer) GetTransactionCount(context.Context, *AccountRequest) (*GetTransactionCountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionCount not implemented")
+}
+func (UnimplementedEthServer) GetCode(context.Context, *AccountRequest) (*GetCodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCode not implemented")
+}
+func (UnimplementedEthServer) GetStorageAt(context.Context, *GetStorageAtRequest) (*GetStorageAtResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStorageAt not implemented")
+}
+func (UnimplementedEthServer) Call(context.Context, *CallRequest) (*CallResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Call not implemented")
+}
+func (UnimplementedEthServer) EstimateGas(context.Context, *CallRequest) (*EstimateGasResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EstimateGas not implemented")
+}
+func (UnimplementedEthServer) SendRawTransaction(context.Context, *SendRawTransactionRequest) (*TransactionHash, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendRawTransaction not implemented")
+}
+func (UnimplementedEthServer) GetHeader(context.Context, *BlockRequest) (*Header, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHeader not implemented")
+}
+func (UnimplementedEthServer) GetTransactionReceipt(context.Context, *TransactionHash) (*Receipt, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionReceipt not implemented")
+}
+func (UnimplementedEthServer) GetLogs(context.Context, *LogFilter) (*GetLogsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLogs not implemented")
+}
+func (UnimplementedEthServer) SubscribeNewHeads(*SubscribeNewHeadsRequest, Eth_SubscribeNewHeadsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeNewHeads not implemented")
+}
+func (UnimplementedEthServer) SubscribeLogs(*LogFilter, Eth_SubscribeLogsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeLogs not implemented")
+}
+func (UnimplementedEthServer) SubscribePendingTransactions(*SubscribePendingTransactionsRequest, Eth_SubscribePendingTransactionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribePendingTransactions not implemented")
+}
+func (UnimplementedEthServer) mustEmbedUnimplementedEthServer() {}
+
+// UnsafeEthServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EthServer will
+// result in compilation errors.
+type UnsafeEthServer interface {
+	mustEmbedUnimplementedEthServer()
+}
+
+func RegisterEthServer(s grpc.ServiceRegistrar, srv EthServer) {
+	s.RegisterService(&Eth_ServiceDesc, srv)
+}
+
+func _Eth_ChainId_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChainIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EthServer).ChainId(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Eth_ChainId_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EthServer).ChainId(ctx, req.(*ChainIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Eth_BlockNumber_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockNumberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EthServer).BlockNumber(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Eth_BlockNumber_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EthServer).BlockNumber(ctx, req.(*BlockNumberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Eth_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EthServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Eth_GetBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EthServer).GetBalance(ctx, req.(*AccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Eth_GetTransactionCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EthServer).GetTransactionCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Eth_GetTransactionCount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EthServer).GetTransactionCount(ctx, req.(*AccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Eth_GetCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EthServer).GetCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Eth_GetCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EthServer).GetCode(ctx, req.(*AccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Eth_GetStorageAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStorageAtRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EthServer).GetStorageAt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Eth_GetStorageAt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EthServer).GetStorageAt(ctx, req.(*GetStorageAtRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Eth_Call_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EthServer).Call(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Eth_Call_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EthServer).Call(ctx, req.(*CallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Eth_EstimateGas_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EthServer).EstimateGas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Eth_EstimateGas_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EthServer).EstimateGas(ctx, req.(*CallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Eth_SendRawTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendRawTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EthServer).SendRawTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Eth_SendRawTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EthServer).SendRawTransaction(ctx, req.(*SendRawTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Eth_GetHeader_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EthServer).GetHeader(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Eth_GetHeader_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EthServer).GetHeader(ctx, req.(*BlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Eth_GetTransactionReceipt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionHash)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EthServer).GetTransactionReceipt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Eth_GetTransactionReceipt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EthServer).GetTransactionReceipt(ctx, req.(*TransactionHash))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Eth_GetLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EthServer).GetLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Eth_GetLogs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EthServer).GetLogs(ctx, req.(*LogFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Eth_SubscribeNewHeads_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeNewHeadsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EthServer).SubscribeNewHeads(m, &ethSubscribeNewHeadsServer{stream})
+}
+
+type Eth_SubscribeNewHeadsServer interface {
+	Send(*Header) error
+	grpc.ServerStream
+}
+
+type ethSubscribeNewHeadsServer struct {
+	grpc.ServerStream
+}
+
+func (x *ethSubscribeNewHeadsServer) Send(m *Header) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Eth_SubscribeLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LogFilter)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EthServer).SubscribeLogs(m, &ethSubscribeLogsServer{stream})
+}
+
+type Eth_SubscribeLogsServer interface {
+	Send(*Log) error
+	grpc.ServerStream
+}
+
+type ethSubscribeLogsServer struct {
+	grpc.ServerStream
+}
+
+func (x *ethSubscribeLogsServer) Send(m *Log) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Eth_SubscribePendingTransactions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribePendingTransactionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EthServer).SubscribePendingTransactions(m, &ethSubscribePendingTransactionsServer{stream})
+}
+
+type Eth_SubscribePendingTransactionsServer interface {
+	Send(*TransactionHash) error
+	grpc.ServerStream
+}
+
+type ethSubscribePendingTransactionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *ethSubscribePendingTransactionsServer) Send(m *TransactionHash) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Eth_ServiceDesc is the grpc.ServiceDesc for Eth service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Eth_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "geth.v1.Eth",
+	HandlerType: (*EthServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ChainId",
+			Handler:    _Eth_ChainId_Handler,
+		},
+		{
+			MethodName: "BlockNumber",
+			Handler:    _Eth_BlockNumber_Handler,
+		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _Eth_GetBalance_Handler,
+		},
+		{
+			MethodName: "GetTransactionCount",
+			Handler:    _Eth_GetTransactionCount_Handler,
+		},
+		{
+			MethodName: "GetCode",
+			Handler:    _Eth_GetCode_Handler,
+		},
+		{
+			MethodName: "GetStorageAt",
+			Handler:    _Eth_GetStorageAt_Handler,
+		},
+		{
+			MethodName: "Call",
+			Handler:    _Eth_Call_Handler,
+		},
+		{
+			MethodName: "EstimateGas",
+			Handler:    _Eth_EstimateGas_Handler,
+		},
+		{
+			MethodName: "SendRawTransaction",
+			Handler:    _Eth_SendRawTransaction_Handler,
+		},
+		{
+			MethodName: "GetHeader",
+			Handler:    _Eth_GetHeader_Handler,
+		},
+		{
+			MethodName: "GetTransactionReceipt",
+			Handler:    _Eth_GetTransactionReceipt_Handler,
+		},
+		{
+			MethodName: "GetLogs",
+			Handler:    _Eth_GetLogs_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeNewHeads",
+			Handler:       _Eth_SubscribeNewHeads_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeLogs",
+			Handler:       _Eth_SubscribeLogs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribePendingTransactions",
+			Handler:       _Eth_SubscribePendingTransactions_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api.proto",
+}
+
+const (
+	Debug_GetRawHeader_FullMethodName      = "/geth.v1.Debug/GetRawHeader"
+	Debug_GetRawBlock_FullMethodName       = "/geth.v1.Debug/GetRawBlock"
+	Debug_GetRawReceipts_FullMethodName    = "/geth.v1.Debug/GetRawReceipts"
+	Debug_GetRawTransaction_FullMethodName = "/geth.v1.Debug/GetRawTransaction"
+	Debug_TraceTransaction_FullMethodName  = "/geth.v1.Debug/TraceTransaction"
+)
+
+// DebugClient is the client API for Debug service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DebugClient interface {
+	GetRawHeader(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*RawData, error)
+	GetRawBlock(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*RawData, error)
+	GetRawReceipts(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*RawReceipts, error)
+	GetRawTransaction(ctx context.Context, in *TransactionHash, opts ...grpc.CallOption) (*RawData, error)
+	TraceTransaction(ctx context.Context, in *TraceTransactionRequest, opts ...grpc.CallOption) (*TraceResponse, error)
+}
+
+type debugClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDebugClient(cc grpc.ClientConnInterface) DebugClient {
+	return &debugClient{cc}
+}
+
+func (c *debugClient) GetRawHeader(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*RawData, error) {
+	out := new(RawData)
+	err := c.cc.Invoke(ctx, Debug_GetRawHeader_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debugClient) GetRawBlock(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*RawData, error) {
+	out := new(RawData)
+	err := c.cc.Invoke(ctx, Debug_GetRawBlock_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debugClient) GetRawReceipts(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*RawReceipts, error) {
+	out := new(RawReceipts)
+	err := c.cc.Invoke(ctx, Debug_GetRawReceipts_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debugClient) GetRawTransaction(ctx context.Context, in *TransactionHash, opts ...grpc.CallOption) (*RawData, error) {
+	out := new(RawData)
+	err := c.cc.Invoke(ctx, Debug_GetRawTransaction_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *debugClient) TraceTransaction(ctx context.Context, in *TraceTransactionRequest, opts ...grpc.CallOption) (*TraceResponse, error) {
+	out := new(TraceResponse)
+	err := c.cc.Invoke(ctx, Debug_TraceTransaction_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DebugServer is the server API for Debug service.
+// All implementations must embed UnimplementedDebugServer
+// for forward compatibility
+type DebugServer interface {
+	GetRawHeader(context.Context, *BlockRequest) (*RawData, error)
+	GetRawBlock(context.Context, *BlockRequest) (*RawData, error)
+	GetRawReceipts(context.Context, *BlockRequest) (*RawReceipts, error)
+	GetRawTransaction(context.Context, *TransactionHash) (*RawData, error)
+	TraceTransaction(context.Context, *TraceTransactionRequest) (*TraceResponse, error)
+	mustEmbedUnimplementedDebugServer()
+}
+
+// UnimplementedDebugServer must be embedded to have forward compatible implementations.
+type UnimplementedDebugServer struct {
+}
+
+func (UnimplementedDebugServer) GetRawHeader(context.Context, *BlockRequest) (*RawData, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRawHeader not implemented")
+}
+func (UnimplementedDebugServer) GetRawBlock(context.Context, *BlockRequest) (*RawData, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRawBlock not implemented")
+}
+func (UnimplementedDebugServer) GetRawReceipts(context.Context, *BlockRequest) (*RawReceipts, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRawReceipts not implemented")
+}
+func (UnimplementedDebugServer) GetRawTransaction(context.Context, *TransactionHash) (*RawData, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRawTransaction not implemented")
+}
+func (UnimplementedDebugServer) TraceTransaction(context.Context, *TraceTransactionRequest) (*TraceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TraceTransaction not implemented")
+}
+func (UnimplementedDebugServer) mustEmbedUnimplementedDebugServer() {}
+
+// UnsafeDebugServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DebugServer will
+// result in compilation errors.
+type UnsafeDebugServer interface {
+	mustEmbedUnimplementedDebugServer()
+}
+
+func RegisterDebugServer(s grpc.ServiceRegistrar, srv DebugServer) {
+	s.RegisterService(&Debug_ServiceDesc, srv)
+}
+
+func _Debug_GetRawHeader_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebugServer).GetRawHeader(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debug_GetRawHeader_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebugServer).GetRawHeader(ctx, req.(*BlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debug_GetRawBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebugServer).GetRawBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debug_GetRawBlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebugServer).GetRawBlock(ctx, req.(*BlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debug_GetRawReceipts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebugServer).GetRawReceipts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debug_GetRawReceipts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebugServer).GetRawReceipts(ctx, req.(*BlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debug_GetRawTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionHash)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebugServer).GetRawTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debug_GetRawTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebugServer).GetRawTransaction(ctx, req.(*TransactionHash))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Debug_TraceTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TraceTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebugServer).TraceTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Debug_TraceTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebugServer).TraceTransaction(ctx, req.(*TraceTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Debug_ServiceDesc is the grpc.ServiceDesc for Debug service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Debug_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "geth.v1.Debug",
+	HandlerType: (*DebugServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetRawHeader",
+			Handler:    _Debug_GetRawHeader_Handler,
+		},
+		{
+			MethodName: "GetRawBlock",
+			Handler:    _Debug_GetRawBlock_Handler,
+		},
+		{
+			MethodName: "GetRawReceipts",
+			Handler:    _Debug_GetRawReceipts_Handler,
+		},
+		{
+			MethodName: "GetRawTransaction",
+			Handler:    _Debug_GetRawTransaction_Handler,
+		},
+		{
+			MethodName: "TraceTransaction",
+			Handler:    _Debug_TraceTransaction_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api.proto",
+}
@@ -0,0 +1,308 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package ethgrpc implements a gRPC server exposing the core methods of the eth
+// and debug RPC namespaces, for services which prefer typed clients and HTTP/2
+// multiplexing over JSON. The schema is defined in api.proto.
+//
+// The methods are relayed to an RPC handler set up like the HTTP endpoint of the
+// node, hence they behave exactly like their JSON-RPC counterparts and are subject
+// to the same access rules, rate limits, method timeouts and audit log. Clients
+// present API keys in the x-api-key metadata and JWT tokens in the authorization
+// metadata of their calls.
+package ethgrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative api.proto
+
+// Config contains the settings of the gRPC server.
+type Config struct {
+	Host      string // Interface to listen on
+	Port      int    // Port to listen on, zero picks a random port
+	JWTSecret string // Path to the JWT secret authenticating calls, if required
+	TLSCert   string // TLS certificate file, if the server uses TLS
+	TLSKey    string // TLS key file, if the server uses TLS
+}
+
+// DefaultConfig contains the default gRPC server settings.
+var DefaultConfig = Config{
+	Host: "localhost",
+	Port: 8548,
+}
+
+// modules are the RPC namespaces the gRPC services are relayed to.
+var modules = []string{"eth", "debug"}
+
+// subscriptionBuffer is the number of notifications buffered for a subscription
+// stream which is slow to consume them.
+const subscriptionBuffer = 128
+
+// service is the node lifecycle running the gRPC server.
+type service struct {
+	config    Config
+	stack     *node.Node
+	jwtSecret []byte
+	creds     credentials.TransportCredentials
+
+	handler  *rpc.Server
+	server   *grpc.Server
+	listener net.Listener
+}
+
+// New registers the gRPC server as a lifecycle of the node. It must be called
+// after all APIs served by it have been registered.
+//
+// Servers listening on other than loopback interfaces must either authenticate
+// their clients with a JWT secret or use TLS.
+func New(stack *node.Node, config Config) error {
+	s, err := newService(stack, config)
+	if err != nil {
+		return err
+	}
+	stack.RegisterLifecycle(s)
+	return nil
+}
+
+func newService(stack *node.Node, config Config) (*service, error) {
+	s := &service{config: config, stack: stack}
+	if (config.TLSCert == "") != (config.TLSKey == "") {
+		return nil, errors.New("both TLS certificate and key are required")
+	}
+	if config.TLSCert != "" {
+		creds, err := credentials.NewServerTLSFromFile(config.TLSCert, config.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS key pair: %v", err)
+		}
+		s.creds = creds
+	}
+	if config.JWTSecret != "" {
+		secret, err := node.ObtainJWTSecret(config.JWTSecret)
+		if err != nil {
+			return nil, err
+		}
+		s.jwtSecret = secret
+	}
+	if !isLoopback(config.Host) && s.jwtSecret == nil && s.creds == nil {
+		return nil, fmt.Errorf("gRPC server on non-loopback interface %s requires a JWT secret or TLS", config.Host)
+	}
+	return s, nil
+}
+
+// isLoopback reports whether a listening interface only accepts connections from
+// the local machine.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Start implements node.Lifecycle, starting the gRPC server.
+func (s *service) Start() error {
+	handler, err := s.stack.NewRPCServer(modules)
+	if err != nil {
+		return err
+	}
+	endpoint := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		handler.Stop()
+		return fmt.Errorf("failed to listen for gRPC on %s: %v", endpoint, err)
+	}
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(s.interceptUnary),
+		grpc.StreamInterceptor(s.interceptStream),
+	}
+	if s.creds != nil {
+		opts = append(opts, grpc.Creds(s.creds))
+	}
+	s.handler = handler
+	s.listener = listener
+	s.server = grpc.NewServer(opts...)
+	RegisterEthServer(s.server, new(ethServer))
+	RegisterDebugServer(s.server, new(debugServer))
+
+	go s.server.Serve(listener)
+	log.Info("gRPC server started", "endpoint", listener.Addr(), "tls", s.creds != nil, "auth", s.jwtSecret != nil)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the gRPC server. Subscription
+// streams are long-lived, so open calls are cancelled rather than awaited.
+func (s *service) Stop() error {
+	s.server.Stop()
+	s.handler.Stop()
+	log.Info("gRPC server stopped", "endpoint", s.listener.Addr())
+	return nil
+}
+
+type clientKey struct{}
+
+// clientFrom returns the RPC client of the caller, which is attached to the
+// context of every call by the interceptors of the server.
+func clientFrom(ctx context.Context) *rpc.Client {
+	return ctx.Value(clientKey{}).(*rpc.Client)
+}
+
+// dial authenticates the caller and connects it to the RPC handler, on behalf of
+// the caller.
+func (s *service) dial(ctx context.Context) (*rpc.Client, error) {
+	info := rpc.PeerInfo{Transport: "grpc"}
+	if p, ok := peer.FromContext(ctx); ok {
+		info.RemoteAddr = p.Addr.String()
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if keys := md.Get(rpc.APIKeyHeader); len(keys) > 0 {
+		info.APIKey = keys[0]
+	}
+	if s.jwtSecret != nil {
+		var token string
+		if auth := md.Get("authorization"); len(auth) > 0 && strings.HasPrefix(auth[0], "Bearer ") {
+			token = strings.TrimPrefix(auth[0], "Bearer ")
+		}
+		if token == "" {
+			return nil, status.Error(codes.Unauthenticated, "missing token")
+		}
+		scopes, err := node.ValidateJWT(s.jwtSecret, token)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		info.Scopes = scopes
+	}
+	return rpc.DialInProcAs(s.handler, info), nil
+}
+
+func (s *service) interceptUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	client, err := s.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	return handler(context.WithValue(ctx, clientKey{}, client), req)
+}
+
+func (s *service) interceptStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	client, err := s.dial(stream.Context())
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return handler(srv, &clientStream{stream, context.WithValue(stream.Context(), clientKey{}, client)})
+}
+
+// clientStream is a server stream carrying the RPC client of the caller.
+type clientStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *clientStream) Context() context.Context { return s.ctx }
+
+// forward sends the notifications of an RPC subscription to a stream, until
+// either the subscription fails or the client cancels the call.
+func forward[T, M any](ctx context.Context, ch <-chan T, sub *rpc.ClientSubscription, convert func(T) (M, error), send func(M) error) error {
+	defer sub.Unsubscribe()
+	for {
+		select {
+		case v := <-ch:
+			msg, err := convert(v)
+			if err != nil {
+				return toStatus(err)
+			}
+			if err := send(msg); err != nil {
+				return err
+			}
+		case err := <-sub.Err():
+			if err == nil {
+				return status.Error(codes.Unavailable, "subscription closed")
+			}
+			return toStatus(err)
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+// JSON-RPC error codes translated to gRPC status codes.
+const (
+	errcodeReverted       = 3
+	errcodeMethodNotFound = -32601
+	errcodeInvalidParams  = -32602
+	errcodeTimeout        = -32002
+	errcodeAccessDenied   = -32004
+	errcodeLimitExceeded  = -32005
+)
+
+// toStatus converts an error of the RPC handler into a gRPC status error. Errors
+// carrying data, e.g. the return data of reverted calls, include it in the
+// status message.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return status.Error(codes.Unknown, err.Error())
+	}
+	code := codes.Unknown
+	switch rpcErr.ErrorCode() {
+	case errcodeReverted:
+		code = codes.Aborted
+	case errcodeMethodNotFound:
+		code = codes.Unimplemented
+	case errcodeInvalidParams:
+		code = codes.InvalidArgument
+	case errcodeTimeout:
+		code = codes.DeadlineExceeded
+	case errcodeAccessDenied:
+		code = codes.PermissionDenied
+	case errcodeLimitExceeded:
+		code = codes.ResourceExhausted
+	}
+	msg := err.Error()
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if data, ok := dataErr.ErrorData().(string); ok && data != "" {
+			msg = fmt.Sprintf("%s (data: %s)", msg, data)
+		}
+	}
+	return status.Error(code, msg)
+}
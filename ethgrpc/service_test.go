@@ -0,0 +1,276 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethgrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// testEthAPI is a minimal eth namespace served to the gRPC server.
+type testEthAPI struct{}
+
+type testRevertError struct{}
+
+func (testRevertError) Error() string          { return "execution reverted" }
+func (testRevertError) ErrorCode() int         { return errcodeReverted }
+func (testRevertError) ErrorData() interface{} { return "0x08c379a0" }
+
+func (testEthAPI) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1337))
+}
+
+func (testEthAPI) GetBalance(addr common.Address, block rpc.BlockNumberOrHash) (*hexutil.Big, error) {
+	if number, ok := block.Number(); !ok || number != 5 {
+		return nil, errors.New("unexpected block")
+	}
+	return (*hexutil.Big)(new(big.Int).SetBytes(addr.Bytes())), nil
+}
+
+func (testEthAPI) Call(args map[string]interface{}, block rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	return nil, testRevertError{}
+}
+
+func (testEthAPI) GetHeaderByNumber(number rpc.BlockNumber) *types.Header {
+	if number != rpc.LatestBlockNumber {
+		return nil
+	}
+	return testHeader(10)
+}
+
+func (testEthAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, _ := rpc.NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+	go func() {
+		for i := uint64(1); i <= 3; i++ {
+			notifier.Notify(sub.ID, testHeader(i))
+		}
+	}()
+	return sub, nil
+}
+
+func testHeader(number uint64) *types.Header {
+	return &types.Header{
+		Number:     new(big.Int).SetUint64(number),
+		Time:       number * 12,
+		GasLimit:   30_000_000,
+		Difficulty: common.Big0,
+		BaseFee:    big.NewInt(7),
+	}
+}
+
+func startTestServer(t *testing.T, nodeConfig *node.Config, config Config) *grpc.ClientConn {
+	stack, err := node.New(nodeConfig)
+	if err != nil {
+		t.Fatalf("could not create node: %v", err)
+	}
+	t.Cleanup(func() { stack.Close() })
+	stack.RegisterAPIs([]rpc.API{{Namespace: "eth", Service: testEthAPI{}}})
+
+	config.Host = "127.0.0.1"
+	s, err := newService(stack, config)
+	if err != nil {
+		t.Fatalf("could not create gRPC service: %v", err)
+	}
+	stack.RegisterLifecycle(s)
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	conn, err := grpc.Dial(s.listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("could not dial gRPC server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestServer(t *testing.T) {
+	client := NewEthClient(startTestServer(t, &node.Config{}, Config{}))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Unary calls are answered by the RPC handler.
+	chainID, err := client.ChainId(ctx, &ChainIdRequest{})
+	if err != nil {
+		t.Fatalf("ChainId failed: %v", err)
+	}
+	if chainID.ChainId != 1337 {
+		t.Errorf("wrong chain id: have %d, want 1337", chainID.ChainId)
+	}
+	addr := common.HexToAddress("0x0102")
+	balance, err := client.GetBalance(ctx, &AccountRequest{Address: addr.Bytes(), Block: "0x5"})
+	if err != nil {
+		t.Fatalf("GetBalance failed: %v", err)
+	}
+	if !bytes.Equal(balance.Balance, []byte{1, 2}) {
+		t.Errorf("wrong balance: have %x, want 0102", balance.Balance)
+	}
+	head, err := client.GetHeader(ctx, &BlockRequest{})
+	if err != nil {
+		t.Fatalf("GetHeader failed: %v", err)
+	}
+	if want := testHeader(10); head.Number != 10 || head.Timestamp != 120 || !bytes.Equal(head.Hash, want.Hash().Bytes()) || !bytes.Equal(head.BaseFee, []byte{7}) {
+		t.Errorf("wrong header: %v", head)
+	}
+
+	// Errors are translated to status codes.
+	errtests := []struct {
+		name string
+		call func() error
+		code codes.Code
+		msg  string
+	}{
+		{"GetBalance", func() error {
+			_, err := client.GetBalance(ctx, &AccountRequest{Address: []byte{1}})
+			return err
+		}, codes.InvalidArgument, "invalid address length"},
+		{"GetBalance", func() error {
+			_, err := client.GetBalance(ctx, &AccountRequest{Address: addr.Bytes(), Block: "foo"})
+			return err
+		}, codes.InvalidArgument, "invalid block"},
+		{"Call", func() error {
+			_, err := client.Call(ctx, &CallRequest{To: addr.Bytes()})
+			return err
+		}, codes.Aborted, "execution reverted (data: 0x08c379a0)"},
+		{"GetHeader", func() error {
+			_, err := client.GetHeader(ctx, &BlockRequest{Block: "0x1"})
+			return err
+		}, codes.NotFound, "header not found"},
+		{"BlockNumber", func() error {
+			_, err := client.BlockNumber(ctx, &BlockNumberRequest{})
+			return err
+		}, codes.Unimplemented, "does not exist"},
+	}
+	for _, test := range errtests {
+		err := test.call()
+		if status.Code(err) != test.code || !strings.Contains(err.Error(), test.msg) {
+			t.Errorf("%s: wrong error: have %v, want code %v with %q", test.name, err, test.code, test.msg)
+		}
+	}
+}
+
+func TestServerSubscription(t *testing.T) {
+	client := NewEthClient(startTestServer(t, &node.Config{}, Config{}))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := client.SubscribeNewHeads(ctx, &SubscribeNewHeadsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(1); i <= 3; i++ {
+		head, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("notification %d: %v", i, err)
+		}
+		if head.Number != i {
+			t.Errorf("notification %d: wrong header number %d", i, head.Number)
+		}
+		var decoded types.Header
+		if err := rlp.DecodeBytes(head.Rlp, &decoded); err != nil || decoded.Hash() != common.BytesToHash(head.Hash) {
+			t.Errorf("notification %d: header RLP doesn't match hash (err %v)", i, err)
+		}
+	}
+}
+
+// TestServerAuth checks that calls are subject to the JWT authentication of the
+// server and the access rules of the HTTP endpoint.
+func TestServerAuth(t *testing.T) {
+	var secret [32]byte
+	secret[0] = 1
+	secretFile := filepath.Join(t.TempDir(), "jwt.hex")
+	if err := os.WriteFile(secretFile, []byte(hexutil.Encode(secret[:])), 0600); err != nil {
+		t.Fatal(err)
+	}
+	nodeConfig := &node.Config{
+		HTTPAccessControl: &rpc.AccessControl{
+			Default: rpc.AccessRule{Allow: []string{"eth_chainId"}},
+			Keys:    map[string]rpc.AccessRule{"key": {Allow: []string{"eth"}}},
+		},
+	}
+	client := NewEthClient(startTestServer(t, nodeConfig, Config{JWTSecret: secretFile}))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Calls without a valid token are rejected.
+	if _, err := client.ChainId(ctx, &ChainIdRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected unauthenticated error, got %v", err)
+	}
+	badCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer junk")
+	if _, err := client.ChainId(badCtx, &ChainIdRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected unauthenticated error for invalid token, got %v", err)
+	}
+	header := make(http.Header)
+	if err := node.NewJWTAuth(secret)(header); err != nil {
+		t.Fatal(err)
+	}
+	authCtx := metadata.AppendToOutgoingContext(ctx, "authorization", header.Get("Authorization"))
+	if _, err := client.ChainId(authCtx, &ChainIdRequest{}); err != nil {
+		t.Fatalf("authenticated call failed: %v", err)
+	}
+
+	// The access rules of the HTTP endpoint apply.
+	req := &AccountRequest{Address: common.HexToAddress("0x0102").Bytes(), Block: "0x5"}
+	if _, err := client.GetBalance(authCtx, req); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected permission denied error, got %v", err)
+	}
+	keyCtx := metadata.AppendToOutgoingContext(authCtx, strings.ToLower(rpc.APIKeyHeader), "key")
+	if _, err := client.GetBalance(keyCtx, req); err != nil {
+		t.Fatalf("call with API key failed: %v", err)
+	}
+}
+
+func TestServerExposure(t *testing.T) {
+	stack, err := node.New(&node.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stack.Close()
+
+	if _, err := newService(stack, Config{Host: "0.0.0.0"}); err == nil {
+		t.Error("server on public interface created without authentication")
+	}
+	secretFile := filepath.Join(t.TempDir(), "jwt.hex")
+	if _, err := newService(stack, Config{Host: "0.0.0.0", JWTSecret: secretFile}); err != nil {
+		t.Errorf("server with JWT authentication rejected: %v", err)
+	}
+	for _, host := range []string{"localhost", "127.0.0.1", "::1"} {
+		if _, err := newService(stack, Config{Host: host}); err != nil {
+			t.Errorf("server on %s rejected: %v", host, err)
+		}
+	}
+}
@@ -19,6 +19,7 @@ package graphql
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -34,10 +35,14 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	_ "github.com/ethereum/go-ethereum/eth/tracers/native" // register the call tracer
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
 )
 
 var (
@@ -139,6 +144,177 @@ func (a *Account) Storage(ctx context.Context, args struct{ Slot common.Hash })
 	return state.GetState(a.address, args.Slot), nil
 }
 
+func (a *Account) Proof(ctx context.Context, args struct{ Slots *[]common.Hash }) (*AccountProof, error) {
+	var keys []string
+	if args.Slots != nil {
+		for _, slot := range *args.Slots {
+			keys = append(keys, slot.Hex())
+		}
+	}
+	res, err := ethapi.NewBlockChainAPI(a.r.backend).GetProof(ctx, a.address, keys, a.blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountProof{res}, nil
+}
+
+// maxStorageRange is the maximum number of storage slots returned by a single
+// storageRange query.
+const maxStorageRange = 1024
+
+func (a *Account) StorageRange(ctx context.Context, args struct {
+	Start *common.Hash
+	Limit *int32
+}) (*StorageRange, error) {
+	limit := maxStorageRange
+	if args.Limit != nil {
+		if *args.Limit <= 0 {
+			return nil, errors.New("limit must be positive")
+		}
+		limit = min(int(*args.Limit), maxStorageRange)
+	}
+	var start []byte
+	if args.Start != nil {
+		start = args.Start.Bytes()
+	}
+	statedb, header, err := a.r.backend.StateAndHeaderByNumberOrHash(ctx, a.blockNrOrHash)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	result := &StorageRange{entries: []*StorageEntry{}}
+	storageRoot := statedb.GetStorageRoot(a.address)
+	if storageRoot == types.EmptyRootHash || storageRoot == (common.Hash{}) {
+		return result, nil
+	}
+	id := trie.StorageTrieID(header.Root, crypto.Keccak256Hash(a.address.Bytes()), storageRoot)
+	tr, err := trie.NewStateTrie(id, statedb.Database().TrieDB())
+	if err != nil {
+		return nil, err
+	}
+	nodeIt, err := tr.NodeIterator(start)
+	if err != nil {
+		return nil, err
+	}
+	it := trie.NewIterator(nodeIt)
+	for i := 0; i < limit && it.Next(); i++ {
+		_, content, _, err := rlp.Split(it.Value)
+		if err != nil {
+			return nil, err
+		}
+		entry := &StorageEntry{hashedKey: common.BytesToHash(it.Key), value: common.BytesToHash(content)}
+		if preimage := tr.GetKey(it.Key); preimage != nil {
+			key := common.BytesToHash(preimage)
+			entry.key = &key
+		}
+		result.entries = append(result.entries, entry)
+	}
+	if it.Next() {
+		next := common.BytesToHash(it.Key)
+		result.nextKey = &next
+	}
+	return result, it.Err
+}
+
+// AccountProof represents the Merkle proof of an account and its storage slots.
+type AccountProof struct {
+	res *ethapi.AccountResult
+}
+
+func (p *AccountProof) Address(ctx context.Context) common.Address {
+	return p.res.Address
+}
+
+func (p *AccountProof) Balance(ctx context.Context) hexutil.Big {
+	return *p.res.Balance
+}
+
+func (p *AccountProof) Nonce(ctx context.Context) hexutil.Uint64 {
+	return p.res.Nonce
+}
+
+func (p *AccountProof) CodeHash(ctx context.Context) common.Hash {
+	return p.res.CodeHash
+}
+
+func (p *AccountProof) StorageHash(ctx context.Context) common.Hash {
+	return p.res.StorageHash
+}
+
+func (p *AccountProof) AccountProof(ctx context.Context) ([]hexutil.Bytes, error) {
+	return decodeProof(p.res.AccountProof)
+}
+
+func (p *AccountProof) StorageProof(ctx context.Context) []*StorageProof {
+	ret := make([]*StorageProof, 0, len(p.res.StorageProof))
+	for i := range p.res.StorageProof {
+		ret = append(ret, &StorageProof{&p.res.StorageProof[i]})
+	}
+	return ret
+}
+
+// StorageProof represents the Merkle proof of a single storage slot.
+type StorageProof struct {
+	res *ethapi.StorageResult
+}
+
+func (p *StorageProof) Key(ctx context.Context) common.Hash {
+	return common.HexToHash(p.res.Key)
+}
+
+func (p *StorageProof) Value(ctx context.Context) hexutil.Big {
+	return *p.res.Value
+}
+
+func (p *StorageProof) Proof(ctx context.Context) ([]hexutil.Bytes, error) {
+	return decodeProof(p.res.Proof)
+}
+
+// decodeProof converts the hex encoded trie nodes of a proof into bytes.
+func decodeProof(proof []string) ([]hexutil.Bytes, error) {
+	ret := make([]hexutil.Bytes, 0, len(proof))
+	for _, node := range proof {
+		blob, err := hexutil.Decode(node)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, blob)
+	}
+	return ret, nil
+}
+
+// StorageRange represents a page of the storage of an account.
+type StorageRange struct {
+	entries []*StorageEntry
+	nextKey *common.Hash
+}
+
+func (r *StorageRange) Entries(ctx context.Context) []*StorageEntry {
+	return r.entries
+}
+
+func (r *StorageRange) NextKey(ctx context.Context) *common.Hash {
+	return r.nextKey
+}
+
+// StorageEntry represents a single storage slot of an account.
+type StorageEntry struct {
+	hashedKey common.Hash
+	key       *common.Hash
+	value     common.Hash
+}
+
+func (e *StorageEntry) HashedKey(ctx context.Context) common.Hash {
+	return e.hashedKey
+}
+
+func (e *StorageEntry) Key(ctx context.Context) *common.Hash {
+	return e.key
+}
+
+func (e *StorageEntry) Value(ctx context.Context) common.Hash {
+	return e.value
+}
+
 // Log represents an individual log message. All arguments are mandatory.
 type Log struct {
 	r           *Resolver
@@ -641,6 +817,99 @@ func (t *Transaction) RawReceipt(ctx context.Context) (hexutil.Bytes, error) {
 	return receipt.MarshalBinary()
 }
 
+func (t *Transaction) Trace(ctx context.Context) (*CallFrame, error) {
+	_, block := t.resolve(ctx)
+	if block == nil {
+		return nil, nil
+	}
+	backend, ok := t.r.backend.(tracers.Backend)
+	if !ok {
+		return nil, errors.New("tracing not supported by the backend")
+	}
+	tracer := "callTracer"
+	res, err := tracers.NewAPI(backend).TraceTransaction(ctx, t.hash, &tracers.TraceConfig{Tracer: &tracer})
+	if err != nil {
+		return nil, err
+	}
+	blob, ok := res.(json.RawMessage)
+	if !ok {
+		return nil, fmt.Errorf("unexpected tracer result type %T", res)
+	}
+	frame := new(callFrame)
+	if err := json.Unmarshal(blob, frame); err != nil {
+		return nil, err
+	}
+	return &CallFrame{frame}, nil
+}
+
+// callFrame is a message call as encoded by the call tracer.
+type callFrame struct {
+	Type         string          `json:"type"`
+	From         common.Address  `json:"from"`
+	To           *common.Address `json:"to"`
+	Value        *hexutil.Big    `json:"value"`
+	Gas          hexutil.Uint64  `json:"gas"`
+	GasUsed      hexutil.Uint64  `json:"gasUsed"`
+	Input        hexutil.Bytes   `json:"input"`
+	Output       *hexutil.Bytes  `json:"output"`
+	Error        *string         `json:"error"`
+	RevertReason *string         `json:"revertReason"`
+	Calls        []*callFrame    `json:"calls"`
+}
+
+// CallFrame represents a message call in the trace of a transaction.
+type CallFrame struct {
+	frame *callFrame
+}
+
+func (f *CallFrame) Type(ctx context.Context) string {
+	return f.frame.Type
+}
+
+func (f *CallFrame) From(ctx context.Context) common.Address {
+	return f.frame.From
+}
+
+func (f *CallFrame) To(ctx context.Context) *common.Address {
+	return f.frame.To
+}
+
+func (f *CallFrame) Value(ctx context.Context) *hexutil.Big {
+	return f.frame.Value
+}
+
+func (f *CallFrame) Gas(ctx context.Context) hexutil.Uint64 {
+	return f.frame.Gas
+}
+
+func (f *CallFrame) GasUsed(ctx context.Context) hexutil.Uint64 {
+	return f.frame.GasUsed
+}
+
+func (f *CallFrame) Input(ctx context.Context) hexutil.Bytes {
+	return f.frame.Input
+}
+
+func (f *CallFrame) Output(ctx context.Context) *hexutil.Bytes {
+	return f.frame.Output
+}
+
+func (f *CallFrame) Error(ctx context.Context) *string {
+	return f.frame.Error
+}
+
+func (f *CallFrame) RevertReason(ctx context.Context) *string {
+	return f.frame.RevertReason
+}
+
+func (f *CallFrame) Calls(ctx context.Context) []*CallFrame {
+	ret := make([]*CallFrame, 0, len(f.frame.Calls))
+	for _, call := range f.frame.Calls {
+		ret = append(ret, &CallFrame{call})
+	}
+	return ret
+}
+
 type BlockType int
 
 // Block represents an Ethereum block.
@@ -1284,6 +1553,9 @@ func (p *Pending) EstimateGas(ctx context.Context, args struct {
 type Resolver struct {
 	backend      ethapi.Backend
 	filterSystem *filters.FilterSystem
+
+	eventsOnce sync.Once
+	events     *filters.EventSystem // Event system serving subscriptions, created on demand
 }
 
 func (r *Resolver) Block(ctx context.Context, args struct {
@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/beacon"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
@@ -371,6 +372,132 @@ func TestGraphQLConcurrentResolvers(t *testing.T) {
 	}
 }
 
+func TestGraphQLTraceAndState(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		dadStr  = "0x0000000000000000000000000000000000000dad"
+		dad     = common.HexToAddress(dadStr)
+		momStr  = "0x0000000000000000000000000000000000000b0b"
+		genesis = &core.Genesis{
+			Config:     params.AllEthashProtocolChanges,
+			GasLimit:   11500000,
+			Difficulty: big.NewInt(1048576),
+			Alloc: types.GenesisAlloc{
+				addr: {Balance: big.NewInt(params.Ether)},
+				dad: {
+					// CALL(GAS, 0xb0b, 0, 0, 0, 0, 0), STOP
+					Code: common.Hex2Bytes("60006000600060006000730000000000000000000000000000000000000b0b5af100"),
+					Storage: map[common.Hash]common.Hash{
+						common.HexToHash("0x01"): common.HexToHash("0x02"),
+						common.HexToHash("0x03"): common.HexToHash("0x04"),
+					},
+				},
+			},
+		}
+		signer = types.LatestSigner(genesis.Config)
+		stack  = createNode(t)
+	)
+	defer stack.Close()
+
+	var tx *types.Transaction
+	handler, _ := newGQLService(t, stack, false, genesis, 1, func(i int, gen *core.BlockGen) {
+		tx, _ = types.SignNewTx(key, signer, &types.LegacyTx{To: &dad, Gas: 100000, GasPrice: big.NewInt(params.InitialBaseFee)})
+		gen.AddTx(tx)
+	})
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	// Traces of mined transactions are decoded into nested call frames.
+	body := fmt.Sprintf(`{ transaction(hash: "%s") { trace { type from to value input error calls { type from to calls { type } } } } }`, tx.Hash())
+	want := fmt.Sprintf(`{"transaction":{"trace":{"type":"CALL","from":"%s","to":"%s","value":"0x0","input":"0x","error":null,"calls":[{"type":"CALL","from":"%s","to":"%s","calls":[]}]}}}`,
+		strings.ToLower(addr.Hex()), dadStr, dadStr, momStr)
+	res := handler.Schema.Exec(context.Background(), body, "", map[string]interface{}{})
+	if res.Errors != nil {
+		t.Fatalf("failed to execute trace query: %v", res.Errors)
+	}
+	if have, _ := json.Marshal(res.Data); string(have) != want {
+		t.Errorf("trace mismatch.\nExpected:\n%s\nGot:\n%s\n", want, have)
+	}
+	// Storage ranges are paginated in the order of the hashed keys.
+	var (
+		slots   = []common.Hash{common.HexToHash("0x01"), common.HexToHash("0x03")}
+		hashed  = []common.Hash{crypto.Keccak256Hash(slots[0].Bytes()), crypto.Keccak256Hash(slots[1].Bytes())}
+		values  = map[common.Hash]string{hashed[0]: common.HexToHash("0x02").Hex(), hashed[1]: common.HexToHash("0x04").Hex()}
+		visited []common.Hash
+		start   = common.Hash{}
+	)
+	for {
+		body = fmt.Sprintf(`{ block { account(address: "%s") { storageRange(start: "%s", limit: 1) { entries { hashedKey value } nextKey } } } }`, dadStr, start)
+		res = handler.Schema.Exec(context.Background(), body, "", map[string]interface{}{})
+		if res.Errors != nil {
+			t.Fatalf("failed to execute storage range query: %v", res.Errors)
+		}
+		var page struct {
+			Block struct {
+				Account struct {
+					StorageRange struct {
+						Entries []struct {
+							HashedKey common.Hash
+							Value     string
+						}
+						NextKey *common.Hash
+					}
+				}
+			}
+		}
+		if err := json.Unmarshal(res.Data, &page); err != nil {
+			t.Fatalf("failed to decode storage range: %v", err)
+		}
+		rng := page.Block.Account.StorageRange
+		if len(rng.Entries) != 1 {
+			t.Fatalf("wrong storage range page size: %d", len(rng.Entries))
+		}
+		if entry := rng.Entries[0]; values[entry.HashedKey] != entry.Value {
+			t.Fatalf("wrong storage entry: %+v", entry)
+		}
+		visited = append(visited, rng.Entries[0].HashedKey)
+		if rng.NextKey == nil {
+			break
+		}
+		start = *rng.NextKey
+	}
+	if len(visited) != 2 || visited[0] == visited[1] {
+		t.Fatalf("wrong storage iteration: %v", visited)
+	}
+	// Proofs of the account and its storage slots.
+	body = fmt.Sprintf(`{ block { account(address: "%s") { proof(slots: ["%s"]) { address nonce storageProof { key value } } } } }`, dadStr, slots[0])
+	want = fmt.Sprintf(`{"block":{"account":{"proof":{"address":"%s","nonce":"0x0","storageProof":[{"key":"%s","value":"0x2"}]}}}}`, dadStr, slots[0])
+	res = handler.Schema.Exec(context.Background(), body, "", map[string]interface{}{})
+	if res.Errors != nil {
+		t.Fatalf("failed to execute proof query: %v", res.Errors)
+	}
+	if have, _ := json.Marshal(res.Data); string(have) != want {
+		t.Errorf("proof mismatch.\nExpected:\n%s\nGot:\n%s\n", want, have)
+	}
+	body = fmt.Sprintf(`{ block { account(address: "%s") { proof { accountProof storageProof { key } } } } }`, dadStr)
+	res = handler.Schema.Exec(context.Background(), body, "", map[string]interface{}{})
+	if res.Errors != nil {
+		t.Fatalf("failed to execute proof query: %v", res.Errors)
+	}
+	var proof struct {
+		Block struct {
+			Account struct {
+				Proof struct {
+					AccountProof []hexutil.Bytes
+					StorageProof []struct{}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(res.Data, &proof); err != nil {
+		t.Fatalf("failed to decode proof: %v", err)
+	}
+	if len(proof.Block.Account.Proof.AccountProof) == 0 || len(proof.Block.Account.Proof.StorageProof) != 0 {
+		t.Errorf("wrong account proof: %+v", proof)
+	}
+}
+
 func TestWithdrawals(t *testing.T) {
 	var (
 		key, _ = crypto.GenerateKey()
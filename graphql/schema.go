@@ -36,6 +36,7 @@ const schema string = `
     schema {
         query: Query
         mutation: Mutation
+        subscription: Subscription
     }
 
     # Account is an Ethereum account at a particular block.
@@ -54,6 +55,90 @@ const schema string = `
         # Storage provides access to the storage of a contract account, indexed
         # by its 32 byte slot identifier.
         storage(slot: Bytes32!): Bytes32!
+        # Proof is the Merkle proof of the account and the given storage slots,
+        # as returned by eth_getProof.
+        proof(slots: [Bytes32!]): AccountProof!
+        # StorageRange iterates the storage of the account in the order of the
+        # hashed slot keys, starting at the given hashed key. At most limit
+        # entries are returned, defaulting to and capped at 1024.
+        storageRange(start: Bytes32, limit: Int): StorageRange!
+    }
+
+    # AccountProof is the Merkle proof of an account and some of its storage
+    # slots, as defined by EIP-1186.
+    type AccountProof {
+        # Address is the address of the proven account.
+        address: Address!
+        # Balance is the balance of the account, in wei.
+        balance: BigInt!
+        # Nonce is the nonce of the account.
+        nonce: Long!
+        # CodeHash is the hash of the code of the account.
+        codeHash: Bytes32!
+        # StorageHash is the root hash of the storage trie of the account.
+        storageHash: Bytes32!
+        # AccountProof is the list of RLP-encoded trie nodes from the state root
+        # to the account.
+        accountProof: [Bytes!]!
+        # StorageProof contains the proofs of the requested storage slots.
+        storageProof: [StorageProof!]!
+    }
+
+    # StorageProof is the Merkle proof of a single storage slot.
+    type StorageProof {
+        # Key is the storage slot.
+        key: Bytes32!
+        # Value is the value of the storage slot.
+        value: BigInt!
+        # Proof is the list of RLP-encoded trie nodes from the storage root to
+        # the slot.
+        proof: [Bytes!]!
+    }
+
+    # StorageRange is a page of the storage of an account.
+    type StorageRange {
+        # Entries are the storage slots of the page.
+        entries: [StorageEntry!]!
+        # NextKey is the hashed key of the first slot of the following page, or
+        # null if the storage was iterated completely.
+        nextKey: Bytes32
+    }
+
+    # StorageEntry is a single storage slot.
+    type StorageEntry {
+        # HashedKey is the hash of the storage slot, its key in the storage trie.
+        hashedKey: Bytes32!
+        # Key is the storage slot, if its preimage is known to the node.
+        key: Bytes32
+        # Value is the value of the storage slot.
+        value: Bytes32!
+    }
+
+    # CallFrame is a message call executed by a transaction, as reported by the
+    # call tracer.
+    type CallFrame {
+        # Type is the kind of the call, such as CALL, DELEGATECALL or CREATE.
+        type: String!
+        # From is the address of the caller.
+        from: Address!
+        # To is the address of the callee, or the created contract.
+        to: Address
+        # Value is the amount of wei transferred with the call, if any.
+        value: BigInt
+        # Gas is the amount of gas provided to the call.
+        gas: Long!
+        # GasUsed is the amount of gas used by the call.
+        gasUsed: Long!
+        # Input is the call data, or the init code of contract creations.
+        input: Bytes!
+        # Output is the data returned by the call.
+        output: Bytes
+        # Error is the error the call failed with, if any.
+        error: String
+        # RevertReason is the decoded reason of a reverted call, if any.
+        revertReason: String
+        # Calls are the calls made by this call.
+        calls: [CallFrame!]!
     }
 
     # Log is an Ethereum event log.
@@ -170,6 +255,9 @@ const schema string = `
         rawReceipt: Bytes!
         # BlobVersionedHashes is a set of hash outputs from the blobs in the transaction.
         blobVersionedHashes: [Bytes32!]
+        # Trace is the call trace of the transaction, obtained by re-executing
+        # it. This will be null if the transaction has not yet been mined.
+        trace: CallFrame
     }
 
     # BlockFilterCriteria encapsulates log filter criteria for a filter applied
@@ -391,4 +479,17 @@ const schema string = `
         # SendRawTransaction sends an RLP-encoded transaction to the network.
         sendRawTransaction(data: Bytes!): Bytes32!
     }
+
+    # Subscriptions are only available over the WebSocket transport, using the
+    # graphql-transport-ws protocol.
+    type Subscription {
+        # NewHeads emits each block imported as the new head of the chain.
+        newHeads: Block!
+        # NewLogs emits the logs matching the filter of each block imported into
+        # the chain.
+        newLogs(filter: BlockFilterCriteria!): Log!
+        # NewPendingTransactions emits each transaction entering the transaction
+        # pool.
+        newPendingTransactions: Transaction!
+    }
 `
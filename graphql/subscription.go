@@ -0,0 +1,426 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
+	"github.com/graph-gophers/graphql-go"
+	gqlErrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+const (
+	// wsProtocol is the WebSocket subprotocol spoken by the GraphQL server.
+	wsProtocol = "graphql-transport-ws"
+
+	// wsInitTimeout is the time a client has to initialise the connection
+	// after opening it.
+	wsInitTimeout = 10 * time.Second
+
+	// wsWriteTimeout is the maximum time allowed to write a message.
+	wsWriteTimeout = 10 * time.Second
+
+	// wsMaxSubscriptions is the maximum number of concurrent operations a
+	// single connection may run.
+	wsMaxSubscriptions = 100
+
+	// wsReadLimit is the maximum size of a message sent by the client.
+	wsReadLimit = 1024 * 1024
+)
+
+// Message types of the graphql-transport-ws protocol.
+const (
+	msgConnectionInit = "connection_init"
+	msgConnectionAck  = "connection_ack"
+	msgPing           = "ping"
+	msgPong           = "pong"
+	msgSubscribe      = "subscribe"
+	msgNext           = "next"
+	msgError          = "error"
+	msgComplete       = "complete"
+)
+
+// Close codes of the graphql-transport-ws protocol.
+const (
+	closeInvalidMessage      = 4400
+	closeInitTimeout         = 4408
+	closeSubscriberExists    = 4409
+	closeTooManyInitRequests = 4429
+)
+
+// eventSystem returns the event system serving subscriptions, creating it on
+// first use.
+func (r *Resolver) eventSystem() *filters.EventSystem {
+	r.eventsOnce.Do(func() {
+		r.events = filters.NewEventSystem(r.filterSystem)
+	})
+	return r.events
+}
+
+// NewHeads subscribes to the blocks imported as the new head of the chain.
+func (r *Resolver) NewHeads(ctx context.Context) (<-chan *Block, error) {
+	var (
+		headers = make(chan *types.Header)
+		sub     = r.eventSystem().SubscribeNewHeads(headers)
+		blocks  = make(chan *Block)
+	)
+	go func() {
+		defer close(blocks)
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case header := <-headers:
+				numberOrHash := rpc.BlockNumberOrHashWithHash(header.Hash(), true)
+				block := &Block{r: r, numberOrHash: &numberOrHash, hash: header.Hash(), header: header}
+				select {
+				case blocks <- block:
+				case <-ctx.Done():
+					return
+				}
+			case <-sub.Err():
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return blocks, nil
+}
+
+// NewLogs subscribes to the logs matching the filter of the blocks imported into
+// the chain. Logs removed by reorganisations are not emitted.
+func (r *Resolver) NewLogs(ctx context.Context, args struct{ Filter BlockFilterCriteria }) (<-chan *Log, error) {
+	var crit ethereum.FilterQuery
+	if args.Filter.Addresses != nil {
+		crit.Addresses = *args.Filter.Addresses
+	}
+	if args.Filter.Topics != nil {
+		crit.Topics = *args.Filter.Topics
+	}
+	matches := make(chan []*types.Log)
+	sub, err := r.eventSystem().SubscribeLogs(crit, matches)
+	if err != nil {
+		return nil, err
+	}
+	logs := make(chan *Log)
+	go func() {
+		defer close(logs)
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case batch := <-matches:
+				for _, l := range batch {
+					if l.Removed {
+						continue
+					}
+					select {
+					case logs <- &Log{r: r, transaction: &Transaction{r: r, hash: l.TxHash}, log: l}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			case <-sub.Err():
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return logs, nil
+}
+
+// NewPendingTransactions subscribes to the transactions entering the pool.
+func (r *Resolver) NewPendingTransactions(ctx context.Context) (<-chan *Transaction, error) {
+	var (
+		pending = make(chan []*types.Transaction)
+		sub     = r.eventSystem().SubscribePendingTxs(pending)
+		txs     = make(chan *Transaction)
+	)
+	go func() {
+		defer close(txs)
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case batch := <-pending:
+				for _, tx := range batch {
+					select {
+					case txs <- &Transaction{r: r, hash: tx.Hash(), tx: tx}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			case <-sub.Err():
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return txs, nil
+}
+
+// newUpgradeHandler wraps the GraphQL handler stack, making sure WebSocket
+// upgrade requests bypass response compression, which can't hijack connections.
+func newUpgradeHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if websocket.IsWebSocketUpgrade(r) {
+			r.Header.Del("Accept-Encoding")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// wsMessage is a message of the graphql-transport-ws protocol.
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// wsSubscribePayload is the payload of a subscribe message.
+type wsSubscribePayload struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// wsConn is a WebSocket connection running GraphQL operations.
+type wsConn struct {
+	schema  *graphql.Schema
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+
+	mu         sync.Mutex
+	operations map[string]*wsOperation
+	wg         sync.WaitGroup
+}
+
+// wsOperation is an operation running on a WebSocket connection. Operations are
+// tracked by pointer, as the client may reuse the id of a completed operation.
+type wsOperation struct {
+	cancel context.CancelFunc
+}
+
+// serveWebSocket upgrades the request to a WebSocket connection and serves the
+// graphql-transport-ws protocol on it.
+func (h handler) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{
+		Subprotocols: []string{wsProtocol},
+		CheckOrigin:  h.checkOrigin,
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Debug("GraphQL WebSocket upgrade failed", "err", err)
+		return
+	}
+	if conn.Subprotocol() != wsProtocol {
+		conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseProtocolError, "unsupported subprotocol"), time.Now().Add(wsWriteTimeout))
+		conn.Close()
+		return
+	}
+	// Clear the deadlines of the HTTP server, the connection is long-lived.
+	conn.SetReadDeadline(time.Time{})
+	conn.SetReadLimit(wsReadLimit)
+
+	c := &wsConn{schema: h.Schema, conn: conn, operations: make(map[string]*wsOperation)}
+	c.serve(r.Context())
+}
+
+// checkOrigin reports whether the origin of a WebSocket upgrade request is one
+// of the permitted origins, or the host serving the request.
+func (h handler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || slices.Contains(h.origins, "*") || slices.Contains(h.origins, origin) {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// serve reads the messages of the client until the connection is closed.
+func (c *wsConn) serve(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		c.wg.Wait()
+		c.conn.Close()
+	}()
+	// The client must initialise the connection before anything else.
+	initTimer := time.AfterFunc(wsInitTimeout, func() { c.close(closeInitTimeout, "Connection initialisation timeout") })
+	defer initTimer.Stop()
+
+	initialised := false
+	for {
+		var msg wsMessage
+		if err := c.conn.ReadJSON(&msg); err != nil {
+			if _, ok := err.(*json.SyntaxError); ok {
+				c.close(closeInvalidMessage, "Invalid message")
+			}
+			return
+		}
+		switch msg.Type {
+		case msgConnectionInit:
+			if initialised {
+				c.close(closeTooManyInitRequests, "Too many initialisation requests")
+				return
+			}
+			initialised = true
+			initTimer.Stop()
+			c.write(&wsMessage{Type: msgConnectionAck})
+
+		case msgPing:
+			c.write(&wsMessage{Type: msgPong})
+
+		case msgPong:
+
+		case msgSubscribe:
+			if !initialised {
+				c.close(websocket.ClosePolicyViolation, "Unauthorized")
+				return
+			}
+			var payload wsSubscribePayload
+			if msg.ID == "" || json.Unmarshal(msg.Payload, &payload) != nil {
+				c.close(closeInvalidMessage, "Invalid subscribe message")
+				return
+			}
+			if !c.start(ctx, msg.ID, &payload) {
+				return
+			}
+
+		case msgComplete:
+			c.stop(msg.ID)
+
+		default:
+			c.close(closeInvalidMessage, "Invalid message type "+msg.Type)
+			return
+		}
+	}
+}
+
+// start runs the operation of a subscribe message in the background, reporting
+// whether the connection may continue.
+func (c *wsConn) start(ctx context.Context, id string, payload *wsSubscribePayload) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.operations[id]; ok {
+		c.close(closeSubscriberExists, "Subscriber for "+id+" already exists")
+		return false
+	}
+	if len(c.operations) >= wsMaxSubscriptions {
+		c.writeErrors(id, &gqlErrors.QueryError{Message: "too many active subscriptions"})
+		return true
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	responses, err := c.schema.Subscribe(ctx, payload.Query, payload.OperationName, payload.Variables)
+	if err != nil {
+		cancel()
+		c.writeErrors(id, &gqlErrors.QueryError{Message: err.Error()})
+		return true
+	}
+	op := &wsOperation{cancel: cancel}
+	c.operations[id] = op
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.run(ctx, id, responses)
+
+		// The operation may have been stopped and its id reused by a newer one.
+		c.mu.Lock()
+		if c.operations[id] == op {
+			delete(c.operations, id)
+		}
+		c.mu.Unlock()
+		cancel()
+
+		// Drain the responses to release the executor after cancellation.
+		for range responses {
+		}
+	}()
+	return true
+}
+
+// run forwards the responses of an operation to the client until the operation
+// completes or is cancelled.
+func (c *wsConn) run(ctx context.Context, id string, responses <-chan interface{}) {
+	for {
+		select {
+		case resp, ok := <-responses:
+			if !ok {
+				c.write(&wsMessage{ID: id, Type: msgComplete})
+				return
+			}
+			payload, err := json.Marshal(resp)
+			if err != nil {
+				c.writeErrors(id, &gqlErrors.QueryError{Message: err.Error()})
+				return
+			}
+			if err := c.write(&wsMessage{ID: id, Type: msgNext, Payload: payload}); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// stop cancels the operation with the given id.
+func (c *wsConn) stop(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if op, ok := c.operations[id]; ok {
+		op.cancel()
+		delete(c.operations, id)
+	}
+}
+
+// write sends a message to the client.
+func (c *wsConn) write(msg *wsMessage) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return c.conn.WriteJSON(msg)
+}
+
+// writeErrors reports the failure of an operation to the client.
+func (c *wsConn) writeErrors(id string, errs ...*gqlErrors.QueryError) {
+	payload, _ := json.Marshal(errs)
+	c.write(&wsMessage{ID: id, Type: msgError, Payload: payload})
+}
+
+// close terminates the connection with the given close code.
+func (c *wsConn) close(code int, reason string) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteTimeout))
+	c.conn.Close()
+}
@@ -0,0 +1,139 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/gorilla/websocket"
+)
+
+func TestGraphQLSubscriptions(t *testing.T) {
+	stack := createNode(t)
+	defer stack.Close()
+
+	// Other tests in the package mutate the shared config, so reset any
+	// merge-related fields.
+	config := *params.AllEthashProtocolChanges
+	config.TerminalTotalDifficulty = nil
+	config.TerminalTotalDifficultyPassed = true
+	config.ShanghaiTime = nil
+	genesis := &core.Genesis{
+		Config:     &config,
+		GasLimit:   11500000,
+		Difficulty: big.NewInt(1048576),
+	}
+	backend, err := eth.New(stack, &ethconfig.Config{
+		Genesis:        genesis,
+		NetworkId:      1337,
+		TrieCleanCache: 5,
+		TrieDirtyCache: 5,
+		TrieTimeout:    60 * time.Minute,
+		SnapshotCache:  5,
+		StateScheme:    rawdb.HashScheme,
+	})
+	if err != nil {
+		t.Fatalf("could not create eth backend: %v", err)
+	}
+	filterSystem := filters.NewFilterSystem(backend.APIBackend, filters.Config{})
+	if _, err := newHandler(stack, backend.APIBackend, filterSystem, []string{}, []string{}); err != nil {
+		t.Fatalf("could not create graphql service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	dialer := websocket.Dialer{Subprotocols: []string{wsProtocol}}
+	endpoint := strings.Replace(stack.HTTPEndpoint(), "http://", "ws://", 1) + "/graphql"
+	conn, _, err := dialer.Dial(endpoint, nil)
+	if err != nil {
+		t.Fatalf("could not dial graphql websocket: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+
+	send := func(msg string) {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	recv := func() wsMessage {
+		var msg wsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		return msg
+	}
+	send(`{"type":"connection_init"}`)
+	if msg := recv(); msg.Type != msgConnectionAck {
+		t.Fatalf("expected connection ack, got %+v", msg)
+	}
+	// Queries complete after their single result.
+	send(`{"id":"q","type":"subscribe","payload":{"query":"{block{number}}"}}`)
+	if msg := recv(); msg.ID != "q" || msg.Type != msgNext || string(msg.Payload) != `{"data":{"block":{"number":"0x0"}}}` {
+		t.Fatalf("unexpected query result %+v", msg)
+	}
+	if msg := recv(); msg.ID != "q" || msg.Type != msgComplete {
+		t.Fatalf("expected query completion, got %+v", msg)
+	}
+	// Subscriptions emit every imported head.
+	send(`{"id":"s","type":"subscribe","payload":{"query":"subscription{newHeads{number}}"}}`)
+	send(`{"type":"ping"}`)
+	if msg := recv(); msg.Type != msgPong {
+		t.Fatalf("expected pong, got %+v", msg)
+	}
+	blocks, _ := core.GenerateChain(genesis.Config, backend.BlockChain().Genesis(), ethash.NewFaker(), backend.ChainDb(), 2, nil)
+	for _, block := range blocks {
+		if _, err := backend.BlockChain().InsertChain([]*types.Block{block}); err != nil {
+			t.Fatalf("could not import block: %v", err)
+		}
+		var result struct {
+			Data struct {
+				NewHeads struct {
+					Number string `json:"number"`
+				} `json:"newHeads"`
+			} `json:"data"`
+		}
+		msg := recv()
+		if msg.ID != "s" || msg.Type != msgNext {
+			t.Fatalf("expected subscription event, got %+v", msg)
+		}
+		if err := json.Unmarshal(msg.Payload, &result); err != nil {
+			t.Fatalf("invalid subscription event: %v", err)
+		}
+		if want := (*hexutil.Big)(block.Number()).String(); result.Data.NewHeads.Number != want {
+			t.Fatalf("wrong head number: have %s, want %s", result.Data.NewHeads.Number, want)
+		}
+	}
+	// Duplicate operation ids terminate the connection.
+	send(`{"id":"s","type":"subscribe","payload":{"query":"subscription{newHeads{number}}"}}`)
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, closeSubscriberExists) {
+		t.Fatalf("expected close error %d, got %v", closeSubscriberExists, err)
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package health implements HTTP endpoints reporting the liveness and readiness
+// of a node, suitable for Kubernetes probes and load-balancer health checks.
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+)
+
+// Config contains the thresholds a node must meet to be reported as ready.
+type Config struct {
+	MinPeers    int           // Minimum number of connected peers
+	MaxBlockAge time.Duration // Maximum age of the head block, zero disables the check
+}
+
+// DefaultConfig contains the default readiness thresholds.
+var DefaultConfig = Config{
+	MinPeers:    1,
+	MaxBlockAge: time.Minute,
+}
+
+// Check is the outcome of a single health check.
+type Check struct {
+	Name    string `json:"name"`
+	OK      bool   `json:"ok"`
+	Message string `json:"message,omitempty"`
+}
+
+// Report is the response of the health endpoints.
+type Report struct {
+	OK     bool    `json:"ok"`
+	Checks []Check `json:"checks"`
+}
+
+// peerCounter is the subset of the p2p server used to count peers.
+type peerCounter interface {
+	PeerCount() int
+}
+
+// service serves the health and readiness endpoints.
+type service struct {
+	backend ethapi.Backend
+	peers   peerCounter
+	config  Config
+	now     func() time.Time
+}
+
+// New registers the /health and /ready endpoints on the HTTP server of the node.
+// The health endpoint reports whether the node is alive and its database is
+// readable, the readiness endpoint additionally requires the node to be synced,
+// sufficiently connected and following the chain.
+func New(stack *node.Node, backend ethapi.Backend, config Config) error {
+	s := &service{
+		backend: backend,
+		peers:   stack.Server(),
+		config:  config,
+		now:     time.Now,
+	}
+	stack.RegisterHandler("Health", "/health", http.HandlerFunc(s.serveHealth))
+	stack.RegisterHandler("Readiness", "/ready", http.HandlerFunc(s.serveReady))
+	return nil
+}
+
+// serveHealth reports the liveness of the node.
+func (s *service) serveHealth(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, newReport(s.checkDatabase()))
+}
+
+// serveReady reports whether the node is ready to serve requests.
+func (s *service) serveReady(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, newReport(s.checkDatabase(), s.checkSync(), s.checkPeers(), s.checkBlockAge()))
+}
+
+// checkDatabase verifies that the head header can be read from the database.
+func (s *service) checkDatabase() Check {
+	check := Check{Name: "database"}
+	head := s.backend.CurrentHeader()
+	if head == nil {
+		check.Message = "no head header"
+		return check
+	}
+	if rawdb.ReadHeader(s.backend.ChainDb(), head.Hash(), head.Number.Uint64()) == nil {
+		check.Message = fmt.Sprintf("head header #%d unreadable", head.Number)
+		return check
+	}
+	check.OK = true
+	return check
+}
+
+// checkSync verifies that the node is not catching up with the network.
+func (s *service) checkSync() Check {
+	check := Check{Name: "sync"}
+	progress := s.backend.SyncProgress()
+	if progress.CurrentBlock < progress.HighestBlock {
+		check.Message = fmt.Sprintf("syncing, at block %d of %d", progress.CurrentBlock, progress.HighestBlock)
+		return check
+	}
+	check.OK = true
+	return check
+}
+
+// checkPeers verifies that the node is connected to enough peers.
+func (s *service) checkPeers() Check {
+	check := Check{Name: "peers"}
+	count := s.peers.PeerCount()
+	if count < s.config.MinPeers {
+		check.Message = fmt.Sprintf("%d peers connected, want at least %d", count, s.config.MinPeers)
+		return check
+	}
+	check.OK = true
+	return check
+}
+
+// checkBlockAge verifies that the head block is recent.
+func (s *service) checkBlockAge() Check {
+	check := Check{Name: "blockAge"}
+	if s.config.MaxBlockAge <= 0 {
+		check.OK = true
+		return check
+	}
+	head := s.backend.CurrentHeader()
+	if head == nil {
+		check.Message = "no head header"
+		return check
+	}
+	age := s.now().Sub(time.Unix(int64(head.Time), 0))
+	if age > s.config.MaxBlockAge {
+		check.Message = fmt.Sprintf("head block #%d is %v old, want at most %v", head.Number, age.Round(time.Second), s.config.MaxBlockAge)
+		return check
+	}
+	check.OK = true
+	return check
+}
+
+// newReport aggregates the outcome of the given checks.
+func newReport(checks ...Check) *Report {
+	report := &Report{OK: true, Checks: checks}
+	for _, check := range checks {
+		report.OK = report.OK && check.OK
+	}
+	return report
+}
+
+// writeReport responds with the given report, using the status code to signal
+// failures to probes which ignore the body.
+func writeReport(w http.ResponseWriter, report *Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	if report.OK {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Debug("Failed to write health report", "err", err)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package health
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+)
+
+type testBackend struct {
+	ethapi.Backend
+	db       ethdb.Database
+	head     *types.Header
+	progress ethereum.SyncProgress
+}
+
+func (b *testBackend) ChainDb() ethdb.Database             { return b.db }
+func (b *testBackend) CurrentHeader() *types.Header        { return b.head }
+func (b *testBackend) SyncProgress() ethereum.SyncProgress { return b.progress }
+
+type testPeers int
+
+func (p testPeers) PeerCount() int { return int(p) }
+
+func TestHealthEndpoints(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	head := &types.Header{Number: big.NewInt(10), Time: uint64(now.Unix()) - 12}
+	db := rawdb.NewMemoryDatabase()
+	rawdb.WriteHeader(db, head)
+
+	backend := &testBackend{db: db, head: head}
+	s := &service{
+		backend: backend,
+		peers:   testPeers(3),
+		config:  Config{MinPeers: 2, MaxBlockAge: time.Minute},
+		now:     func() time.Time { return now },
+	}
+	serve := func(handler http.HandlerFunc) (int, *Report) {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		var report Report
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatalf("invalid report: %v", err)
+		}
+		return rec.Code, &report
+	}
+	failed := func(report *Report) []string {
+		var names []string
+		for _, check := range report.Checks {
+			if !check.OK {
+				names = append(names, check.Name)
+			}
+		}
+		return names
+	}
+	if code, report := serve(s.serveHealth); code != http.StatusOK || !report.OK {
+		t.Fatalf("healthy node reported %d: %+v", code, report)
+	}
+	if code, report := serve(s.serveReady); code != http.StatusOK || !report.OK {
+		t.Fatalf("ready node reported %d: %+v", code, report)
+	}
+	// Syncing, poorly connected nodes with a stale head are alive but not ready.
+	backend.progress = ethereum.SyncProgress{CurrentBlock: 10, HighestBlock: 100}
+	s.peers = testPeers(1)
+	s.now = func() time.Time { return now.Add(time.Hour) }
+
+	if code, report := serve(s.serveHealth); code != http.StatusOK || !report.OK {
+		t.Fatalf("healthy node reported %d: %+v", code, report)
+	}
+	code, report := serve(s.serveReady)
+	if code != http.StatusServiceUnavailable || report.OK {
+		t.Fatalf("unready node reported %d: %+v", code, report)
+	}
+	if names := failed(report); len(names) != 3 || names[0] != "sync" || names[1] != "peers" || names[2] != "blockAge" {
+		t.Fatalf("wrong failed checks: %v", names)
+	}
+	// A head missing from the database renders the node unhealthy.
+	backend.head = &types.Header{Number: big.NewInt(11)}
+	if code, report := serve(s.serveHealth); code != http.StatusServiceUnavailable || report.OK {
+		t.Fatalf("unhealthy node reported %d: %+v", code, report)
+	}
+}
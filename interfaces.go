@@ -100,6 +100,11 @@ type SyncProgress struct {
 	CurrentBlock  uint64 // Current block number where sync is at
 	HighestBlock  uint64 // Highest alleged block number in the chain
 
+	// Chain download fields.
+	SyncedHeaders  uint64 // Number of headers downloaded
+	SyncedBodies   uint64 // Number of block bodies downloaded
+	SyncedReceipts uint64 // Number of block receipts downloaded
+
 	// "fast sync" fields. These used to be sent by geth, but are no longer used
 	// since version v1.10.
 	PulledStates uint64 // Number of state trie entries already downloaded
@@ -166,6 +171,14 @@ type ContractCaller interface {
 	CallContract(ctx context.Context, call CallMsg, blockNumber *big.Int) ([]byte, error)
 }
 
+// OverrideContractCaller provides contract calls executed against a modified state
+// and block context, e.g. to evaluate a call with hypothetical balances or code. The
+// accounts of state are overridden before the call, and the set fields of block
+// replace the ones of the block header. Either of them can be nil.
+type OverrideContractCaller interface {
+	CallContractWithOverrides(ctx context.Context, call CallMsg, blockNumber *big.Int, state map[common.Address]OverrideAccount, block *BlockOverrides) ([]byte, error)
+}
+
 // FilterQuery contains options for contract log filtering.
 type FilterQuery struct {
 	BlockHash *common.Hash     // used by eth_getLogs, return logs only from block with this hash
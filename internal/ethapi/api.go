@@ -17,6 +17,7 @@
 package ethapi
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"errors"
@@ -27,6 +28,7 @@ import (
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/audit"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/accounts/scwallet"
 	"github.com/ethereum/go-ethereum/common"
@@ -159,6 +161,9 @@ func (api *EthereumAPI) Syncing() (interface{}, error) {
 		"startingBlock":          hexutil.Uint64(progress.StartingBlock),
 		"currentBlock":           hexutil.Uint64(progress.CurrentBlock),
 		"highestBlock":           hexutil.Uint64(progress.HighestBlock),
+		"syncedHeaders":          hexutil.Uint64(progress.SyncedHeaders),
+		"syncedBodies":           hexutil.Uint64(progress.SyncedBodies),
+		"syncedReceipts":         hexutil.Uint64(progress.SyncedReceipts),
 		"syncedAccounts":         hexutil.Uint64(progress.SyncedAccounts),
 		"syncedAccountBytes":     hexutil.Uint64(progress.SyncedAccountBytes),
 		"syncedBytecodes":        hexutil.Uint64(progress.SyncedBytecodes),
@@ -420,10 +425,57 @@ func (api *PersonalAccountAPI) ImportRawKey(privkey string, password string) (co
 	return acc.Address, err
 }
 
+// ImportMnemonic derives the accounts of the given BIP-39 mnemonic and stores
+// them into the key directory, encrypting them with the passphrase. The accounts
+// are derived along the path template, m/44'/60'/0'/0/x by default. If count is
+// nil, the accounts with a balance or nonce in the latest state are imported.
+func (api *PersonalAccountAPI) ImportMnemonic(ctx context.Context, mnemonic string, password string, path *string, count *uint64, seedPassphrase *string) ([]common.Address, error) {
+	ks, err := fetchKeystore(api.am)
+	if err != nil {
+		return nil, err
+	}
+	template := accounts.DerivationTemplate{Base: accounts.DefaultBaseDerivationPath, Index: len(accounts.DefaultBaseDerivationPath) - 1}
+	if path != nil {
+		if template, err = accounts.ParseDerivationTemplate(*path); err != nil {
+			return nil, err
+		}
+	}
+	var passphrase string
+	if seedPassphrase != nil {
+		passphrase = *seedPassphrase
+	}
+	var paths []accounts.DerivationPath
+	if count != nil {
+		if *count == 0 || *count > 1024 {
+			return nil, errors.New("account count must be between 1 and 1024")
+		}
+		paths = keystore.MnemonicPaths(template, int(*count))
+	} else {
+		state, _, err := api.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+		if state == nil || err != nil {
+			return nil, err
+		}
+		used := func(addr common.Address) (bool, error) {
+			return state.GetNonce(addr) > 0 || state.GetBalance(addr).Sign() > 0, nil
+		}
+		if paths, err = keystore.DiscoverMnemonicPaths(mnemonic, passphrase, template, keystore.DefaultDiscoveryGap, used); err != nil {
+			return nil, err
+		}
+	}
+	accs, err := ks.ImportMnemonic(mnemonic, passphrase, paths, password)
+	addresses := make([]common.Address, len(accs))
+	for i, acc := range accs {
+		addresses[i] = acc.Address
+	}
+	return addresses, err
+}
+
 // UnlockAccount will unlock the account associated with the given address with
 // the given password for duration seconds. If duration is nil it will use a
-// default of 300 seconds. It returns an indication if the account was unlocked.
-func (api *PersonalAccountAPI) UnlockAccount(ctx context.Context, addr common.Address, password string, duration *uint64) (bool, error) {
+// default of 300 seconds, indefinite unlocks are not allowed. If a scope is
+// given, the unlocked key may only sign the requests within the scope. It
+// returns an indication if the account was unlocked.
+func (api *PersonalAccountAPI) UnlockAccount(ctx context.Context, addr common.Address, password string, duration *uint64, scope *keystore.UnlockScope) (bool, error) {
 	// When the API is exposed by external RPC(http, ws etc), unless the user
 	// explicitly specifies to allow the insecure account unlocking, otherwise
 	// it is disabled.
@@ -435,6 +487,8 @@ func (api *PersonalAccountAPI) UnlockAccount(ctx context.Context, addr common.Ad
 	var d time.Duration
 	if duration == nil {
 		d = 300 * time.Second
+	} else if *duration == 0 {
+		return false, errors.New("indefinite unlock not allowed, give a duration")
 	} else if *duration > max {
 		return false, errors.New("unlock duration too large")
 	} else {
@@ -444,13 +498,23 @@ func (api *PersonalAccountAPI) UnlockAccount(ctx context.Context, addr common.Ad
 	if err != nil {
 		return false, err
 	}
-	err = ks.TimedUnlock(accounts.Account{Address: addr}, password, d)
+	err = ks.ScopedUnlock(accounts.Account{Address: addr}, password, d, scope)
 	if err != nil {
 		log.Warn("Failed account unlock attempt", "address", addr, "err", err)
 	}
 	return err == nil, err
 }
 
+// ListUnlocks returns the accounts unlocked in the keystore, with the expiry
+// and the scope of their unlock.
+func (api *PersonalAccountAPI) ListUnlocks() ([]keystore.UnlockSession, error) {
+	ks, err := fetchKeystore(api.am)
+	if err != nil {
+		return nil, err
+	}
+	return ks.UnlockSessions(), nil
+}
+
 // LockAccount will lock the account associated with the given address when it's unlocked.
 func (api *PersonalAccountAPI) LockAccount(addr common.Address) bool {
 	if ks, err := fetchKeystore(api.am); err == nil {
@@ -476,7 +540,7 @@ func (api *PersonalAccountAPI) signTransaction(ctx context.Context, args *Transa
 	// Assemble the transaction and sign with the wallet
 	tx := args.ToTransaction()
 
-	return wallet.SignTxWithPassphrase(account, passwd, tx, api.b.ChainConfig().ChainID)
+	return signTx(ctx, api.b, wallet, account, tx, &passwd)
 }
 
 // SendTransaction will create a transaction from the given arguments and
@@ -508,19 +572,19 @@ func (api *PersonalAccountAPI) SignTransaction(ctx context.Context, args Transac
 	// No need to obtain the noncelock mutex, since we won't be sending this
 	// tx into the transaction pool, but right back to the user
 	if args.From == nil {
-		return nil, errors.New("sender not specified")
+		return nil, &invalidParamsError{"sender not specified"}
 	}
 	if args.Gas == nil {
-		return nil, errors.New("gas not specified")
+		return nil, &invalidParamsError{"gas not specified"}
 	}
 	if args.GasPrice == nil && (args.MaxFeePerGas == nil || args.MaxPriorityFeePerGas == nil) {
-		return nil, errors.New("missing gasPrice or maxFeePerGas/maxPriorityFeePerGas")
+		return nil, &invalidParamsError{"missing gasPrice or maxFeePerGas/maxPriorityFeePerGas"}
 	}
 	if args.IsEIP4844() {
 		return nil, errBlobTxNotSupported
 	}
 	if args.Nonce == nil {
-		return nil, errors.New("nonce not specified")
+		return nil, &invalidParamsError{"nonce not specified"}
 	}
 	// Before actually signing the transaction, ensure the transaction fee is reasonable.
 	tx := args.ToTransaction()
@@ -558,6 +622,7 @@ func (api *PersonalAccountAPI) Sign(ctx context.Context, data hexutil.Bytes, add
 	}
 	// Assemble sign the data with the wallet
 	signature, err := wallet.SignTextWithPassphrase(account, passwd, data)
+	auditSign(ctx, api.b, addr, audit.KindText, common.BytesToHash(accounts.TextHash(data)), err)
 	if err != nil {
 		log.Warn("Failed data sign attempt", "address", addr, "err", err)
 		return nil, err
@@ -578,10 +643,10 @@ func (api *PersonalAccountAPI) Sign(ctx context.Context, data hexutil.Bytes, add
 // https://geth.ethereum.org/docs/interacting-with-geth/rpc/ns-personal#personal-ecrecover
 func (api *PersonalAccountAPI) EcRecover(ctx context.Context, data, sig hexutil.Bytes) (common.Address, error) {
 	if len(sig) != crypto.SignatureLength {
-		return common.Address{}, fmt.Errorf("signature must be %d bytes long", crypto.SignatureLength)
+		return common.Address{}, &invalidParamsError{fmt.Sprintf("signature must be %d bytes long", crypto.SignatureLength)}
 	}
 	if sig[crypto.RecoveryIDOffset] != 27 && sig[crypto.RecoveryIDOffset] != 28 {
-		return common.Address{}, errors.New("invalid Ethereum signature (V is not 27 or 28)")
+		return common.Address{}, &invalidParamsError{"invalid Ethereum signature (V is not 27 or 28)"}
 	}
 	sig[crypto.RecoveryIDOffset] -= 27 // Transform yellow paper V from 27/28 to 0/1
 
@@ -634,6 +699,55 @@ func (api *PersonalAccountAPI) Unpair(ctx context.Context, url string, pin strin
 	}
 }
 
+// AccountAPI provides access to the accounts managed by this node, superseding
+// the signing methods of the deprecated personal namespace. Besides IPC, it is
+// served on the authenticated endpoint by default, where the scope of the access
+// token may restrict the client to a subset of its methods, e.g.
+// "account_listAccounts". Other HTTP and WebSocket endpoints serve it only if it
+// is listed in their modules.
+type AccountAPI struct {
+	personal *PersonalAccountAPI
+}
+
+// NewAccountAPI creates a new AccountAPI.
+func NewAccountAPI(b Backend, nonceLock *AddrLocker) *AccountAPI {
+	return &AccountAPI{personal: NewPersonalAccountAPI(b, nonceLock)}
+}
+
+// ListAccounts returns the addresses of the accounts this node manages.
+func (api *AccountAPI) ListAccounts() []common.Address {
+	return api.personal.ListAccounts()
+}
+
+// ListWallets returns the wallets this node manages, with their accounts.
+func (api *AccountAPI) ListWallets() []rawWallet {
+	return api.personal.ListWallets()
+}
+
+// Sign calculates an EIP-191 signature of the data with the key of the given
+// account, which is decrypted with the given password.
+func (api *AccountAPI) Sign(ctx context.Context, data hexutil.Bytes, addr common.Address, passwd string) (hexutil.Bytes, error) {
+	return api.personal.Sign(ctx, data, addr, passwd)
+}
+
+// SignTransaction signs a fully specified transaction with the key of its sender,
+// which is decrypted with the given password. The transaction is not submitted.
+func (api *AccountAPI) SignTransaction(ctx context.Context, args TransactionArgs, passwd string) (*SignTransactionResult, error) {
+	return api.personal.SignTransaction(ctx, args, passwd)
+}
+
+// SendTransaction fills in the defaults of a transaction, signs it with the key
+// of its sender, which is decrypted with the given password, and submits it.
+func (api *AccountAPI) SendTransaction(ctx context.Context, args TransactionArgs, passwd string) (common.Hash, error) {
+	return api.personal.SendTransaction(ctx, args, passwd)
+}
+
+// EcRecover returns the address of the account which created an EIP-191 signature
+// of the data, see Sign.
+func (api *AccountAPI) EcRecover(ctx context.Context, data, sig hexutil.Bytes) (common.Address, error) {
+	return api.personal.EcRecover(ctx, data, sig)
+}
+
 // BlockChainAPI provides an API to access Ethereum blockchain data.
 type BlockChainAPI struct {
 	b Backend
@@ -717,10 +831,14 @@ func (api *BlockChainAPI) GetProof(ctx context.Context, address common.Address,
 			return nil, err
 		}
 	}
-	statedb, header, err := api.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	// Proofs may be requested for arbitrary historical blocks, so regenerate the
+	// state if it has been pruned already.
+	statedb, header, release, err := api.b.HistoricalStateAndHeader(ctx, blockNrOrHash)
 	if statedb == nil || err != nil {
 		return nil, err
 	}
+	defer release()
+
 	codeHash := statedb.GetCodeHash(address)
 	storageRoot := statedb.GetStorageRoot(address)
 
@@ -779,6 +897,44 @@ func (api *BlockChainAPI) GetProof(ctx context.Context, address common.Address,
 	}, statedb.Error()
 }
 
+// AccountInfo is the state of an account as returned by eth_getAccount.
+type AccountInfo struct {
+	Balance      *hexutil.Big   `json:"balance"`
+	Nonce        hexutil.Uint64 `json:"nonce"`
+	CodeHash     common.Hash    `json:"codeHash"`
+	StorageHash  common.Hash    `json:"storageHash"`
+	AccountProof []string       `json:"accountProof,omitempty"`
+}
+
+// GetAccount returns the balance, nonce, code hash and storage root of an account
+// in the state of the given block, optionally along with the Merkle-proof of the
+// account.
+func (api *BlockChainAPI) GetAccount(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash, withProof *bool) (*AccountInfo, error) {
+	if withProof != nil && *withProof {
+		res, err := api.GetProof(ctx, address, nil, blockNrOrHash)
+		if res == nil || err != nil {
+			return nil, err
+		}
+		return &AccountInfo{
+			Balance:      res.Balance,
+			Nonce:        res.Nonce,
+			CodeHash:     res.CodeHash,
+			StorageHash:  res.StorageHash,
+			AccountProof: res.AccountProof,
+		}, nil
+	}
+	state, _, err := api.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	return &AccountInfo{
+		Balance:     (*hexutil.Big)(state.GetBalance(address).ToBig()),
+		Nonce:       hexutil.Uint64(state.GetNonce(address)),
+		CodeHash:    state.GetCodeHash(address),
+		StorageHash: state.GetStorageRoot(address),
+	}, state.Error()
+}
+
 // decodeHash parses a hex-encoded 32-byte hash. The input may optionally
 // be prefixed by 0x and can have a byte length up to 32.
 func decodeHash(s string) (h common.Hash, inputLength int, err error) {
@@ -955,7 +1111,7 @@ func (api *BlockChainAPI) GetBlockReceipts(ctx context.Context, blockNrOrHash rp
 
 	result := make([]map[string]interface{}, len(receipts))
 	for i, receipt := range receipts {
-		result[i] = marshalReceipt(receipt, block.Hash(), block.NumberU64(), signer, txs[i], i)
+		result[i] = marshalReceipt(receipt, block.Hash(), block.NumberU64(), block.BaseFee(), signer, txs[i], i)
 	}
 
 	return result, nil
@@ -1136,10 +1292,12 @@ func doCall(ctx context.Context, b Backend, args TransactionArgs, state *state.S
 
 	// If the timer caused an abort, return an appropriate error message
 	if evm.Cancelled() {
-		return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+		return nil, &apiError{err: fmt.Errorf("execution aborted (timeout = %v)", timeout), code: errCodeLimitExceeded}
 	}
 	if err != nil {
-		return result, fmt.Errorf("err: %w (supplied gas %d)", err, msg.GasLimit)
+		cost := new(big.Int).Mul(new(big.Int).SetUint64(msg.GasLimit), msg.GasPrice)
+		cost.Add(cost, msg.Value)
+		return result, callFailedError(fmt.Errorf("err: %w (supplied gas %d)", err, msg.GasLimit), cost, state.GetBalance(msg.From).ToBig())
 	}
 	return result, nil
 }
@@ -1702,11 +1860,11 @@ func (api *TransactionAPI) GetTransactionReceipt(ctx context.Context, hash commo
 
 	// Derive the sender.
 	signer := types.MakeSigner(api.b.ChainConfig(), header.Number, header.Time)
-	return marshalReceipt(receipt, blockHash, blockNumber, signer, tx, int(index)), nil
+	return marshalReceipt(receipt, blockHash, blockNumber, header.BaseFee, signer, tx, int(index)), nil
 }
 
 // marshalReceipt marshals a transaction receipt into a JSON object.
-func marshalReceipt(receipt *types.Receipt, blockHash common.Hash, blockNumber uint64, signer types.Signer, tx *types.Transaction, txIndex int) map[string]interface{} {
+func marshalReceipt(receipt *types.Receipt, blockHash common.Hash, blockNumber uint64, baseFee *big.Int, signer types.Signer, tx *types.Transaction, txIndex int) map[string]interface{} {
 	from, _ := types.Sender(signer, tx)
 
 	fields := map[string]interface{}{
@@ -1739,6 +1897,14 @@ func marshalReceipt(receipt *types.Receipt, blockHash common.Hash, blockNumber u
 		fields["blobGasUsed"] = hexutil.Uint64(receipt.BlobGasUsed)
 		fields["blobGasPrice"] = (*hexutil.Big)(receipt.BlobGasPrice)
 	}
+	// Break down the execution fee into the burnt base fee and the tip paid to
+	// the fee recipient of the block. The blob fee, blobGasUsed*blobGasPrice, is
+	// burnt as well but not included in baseFeeBurned.
+	if receipt.EffectiveGasPrice != nil {
+		burnt, tip := receiptFees(receipt, baseFee)
+		fields["baseFeeBurned"] = (*hexutil.Big)(burnt)
+		fields["tipPaid"] = (*hexutil.Big)(tip)
+	}
 
 	// If the ContractAddress is 20 0x0 bytes, assume it is not a contract creation
 	if receipt.ContractAddress != (common.Address{}) {
@@ -1747,8 +1913,21 @@ func marshalReceipt(receipt *types.Receipt, blockHash common.Hash, blockNumber u
 	return fields
 }
 
+// receiptFees returns the portion of the execution fee of a transaction which is
+// burnt as base fee, and the portion which is paid to the fee recipient as tip.
+func receiptFees(receipt *types.Receipt, baseFee *big.Int) (burnt, tip *big.Int) {
+	gasUsed := new(big.Int).SetUint64(receipt.GasUsed)
+	burnt = new(big.Int)
+	if baseFee != nil {
+		burnt.Mul(gasUsed, baseFee)
+	}
+	tip = new(big.Int).Mul(gasUsed, receipt.EffectiveGasPrice)
+	tip.Sub(tip, burnt)
+	return burnt, tip
+}
+
 // sign is a helper function that signs a transaction with the private key of the given address.
-func (api *TransactionAPI) sign(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
+func (api *TransactionAPI) sign(ctx context.Context, addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
 	// Look up the wallet containing the requested signer
 	account := accounts.Account{Address: addr}
 
@@ -1757,7 +1936,51 @@ func (api *TransactionAPI) sign(addr common.Address, tx *types.Transaction) (*ty
 		return nil, err
 	}
 	// Request the wallet to sign the transaction
-	return wallet.SignTx(account, tx, api.b.ChainConfig().ChainID)
+	return signTx(ctx, api.b, wallet, account, tx, nil)
+}
+
+// signTx signs a transaction with the wallet, using the passphrase if given. The
+// signed transaction is checked against the policy of the account, and the
+// operation is recorded in the signing audit log.
+func signTx(ctx context.Context, b Backend, wallet accounts.Wallet, account accounts.Account, tx *types.Transaction, passwd *string) (*types.Transaction, error) {
+	var (
+		chainID = b.ChainConfig().ChainID
+		signed  *types.Transaction
+		err     error
+	)
+	if passwd != nil {
+		signed, err = wallet.SignTxWithPassphrase(account, *passwd, tx, chainID)
+	} else {
+		signed, err = wallet.SignTx(account, tx, chainID)
+	}
+	if err == nil {
+		err = checkSignedTx(b, account.Address, signed)
+	}
+	auditSign(ctx, b, account.Address, audit.KindTransaction, types.LatestSignerForChainID(chainID).Hash(tx), err)
+	if err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+// auditSign records a signing operation in the signing audit log, if enabled.
+func auditSign(ctx context.Context, b Backend, account common.Address, kind string, digest common.Hash, err error) {
+	if auditor := b.AccountManager().Config().Auditor; auditor != nil {
+		auditor.RecordSign(ctx, account, kind, digest, err)
+	}
+}
+
+// checkSignedTx ensures a transaction signed by a wallet of the node is for the
+// chain of the node, and replay-protected (EIP-155) if the account requires it.
+func checkSignedTx(b Backend, from common.Address, tx *types.Transaction) error {
+	chainID := b.ChainConfig().ChainID
+	if b.AccountManager().Config().ReplayProtectionRequired(from) {
+		return accounts.CheckReplayProtection(tx, chainID)
+	}
+	if tx.Protected() && tx.ChainId().Cmp(chainID) != 0 {
+		return fmt.Errorf("transaction signed for chain %v, node's is %v", tx.ChainId(), chainID)
+	}
+	return nil
 }
 
 // SubmitTransaction is a helper function that submits tx to txPool and logs a message.
@@ -1767,12 +1990,22 @@ func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (c
 	if err := checkTxFee(tx.GasPrice(), tx.Gas(), b.RPCTxFeeCap()); err != nil {
 		return common.Hash{}, err
 	}
-	if !b.UnprotectedAllowed() && !tx.Protected() {
+	if !tx.Protected() {
 		// Ensure only eip155 signed transactions are submitted if EIP155Required is set.
-		return common.Hash{}, errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
+		if !b.UnprotectedAllowed() {
+			return common.Hash{}, &apiError{err: errors.New("only replay-protected (EIP-155) transactions allowed over RPC"), code: errCodeTransactionRejected}
+		}
+		// Accounts requiring replay protection don't send unprotected transactions either
+		if from, err := types.Sender(types.HomesteadSigner{}, tx); err == nil && b.AccountManager().Config().ReplayProtectionRequired(from) {
+			return common.Hash{}, &apiError{err: fmt.Errorf("%w from %v", accounts.ErrUnprotectedTx, from), code: errCodeTransactionRejected}
+		}
 	}
 	if err := b.SendTx(ctx, tx); err != nil {
-		return common.Hash{}, err
+		var balance *big.Int
+		if errors.Is(err, core.ErrInsufficientFunds) {
+			balance = senderBalance(ctx, b, tx)
+		}
+		return common.Hash{}, txRejectedError(err, tx.Cost(), balance)
 	}
 	// Print a log with full tx details for manual investigations and interventions
 	head := b.CurrentBlock()
@@ -1791,6 +2024,21 @@ func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (c
 	return tx.Hash(), nil
 }
 
+// senderBalance returns the balance of the sender of a transaction at the head of
+// the chain, or nil if it's unavailable.
+func senderBalance(ctx context.Context, b Backend, tx *types.Transaction) *big.Int {
+	head := b.CurrentBlock()
+	from, err := types.Sender(types.MakeSigner(b.ChainConfig(), head.Number, head.Time), tx)
+	if err != nil {
+		return nil
+	}
+	state, _, err := b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if state == nil || err != nil {
+		return nil
+	}
+	return state.GetBalance(from).ToBig()
+}
+
 // SendTransaction creates a transaction for the given argument, sign it and submit it to the
 // transaction pool.
 func (api *TransactionAPI) SendTransaction(ctx context.Context, args TransactionArgs) (common.Hash, error) {
@@ -1819,7 +2067,7 @@ func (api *TransactionAPI) SendTransaction(ctx context.Context, args Transaction
 	// Assemble the transaction and sign with the wallet
 	tx := args.ToTransaction()
 
-	signed, err := wallet.SignTx(account, tx, api.b.ChainConfig().ChainID)
+	signed, err := signTx(ctx, api.b, wallet, account, tx, nil)
 	if err != nil {
 		return common.Hash{}, err
 	}
@@ -1855,6 +2103,41 @@ func (api *TransactionAPI) SendRawTransaction(ctx context.Context, input hexutil
 	return SubmitTransaction(ctx, api.b, tx)
 }
 
+// maxConditionalCost is the maximum number of storage lookups the conditions of
+// a single eth_sendRawTransactionConditional request may require.
+const maxConditionalCost = 1000
+
+// SendRawTransactionConditional will add the signed transaction to the transaction
+// pool, to be included only in a block satisfying the given conditions. The
+// conditions are checked against the current head upon submission, rechecked
+// by the miner before inclusion, and the transaction is dropped from the pool
+// once they can no longer be met. Conditional transactions are not propagated
+// to other peers.
+func (api *TransactionAPI) SendRawTransactionConditional(ctx context.Context, input hexutil.Bytes, cond types.TransactionConditional) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	if err := cond.Validate(); err != nil {
+		return common.Hash{}, err
+	}
+	if cost := cond.Cost(); cost > maxConditionalCost {
+		return common.Hash{}, fmt.Errorf("conditions too expensive: cost %d exceeds limit %d", cost, maxConditionalCost)
+	}
+	state, head, err := api.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if state == nil || err != nil {
+		return common.Hash{}, err
+	}
+	if cond.Expired(new(big.Int).Add(head.Number, common.Big1), head.Time) {
+		return common.Hash{}, fmt.Errorf("conditions expired at block %d", head.Number)
+	}
+	if err := state.CheckTransactionConditional(&cond); err != nil {
+		return common.Hash{}, err
+	}
+	tx.SetConditional(&cond)
+	return SubmitTransaction(ctx, api.b, tx)
+}
+
 // Sign calculates an ECDSA signature for:
 // keccak256("\x19Ethereum Signed Message:\n" + len(message) + message).
 //
@@ -1864,7 +2147,7 @@ func (api *TransactionAPI) SendRawTransaction(ctx context.Context, input hexutil
 // The account associated with addr must be unlocked.
 //
 // https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_sign
-func (api *TransactionAPI) Sign(addr common.Address, data hexutil.Bytes) (hexutil.Bytes, error) {
+func (api *TransactionAPI) Sign(ctx context.Context, addr common.Address, data hexutil.Bytes) (hexutil.Bytes, error) {
 	// Look up the wallet containing the requested signer
 	account := accounts.Account{Address: addr}
 
@@ -1874,6 +2157,7 @@ func (api *TransactionAPI) Sign(addr common.Address, data hexutil.Bytes) (hexuti
 	}
 	// Sign the requested hash with the wallet
 	signature, err := wallet.SignText(account, data)
+	auditSign(ctx, api.b, addr, audit.KindText, common.BytesToHash(accounts.TextHash(data)), err)
 	if err == nil {
 		signature[64] += 27 // Transform V from 0/1 to 27/28 according to the yellow paper
 	}
@@ -1893,13 +2177,13 @@ func (api *TransactionAPI) SignTransaction(ctx context.Context, args Transaction
 	args.blobSidecarAllowed = true
 
 	if args.Gas == nil {
-		return nil, errors.New("gas not specified")
+		return nil, &invalidParamsError{"gas not specified"}
 	}
 	if args.GasPrice == nil && (args.MaxPriorityFeePerGas == nil || args.MaxFeePerGas == nil) {
-		return nil, errors.New("missing gasPrice or maxFeePerGas/maxPriorityFeePerGas")
+		return nil, &invalidParamsError{"missing gasPrice or maxFeePerGas/maxPriorityFeePerGas"}
 	}
 	if args.Nonce == nil {
-		return nil, errors.New("nonce not specified")
+		return nil, &invalidParamsError{"nonce not specified"}
 	}
 	if err := args.setDefaults(ctx, api.b, false); err != nil {
 		return nil, err
@@ -1909,7 +2193,7 @@ func (api *TransactionAPI) SignTransaction(ctx context.Context, args Transaction
 	if err := checkTxFee(tx.GasPrice(), tx.Gas(), api.b.RPCTxFeeCap()); err != nil {
 		return nil, err
 	}
-	signed, err := api.sign(args.from(), tx)
+	signed, err := api.sign(ctx, args.from(), tx)
 	if err != nil {
 		return nil, err
 	}
@@ -1958,7 +2242,7 @@ func (api *TransactionAPI) PendingTransactions() ([]*RPCTransaction, error) {
 // the given transaction from the pool and reinsert it with the new gas price and limit.
 func (api *TransactionAPI) Resend(ctx context.Context, sendArgs TransactionArgs, gasPrice *hexutil.Big, gasLimit *hexutil.Uint64) (common.Hash, error) {
 	if sendArgs.Nonce == nil {
-		return common.Hash{}, errors.New("missing transaction nonce in transaction spec")
+		return common.Hash{}, &invalidParamsError{"missing transaction nonce in transaction spec"}
 	}
 	if err := sendArgs.setDefaults(ctx, api.b, false); err != nil {
 		return common.Hash{}, err
@@ -1993,7 +2277,7 @@ func (api *TransactionAPI) Resend(ctx context.Context, sendArgs TransactionArgs,
 			if gasLimit != nil && *gasLimit != 0 {
 				sendArgs.Gas = gasLimit
 			}
-			signedTx, err := api.sign(sendArgs.from(), sendArgs.ToTransaction())
+			signedTx, err := api.sign(ctx, sendArgs.from(), sendArgs.ToTransaction())
 			if err != nil {
 				return common.Hash{}, err
 			}
@@ -2003,7 +2287,7 @@ func (api *TransactionAPI) Resend(ctx context.Context, sendArgs TransactionArgs,
 			return signedTx.Hash(), nil
 		}
 	}
-	return common.Hash{}, fmt.Errorf("transaction %#x not found", matchTx.Hash())
+	return common.Hash{}, newNotFoundError("transaction %#x not found", matchTx.Hash())
 }
 
 // DebugAPI is the collection of Ethereum APIs exposed over the debugging
@@ -2017,55 +2301,52 @@ func NewDebugAPI(b Backend) *DebugAPI {
 	return &DebugAPI{b: b}
 }
 
+// blockHash resolves the hash of the block referenced by blockNrOrHash.
+func (api *DebugAPI) blockHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (common.Hash, error) {
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		return hash, nil
+	}
+	header, err := api.b.HeaderByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if header == nil {
+		return common.Hash{}, newNotFoundError("block not found")
+	}
+	return header.Hash(), nil
+}
+
 // GetRawHeader retrieves the RLP encoding for a single header.
 func (api *DebugAPI) GetRawHeader(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
-	var hash common.Hash
-	if h, ok := blockNrOrHash.Hash(); ok {
-		hash = h
-	} else {
-		block, err := api.b.BlockByNumberOrHash(ctx, blockNrOrHash)
-		if err != nil {
-			return nil, err
-		}
-		hash = block.Hash()
+	hash, err := api.blockHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
 	}
 	header, _ := api.b.HeaderByHash(ctx, hash)
 	if header == nil {
-		return nil, fmt.Errorf("header #%d not found", hash)
+		return nil, newNotFoundError("header %#x not found", hash)
 	}
 	return rlp.EncodeToBytes(header)
 }
 
 // GetRawBlock retrieves the RLP encoded for a single block.
 func (api *DebugAPI) GetRawBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
-	var hash common.Hash
-	if h, ok := blockNrOrHash.Hash(); ok {
-		hash = h
-	} else {
-		block, err := api.b.BlockByNumberOrHash(ctx, blockNrOrHash)
-		if err != nil {
-			return nil, err
-		}
-		hash = block.Hash()
+	hash, err := api.blockHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
 	}
 	block, _ := api.b.BlockByHash(ctx, hash)
 	if block == nil {
-		return nil, fmt.Errorf("block #%d not found", hash)
+		return nil, newNotFoundError("block %#x not found", hash)
 	}
 	return rlp.EncodeToBytes(block)
 }
 
 // GetRawReceipts retrieves the binary-encoded receipts of a single block.
 func (api *DebugAPI) GetRawReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]hexutil.Bytes, error) {
-	var hash common.Hash
-	if h, ok := blockNrOrHash.Hash(); ok {
-		hash = h
-	} else {
-		block, err := api.b.BlockByNumberOrHash(ctx, blockNrOrHash)
-		if err != nil {
-			return nil, err
-		}
-		hash = block.Hash()
+	hash, err := api.blockHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
 	}
 	receipts, err := api.b.GetReceipts(ctx, hash)
 	if err != nil {
@@ -2102,7 +2383,7 @@ func (api *DebugAPI) GetRawTransaction(ctx context.Context, hash common.Hash) (h
 func (api *DebugAPI) PrintBlock(ctx context.Context, number uint64) (string, error) {
 	block, _ := api.b.BlockByNumber(ctx, rpc.BlockNumber(number))
 	if block == nil {
-		return "", fmt.Errorf("block #%d not found", number)
+		return "", newNotFoundError("block #%d not found", number)
 	}
 	return spew.Sdump(block), nil
 }
@@ -2133,6 +2414,23 @@ func (api *DebugAPI) ChaindbCompact() error {
 	return nil
 }
 
+// ChaindbCompactRange compacts the key range [start, end) of the key-value
+// database. A nil start is treated as a key before all keys in the database,
+// a nil end as a key after all keys.
+func (api *DebugAPI) ChaindbCompactRange(start, end hexutil.Bytes) error {
+	if start != nil && end != nil && bytes.Compare(start, end) >= 0 {
+		return fmt.Errorf("invalid compaction range %#x-%#x", start, end)
+	}
+	cstart := time.Now()
+	log.Info("Compacting database", "range", fmt.Sprintf("%#X-%#X", []byte(start), []byte(end)))
+	if err := api.b.ChainDb().Compact(start, end); err != nil {
+		log.Error("Database compaction failed", "err", err)
+		return err
+	}
+	log.Info("Compacted database", "range", fmt.Sprintf("%#X-%#X", []byte(start), []byte(end)), "elapsed", common.PrettyDuration(time.Since(cstart)))
+	return nil
+}
+
 // SetHead rewinds the head of the blockchain to a previous block.
 func (api *DebugAPI) SetHead(number hexutil.Uint64) {
 	api.b.SetHead(uint64(number))
@@ -2171,10 +2469,11 @@ func checkTxFee(gasPrice *big.Int, gas uint64, cap float64) error {
 	if cap == 0 {
 		return nil
 	}
-	feeEth := new(big.Float).Quo(new(big.Float).SetInt(new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gas))), new(big.Float).SetInt(big.NewInt(params.Ether)))
+	fee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gas))
+	feeEth := new(big.Float).Quo(new(big.Float).SetInt(fee), new(big.Float).SetInt(big.NewInt(params.Ether)))
 	feeFloat, _ := feeEth.Float64()
 	if feeFloat > cap {
-		return fmt.Errorf("tx fee (%.2f ether) exceeds the configured cap (%.2f ether)", feeFloat, cap)
+		return newFeeCapError(fee, feeFloat, cap)
 	}
 	return nil
 }
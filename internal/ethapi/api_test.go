@@ -55,6 +55,7 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/internal/blocktest"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -549,6 +550,10 @@ func (b testBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOr
 	}
 	panic("only implemented for number")
 }
+func (b testBackend) HistoricalStateAndHeader(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, func(), error) {
+	statedb, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	return statedb, header, func() {}, err
+}
 func (b testBackend) Pending() (*types.Block, types.Receipts, *state.StateDB) { panic("implement me") }
 func (b testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
 	header, err := b.HeaderByHash(ctx, hash)
@@ -614,6 +619,9 @@ func (b testBackend) GetLogs(ctx context.Context, blockHash common.Hash, number
 func (b testBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
 	panic("implement me")
 }
+func (b testBackend) SubscribeChainFinalityEvent(ch chan<- core.ChainFinalityEvent) event.Subscription {
+	panic("implement me")
+}
 func (b testBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	panic("implement me")
 }
@@ -975,6 +983,125 @@ func TestCall(t *testing.T) {
 	}
 }
 
+func TestSimulateV1(t *testing.T) {
+	t.Parallel()
+	var (
+		accounts = newAccounts(2)
+		genesis  = &core.Genesis{
+			Config: params.MergedTestChainConfig,
+			Alloc: types.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+		genBlocks = 10
+		// Logs and returns the block number.
+		emitter  = common.Address{0xe1}
+		reverter = common.Address{0xe2}
+		value    = (*hexutil.Big)(big.NewInt(1000))
+	)
+	api := NewBlockChainAPI(newTestBackend(t, genBlocks, genesis, beacon.New(ethash.NewFaker()), func(i int, b *core.BlockGen) {
+		b.SetPoS()
+	}))
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	overrides := StateOverride{
+		emitter:  OverrideAccount{Code: hex2Bytes("4360005260206000a060206000f3")},
+		reverter: OverrideAccount{Code: hex2Bytes("60006000fd")},
+	}
+	results, err := api.SimulateV1(context.Background(), simOpts{
+		BlockStateCalls: []simBlock{{
+			StateOverrides: &overrides,
+			Calls: []TransactionArgs{
+				{From: &accounts[0].addr, To: &accounts[1].addr, Value: value},
+				{From: &accounts[0].addr, To: &emitter},
+				{From: &accounts[0].addr, To: &reverter},
+			},
+		}, {
+			BlockOverrides: &BlockOverrides{Number: (*hexutil.Big)(big.NewInt(13))},
+			Calls: []TransactionArgs{
+				{From: &accounts[1].addr, To: &accounts[0].addr, Value: value},
+				{From: &accounts[0].addr, To: &emitter},
+			},
+		}},
+	}, &latest)
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	// The gap between the simulated blocks is filled with an empty block.
+	if len(results) != 3 {
+		t.Fatalf("block count mismatch: have %d, want 3", len(results))
+	}
+	for i, result := range results {
+		if number := result["number"].(*hexutil.Big).ToInt().Uint64(); number != uint64(genBlocks+i+1) {
+			t.Errorf("block %d: number mismatch: have %d, want %d", i, number, genBlocks+i+1)
+		}
+		if i == 0 {
+			continue
+		}
+		if result["parentHash"] != results[i-1]["hash"] {
+			t.Errorf("block %d: parent hash mismatch", i)
+		}
+		var (
+			have = uint64(result["timestamp"].(hexutil.Uint64))
+			want = uint64(results[i-1]["timestamp"].(hexutil.Uint64)) + timestampIncrement
+		)
+		if have != want {
+			t.Errorf("block %d: timestamp mismatch: have %d, want %d", i, have, want)
+		}
+	}
+	calls := results[0]["calls"].([]simCallResult)
+	if len(calls) != 3 {
+		t.Fatalf("call count mismatch: have %d, want 3", len(calls))
+	}
+	if calls[0].Status != hexutil.Uint64(types.ReceiptStatusSuccessful) || calls[0].GasUsed != hexutil.Uint64(params.TxGas) {
+		t.Errorf("transfer result mismatch: %+v", calls[0])
+	}
+	if have := new(big.Int).SetBytes(calls[1].ReturnValue).Uint64(); have != uint64(genBlocks+1) {
+		t.Errorf("return value mismatch: have %d, want %d", have, genBlocks+1)
+	}
+	if len(calls[1].Logs) != 1 || calls[1].Logs[0].BlockHash != results[0]["hash"] || calls[1].Logs[0].TxIndex != 1 {
+		t.Errorf("log mismatch: %+v", calls[1].Logs)
+	}
+	if calls[2].Status != hexutil.Uint64(types.ReceiptStatusFailed) || calls[2].Error == nil || calls[2].Error.Code != errCodeReverted {
+		t.Errorf("revert result mismatch: %+v", calls[2])
+	}
+	// State and overrides are carried over to the following blocks.
+	calls = results[2]["calls"].([]simCallResult)
+	if calls[0].Error != nil {
+		t.Errorf("transfer of simulated balance failed: %+v", calls[0].Error)
+	}
+	if have := new(big.Int).SetBytes(calls[1].ReturnValue).Uint64(); have != uint64(genBlocks+3) {
+		t.Errorf("return value mismatch: have %d, want %d", have, genBlocks+3)
+	}
+
+	// With validation enabled, calls are rejected like invalid transactions.
+	var (
+		nonce  = hexutil.Uint64(0)
+		gas    = hexutil.Uint64(params.TxGas)
+		feeCap = (*hexutil.Big)(big.NewInt(params.GWei))
+	)
+	_, err = api.SimulateV1(context.Background(), simOpts{
+		Validation: true,
+		BlockStateCalls: []simBlock{{
+			Calls: []TransactionArgs{
+				{From: &accounts[0].addr, To: &accounts[1].addr, Value: value, Gas: &gas, MaxFeePerGas: feeCap},
+				{From: &accounts[0].addr, To: &accounts[1].addr, Value: value, Gas: &gas, MaxFeePerGas: feeCap, Nonce: &nonce},
+			},
+		}},
+	}, &latest)
+	if txErr, ok := err.(*invalidTxError); !ok || txErr.Code != errCodeNonceTooLow {
+		t.Errorf("nonce error mismatch: have %v, want code %d", err, errCodeNonceTooLow)
+	}
+	// Block numbers must increase.
+	_, err = api.SimulateV1(context.Background(), simOpts{
+		BlockStateCalls: []simBlock{{
+			BlockOverrides: &BlockOverrides{Number: (*hexutil.Big)(big.NewInt(int64(genBlocks)))},
+		}},
+	}, &latest)
+	if _, ok := err.(*invalidBlockNumberError); !ok {
+		t.Errorf("block number error mismatch: have %v", err)
+	}
+}
+
 func TestSignTransaction(t *testing.T) {
 	t.Parallel()
 	// Initialize test accounts
@@ -1013,6 +1140,45 @@ func TestSignTransaction(t *testing.T) {
 	}
 }
 
+func TestCheckSignedTx(t *testing.T) {
+	t.Parallel()
+	var (
+		key, _  = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		from    = crypto.PubkeyToAddress(key.PublicKey)
+		genesis = &core.Genesis{
+			Config: params.MergedTestChainConfig,
+			Alloc:  types.GenesisAlloc{},
+		}
+		b      = newTestBackend(t, 0, genesis, beacon.New(ethash.NewFaker()), nil)
+		config = b.AccountManager().Config()
+		tx     = types.NewTx(&types.LegacyTx{Gas: 21000, GasPrice: big.NewInt(1)})
+	)
+	unprotected, _ := types.SignTx(tx, types.HomesteadSigner{}, key)
+	protected, _ := types.SignTx(tx, types.NewEIP155Signer(genesis.Config.ChainID), key)
+	otherChain, _ := types.SignTx(tx, types.NewEIP155Signer(big.NewInt(5)), key)
+
+	if err := checkSignedTx(b, from, unprotected); err != nil {
+		t.Fatalf("unprotected transaction rejected without policy: %v", err)
+	}
+	if err := checkSignedTx(b, from, otherChain); err == nil {
+		t.Fatal("transaction of another chain accepted")
+	}
+	config.ReplayProtected = []common.Address{from}
+	if err := checkSignedTx(b, from, unprotected); !errors.Is(err, accounts.ErrUnprotectedTx) {
+		t.Fatalf("wrong error for unprotected transaction: %v", err)
+	}
+	if err := checkSignedTx(b, from, protected); err != nil {
+		t.Fatalf("protected transaction rejected: %v", err)
+	}
+	if err := checkSignedTx(b, common.Address{1}, unprotected); err != nil {
+		t.Fatalf("policy applied to other account: %v", err)
+	}
+	config.ReplayProtected, config.StrictReplayProtection = nil, true
+	if err := checkSignedTx(b, common.Address{1}, unprotected); !errors.Is(err, accounts.ErrUnprotectedTx) {
+		t.Fatalf("wrong error for unprotected transaction in strict mode: %v", err)
+	}
+}
+
 func TestSignBlobTransaction(t *testing.T) {
 	t.Parallel()
 	// Initialize test accounts
@@ -2053,3 +2219,223 @@ func testRPCResponseWithFile(t *testing.T, testid int, result interface{}, rpc s
 	}
 	require.JSONEqf(t, string(want), string(data), "test %d: json not match, want: %s, have: %s", testid, string(want), string(data))
 }
+
+func TestCachePolicy(t *testing.T) {
+	t.Parallel()
+
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  types.GenesisAlloc{},
+	}
+	backend := newTestBackend(t, 10, genesis, ethash.NewFaker(), func(i int, b *core.BlockGen) {})
+	var (
+		buried   = backend.chain.GetHeaderByNumber(5).Hash()
+		recent   = backend.chain.GetHeaderByNumber(8).Hash()
+		policy   = NewCachePolicy(backend, 4)
+		disabled = NewCachePolicy(backend, 0)
+	)
+	tests := []struct {
+		method string
+		params string
+		want   bool
+	}{
+		{"eth_getBlockByNumber", `["0x6", false]`, true},
+		{"eth_getBlockByNumber", `["0x7", true]`, false},
+		{"eth_getBlockByNumber", `["latest", false]`, false},
+		{"eth_getBlockByNumber", `["finalized", false]`, false},
+		{"eth_getBlockByHash", fmt.Sprintf(`["%s", false]`, buried.Hex()), true},
+		{"eth_getBlockByHash", fmt.Sprintf(`["%s", false]`, recent.Hex()), false},
+		{"eth_getBlockByHash", fmt.Sprintf(`["%s", false]`, common.Hash{1}.Hex()), false},
+		{"eth_getBlockReceipts", `["0x1"]`, true},
+		{"eth_getBlockReceipts", fmt.Sprintf(`[{"blockHash": "%s"}]`, buried.Hex()), true},
+		{"eth_getLogs", `[{"fromBlock": "0x1", "toBlock": "0x5"}]`, true},
+		{"eth_getLogs", `[{"fromBlock": "0x1", "toBlock": "0x9"}]`, false},
+		{"eth_getLogs", `[{"fromBlock": "0x1"}]`, false},
+		{"eth_getLogs", fmt.Sprintf(`[{"blockHash": "%s"}]`, buried.Hex()), true},
+		{"eth_getBalance", `["0x0000000000000000000000000000000000000000", "0x1"]`, false},
+		{"eth_getBlockByNumber", `[]`, false},
+	}
+	for i, tt := range tests {
+		if have := policy.Cacheable(context.Background(), tt.method, json.RawMessage(tt.params)); have != tt.want {
+			t.Errorf("test %d: %s %s cacheable mismatch: have %v, want %v", i, tt.method, tt.params, have, tt.want)
+		}
+		// Without finality nor depth, nothing is considered immutable
+		if disabled.Cacheable(context.Background(), tt.method, json.RawMessage(tt.params)) {
+			t.Errorf("test %d: %s %s cacheable without immutable blocks", i, tt.method, tt.params)
+		}
+	}
+}
+
+func TestDebugGetRawChainData(t *testing.T) {
+	t.Parallel()
+
+	var (
+		genBlocks  = 6
+		backend, _ = setupReceiptBackend(t, genBlocks)
+		api        = NewDebugAPI(backend)
+		ctx        = context.Background()
+	)
+	for i := 0; i <= genBlocks; i++ {
+		block, err := backend.BlockByNumber(ctx, rpc.BlockNumber(i))
+		if err != nil {
+			t.Fatalf("failed to get block %d: %v", i, err)
+		}
+		receipts, err := backend.GetReceipts(ctx, block.Hash())
+		if err != nil {
+			t.Fatalf("failed to get receipts of block %d: %v", i, err)
+		}
+		for _, ref := range []rpc.BlockNumberOrHash{
+			rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(i)),
+			rpc.BlockNumberOrHashWithHash(block.Hash(), false),
+		} {
+			rawHeader, err := api.GetRawHeader(ctx, ref)
+			if err != nil {
+				t.Fatalf("block %d: failed to get raw header: %v", i, err)
+			}
+			var header types.Header
+			if err := rlp.DecodeBytes(rawHeader, &header); err != nil {
+				t.Fatalf("block %d: failed to decode raw header: %v", i, err)
+			}
+			if header.Hash() != block.Hash() {
+				t.Fatalf("block %d: header hash mismatch: have %x, want %x", i, header.Hash(), block.Hash())
+			}
+			rawBlock, err := api.GetRawBlock(ctx, ref)
+			if err != nil {
+				t.Fatalf("block %d: failed to get raw block: %v", i, err)
+			}
+			var decoded types.Block
+			if err := rlp.DecodeBytes(rawBlock, &decoded); err != nil {
+				t.Fatalf("block %d: failed to decode raw block: %v", i, err)
+			}
+			if decoded.Hash() != block.Hash() || decoded.Transactions().Len() != block.Transactions().Len() {
+				t.Fatalf("block %d: decoded block mismatch", i)
+			}
+			rawReceipts, err := api.GetRawReceipts(ctx, ref)
+			if err != nil {
+				t.Fatalf("block %d: failed to get raw receipts: %v", i, err)
+			}
+			if len(rawReceipts) != len(receipts) {
+				t.Fatalf("block %d: have %d receipts, want %d", i, len(rawReceipts), len(receipts))
+			}
+			for j, receipt := range receipts {
+				want, _ := receipt.MarshalBinary()
+				if !bytes.Equal(rawReceipts[j], want) {
+					t.Fatalf("block %d: receipt %d mismatch", i, j)
+				}
+			}
+		}
+	}
+	// Unknown blocks must be reported as errors.
+	missing := rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(genBlocks + 1))
+	if _, err := api.GetRawHeader(ctx, missing); err == nil {
+		t.Fatal("expected error for missing header")
+	}
+	if _, err := api.GetRawBlock(ctx, missing); err == nil {
+		t.Fatal("expected error for missing block")
+	}
+	if _, err := api.GetRawReceipts(ctx, missing); err == nil {
+		t.Fatal("expected error for missing receipts")
+	}
+}
+
+func TestChaindbCompactRange(t *testing.T) {
+	t.Parallel()
+
+	var (
+		backend, _ = setupReceiptBackend(t, 2)
+		api        = NewDebugAPI(backend)
+	)
+	if err := api.ChaindbCompactRange(hexutil.Bytes{0x02}, hexutil.Bytes{0x01}); err == nil {
+		t.Fatal("expected error for inverted compaction range")
+	}
+	if err := api.ChaindbCompactRange(hexutil.Bytes{0x01}, hexutil.Bytes{0x01}); err == nil {
+		t.Fatal("expected error for empty compaction range")
+	}
+	if err := api.ChaindbCompactRange(hexutil.Bytes{0x00}, hexutil.Bytes{0xff}); err != nil {
+		t.Fatalf("failed to compact range: %v", err)
+	}
+	if err := api.ChaindbCompactRange(nil, nil); err != nil {
+		t.Fatalf("failed to compact whole database: %v", err)
+	}
+}
+
+func TestStructuredErrors(t *testing.T) {
+	t.Parallel()
+
+	// Fee cap violations report the fee and the cap in wei.
+	err := checkTxFee(big.NewInt(params.GWei), 2_000_000, 0.001)
+	var feeErr *apiError
+	if !errors.As(err, &feeErr) || feeErr.ErrorCode() != errCodeTransactionRejected {
+		t.Fatalf("unexpected fee cap error: %v", err)
+	}
+	data := feeErr.ErrorData().(*feeCapData)
+	if data.Fee.ToInt().Cmp(big.NewInt(2_000_000*params.GWei)) != 0 || data.Cap.ToInt().Cmp(big.NewInt(params.Ether/1000)) != 0 {
+		t.Fatalf("unexpected fee cap data: fee %v cap %v", data.Fee, data.Cap)
+	}
+
+	// Funding errors report the required and available balance.
+	err = txRejectedError(fmt.Errorf("%w: balance 1", core.ErrInsufficientFunds), big.NewInt(10), big.NewInt(1))
+	var txErr *apiError
+	if !errors.As(err, &txErr) || txErr.ErrorCode() != errCodeInsufficientFunds {
+		t.Fatalf("unexpected rejection error: %v", err)
+	}
+	if !errors.Is(err, core.ErrInsufficientFunds) {
+		t.Fatalf("rejection error does not wrap the original one")
+	}
+	funds := txErr.ErrorData().(*fundsData)
+	if funds.Required.ToInt().Int64() != 10 || funds.Available.ToInt().Int64() != 1 {
+		t.Fatalf("unexpected funds data: required %v available %v", funds.Required, funds.Available)
+	}
+
+	// Missing resources have a dedicated code.
+	var rpcErr rpc.Error
+	if err := newNotFoundError("block %d not found", 1); !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errCodeResourceNotFound {
+		t.Fatalf("unexpected not found error: %v", err)
+	}
+}
+
+func TestGetAccount(t *testing.T) {
+	t.Parallel()
+
+	var (
+		contract = common.Address{0xc0, 0xde}
+		genesis  = &core.Genesis{
+			Config: params.MergedTestChainConfig,
+			Alloc: types.GenesisAlloc{
+				contract: {
+					Balance: big.NewInt(params.Ether),
+					Nonce:   3,
+					Code:    []byte{byte(vm.PUSH1), 0x00, byte(vm.SLOAD)},
+					Storage: map[common.Hash]common.Hash{{0x01}: {0x02}},
+				},
+			},
+		}
+		api    = NewBlockChainAPI(newTestBackend(t, 1, genesis, beacon.New(ethash.NewFaker()), func(i int, b *core.BlockGen) { b.SetPoS() }))
+		latest = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	)
+	proof, err := api.GetProof(context.Background(), contract, nil, latest)
+	if err != nil {
+		t.Fatalf("failed to get proof: %v", err)
+	}
+	for _, withProof := range []bool{false, true} {
+		account, err := api.GetAccount(context.Background(), contract, latest, &withProof)
+		if err != nil {
+			t.Fatalf("failed to get account (proof %t): %v", withProof, err)
+		}
+		want := &AccountInfo{
+			Balance:     (*hexutil.Big)(big.NewInt(params.Ether)),
+			Nonce:       3,
+			CodeHash:    crypto.Keccak256Hash(genesis.Alloc[contract].Code),
+			StorageHash: proof.StorageHash,
+		}
+		if withProof {
+			want.AccountProof = proof.AccountProof
+		}
+		if !reflect.DeepEqual(account, want) {
+			t.Errorf("account mismatch (proof %t): have %+v, want %+v", withProof, account, want)
+		}
+	}
+	if proof.StorageHash == types.EmptyRootHash {
+		t.Error("storage root of contract is empty")
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// blockMethods are the methods whose first parameter references the block their
+// result is derived from.
+var blockMethods = map[string]bool{
+	"eth_getBlockByNumber":                    true,
+	"eth_getBlockByHash":                      true,
+	"eth_getHeaderByNumber":                   true,
+	"eth_getHeaderByHash":                     true,
+	"eth_getBlockReceipts":                    true,
+	"eth_getBlockTransactionCountByNumber":    true,
+	"eth_getBlockTransactionCountByHash":      true,
+	"eth_getTransactionByBlockNumberAndIndex": true,
+	"eth_getTransactionByBlockHashAndIndex":   true,
+	"eth_getUncleByBlockNumberAndIndex":       true,
+	"eth_getUncleByBlockHashAndIndex":         true,
+	"eth_getUncleCountByBlockNumber":          true,
+	"eth_getUncleCountByBlockHash":            true,
+}
+
+// CachePolicy determines which calls of the eth namespace may be answered from
+// the RPC response cache. Calls are cacheable if they reference a block by
+// explicit number or hash which is finalized, or which is buried under at least
+// the configured number of blocks on chains without finality.
+type CachePolicy struct {
+	b     Backend
+	depth uint64
+}
+
+// NewCachePolicy creates the cache policy for the given backend. A zero depth
+// only considers finalized blocks immutable.
+func NewCachePolicy(b Backend, depth uint64) *CachePolicy {
+	return &CachePolicy{b: b, depth: depth}
+}
+
+// Cacheable implements rpc.CachePolicy.
+func (p *CachePolicy) Cacheable(ctx context.Context, method string, params json.RawMessage) bool {
+	switch {
+	case blockMethods[method]:
+		var args []json.RawMessage
+		if err := json.Unmarshal(params, &args); err != nil || len(args) == 0 {
+			return false
+		}
+		var block rpc.BlockNumberOrHash
+		if err := json.Unmarshal(args[0], &block); err != nil {
+			return false
+		}
+		return p.immutable(ctx, block)
+
+	case method == "eth_getLogs":
+		var args []struct {
+			BlockHash *common.Hash     `json:"blockHash"`
+			ToBlock   *rpc.BlockNumber `json:"toBlock"`
+		}
+		if err := json.Unmarshal(params, &args); err != nil || len(args) != 1 {
+			return false
+		}
+		if args[0].BlockHash != nil {
+			return p.immutable(ctx, rpc.BlockNumberOrHashWithHash(*args[0].BlockHash, false))
+		}
+		if args[0].ToBlock != nil {
+			return p.immutable(ctx, rpc.BlockNumberOrHashWithNumber(*args[0].ToBlock))
+		}
+	}
+	return false
+}
+
+// immutable reports whether the referenced block can no longer be reorged. Block
+// tags are never considered immutable, since the block they reference changes.
+func (p *CachePolicy) immutable(ctx context.Context, block rpc.BlockNumberOrHash) bool {
+	bound, ok := p.immutableBound(ctx)
+	if !ok {
+		return false
+	}
+	if number, ok := block.Number(); ok {
+		return number >= 0 && uint64(number) <= bound
+	}
+	if hash, ok := block.Hash(); ok {
+		header, err := p.b.HeaderByHash(ctx, hash)
+		if err != nil || header == nil {
+			return false
+		}
+		return header.Number.Uint64() <= bound
+	}
+	return false
+}
+
+// immutableBound returns the highest block number considered immutable.
+func (p *CachePolicy) immutableBound(ctx context.Context) (uint64, bool) {
+	var (
+		bound uint64
+		ok    bool
+	)
+	if header, err := p.b.HeaderByNumber(ctx, rpc.FinalizedBlockNumber); err == nil && header != nil {
+		bound, ok = header.Number.Uint64(), true
+	}
+	if p.depth > 0 {
+		if head := p.b.CurrentHeader(); head != nil && head.Number.Uint64() >= p.depth {
+			if buried := head.Number.Uint64() - p.depth; !ok || buried > bound {
+				bound, ok = buried, true
+			}
+		}
+	}
+	return bound, ok
+}
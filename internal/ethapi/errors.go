@@ -17,10 +17,14 @@
 package ethapi
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/vm"
 )
 
@@ -43,12 +47,16 @@ func (e *revertError) ErrorData() interface{} {
 }
 
 // newRevertError creates a revertError instance with the provided revert data.
+// Custom errors can't be decoded without the contract ABI, so only their selector
+// is reported, the caller decoding the revert data.
 func newRevertError(revert []byte) *revertError {
 	err := vm.ErrExecutionReverted
 
 	reason, errUnpack := abi.UnpackRevert(revert)
 	if errUnpack == nil {
 		err = fmt.Errorf("%w: %v", vm.ErrExecutionReverted, reason)
+	} else if len(revert) >= 4 {
+		err = fmt.Errorf("%w: custom error %#x", vm.ErrExecutionReverted, revert[:4])
 	}
 	return &revertError{
 		error:  err,
@@ -71,8 +79,187 @@ func (e *TxIndexingError) Error() string {
 // ErrorCode returns the JSON error code for a revert.
 // See: https://github.com/ethereum/wiki/wiki/JSON-RPC-Error-Codes-Improvement-Proposal
 func (e *TxIndexingError) ErrorCode() int {
-	return -32000 // to be decided
+	return errCodeResourceUnavailable
 }
 
 // ErrorData returns the hex encoded revert reason.
 func (e *TxIndexingError) ErrorData() interface{} { return "transaction indexing is in progress" }
+
+// Error codes of the eth_simulateV1 API.
+const (
+	errCodeNonceTooHigh            = -38011
+	errCodeNonceTooLow             = -38010
+	errCodeIntrinsicGas            = -38013
+	errCodeInsufficientFunds       = -38014
+	errCodeBlockGasLimitReached    = -38015
+	errCodeBlockNumberInvalid      = -38020
+	errCodeBlockTimestampInvalid   = -38021
+	errCodeSenderIsNotEOA          = -38024
+	errCodeMaxInitCodeSizeExceeded = -38025
+	errCodeClientLimitExceeded     = -38026
+	errCodeInternalError           = -32603
+	errCodeInvalidParams           = -32602
+	errCodeReverted                = -32000
+	errCodeVMError                 = -32015
+)
+
+// callError is the error of a simulated call, which is reported as part of the
+// call result instead of failing the request.
+type callError struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+	Data    string `json:"data,omitempty"`
+}
+
+// invalidTxError is an API error signaling that a simulated call is invalid as
+// a transaction, e.g. because its nonce or fees do not check out.
+type invalidTxError struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+func (e *invalidTxError) Error() string  { return e.Message }
+func (e *invalidTxError) ErrorCode() int { return e.Code }
+
+// txValidationError maps the transaction validation errors of the state
+// transition to their JSON error codes.
+func txValidationError(err error) *invalidTxError {
+	if err == nil {
+		return nil
+	}
+	switch {
+	case errors.Is(err, core.ErrNonceTooHigh):
+		return &invalidTxError{Message: err.Error(), Code: errCodeNonceTooHigh}
+	case errors.Is(err, core.ErrNonceTooLow):
+		return &invalidTxError{Message: err.Error(), Code: errCodeNonceTooLow}
+	case errors.Is(err, core.ErrSenderNoEOA):
+		return &invalidTxError{Message: err.Error(), Code: errCodeSenderIsNotEOA}
+	case errors.Is(err, core.ErrFeeCapVeryHigh), errors.Is(err, core.ErrTipVeryHigh),
+		errors.Is(err, core.ErrTipAboveFeeCap), errors.Is(err, core.ErrFeeCapTooLow):
+		return &invalidTxError{Message: err.Error(), Code: errCodeInvalidParams}
+	case errors.Is(err, core.ErrInsufficientFunds), errors.Is(err, core.ErrInsufficientFundsForTransfer):
+		return &invalidTxError{Message: err.Error(), Code: errCodeInsufficientFunds}
+	case errors.Is(err, core.ErrIntrinsicGas):
+		return &invalidTxError{Message: err.Error(), Code: errCodeIntrinsicGas}
+	case errors.Is(err, core.ErrMaxInitCodeSizeExceeded):
+		return &invalidTxError{Message: err.Error(), Code: errCodeMaxInitCodeSizeExceeded}
+	case errors.Is(err, core.ErrGasLimitReached):
+		return &invalidTxError{Message: err.Error(), Code: errCodeBlockGasLimitReached}
+	}
+	return &invalidTxError{Message: err.Error(), Code: errCodeInternalError}
+}
+
+type invalidParamsError struct{ message string }
+
+func (e *invalidParamsError) Error() string  { return e.message }
+func (e *invalidParamsError) ErrorCode() int { return errCodeInvalidParams }
+
+type clientLimitExceededError struct{ message string }
+
+func (e *clientLimitExceededError) Error() string  { return e.message }
+func (e *clientLimitExceededError) ErrorCode() int { return errCodeClientLimitExceeded }
+
+type invalidBlockNumberError struct{ message string }
+
+func (e *invalidBlockNumberError) Error() string  { return e.message }
+func (e *invalidBlockNumberError) ErrorCode() int { return errCodeBlockNumberInvalid }
+
+type invalidBlockTimestampError struct{ message string }
+
+func (e *invalidBlockTimestampError) Error() string  { return e.message }
+func (e *invalidBlockTimestampError) ErrorCode() int { return errCodeBlockTimestampInvalid }
+
+type blockGasLimitReachedError struct{ message string }
+
+func (e *blockGasLimitReachedError) Error() string  { return e.message }
+func (e *blockGasLimitReachedError) ErrorCode() int { return errCodeBlockGasLimitReached }
+
+// Error codes of EIP-1474.
+const (
+	errCodeInvalidInput        = -32000
+	errCodeResourceNotFound    = -32001
+	errCodeResourceUnavailable = -32002
+	errCodeTransactionRejected = -32003
+	errCodeLimitExceeded       = -32005
+)
+
+// apiError is an API error with a distinct JSON error code and optional data
+// describing it, so clients need not parse the message. It wraps the original
+// error, which remains accessible through errors.Is and errors.As.
+type apiError struct {
+	err  error
+	code int
+	data interface{}
+}
+
+func (e *apiError) Error() string          { return e.err.Error() }
+func (e *apiError) Unwrap() error          { return e.err }
+func (e *apiError) ErrorCode() int         { return e.code }
+func (e *apiError) ErrorData() interface{} { return e.data }
+
+// newNotFoundError creates an error reporting a missing block, header or
+// transaction.
+func newNotFoundError(format string, args ...interface{}) error {
+	return &apiError{err: fmt.Errorf(format, args...), code: errCodeResourceNotFound}
+}
+
+// fundsData is the error data of transactions or calls exceeding the balance of
+// their sender.
+type fundsData struct {
+	Required  *hexutil.Big `json:"required"`
+	Available *hexutil.Big `json:"available"`
+}
+
+// feeCapData is the error data of transactions exceeding the fee cap of the node.
+type feeCapData struct {
+	Fee *hexutil.Big `json:"fee"`
+	Cap *hexutil.Big `json:"cap"`
+}
+
+// newFeeCapError creates an error rejecting a transaction whose fee exceeds the
+// configured cap. Both are reported in wei.
+func newFeeCapError(fee *big.Int, feeEth float64, cap float64) error {
+	capWei, _ := new(big.Float).Mul(big.NewFloat(cap), big.NewFloat(1e18)).Int(nil)
+	return &apiError{
+		err:  fmt.Errorf("tx fee (%.2f ether) exceeds the configured cap (%.2f ether)", feeEth, cap),
+		code: errCodeTransactionRejected,
+		data: &feeCapData{Fee: (*hexutil.Big)(fee), Cap: (*hexutil.Big)(capWei)},
+	}
+}
+
+// txRejectedError maps an error rejecting a submitted transaction to its JSON
+// error code. Validation errors share the codes of eth_simulateV1, pool policy
+// errors are reported as rejected transactions. The balance of the sender is
+// attached to funding errors, if known.
+func txRejectedError(err error, cost *big.Int, balance *big.Int) error {
+	var data interface{}
+	if errors.Is(err, core.ErrInsufficientFunds) && balance != nil {
+		data = &fundsData{Required: (*hexutil.Big)(cost), Available: (*hexutil.Big)(balance)}
+	}
+	switch {
+	case errors.Is(err, txpool.ErrAlreadyKnown), errors.Is(err, txpool.ErrUnderpriced),
+		errors.Is(err, txpool.ErrReplaceUnderpriced), errors.Is(err, txpool.ErrAccountLimitExceeded),
+		errors.Is(err, txpool.ErrFutureReplacePending), errors.Is(err, txpool.ErrAlreadyReserved),
+		errors.Is(err, txpool.ErrOversizedData), errors.Is(err, txpool.ErrGasLimit),
+		errors.Is(err, txpool.ErrInvalidSender), errors.Is(err, txpool.ErrNegativeValue):
+		return &apiError{err: err, code: errCodeTransactionRejected}
+	}
+	if code := txValidationError(err).Code; code != errCodeInternalError {
+		return &apiError{err: err, code: code, data: data}
+	}
+	return &apiError{err: err, code: errCodeTransactionRejected, data: data}
+}
+
+// callFailedError maps an error aborting the execution of a call to its JSON
+// error code. The balance of the sender is attached to funding errors.
+func callFailedError(err error, cost *big.Int, balance *big.Int) error {
+	var data interface{}
+	if errors.Is(err, core.ErrInsufficientFunds) || errors.Is(err, core.ErrInsufficientFundsForTransfer) {
+		data = &fundsData{Required: (*hexutil.Big)(cost), Available: (*hexutil.Big)(balance)}
+	}
+	code := txValidationError(err).Code
+	if code == errCodeInternalError {
+		code = errCodeInvalidInput
+	}
+	return &apiError{err: err, code: code, data: data}
+}
@@ -0,0 +1,412 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+const (
+	// maxSimulateBlocks is the maximum number of blocks that can be simulated
+	// in a single request.
+	maxSimulateBlocks = 256
+
+	// timestampIncrement is the default increment between block timestamps.
+	timestampIncrement = 12
+)
+
+// simBlock is a batch of calls to be simulated sequentially.
+type simBlock struct {
+	BlockOverrides *BlockOverrides
+	StateOverrides *StateOverride
+	Calls          []TransactionArgs
+}
+
+// simOpts are the inputs to eth_simulateV1.
+type simOpts struct {
+	BlockStateCalls        []simBlock
+	Validation             bool
+	ReturnFullTransactions bool
+}
+
+// simCallResult is the result of a simulated call.
+type simCallResult struct {
+	ReturnValue hexutil.Bytes  `json:"returnData"`
+	Logs        []*types.Log   `json:"logs"`
+	GasUsed     hexutil.Uint64 `json:"gasUsed"`
+	Status      hexutil.Uint64 `json:"status"`
+	Error       *callError     `json:"error,omitempty"`
+}
+
+// simChainHeadReader resolves the headers of the simulated blocks, falling back
+// to the canonical chain for the blocks preceding the simulation.
+type simChainHeadReader struct {
+	*ChainContext
+	headers map[common.Hash]*types.Header
+}
+
+func (r *simChainHeadReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header, ok := r.headers[hash]; ok {
+		return header
+	}
+	return r.ChainContext.GetHeader(hash, number)
+}
+
+// simulator is a stateful object that simulates a series of blocks on top of
+// a base block.
+type simulator struct {
+	b           Backend
+	state       *state.StateDB
+	base        *types.Header
+	chainConfig *params.ChainConfig
+	gp          *core.GasPool
+	validate    bool
+	fullTx      bool
+}
+
+// SimulateV1 executes series of transactions on top of a base state. The
+// transactions are packed into blocks. For each block, block header fields
+// and state can be overridden. The state is carried over from one block to
+// the next. If validation is enabled, the calls are checked like regular
+// transactions, i.e. nonces, balances and the base fee need to be valid.
+func (api *BlockChainAPI) SimulateV1(ctx context.Context, opts simOpts, blockNrOrHash *rpc.BlockNumberOrHash) ([]map[string]interface{}, error) {
+	if len(opts.BlockStateCalls) == 0 {
+		return nil, &invalidParamsError{message: "empty input"}
+	} else if len(opts.BlockStateCalls) > maxSimulateBlocks {
+		return nil, &clientLimitExceededError{message: "too many blocks"}
+	}
+	if blockNrOrHash == nil {
+		n := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &n
+	}
+	state, base, err := api.b.StateAndHeaderByNumberOrHash(ctx, *blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	// Setup context so it may be cancelled before the simulation completes.
+	var cancel context.CancelFunc
+	if timeout := api.b.RPCEVMTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	gasCap := api.b.RPCGasCap()
+	if gasCap == 0 {
+		gasCap = math.MaxUint64
+	}
+	sim := &simulator{
+		b:           api.b,
+		state:       state,
+		base:        base,
+		chainConfig: api.b.ChainConfig(),
+		// Each tx and all the series of txes shouldn't consume more gas than cap
+		gp:       new(core.GasPool).AddGas(gasCap),
+		validate: opts.Validation,
+		fullTx:   opts.ReturnFullTransactions,
+	}
+	return sim.execute(ctx, opts.BlockStateCalls)
+}
+
+// execute runs the simulation of a series of blocks.
+func (sim *simulator) execute(ctx context.Context, blocks []simBlock) ([]map[string]interface{}, error) {
+	blocks, err := sim.sanitizeChain(blocks)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		results = make([]map[string]interface{}, len(blocks))
+		parent  = sim.base
+		reader  = &simChainHeadReader{NewChainContext(ctx, sim.b), make(map[common.Hash]*types.Header)}
+	)
+	for i, block := range blocks {
+		result, callResults, senders, err := sim.processBlock(ctx, &block, parent, reader)
+		if err != nil {
+			return nil, err
+		}
+		enc := RPCMarshalBlock(result, true, sim.fullTx, sim.chainConfig)
+		if sim.fullTx {
+			// Simulated transactions are unsigned, fill in the senders explicitly.
+			for j, tx := range enc["transactions"].([]interface{}) {
+				tx.(*RPCTransaction).From = senders[j]
+			}
+		}
+		enc["calls"] = callResults
+		results[i] = enc
+
+		parent = result.Header()
+		reader.headers[parent.Hash()] = parent
+	}
+	return results, nil
+}
+
+// processBlock executes the calls of a block on top of its parent and assembles
+// the resulting block.
+func (sim *simulator) processBlock(ctx context.Context, block *simBlock, parent *types.Header, reader *simChainHeadReader) (*types.Block, []simCallResult, []common.Address, error) {
+	header := sim.makeHeader(block.BlockOverrides, parent)
+
+	blockContext := core.NewEVMBlockContext(header, reader, &header.Coinbase)
+	if block.BlockOverrides.BlobBaseFee != nil {
+		blockContext.BlobBaseFee = block.BlockOverrides.BlobBaseFee.ToInt()
+	}
+	if err := block.StateOverrides.Apply(sim.state); err != nil {
+		return nil, nil, nil, err
+	}
+	var (
+		gasUsed     uint64
+		blobGasUsed uint64
+		txes        = make([]*types.Transaction, len(block.Calls))
+		senders     = make([]common.Address, len(block.Calls))
+		receipts    = make([]*types.Receipt, len(block.Calls))
+		callResults = make([]simCallResult, len(block.Calls))
+		vmConfig    = vm.Config{NoBaseFee: !sim.validate}
+	)
+	evm := vm.NewEVM(blockContext, vm.TxContext{GasPrice: new(big.Int)}, sim.state, sim.chainConfig, vmConfig)
+
+	// Wait for the context to be done and cancel the evm. Even if the
+	// EVM has finished, cancelling may be done (repeatedly)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			evm.Cancel()
+		case <-done:
+		}
+	}()
+	if header.ParentBeaconRoot != nil {
+		core.ProcessBeaconBlockRoot(*header.ParentBeaconRoot, evm, sim.state)
+	}
+	for i := range block.Calls {
+		call := &block.Calls[i]
+		if err := sim.sanitizeCall(call, header, gasUsed); err != nil {
+			return nil, nil, nil, err
+		}
+		var (
+			tx  = call.ToTransaction()
+			msg = call.ToMessage(header.BaseFee)
+		)
+		if sim.validate {
+			msg.Nonce = uint64(*call.Nonce)
+			msg.SkipAccountChecks = false
+		}
+		txes[i], senders[i] = tx, msg.From
+
+		sim.state.SetTxContext(tx.Hash(), i)
+		evm.Reset(core.NewEVMTxContext(msg), sim.state)
+		result, err := core.ApplyMessage(evm, msg, sim.gp)
+		if err != nil {
+			return nil, nil, nil, txValidationError(fmt.Errorf("block %d, call %d: %w", header.Number, i, err))
+		}
+		if err := sim.state.Error(); err != nil {
+			return nil, nil, nil, err
+		}
+		if evm.Cancelled() {
+			return nil, nil, nil, fmt.Errorf("execution aborted (timeout = %v)", sim.b.RPCEVMTimeout())
+		}
+		var root []byte
+		if sim.chainConfig.IsByzantium(header.Number) {
+			sim.state.Finalise(true)
+		} else {
+			root = sim.state.IntermediateRoot(sim.chainConfig.IsEIP158(header.Number)).Bytes()
+		}
+		gasUsed += result.UsedGas
+		blobGasUsed += uint64(len(tx.BlobHashes()) * params.BlobTxBlobGasPerBlob)
+		receipts[i] = core.MakeReceipt(evm, result, sim.state, header.Number, common.Hash{}, tx, gasUsed, root)
+
+		callResult := simCallResult{
+			ReturnValue: result.Return(),
+			Logs:        receipts[i].Logs,
+			GasUsed:     hexutil.Uint64(result.UsedGas),
+			Status:      hexutil.Uint64(receipts[i].Status),
+		}
+		if result.Failed() {
+			if errors.Is(result.Err, vm.ErrExecutionReverted) {
+				// If the result contains a revert reason, try to unpack it.
+				revertErr := newRevertError(result.Revert())
+				callResult.Error = &callError{Message: revertErr.Error(), Code: errCodeReverted, Data: revertErr.reason}
+			} else {
+				callResult.Error = &callError{Message: result.Err.Error(), Code: errCodeVMError}
+			}
+		}
+		if callResult.Logs == nil {
+			callResult.Logs = []*types.Log{}
+		}
+		callResults[i] = callResult
+	}
+	header.Root = sim.state.IntermediateRoot(sim.chainConfig.IsEIP158(header.Number))
+	header.GasUsed = gasUsed
+	if sim.chainConfig.IsCancun(header.Number, header.Time) {
+		header.BlobGasUsed = &blobGasUsed
+	}
+	var withdrawals types.Withdrawals
+	if sim.chainConfig.IsShanghai(header.Number, header.Time) {
+		withdrawals = make(types.Withdrawals, 0)
+	}
+	result := types.NewBlock(header, &types.Body{Transactions: txes, Withdrawals: withdrawals}, receipts, trie.NewStackTrie(nil))
+
+	// The block hash is only known after execution, patch it into the logs.
+	hash := result.Hash()
+	for _, receipt := range receipts {
+		for _, log := range receipt.Logs {
+			log.BlockHash = hash
+		}
+	}
+	return result, callResults, senders, nil
+}
+
+// sanitizeCall fills in the defaults of a call, making sure it fits into the
+// gas remaining in the block.
+func (sim *simulator) sanitizeCall(call *TransactionArgs, header *types.Header, gasUsed uint64) error {
+	if call.Nonce == nil {
+		nonce := sim.state.GetNonce(call.from())
+		call.Nonce = (*hexutil.Uint64)(&nonce)
+	}
+	// Let the call run wild unless explicitly specified.
+	if call.Gas == nil {
+		remaining := header.GasLimit - gasUsed
+		call.Gas = (*hexutil.Uint64)(&remaining)
+	}
+	if gasUsed+uint64(*call.Gas) > header.GasLimit {
+		return &blockGasLimitReachedError{fmt.Sprintf("block gas limit reached: %d >= %d", gasUsed, header.GasLimit)}
+	}
+	if call.BlobHashes != nil && call.To == nil {
+		return &invalidParamsError{message: core.ErrBlobTxCreate.Error()}
+	}
+	return call.CallDefaults(sim.gp.Gas(), header.BaseFee, sim.chainConfig.ChainID)
+}
+
+// sanitizeChain checks the chain integrity. Specifically it checks that
+// block numbers and timestamps are strictly increasing, setting default values
+// when necessary. Gaps in block numbers are filled with empty blocks.
+func (sim *simulator) sanitizeChain(blocks []simBlock) ([]simBlock, error) {
+	var (
+		res           = make([]simBlock, 0, len(blocks))
+		base          = sim.base
+		prevNumber    = base.Number
+		prevTimestamp = base.Time
+	)
+	for _, block := range blocks {
+		if block.BlockOverrides == nil {
+			block.BlockOverrides = new(BlockOverrides)
+		}
+		if block.BlockOverrides.Number == nil {
+			n := new(big.Int).Add(prevNumber, big.NewInt(1))
+			block.BlockOverrides.Number = (*hexutil.Big)(n)
+		}
+		number := block.BlockOverrides.Number.ToInt()
+		diff := new(big.Int).Sub(number, prevNumber)
+		if diff.Sign() <= 0 {
+			return nil, &invalidBlockNumberError{fmt.Sprintf("block numbers must be in order: %d <= %d", number, prevNumber)}
+		}
+		if total := new(big.Int).Sub(number, base.Number); total.Cmp(big.NewInt(maxSimulateBlocks)) > 0 {
+			return nil, &clientLimitExceededError{message: "too many blocks"}
+		}
+		// Fill the gap with empty blocks.
+		for i := uint64(1); i < diff.Uint64(); i++ {
+			var (
+				n = new(big.Int).Add(prevNumber, new(big.Int).SetUint64(i))
+				t = prevTimestamp + timestampIncrement
+			)
+			res = append(res, simBlock{BlockOverrides: &BlockOverrides{Number: (*hexutil.Big)(n), Time: (*hexutil.Uint64)(&t)}})
+			prevTimestamp = t
+		}
+		prevNumber = number
+
+		var t uint64
+		if block.BlockOverrides.Time == nil {
+			t = prevTimestamp + timestampIncrement
+			block.BlockOverrides.Time = (*hexutil.Uint64)(&t)
+		} else {
+			t = uint64(*block.BlockOverrides.Time)
+			if t <= prevTimestamp {
+				return nil, &invalidBlockTimestampError{fmt.Sprintf("block timestamps must be in order: %d <= %d", t, prevTimestamp)}
+			}
+		}
+		prevTimestamp = t
+		res = append(res, block)
+	}
+	return res, nil
+}
+
+// makeHeader assembles the header of a simulated block from its overrides and
+// its parent. The execution results are filled in after processing the block.
+func (sim *simulator) makeHeader(overrides *BlockOverrides, parent *types.Header) *types.Header {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		UncleHash:  types.EmptyUncleHash,
+		Coinbase:   parent.Coinbase,
+		Difficulty: parent.Difficulty,
+		Number:     overrides.Number.ToInt(),
+		GasLimit:   parent.GasLimit,
+		Time:       uint64(*overrides.Time),
+		MixDigest:  parent.MixDigest,
+	}
+	if overrides.Coinbase != nil {
+		header.Coinbase = *overrides.Coinbase
+	}
+	if overrides.Difficulty != nil {
+		header.Difficulty = overrides.Difficulty.ToInt()
+	}
+	if overrides.GasLimit != nil {
+		header.GasLimit = uint64(*overrides.GasLimit)
+	}
+	if overrides.Random != nil {
+		header.MixDigest = *overrides.Random
+	}
+	if sim.chainConfig.IsLondon(header.Number) {
+		switch {
+		case overrides.BaseFee != nil:
+			header.BaseFee = overrides.BaseFee.ToInt()
+		case sim.validate && sim.chainConfig.IsLondon(parent.Number):
+			header.BaseFee = eip1559.CalcBaseFee(sim.chainConfig, parent)
+		case sim.validate:
+			header.BaseFee = new(big.Int).SetUint64(params.InitialBaseFee)
+		default:
+			header.BaseFee = new(big.Int)
+		}
+	}
+	if sim.chainConfig.IsShanghai(header.Number, header.Time) {
+		header.WithdrawalsHash = &types.EmptyWithdrawalsHash
+	}
+	if sim.chainConfig.IsCancun(header.Number, header.Time) {
+		var excess uint64
+		if sim.chainConfig.IsCancun(parent.Number, parent.Time) {
+			excess = eip4844.CalcExcessBlobGas(*parent.ExcessBlobGas, *parent.BlobGasUsed)
+		}
+		header.ExcessBlobGas = &excess
+		header.ParentBeaconRoot = new(common.Hash)
+	}
+	return header
+}
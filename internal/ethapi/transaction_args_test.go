@@ -360,6 +360,9 @@ func (b *backendMock) StateAndHeaderByNumber(ctx context.Context, number rpc.Blo
 func (b *backendMock) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
 	return nil, nil, nil
 }
+func (b *backendMock) HistoricalStateAndHeader(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, func(), error) {
+	return nil, nil, nil, nil
+}
 func (b *backendMock) Pending() (*types.Block, types.Receipts, *state.StateDB) { return nil, nil, nil }
 func (b *backendMock) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
 	return nil, nil
@@ -401,5 +404,8 @@ func (b *backendMock) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscript
 func (b *backendMock) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
 	return nil
 }
+func (b *backendMock) SubscribeChainFinalityEvent(ch chan<- core.ChainFinalityEvent) event.Subscription {
+	return nil
+}
 
 func (b *backendMock) Engine() consensus.Engine { return nil }
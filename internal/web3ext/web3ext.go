@@ -18,19 +18,22 @@
 package web3ext
 
 var Modules = map[string]string{
-	"admin":    AdminJs,
-	"clique":   CliqueJs,
-	"ethash":   EthashJs,
-	"debug":    DebugJs,
-	"eth":      EthJs,
-	"miner":    MinerJs,
-	"net":      NetJs,
-	"personal": PersonalJs,
-	"rpc":      RpcJs,
-	"txpool":   TxpoolJs,
-	"les":      LESJs,
-	"vflux":    VfluxJs,
-	"dev":      DevJs,
+	"admin":       AdminJs,
+	"clique":      CliqueJs,
+	"ethash":      EthashJs,
+	"debug":       DebugJs,
+	"eth":         EthJs,
+	"miner":       MinerJs,
+	"net":         NetJs,
+	"personal":    PersonalJs,
+	"rpc":         RpcJs,
+	"txpool":      TxpoolJs,
+	"trace":       TraceJs,
+	"les":         LESJs,
+	"vflux":       VfluxJs,
+	"dev":         DevJs,
+	"audit":       AuditJs,
+	"addressbook": AddressbookJs,
 }
 
 const CliqueJs = `
@@ -142,6 +145,26 @@ web3._extend({
 			call: 'admin_removeTrustedPeer',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'savePeers',
+			call: 'admin_savePeers',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'setNetRestrict',
+			call: 'admin_setNetRestrict',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'addNetRestrict',
+			call: 'admin_addNetRestrict',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'removeNetRestrict',
+			call: 'admin_removeNetRestrict',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'exportChain',
 			call: 'admin_exportChain',
@@ -153,6 +176,14 @@ web3._extend({
 			call: 'admin_importChain',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'pauseSync',
+			call: 'admin_pauseSync'
+		}),
+		new web3._extend.Method({
+			name: 'resumeSync',
+			call: 'admin_resumeSync'
+		}),
 		new web3._extend.Method({
 			name: 'sleepBlocks',
 			call: 'admin_sleepBlocks',
@@ -190,6 +221,26 @@ web3._extend({
 			name: 'stopWS',
 			call: 'admin_stopWS'
 		}),
+		new web3._extend.Method({
+			name: 'startRPCModule',
+			call: 'admin_startRPCModule',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'stopRPCModule',
+			call: 'admin_stopRPCModule',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'setHTTPCors',
+			call: 'admin_setHTTPCors',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'setHTTPVirtualHosts',
+			call: 'admin_setHTTPVirtualHosts',
+			params: 1
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -200,10 +251,36 @@ web3._extend({
 			name: 'peers',
 			getter: 'admin_peers'
 		}),
+		new web3._extend.Method({
+			name: 'crawlNetwork',
+			call: 'admin_crawlNetwork',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Property({
+			name: 'connectivityReport',
+			getter: 'admin_connectivityReport'
+		}),
+		new web3._extend.Property({
+			name: 'syncProgress',
+			getter: 'admin_syncProgress'
+		}),
+		new web3._extend.Property({
+			name: 'netRestrict',
+			getter: 'admin_netRestrict'
+		}),
 		new web3._extend.Property({
 			name: 'datadir',
 			getter: 'admin_datadir'
 		}),
+		new web3._extend.Property({
+			name: 'httpCors',
+			getter: 'admin_httpCors'
+		}),
+		new web3._extend.Property({
+			name: 'httpVirtualHosts',
+			getter: 'admin_httpVirtualHosts'
+		}),
 	]
 });
 `
@@ -270,6 +347,11 @@ web3._extend({
 			name: 'chaindbCompact',
 			call: 'debug_chaindbCompact',
 		}),
+		new web3._extend.Method({
+			name: 'chaindbCompactRange',
+			call: 'debug_chaindbCompactRange',
+			params: 2,
+		}),
 		new web3._extend.Method({
 			name: 'verbosity',
 			call: 'debug_verbosity',
@@ -431,6 +513,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, null]
 		}),
+		new web3._extend.Method({
+			name: 'traceTransactions',
+			call: 'debug_traceTransactions',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 		new web3._extend.Method({
 			name: 'traceCall',
 			call: 'debug_traceCall',
@@ -476,6 +564,12 @@ web3._extend({
 			params: 2,
 			inputFormatter:[web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter],
 		}),
+		new web3._extend.Method({
+			name: 'stateAvailability',
+			call: 'debug_stateAvailability',
+			params: 3,
+			inputFormatter:[web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter, null],
+		}),
 		new web3._extend.Method({
 			name: 'dbGet',
 			call: 'debug_dbGet',
@@ -580,6 +674,23 @@ web3._extend({
 			call: 'eth_getRawTransactionByHash',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'sendRawTransactionConditional',
+			call: 'eth_sendRawTransactionConditional',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'getLogsPage',
+			call: 'eth_getLogsPage',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'reattachFilter',
+			call: 'eth_reattachFilter',
+			params: 2,
+			inputFormatter: [null, web3._extend.utils.fromDecimal]
+		}),
 		new web3._extend.Method({
 			name: 'getRawTransactionFromBlock',
 			call: function(args) {
@@ -594,6 +705,12 @@ web3._extend({
 			params: 3,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputBlockNumberFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'getAccount',
+			call: 'eth_getAccount',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
 		new web3._extend.Method({
 			name: 'createAccessList',
 			call: 'eth_createAccessList',
@@ -617,6 +734,37 @@ web3._extend({
 			params: 4,
 			inputFormatter: [web3._extend.formatters.inputCallFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter, null, null],
 		}),
+		new web3._extend.Method({
+			name: 'simulateV1',
+			call: 'eth_simulateV1',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputDefaultBlockNumberFormatter],
+		}),
+		new web3._extend.Method({
+			name: 'supportedEntryPoints',
+			call: 'eth_supportedEntryPoints',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'sendUserOperation',
+			call: 'eth_sendUserOperation',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'estimateUserOperationGas',
+			call: 'eth_estimateUserOperationGas',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'getUserOperationByHash',
+			call: 'eth_getUserOperationByHash',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getUserOperationReceipt',
+			call: 'eth_getUserOperationReceipt',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'getBlockReceipts',
 			call: 'eth_getBlockReceipts',
@@ -693,6 +841,12 @@ web3._extend({
 			call: 'personal_importRawKey',
 			params: 2
 		}),
+		new web3._extend.Method({
+			name: 'importMnemonic',
+			call: 'personal_importMnemonic',
+			params: 5,
+			inputFormatter: [null, null, null, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'sign',
 			call: 'personal_sign',
@@ -736,6 +890,10 @@ web3._extend({
 			name: 'listWallets',
 			getter: 'personal_listWallets'
 		}),
+		new web3._extend.Property({
+			name: 'listUnlocks',
+			getter: 'personal_listUnlocks'
+		}),
 	]
 })
 `
@@ -785,6 +943,37 @@ web3._extend({
 });
 `
 
+const TraceJs = `
+web3._extend({
+	property: 'trace',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'block',
+			call: 'trace_block',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'transaction',
+			call: 'trace_transaction',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'filter',
+			call: 'trace_filter',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'call',
+			call: 'trace_call',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputCallFormatter, null, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+	]
+});
+`
+
 const LESJs = `
 web3._extend({
 	property: 'les',
@@ -888,3 +1077,51 @@ web3._extend({
 	],
 });
 `
+
+const AuditJs = `
+web3._extend({
+	property: 'audit',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'records',
+			call: 'audit_records',
+			params: 2,
+			inputFormatter: [web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'verify',
+			call: 'audit_verify',
+			outputFormatter: web3._extend.utils.toDecimal
+		}),
+	],
+});
+`
+
+const AddressbookJs = `
+web3._extend({
+	property: 'addressbook',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'add',
+			call: 'addressbook_add',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'remove',
+			call: 'addressbook_remove',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+	],
+	properties:
+	[
+		new web3._extend.Property({
+			name: 'list',
+			getter: 'addressbook_list'
+		}),
+	]
+});
+`
@@ -18,6 +18,7 @@
 package miner
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"sync"
@@ -40,6 +41,16 @@ type Backend interface {
 	TxPool() *txpool.TxPool
 }
 
+// BundleBuilder creates transactions placed at the top of the blocks built by
+// the miner, such as the bundles of ERC-4337 user operations. The state is the
+// pre-state of the block, which may be modified. The context is done when the
+// allowance for building the block runs out, builders must not wait for the
+// signing of their transactions beyond it. The bundles built on a parent block
+// are reused for all the blocks built on it.
+type BundleBuilder interface {
+	BuildBundles(ctx context.Context, header *types.Header, state *state.StateDB) []*types.Transaction
+}
+
 // Config is the configuration parameters of mining.
 type Config struct {
 	Etherbase           common.Address `toml:"-"`          // Deprecated
@@ -73,6 +84,17 @@ type Miner struct {
 	chain       *core.BlockChain
 	pending     *pending
 	pendingMu   sync.Mutex // Lock protects the pending block
+	bundles     BundleBuilder
+
+	bundleMu    sync.Mutex  // Lock protects the bundle cache, held while building bundles
+	bundleCache bundleCache // Bundles built on the latest parent block
+}
+
+// bundleCache holds the bundles built on top of a parent block, which are reused
+// when the block is rebuilt instead of being built and signed again.
+type bundleCache struct {
+	parent common.Hash
+	txs    []*types.Transaction
 }
 
 // New creates a new miner with provided config.
@@ -125,6 +147,18 @@ func (miner *Miner) SetGasTip(tip *big.Int) error {
 	return nil
 }
 
+// SetBundleBuilder sets the builder of the transactions placed at the top of
+// the built blocks.
+func (miner *Miner) SetBundleBuilder(builder BundleBuilder) {
+	miner.confMu.Lock()
+	miner.bundles = builder
+	miner.confMu.Unlock()
+
+	miner.bundleMu.Lock()
+	miner.bundleCache = bundleCache{}
+	miner.bundleMu.Unlock()
+}
+
 // BuildPayload builds the payload according to the provided parameters.
 func (miner *Miner) BuildPayload(args *BuildPayloadArgs) (*Payload, error) {
 	return miner.buildPayload(args)
@@ -17,8 +17,10 @@
 package miner
 
 import (
+	"context"
 	"math/big"
 	"reflect"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -30,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -197,6 +200,67 @@ func TestBuildPayload(t *testing.T) {
 	}
 }
 
+// testBundleBuilder returns a transfer of the bank at the nonce of the state, and
+// one with a nonce gap which can't be included.
+type testBundleBuilder struct {
+	built []*types.Transaction
+	calls atomic.Int32
+}
+
+func (b *testBundleBuilder) BuildBundles(ctx context.Context, header *types.Header, state *state.StateDB) []*types.Transaction {
+	b.calls.Add(1)
+	signer := types.LatestSigner(params.TestChainConfig)
+	nonce := state.GetNonce(testBankAddress)
+	b.built = nil
+	for _, n := range []uint64{nonce, nonce + 5} {
+		b.built = append(b.built, types.MustSignNewTx(testBankKey, signer, &types.DynamicFeeTx{
+			ChainID:   params.TestChainConfig.ChainID,
+			Nonce:     n,
+			To:        &testUserAddress,
+			Gas:       params.TxGas,
+			GasFeeCap: header.BaseFee,
+		}))
+	}
+	return b.built
+}
+
+func TestBuildPayloadBundles(t *testing.T) {
+	w, b := newTestWorker(t, params.TestChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+	builder := new(testBundleBuilder)
+	w.SetBundleBuilder(builder)
+
+	payload, err := w.buildPayload(&BuildPayloadArgs{
+		Parent:    b.chain.CurrentBlock().Hash(),
+		Timestamp: uint64(time.Now().Unix()),
+	})
+	if err != nil {
+		t.Fatalf("Failed to build payload %v", err)
+	}
+	// The bundle takes the nonce of the pending transaction, replacing it
+	txs := payload.ResolveFull().ExecutionPayload.Transactions
+	if len(txs) != 1 {
+		t.Fatalf("wrong number of transactions: %d", len(txs))
+	}
+	if enc, _ := builder.built[0].MarshalBinary(); !reflect.DeepEqual(txs[0], enc) {
+		t.Fatal("bundle not at the top of the block")
+	}
+	// Rebuilding on the same parent reuses the bundles
+	payload, err = w.buildPayload(&BuildPayloadArgs{
+		Parent:    b.chain.CurrentBlock().Hash(),
+		Timestamp: uint64(time.Now().Unix()) + 1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to build payload %v", err)
+	}
+	txs = payload.ResolveFull().ExecutionPayload.Transactions
+	if enc, _ := builder.built[0].MarshalBinary(); len(txs) != 1 || !reflect.DeepEqual(txs[0], enc) {
+		t.Fatal("bundle not reused")
+	}
+	if calls := builder.calls.Load(); calls != 1 {
+		t.Fatalf("bundles built %d times", calls)
+	}
+}
+
 func TestPayloadId(t *testing.T) {
 	t.Parallel()
 	ids := make(map[string]int)
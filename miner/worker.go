@@ -17,6 +17,7 @@
 package miner
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
@@ -349,6 +350,15 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 			txs.Pop()
 			continue
 		}
+		// Skip the sender if the inclusion conditions of the transaction do
+		// not hold for the block being built.
+		if cond := tx.Conditional(); cond != nil {
+			if err := miner.checkConditional(env, cond); err != nil {
+				log.Trace("Ignoring conditional transaction", "hash", ltx.Hash, "err", err)
+				txs.Pop()
+				continue
+			}
+		}
 		// Start executing the transaction
 		env.state.SetTxContext(tx.Hash(), env.tcount)
 
@@ -373,14 +383,68 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 	return nil
 }
 
+// commitBundles places the transactions of the bundle builder at the top of the
+// block. Bundles failing to apply are skipped. The builder is bounded by the
+// block building allowance, as the same applies to its signing.
+func (miner *Miner) commitBundles(env *environment, builder BundleBuilder) {
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	for _, tx := range miner.buildBundles(env, builder) {
+		env.state.SetTxContext(tx.Hash(), env.tcount)
+		if err := miner.commitTransaction(env, tx); err != nil {
+			log.Debug("Skipping bundle transaction", "hash", tx.Hash(), "err", err)
+		}
+	}
+}
+
+// buildBundles returns the bundles of the builder for the block. The bundles are
+// only built once per parent block, as the block is rebuilt on every recommit.
+func (miner *Miner) buildBundles(env *environment, builder BundleBuilder) []*types.Transaction {
+	miner.bundleMu.Lock()
+	defer miner.bundleMu.Unlock()
+
+	if cache := miner.bundleCache; cache.parent == env.header.ParentHash && len(cache.txs) > 0 {
+		return cache.txs
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), miner.config.Recommit)
+	defer cancel()
+
+	txs := builder.BuildBundles(ctx, env.header, env.state.Copy())
+	miner.bundleCache = bundleCache{parent: env.header.ParentHash, txs: txs}
+	return txs
+}
+
+// checkConditional checks the inclusion conditions of a transaction against the
+// block being built, including the state changes of the transactions already
+// committed into it.
+func (miner *Miner) checkConditional(env *environment, cond *types.TransactionConditional) error {
+	if err := cond.CheckBlock(env.header.Number, env.header.Time); err != nil {
+		return err
+	}
+	for _, account := range cond.KnownAccounts {
+		if account.StorageRoot != nil {
+			// Storage roots are only refreshed when the state root is computed
+			env.state.IntermediateRoot(miner.chainConfig.IsEIP158(env.header.Number))
+			break
+		}
+	}
+	return env.state.CheckTransactionConditional(cond)
+}
+
 // fillTransactions retrieves the pending transactions from the txpool and fills them
 // into the given sealing block. The transaction selection and ordering strategy can
 // be customized with the plugin in the future.
 func (miner *Miner) fillTransactions(interrupt *atomic.Int32, env *environment) error {
 	miner.confMu.RLock()
 	tip := miner.config.GasPrice
+	bundles := miner.bundles
 	miner.confMu.RUnlock()
 
+	if bundles != nil {
+		miner.commitBundles(env, bundles)
+	}
+
 	// Retrieve the pending transactions pre-filtered by the 1559/4844 dynamic fees
 	filter := txpool.PendingFilter{
 		MinTip: uint256.MustFromBig(tip),
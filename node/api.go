@@ -18,16 +18,22 @@ package node
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/netip"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/internal/debug"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/crawl"
 	"github.com/ethereum/go-ethereum/p2p/discover"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/netutil"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -70,6 +76,9 @@ func (api *adminAPI) AddPeer(url string) (bool, error) {
 		return false, fmt.Errorf("invalid enode: %v", err)
 	}
 	server.AddPeer(node)
+	if lists := api.node.peerLists; lists != nil {
+		lists.update(false, node, true)
+	}
 	return true, nil
 }
 
@@ -86,6 +95,9 @@ func (api *adminAPI) RemovePeer(url string) (bool, error) {
 		return false, fmt.Errorf("invalid enode: %v", err)
 	}
 	server.RemovePeer(node)
+	if lists := api.node.peerLists; lists != nil {
+		lists.update(false, node, false)
+	}
 	return true, nil
 }
 
@@ -101,6 +113,9 @@ func (api *adminAPI) AddTrustedPeer(url string) (bool, error) {
 		return false, fmt.Errorf("invalid enode: %v", err)
 	}
 	server.AddTrustedPeer(node)
+	if lists := api.node.peerLists; lists != nil {
+		lists.update(true, node, true)
+	}
 	return true, nil
 }
 
@@ -117,9 +132,76 @@ func (api *adminAPI) RemoveTrustedPeer(url string) (bool, error) {
 		return false, fmt.Errorf("invalid enode: %v", err)
 	}
 	server.RemoveTrustedPeer(node)
+	if lists := api.node.peerLists; lists != nil {
+		lists.update(true, node, false)
+	}
 	return true, nil
 }
 
+// SavePeers writes the static and trusted peers added or removed at runtime back
+// to the configured node files, so they persist across restarts.
+func (api *adminAPI) SavePeers() error {
+	lists := api.node.peerLists
+	if lists == nil {
+		return errNoPeerLists
+	}
+	return lists.save()
+}
+
+// NetRestrict returns the IP networks connectivity is restricted to, null if
+// connectivity is unrestricted.
+func (api *adminAPI) NetRestrict() ([]string, error) {
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	list := server.CurrentNetRestrict()
+	if list == nil {
+		return nil, nil
+	}
+	return list.MarshalTOML().([]string), nil
+}
+
+// SetNetRestrict replaces the IP networks connectivity is restricted to. An empty
+// list removes the restriction. The change is persisted across restarts.
+func (api *adminAPI) SetNetRestrict(masks []string) (bool, error) {
+	list, err := parseNetlist(masks)
+	if err != nil {
+		return false, fmt.Errorf("invalid netrestrict mask: %v", err)
+	}
+	err = api.node.updateNetRestrict(func(netutil.Netlist) netutil.Netlist { return *list })
+	return err == nil, err
+}
+
+// AddNetRestrict adds an IP network to the netrestrict list. The change is
+// persisted across restarts.
+func (api *adminAPI) AddNetRestrict(mask string) (bool, error) {
+	prefix, err := netip.ParsePrefix(mask)
+	if err != nil {
+		return false, fmt.Errorf("invalid netrestrict mask: %v", err)
+	}
+	err = api.node.updateNetRestrict(func(list netutil.Netlist) netutil.Netlist {
+		if slices.Contains(list, prefix) {
+			return list
+		}
+		return append(list, prefix)
+	})
+	return err == nil, err
+}
+
+// RemoveNetRestrict removes an IP network from the netrestrict list. Removing the
+// last network lifts the restriction. The change is persisted across restarts.
+func (api *adminAPI) RemoveNetRestrict(mask string) (bool, error) {
+	prefix, err := netip.ParsePrefix(mask)
+	if err != nil {
+		return false, fmt.Errorf("invalid netrestrict mask: %v", err)
+	}
+	err = api.node.updateNetRestrict(func(list netutil.Netlist) netutil.Netlist {
+		return slices.DeleteFunc(list, func(p netip.Prefix) bool { return p == prefix })
+	})
+	return err == nil, err
+}
+
 // PeerEvents creates an RPC subscription which receives peer events from the
 // node's p2p.Server
 func (api *adminAPI) PeerEvents(ctx context.Context) (*rpc.Subscription, error) {
@@ -191,7 +273,7 @@ func (api *adminAPI) StartHTTP(host *string, port *int, cors *string, apis *stri
 	}
 	if vhosts != nil {
 		config.Vhosts = nil
-		for _, vhost := range strings.Split(*host, ",") {
+		for _, vhost := range strings.Split(*vhosts, ",") {
 			config.Vhosts = append(config.Vhosts, strings.TrimSpace(vhost))
 		}
 	}
@@ -297,6 +379,93 @@ func (api *adminAPI) StopWS() (bool, error) {
 	return true, nil
 }
 
+// StartRPCModule exposes the APIs of the given module on the running HTTP and
+// WebSocket RPC servers. The module stays enabled until it is stopped again or
+// the servers are restarted, which restores the configured module lists.
+func (api *adminAPI) StartRPCModule(module string) (bool, error) {
+	api.node.lock.Lock()
+	defer api.node.lock.Unlock()
+
+	servers := api.node.rpcServers()
+	if len(servers) == 0 {
+		return false, errors.New("no HTTP or WebSocket RPC server running")
+	}
+	if module == "personal" && !api.node.config.EnablePersonal {
+		return false, errors.New("the personal module is disabled")
+	}
+	apis, _ := api.node.getAPIs()
+	found := false
+	for _, rpcAPI := range apis {
+		if rpcAPI.Namespace != module {
+			continue
+		}
+		found = true
+		for _, srv := range servers {
+			if err := srv.RegisterName(rpcAPI.Namespace, rpcAPI.Service); err != nil {
+				return false, err
+			}
+		}
+	}
+	if !found {
+		return false, fmt.Errorf("unknown module %q", module)
+	}
+	api.node.log.Info("Enabled RPC module", "module", module)
+	return true, nil
+}
+
+// StopRPCModule removes the APIs of the given module from the running HTTP and
+// WebSocket RPC servers. Subscriptions which were already established remain
+// active. It reports whether the module was exposed on any of the servers.
+func (api *adminAPI) StopRPCModule(module string) (bool, error) {
+	api.node.lock.Lock()
+	defer api.node.lock.Unlock()
+
+	removed := false
+	for _, srv := range api.node.rpcServers() {
+		if srv.UnregisterName(module) {
+			removed = true
+		}
+	}
+	if removed {
+		api.node.log.Info("Disabled RPC module", "module", module)
+	}
+	return removed, nil
+}
+
+// HttpCors returns the origins permitted to make cross-origin requests to the
+// running HTTP RPC server.
+func (api *adminAPI) HttpCors() ([]string, error) {
+	cors, _, err := api.node.http.httpFilters()
+	return cors, err
+}
+
+// SetHTTPCors replaces the origins permitted to make cross-origin requests to
+// the running HTTP RPC server. The change takes effect without a restart.
+func (api *adminAPI) SetHTTPCors(origins []string) (bool, error) {
+	if err := api.node.http.setHTTPFilters(&origins, nil); err != nil {
+		return false, err
+	}
+	api.node.log.Info("Updated HTTP CORS origins", "origins", origins)
+	return true, nil
+}
+
+// HttpVirtualHosts returns the virtual hostnames accepted by the running HTTP
+// RPC server.
+func (api *adminAPI) HttpVirtualHosts() ([]string, error) {
+	_, vhosts, err := api.node.http.httpFilters()
+	return vhosts, err
+}
+
+// SetHTTPVirtualHosts replaces the virtual hostnames accepted by the running
+// HTTP RPC server. The change takes effect without a restart.
+func (api *adminAPI) SetHTTPVirtualHosts(vhosts []string) (bool, error) {
+	if err := api.node.http.setHTTPFilters(nil, &vhosts); err != nil {
+		return false, err
+	}
+	api.node.log.Info("Updated HTTP virtual hosts", "vhosts", vhosts)
+	return true, nil
+}
+
 // Peers retrieves all the information we know about each individual peer at the
 // protocol granularity.
 func (api *adminAPI) Peers() ([]*p2p.PeerInfo, error) {
@@ -317,6 +486,56 @@ func (api *adminAPI) NodeInfo() (*p2p.NodeInfo, error) {
 	return server.NodeInfo(), nil
 }
 
+// ConnectivityReport summarizes discovery, dialing, NAT traversal and handshake
+// failures over a recent window, to help diagnose why the node has few peers.
+func (api *adminAPI) ConnectivityReport() (*p2p.ConnectivityReport, error) {
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	return server.ConnectivityReport(), nil
+}
+
+// crawlArgs are the settings of admin_crawlNetwork.
+type crawlArgs struct {
+	Timeout  uint64 `json:"timeout"`  // duration of the walk in seconds
+	MaxNodes int    `json:"maxNodes"` // stop after finding this many nodes
+	Probe    bool   `json:"probe"`    // probe the nodes with an RLPx connection
+}
+
+// maxCrawlTimeout limits the duration of crawls started over RPC.
+const maxCrawlTimeout = 10 * time.Minute
+
+// CrawlNetwork walks the discovery DHT and returns a snapshot of the nodes found,
+// optionally probing them for their client name, capabilities and eth status.
+func (api *adminAPI) CrawlNetwork(ctx context.Context, args *crawlArgs) (*crawl.Snapshot, error) {
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	v4, v5 := server.DiscoveryV4(), server.DiscoveryV5()
+	if v4 == nil && v5 == nil {
+		return nil, errors.New("discovery is disabled")
+	}
+	var config crawl.Config
+	if args != nil {
+		config.Timeout = time.Duration(args.Timeout) * time.Second
+		config.MaxNodes = args.MaxNodes
+		config.Probe = args.Probe
+	}
+	if config.Timeout > maxCrawlTimeout {
+		return nil, fmt.Errorf("crawl timeout exceeds the maximum of %v", maxCrawlTimeout)
+	}
+	mix := enode.NewFairMix(0)
+	if v4 != nil {
+		mix.AddSource(v4.RandomNodes())
+	}
+	if v5 != nil {
+		mix.AddSource(v5.RandomNodes())
+	}
+	return crawl.Run(ctx, mix, config), nil
+}
+
 // Datadir retrieves the current data directory the node is using.
 func (api *adminAPI) Datadir() string {
 	return api.node.DataDir()
@@ -22,9 +22,11 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/p2p/netutil"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/stretchr/testify/assert"
 )
@@ -299,6 +301,127 @@ func TestStartRPC(t *testing.T) {
 	}
 }
 
+// This test checks that the CORS origins and virtual hosts of the running HTTP
+// server can be changed through the admin API.
+func TestHTTPFilterUpdate(t *testing.T) {
+	stack, err := New(&Config{
+		HTTPHost:         "127.0.0.1",
+		HTTPVirtualHosts: []string{"localhost"},
+		HTTPTimeouts:     rpc.DefaultHTTPTimeouts,
+	})
+	if err != nil {
+		t.Fatal("can't create node:", err)
+	}
+	defer stack.Close()
+	if err := stack.Start(); err != nil {
+		t.Fatal("can't start node:", err)
+	}
+	var (
+		api = &adminAPI{stack}
+		url = stack.HTTPEndpoint()
+	)
+	// Requests for unknown hosts and origins are rejected initially.
+	resp := rpcRequest(t, url, "rpc_modules", "host", "example.com")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp = rpcRequest(t, url, "rpc_modules", "host", "localhost", "origin", "https://example.com")
+	assert.Equal(t, "", resp.Header.Get("Access-Control-Allow-Origin"))
+
+	// Update the filters and check they are applied immediately.
+	_, err = api.SetHTTPVirtualHosts([]string{"localhost", "example.com"})
+	assert.NoError(t, err)
+	_, err = api.SetHTTPCors([]string{"https://example.com"})
+	assert.NoError(t, err)
+
+	resp = rpcRequest(t, url, "rpc_modules", "host", "example.com", "origin", "https://example.com")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "https://example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+
+	vhosts, err := api.HttpVirtualHosts()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"localhost", "example.com"}, vhosts)
+	cors, err := api.HttpCors()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com"}, cors)
+
+	// Updates are rejected if HTTP is not running.
+	_, err = api.StopHTTP()
+	assert.NoError(t, err)
+	_, err = api.SetHTTPCors([]string{"*"})
+	assert.Error(t, err)
+}
+
+// This test checks that RPC modules can be switched on and off on the running
+// HTTP server through the admin API.
+func TestRPCModuleToggle(t *testing.T) {
+	stack, err := New(&Config{
+		HTTPHost:     "127.0.0.1",
+		HTTPModules:  []string{"web3"},
+		HTTPTimeouts: rpc.DefaultHTTPTimeouts,
+	})
+	if err != nil {
+		t.Fatal("can't create node:", err)
+	}
+	defer stack.Close()
+
+	// Modules can't be toggled before any server is running.
+	api := &adminAPI{stack}
+	_, err = api.StartRPCModule("admin")
+	assert.Error(t, err)
+
+	if err := stack.Start(); err != nil {
+		t.Fatal("can't start node:", err)
+	}
+	client, err := rpc.Dial(stack.HTTPEndpoint())
+	if err != nil {
+		t.Fatal("can't dial node:", err)
+	}
+	defer client.Close()
+
+	var datadir string
+	assert.Error(t, client.Call(&datadir, "admin_datadir"))
+
+	ok, err := api.StartRPCModule("admin")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NoError(t, client.Call(&datadir, "admin_datadir"))
+
+	ok, err = api.StopRPCModule("admin")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Error(t, client.Call(&datadir, "admin_datadir"))
+
+	// Stopping a module which isn't exposed is a no-op, unknown modules are rejected.
+	ok, err = api.StopRPCModule("admin")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	_, err = api.StartRPCModule("foo")
+	assert.Error(t, err)
+}
+
+// This test checks that admin_startHTTP applies the virtual hosts argument.
+func TestStartHTTPVirtualHosts(t *testing.T) {
+	stack, err := New(&Config{})
+	if err != nil {
+		t.Fatal("can't create node:", err)
+	}
+	defer stack.Close()
+	if err := stack.Start(); err != nil {
+		t.Fatal("can't start node:", err)
+	}
+	api := &adminAPI{stack}
+	_, err = api.StartHTTP(sp("127.0.0.1"), ip(0), nil, nil, sp("example.com, example.org"))
+	assert.NoError(t, err)
+
+	vhosts, err := api.HttpVirtualHosts()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"example.com", "example.org"}, vhosts)
+
+	resp := rpcRequest(t, stack.HTTPEndpoint(), "rpc_modules", "host", "example.org")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = rpcRequest(t, stack.HTTPEndpoint(), "rpc_modules", "host", "localhost")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
 // checkReachable checks if the TCP endpoint in rawurl is open.
 func checkReachable(rawurl string) bool {
 	u, err := url.Parse(rawurl)
@@ -353,3 +476,66 @@ func not(ok bool) string {
 	}
 	return "not "
 }
+
+// Tests that the netrestrict list can be changed at runtime and that changes
+// persist across restarts.
+func TestNetRestrictAPI(t *testing.T) {
+	config := testNodeConfig()
+	config.DataDir = t.TempDir()
+	config.P2P.NetRestrict, _ = netutil.ParseNetlist("10.0.0.0/8")
+
+	stack, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatal(err)
+	}
+	api := &adminAPI{stack}
+	check := func(want ...string) {
+		t.Helper()
+		have, err := api.NetRestrict()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(have, want) && (len(have) != 0 || len(want) != 0) {
+			t.Fatalf("wrong netrestrict list: have %v, want %v", have, want)
+		}
+	}
+	check("10.0.0.0/8")
+
+	if _, err := api.AddNetRestrict("192.168.0.0/16"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.AddNetRestrict("10.0.0.0/8"); err != nil {
+		t.Fatal(err)
+	}
+	check("10.0.0.0/8", "192.168.0.0/16")
+	if _, err := api.RemoveNetRestrict("10.0.0.0/8"); err != nil {
+		t.Fatal(err)
+	}
+	check("192.168.0.0/16")
+	if _, err := api.AddNetRestrict("invalid"); err == nil {
+		t.Fatal("invalid mask accepted")
+	}
+	stack.Close()
+
+	// The runtime change overrides the configured list after a restart.
+	stack, err = New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stack.Close()
+	if err := stack.Start(); err != nil {
+		t.Fatal(err)
+	}
+	api = &adminAPI{stack}
+	check("192.168.0.0/16")
+	if _, err := api.SetNetRestrict(nil); err != nil {
+		t.Fatal(err)
+	}
+	check()
+	if list, ok, err := stack.loadNetRestrict(); err != nil || !ok || list != nil {
+		t.Fatalf("lifted restriction not persisted: %v %v %v", list, ok, err)
+	}
+}
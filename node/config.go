@@ -23,7 +23,9 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -39,6 +41,7 @@ const (
 	datadirStaticNodes     = "static-nodes.json"  // Path within the datadir to the static node list
 	datadirTrustedNodes    = "trusted-nodes.json" // Path within the datadir to the trusted node list
 	datadirNodeDatabase    = "nodes"              // Path within the datadir to store the node infos
+	datadirNetRestrict     = "netrestrict.json"   // Path within the datadir to the netrestrict list set at runtime
 )
 
 // Config represents a small collection of configuration values to fine tune the
@@ -67,6 +70,12 @@ type Config struct {
 	// Configuration of peer-to-peer networking.
 	P2P p2p.Config
 
+	// StaticNodesFile and TrustedNodesFile are JSON lists of enode URLs which are
+	// watched for changes and applied at runtime. Changes made through the admin
+	// API are written back by admin_savePeers.
+	StaticNodesFile  string `toml:",omitempty"`
+	TrustedNodesFile string `toml:",omitempty"`
+
 	// KeyStoreDir is the file system folder that contains private keys. The directory can
 	// be specified as a relative path, in which case it is resolved relative to the
 	// current directory.
@@ -76,16 +85,76 @@ type Config struct {
 	// is created by New and destroyed when the node is stopped.
 	KeyStoreDir string `toml:",omitempty"`
 
+	// KeyStoreExtraDirs are additional key directories of the keystore, in the form
+	// "path[:ro][:nounlock][:maxunlock=<duration>]", see keystore.ParseKeyDir.
+	// Keys of the primary keystore directory take precedence, then those of the
+	// additional directories in order.
+	KeyStoreExtraDirs []string `toml:",omitempty"`
+
 	// ExternalSigner specifies an external URI for a clef-type signer.
 	ExternalSigner string `toml:",omitempty"`
 
+	// KMSKeys are the URLs of signing keys held by a cloud key management service,
+	// see package accounts/kms for the format.
+	KMSKeys []string `toml:",omitempty"`
+
+	// MPCEndpoint is the JSON-RPC endpoint of an MPC or threshold signing service,
+	// see package accounts/mpc for the protocol. MPCTimeout bounds the time waited
+	// for a signature when the caller sets no deadline.
+	MPCEndpoint string        `toml:",omitempty"`
+	MPCTimeout  time.Duration `toml:",omitempty"`
+
+	// PKCS11Module is the path of a PKCS#11 module giving access to the signing keys
+	// of a hardware security module. The token PIN is read from PKCS11PINFile.
+	PKCS11Module  string `toml:",omitempty"`
+	PKCS11PINFile string `toml:",omitempty"`
+
+	// VaultAddress is the URL of a HashiCorp Vault server storing signing keys at
+	// the VaultKeys paths of the KV engine mounted at VaultMount, optionally
+	// encrypted with the VaultTransit key. The remaining fields configure the
+	// login, see package accounts/vault.
+	VaultAddress    string   `toml:",omitempty"`
+	VaultKeys       []string `toml:",omitempty"`
+	VaultMount      string   `toml:",omitempty"`
+	VaultTransit    string   `toml:",omitempty"`
+	VaultAuthMethod string   `toml:",omitempty"`
+	VaultAuthMount  string   `toml:",omitempty"`
+	VaultAuthRole   string   `toml:",omitempty"`
+	VaultSecretFile string   `toml:",omitempty"`
+
+	// AddressBook enables the address book of watch-only accounts, stored in the
+	// data directory and editable over the authenticated RPC endpoints.
+	AddressBook bool `toml:",omitempty"`
+
 	// UseLightweightKDF lowers the memory and CPU requirements of the key store
 	// scrypt KDF at the expense of security.
 	UseLightweightKDF bool `toml:",omitempty"`
 
+	// KeyStoreKDF is the key derivation function encrypting new keys, in the form
+	// "scrypt:n=262144,p=1" or "argon2id:t=3,m=65536,p=4". It takes precedence
+	// over UseLightweightKDF.
+	KeyStoreKDF string `toml:",omitempty"`
+
+	// KeyStoreKDFTarget, if set, picks the parameters of the key derivation function
+	// by benchmark, so that unlocking a key takes about the given time.
+	KeyStoreKDFTarget time.Duration `toml:",omitempty"`
+
 	// InsecureUnlockAllowed allows user to unlock accounts in unsafe http environment.
 	InsecureUnlockAllowed bool `toml:",omitempty"`
 
+	// StrictReplayProtection makes all accounts sign and send replay-protected
+	// (EIP-155) transactions only. ReplayProtectedAccounts applies the same policy
+	// to the listed accounts only.
+	StrictReplayProtection  bool             `toml:",omitempty"`
+	ReplayProtectedAccounts []common.Address `toml:",omitempty"`
+
+	// SigningAuditLog is the file recording the signing operations requested from
+	// the accounts of the node over RPC, see package accounts/audit. Relative paths
+	// are resolved in the instance directory. SigningAuditHashChain hash-chains the
+	// records to make tampering evident.
+	SigningAuditLog       string `toml:",omitempty"`
+	SigningAuditHashChain bool   `toml:",omitempty"`
+
 	// NoUSB disables hardware wallet monitoring and connectivity.
 	// Deprecated: USB monitoring is disabled by default and must be enabled explicitly.
 	NoUSB bool `toml:",omitempty"`
@@ -93,6 +162,11 @@ type Config struct {
 	// USB enables hardware wallet monitoring and connectivity.
 	USB bool `toml:",omitempty"`
 
+	// USBDerivationPaths are the derivation path templates used to discover the
+	// accounts of hardware wallets, e.g. m/44'/60'/x'/0/0. If empty, the default
+	// and legacy Ledger paths are used.
+	USBDerivationPaths []string `toml:",omitempty"`
+
 	// SmartCardDaemonPath is the path to the smartcard daemon's socket.
 	SmartCardDaemonPath string `toml:",omitempty"`
 
@@ -102,6 +176,16 @@ type Config struct {
 	// relative), then that specific path is enforced. An empty path disables IPC.
 	IPCPath string
 
+	// IPCAccessControl restricts the methods which may be called over the IPC
+	// interface. IPC clients cannot present API keys, so only the default rule
+	// applies.
+	IPCAccessControl *rpc.AccessControl `toml:",omitempty"`
+
+	// IPCEndpoints configures additional IPC endpoints, each exposing its own set
+	// of API modules. This allows isolating local processes by capability, e.g.
+	// by offering a read-only socket next to the administrative one.
+	IPCEndpoints []IPCEndpointConfig `toml:",omitempty"`
+
 	// HTTPHost is the host interface on which to start the HTTP RPC server. If this
 	// field is empty, no HTTP API endpoint will be started.
 	HTTPHost string
@@ -137,6 +221,19 @@ type Config struct {
 	// HTTPPathPrefix specifies a path prefix on which http-rpc is to be served.
 	HTTPPathPrefix string `toml:",omitempty"`
 
+	// HTTPCompressionThreshold is the minimum size in bytes of HTTP responses to
+	// compress with gzip or brotli, as accepted by the client. Smaller responses
+	// are sent as is.
+	HTTPCompressionThreshold int `toml:",omitempty"`
+
+	// HTTPJWTSecret is the path to the hex-encoded jwt secret used to authenticate
+	// requests on the HTTP RPC interface. If empty, requests are not authenticated.
+	HTTPJWTSecret string `toml:",omitempty"`
+
+	// HTTPAccessControl restricts the methods and namespaces HTTP RPC clients may
+	// call, optionally depending on the API key they present.
+	HTTPAccessControl *rpc.AccessControl `toml:",omitempty"`
+
 	// AuthAddr is the listening address on which authenticated APIs are provided.
 	AuthAddr string `toml:",omitempty"`
 
@@ -159,6 +256,15 @@ type Config struct {
 	// WSPathPrefix specifies a path prefix on which ws-rpc is to be served.
 	WSPathPrefix string `toml:",omitempty"`
 
+	// WSJWTSecret is the path to the hex-encoded jwt secret used to authenticate
+	// connections on the websocket RPC interface. If empty, connections are not
+	// authenticated.
+	WSJWTSecret string `toml:",omitempty"`
+
+	// WSAccessControl restricts the methods and namespaces websocket RPC clients
+	// may call, optionally depending on the API key they present.
+	WSAccessControl *rpc.AccessControl `toml:",omitempty"`
+
 	// WSOrigins is the list of domain to accept websocket requests from. Please be
 	// aware that the server can only act upon the HTTP request the client sends and
 	// cannot verify the validity of the request header.
@@ -169,6 +275,55 @@ type Config struct {
 	// exposed.
 	WSModules []string
 
+	// WSCompression enables negotiating permessage-deflate compression with
+	// websocket clients, reducing the bandwidth of large subscription payloads.
+	WSCompression bool `toml:",omitempty"`
+
+	// WSCompressionLevel is the compress/flate level used for compressed websocket
+	// messages.
+	WSCompressionLevel int `toml:",omitempty"`
+
+	// WSCompressionThreshold is the minimum size in bytes of websocket messages
+	// to compress. Smaller messages are sent as is.
+	WSCompressionThreshold int `toml:",omitempty"`
+
+	// WSSubscriptionPolicy determines how notifications are handled when a websocket
+	// subscriber consumes them slower than they are produced.
+	WSSubscriptionPolicy rpc.BackpressurePolicy `toml:",omitempty"`
+
+	// WSSubscriptionBuffer is the number of notifications queued per subscription
+	// before the subscription policy is applied.
+	WSSubscriptionBuffer int `toml:",omitempty"`
+
+	// WSPingInterval is the time without outgoing messages after which a ping is
+	// sent to keep websocket connections alive through proxies.
+	WSPingInterval time.Duration `toml:",omitempty"`
+
+	// WSPongTimeout is the time to wait for the pong answering a ping before the
+	// websocket connection is considered dead.
+	WSPongTimeout time.Duration `toml:",omitempty"`
+
+	// WSWriteTimeout is the deadline of writing a message to a websocket connection.
+	WSWriteTimeout time.Duration `toml:",omitempty"`
+
+	// WSIdleTimeout is the time after which websocket connections without any
+	// messages in either direction are closed. Zero keeps idle connections open.
+	WSIdleTimeout time.Duration `toml:",omitempty"`
+
+	// WSMaxConnections is the maximum number of concurrent websocket connections.
+	// Zero means no limit.
+	WSMaxConnections int `toml:",omitempty"`
+
+	// WSMaxSubscriptions is the maximum number of active subscriptions of a single
+	// websocket connection. Zero means no limit.
+	WSMaxSubscriptions int `toml:",omitempty"`
+
+	// WSMessageRate is the sustained number of messages per second accepted from a
+	// single websocket connection, WSMessageBurst the number permitted at once.
+	// A zero rate means no limit.
+	WSMessageRate  float64 `toml:",omitempty"`
+	WSMessageBurst int     `toml:",omitempty"`
+
 	// WSExposeAll exposes all API modules via the WebSocket RPC interface rather
 	// than just the public ones.
 	//
@@ -204,6 +359,41 @@ type Config struct {
 	// BatchResponseMaxSize is the maximum number of bytes returned from a batched rpc call.
 	BatchResponseMaxSize int `toml:",omitempty"`
 
+	// BatchTimeout is the maximum time spent executing the calls of a batch. Calls
+	// not completed in time are answered with a timeout error.
+	BatchTimeout time.Duration `toml:",omitempty"`
+
+	// RPCMethodTimeouts sets execution deadlines for individual methods on the
+	// HTTP and websocket RPC interfaces, e.g. to abort long running traces.
+	RPCMethodTimeouts map[string]time.Duration `toml:",omitempty"`
+
+	// RPCRateLimit configures per-client request quotas on the HTTP and websocket
+	// RPC interfaces. Clients are identified by API key or IP address.
+	RPCRateLimit *rpc.RateLimit `toml:",omitempty"`
+
+	// RPCAuditLog is the file to which a JSON record of every call served by the
+	// HTTP and websocket RPC interfaces is appended. Secrets such as account
+	// passwords are redacted. Relative paths are resolved in the instance directory.
+	RPCAuditLog string `toml:",omitempty"`
+
+	// RPCAuditNamespaces restricts the audit log to calls of the given API
+	// namespaces, e.g. admin and debug. All calls are recorded if empty.
+	RPCAuditNamespaces []string `toml:",omitempty"`
+
+	// RPCTrustedProxies lists the addresses and CIDR ranges of reverse proxies in
+	// front of the HTTP and websocket RPC interfaces. Requests relayed by them are
+	// attributed to the client named in the X-Forwarded-For header for rate
+	// limiting, access control and audit logging.
+	RPCTrustedProxies []string `toml:",omitempty"`
+
+	// RPCProxyProtocol accepts PROXY protocol headers announcing the client address
+	// on connections from trusted proxies.
+	RPCProxyProtocol bool `toml:",omitempty"`
+
+	// RPCCacheSize is the amount of memory in megabytes used for caching the
+	// results of HTTP and websocket RPC calls which reference immutable chain data.
+	RPCCacheSize int `toml:",omitempty"`
+
 	// JWTSecret is the path to the hex-encoded jwt secret.
 	JWTSecret string `toml:",omitempty"`
 
@@ -217,25 +407,60 @@ type Config struct {
 // account the set data folders as well as the designated platform we're currently
 // running on.
 func (c *Config) IPCEndpoint() string {
+	return c.resolveIPCPath(c.IPCPath)
+}
+
+// resolveIPCPath resolves the requested location of an IPC endpoint into the
+// path of the socket, or the name of the pipe on Windows.
+func (c *Config) resolveIPCPath(path string) string {
 	// Short circuit if IPC has not been enabled
-	if c.IPCPath == "" {
+	if path == "" {
 		return ""
 	}
 	// On windows we can only use plain top-level pipes
 	if runtime.GOOS == "windows" {
-		if strings.HasPrefix(c.IPCPath, `\\.\pipe\`) {
-			return c.IPCPath
+		if strings.HasPrefix(path, `\\.\pipe\`) {
+			return path
 		}
-		return `\\.\pipe\` + c.IPCPath
+		return `\\.\pipe\` + path
 	}
 	// Resolve names into the data directory full paths otherwise
-	if filepath.Base(c.IPCPath) == c.IPCPath {
+	if filepath.Base(path) == path {
 		if c.DataDir == "" {
-			return filepath.Join(os.TempDir(), c.IPCPath)
+			return filepath.Join(os.TempDir(), path)
 		}
-		return filepath.Join(c.DataDir, c.IPCPath)
+		return filepath.Join(c.DataDir, path)
+	}
+	return path
+}
+
+// IPCEndpointConfig configures an additional IPC endpoint of the node.
+type IPCEndpointConfig struct {
+	// Path is the location of the endpoint, resolved the same way as IPCPath.
+	Path string
+
+	// Modules is the list of API modules exposed on the endpoint. An empty list
+	// exposes all modules.
+	Modules []string `toml:",omitempty"`
+
+	// Permissions is the octal file mode of the socket (e.g. "0660"). It defaults
+	// to only permitting access by the owner and is ignored on Windows.
+	Permissions string `toml:",omitempty"`
+
+	// AccessControl restricts the methods which may be called on the endpoint.
+	AccessControl *rpc.AccessControl `toml:",omitempty"`
+}
+
+// fileMode returns the configured permissions of the socket.
+func (c *IPCEndpointConfig) fileMode() (os.FileMode, error) {
+	if c.Permissions == "" {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(c.Permissions, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid permissions %q for IPC endpoint %s", c.Permissions, c.Path)
 	}
-	return c.IPCPath
+	return os.FileMode(mode), nil
 }
 
 // NodeDB returns the path to the discovery node database.
@@ -49,24 +49,30 @@ var (
 	DefaultAuthVhosts  = []string{"localhost"} // Default virtual hosts for the authenticated apis
 	DefaultAuthOrigins = []string{"localhost"} // Default origins for the authenticated apis
 	DefaultAuthPrefix  = ""                    // Default prefix for the authenticated apis
-	DefaultAuthModules = []string{"eth", "engine"}
+	DefaultAuthModules = []string{"eth", "engine", "account"}
 )
 
 // DefaultConfig contains reasonable default settings.
 var DefaultConfig = Config{
-	DataDir:              DefaultDataDir(),
-	HTTPPort:             DefaultHTTPPort,
-	AuthAddr:             DefaultAuthHost,
-	AuthPort:             DefaultAuthPort,
-	AuthVirtualHosts:     DefaultAuthVhosts,
-	HTTPModules:          []string{"net", "web3"},
-	HTTPVirtualHosts:     []string{"localhost"},
-	HTTPTimeouts:         rpc.DefaultHTTPTimeouts,
-	WSPort:               DefaultWSPort,
-	WSModules:            []string{"net", "web3"},
-	BatchRequestLimit:    1000,
-	BatchResponseMaxSize: 25 * 1000 * 1000,
-	GraphQLVirtualHosts:  []string{"localhost"},
+	DataDir:                DefaultDataDir(),
+	HTTPPort:               DefaultHTTPPort,
+	AuthAddr:               DefaultAuthHost,
+	AuthPort:               DefaultAuthPort,
+	AuthVirtualHosts:       DefaultAuthVhosts,
+	HTTPModules:            []string{"net", "web3"},
+	HTTPVirtualHosts:       []string{"localhost"},
+	HTTPTimeouts:           rpc.DefaultHTTPTimeouts,
+	WSPort:                 DefaultWSPort,
+	WSModules:              []string{"net", "web3"},
+	WSCompressionLevel:     1,
+	WSCompressionThreshold: rpc.DefaultWebsocketCompressionThreshold,
+	WSSubscriptionBuffer:   rpc.DefaultSubscriptionBuffer,
+	WSPingInterval:         rpc.DefaultWebsocketPingInterval,
+	WSPongTimeout:          rpc.DefaultWebsocketPongTimeout,
+	WSWriteTimeout:         rpc.DefaultWebsocketWriteTimeout,
+	BatchRequestLimit:      1000,
+	BatchResponseMaxSize:   25 * 1000 * 1000,
+	GraphQLVirtualHosts:    []string{"localhost"},
 	P2P: p2p.Config{
 		ListenAddr: ":30303",
 		MaxPeers:   50,
@@ -19,6 +19,7 @@ package node
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/rpc"
@@ -43,3 +44,22 @@ func NewJWTAuth(jwtsecret [32]byte) rpc.HTTPAuth {
 		return nil
 	}
 }
+
+// NewScopedJWTAuth creates an rpc client authentication provider that uses JWT
+// tokens restricted to the given scopes. The bearer of the tokens may only call
+// the methods matching the scopes, which are namespaces ("account"), method names
+// ("account_signTransaction") or wildcard patterns.
+func NewScopedJWTAuth(jwtsecret [32]byte, scopes []string) rpc.HTTPAuth {
+	return func(h http.Header) error {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"iat":   &jwt.NumericDate{Time: time.Now()},
+			"scope": strings.Join(scopes, " "),
+		})
+		s, err := token.SignedString(jwtsecret[:])
+		if err != nil {
+			return fmt.Errorf("failed to create JWT token: %w", err)
+		}
+		h.Set("Authorization", "Bearer "+s)
+		return nil
+	}
+}
@@ -17,15 +17,34 @@
 package node
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/golang-jwt/jwt/v4"
 )
 
 const jwtExpiryTimeout = 60 * time.Second
 
+// jwtClaims are the claims of the tokens accepted by the authenticated endpoint.
+// The optional scope claim is a space-separated list of the methods the bearer
+// may call, given as namespaces ("engine"), methods ("account_sign") or wildcard
+// patterns. Tokens without a scope claim grant access to all methods.
+type jwtClaims struct {
+	jwt.RegisteredClaims
+	Scope *string `json:"scope,omitempty"`
+}
+
+// scopes returns the scopes granted by the token, or nil if it is unrestricted.
+func (c *jwtClaims) scopes() []string {
+	if c.Scope == nil {
+		return nil
+	}
+	return append([]string{}, strings.Fields(*c.Scope)...)
+}
+
 type jwtHandler struct {
 	keyFunc func(token *jwt.Token) (interface{}, error)
 	next    http.Handler
@@ -45,7 +64,7 @@ func newJWTHandler(secret []byte, next http.Handler) http.Handler {
 func (handler *jwtHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
 	var (
 		strToken string
-		claims   jwt.RegisteredClaims
+		claims   jwtClaims
 	)
 	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
 		strToken = strings.TrimPrefix(auth, "Bearer ")
@@ -54,27 +73,49 @@ func (handler *jwtHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
 		http.Error(out, "missing token", http.StatusUnauthorized)
 		return
 	}
+	if err := validateJWT(strToken, &claims, handler.keyFunc); err != nil {
+		http.Error(out, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if scopes := claims.scopes(); scopes != nil {
+		r = r.WithContext(rpc.NewContextWithScopes(r.Context(), scopes))
+	}
+	handler.next.ServeHTTP(out, r)
+}
+
+// ValidateJWT checks a token presented to an endpoint authenticated with the given
+// secret. It returns the scopes granted by the token, which are nil if the token
+// is unrestricted.
+func ValidateJWT(secret []byte, strToken string) ([]string, error) {
+	var claims jwtClaims
+	keyFunc := func(token *jwt.Token) (interface{}, error) { return secret, nil }
+	if err := validateJWT(strToken, &claims, keyFunc); err != nil {
+		return nil, err
+	}
+	return claims.scopes(), nil
+}
+
+func validateJWT(strToken string, claims *jwtClaims, keyFunc jwt.Keyfunc) error {
 	// We explicitly set only HS256 allowed, and also disables the
 	// claim-check: the RegisteredClaims internally requires 'iat' to
 	// be no later than 'now', but we allow for a bit of drift.
-	token, err := jwt.ParseWithClaims(strToken, &claims, handler.keyFunc,
+	token, err := jwt.ParseWithClaims(strToken, claims, keyFunc,
 		jwt.WithValidMethods([]string{"HS256"}),
 		jwt.WithoutClaimsValidation())
 
 	switch {
 	case err != nil:
-		http.Error(out, err.Error(), http.StatusUnauthorized)
+		return err
 	case !token.Valid:
-		http.Error(out, "invalid token", http.StatusUnauthorized)
+		return errors.New("invalid token")
 	case !claims.VerifyExpiresAt(time.Now(), false): // optional
-		http.Error(out, "token is expired", http.StatusUnauthorized)
+		return errors.New("token is expired")
 	case claims.IssuedAt == nil:
-		http.Error(out, "missing issued-at", http.StatusUnauthorized)
+		return errors.New("missing issued-at")
 	case time.Since(claims.IssuedAt.Time) > jwtExpiryTimeout:
-		http.Error(out, "stale token", http.StatusUnauthorized)
+		return errors.New("stale token")
 	case time.Until(claims.IssuedAt.Time) > jwtExpiryTimeout:
-		http.Error(out, "future token", http.StatusUnauthorized)
-	default:
-		handler.next.ServeHTTP(out, r)
+		return errors.New("future token")
 	}
+	return nil
 }
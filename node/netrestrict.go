@@ -0,0 +1,107 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/p2p/netutil"
+)
+
+// loadNetRestrict reads the netrestrict list persisted by a previous run, which
+// overrides the configured one. The list is nil if the restriction was removed,
+// ok reports whether a list was persisted at all.
+func (n *Node) loadNetRestrict() (list *netutil.Netlist, ok bool, err error) {
+	path := n.config.ResolvePath(datadirNetRestrict)
+	if path == "" {
+		return nil, false, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	var masks []string
+	if err := json.Unmarshal(data, &masks); err != nil {
+		return nil, false, fmt.Errorf("invalid netrestrict file %s: %v", path, err)
+	}
+	if len(masks) == 0 {
+		return nil, true, nil
+	}
+	if list, err = parseNetlist(masks); err != nil {
+		return nil, false, fmt.Errorf("invalid netrestrict file %s: %v", path, err)
+	}
+	return list, true, nil
+}
+
+// saveNetRestrict persists the netrestrict list, so it survives restarts. An
+// empty list is stored for a nil one, removing the restriction of the config.
+func (n *Node) saveNetRestrict(list *netutil.Netlist) error {
+	path := n.config.ResolvePath(datadirNetRestrict)
+	if path == "" {
+		return nil // ephemeral
+	}
+	masks := []string{}
+	if list != nil {
+		masks = list.MarshalTOML().([]string)
+	}
+	data, err := json.Marshal(masks)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// updateNetRestrict applies a change to the current netrestrict list of the
+// server and persists the result.
+func (n *Node) updateNetRestrict(update func(netutil.Netlist) netutil.Netlist) error {
+	n.netrestrictMu.Lock()
+	defer n.netrestrictMu.Unlock()
+
+	server := n.Server()
+	if server == nil {
+		return ErrNodeStopped
+	}
+	var current netutil.Netlist
+	if list := server.CurrentNetRestrict(); list != nil {
+		current = slices.Clone(*list)
+	}
+	var next *netutil.Netlist
+	if updated := update(current); len(updated) > 0 {
+		next = &updated
+	}
+	server.SetNetRestrict(next)
+	return n.saveNetRestrict(next)
+}
+
+func parseNetlist(masks []string) (*netutil.Netlist, error) {
+	list := make(netutil.Netlist, 0, len(masks))
+	for _, mask := range masks {
+		prefix, err := netip.ParsePrefix(mask)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, prefix)
+	}
+	return &list, nil
+}
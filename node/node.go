@@ -30,6 +30,7 @@ import (
 	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/audit"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/rawdb"
@@ -47,24 +48,32 @@ type Node struct {
 	eventmux      *event.TypeMux
 	config        *Config
 	accman        *accounts.Manager
+	auditLog      *audit.Log // Signing audit log, nil if disabled
 	log           log.Logger
 	keyDir        string        // key store directory
 	keyDirTemp    bool          // If true, key directory will be removed by Stop
 	dirLock       *flock.Flock  // prevents concurrent use of instance directory
 	stop          chan struct{} // Channel to wait for termination notifications
 	server        *p2p.Server   // Currently running P2P networking layer
+	peerLists     *peerLists    // Watched static and trusted node files, if configured
+	netrestrictMu sync.Mutex    // Serializes runtime netrestrict changes
 	startStopLock sync.Mutex    // Start/Stop are protected by an additional lock
 	state         int           // Tracks state of node lifecycle
 
 	lock          sync.Mutex
-	lifecycles    []Lifecycle // All registered backends, services, and auxiliary services that have a lifecycle
-	rpcAPIs       []rpc.API   // List of APIs currently provided by the node
-	http          *httpServer //
-	ws            *httpServer //
-	httpAuth      *httpServer //
-	wsAuth        *httpServer //
-	ipc           *ipcServer  // Stores information about the ipc http server
-	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
+	lifecycles    []Lifecycle     // All registered backends, services, and auxiliary services that have a lifecycle
+	rpcAPIs       []rpc.API       // List of APIs currently provided by the node
+	http          *httpServer     //
+	ws            *httpServer     //
+	httpAuth      *httpServer     //
+	wsAuth        *httpServer     //
+	ipc           *ipcServer      // Stores information about the ipc http server
+	ipcExtra      []*ipcServer    // Additional IPC endpoints with restricted module sets
+	inprocHandler *rpc.Server     // In-process RPC request handler to process the API requests
+	cachePolicy   rpc.CachePolicy // Determines the RPC calls served from the response cache
+	auditFile     *os.File        // Destination of the RPC audit log, if enabled
+
+	rpcConfig rpcEndpointConfig // Settings of the HTTP endpoint, applied by NewRPCServer
 
 	databases map[*closeTrackingDB]struct{} // All open databases
 }
@@ -103,6 +112,17 @@ func New(conf *Config) (*Node, error) {
 	if strings.HasSuffix(conf.Name, ".ipc") {
 		return nil, errors.New(`Config.Name cannot end in ".ipc"`)
 	}
+	ipcModes := make([]os.FileMode, len(conf.IPCEndpoints))
+	for i, endpoint := range conf.IPCEndpoints {
+		if endpoint.Path == "" {
+			return nil, errors.New("IPC endpoint path must not be empty")
+		}
+		mode, err := endpoint.fileMode()
+		if err != nil {
+			return nil, err
+		}
+		ipcModes[i] = mode
+	}
 	server := rpc.NewServer()
 	server.SetBatchLimits(conf.BatchRequestLimit, conf.BatchResponseMaxSize)
 	node := &Node{
@@ -128,9 +148,25 @@ func New(conf *Config) (*Node, error) {
 	}
 	node.keyDir = keyDir
 	node.keyDirTemp = isEphem
+	accConf := &accounts.Config{
+		InsecureUnlockAllowed:  conf.InsecureUnlockAllowed,
+		StrictReplayProtection: conf.StrictReplayProtection,
+		ReplayProtected:        conf.ReplayProtectedAccounts,
+	}
+	if conf.SigningAuditLog != "" {
+		path := conf.ResolvePath(conf.SigningAuditLog)
+		if path == "" {
+			return nil, errors.New("relative signing audit log path requires a data directory")
+		}
+		if node.auditLog, err = audit.Open(path, conf.SigningAuditHashChain); err != nil {
+			return nil, fmt.Errorf("failed to open signing audit log: %v", err)
+		}
+		accConf.Auditor = node.auditLog
+		node.rpcAPIs = append(node.rpcAPIs, rpc.API{Namespace: "audit", Service: audit.NewAPI(node.auditLog)})
+	}
 	// Creates an empty AccountManager with no backends. Callers (e.g. cmd/geth)
 	// are required to add the backends later on.
-	node.accman = accounts.NewManager(&accounts.Config{InsecureUnlockAllowed: conf.InsecureUnlockAllowed})
+	node.accman = accounts.NewManager(accConf)
 
 	// Initialize the p2p server. This creates the node key and discovery databases.
 	node.server.Config.PrivateKey = node.config.NodeKey()
@@ -154,7 +190,12 @@ func New(conf *Config) (*Node, error) {
 	node.httpAuth = newHTTPServer(node.log, conf.HTTPTimeouts)
 	node.ws = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
 	node.wsAuth = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
-	node.ipc = newIPCServer(node.log, conf.IPCEndpoint())
+	node.ipc = newIPCServer(node.log, conf.IPCEndpoint(), conf.IPCAccessControl)
+	for i, endpoint := range conf.IPCEndpoints {
+		ipc := newIPCServer(node.log, conf.resolveIPCPath(endpoint.Path), endpoint.AccessControl)
+		ipc.modules, ipc.mode = endpoint.Modules, ipcModes[i]
+		node.ipcExtra = append(node.ipcExtra, ipc)
+	}
 
 	return node, nil
 }
@@ -241,6 +282,11 @@ func (n *Node) doClose(errs []error) error {
 	if err := n.accman.Close(); err != nil {
 		errs = append(errs, err)
 	}
+	if n.auditLog != nil {
+		if err := n.auditLog.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	if n.keyDirTemp {
 		if err := os.RemoveAll(n.keyDir); err != nil {
 			errs = append(errs, err)
@@ -268,18 +314,35 @@ func (n *Node) doClose(errs []error) error {
 func (n *Node) openEndpoints() error {
 	// start networking endpoints
 	n.log.Info("Starting peer-to-peer node", "instance", n.server.Name)
+	if list, ok, err := n.loadNetRestrict(); err != nil {
+		return err
+	} else if ok {
+		n.log.Info("Using netrestrict list set at runtime", "file", n.config.ResolvePath(datadirNetRestrict))
+		n.server.SetNetRestrict(list)
+	}
 	if err := n.server.Start(); err != nil {
 		return convertFileLockError(err)
 	}
+	if n.config.StaticNodesFile != "" || n.config.TrustedNodesFile != "" {
+		n.peerLists = newPeerLists(n.server, n.config.StaticNodesFile, n.config.TrustedNodesFile, n.log)
+		n.peerLists.start()
+	}
 	// start RPC endpoints
 	err := n.startRPC()
 	if err != nil {
 		n.stopRPC()
+		n.stopPeerLists()
 		n.server.Stop()
 	}
 	return err
 }
 
+func (n *Node) stopPeerLists() {
+	if n.peerLists != nil {
+		n.peerLists.stop()
+	}
+}
+
 // stopServices terminates running services, RPC and p2p networking.
 // It is the inverse of Start.
 func (n *Node) stopServices(running []Lifecycle) error {
@@ -294,6 +357,7 @@ func (n *Node) stopServices(running []Lifecycle) error {
 	}
 
 	// Stop p2p networking.
+	n.stopPeerLists()
 	n.server.Stop()
 
 	if len(failure.Services) > 0 {
@@ -397,26 +461,66 @@ func (n *Node) startRPC() error {
 			return err
 		}
 	}
+	for _, ipc := range n.ipcExtra {
+		if err := ipc.start(apis); err != nil {
+			return err
+		}
+	}
 	var (
 		servers           []*httpServer
 		openAPIs, allAPIs = n.getAPIs()
+		auditLog          *rpc.AuditLog
 	)
+	trustedProxies, err := rpc.ParseTrustedProxies(n.config.RPCTrustedProxies)
+	if err != nil {
+		return err
+	}
+	if n.config.RPCAuditLog != "" {
+		path := n.config.ResolvePath(n.config.RPCAuditLog)
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open RPC audit log: %w", err)
+		}
+		n.auditFile = file
+		auditLog = rpc.NewAuditLog(file, n.config.RPCAuditNamespaces)
+		n.log.Info("Recording RPC audit log", "path", path, "namespaces", n.config.RPCAuditNamespaces)
+	}
 
 	rpcConfig := rpcEndpointConfig{
 		batchItemLimit:         n.config.BatchRequestLimit,
 		batchResponseSizeLimit: n.config.BatchResponseMaxSize,
+		batchTimeout:           n.config.BatchTimeout,
+		methodTimeouts:         n.config.RPCMethodTimeouts,
+		rateLimit:              n.config.RPCRateLimit,
+		cacheSize:              uint64(n.config.RPCCacheSize) * 1024 * 1024,
+		cachePolicy:            n.cachePolicy,
+		auditLog:               auditLog,
+		trustedProxies:         trustedProxies,
 	}
+	n.rpcConfig = rpcConfig
+	n.rpcConfig.accessControl = n.config.HTTPAccessControl
 
 	initHttp := func(server *httpServer, port int) error {
 		if err := server.setListenAddr(n.config.HTTPHost, port); err != nil {
 			return err
 		}
+		endpointConfig := rpcConfig
+		endpointConfig.accessControl = n.config.HTTPAccessControl
+		if n.config.HTTPJWTSecret != "" {
+			secret, err := ObtainJWTSecret(n.config.HTTPJWTSecret)
+			if err != nil {
+				return err
+			}
+			endpointConfig.jwtSecret = secret
+		}
 		if err := server.enableRPC(openAPIs, httpConfig{
 			CorsAllowedOrigins: n.config.HTTPCors,
 			Vhosts:             n.config.HTTPVirtualHosts,
 			Modules:            n.config.HTTPModules,
 			prefix:             n.config.HTTPPathPrefix,
-			rpcEndpointConfig:  rpcConfig,
+
+			compressionThreshold: n.config.HTTPCompressionThreshold,
+			rpcEndpointConfig:    endpointConfig,
 		}); err != nil {
 			return err
 		}
@@ -429,11 +533,37 @@ func (n *Node) startRPC() error {
 		if err := server.setListenAddr(n.config.WSHost, port); err != nil {
 			return err
 		}
+		endpointConfig := rpcConfig
+		endpointConfig.accessControl = n.config.WSAccessControl
+		if n.config.WSJWTSecret != "" {
+			secret, err := ObtainJWTSecret(n.config.WSJWTSecret)
+			if err != nil {
+				return err
+			}
+			endpointConfig.jwtSecret = secret
+		}
 		if err := server.enableWS(openAPIs, wsConfig{
-			Modules:           n.config.WSModules,
-			Origins:           n.config.WSOrigins,
-			prefix:            n.config.WSPathPrefix,
-			rpcEndpointConfig: rpcConfig,
+			Modules:              n.config.WSModules,
+			Origins:              n.config.WSOrigins,
+			prefix:               n.config.WSPathPrefix,
+			compression:          n.config.WSCompression,
+			compressionLevel:     n.config.WSCompressionLevel,
+			compressionThreshold: n.config.WSCompressionThreshold,
+			subscriptionPolicy:   n.config.WSSubscriptionPolicy,
+			subscriptionBuffer:   n.config.WSSubscriptionBuffer,
+			timeouts: rpc.WebsocketTimeouts{
+				PingInterval: n.config.WSPingInterval,
+				PongTimeout:  n.config.WSPongTimeout,
+				WriteTimeout: n.config.WSWriteTimeout,
+				IdleTimeout:  n.config.WSIdleTimeout,
+			},
+			limits: rpc.WebsocketLimits{
+				MaxConnections:    n.config.WSMaxConnections,
+				MaxSubscriptions:  n.config.WSMaxSubscriptions,
+				MessagesPerSecond: n.config.WSMessageRate,
+				MessageBurst:      n.config.WSMessageBurst,
+			},
+			rpcEndpointConfig: endpointConfig,
 		}); err != nil {
 			return err
 		}
@@ -452,6 +582,7 @@ func (n *Node) startRPC() error {
 			batchItemLimit:         engineAPIBatchItemLimit,
 			batchResponseSizeLimit: engineAPIBatchResponseSizeLimit,
 			httpBodyLimit:          engineAPIBodyLimit,
+			auditLog:               auditLog,
 		}
 		err := server.enableRPC(allAPIs, httpConfig{
 			CorsAllowedOrigins: DefaultAuthCors,
@@ -508,6 +639,9 @@ func (n *Node) startRPC() error {
 	}
 	// Start the servers
 	for _, server := range servers {
+		if n.config.RPCProxyProtocol {
+			server.setProxyProtocol(trustedProxies)
+		}
 		if err := server.start(); err != nil {
 			return err
 		}
@@ -532,7 +666,14 @@ func (n *Node) stopRPC() {
 	n.httpAuth.stop()
 	n.wsAuth.stop()
 	n.ipc.stop()
+	for _, ipc := range n.ipcExtra {
+		ipc.stop()
+	}
 	n.stopInProc()
+	if n.auditFile != nil {
+		n.auditFile.Close()
+		n.auditFile = nil
+	}
 }
 
 // startInProc registers all RPC APIs on the inproc server.
@@ -591,6 +732,19 @@ func (n *Node) RegisterAPIs(apis []rpc.API) {
 	n.rpcAPIs = append(n.rpcAPIs, apis...)
 }
 
+// SetRPCCachePolicy sets the policy determining which calls on the HTTP and
+// websocket RPC endpoints have immutable results and may be served from the
+// response cache. The cache is only enabled if RPCCacheSize is configured.
+func (n *Node) SetRPCCachePolicy(policy rpc.CachePolicy) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.state != initializingState {
+		panic("can't set RPC cache policy on running/stopped node")
+	}
+	n.cachePolicy = policy
+}
+
 // getAPIs return two sets of APIs, both the ones that do not require
 // authentication, and the complete set
 func (n *Node) getAPIs() (unauthenticated, all []rpc.API) {
@@ -602,6 +756,16 @@ func (n *Node) getAPIs() (unauthenticated, all []rpc.API) {
 	return unauthenticated, n.rpcAPIs
 }
 
+// rpcServers returns the RPC servers of the running HTTP and WebSocket endpoints,
+// excluding the authenticated ones.
+func (n *Node) rpcServers() []*rpc.Server {
+	servers := n.http.rpcServers()
+	if n.ws != n.http {
+		servers = append(servers, n.ws.rpcServers()...)
+	}
+	return servers
+}
+
 // RegisterHandler mounts a handler on the given path on the canonical HTTP server.
 //
 // The name of the handler is shown in a log message when the HTTP server starts
@@ -634,6 +798,26 @@ func (n *Node) RPCHandler() (*rpc.Server, error) {
 	return n.inprocHandler, nil
 }
 
+// NewRPCServer creates an RPC server serving the given modules of the public APIs,
+// for endpoints of other protocols provided by lifecycles. The server is set up
+// like the HTTP endpoint: its access rules, rate limits, method timeouts, response
+// cache and audit log apply. It can only be created while the node is running, and
+// the caller is responsible for stopping it.
+func (n *Node) NewRPCServer(modules []string) (*rpc.Server, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.state != runningState {
+		return nil, ErrNodeStopped
+	}
+	openAPIs, _ := n.getAPIs()
+	srv := n.rpcConfig.newServer()
+	if err := RegisterApis(openAPIs, modules, srv); err != nil {
+		return nil, err
+	}
+	return srv, nil
+}
+
 // Config returns the configuration of node.
 func (n *Node) Config() *Config {
 	return n.config
@@ -197,6 +197,117 @@ func TestAuthEndpoints(t *testing.T) {
 	}
 }
 
+func TestAuthenticatedPublicEndpoints(t *testing.T) {
+	var secret [32]byte
+	if _, err := crand.Read(secret[:]); err != nil {
+		t.Fatalf("failed to create jwt secret: %v", err)
+	}
+	jwtPath := filepath.Join(t.TempDir(), "jwt_secret")
+	if err := os.WriteFile(jwtPath, []byte(hexutil.Encode(secret[:])), 0600); err != nil {
+		t.Fatalf("failed to prepare jwt secret file: %v", err)
+	}
+	// Protect the regular HTTP and WS endpoints with the secret, on separate ports
+	conf := &Config{
+		HTTPHost:      "127.0.0.1",
+		HTTPPort:      0,
+		HTTPJWTSecret: jwtPath,
+		WSHost:        "127.0.0.1",
+		WSPort:        0,
+		WSJWTSecret:   jwtPath,
+
+		WSModules:   []string{"eth", "engine"},
+		HTTPModules: []string{"eth", "engine"},
+	}
+	node, err := New(conf)
+	if err != nil {
+		t.Fatalf("could not create a new node: %v", err)
+	}
+	node.RegisterAPIs([]rpc.API{
+		{Namespace: "engine", Service: helloRPC("hello engine")},
+		{Namespace: "eth", Service: helloRPC("hello eth")},
+	})
+	if err := node.Start(); err != nil {
+		t.Fatalf("failed to start test node: %v", err)
+	}
+	defer node.Close()
+
+	var otherSecret [32]byte
+	if _, err := crand.Read(otherSecret[:]); err != nil {
+		t.Fatalf("failed to create jwt secret: %v", err)
+	}
+	goodAuth, badAuth := NewJWTAuth(secret), NewJWTAuth(otherSecret)
+	noAuth := func(header http.Header) error { return nil }
+
+	testCases := []authTest{
+		{name: "ws good", endpoint: node.WSEndpoint(), prov: goodAuth},
+		{name: "http good", endpoint: node.HTTPEndpoint(), prov: goodAuth},
+		{name: "ws bad", endpoint: node.WSEndpoint(), prov: badAuth, expectDialFail: true},
+		{name: "http bad", endpoint: node.HTTPEndpoint(), prov: badAuth, expectCall1Fail: true},
+		{name: "ws missing", endpoint: node.WSEndpoint(), prov: noAuth, expectDialFail: true},
+		{name: "http missing", endpoint: node.HTTPEndpoint(), prov: noAuth, expectCall1Fail: true},
+		{name: "ws none", endpoint: node.WSEndpoint(), prov: noneAuth(secret), expectDialFail: true},
+		{name: "http none", endpoint: node.HTTPEndpoint(), prov: noneAuth(secret), expectCall1Fail: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, testCase.Run)
+	}
+}
+
+func TestScopedAuthEndpoints(t *testing.T) {
+	var secret [32]byte
+	if _, err := crand.Read(secret[:]); err != nil {
+		t.Fatalf("failed to create jwt secret: %v", err)
+	}
+	jwtPath := filepath.Join(t.TempDir(), "jwt_secret")
+	if err := os.WriteFile(jwtPath, []byte(hexutil.Encode(secret[:])), 0600); err != nil {
+		t.Fatalf("failed to prepare jwt secret file: %v", err)
+	}
+	conf := &Config{AuthAddr: "127.0.0.1", AuthPort: 0, JWTSecret: jwtPath}
+	node, err := New(conf)
+	if err != nil {
+		t.Fatalf("could not create a new node: %v", err)
+	}
+	node.RegisterAPIs([]rpc.API{
+		{Namespace: "engine", Service: helloRPC("hello engine"), Authenticated: true},
+		{Namespace: "account", Service: helloRPC("hello account"), Authenticated: true},
+	})
+	if err := node.Start(); err != nil {
+		t.Fatalf("failed to start test node: %v", err)
+	}
+	defer node.Close()
+
+	tests := []struct {
+		scopes  []string
+		allowed map[string]bool
+	}{
+		{nil, map[string]bool{"engine_helloWorld": true, "account_helloWorld": true}},
+		{[]string{"account"}, map[string]bool{"account_helloWorld": true}},
+		{[]string{"engine_helloWorld", "account_*"}, map[string]bool{"engine_helloWorld": true, "account_helloWorld": true}},
+		{[]string{"account_sign"}, map[string]bool{}},
+		{[]string{}, map[string]bool{}},
+	}
+	for _, endpoint := range []string{node.HTTPAuthEndpoint(), node.WSAuthEndpoint()} {
+		for i, test := range tests {
+			auth := NewJWTAuth(secret)
+			if test.scopes != nil {
+				auth = NewScopedJWTAuth(secret, test.scopes)
+			}
+			cl, err := rpc.DialOptions(context.Background(), endpoint, rpc.WithHTTPAuth(auth))
+			if err != nil {
+				t.Fatalf("failed to dial %s: %v", endpoint, err)
+			}
+			for _, method := range []string{"engine_helloWorld", "account_helloWorld"} {
+				var x string
+				err := cl.Call(&x, method)
+				if allowed := test.allowed[method]; allowed != (err == nil) {
+					t.Errorf("%s test %d: wrong result of %s with scopes %v: %v", endpoint, i, method, test.scopes, err)
+				}
+			}
+			cl.Close()
+		}
+	}
+}
+
 func noneAuth(secret [32]byte) rpc.HTTPAuth {
 	return func(header http.Header) error {
 		token := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
@@ -22,7 +22,10 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"os"
+	"path/filepath"
 	"reflect"
+	"runtime"
 	"slices"
 	"strings"
 	"testing"
@@ -570,6 +573,69 @@ func (test rpcPrefixTest) check(t *testing.T, node *Node) {
 	}
 }
 
+// Tests that additional IPC endpoints only expose their configured modules with
+// the requested file permissions.
+func TestNodeExtraIPCEndpoints(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("socket permissions are not supported on windows")
+	}
+	dir := t.TempDir()
+	conf := &Config{
+		DataDir: dir,
+		IPCEndpoints: []IPCEndpointConfig{
+			{Path: filepath.Join(dir, "ro.ipc"), Modules: []string{"web3"}, Permissions: "0660"},
+			{Path: filepath.Join(dir, "admin.ipc")},
+		},
+	}
+	node, err := New(conf)
+	if err != nil {
+		t.Fatalf("could not create node: %v", err)
+	}
+	defer node.Close()
+	if err := node.Start(); err != nil {
+		t.Fatalf("could not start node: %v", err)
+	}
+	tests := []struct {
+		path      string
+		mode      os.FileMode
+		adminOpen bool
+	}{
+		{filepath.Join(dir, "ro.ipc"), 0660, false},
+		{filepath.Join(dir, "admin.ipc"), 0600, true},
+	}
+	for _, tt := range tests {
+		info, err := os.Stat(tt.path)
+		if err != nil {
+			t.Fatalf("%s: missing socket: %v", tt.path, err)
+		}
+		if mode := info.Mode().Perm(); mode != tt.mode {
+			t.Errorf("%s: wrong permissions: have %o, want %o", tt.path, mode, tt.mode)
+		}
+		client, err := rpc.Dial(tt.path)
+		if err != nil {
+			t.Fatalf("%s: could not dial: %v", tt.path, err)
+		}
+		var version string
+		if err := client.Call(&version, "web3_clientVersion"); err != nil {
+			t.Errorf("%s: web3_clientVersion failed: %v", tt.path, err)
+		}
+		var nodeInfo interface{}
+		err = client.Call(&nodeInfo, "admin_nodeInfo")
+		if tt.adminOpen && err != nil {
+			t.Errorf("%s: admin_nodeInfo failed: %v", tt.path, err)
+		}
+		if !tt.adminOpen && err == nil {
+			t.Errorf("%s: admin_nodeInfo available on restricted endpoint", tt.path)
+		}
+		client.Close()
+	}
+	// Invalid permissions are rejected on creation
+	conf = &Config{IPCEndpoints: []IPCEndpointConfig{{Path: "bad.ipc", Permissions: "0999"}}}
+	if _, err := New(conf); err == nil {
+		t.Fatal("node created with invalid IPC permissions")
+	}
+}
+
 func createNode(t *testing.T, httpPort, wsPort int) *Node {
 	conf := &Config{
 		HTTPHost:     "127.0.0.1",
@@ -0,0 +1,236 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// peerListReloadInterval is the interval of checking peer list files for changes.
+const peerListReloadInterval = 3 * time.Second
+
+var errNoPeerLists = errors.New("no peer list files configured")
+
+// peerList is a list of nodes kept in sync with a JSON file of enode URLs.
+type peerList struct {
+	name    string
+	path    string
+	modTime time.Time
+	nodes   map[enode.ID]*enode.Node
+	add     func(*enode.Node)
+	remove  func(*enode.Node)
+}
+
+// peerLists applies changes of the static and trusted node files to the p2p
+// server at runtime, and persists changes made through the admin API.
+type peerLists struct {
+	mu    sync.Mutex
+	lists []*peerList // static, trusted
+	log   log.Logger
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newPeerLists(server *p2p.Server, staticFile, trustedFile string, logger log.Logger) *peerLists {
+	pl := &peerLists{log: logger, quit: make(chan struct{})}
+	for _, l := range []*peerList{
+		{name: "static", path: staticFile, add: server.AddPeer, remove: server.RemovePeer},
+		{name: "trusted", path: trustedFile, add: server.AddTrustedPeer, remove: server.RemoveTrustedPeer},
+	} {
+		l.nodes = make(map[enode.ID]*enode.Node)
+		pl.lists = append(pl.lists, l)
+	}
+	return pl
+}
+
+// start loads the peer lists and starts watching the files for changes.
+func (pl *peerLists) start() {
+	pl.reload()
+	pl.wg.Add(1)
+	go pl.loop()
+}
+
+func (pl *peerLists) stop() {
+	close(pl.quit)
+	pl.wg.Wait()
+}
+
+func (pl *peerLists) loop() {
+	defer pl.wg.Done()
+
+	ticker := time.NewTicker(peerListReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			pl.reload()
+		case <-pl.quit:
+			return
+		}
+	}
+}
+
+// reload applies the changes of all modified files.
+func (pl *peerLists) reload() {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+
+	for _, l := range pl.lists {
+		if l.path == "" {
+			continue
+		}
+		stat, err := os.Stat(l.path)
+		if err != nil || stat.ModTime().Equal(l.modTime) {
+			continue
+		}
+		nodes, err := loadPeerList(l.path)
+		if err != nil {
+			pl.log.Error("Failed to load peer list", "list", l.name, "file", l.path, "err", err)
+			continue
+		}
+		l.modTime = stat.ModTime()
+		pl.apply(l, nodes)
+	}
+}
+
+// apply updates a list to the given nodes, adding and removing the differences
+// on the server.
+func (pl *peerLists) apply(l *peerList, nodes []*enode.Node) {
+	var added, removed int
+	next := make(map[enode.ID]*enode.Node, len(nodes))
+	for _, n := range nodes {
+		next[n.ID()] = n
+		if old := l.nodes[n.ID()]; old == nil || old.URLv4() != n.URLv4() {
+			l.add(n)
+			added++
+		}
+	}
+	for id, n := range l.nodes {
+		if next[id] == nil {
+			l.remove(n)
+			removed++
+		}
+	}
+	l.nodes = next
+	if added > 0 || removed > 0 {
+		pl.log.Info("Applied peer list changes", "list", l.name, "added", added, "removed", removed)
+	}
+}
+
+// update records a change made to the server through the admin API.
+func (pl *peerLists) update(trusted bool, n *enode.Node, add bool) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+
+	l := pl.lists[0]
+	if trusted {
+		l = pl.lists[1]
+	}
+	if add {
+		l.nodes[n.ID()] = n
+	} else {
+		delete(l.nodes, n.ID())
+	}
+}
+
+// save writes the current lists to their files.
+func (pl *peerLists) save() error {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+
+	var saved bool
+	for _, l := range pl.lists {
+		if l.path == "" {
+			continue
+		}
+		urls := make([]string, 0, len(l.nodes))
+		for _, n := range l.nodes {
+			urls = append(urls, n.URLv4())
+		}
+		slices.Sort(urls)
+		if err := writePeerList(l.path, urls); err != nil {
+			return err
+		}
+		// Don't reapply our own changes.
+		if stat, err := os.Stat(l.path); err == nil {
+			l.modTime = stat.ModTime()
+		}
+		saved = true
+	}
+	if !saved {
+		return errNoPeerLists
+	}
+	return nil
+}
+
+// loadPeerList reads a JSON list of enode URLs. Invalid entries are skipped.
+func loadPeerList(path string) ([]*enode.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var urls []string
+	if err := json.Unmarshal(data, &urls); err != nil {
+		return nil, err
+	}
+	nodes := make([]*enode.Node, 0, len(urls))
+	for _, url := range urls {
+		if url == "" {
+			continue
+		}
+		n, err := enode.Parse(enode.ValidSchemes, url)
+		if err != nil {
+			log.Error("Invalid peer list entry", "file", path, "url", url, "err", err)
+			continue
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+// writePeerList atomically replaces the file with the given URLs.
+func writePeerList(path string, urls []string) error {
+	data, err := json.MarshalIndent(urls, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
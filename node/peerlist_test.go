@@ -0,0 +1,98 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func TestPeerLists(t *testing.T) {
+	var (
+		path    = filepath.Join(t.TempDir(), "static.json")
+		nodes   = make([]*enode.Node, 3)
+		current = make(map[enode.ID]bool)
+		pl      = &peerLists{log: log.Root(), quit: make(chan struct{})}
+	)
+	for i := range nodes {
+		key, _ := crypto.GenerateKey()
+		nodes[i] = enode.NewV4(&key.PublicKey, nil, 30303+i, 30303+i)
+	}
+	pl.lists = []*peerList{
+		{
+			name:   "static",
+			path:   path,
+			nodes:  make(map[enode.ID]*enode.Node),
+			add:    func(n *enode.Node) { current[n.ID()] = true },
+			remove: func(n *enode.Node) { delete(current, n.ID()) },
+		},
+		{name: "trusted", nodes: make(map[enode.ID]*enode.Node)},
+	}
+	write := func(nodes ...*enode.Node) {
+		var urls []string
+		for _, n := range nodes {
+			urls = append(urls, n.URLv4())
+		}
+		if err := writePeerList(path, urls); err != nil {
+			t.Fatal(err)
+		}
+		// Make sure the modification is detected on coarse-grained file systems.
+		mtime := time.Now().Add(time.Duration(len(nodes)) * time.Second)
+		os.Chtimes(path, mtime, mtime)
+	}
+	check := func(want ...*enode.Node) {
+		t.Helper()
+		if len(current) != len(want) {
+			t.Fatalf("wrong peers: have %v, want %d", current, len(want))
+		}
+		for _, n := range want {
+			if !current[n.ID()] {
+				t.Fatalf("missing peer %v", n.ID())
+			}
+		}
+	}
+	write(nodes[0], nodes[1])
+	pl.reload()
+	check(nodes[0], nodes[1])
+
+	write(nodes[1], nodes[2], nodes[2])
+	pl.reload()
+	check(nodes[1], nodes[2])
+
+	// Runtime changes are written back to the file.
+	pl.update(false, nodes[0], true)
+	pl.update(false, nodes[1], false)
+	if err := pl.save(); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := loadPeerList(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 2 || pl.lists[0].nodes[saved[0].ID()] == nil || pl.lists[0].nodes[saved[1].ID()] == nil {
+		t.Fatalf("wrong saved peers: %v", saved)
+	}
+	if pl.lists[0].nodes[nodes[0].ID()] == nil || pl.lists[0].nodes[nodes[2].ID()] == nil {
+		t.Fatal("runtime changes not recorded")
+	}
+}
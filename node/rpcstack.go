@@ -24,6 +24,9 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"os"
+	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -31,6 +34,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/andybalholm/brotli"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/rs/cors"
@@ -42,6 +46,8 @@ type httpConfig struct {
 	CorsAllowedOrigins []string
 	Vhosts             []string
 	prefix             string // path prefix on which to mount http handler
+
+	compressionThreshold int // minimum size of compressed responses
 	rpcEndpointConfig
 }
 
@@ -50,6 +56,16 @@ type wsConfig struct {
 	Origins []string
 	Modules []string
 	prefix  string // path prefix on which to mount ws handler
+
+	compression          bool // whether to negotiate permessage-deflate
+	compressionLevel     int  // compress/flate level of compressed messages
+	compressionThreshold int  // minimum size of compressed messages
+
+	subscriptionPolicy rpc.BackpressurePolicy // handling of notifications to slow subscribers
+	subscriptionBuffer int                    // notifications queued per subscription
+
+	timeouts rpc.WebsocketTimeouts // keepalive and idle reaping of connections
+	limits   rpc.WebsocketLimits   // caps on connections, subscriptions and message rate
 	rpcEndpointConfig
 }
 
@@ -57,7 +73,34 @@ type rpcEndpointConfig struct {
 	jwtSecret              []byte // optional JWT secret
 	batchItemLimit         int
 	batchResponseSizeLimit int
+	batchTimeout           time.Duration
+	methodTimeouts         map[string]time.Duration // optional per-method execution deadlines
 	httpBodyLimit          int
+	accessControl          *rpc.AccessControl // optional per-method access rules
+	rateLimit              *rpc.RateLimit     // optional per-client request quotas
+	cacheSize              uint64             // memory allowance of the response cache
+	cachePolicy            rpc.CachePolicy    // determines the cacheable calls
+	auditLog               *rpc.AuditLog      // optional record of served calls
+	trustedProxies         rpc.TrustedProxies // proxies trusted to identify clients
+}
+
+// newServer creates an RPC server applying the settings of the endpoint.
+func (config *rpcEndpointConfig) newServer() *rpc.Server {
+	srv := rpc.NewServer()
+	srv.SetBatchLimits(config.batchItemLimit, config.batchResponseSizeLimit)
+	srv.SetBatchTimeout(config.batchTimeout)
+	srv.SetMethodTimeouts(config.methodTimeouts)
+	if config.httpBodyLimit > 0 {
+		srv.SetHTTPBodyLimit(config.httpBodyLimit)
+	}
+	srv.SetAccessControl(config.accessControl)
+	if config.rateLimit != nil {
+		srv.SetRateLimit(*config.rateLimit)
+	}
+	srv.SetResponseCache(config.cacheSize, config.cachePolicy)
+	srv.SetAuditLog(config.auditLog)
+	srv.SetTrustedProxies(config.trustedProxies)
+	return srv
 }
 
 type rpcHandler struct {
@@ -74,6 +117,9 @@ type httpServer struct {
 	server   *http.Server
 	listener net.Listener // non-nil when server is running
 
+	// proxyProtocol are the proxies whose PROXY protocol headers are accepted.
+	proxyProtocol rpc.TrustedProxies
+
 	// HTTP RPC handler things.
 
 	httpConfig  httpConfig
@@ -118,6 +164,15 @@ func (h *httpServer) setListenAddr(host string, port int) error {
 	return nil
 }
 
+// setProxyProtocol configures the proxies permitted to announce clients using the
+// PROXY protocol. It takes effect when the server is started.
+func (h *httpServer) setProxyProtocol(proxies rpc.TrustedProxies) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.proxyProtocol = proxies
+}
+
 // listenAddr returns the listening address of the server.
 func (h *httpServer) listenAddr() string {
 	h.mu.Lock()
@@ -157,6 +212,9 @@ func (h *httpServer) start() error {
 		h.disableWS()
 		return err
 	}
+	if len(h.proxyProtocol) > 0 {
+		listener = rpc.NewProxyProtocolListener(listener, h.proxyProtocol)
+	}
 	h.listener = listener
 	go h.server.Serve(listener)
 
@@ -197,12 +255,11 @@ func (h *httpServer) start() error {
 }
 
 func (h *httpServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// check if ws request and serve if ws enabled
+	// check if ws request and serve if ws enabled. Websocket requests to other
+	// paths fall through to the handlers registered on the mux.
 	ws := h.wsHandler.Load().(*rpcHandler)
-	if ws != nil && isWebsocket(r) {
-		if checkPath(r, h.wsConfig.prefix) {
-			ws.ServeHTTP(w, r)
-		}
+	if ws != nil && isWebsocket(r) && checkPath(r, h.wsConfig.prefix) {
+		ws.ServeHTTP(w, r)
 		return
 	}
 
@@ -304,22 +361,65 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	}
 
 	// Create RPC server and handler.
-	srv := rpc.NewServer()
-	srv.SetBatchLimits(config.batchItemLimit, config.batchResponseSizeLimit)
-	if config.httpBodyLimit > 0 {
-		srv.SetHTTPBodyLimit(config.httpBodyLimit)
-	}
+	srv := config.newServer()
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
 	h.httpConfig = config
 	h.httpHandler.Store(&rpcHandler{
-		Handler: NewHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts, config.jwtSecret),
+		Handler: newHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts, config.jwtSecret, config.compressionThreshold),
 		server:  srv,
 	})
 	return nil
 }
 
+// rpcServers returns the RPC servers of the enabled HTTP and WebSocket handlers.
+func (h *httpServer) rpcServers() []*rpc.Server {
+	var servers []*rpc.Server
+	if handler := h.httpHandler.Load().(*rpcHandler); handler != nil {
+		servers = append(servers, handler.server)
+	}
+	if handler := h.wsHandler.Load().(*rpcHandler); handler != nil {
+		servers = append(servers, handler.server)
+	}
+	return servers
+}
+
+// httpFilters returns the CORS origins and virtual hosts of the HTTP RPC handler.
+func (h *httpServer) httpFilters() ([]string, []string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.rpcAllowed() {
+		return nil, nil, errors.New("JSON-RPC over HTTP is not enabled")
+	}
+	return slices.Clone(h.httpConfig.CorsAllowedOrigins), slices.Clone(h.httpConfig.Vhosts), nil
+}
+
+// setHTTPFilters replaces the CORS origins and virtual hosts of the running HTTP
+// RPC handler. Nil values leave the respective setting unchanged. The handler is
+// swapped atomically, so requests in flight are not interrupted.
+func (h *httpServer) setHTTPFilters(cors, vhosts *[]string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	handler := h.httpHandler.Load().(*rpcHandler)
+	if handler == nil {
+		return errors.New("JSON-RPC over HTTP is not enabled")
+	}
+	if cors != nil {
+		h.httpConfig.CorsAllowedOrigins = slices.Clone(*cors)
+	}
+	if vhosts != nil {
+		h.httpConfig.Vhosts = slices.Clone(*vhosts)
+	}
+	h.httpHandler.Store(&rpcHandler{
+		Handler: newHTTPHandlerStack(handler.server, h.httpConfig.CorsAllowedOrigins, h.httpConfig.Vhosts, h.httpConfig.jwtSecret, h.httpConfig.compressionThreshold),
+		server:  handler.server,
+	})
+	return nil
+}
+
 // disableRPC stops the HTTP RPC handler. This is internal, the caller must hold h.mu.
 func (h *httpServer) disableRPC() bool {
 	handler := h.httpHandler.Load().(*rpcHandler)
@@ -339,11 +439,15 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 		return errors.New("JSON-RPC over WebSocket is already enabled")
 	}
 	// Create RPC server and handler.
-	srv := rpc.NewServer()
-	srv.SetBatchLimits(config.batchItemLimit, config.batchResponseSizeLimit)
-	if config.httpBodyLimit > 0 {
-		srv.SetHTTPBodyLimit(config.httpBodyLimit)
+	srv := config.newServer()
+	if config.compression {
+		if err := srv.SetWebsocketCompression(config.compressionLevel, config.compressionThreshold); err != nil {
+			return err
+		}
 	}
+	srv.SetSubscriptionBackpressure(config.subscriptionPolicy, config.subscriptionBuffer)
+	srv.SetWebsocketTimeouts(config.timeouts)
+	srv.SetWebsocketLimits(config.limits)
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
@@ -395,13 +499,19 @@ func isWebsocket(r *http.Request) bool {
 
 // NewHTTPHandlerStack returns wrapped http-related handlers
 func NewHTTPHandlerStack(srv http.Handler, cors []string, vhosts []string, jwtSecret []byte) http.Handler {
+	return newHTTPHandlerStack(srv, cors, vhosts, jwtSecret, 0)
+}
+
+// newHTTPHandlerStack returns wrapped http-related handlers, compressing responses
+// of at least the given size.
+func newHTTPHandlerStack(srv http.Handler, cors []string, vhosts []string, jwtSecret []byte, compressionThreshold int) http.Handler {
 	// Wrap the CORS-handler within a host-handler
 	handler := newCorsHandler(srv, cors)
 	handler = newVHostHandler(vhosts, handler)
 	if len(jwtSecret) != 0 {
 		handler = newJWTHandler(jwtSecret, handler)
 	}
-	return newGzipHandler(handler)
+	return newCompressionHandler(handler, compressionThreshold)
 }
 
 // NewWSHandlerStack returns a wrapped ws-related handler.
@@ -472,26 +582,78 @@ func (h *virtualHostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	http.Error(w, "invalid host specified", http.StatusForbidden)
 }
 
-var gzPool = sync.Pool{
-	New: func() interface{} {
-		w := gzip.NewWriter(io.Discard)
-		return w
-	},
+var (
+	gzPool = sync.Pool{
+		New: func() interface{} {
+			return gzip.NewWriter(io.Discard)
+		},
+	}
+	brPool = sync.Pool{
+		New: func() interface{} {
+			// Level 4 compresses better than gzip at a comparable speed, the
+			// default level is too slow for dynamic responses.
+			return brotli.NewWriterLevel(io.Discard, 4)
+		},
+	}
+)
+
+// compressor is a streaming encoder of compressed responses.
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
 }
 
-type gzipResponseWriter struct {
-	resp http.ResponseWriter
+// negotiateEncoding picks the compression of a response from the Accept-Encoding
+// header of the request. Brotli is preferred over gzip at equal weights. An empty
+// string is returned if the client accepts neither.
+func negotiateEncoding(accept string) string {
+	var (
+		best  string
+		bestQ float64
+	)
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "br" && name != "gzip" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > bestQ || (q > 0 && q == bestQ && name == "br") {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
 
-	gz            *gzip.Writer
+type compressResponseWriter struct {
+	resp     http.ResponseWriter
+	encoding string     // negotiated content encoding
+	pool     *sync.Pool // pool of the encoders of the content encoding
+	minSize  int        // minimum size of compressed responses
+
+	enc           compressor
+	buf           []byte // uncompressed output held back until the minimum size is reached
+	status        int    // status code held back with the output
 	contentLength uint64 // total length of the uncompressed response
 	written       uint64 // amount of written bytes from the uncompressed response
 	hasLength     bool   // true if uncompressed response had Content-Length
 	inited        bool   // true after init was called for the first time
+	decided       bool   // true once the headers are written and compression is decided
+	closed        bool   // true once the compressed stream is terminated
 }
 
-// init runs just before response headers are written. Among other things, this function
-// also decides whether compression will be applied at all.
-func (w *gzipResponseWriter) init() {
+// init runs when the handler first writes a header or data. Among other things, this
+// function decides whether compression will be applied at all, if the response isn't
+// buffered until it reaches the minimum size.
+func (w *compressResponseWriter) init() {
 	if w.inited {
 		return
 	}
@@ -500,7 +662,7 @@ func (w *gzipResponseWriter) init() {
 	hdr := w.resp.Header()
 	length := hdr.Get("content-length")
 	if len(length) > 0 {
-		if n, err := strconv.ParseUint(length, 10, 64); err != nil {
+		if n, err := strconv.ParseUint(length, 10, 64); err == nil {
 			w.hasLength = true
 			w.contentLength = n
 		}
@@ -509,75 +671,136 @@ func (w *gzipResponseWriter) init() {
 	// Setting Transfer-Encoding to "identity" explicitly disables compression. net/http
 	// also recognizes this header value and uses it to disable "chunked" transfer
 	// encoding, trimming the header from the response. This means downstream handlers can
-	// set this without harm, even if they aren't wrapped by newGzipHandler.
+	// set this without harm, even if they aren't wrapped by newCompressionHandler.
 	//
 	// In go-ethereum, we use this signal to disable compression for certain error
 	// responses which are flushed out close to the write deadline of the response. For
 	// these cases, we want to avoid chunked transfer encoding and compression because
 	// they require additional output that may not get written in time.
 	passthrough := hdr.Get("transfer-encoding") == "identity"
-	if !passthrough {
-		w.gz = gzPool.Get().(*gzip.Writer)
-		w.gz.Reset(w.resp)
+	switch {
+	case passthrough, w.hasLength && w.contentLength < uint64(w.minSize):
+		w.decide(false)
+	case w.minSize == 0, w.hasLength:
+		w.decide(true)
+	}
+}
+
+// decide writes the response headers, enabling compression if requested, and
+// releases the output held back so far.
+func (w *compressResponseWriter) decide(compress bool) error {
+	w.decided = true
+	if compress {
+		w.enc = w.pool.Get().(compressor)
+		w.enc.Reset(w.resp)
+		hdr := w.resp.Header()
 		hdr.Del("content-length")
-		hdr.Set("content-encoding", "gzip")
+		hdr.Set("content-encoding", w.encoding)
+		hdr.Add("vary", "Accept-Encoding")
+	}
+	if w.status != 0 {
+		w.resp.WriteHeader(w.status)
 	}
+	if len(w.buf) == 0 {
+		return nil
+	}
+	buf := w.buf
+	w.buf = nil
+	_, err := w.write(buf)
+	return err
 }
 
-func (w *gzipResponseWriter) Header() http.Header {
+func (w *compressResponseWriter) Header() http.Header {
 	return w.resp.Header()
 }
 
-func (w *gzipResponseWriter) WriteHeader(status int) {
+func (w *compressResponseWriter) WriteHeader(status int) {
 	w.init()
-	w.resp.WriteHeader(status)
+	if w.decided {
+		w.resp.WriteHeader(status)
+	} else {
+		w.status = status
+	}
 }
 
-func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+func (w *compressResponseWriter) Write(b []byte) (int, error) {
 	w.init()
 
-	if w.gz == nil {
+	if !w.decided {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) < w.minSize {
+			return len(b), nil
+		}
+		return len(b), w.decide(true)
+	}
+	return w.write(b)
+}
+
+func (w *compressResponseWriter) write(b []byte) (int, error) {
+	if w.enc == nil {
 		// Compression is disabled.
 		return w.resp.Write(b)
 	}
 
-	n, err := w.gz.Write(b)
+	n, err := w.enc.Write(b)
 	w.written += uint64(n)
-	if w.hasLength && w.written >= w.contentLength {
+	if w.hasLength && w.written >= w.contentLength && err == nil {
 		// The HTTP handler has finished writing the entire uncompressed response. Close
-		// the gzip stream to ensure the footer will be seen by the client in case the
-		// response is flushed after this call to write.
-		err = w.gz.Close()
+		// the compressed stream to ensure the footer will be seen by the client in case
+		// the response is flushed after this call to write.
+		w.closed = true
+		err = w.enc.Close()
 	}
 	return n, err
 }
 
-func (w *gzipResponseWriter) Flush() {
-	if w.gz != nil {
-		w.gz.Flush()
+// Flush sends the output written so far. If compression is undecided yet, it is
+// only applied if the output reached the minimum size.
+func (w *compressResponseWriter) Flush() {
+	if !w.decided {
+		w.init()
+		if !w.decided {
+			w.decide(len(w.buf) >= w.minSize)
+		}
+	}
+	if w.enc != nil && !w.closed {
+		w.enc.Flush()
 	}
 	if f, ok := w.resp.(http.Flusher); ok {
 		f.Flush()
 	}
 }
 
-func (w *gzipResponseWriter) close() {
-	if w.gz == nil {
+func (w *compressResponseWriter) close() {
+	if w.inited && !w.decided {
+		// The response is smaller than the minimum size.
+		w.decide(false)
+	}
+	if w.enc == nil {
 		return
 	}
-	w.gz.Close()
-	gzPool.Put(w.gz)
-	w.gz = nil
+	if !w.closed {
+		w.enc.Close()
+	}
+	w.pool.Put(w.enc)
+	w.enc = nil
 }
 
-func newGzipHandler(next http.Handler) http.Handler {
+// newCompressionHandler compresses the responses of the given handler with gzip
+// or brotli, as accepted by the client. Responses smaller than minSize are sent
+// uncompressed.
+func newCompressionHandler(next http.Handler, minSize int) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" {
 			next.ServeHTTP(w, r)
 			return
 		}
-
-		wrapper := &gzipResponseWriter{resp: w}
+		pool := &gzPool
+		if encoding == "br" {
+			pool = &brPool
+		}
+		wrapper := &compressResponseWriter{resp: w, encoding: encoding, pool: pool, minSize: minSize}
 		defer wrapper.close()
 
 		next.ServeHTTP(wrapper, r)
@@ -591,10 +814,14 @@ type ipcServer struct {
 	mu       sync.Mutex
 	listener net.Listener
 	srv      *rpc.Server
+
+	accessControl *rpc.AccessControl // optional per-method access rules
+	modules       []string           // exposed API modules, all if empty
+	mode          os.FileMode        // optional file permissions of the socket
 }
 
-func newIPCServer(log log.Logger, endpoint string) *ipcServer {
-	return &ipcServer{log: log, endpoint: endpoint}
+func newIPCServer(log log.Logger, endpoint string, accessControl *rpc.AccessControl) *ipcServer {
+	return &ipcServer{log: log, endpoint: endpoint, accessControl: accessControl}
 }
 
 // start starts the httpServer's http.Server
@@ -605,11 +832,32 @@ func (is *ipcServer) start(apis []rpc.API) error {
 	if is.listener != nil {
 		return nil // already running
 	}
-	listener, srv, err := rpc.StartIPCEndpoint(is.endpoint, apis)
+	srv := rpc.NewServer()
+	srv.SetAccessControl(is.accessControl)
+	if bad, available := checkModuleAvailability(is.modules, apis); len(bad) > 0 {
+		is.log.Error("Unavailable modules in IPC API list", "url", is.endpoint, "unavailable", bad, "available", available)
+	}
+	for _, api := range apis {
+		if len(is.modules) > 0 && !slices.Contains(is.modules, api.Namespace) {
+			continue
+		}
+		if err := srv.RegisterName(api.Namespace, api.Service); err != nil {
+			is.log.Info("IPC registration failed", "namespace", api.Namespace, "error", err)
+			return err
+		}
+	}
+	listener, err := srv.ServeIPCEndpoint(is.endpoint)
 	if err != nil {
 		is.log.Warn("IPC opening failed", "url", is.endpoint, "error", err)
 		return err
 	}
+	if is.mode != 0 && runtime.GOOS != "windows" {
+		if err := os.Chmod(is.endpoint, is.mode); err != nil {
+			listener.Close()
+			srv.Stop()
+			return err
+		}
+	}
 	is.log.Info("IPC endpoint opened", "url", is.endpoint)
 	is.listener, is.srv = listener, srv
 	return nil
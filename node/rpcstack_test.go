@@ -18,6 +18,7 @@ package node
 
 import (
 	"bytes"
+	"compress/gzip"
 	"fmt"
 	"io"
 	"net/http"
@@ -28,6 +29,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/andybalholm/brotli"
 	"github.com/ethereum/go-ethereum/internal/testlog"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -524,7 +526,7 @@ func TestGzipHandler(t *testing.T) {
 	for _, test := range tests {
 		test := test
 		t.Run(test.name, func(t *testing.T) {
-			srv := httptest.NewServer(newGzipHandler(test.handler))
+			srv := httptest.NewServer(newCompressionHandler(test.handler, 0))
 			defer srv.Close()
 
 			resp, err := http.Get(srv.URL)
@@ -557,6 +559,141 @@ func TestGzipHandler(t *testing.T) {
 	}
 }
 
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"gzip, deflate, br", "br"},
+		{"br;q=0.5, gzip", "gzip"},
+		{"br;q=0, gzip;q=0", ""},
+		{"GZIP;q=0.8, br;q=0.8", "br"},
+		{"br;q=invalid, gzip;q=0.1", "gzip"},
+	}
+	for _, tt := range tests {
+		if have := negotiateEncoding(tt.accept); have != tt.want {
+			t.Errorf("accept %q: have %q, want %q", tt.accept, have, tt.want)
+		}
+	}
+}
+
+func TestCompressionHandlerThreshold(t *testing.T) {
+	var (
+		small = strings.Repeat("a", 99)
+		large = strings.Repeat("b", 100)
+	)
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		accept   string
+		encoding string
+		body     string
+		status   int
+	}{
+		{
+			name:    "small",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(small)) },
+			accept:  "gzip",
+			body:    small,
+			status:  200,
+		},
+		{
+			name: "small-WriteHeader",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(202)
+				w.Write([]byte(small[:50]))
+				w.Write([]byte(small[50:]))
+			},
+			accept: "gzip",
+			body:   small,
+			status: 202,
+		},
+		{
+			name:     "large-gzip",
+			handler:  func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(large)) },
+			accept:   "gzip",
+			encoding: "gzip",
+			body:     large,
+			status:   200,
+		},
+		{
+			name: "large-br-chunks",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(201)
+				w.Write([]byte(large[:60]))
+				w.Write([]byte(large[60:]))
+			},
+			accept:   "gzip, br",
+			encoding: "br",
+			body:     large,
+			status:   201,
+		},
+		{
+			name: "small-ContentLength",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("content-length", strconv.Itoa(len(small)))
+				w.Write([]byte(small))
+			},
+			accept: "br",
+			body:   small,
+			status: 200,
+		},
+		{
+			name: "large-ContentLength",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("content-length", strconv.Itoa(len(large)))
+				w.Write([]byte(large))
+			},
+			accept:   "br",
+			encoding: "br",
+			body:     large,
+			status:   200,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			srv := httptest.NewServer(newCompressionHandler(test.handler, 100))
+			defer srv.Close()
+
+			req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+			req.Header.Set("Accept-Encoding", test.accept)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != test.status {
+				t.Fatalf("response status == %d, want %d", resp.StatusCode, test.status)
+			}
+			encoding := resp.Header.Get("content-encoding")
+			if encoding != test.encoding {
+				t.Fatalf("response encoding == %q, want %q", encoding, test.encoding)
+			}
+			var body io.Reader = resp.Body
+			switch encoding {
+			case "gzip":
+				if body, err = gzip.NewReader(resp.Body); err != nil {
+					t.Fatal(err)
+				}
+			case "br":
+				body = brotli.NewReader(resp.Body)
+			}
+			content, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != test.body {
+				t.Fatalf("wrong response content %q", content)
+			}
+		})
+	}
+}
+
 func TestHTTPWriteTimeout(t *testing.T) {
 	const (
 		timeoutRes = `{"jsonrpc":"2.0","id":1,"error":{"code":-32002,"message":"request timed out"}}`
@@ -0,0 +1,362 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+const (
+	connectivityWindow    = 10 * time.Minute // time span covered by connectivity reports
+	maxConnectivityEvents = 8192             // maximum number of events kept
+)
+
+type connEventKind uint8
+
+const (
+	eventDialFailed      connEventKind = iota // dial failed to connect
+	eventDialConnected                        // dial connected, handshake follows
+	eventInboundAccepted                      // inbound connection accepted, handshake follows
+	eventInboundRejected                      // inbound connection rejected before handshake
+	eventHandshakeFailed                      // handshake of a connection failed
+	eventPeerAdded                            // handshake succeeded
+)
+
+type connEvent struct {
+	time   mclock.AbsTime
+	kind   connEventKind
+	reason string
+}
+
+// connectivityLog records connection events and the NAT status for connectivity
+// reports.
+type connectivityLog struct {
+	clock mclock.Clock
+
+	mu       sync.Mutex
+	events   []connEvent
+	extIP    net.IP
+	extIPErr error
+	mappings map[string]*PortMappingStatus
+}
+
+func newConnectivityLog(clock mclock.Clock) *connectivityLog {
+	return &connectivityLog{clock: clock, mappings: make(map[string]*PortMappingStatus)}
+}
+
+func (l *connectivityLog) add(kind connEventKind, reason string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	l.expire(now)
+	if len(l.events) >= maxConnectivityEvents {
+		l.events = slices.Delete(l.events, 0, len(l.events)-maxConnectivityEvents+1)
+	}
+	l.events = append(l.events, connEvent{time: now, kind: kind, reason: reason})
+}
+
+// expire drops the events which fell out of the window.
+func (l *connectivityLog) expire(now mclock.AbsTime) {
+	cutoff := 0
+	for cutoff < len(l.events) && now.Sub(l.events[cutoff].time) > connectivityWindow {
+		cutoff++
+	}
+	l.events = slices.Delete(l.events, 0, cutoff)
+}
+
+func (l *connectivityLog) setExternalIP(ip net.IP, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.extIP, l.extIPErr = ip, err
+}
+
+func (l *connectivityLog) setMapping(m *portMapping, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	status := &PortMappingStatus{Name: m.name, Protocol: m.protocol, Port: m.port, ExternalPort: m.extPort}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	l.mappings[m.name] = status
+}
+
+// ConnectivityReport summarizes the connectivity of the node over a recent window,
+// to help figuring out why the node can't find peers.
+type ConnectivityReport struct {
+	Window       string          `json:"window"`
+	Peers        int             `json:"peers"`
+	MaxPeers     int             `json:"maxPeers"`
+	InboundPeers int             `json:"inboundPeers"`
+	Listening    bool            `json:"listening"`
+	Dialing      bool            `json:"dialing"`
+	Discovery    DiscoveryStatus `json:"discovery"`
+	NAT          NATStatus       `json:"nat"`
+	Dials        ConnStats       `json:"dials"`
+	Inbound      ConnStats       `json:"inbound"`
+	Handshakes   ConnStats       `json:"handshakes"`
+	Hints        []string        `json:"hints"` // likely causes of connectivity problems
+}
+
+// DiscoveryStatus is the state of the discovery protocols.
+type DiscoveryStatus struct {
+	V4             bool `json:"v4"`
+	V5             bool `json:"v5"`
+	V4TableNodes   int  `json:"v4TableNodes"`
+	V5TableNodes   int  `json:"v5TableNodes"`
+	BootstrapNodes int  `json:"bootstrapNodes"`
+	StaticNodes    int  `json:"staticNodes"`
+}
+
+// NATStatus is the state of NAT traversal.
+type NATStatus struct {
+	Interface  string               `json:"interface,omitempty"`
+	ExternalIP string               `json:"externalIP,omitempty"`
+	Error      string               `json:"error,omitempty"`
+	Mappings   []*PortMappingStatus `json:"mappings,omitempty"`
+}
+
+// PortMappingStatus is the state of a NAT port mapping.
+type PortMappingStatus struct {
+	Name         string `json:"name"`
+	Protocol     string `json:"protocol"`
+	Port         int    `json:"port"`
+	ExternalPort int    `json:"externalPort"` // zero if not mapped
+	Error        string `json:"error,omitempty"`
+}
+
+// ConnStats counts connection attempts and their failure reasons.
+type ConnStats struct {
+	Attempts  int            `json:"attempts"`
+	Succeeded int            `json:"succeeded"`
+	Failed    int            `json:"failed"`
+	Reasons   map[string]int `json:"reasons,omitempty"` // failures by reason
+}
+
+func (s *ConnStats) fail(reason string) {
+	s.Failed++
+	if s.Reasons == nil {
+		s.Reasons = make(map[string]int)
+	}
+	s.Reasons[reason]++
+}
+
+// topReason returns the most frequent failure reason.
+func (s *ConnStats) topReason() string {
+	var top string
+	for reason, n := range s.Reasons {
+		if n > s.Reasons[top] || (n == s.Reasons[top] && reason < top) {
+			top = reason
+		}
+	}
+	return top
+}
+
+// ConnectivityReport returns a summary of the node's connectivity.
+func (srv *Server) ConnectivityReport() *ConnectivityReport {
+	srv.lock.Lock()
+	running := srv.running
+	srv.lock.Unlock()
+
+	report := &ConnectivityReport{
+		Window:    connectivityWindow.String(),
+		MaxPeers:  srv.MaxPeers,
+		Listening: srv.listener != nil || srv.wsListener != nil,
+		Dialing:   !srv.NoDial && srv.maxDialedConns() > 0,
+	}
+	if !running {
+		report.Hints = []string{"P2P server is not running"}
+		return report
+	}
+	for _, p := range srv.Peers() {
+		report.Peers++
+		if p.Inbound() {
+			report.InboundPeers++
+		}
+	}
+
+	// Discovery.
+	d := &report.Discovery
+	d.StaticNodes = len(srv.StaticNodes)
+	d.BootstrapNodes = len(srv.BootstrapNodes)
+	if srv.discv4 != nil {
+		d.V4 = true
+		for _, b := range srv.discv4.TableBuckets() {
+			d.V4TableNodes += len(b)
+		}
+	}
+	if srv.discv5 != nil {
+		d.V5 = true
+		d.V5TableNodes = len(srv.discv5.AllNodes())
+	}
+
+	// NAT and connection events.
+	l := srv.connectivity
+	l.mu.Lock()
+	if srv.NAT != nil {
+		report.NAT.Interface = srv.NAT.String()
+		if l.extIPErr != nil {
+			report.NAT.Error = l.extIPErr.Error()
+		} else if l.extIP != nil {
+			report.NAT.ExternalIP = l.extIP.String()
+		}
+		for _, m := range l.mappings {
+			mcopy := *m
+			report.NAT.Mappings = append(report.NAT.Mappings, &mcopy)
+		}
+		slices.SortFunc(report.NAT.Mappings, func(a, b *PortMappingStatus) int {
+			return cmp.Compare(a.Name, b.Name)
+		})
+	}
+	l.expire(l.clock.Now())
+	for _, ev := range l.events {
+		switch ev.kind {
+		case eventDialFailed:
+			report.Dials.Attempts++
+			report.Dials.fail(ev.reason)
+		case eventDialConnected:
+			report.Dials.Attempts++
+			report.Dials.Succeeded++
+		case eventInboundAccepted:
+			report.Inbound.Attempts++
+			report.Inbound.Succeeded++
+		case eventInboundRejected:
+			report.Inbound.Attempts++
+			report.Inbound.fail(ev.reason)
+		case eventHandshakeFailed:
+			report.Handshakes.Attempts++
+			report.Handshakes.fail(ev.reason)
+		case eventPeerAdded:
+			report.Handshakes.Attempts++
+			report.Handshakes.Succeeded++
+		}
+	}
+	l.mu.Unlock()
+
+	report.Hints = srv.connectivityHints(report)
+	return report
+}
+
+// connectivityHints lists likely causes of connectivity problems.
+func (srv *Server) connectivityHints(r *ConnectivityReport) []string {
+	var hints []string
+	if srv.MaxPeers == 0 {
+		hints = append(hints, "MaxPeers is zero, no peers can connect")
+	}
+	if !r.Listening {
+		hints = append(hints, "Node is not listening, inbound connections are impossible")
+	}
+	if !r.Dialing {
+		hints = append(hints, "Dialing is disabled, the node only accepts inbound connections")
+	}
+	if srv.NetRestrict != nil || srv.netrestrict.Load() != nil {
+		hints = append(hints, "Connections are restricted by the netrestrict list")
+	}
+	if len(srv.Sentries) > 0 {
+		hints = append(hints, "Node runs as a validator and connects only to its sentries")
+	}
+	d := r.Discovery
+	switch {
+	case !d.V4 && !d.V5 && d.StaticNodes == 0 && r.Peers == 0:
+		hints = append(hints, "Discovery is disabled and no static nodes are configured")
+	case (d.V4 || d.V5) && d.V4TableNodes+d.V5TableNodes == 0:
+		hints = append(hints, "Discovery has found no nodes, check the bootstrap nodes and that UDP traffic isn't blocked")
+	}
+	if r.Dials.Attempts > 0 && r.Dials.Succeeded == 0 {
+		hints = append(hints, fmt.Sprintf("No dial attempt connected, most common failure: %s", r.Dials.topReason()))
+	}
+	if r.Handshakes.Failed > 0 && r.Handshakes.Succeeded == 0 {
+		hints = append(hints, fmt.Sprintf("No handshake succeeded, most common failure: %s", r.Handshakes.topReason()))
+	}
+	if r.Listening && r.Inbound.Attempts == 0 {
+		if srv.NAT == nil {
+			hints = append(hints, "No inbound connection attempts, the node may be behind a NAT or firewall without port forwarding")
+		} else if r.NAT.Error != "" {
+			hints = append(hints, "No inbound connection attempts and the NAT external IP is unknown: "+r.NAT.Error)
+		}
+	}
+	for _, m := range r.NAT.Mappings {
+		if m.Error != "" {
+			hints = append(hints, fmt.Sprintf("NAT port mapping %q failed: %s", m.Name, m.Error))
+		}
+	}
+	return hints
+}
+
+// trackedDialer records the outcome of dials in the connectivity log.
+type trackedDialer struct {
+	NodeDialer
+	log *connectivityLog
+}
+
+func (t trackedDialer) Dial(ctx context.Context, dest *enode.Node) (net.Conn, error) {
+	fd, err := t.NodeDialer.Dial(ctx, dest)
+	if err != nil {
+		t.log.add(eventDialFailed, dialFailureReason(err))
+	} else {
+		t.log.add(eventDialConnected, "")
+	}
+	return fd, err
+}
+
+// dialFailureReason classifies dial errors.
+func dialFailureReason(err error) string {
+	var nerr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.As(err, &nerr) && nerr.Timeout():
+		return "timeout"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection refused"
+	case errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH):
+		return "unreachable"
+	case errors.Is(err, errNoPort):
+		return err.Error()
+	default:
+		return "connection failed"
+	}
+}
+
+// handshakeFailureReason classifies connection setup errors.
+func handshakeFailureReason(err error) string {
+	var reason DiscReason
+	switch {
+	case errors.As(err, &reason):
+		return reason.String()
+	case errors.Is(err, errEncHandshakeError):
+		return "rlpx handshake failed"
+	case errors.Is(err, errProtoHandshakeError):
+		return "protocol handshake failed"
+	default:
+		return err.Error()
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/internal/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+type refusingDialer struct{}
+
+func (refusingDialer) Dial(context.Context, *enode.Node) (net.Conn, error) {
+	return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+}
+
+func TestConnectivityReport(t *testing.T) {
+	srv := &Server{Config: Config{
+		PrivateKey:  newkey(),
+		MaxPeers:    10,
+		NoDiscovery: true,
+		Dialer:      refusingDialer{},
+		StaticNodes: []*enode.Node{enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 1}, 30303, 0)},
+		Logger:      testlog.Logger(t, log.LvlTrace),
+	}}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	var report *ConnectivityReport
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		report = srv.ConnectivityReport()
+		if report.Dials.Failed > 0 {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("dial failure not recorded")
+		}
+	}
+	if report.Dials.Succeeded != 0 || report.Dials.Reasons["connection refused"] != report.Dials.Failed {
+		t.Fatalf("wrong dial stats: %+v", report.Dials)
+	}
+	if report.Listening {
+		t.Fatal("report says server is listening")
+	}
+	want := "No dial attempt connected, most common failure: connection refused"
+	if !slices.Contains(report.Hints, want) {
+		t.Fatalf("hint %q missing, have %q", want, report.Hints)
+	}
+}
+
+func TestConnectivityLogExpiry(t *testing.T) {
+	clock := new(mclock.Simulated)
+	l := newConnectivityLog(clock)
+	l.add(eventDialFailed, "timeout")
+	clock.Run(connectivityWindow / 2)
+	l.add(eventDialConnected, "")
+	clock.Run(connectivityWindow/2 + time.Second)
+	l.expire(clock.Now())
+	if len(l.events) != 1 || l.events[0].kind != eventDialConnected {
+		t.Fatalf("wrong events after expiry: %+v", l.events)
+	}
+
+	for i := 0; i < maxConnectivityEvents+10; i++ {
+		l.add(eventHandshakeFailed, "too many peers")
+	}
+	if len(l.events) != maxConnectivityEvents {
+		t.Fatalf("wrong number of events %d", len(l.events))
+	}
+}
+
+func TestHandshakeFailureReason(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{DiscTooManyPeers, DiscTooManyPeers.String()},
+		{fmt.Errorf("%w: %v", errEncHandshakeError, "EOF"), "rlpx handshake failed"},
+		{errNotPermitted, errNotPermitted.Error()},
+	}
+	for _, test := range tests {
+		if have := handshakeFailureReason(test.err); have != test.want {
+			t.Errorf("reason for %v: have %q, want %q", test.err, have, test.want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package crawl implements a network crawler producing topology snapshots.
+//
+// The crawler walks the discovery DHT, collects the node records it finds and
+// optionally probes every node with an RLPx connection, recording the client name,
+// capabilities and eth protocol status it announces. Operators can use the
+// snapshots to audit which nodes take part in their network.
+package crawl
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+const (
+	defaultTimeout      = 30 * time.Second
+	defaultProbeTimeout = 5 * time.Second
+	defaultParallelism  = 16
+)
+
+// Config holds the crawler settings.
+type Config struct {
+	// Timeout is the duration of the DHT walk. The probes of the nodes found
+	// before the timeout still complete.
+	Timeout time.Duration
+
+	// MaxNodes ends the walk after this many nodes are found. Zero means no limit.
+	MaxNodes int
+
+	// Probe enables probing the nodes with an RLPx connection.
+	Probe bool
+
+	// ProbeTimeout limits the duration of a single probe.
+	ProbeTimeout time.Duration
+
+	// Parallelism is the number of concurrent probes.
+	Parallelism int
+
+	// PrivateKey is the node key used for probing. A new key is generated if nil,
+	// so the probes don't interfere with the connections of a running node.
+	PrivateKey *ecdsa.PrivateKey
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.ProbeTimeout == 0 {
+		cfg.ProbeTimeout = defaultProbeTimeout
+	}
+	if cfg.Parallelism <= 0 {
+		cfg.Parallelism = defaultParallelism
+	}
+	if cfg.PrivateKey == nil {
+		cfg.PrivateKey, _ = crypto.GenerateKey()
+	}
+	return cfg
+}
+
+// Snapshot is the network topology found by a crawl.
+type Snapshot struct {
+	Time     time.Time `json:"time"`
+	Duration string    `json:"duration"`
+	Nodes    []*Node   `json:"nodes"`
+}
+
+// Node is a node found by the crawler.
+type Node struct {
+	ID     enode.ID    `json:"id"`
+	Record *enode.Node `json:"record"`
+	IP     string      `json:"ip,omitempty"`
+	TCP    int         `json:"tcp,omitempty"`
+	UDP    int         `json:"udp,omitempty"`
+	Probe  *Probe      `json:"probe,omitempty"`
+}
+
+// probeTask is a node to probe. The record is passed separately, since the node's
+// record may be updated while the probe runs.
+type probeTask struct {
+	node *Node
+	rec  *enode.Node
+}
+
+// Run crawls the network through the given iterator, which is closed when the walk
+// ends. It returns the nodes found, sorted by ID.
+func Run(ctx context.Context, it enode.Iterator, cfg Config) *Snapshot {
+	cfg = cfg.withDefaults()
+	start := time.Now()
+
+	// Close the iterator when the walk ends, to unblock it.
+	walkCtx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+	go func() {
+		<-walkCtx.Done()
+		it.Close()
+	}()
+
+	var (
+		found  = make(map[enode.ID]*Node)
+		probes = make(chan probeTask)
+		wg     sync.WaitGroup
+	)
+	if cfg.Probe {
+		wg.Add(cfg.Parallelism)
+		for i := 0; i < cfg.Parallelism; i++ {
+			go func() {
+				defer wg.Done()
+				for t := range probes {
+					t.node.Probe = probeNode(ctx, t.rec, cfg.PrivateKey, cfg.ProbeTimeout)
+				}
+			}()
+		}
+	}
+	for it.Next() {
+		rec := it.Node()
+		if n := found[rec.ID()]; n != nil {
+			// Keep the latest record, but probe only once.
+			if rec.Seq() > n.Record.Seq() {
+				n.Record = rec
+			}
+			continue
+		}
+		n := &Node{ID: rec.ID(), Record: rec}
+		found[n.ID] = n
+		if cfg.Probe {
+			select {
+			case probes <- probeTask{n, rec}:
+			case <-walkCtx.Done():
+			}
+		}
+		if cfg.MaxNodes > 0 && len(found) >= cfg.MaxNodes {
+			break
+		}
+	}
+	cancel()
+	close(probes)
+	wg.Wait()
+
+	snap := &Snapshot{Time: start.UTC(), Duration: time.Since(start).Round(time.Millisecond).String()}
+	for _, n := range found {
+		// Fill the endpoints only now, the records may have been updated.
+		if ip := n.Record.IPAddr(); ip.IsValid() {
+			n.IP = ip.String()
+		}
+		n.TCP, n.UDP = n.Record.TCP(), n.Record.UDP()
+		snap.Nodes = append(snap.Nodes, n)
+	}
+	slices.SortFunc(snap.Nodes, func(a, b *Node) int {
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+	log.Debug("Network crawl done", "nodes", len(snap.Nodes), "elapsed", snap.Duration)
+	return snap
+}
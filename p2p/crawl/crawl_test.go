@@ -0,0 +1,126 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package crawl
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func startTestServer(t *testing.T, status *statusPacket) *p2p.Server {
+	key, _ := crypto.GenerateKey()
+	srv := &p2p.Server{Config: p2p.Config{
+		PrivateKey:  key,
+		MaxPeers:    10,
+		ListenAddr:  "127.0.0.1:0",
+		NoDiscovery: true,
+		NoDial:      true,
+		Name:        "test-node",
+		Protocols: []p2p.Protocol{{
+			Name:    "eth",
+			Version: ethVersion,
+			Length:  17,
+			Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+				if err := p2p.Send(rw, 0, status); err != nil {
+					return err
+				}
+				_, err := rw.ReadMsg()
+				return err
+			},
+		}},
+	}}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Stop)
+	return srv
+}
+
+func TestCrawlProbe(t *testing.T) {
+	status := &statusPacket{
+		ProtocolVersion: ethVersion,
+		NetworkID:       1337,
+		TD:              big.NewInt(1),
+		Head:            common.Hash{1},
+		Genesis:         common.Hash{2},
+		ForkID:          forkid.ID{Hash: [4]byte{1, 2, 3, 4}, Next: 10},
+	}
+	srv := startTestServer(t, status)
+
+	// A node without TCP endpoint can't be probed.
+	key, _ := crypto.GenerateKey()
+	udpOnly := enode.NewV4(&key.PublicKey, net.IP{127, 0, 0, 1}, 0, 30303)
+
+	nodes := []*enode.Node{srv.Self(), udpOnly, srv.Self()}
+	snap := Run(context.Background(), enode.IterNodes(nodes), Config{Probe: true, Timeout: 10 * time.Second})
+	if len(snap.Nodes) != 2 {
+		t.Fatalf("wrong number of nodes %d, want 2", len(snap.Nodes))
+	}
+	for _, n := range snap.Nodes {
+		switch n.ID {
+		case srv.Self().ID():
+			if n.Probe == nil || n.Probe.Error != "" {
+				t.Fatalf("probe failed: %+v", n.Probe)
+			}
+			if n.Probe.Client != "test-node" {
+				t.Errorf("wrong client name %q", n.Probe.Client)
+			}
+			if len(n.Probe.Caps) != 1 || n.Probe.Caps[0] != "eth/68" {
+				t.Errorf("wrong caps %v", n.Probe.Caps)
+			}
+			eth := n.Probe.Eth
+			if eth == nil || eth.NetworkID != 1337 || eth.Head != status.Head || eth.Genesis != status.Genesis || eth.ForkNext != 10 {
+				t.Errorf("wrong eth status %+v", eth)
+			}
+			if n.IP != "127.0.0.1" || n.TCP == 0 {
+				t.Errorf("wrong endpoint %s:%d", n.IP, n.TCP)
+			}
+		case udpOnly.ID():
+			if n.Probe == nil || n.Probe.Error != errNoTCP.Error() {
+				t.Errorf("wrong probe result %+v", n.Probe)
+			}
+		default:
+			t.Errorf("unexpected node %v", n.ID)
+		}
+	}
+}
+
+func TestCrawlMaxNodes(t *testing.T) {
+	var nodes []*enode.Node
+	for i := 0; i < 10; i++ {
+		key, _ := crypto.GenerateKey()
+		nodes = append(nodes, enode.NewV4(&key.PublicKey, net.IP{127, 0, 0, 1}, 0, 30303))
+	}
+	snap := Run(context.Background(), enode.IterNodes(nodes), Config{MaxNodes: 4})
+	if len(snap.Nodes) != 4 {
+		t.Fatalf("wrong number of nodes %d, want 4", len(snap.Nodes))
+	}
+	for _, n := range snap.Nodes {
+		if n.Probe != nil {
+			t.Errorf("node %v probed", n.ID)
+		}
+	}
+}
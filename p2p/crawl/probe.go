@@ -0,0 +1,198 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package crawl
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/rlpx"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Message codes of the probe. The eth protocol is the only capability announced by
+// the probe, so its messages start right after the base protocol.
+const (
+	handshakeMsg  = 0x00
+	discMsg       = 0x01
+	baseLength    = 0x10
+	ethStatusMsg  = baseLength + 0x00
+	probeVersion  = 5 // devp2p version announced in the hello, enables snappy
+	ethVersion    = 68
+	probeName     = "crawler"
+	maxProbeReads = 8 // messages read while waiting for the eth status
+)
+
+var errNoTCP = errors.New("node has no TCP endpoint")
+
+// Probe is the result of probing a node.
+type Probe struct {
+	Client string     `json:"client,omitempty"`
+	Caps   []string   `json:"caps,omitempty"`
+	Eth    *EthStatus `json:"eth,omitempty"`
+	Error  string     `json:"error,omitempty"`
+}
+
+// EthStatus is the eth protocol status announced by a node.
+type EthStatus struct {
+	Version   uint32        `json:"version"`
+	NetworkID uint64        `json:"networkId"`
+	Head      common.Hash   `json:"head"`
+	Genesis   common.Hash   `json:"genesis"`
+	ForkHash  hexutil.Bytes `json:"forkHash"`
+	ForkNext  uint64        `json:"forkNext"`
+}
+
+// hello is the devp2p handshake message.
+type hello struct {
+	Version    uint64
+	Name       string
+	Caps       []p2p.Cap
+	ListenPort uint64
+	ID         []byte
+	Rest       []rlp.RawValue `rlp:"tail"`
+}
+
+// statusPacket is the eth protocol handshake message.
+type statusPacket struct {
+	ProtocolVersion uint32
+	NetworkID       uint64
+	TD              *big.Int
+	Head            common.Hash
+	Genesis         common.Hash
+	ForkID          forkid.ID
+	Rest            []rlp.RawValue `rlp:"tail"`
+}
+
+// probeNode connects to a node and records its devp2p hello and eth status.
+func probeNode(ctx context.Context, n *enode.Node, key *ecdsa.PrivateKey, timeout time.Duration) *Probe {
+	result := new(Probe)
+	if err := probe(ctx, n, key, timeout, result); err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func probe(ctx context.Context, n *enode.Node, key *ecdsa.PrivateKey, timeout time.Duration, result *Probe) error {
+	addr, ok := n.TCPEndpoint()
+	if !ok {
+		return errNoTCP
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var dialer net.Dialer
+	fd, err := dialer.DialContext(ctx, "tcp", addr.String())
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	fd.SetDeadline(deadline)
+	conn := rlpx.NewConn(fd, n.Pubkey())
+	defer conn.Close()
+
+	if _, err := conn.Handshake(key); err != nil {
+		return fmt.Errorf("rlpx handshake failed: %v", err)
+	}
+	ours := &hello{
+		Version: probeVersion,
+		Name:    probeName,
+		Caps:    []p2p.Cap{{Name: "eth", Version: ethVersion}},
+		ID:      crypto.FromECDSAPub(&key.PublicKey)[1:],
+	}
+	if err := writeMsg(conn, handshakeMsg, ours); err != nil {
+		return err
+	}
+	code, data, err := readMsg(conn)
+	if err != nil {
+		return err
+	}
+	if code != handshakeMsg {
+		return fmt.Errorf("unexpected message %#x instead of hello", code)
+	}
+	var theirs hello
+	if err := rlp.DecodeBytes(data, &theirs); err != nil {
+		return fmt.Errorf("invalid hello: %v", err)
+	}
+	result.Client = theirs.Name
+	shared := false
+	for _, c := range theirs.Caps {
+		result.Caps = append(result.Caps, c.String())
+		shared = shared || (c.Name == "eth" && c.Version == ethVersion)
+	}
+	if !shared {
+		return nil
+	}
+	conn.SetSnappy(theirs.Version >= probeVersion)
+
+	// The node sends its status after the hello, if it accepts the connection.
+	for i := 0; i < maxProbeReads; i++ {
+		code, data, err := readMsg(conn)
+		if err != nil {
+			return err
+		}
+		switch code {
+		case ethStatusMsg:
+			var status statusPacket
+			if err := rlp.DecodeBytes(data, &status); err != nil {
+				return fmt.Errorf("invalid eth status: %v", err)
+			}
+			result.Eth = &EthStatus{
+				Version:   status.ProtocolVersion,
+				NetworkID: status.NetworkID,
+				Head:      status.Head,
+				Genesis:   status.Genesis,
+				ForkHash:  status.ForkID.Hash[:],
+				ForkNext:  status.ForkID.Next,
+			}
+			writeMsg(conn, discMsg, []p2p.DiscReason{p2p.DiscRequested})
+			return nil
+		case discMsg:
+			var reason []p2p.DiscReason
+			if rlp.DecodeBytes(data, &reason); len(reason) > 0 {
+				return fmt.Errorf("disconnected: %v", reason[0])
+			}
+			return errors.New("disconnected")
+		}
+		// Skip pings and other messages.
+	}
+	return errors.New("no eth status received")
+}
+
+func writeMsg(conn *rlpx.Conn, code uint64, msg interface{}) error {
+	data, err := rlp.EncodeToBytes(msg)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write(code, data)
+	return err
+}
+
+func readMsg(conn *rlpx.Conn) (uint64, []byte, error) {
+	code, data, _, err := conn.Read()
+	return code, data, err
+}
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	mrand "math/rand"
 	"net"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -79,6 +80,47 @@ var (
 	errNoPort           = errors.New("node does not provide TCP port")
 )
 
+// dialSource is a source of dial candidates.
+type dialSource uint8
+
+const (
+	dialSourceStatic dialSource = iota
+	dialSourceBootnodes
+	dialSourceDiscovery
+)
+
+var dialSourceNames = map[string]dialSource{
+	"static":    dialSourceStatic,
+	"bootnodes": dialSourceBootnodes,
+	"discovery": dialSourceDiscovery,
+}
+
+// defaultDialPriority dials static nodes first, then discovered ones.
+var defaultDialPriority = []dialSource{dialSourceStatic, dialSourceDiscovery}
+
+// parseDialPriority parses the names of dial candidate sources.
+func parseDialPriority(names []string) ([]dialSource, error) {
+	if len(names) == 0 {
+		return defaultDialPriority, nil
+	}
+	var (
+		priority = make([]dialSource, 0, len(names))
+		seen     = make(map[dialSource]bool)
+	)
+	for _, name := range names {
+		src, ok := dialSourceNames[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown dial source %q", name)
+		}
+		if seen[src] {
+			return nil, fmt.Errorf("duplicate dial source %q", name)
+		}
+		seen[src] = true
+		priority = append(priority, src)
+	}
+	return priority, nil
+}
+
 // dialer creates outbound connections and submits them into Server.
 // Two types of peer connections can be created:
 //
@@ -90,16 +132,17 @@ var (
 //     to create peer connections to nodes arriving through the iterator.
 type dialScheduler struct {
 	dialConfig
-	setupFunc   dialSetupFunc
-	wg          sync.WaitGroup
-	cancel      context.CancelFunc
-	ctx         context.Context
-	nodesIn     chan *enode.Node
-	doneCh      chan *dialTask
-	addStaticCh chan *enode.Node
-	remStaticCh chan *enode.Node
-	addPeerCh   chan *conn
-	remPeerCh   chan *conn
+	setupFunc     dialSetupFunc
+	wg            sync.WaitGroup
+	cancel        context.CancelFunc
+	ctx           context.Context
+	nodesIn       chan *enode.Node
+	doneCh        chan *dialTask
+	addStaticCh   chan *enode.Node
+	remStaticCh   chan *enode.Node
+	addPeerCh     chan *conn
+	remPeerCh     chan *conn
+	netRestrictCh chan *netutil.Netlist
 
 	// Everything below here belongs to loop and
 	// should only be accessed by code on the loop goroutine.
@@ -118,6 +161,9 @@ type dialScheduler struct {
 	history      expHeap
 	historyTimer *mclock.Alarm
 
+	// Bootstrap nodes are dialed in turn, if they are a dial source.
+	nextBootnode int
+
 	// for logStats
 	lastStatsLog     mclock.AbsTime
 	doneSinceLastLog int
@@ -126,21 +172,37 @@ type dialScheduler struct {
 type dialSetupFunc func(net.Conn, connFlag, *enode.Node) error
 
 type dialConfig struct {
-	self           enode.ID         // our own ID
-	maxDialPeers   int              // maximum number of dialed peers
-	maxActiveDials int              // maximum number of active dials
-	netRestrict    *netutil.Netlist // IP netrestrict list, disabled if nil
-	resolver       nodeResolver
-	dialer         NodeDialer
-	log            log.Logger
-	clock          mclock.Clock
-	rand           *mrand.Rand
+	self             enode.ID          // our own ID
+	maxDialPeers     int               // maximum number of dialed peers
+	maxActiveDials   int               // maximum number of active dials
+	redialInterval   time.Duration     // minimum time between dials to a node
+	maxRedialBackoff time.Duration     // backoff limit of failing static dials, no backoff if zero
+	maxResolveDelay  time.Duration     // backoff limit of static node lookups
+	priority         []dialSource      // order of the dial candidate sources
+	keepStatic       bool              // redial static nodes immediately on disconnect
+	reserved         map[enode.ID]bool // static nodes with reserved slots, dialed regardless of free slots
+	bootnodes        []*enode.Node     // dialed directly if listed in priority
+	netRestrict      *netutil.Netlist  // IP netrestrict list, disabled if nil
+	resolver         nodeResolver
+	dialer           NodeDialer
+	log              log.Logger
+	clock            mclock.Clock
+	rand             *mrand.Rand
 }
 
 func (cfg dialConfig) withDefaults() dialConfig {
 	if cfg.maxActiveDials == 0 {
 		cfg.maxActiveDials = defaultMaxPendingPeers
 	}
+	if cfg.redialInterval == 0 {
+		cfg.redialInterval = dialHistoryExpiration
+	}
+	if cfg.maxResolveDelay == 0 {
+		cfg.maxResolveDelay = maxResolveDelay
+	}
+	if cfg.priority == nil {
+		cfg.priority = defaultDialPriority
+	}
 	if cfg.log == nil {
 		cfg.log = log.Root()
 	}
@@ -159,18 +221,19 @@ func (cfg dialConfig) withDefaults() dialConfig {
 func newDialScheduler(config dialConfig, it enode.Iterator, setupFunc dialSetupFunc) *dialScheduler {
 	cfg := config.withDefaults()
 	d := &dialScheduler{
-		dialConfig:   cfg,
-		historyTimer: mclock.NewAlarm(cfg.clock),
-		setupFunc:    setupFunc,
-		dialing:      make(map[enode.ID]*dialTask),
-		static:       make(map[enode.ID]*dialTask),
-		peers:        make(map[enode.ID]struct{}),
-		doneCh:       make(chan *dialTask),
-		nodesIn:      make(chan *enode.Node),
-		addStaticCh:  make(chan *enode.Node),
-		remStaticCh:  make(chan *enode.Node),
-		addPeerCh:    make(chan *conn),
-		remPeerCh:    make(chan *conn),
+		dialConfig:    cfg,
+		historyTimer:  mclock.NewAlarm(cfg.clock),
+		setupFunc:     setupFunc,
+		dialing:       make(map[enode.ID]*dialTask),
+		static:        make(map[enode.ID]*dialTask),
+		peers:         make(map[enode.ID]struct{}),
+		doneCh:        make(chan *dialTask),
+		nodesIn:       make(chan *enode.Node),
+		addStaticCh:   make(chan *enode.Node),
+		remStaticCh:   make(chan *enode.Node),
+		addPeerCh:     make(chan *conn),
+		remPeerCh:     make(chan *conn),
+		netRestrictCh: make(chan *netutil.Netlist),
 	}
 	d.lastStatsLog = d.clock.Now()
 	d.ctx, d.cancel = context.WithCancel(context.Background())
@@ -202,6 +265,14 @@ func (d *dialScheduler) removeStatic(n *enode.Node) {
 	}
 }
 
+// setNetRestrict replaces the netrestrict list.
+func (d *dialScheduler) setNetRestrict(list *netutil.Netlist) {
+	select {
+	case d.netRestrictCh <- list:
+	case <-d.ctx.Done():
+	}
+}
+
 // peerAdded updates the peer set.
 func (d *dialScheduler) peerAdded(c *conn) {
 	select {
@@ -226,10 +297,25 @@ func (d *dialScheduler) loop(it enode.Iterator) {
 
 loop:
 	for {
-		// Launch new dials if slots are available.
+		// Launch new dials if slots are available. Nodes with reserved slots
+		// don't need a dial slot.
+		d.startReservedDials()
 		slots := d.freeDialSlots()
-		slots -= d.startStaticDials(slots)
-		if slots > 0 {
+		for _, src := range d.priority {
+			switch src {
+			case dialSourceStatic:
+				if d.keepStatic {
+					slots -= d.startStaticDials(len(d.staticPool))
+				} else {
+					slots -= d.startStaticDials(slots)
+				}
+			case dialSourceBootnodes:
+				slots -= d.startBootnodeDials(slots)
+			case dialSourceDiscovery:
+				slots -= d.startDiscoveredDials(slots)
+			}
+		}
+		if slots > 0 && slices.Contains(d.priority, dialSourceDiscovery) {
 			nodesCh = d.nodesIn
 		} else {
 			nodesCh = nil
@@ -248,28 +334,38 @@ loop:
 		case task := <-d.doneCh:
 			id := task.dest().ID()
 			delete(d.dialing, id)
+			if task.failed && task.flags&staticDialedConn != 0 {
+				d.backoffStatic(task)
+			}
 			d.updateStaticPool(id)
 			d.doneSinceLastLog++
 
 		case c := <-d.addPeerCh:
-			if c.is(dynDialedConn) || c.is(staticDialedConn) {
+			if (c.is(dynDialedConn) || c.is(staticDialedConn)) && !c.is(reservedConn) {
 				d.dialPeers++
 			}
 			id := c.node.ID()
 			d.peers[id] = struct{}{}
 			// Remove from static pool because the node is now connected.
 			task := d.static[id]
-			if task != nil && task.staticPoolIndex >= 0 {
-				d.removeFromStaticPool(task.staticPoolIndex)
+			if task != nil {
+				task.failures = 0
+				if task.staticPoolIndex >= 0 {
+					d.removeFromStaticPool(task.staticPoolIndex)
+				}
 			}
 			// TODO: cancel dials to connected peers
 
 		case c := <-d.remPeerCh:
-			if c.is(dynDialedConn) || c.is(staticDialedConn) {
+			if (c.is(dynDialedConn) || c.is(staticDialedConn)) && !c.is(reservedConn) {
 				d.dialPeers--
 			}
-			delete(d.peers, c.node.ID())
-			d.updateStaticPool(c.node.ID())
+			id := c.node.ID()
+			delete(d.peers, id)
+			if _, ok := d.static[id]; ok && d.keepStatic {
+				d.history.remove(string(id.Bytes()))
+			}
+			d.updateStaticPool(id)
 
 		case node := <-d.addStaticCh:
 			id := node.ID()
@@ -295,6 +391,16 @@ loop:
 				}
 			}
 
+		case list := <-d.netRestrictCh:
+			d.netRestrict = list
+			// Re-check the static nodes against the new list.
+			for id, task := range d.static {
+				if task.staticPoolIndex >= 0 && d.checkDial(task.dest()) != nil {
+					d.removeFromStaticPool(task.staticPoolIndex)
+				}
+				d.updateStaticPool(id)
+			}
+
 		case <-d.historyTimer.C():
 			d.expireHistory()
 
@@ -377,7 +483,10 @@ func (d *dialScheduler) checkDial(n *enode.Node) error {
 		// This check can trigger if a non-TCP node is found
 		// by discovery. If there is no IP, the node is a static
 		// node and the actual endpoint will be resolved later in dialTask.
-		return errNoPort
+		// Nodes reachable over WebSocket only are fine.
+		if _, ok := wsEndpoint(n); !ok {
+			return errNoPort
+		}
 	}
 	if _, ok := d.dialing[n.ID()]; ok {
 		return errAlreadyDialing
@@ -405,6 +514,72 @@ func (d *dialScheduler) startStaticDials(n int) (started int) {
 	return started
 }
 
+// startReservedDials starts the dials of all static nodes with reserved slots.
+func (d *dialScheduler) startReservedDials() {
+	if len(d.reserved) == 0 {
+		return
+	}
+	for idx := 0; idx < len(d.staticPool); {
+		task := d.staticPool[idx]
+		if !d.reserved[task.dest().ID()] {
+			idx++
+			continue
+		}
+		d.startDial(task)
+		d.removeFromStaticPool(idx) // moves the last task to idx
+	}
+}
+
+// startBootnodeDials starts up to n dials to bootstrap nodes, in turn.
+func (d *dialScheduler) startBootnodeDials(n int) (started int) {
+	for i := 0; i < len(d.bootnodes) && started < n; i++ {
+		node := d.bootnodes[d.nextBootnode]
+		d.nextBootnode = (d.nextBootnode + 1) % len(d.bootnodes)
+		if d.checkDial(node) == nil {
+			d.startDial(newDialTask(node, dynDialedConn))
+			started++
+		}
+	}
+	return started
+}
+
+// startDiscoveredDials starts up to n dials to the discovered nodes which are
+// ready, without waiting for more.
+func (d *dialScheduler) startDiscoveredDials(n int) (started int) {
+	for i := 0; i < n; i++ {
+		select {
+		case node := <-d.nodesIn:
+			if err := d.checkDial(node); err != nil {
+				d.log.Trace("Discarding dial candidate", "id", node.ID(), "ip", node.IPAddr(), "reason", err)
+				continue
+			}
+			d.startDial(newDialTask(node, dynDialedConn))
+			started++
+		default:
+			return started
+		}
+	}
+	return started
+}
+
+// backoffStatic delays the next dial of a static node which failed to connect.
+// The delay doubles with every consecutive failure, up to maxRedialBackoff.
+func (d *dialScheduler) backoffStatic(task *dialTask) {
+	task.failures++
+	if d.maxRedialBackoff <= d.redialInterval {
+		return
+	}
+	delay := d.redialInterval
+	for i := 1; i < task.failures && delay < d.maxRedialBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, d.maxRedialBackoff)
+	if delay > d.redialInterval {
+		hkey := string(task.dest().ID().Bytes())
+		d.history.add(hkey, d.clock.Now().Add(delay))
+	}
+}
+
 // updateStaticPool attempts to move the given static dial back into staticPool.
 func (d *dialScheduler) updateStaticPool(id enode.ID) {
 	task, ok := d.static[id]
@@ -438,7 +613,7 @@ func (d *dialScheduler) startDial(task *dialTask) {
 	node := task.dest()
 	d.log.Trace("Starting p2p dial", "id", node.ID(), "ip", node.IPAddr(), "flag", task.flags)
 	hkey := string(node.ID().Bytes())
-	d.history.add(hkey, d.clock.Now().Add(dialHistoryExpiration))
+	d.history.add(hkey, d.clock.Now().Add(d.redialInterval))
 	d.dialing[node.ID()] = task
 	go func() {
 		task.run(d)
@@ -450,6 +625,8 @@ func (d *dialScheduler) startDial(task *dialTask) {
 type dialTask struct {
 	staticPoolIndex int
 	flags           connFlag
+	failures        int // consecutive failed dials of a static node, owned by dialScheduler
+	failed          bool
 
 	// These fields are private to the task and should not be
 	// accessed by dialScheduler while the task is running.
@@ -473,6 +650,7 @@ func (t *dialTask) dest() *enode.Node {
 }
 
 func (t *dialTask) run(d *dialScheduler) {
+	t.failed = false
 	if t.needResolve() && !t.resolve(d) {
 		return
 	}
@@ -482,10 +660,11 @@ func (t *dialTask) run(d *dialScheduler) {
 		// For static nodes, resolve one more time if dialing fails.
 		if _, ok := err.(*dialError); ok && t.flags&staticDialedConn != 0 {
 			if t.resolve(d) {
-				t.dial(d, t.dest())
+				err = t.dial(d, t.dest())
 			}
 		}
 	}
+	t.failed = err != nil
 }
 
 func (t *dialTask) needResolve() bool {
@@ -503,7 +682,7 @@ func (t *dialTask) resolve(d *dialScheduler) bool {
 		return false
 	}
 	if t.resolveDelay == 0 {
-		t.resolveDelay = initialResolveDelay
+		t.resolveDelay = min(initialResolveDelay, d.maxResolveDelay)
 	}
 	if t.lastResolved > 0 && time.Duration(d.clock.Now()-t.lastResolved) < t.resolveDelay {
 		return false
@@ -514,14 +693,14 @@ func (t *dialTask) resolve(d *dialScheduler) bool {
 	t.lastResolved = d.clock.Now()
 	if resolved == nil {
 		t.resolveDelay *= 2
-		if t.resolveDelay > maxResolveDelay {
-			t.resolveDelay = maxResolveDelay
+		if t.resolveDelay > d.maxResolveDelay {
+			t.resolveDelay = d.maxResolveDelay
 		}
 		d.log.Debug("Resolving node failed", "id", node.ID(), "newdelay", t.resolveDelay)
 		return false
 	}
 	// The node was found.
-	t.resolveDelay = initialResolveDelay
+	t.resolveDelay = min(initialResolveDelay, d.maxResolveDelay)
 	t.destPtr.Store(resolved)
 	resAddr, _ := resolved.TCPEndpoint()
 	d.log.Debug("Resolved node", "id", resolved.ID(), "addr", resAddr)
@@ -278,6 +278,163 @@ func TestDialSchedRemoveStatic(t *testing.T) {
 	})
 }
 
+// This test checks that static nodes are kept connected: they are all dialed regardless
+// of the dial slots, and redialed immediately when they disconnect.
+func TestDialSchedKeepStatic(t *testing.T) {
+	t.Parallel()
+
+	config := dialConfig{
+		maxActiveDials: 5,
+		maxDialPeers:   1,
+		keepStatic:     true,
+	}
+	runDialTest(t, config, []dialTestRound{
+		{
+			update: func(d *dialScheduler) {
+				d.addStatic(newNode(uintID(0x01), "127.0.0.1:30303"))
+				d.addStatic(newNode(uintID(0x02), "127.0.0.2:30303"))
+				d.addStatic(newNode(uintID(0x03), "127.0.0.3:30303"))
+			},
+			wantNewDials: []*enode.Node{
+				newNode(uintID(0x01), "127.0.0.1:30303"),
+				newNode(uintID(0x02), "127.0.0.2:30303"),
+				newNode(uintID(0x03), "127.0.0.3:30303"),
+			},
+		},
+		{
+			succeeded: []enode.ID{
+				uintID(0x01),
+				uintID(0x02),
+				uintID(0x03),
+			},
+		},
+		// 0x01 drops and is redialed although it was dialed recently.
+		{
+			peersRemoved: []enode.ID{
+				uintID(0x01),
+			},
+			wantNewDials: []*enode.Node{
+				newNode(uintID(0x01), "127.0.0.1:30303"),
+			},
+		},
+	})
+}
+
+// This test checks that static nodes with reserved slots are dialed when all
+// dial slots are taken.
+func TestDialSchedReserved(t *testing.T) {
+	t.Parallel()
+
+	config := dialConfig{
+		maxActiveDials: 5,
+		maxDialPeers:   0,
+		reserved:       map[enode.ID]bool{uintID(0x01): true},
+	}
+	runDialTest(t, config, []dialTestRound{
+		{
+			update: func(d *dialScheduler) {
+				d.addStatic(newNode(uintID(0x01), "127.0.0.1:30303"))
+				d.addStatic(newNode(uintID(0x02), "127.0.0.2:30303"))
+			},
+			wantNewDials: []*enode.Node{
+				newNode(uintID(0x01), "127.0.0.1:30303"),
+			},
+		},
+	})
+}
+
+// This test checks that the redial interval of failing static nodes backs off.
+func TestDialSchedStaticBackoff(t *testing.T) {
+	t.Parallel()
+
+	config := dialConfig{
+		maxActiveDials:   5,
+		maxDialPeers:     4,
+		redialInterval:   10 * time.Second,
+		maxRedialBackoff: 40 * time.Second,
+	}
+	runDialTest(t, config, []dialTestRound{
+		{
+			update: func(d *dialScheduler) {
+				d.addStatic(newNode(uintID(0x01), "127.0.0.1:30303"))
+			},
+			wantNewDials: []*enode.Node{
+				newNode(uintID(0x01), "127.0.0.1:30303"),
+			},
+		},
+		// The first failure doesn't delay the redial.
+		{
+			failed: []enode.ID{
+				uintID(0x01),
+			},
+			wantResolves: map[enode.ID]*enode.Node{
+				uintID(0x01): nil,
+			},
+			wantNewDials: []*enode.Node{
+				newNode(uintID(0x01), "127.0.0.1:30303"),
+			},
+		},
+		// The second failure doubles the interval to 20s.
+		{
+			failed: []enode.ID{
+				uintID(0x01),
+			},
+		},
+		{},
+		{
+			wantNewDials: []*enode.Node{
+				newNode(uintID(0x01), "127.0.0.1:30303"),
+			},
+		},
+	})
+}
+
+// This test checks that bootstrap nodes are dialed directly when they are listed in
+// the dial priority.
+func TestDialSchedBootnodes(t *testing.T) {
+	t.Parallel()
+
+	config := dialConfig{
+		maxActiveDials: 5,
+		maxDialPeers:   2,
+		priority:       []dialSource{dialSourceBootnodes, dialSourceDiscovery},
+		bootnodes: []*enode.Node{
+			newNode(uintID(0x01), "127.0.0.1:30303"),
+			newNode(uintID(0x02), "127.0.0.2:30303"),
+		},
+	}
+	runDialTest(t, config, []dialTestRound{
+		{
+			discovered: []*enode.Node{
+				newNode(uintID(0x03), "127.0.0.3:30303"),
+				newNode(uintID(0x04), "127.0.0.4:30303"),
+				newNode(uintID(0x05), "127.0.0.5:30303"),
+			},
+			wantNewDials: []*enode.Node{
+				newNode(uintID(0x01), "127.0.0.1:30303"),
+				newNode(uintID(0x02), "127.0.0.2:30303"),
+				newNode(uintID(0x03), "127.0.0.3:30303"),
+				newNode(uintID(0x04), "127.0.0.4:30303"),
+			},
+		},
+	})
+}
+
+func TestParseDialPriority(t *testing.T) {
+	if p, err := parseDialPriority(nil); err != nil || !reflect.DeepEqual(p, defaultDialPriority) {
+		t.Errorf("wrong default priority %v, err %v", p, err)
+	}
+	p, err := parseDialPriority([]string{"bootnodes", "static"})
+	if err != nil || !reflect.DeepEqual(p, []dialSource{dialSourceBootnodes, dialSourceStatic}) {
+		t.Errorf("wrong priority %v, err %v", p, err)
+	}
+	for _, names := range [][]string{{"static", "static"}, {"peers"}} {
+		if _, err := parseDialPriority(names); err == nil {
+			t.Errorf("no error for %v", names)
+		}
+	}
+}
+
 // This test checks that static dials are selected at random.
 func TestDialSchedManyStaticNodes(t *testing.T) {
 	t.Parallel()
@@ -27,6 +27,7 @@ import (
 	"io"
 	"net/netip"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/crypto"
@@ -68,7 +69,7 @@ const (
 type UDPv4 struct {
 	conn        UDPConn
 	log         log.Logger
-	netrestrict *netutil.Netlist
+	netrestrict atomic.Pointer[netutil.Netlist]
 	priv        *ecdsa.PrivateKey
 	localNode   *enode.LocalNode
 	db          *enode.DB
@@ -133,7 +134,6 @@ func ListenV4(c UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv4, error) {
 	t := &UDPv4{
 		conn:            newMeteredConn(c),
 		priv:            cfg.PrivateKey,
-		netrestrict:     cfg.NetRestrict,
 		localNode:       ln,
 		db:              ln.Database(),
 		gotreply:        make(chan reply),
@@ -142,6 +142,7 @@ func ListenV4(c UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv4, error) {
 		cancelCloseCtx:  cancel,
 		log:             cfg.Log,
 	}
+	t.netrestrict.Store(cfg.NetRestrict)
 
 	tab, err := newTable(t, ln.Database(), cfg)
 	if err != nil {
@@ -156,6 +157,12 @@ func ListenV4(c UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv4, error) {
 	return t, nil
 }
 
+// SetNetRestrict replaces the list of IP networks nodes are accepted from.
+// A nil list accepts all networks.
+func (t *UDPv4) SetNetRestrict(list *netutil.Netlist) {
+	t.netrestrict.Store(list)
+}
+
 // Self returns the local node.
 func (t *UDPv4) Self() *enode.Node {
 	return t.localNode.Node()
@@ -603,7 +610,7 @@ func (t *UDPv4) nodeFromRPC(sender netip.AddrPort, rn v4wire.Node) (*enode.Node,
 	if err := netutil.CheckRelayIP(sender.Addr().AsSlice(), rn.IP); err != nil {
 		return nil, err
 	}
-	if t.netrestrict != nil && !t.netrestrict.Contains(rn.IP) {
+	if nr := t.netrestrict.Load(); nr != nil && !nr.Contains(rn.IP) {
 		return nil, errors.New("not contained in netrestrict list")
 	}
 	key, err := v4wire.DecodePubkey(crypto.S256(), rn.ID)
@@ -0,0 +1,354 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Topic advertisement lets nodes find peers offering a service without crawling the
+// whole DHT. A node advertises a topic by registering its record with the nodes
+// closest to the hash of the topic, which answer topic queries with the records
+// registered at them. Both are exchanged as TALKREQ messages of the "topic" protocol.
+
+const (
+	topicProtocol = "topic"
+
+	topicAdLifetime    = 15 * time.Minute // time after which an ad expires unless renewed
+	topicAdRefresh     = 10 * time.Minute // interval of renewing the ads of the local node
+	topicQueryInterval = 30 * time.Second // minimum interval of querying a topic again
+	topicRegistrars    = 8                // number of nodes a topic is advertised at
+	maxAdsPerTopic     = 64               // maximum number of ads stored per topic
+	maxTopics          = 512              // maximum number of topics stored
+
+	// topicResponseSizeLimit is the space available for records in a topic
+	// query response, see packNodes.
+	topicResponseSizeLimit = 1000
+)
+
+const (
+	topicRegisterOp = iota
+	topicQueryOp
+)
+
+// topicRequest is the message of a topic TALKREQ. Registrations carry the record
+// of the advertising node.
+type topicRequest struct {
+	Op     uint
+	Topic  string
+	Record *enr.Record `rlp:"optional"`
+}
+
+// topicResponse is the message of a topic TALKRESP.
+type topicResponse struct {
+	Accepted bool
+	Records  []*enr.Record
+}
+
+// TopicID returns the position of a topic in the node ID space. Topic ads are
+// stored by the nodes closest to it.
+func TopicID(topic string) enode.ID {
+	return enode.ID(crypto.Keccak256Hash([]byte(topic)))
+}
+
+// topicTable stores the ads registered by other nodes.
+type topicTable struct {
+	mu    sync.Mutex
+	clock mclock.Clock
+	ads   map[string]map[enode.ID]*topicAd
+}
+
+type topicAd struct {
+	node    *enode.Node
+	expires mclock.AbsTime
+}
+
+func newTopicTable(clock mclock.Clock) *topicTable {
+	return &topicTable{clock: clock, ads: make(map[string]map[enode.ID]*topicAd)}
+}
+
+// add registers or renews an ad, reporting whether it was accepted. New ads are
+// rejected once the limits are reached.
+func (tt *topicTable) add(topic string, n *enode.Node) bool {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	now := tt.clock.Now()
+	tt.expire(now)
+
+	ads := tt.ads[topic]
+	if ads == nil {
+		if len(tt.ads) >= maxTopics {
+			return false
+		}
+		ads = make(map[enode.ID]*topicAd)
+		tt.ads[topic] = ads
+	}
+	if _, ok := ads[n.ID()]; !ok && len(ads) >= maxAdsPerTopic {
+		return false
+	}
+	ads[n.ID()] = &topicAd{node: n, expires: now.Add(topicAdLifetime)}
+	return true
+}
+
+// get returns the nodes advertising a topic in random order.
+func (tt *topicTable) get(topic string) []*enode.Node {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	tt.expire(tt.clock.Now())
+
+	nodes := make([]*enode.Node, 0, len(tt.ads[topic]))
+	for _, ad := range tt.ads[topic] {
+		nodes = append(nodes, ad.node)
+	}
+	rand.Shuffle(len(nodes), func(i, j int) { nodes[i], nodes[j] = nodes[j], nodes[i] })
+	return nodes
+}
+
+// expire drops the ads which weren't renewed in time.
+func (tt *topicTable) expire(now mclock.AbsTime) {
+	for topic, ads := range tt.ads {
+		for id, ad := range ads {
+			if ad.expires <= now {
+				delete(ads, id)
+			}
+		}
+		if len(ads) == 0 {
+			delete(tt.ads, topic)
+		}
+	}
+}
+
+// handleTopicRequest serves the topic TALKREQs of other nodes.
+func (t *UDPv5) handleTopicRequest(id enode.ID, addr *net.UDPAddr, msg []byte) []byte {
+	var req topicRequest
+	if err := rlp.DecodeBytes(msg, &req); err != nil {
+		t.log.Debug("Invalid topic request", "id", id, "addr", addr, "err", err)
+		return nil
+	}
+	var resp topicResponse
+	switch req.Op {
+	case topicRegisterOp:
+		if req.Record == nil {
+			return nil
+		}
+		// Nodes may only advertise themselves, at the endpoint they're sending from.
+		n, err := enode.New(t.validSchemes, req.Record)
+		if err != nil || n.ID() != id || !adEndpointMatches(n, addr) {
+			t.log.Debug("Rejected topic ad", "id", id, "addr", addr, "topic", req.Topic, "err", err)
+			return nil
+		}
+		resp.Accepted = t.topics.add(req.Topic, n)
+
+	case topicQueryOp:
+		var size uint64
+		for _, n := range t.topics.get(req.Topic) {
+			if size += n.Record().Size(); size > topicResponseSizeLimit {
+				break
+			}
+			resp.Records = append(resp.Records, n.Record())
+		}
+	default:
+		return nil
+	}
+	enc, _ := rlp.EncodeToBytes(&resp)
+	return enc
+}
+
+// adEndpointMatches reports whether the record of an ad announces the UDP endpoint
+// its registration was sent from, so that nodes can't advertise hosts they don't
+// control.
+func adEndpointMatches(n *enode.Node, addr *net.UDPAddr) bool {
+	if ip4 := addr.IP.To4(); ip4 != nil {
+		var (
+			ip   enr.IPv4
+			port enr.UDP
+		)
+		return n.Load(&ip) == nil && net.IP(ip).Equal(ip4) && n.Load(&port) == nil && int(port) == addr.Port
+	}
+	var ip enr.IPv6
+	if n.Load(&ip) != nil || !net.IP(ip).Equal(addr.IP) {
+		return false
+	}
+	// The IPv6 port defaults to the IPv4 one if the record doesn't set it.
+	var port6 enr.UDP6
+	if n.Load(&port6) == nil {
+		return int(port6) == addr.Port
+	}
+	var port enr.UDP
+	return n.Load(&port) == nil && int(port) == addr.Port
+}
+
+// topicCall sends a topic request to a node and decodes the response.
+func (t *UDPv5) topicCall(n *enode.Node, req *topicRequest) (*topicResponse, error) {
+	enc, err := rlp.EncodeToBytes(req)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := t.TalkRequest(n, topicProtocol, enc)
+	if err != nil {
+		return nil, err
+	}
+	var resp topicResponse
+	if err := rlp.DecodeBytes(msg, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RegisterTopic advertises the local node under the given topic at the nodes
+// closest to it, renewing the ads periodically until the returned function is
+// called or the transport is closed.
+func (t *UDPv5) RegisterTopic(topic string) (stop func()) {
+	ctx, cancel := context.WithCancel(t.closeCtx)
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		for {
+			t.registerTopic(ctx, topic)
+			select {
+			case <-t.clock.After(topicAdRefresh):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return cancel
+}
+
+// registerTopic registers the local node under the given topic once.
+func (t *UDPv5) registerTopic(ctx context.Context, topic string) {
+	var (
+		req      = &topicRequest{Op: topicRegisterOp, Topic: topic, Record: t.Self().Record()}
+		accepted int
+	)
+	for _, n := range t.newLookup(ctx, TopicID(topic)).run() {
+		if ctx.Err() != nil || accepted >= topicRegistrars {
+			break
+		}
+		resp, err := t.topicCall(n, req)
+		if err != nil {
+			t.log.Trace("Topic registration failed", "id", n.ID(), "topic", topic, "err", err)
+			continue
+		}
+		if resp.Accepted {
+			accepted++
+		}
+	}
+	t.log.Debug("Advertised topic", "topic", topic, "registrars", accepted)
+}
+
+// TopicQuery returns the nodes advertising the given topic, as known to the nodes
+// closest to the topic.
+func (t *UDPv5) TopicQuery(topic string) []*enode.Node {
+	return t.topicQuery(t.closeCtx, topic)
+}
+
+func (t *UDPv5) topicQuery(ctx context.Context, topic string) []*enode.Node {
+	var (
+		req     = &topicRequest{Op: topicQueryOp, Topic: topic}
+		seen    = make(map[enode.ID]struct{})
+		results []*enode.Node
+		queried int
+	)
+	for _, n := range t.newLookup(ctx, TopicID(topic)).run() {
+		if ctx.Err() != nil || queried >= topicRegistrars {
+			break
+		}
+		queried++
+		resp, err := t.topicCall(n, req)
+		if err != nil {
+			t.log.Trace("Topic query failed", "id", n.ID(), "topic", topic, "err", err)
+			continue
+		}
+		for _, r := range resp.Records {
+			node, err := enode.New(t.validSchemes, r)
+			if err != nil || node.ID() == t.Self().ID() {
+				continue
+			}
+			if _, ok := seen[node.ID()]; !ok {
+				seen[node.ID()] = struct{}{}
+				results = append(results, node)
+			}
+		}
+	}
+	return results
+}
+
+// TopicNodes returns an iterator over the nodes advertising the given topic. The
+// topic is queried again once all known nodes have been returned.
+func (t *UDPv5) TopicNodes(topic string) enode.Iterator {
+	ctx, cancel := context.WithCancel(t.closeCtx)
+	return &topicIterator{t: t, topic: topic, ctx: ctx, cancel: cancel}
+}
+
+// topicIterator implements TopicNodes.
+type topicIterator struct {
+	t       *UDPv5
+	topic   string
+	ctx     context.Context
+	cancel  func()
+	buffer  []*enode.Node
+	queried bool
+}
+
+// Node returns the current node.
+func (it *topicIterator) Node() *enode.Node {
+	if len(it.buffer) == 0 {
+		return nil
+	}
+	return it.buffer[0]
+}
+
+// Next moves to the next node.
+func (it *topicIterator) Next() bool {
+	if len(it.buffer) > 0 {
+		it.buffer = it.buffer[1:]
+	}
+	for len(it.buffer) == 0 {
+		// Rate-limit the queries, a topic may not be advertised at all.
+		if it.queried {
+			select {
+			case <-it.t.clock.After(topicQueryInterval):
+			case <-it.ctx.Done():
+			}
+		}
+		if it.ctx.Err() != nil {
+			it.buffer = nil
+			return false
+		}
+		it.buffer = it.t.topicQuery(it.ctx, it.topic)
+		it.queried = true
+	}
+	return true
+}
+
+// Close ends the iterator.
+func (it *topicIterator) Close() {
+	it.cancel()
+}
@@ -0,0 +1,152 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestTopicTable(t *testing.T) {
+	var (
+		clock = new(mclock.Simulated)
+		tab   = newTopicTable(clock)
+		nodes = make([]*enode.Node, maxAdsPerTopic+1)
+	)
+	for i := range nodes {
+		nodes[i] = enode.SignNull(new(enr.Record), enode.ID{byte(i), byte(i >> 8)})
+	}
+	for i, n := range nodes[:maxAdsPerTopic] {
+		if !tab.add("foo", n) {
+			t.Fatalf("ad %d rejected", i)
+		}
+	}
+	if tab.add("foo", nodes[maxAdsPerTopic]) {
+		t.Fatal("ad beyond the topic limit accepted")
+	}
+	if !tab.add("foo", nodes[0]) {
+		t.Fatal("renewal of existing ad rejected")
+	}
+	if len(tab.get("foo")) != maxAdsPerTopic || len(tab.get("bar")) != 0 {
+		t.Fatal("wrong number of ads")
+	}
+	// All ads except the renewed one expire.
+	clock.Run(topicAdLifetime - time.Second)
+	if !tab.add("foo", nodes[0]) {
+		t.Fatal("renewal of existing ad rejected")
+	}
+	clock.Run(time.Second)
+	if ads := tab.get("foo"); len(ads) != 1 || ads[0].ID() != nodes[0].ID() {
+		t.Fatalf("wrong ads after expiry: %v", ads)
+	}
+	clock.Run(topicAdLifetime)
+	if len(tab.get("foo")) != 0 || len(tab.ads) != 0 {
+		t.Fatal("expired topic not dropped")
+	}
+}
+
+// This test checks that topic ads are rejected unless they're signed by the
+// requesting node and announce the endpoint the request was sent from.
+func TestUDPv5_topicRegisterHandling(t *testing.T) {
+	t.Parallel()
+	test := newUDPV5Test(t)
+	defer test.close()
+
+	var (
+		addr  = &net.UDPAddr{IP: net.IP{10, 0, 0, 1}, Port: 30303}
+		local = enode.NewLocalNode(test.db, newkey())
+	)
+	local.SetStaticIP(addr.IP)
+	local.Set(enr.UDP(addr.Port))
+	other := local.Node()
+
+	register := func(from enode.ID, n *enode.Node) *topicResponse {
+		req, _ := rlp.EncodeToBytes(&topicRequest{Op: topicRegisterOp, Topic: "foo", Record: n.Record()})
+		enc := test.udp.handleTopicRequest(from, addr, req)
+		if enc == nil {
+			return nil
+		}
+		var resp topicResponse
+		if err := rlp.DecodeBytes(enc, &resp); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		return &resp
+	}
+	if resp := register(enode.PubkeyToIDV4(&test.remotekey.PublicKey), other); resp != nil {
+		t.Fatal("ad of another node accepted")
+	}
+	local.Set(enr.UDP(addr.Port + 1))
+	if resp := register(other.ID(), local.Node()); resp != nil {
+		t.Fatal("ad for another endpoint accepted")
+	}
+	if resp := register(other.ID(), other); resp == nil || !resp.Accepted {
+		t.Fatal("ad rejected")
+	}
+	if nodes := test.udp.topics.get("foo"); len(nodes) != 1 || nodes[0].ID() != other.ID() {
+		t.Fatalf("wrong ads: %v", nodes)
+	}
+}
+
+// Real sockets, real crypto: this test checks that advertised topics can be found.
+func TestUDPv5_topicE2E(t *testing.T) {
+	t.Parallel()
+
+	const N = 5
+	var nodes []*UDPv5
+	for i := 0; i < N; i++ {
+		var cfg Config
+		if len(nodes) > 0 {
+			cfg.Bootnodes = []*enode.Node{nodes[0].Self()}
+		}
+		node := startLocalhostV5(t, cfg)
+		nodes = append(nodes, node)
+		defer node.Close()
+	}
+	// Fill the tables of the advertisers, so they find all registrars.
+	for _, n := range nodes {
+		n.Lookup(n.Self().ID())
+	}
+	var want []enode.ID
+	for _, n := range nodes[1:3] {
+		stop := n.RegisterTopic("foo")
+		defer stop()
+		want = append(want, n.Self().ID())
+	}
+	slices.SortFunc(want, func(a, b enode.ID) int { return bytes.Compare(a[:], b[:]) })
+
+	var have []enode.ID
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(100 * time.Millisecond) {
+		have = have[:0]
+		for _, n := range nodes[N-1].TopicQuery("foo") {
+			have = append(have, n.ID())
+		}
+		slices.SortFunc(have, func(a, b enode.ID) int { return bytes.Compare(a[:], b[:]) })
+		if slices.Equal(have, want) {
+			return
+		}
+	}
+	t.Fatalf("wrong topic query result: have %v, want %v", fmt.Sprint(have), fmt.Sprint(want))
+}
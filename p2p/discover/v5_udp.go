@@ -28,6 +28,7 @@ import (
 	"net/netip"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common/mclock"
@@ -64,7 +65,7 @@ type UDPv5 struct {
 	// static fields
 	conn         UDPConn
 	tab          *Table
-	netrestrict  *netutil.Netlist
+	netrestrict  atomic.Pointer[netutil.Netlist]
 	priv         *ecdsa.PrivateKey
 	localNode    *enode.LocalNode
 	db           *enode.DB
@@ -78,6 +79,9 @@ type UDPv5 struct {
 	// talkreq handler registry
 	talk *talkSystem
 
+	// topic ads registered by other nodes
+	topics *topicTable
+
 	// channels into dispatch
 	packetInCh    chan ReadPacket
 	readNextCh    chan struct{}
@@ -153,7 +157,6 @@ func newUDPv5(conn UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv5, error) {
 		conn:         newMeteredConn(conn),
 		localNode:    ln,
 		db:           ln.Database(),
-		netrestrict:  cfg.NetRestrict,
 		priv:         cfg.PrivateKey,
 		log:          cfg.Log,
 		validSchemes: cfg.ValidSchemes,
@@ -175,7 +178,10 @@ func newUDPv5(conn UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv5, error) {
 		closeCtx:       closeCtx,
 		cancelCloseCtx: cancelCloseCtx,
 	}
+	t.netrestrict.Store(cfg.NetRestrict)
 	t.talk = newTalkSystem(t)
+	t.topics = newTopicTable(cfg.Clock)
+	t.talk.register(topicProtocol, t.handleTopicRequest)
 	tab, err := newTable(t, t.db, cfg)
 	if err != nil {
 		return nil, err
@@ -184,6 +190,12 @@ func newUDPv5(conn UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv5, error) {
 	return t, nil
 }
 
+// SetNetRestrict replaces the list of IP networks nodes are accepted from.
+// A nil list accepts all networks.
+func (t *UDPv5) SetNetRestrict(list *netutil.Netlist) {
+	t.netrestrict.Store(list)
+}
+
 // Self returns the local node record.
 func (t *UDPv5) Self() *enode.Node {
 	return t.localNode.Node()
@@ -431,7 +443,7 @@ func (t *UDPv5) verifyResponseNode(c *callV5, r *enr.Record, distances []uint, s
 	if err := netutil.CheckRelayAddr(c.addr.Addr(), node.IPAddr()); err != nil {
 		return nil, err
 	}
-	if t.netrestrict != nil && !t.netrestrict.ContainsAddr(node.IPAddr()) {
+	if nr := t.netrestrict.Load(); nr != nil && !nr.ContainsAddr(node.IPAddr()) {
 		return nil, errors.New("not contained in netrestrict list")
 	}
 	if node.UDP() <= 1024 {
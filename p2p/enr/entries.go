@@ -77,6 +77,17 @@ type UDP6 uint16
 
 func (v UDP6) ENRKey() string { return "udp6" }
 
+// WS is the "ws" key, which holds the port of the node's devp2p WebSocket endpoint.
+type WS uint16
+
+func (v WS) ENRKey() string { return "ws" }
+
+// WSS is the "wss" key, which holds the port of the node's devp2p WebSocket endpoint
+// secured by TLS.
+type WSS uint16
+
+func (v WSS) ENRKey() string { return "wss" }
+
 // ID is the "id" key, which holds the name of the identity scheme.
 type ID string
 
@@ -0,0 +1,113 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package msglimit enforces size and rate limits on the messages received from
+// the peers of a devp2p protocol.
+package msglimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"golang.org/x/time/rate"
+)
+
+// MaxFrameSize is the largest message the RLPx transport can carry.
+const MaxFrameSize = 1<<24 - 1
+
+var (
+	ErrTooLarge     = errors.New("message too long")
+	ErrRateExceeded = errors.New("message rate exceeded")
+)
+
+// Names maps the message codes of a protocol to their names.
+type Names map[uint64]string
+
+// Name returns the name of the message with the given code, or its hex code if
+// the message is unknown.
+func (n Names) Name(code uint64) string {
+	if name, ok := n[code]; ok {
+		return name
+	}
+	return fmt.Sprintf("%#02x", code)
+}
+
+// Code returns the code of the message with the given name.
+func (n Names) Code(name string) (uint64, bool) {
+	for code, have := range n {
+		if have == name {
+			return code, true
+		}
+	}
+	return 0, false
+}
+
+// Limits configures the limits of the messages received from a peer. Zero values
+// select the defaults.
+type Limits struct {
+	MaxSize uint32             // Maximum size of a message, the protocol default if zero
+	Rates   map[uint64]float64 // Maximum number of messages per second by code, unlimited if missing
+}
+
+// Validate checks that the limits can be enforced on the protocol with the given
+// messages.
+func (l Limits) Validate(names Names) error {
+	if l.MaxSize > MaxFrameSize {
+		return fmt.Errorf("maximum message size %d exceeds the transport limit %d", l.MaxSize, MaxFrameSize)
+	}
+	for code, r := range l.Rates {
+		if _, ok := names[code]; !ok {
+			return fmt.Errorf("rate limit of unknown message %#02x", code)
+		}
+		if r <= 0 {
+			return fmt.Errorf("invalid rate limit %v of message %s", r, names.Name(code))
+		}
+	}
+	return nil
+}
+
+// Limiter enforces the message limits of a peer.
+type Limiter struct {
+	maxSize uint32
+	rates   map[uint64]*rate.Limiter
+	names   Names
+}
+
+// New creates a limiter enforcing the given limits, using defaultSize as the
+// maximum message size if the limits don't set one.
+func New(limits Limits, defaultSize uint32, names Names) *Limiter {
+	l := &Limiter{maxSize: limits.MaxSize, rates: make(map[uint64]*rate.Limiter, len(limits.Rates)), names: names}
+	if l.maxSize == 0 {
+		l.maxSize = defaultSize
+	}
+	for code, r := range limits.Rates {
+		// Allow bursts of one second worth of messages.
+		l.rates[code] = rate.NewLimiter(rate.Limit(r), int(math.Ceil(r)))
+	}
+	return l
+}
+
+// Check returns an error if a message exceeds the limits.
+func (l *Limiter) Check(code uint64, size uint32) error {
+	if size > l.maxSize {
+		return fmt.Errorf("%w: %v > %v", ErrTooLarge, size, l.maxSize)
+	}
+	if limiter := l.rates[code]; limiter != nil && !limiter.Allow() {
+		return fmt.Errorf("%w: %s", ErrRateExceeded, l.names.Name(code))
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package msglimit
+
+import (
+	"errors"
+	"testing"
+)
+
+const (
+	pingMsg = 0x00
+	pongMsg = 0x01
+	dataMsg = 0x02
+)
+
+var testNames = Names{pingMsg: "ping", pongMsg: "pong", dataMsg: "data"}
+
+func TestLimiter(t *testing.T) {
+	l := New(Limits{
+		MaxSize: 1024,
+		Rates:   map[uint64]float64{pingMsg: 2},
+	}, 4096, testNames)
+	if err := l.Check(dataMsg, 1024); err != nil {
+		t.Fatalf("message within size limit rejected: %v", err)
+	}
+	if err := l.Check(dataMsg, 1025); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("oversized message not rejected: %v", err)
+	}
+	// The burst allows one second worth of messages.
+	for i := 0; i < 2; i++ {
+		if err := l.Check(pingMsg, 10); err != nil {
+			t.Fatalf("message %d within rate limit rejected: %v", i, err)
+		}
+	}
+	if err := l.Check(pingMsg, 10); !errors.Is(err, ErrRateExceeded) {
+		t.Fatalf("message over rate limit not rejected: %v", err)
+	}
+	// Other messages are not rate limited.
+	for i := 0; i < 10; i++ {
+		if err := l.Check(pongMsg, 10); err != nil {
+			t.Fatalf("unlimited message rejected: %v", err)
+		}
+	}
+	// The default size limit applies if none is configured.
+	l = New(Limits{}, 4096, testNames)
+	if err := l.Check(dataMsg, 4096); err != nil {
+		t.Fatalf("message within default size limit rejected: %v", err)
+	}
+	if err := l.Check(dataMsg, 4097); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("message over default size limit not rejected: %v", err)
+	}
+}
+
+func TestLimitsValidate(t *testing.T) {
+	tests := []struct {
+		limits Limits
+		valid  bool
+	}{
+		{Limits{}, true},
+		{Limits{MaxSize: MaxFrameSize}, true},
+		{Limits{MaxSize: MaxFrameSize + 1}, false},
+		{Limits{Rates: map[uint64]float64{pingMsg: 0.5}}, true},
+		{Limits{Rates: map[uint64]float64{pingMsg: 0}}, false},
+		{Limits{Rates: map[uint64]float64{0xff: 1}}, false},
+	}
+	for i, test := range tests {
+		if err := test.limits.Validate(testNames); (err == nil) != test.valid {
+			t.Errorf("test %d: unexpected validation result: %v", i, err)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	if code, ok := testNames.Code("pong"); !ok || code != pongMsg {
+		t.Errorf("wrong code for pong: %d, %v", code, ok)
+	}
+	if _, ok := testNames.Code("unknown"); ok {
+		t.Error("code found for unknown message")
+	}
+	if name := testNames.Name(0xff); name != "0xff" {
+		t.Errorf("wrong name of unknown message: %q", name)
+	}
+}
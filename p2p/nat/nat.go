@@ -49,6 +49,18 @@ type Interface interface {
 	String() string
 }
 
+// Refresher is implemented by port mappers which know when their mappings must be
+// renewed before the requested lifetime ends.
+type Refresher interface {
+	// MappingLifetime returns the lifetime granted by the gateway for the latest
+	// mapping of the internal port, or zero if it is unknown.
+	MappingLifetime(protocol string, intport int) time.Duration
+
+	// MappingsLost reports whether the gateway lost its port mappings since the
+	// previous call, which happens when it restarts.
+	MappingsLost() bool
+}
+
 // Parse parses a NAT interface description.
 // The following formats are currently accepted.
 // Note that mechanism names are not case-sensitive.
@@ -170,7 +182,7 @@ func UPnP() Interface {
 // address is nil, PMP will attempt to auto-discover the router.
 func PMP(gateway net.IP) Interface {
 	if gateway != nil {
-		return &pmp{gw: gateway, c: natpmp.NewClient(gateway)}
+		return newPMP(gateway, natpmp.NewClient(gateway))
 	}
 	return startautodisc("NAT-PMP", discoverPMP)
 }
@@ -217,6 +229,26 @@ func (n *autodisc) ExternalIP() (net.IP, error) {
 	return n.found.ExternalIP()
 }
 
+func (n *autodisc) MappingLifetime(protocol string, intport int) time.Duration {
+	if n.wait() != nil {
+		return 0
+	}
+	if r, ok := n.found.(Refresher); ok {
+		return r.MappingLifetime(protocol, intport)
+	}
+	return 0
+}
+
+func (n *autodisc) MappingsLost() bool {
+	if n.wait() != nil {
+		return false
+	}
+	if r, ok := n.found.(Refresher); ok {
+		return r.MappingsLost()
+	}
+	return false
+}
+
 func (n *autodisc) String() string {
 	n.mu.Lock()
 	defer n.mu.Unlock()
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"sync"
 	"time"
 
 	natpmp "github.com/jackpal/go-nat-pmp"
@@ -31,6 +32,15 @@ import (
 type pmp struct {
 	gw net.IP
 	c  *natpmp.Client
+
+	mu        sync.Mutex
+	lifetimes map[string]time.Duration // granted mapping lifetimes by protocol and internal port
+	epoch     uint32                   // gateway uptime reported by the last response
+	lost      bool                     // whether the gateway restarted since MappingsLost was called
+}
+
+func newPMP(gw net.IP, c *natpmp.Client) *pmp {
+	return &pmp{gw: gw, c: c, lifetimes: make(map[string]time.Duration)}
 }
 
 func (n *pmp) String() string {
@@ -42,9 +52,22 @@ func (n *pmp) ExternalIP() (net.IP, error) {
 	if err != nil {
 		return nil, err
 	}
+	n.updateEpoch(response.SecondsSinceStartOfEpoc)
 	return response.ExternalIPAddress[:], nil
 }
 
+// updateEpoch tracks the uptime of the gateway. A gateway reporting less uptime
+// than before has restarted and lost all port mappings.
+func (n *pmp) updateEpoch(epoch uint32) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if epoch < n.epoch {
+		n.lost = true
+	}
+	n.epoch = epoch
+}
+
 func (n *pmp) AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) (uint16, error) {
 	if lifetime <= 0 {
 		return 0, errors.New("lifetime must not be <= 0")
@@ -55,6 +78,12 @@ func (n *pmp) AddMapping(protocol string, extport, intport int, name string, lif
 	if err != nil {
 		return 0, err
 	}
+	n.updateEpoch(res.SecondsSinceStartOfEpoc)
+
+	// The gateway may grant a shorter lifetime than requested.
+	n.mu.Lock()
+	n.lifetimes[mappingKey(protocol, intport)] = time.Duration(res.PortMappingLifetimeInSeconds) * time.Second
+	n.mu.Unlock()
 
 	// NAT-PMP maps an alternative available port number if the requested port
 	// is already mapped to another address and returns success. Handling of
@@ -62,6 +91,24 @@ func (n *pmp) AddMapping(protocol string, extport, intport int, name string, lif
 	return res.MappedExternalPort, nil
 }
 
+func (n *pmp) MappingLifetime(protocol string, intport int) time.Duration {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.lifetimes[mappingKey(protocol, intport)]
+}
+
+func (n *pmp) MappingsLost() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	lost := n.lost
+	n.lost = false
+	return lost
+}
+
+func mappingKey(protocol string, intport int) string {
+	return fmt.Sprintf("%s/%d", strings.ToLower(protocol), intport)
+}
+
 func (n *pmp) DeleteMapping(protocol string, extport, intport int) (err error) {
 	// To destroy a mapping, send an add-port with an internalPort of
 	// the internal port to destroy, an external port of zero and a
@@ -81,7 +128,7 @@ func discoverPMP() Interface {
 			if _, err := c.GetExternalAddress(); err != nil {
 				found <- nil
 			} else {
-				found <- &pmp{gw, c}
+				found <- newPMP(gw, c)
 			}
 		}()
 	}
@@ -115,6 +115,9 @@ type Peer struct {
 	pingRecv chan struct{}
 	disc     chan DiscReason
 
+	// rep tracks the score of the peer if set
+	rep *reputation
+
 	// events receives message send / receive events if set
 	events   *event.Feed
 	testPipe *MsgPipeRW // for testing
@@ -214,6 +217,34 @@ func (p *Peer) Disconnect(reason DiscReason) {
 	}
 }
 
+// Penalize lowers the score of the peer for misbehaving, see the Penalty constants.
+// Peers whose score drops too low are disconnected and temporarily banned.
+func (p *Peer) Penalize(penalty int) {
+	if p.penalize(penalty) {
+		p.Disconnect(DiscUselessPeer)
+	}
+}
+
+func (p *Peer) penalize(penalty int) bool {
+	if p.rep == nil {
+		return false
+	}
+	if !p.rep.penalize(p.ID(), penalty) {
+		return false
+	}
+	p.log.Debug("Banning peer", "duration", banDuration)
+	return true
+}
+
+// Score returns the reputation score of the peer. It is zero for well-behaved
+// peers and negative for ones which were penalized recently.
+func (p *Peer) Score() int {
+	if p.rep == nil {
+		return 0
+	}
+	return p.rep.score(p.ID())
+}
+
 // String implements fmt.Stringer.
 func (p *Peer) String() string {
 	id := p.ID()
@@ -277,10 +308,16 @@ loop:
 				reason = r
 			} else {
 				reason = DiscNetworkError
+				if _, ok := err.(*peerError); ok {
+					p.penalize(PenaltyProtocolViolation)
+				}
 			}
 			break loop
 		case err = <-p.protoErr:
 			reason = discReasonForError(err)
+			if reason == DiscProtocolError || reason == DiscSubprotocolError {
+				p.penalize(PenaltyProtocolViolation)
+			}
 			break loop
 		case err = <-p.disc:
 			reason = discReasonForError(err)
@@ -496,6 +533,7 @@ type PeerInfo struct {
 	ENR     string   `json:"enr,omitempty"` // Ethereum Node Record
 	Enode   string   `json:"enode"`         // Node URL
 	ID      string   `json:"id"`            // Unique node identifier
+	Score   int      `json:"score"`         // Reputation score, negative for misbehaving peers
 	Name    string   `json:"name"`          // Name of the node, including client type, version, OS, custom data
 	Caps    []string `json:"caps"`          // Protocols advertised by this peer
 	Network struct {
@@ -504,6 +542,8 @@ type PeerInfo struct {
 		Inbound       bool   `json:"inbound"`
 		Trusted       bool   `json:"trusted"`
 		Static        bool   `json:"static"`
+		Reserved      bool   `json:"reserved"` // connected through a reserved slot
+		Class         string `json:"class,omitempty"`
 	} `json:"network"`
 	Protocols map[string]interface{} `json:"protocols"` // Sub-protocol specific metadata fields
 }
@@ -519,6 +559,7 @@ func (p *Peer) Info() *PeerInfo {
 	info := &PeerInfo{
 		Enode:     p.Node().URLv4(),
 		ID:        p.ID().String(),
+		Score:     p.Score(),
 		Name:      p.Fullname(),
 		Caps:      caps,
 		Protocols: make(map[string]interface{}, len(p.running)),
@@ -531,6 +572,10 @@ func (p *Peer) Info() *PeerInfo {
 	info.Network.Inbound = p.rw.is(inboundConn)
 	info.Network.Trusted = p.rw.is(trustedConn)
 	info.Network.Static = p.rw.is(staticDialedConn)
+	info.Network.Reserved = p.rw.is(reservedConn)
+	if p.rw.class != nil {
+		info.Network.Class = p.rw.class.name
+	}
 
 	// Gather all the running protocol infos
 	for _, proto := range p.running {
@@ -0,0 +1,119 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package permission
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+const (
+	contractCacheTTL    = time.Minute            // time a contract answer is reused
+	contractCallTimeout = 500 * time.Millisecond // maximum time of a single contract call
+	maxContractCache    = 4096                   // maximum number of cached answers
+)
+
+// isNodeAllowedSelector is the selector of the allowlist contract method
+//
+//	function isNodeAllowed(bytes32 nodeID) external view returns (bool)
+var isNodeAllowedSelector = crypto.Keccak256([]byte("isNodeAllowed(bytes32)"))[:4]
+
+// Contract permits the nodes allowed by an allowlist contract. Answers are cached
+// for a short time, so membership changes take effect within contractCacheTTL.
+// Nodes are denied if the contract can't be called.
+//
+// The contract is called against the local chain state, which must contain it for
+// any node to be permitted. A node that doesn't have the contract in its genesis
+// state can therefore only sync from its trusted and static peers and boot nodes,
+// which the p2p server exempts from the check, until it has imported the block
+// deploying the contract.
+type Contract struct {
+	caller  ethereum.ContractCaller
+	address common.Address
+	clock   mclock.Clock
+
+	mu    sync.Mutex
+	cache map[enode.ID]contractAnswer
+}
+
+type contractAnswer struct {
+	allowed bool
+	expires mclock.AbsTime
+}
+
+// NewContract creates a policy backed by the allowlist contract at address.
+func NewContract(caller ethereum.ContractCaller, address common.Address) *Contract {
+	return &Contract{
+		caller:  caller,
+		address: address,
+		clock:   mclock.System{},
+		cache:   make(map[enode.ID]contractAnswer),
+	}
+}
+
+// Permitted reports whether the contract allows the node.
+func (c *Contract) Permitted(n *enode.Node) bool {
+	id := n.ID()
+	now := c.clock.Now()
+
+	c.mu.Lock()
+	answer, ok := c.cache[id]
+	c.mu.Unlock()
+	if ok && answer.expires > now {
+		return answer.allowed
+	}
+
+	allowed, err := c.call(id)
+	if err != nil {
+		log.Warn("Node permission check failed", "id", id, "contract", c.address, "err", err)
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.cache) >= maxContractCache {
+		for id, answer := range c.cache {
+			if answer.expires <= now {
+				delete(c.cache, id)
+			}
+		}
+	}
+	if len(c.cache) < maxContractCache {
+		c.cache[id] = contractAnswer{allowed: allowed, expires: now.Add(contractCacheTTL)}
+	}
+	return allowed
+}
+
+// call queries the contract for a node at the latest block.
+func (c *Contract) call(id enode.ID) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), contractCallTimeout)
+	defer cancel()
+
+	data := append(common.CopyBytes(isNodeAllowedSelector), id[:]...)
+	out, err := c.caller.CallContract(ctx, ethereum.CallMsg{To: &c.address, Data: data}, nil)
+	if err != nil {
+		return false, err
+	}
+	return len(out) == 32 && out[31] == 1, nil
+}
@@ -0,0 +1,200 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package permission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func testNode(t *testing.T) *enode.Node {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return enode.NewV4(&key.PublicKey, nil, 0, 0)
+}
+
+func TestPolicy(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		signer  = crypto.PubkeyToAddress(key.PublicKey)
+		allowed = testNode(t)
+		denied  = testNode(t)
+		path    = filepath.Join(t.TempDir(), "policy.json")
+	)
+	policy := &Policy{Version: 2, Nodes: []enode.ID{allowed.ID()}}
+	if err := policy.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(policy)
+	os.WriteFile(path, data, 0600)
+
+	loaded, err := LoadPolicy(path, signer, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Permitted(allowed) || loaded.Permitted(denied) {
+		t.Fatal("wrong permissions")
+	}
+	if _, err := LoadPolicy(path, common.Address{1}, ""); err != errInvalidSignature {
+		t.Fatalf("wrong error for foreign signer: %v", err)
+	}
+	// Tampering with the node list or version invalidates the signature.
+	tampered := *policy
+	tampered.Nodes = append(tampered.Nodes, denied.ID())
+	data, _ = json.Marshal(&tampered)
+	os.WriteFile(path, data, 0600)
+	if _, err := LoadPolicy(path, signer, ""); err != errInvalidSignature {
+		t.Fatalf("wrong error for modified policy: %v", err)
+	}
+	tampered = *policy
+	tampered.Version++
+	data, _ = json.Marshal(&tampered)
+	os.WriteFile(path, data, 0600)
+	if _, err := LoadPolicy(path, signer, ""); err != errInvalidSignature {
+		t.Fatalf("wrong error for modified version: %v", err)
+	}
+	// Signatures of the bare node list aren't accepted.
+	tampered = *policy
+	tampered.Signature, _ = crypto.Sign(crypto.Keccak256(allowed.ID().Bytes()), key)
+	if err := tampered.Verify(signer); err != errInvalidSignature {
+		t.Fatalf("wrong error for signature without domain: %v", err)
+	}
+}
+
+func TestPolicyVersion(t *testing.T) {
+	var (
+		key, _      = crypto.GenerateKey()
+		signer      = crypto.PubkeyToAddress(key.PublicKey)
+		dir         = t.TempDir()
+		path        = filepath.Join(dir, "policy.json")
+		versionPath = filepath.Join(dir, "instance", "permission-version")
+	)
+	write := func(p *Policy) {
+		t.Helper()
+		if err := p.Sign(key); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := json.Marshal(p)
+		os.WriteFile(path, data, 0600)
+	}
+	write(&Policy{Version: 5})
+	if _, err := LoadPolicy(path, signer, versionPath); err != nil {
+		t.Fatal(err)
+	}
+	// The same and newer versions are accepted, older ones are rejected.
+	if _, err := LoadPolicy(path, signer, versionPath); err != nil {
+		t.Fatalf("reloading policy failed: %v", err)
+	}
+	write(&Policy{Version: 4})
+	if _, err := LoadPolicy(path, signer, versionPath); !errors.Is(err, errPolicyRollback) {
+		t.Fatalf("wrong error for older policy: %v", err)
+	}
+	write(&Policy{Version: 6})
+	if _, err := LoadPolicy(path, signer, versionPath); err != nil {
+		t.Fatalf("loading newer policy failed: %v", err)
+	}
+	write(&Policy{Version: 5})
+	if _, err := LoadPolicy(path, signer, versionPath); !errors.Is(err, errPolicyRollback) {
+		t.Fatalf("wrong error for superseded policy: %v", err)
+	}
+
+	// Expired policies are rejected.
+	write(&Policy{Version: 7, Expiry: uint64(time.Now().Add(-time.Minute).Unix())})
+	if _, err := LoadPolicy(path, signer, versionPath); err != errPolicyExpired {
+		t.Fatalf("wrong error for expired policy: %v", err)
+	}
+	allowed := testNode(t)
+	expiring := &Policy{Version: 7, Expiry: uint64(time.Now().Add(time.Hour).Unix()), Nodes: []enode.ID{allowed.ID()}}
+	write(expiring)
+	loaded, err := LoadPolicy(path, signer, versionPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Permitted(allowed) {
+		t.Fatal("node not permitted before expiry")
+	}
+	loaded.Expiry = uint64(time.Now().Unix())
+	if loaded.Permitted(allowed) {
+		t.Fatal("node permitted after expiry")
+	}
+}
+
+type testCaller struct {
+	allowed map[enode.ID]bool
+	calls   int
+	err     error
+}
+
+func (c *testCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, number *big.Int) ([]byte, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	if !bytes.Equal(msg.Data[:4], isNodeAllowedSelector) {
+		return nil, errors.New("unknown method")
+	}
+	out := make([]byte, 32)
+	if c.allowed[enode.ID(msg.Data[4:])] {
+		out[31] = 1
+	}
+	return out, nil
+}
+
+func TestContract(t *testing.T) {
+	var (
+		allowed = testNode(t)
+		denied  = testNode(t)
+		caller  = &testCaller{allowed: map[enode.ID]bool{allowed.ID(): true}}
+		clock   = new(mclock.Simulated)
+		c       = NewContract(caller, common.Address{1})
+	)
+	c.clock = clock
+
+	if !c.Permitted(allowed) || c.Permitted(denied) {
+		t.Fatal("wrong permissions")
+	}
+	// Answers are cached.
+	c.Permitted(allowed)
+	if caller.calls != 2 {
+		t.Fatalf("wrong number of calls: %d", caller.calls)
+	}
+	// Membership changes apply after the cache expires, failures deny.
+	delete(caller.allowed, allowed.ID())
+	clock.Run(contractCacheTTL + time.Second)
+	if c.Permitted(allowed) {
+		t.Fatal("removed node permitted")
+	}
+	caller.err = errors.New("call failed")
+	if c.Permitted(testNode(t)) {
+		t.Fatal("node permitted on failed call")
+	}
+}
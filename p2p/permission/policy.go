@@ -0,0 +1,172 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package permission implements node permissioning for networks with a restricted
+// membership. Nodes are permitted either by a policy file signed by the network
+// operator or by an allowlist contract.
+package permission
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+var (
+	errInvalidSignature = errors.New("invalid policy signature")
+	errPolicyExpired    = errors.New("policy expired")
+	errPolicyRollback   = errors.New("policy older than the last loaded one")
+)
+
+// policyDomain prefixes the signed data of policies, so that their signatures
+// can't be confused with other data signed by the network operator.
+const policyDomain = "geth node permission policy"
+
+// Policy is a list of permitted nodes, signed by the network operator.
+type Policy struct {
+	// Version increases with every policy issued by the operator. Nodes reject
+	// policies older than the last one they loaded.
+	Version uint64 `json:"version"`
+	// Expiry is the unix time at which the policy stops permitting nodes. Zero
+	// means the policy doesn't expire.
+	Expiry    uint64        `json:"expiry,omitempty"`
+	Nodes     []enode.ID    `json:"nodes"`
+	Signature hexutil.Bytes `json:"signature"`
+
+	permitted map[enode.ID]struct{}
+}
+
+// LoadPolicy reads a policy file and verifies that it is signed by signer and
+// hasn't expired. If versionPath is not empty, the version of the policy is
+// recorded in that file, and policies older than the recorded version are
+// rejected.
+func LoadPolicy(path string, signer common.Address, versionPath string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid policy file: %v", err)
+	}
+	if err := p.Verify(signer); err != nil {
+		return nil, err
+	}
+	if p.expired() {
+		return nil, errPolicyExpired
+	}
+	if versionPath != "" {
+		if err := checkVersion(versionPath, p.Version); err != nil {
+			return nil, err
+		}
+	}
+	p.permitted = make(map[enode.ID]struct{}, len(p.Nodes))
+	for _, id := range p.Nodes {
+		p.permitted[id] = struct{}{}
+	}
+	return &p, nil
+}
+
+// checkVersion rejects policy versions older than the one recorded at path, and
+// records newer ones.
+func checkVersion(path string, version uint64) error {
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		last, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid policy version file: %v", err)
+		}
+		if version < last {
+			return fmt.Errorf("%w: version %d, last loaded %d", errPolicyRollback, version, last)
+		}
+		if version == last {
+			return nil
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(strconv.FormatUint(version, 10)), 0600)
+}
+
+// expired reports whether the policy has passed its expiry time.
+func (p *Policy) expired() bool {
+	return p.Expiry != 0 && uint64(time.Now().Unix()) >= p.Expiry
+}
+
+// sigHash returns the hash covered by the signature.
+func (p *Policy) sigHash() []byte {
+	data := make([]byte, 0, len(policyDomain)+16+len(p.Nodes)*len(enode.ID{}))
+	data = append(data, policyDomain...)
+	data = binary.BigEndian.AppendUint64(data, p.Version)
+	data = binary.BigEndian.AppendUint64(data, p.Expiry)
+	for _, id := range p.Nodes {
+		data = append(data, id[:]...)
+	}
+	return crypto.Keccak256(data)
+}
+
+// Sign signs the policy with the given key.
+func (p *Policy) Sign(key *ecdsa.PrivateKey) error {
+	sig, err := crypto.Sign(p.sigHash(), key)
+	if err != nil {
+		return err
+	}
+	p.Signature = sig
+	return nil
+}
+
+// Verify checks that the policy is signed by signer.
+func (p *Policy) Verify(signer common.Address) error {
+	if len(p.Signature) != crypto.SignatureLength {
+		return errInvalidSignature
+	}
+	pub, err := crypto.SigToPub(p.sigHash(), p.Signature)
+	if err != nil || crypto.PubkeyToAddress(*pub) != signer {
+		return errInvalidSignature
+	}
+	return nil
+}
+
+// Permitted reports whether the node is listed in the policy. No node is
+// permitted once the policy has expired.
+func (p *Policy) Permitted(n *enode.Node) bool {
+	if p.expired() {
+		return false
+	}
+	if p.permitted == nil {
+		return slices.Contains(p.Nodes, n.ID())
+	}
+	_, ok := p.permitted[n.ID()]
+	return ok
+}
@@ -0,0 +1,131 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// Penalties applied to the score of misbehaving peers. A peer whose score drops to
+// -banThreshold is disconnected and banned for banDuration.
+const (
+	PenaltyUselessMessage    = 5
+	PenaltyTimeout           = 10
+	PenaltyInvalidMessage    = 25
+	PenaltyProtocolViolation = 50
+)
+
+const (
+	banThreshold  = 100
+	banDuration   = 30 * time.Minute
+	scoreRecovery = time.Minute // time to recover one point of score
+	maxReputation = 4096        // maximum number of tracked nodes
+)
+
+// reputation tracks the scores of nodes across connections. Scores start at
+// zero, drop on every penalty and recover over time.
+type reputation struct {
+	mu     sync.Mutex
+	clock  mclock.Clock
+	scores map[enode.ID]*nodeScore
+}
+
+type nodeScore struct {
+	score       int
+	updated     mclock.AbsTime
+	bannedUntil mclock.AbsTime
+}
+
+func newReputation(clock mclock.Clock) *reputation {
+	return &reputation{clock: clock, scores: make(map[enode.ID]*nodeScore)}
+}
+
+// penalize lowers the score of a node, reporting whether the node is banned as a
+// result of it.
+func (r *reputation) penalize(id enode.ID, penalty int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	s := r.scores[id]
+	if s == nil {
+		if len(r.scores) >= maxReputation {
+			r.expire(now)
+		}
+		if len(r.scores) >= maxReputation {
+			return false
+		}
+		s = &nodeScore{updated: now}
+		r.scores[id] = s
+	}
+	s.recover(now)
+	if s.bannedUntil > now {
+		return true
+	}
+	if s.score -= penalty; s.score <= -banThreshold {
+		s.score = 0
+		s.bannedUntil = now.Add(banDuration)
+		return true
+	}
+	return false
+}
+
+// score returns the current score of a node.
+func (r *reputation) score(id enode.ID) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.scores[id]
+	if s == nil {
+		return 0
+	}
+	s.recover(r.clock.Now())
+	return s.score
+}
+
+// banned reports whether a node is currently banned.
+func (r *reputation) banned(id enode.ID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.scores[id]
+	return s != nil && s.bannedUntil > r.clock.Now()
+}
+
+// expire drops nodes which have fully recovered and aren't banned.
+func (r *reputation) expire(now mclock.AbsTime) {
+	for id, s := range r.scores {
+		s.recover(now)
+		if s.score == 0 && s.bannedUntil <= now {
+			delete(r.scores, id)
+		}
+	}
+}
+
+// recover raises the score for the time passed since the last update.
+func (s *nodeScore) recover(now mclock.AbsTime) {
+	points := int(time.Duration(now-s.updated) / scoreRecovery)
+	if points == 0 {
+		return
+	}
+	s.score = min(s.score+points, 0)
+	s.updated = s.updated.Add(time.Duration(points) * scoreRecovery)
+}
@@ -0,0 +1,65 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func TestReputation(t *testing.T) {
+	var (
+		clock = new(mclock.Simulated)
+		rep   = newReputation(clock)
+		id    = enode.ID{1}
+	)
+	if rep.penalize(id, PenaltyInvalidMessage) {
+		t.Fatal("banned after a single penalty")
+	}
+	if s := rep.score(id); s != -PenaltyInvalidMessage {
+		t.Fatalf("wrong score %d", s)
+	}
+	// The score recovers over time.
+	clock.Run(5 * scoreRecovery)
+	if s := rep.score(id); s != -PenaltyInvalidMessage+5 {
+		t.Fatalf("wrong score after recovery %d", s)
+	}
+	clock.Run(time.Hour)
+	if s := rep.score(id); s != 0 {
+		t.Fatalf("score recovered beyond zero: %d", s)
+	}
+	// Repeated penalties lead to a ban, which expires.
+	for i := 0; i < banThreshold/PenaltyProtocolViolation-1; i++ {
+		if rep.penalize(id, PenaltyProtocolViolation) {
+			t.Fatalf("banned after %d penalties", i+1)
+		}
+	}
+	if !rep.penalize(id, PenaltyProtocolViolation) || !rep.banned(id) {
+		t.Fatal("not banned")
+	}
+	clock.Run(banDuration)
+	if rep.banned(id) {
+		t.Fatal("ban didn't expire")
+	}
+	rep.expire(clock.Now())
+	if len(rep.scores) != 0 {
+		t.Fatal("recovered node not dropped")
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package sentry implements the relay protocol between a validator and its sentry
+// nodes.
+//
+// In a sentry setup, the validator connects only to sentry nodes run by the same
+// operator, and the sentries handle the public network. The relay protocol runs
+// alongside the regular protocols on these links. It verifies that both ends agree
+// on their roles, and lets sentries report their public connectivity, so the
+// validator knows whether it is still connected to the network.
+package sentry
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// Constants to match up protocol versions and messages.
+const (
+	ProtocolName    = "sentry"
+	ProtocolVersion = 1
+	protocolLength  = 2
+)
+
+const (
+	StatusMsg = 0x00
+	PeersMsg  = 0x01
+)
+
+const (
+	maxMessageSize   = 1024
+	handshakeTimeout = 5 * time.Second
+	reportInterval   = 10 * time.Second // interval of public peer count reports
+)
+
+var (
+	errUnexpectedRole = errors.New("unexpected role")
+	errUnexpectedMsg  = errors.New("unexpected message")
+	errMsgTooLarge    = errors.New("message too long")
+)
+
+// Role is the role of a node in the sentry setup.
+type Role uint8
+
+const (
+	RoleValidator Role = iota + 1
+	RoleSentry
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleValidator:
+		return "validator"
+	case RoleSentry:
+		return "sentry"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(r))
+	}
+}
+
+// StatusPacket is exchanged when a link is established.
+type StatusPacket struct {
+	Role Role
+}
+
+// PeersPacket is sent by sentries to report their number of public peers.
+type PeersPacket struct {
+	Peers uint64
+}
+
+// Server is the part of the p2p server used by the relay.
+type Server interface {
+	Peers() []*p2p.Peer
+}
+
+// Relay runs the relay protocol on the links of a validator or sentry.
+type Relay struct {
+	role   Role
+	server Server
+	links  map[enode.ID]bool // counterparts of the setup
+
+	mu    sync.Mutex
+	peers map[enode.ID]*PeerInfo
+}
+
+// PeerInfo is the information about a link, exposed in the peer info of the node.
+type PeerInfo struct {
+	Role        string `json:"role"`
+	PublicPeers uint64 `json:"publicPeers"` // reported by sentries
+}
+
+// New creates the relay of a node with the given role. The links are the sentries
+// of a validator, or the validators of a sentry.
+func New(role Role, server Server, links []*enode.Node) *Relay {
+	r := &Relay{
+		role:   role,
+		server: server,
+		links:  make(map[enode.ID]bool, len(links)),
+		peers:  make(map[enode.ID]*PeerInfo),
+	}
+	for _, n := range links {
+		r.links[n.ID()] = true
+	}
+	return r
+}
+
+// Protocols returns the p2p protocol definitions of the relay.
+func (r *Relay) Protocols() []p2p.Protocol {
+	return []p2p.Protocol{{
+		Name:    ProtocolName,
+		Version: ProtocolVersion,
+		Length:  protocolLength,
+		Run:     r.run,
+		NodeInfo: func() interface{} {
+			return r.role.String()
+		},
+		PeerInfo: func(id enode.ID) interface{} {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if info := r.peers[id]; info != nil {
+				copy := *info
+				return &copy
+			}
+			return nil
+		},
+	}}
+}
+
+func (r *Relay) run(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+	// Other nodes supporting the protocol aren't part of this setup. Keep the
+	// protocol idle for them, so the connection isn't affected.
+	if !r.links[p.ID()] {
+		for {
+			msg, err := rw.ReadMsg()
+			if err != nil {
+				return err
+			}
+			msg.Discard()
+		}
+	}
+
+	remote, err := r.handshake(rw)
+	if err != nil {
+		p.Log().Debug("Sentry handshake failed", "err", err)
+		return err
+	}
+	info := &PeerInfo{Role: remote.String()}
+	r.mu.Lock()
+	r.peers[p.ID()] = info
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.peers, p.ID())
+		r.mu.Unlock()
+	}()
+	p.Log().Info("Sentry link established", "remote", remote)
+
+	if r.role == RoleSentry {
+		quit := make(chan struct{})
+		defer close(quit)
+		go r.report(rw, quit)
+	}
+	return r.readLoop(p, rw, info)
+}
+
+// handshake exchanges the roles of both ends and checks they are complementary.
+func (r *Relay) handshake(rw p2p.MsgReadWriter) (Role, error) {
+	var (
+		status StatusPacket
+		errc   = make(chan error, 2)
+	)
+	go func() {
+		errc <- p2p.Send(rw, StatusMsg, &StatusPacket{Role: r.role})
+	}()
+	go func() {
+		errc <- readStatus(rw, &status)
+	}()
+	timeout := time.NewTimer(handshakeTimeout)
+	defer timeout.Stop()
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errc:
+			if err != nil {
+				return 0, err
+			}
+		case <-timeout.C:
+			return 0, p2p.DiscReadTimeout
+		}
+	}
+	want := RoleSentry
+	if r.role == RoleSentry {
+		want = RoleValidator
+	}
+	if status.Role != want {
+		return 0, fmt.Errorf("%w: %v, want %v", errUnexpectedRole, status.Role, want)
+	}
+	return status.Role, nil
+}
+
+func readStatus(rw p2p.MsgReadWriter, status *StatusPacket) error {
+	msg, err := rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	defer msg.Discard()
+	if msg.Code != StatusMsg {
+		return fmt.Errorf("%w: first message has code %x", errUnexpectedMsg, msg.Code)
+	}
+	if msg.Size > maxMessageSize {
+		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
+	}
+	return msg.Decode(status)
+}
+
+// readLoop handles the messages of a link.
+func (r *Relay) readLoop(p *p2p.Peer, rw p2p.MsgReadWriter, info *PeerInfo) error {
+	for {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return err
+		}
+		if msg.Size > maxMessageSize {
+			return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
+		}
+		switch {
+		case msg.Code == PeersMsg && r.role == RoleValidator:
+			var report PeersPacket
+			if err := msg.Decode(&report); err != nil {
+				return fmt.Errorf("invalid peers report: %v", err)
+			}
+			r.mu.Lock()
+			changed := info.PublicPeers != report.Peers
+			info.PublicPeers = report.Peers
+			r.mu.Unlock()
+			if changed && report.Peers == 0 {
+				p.Log().Warn("Sentry has no public peers")
+			}
+		default:
+			msg.Discard()
+			return fmt.Errorf("%w: code %x", errUnexpectedMsg, msg.Code)
+		}
+		msg.Discard()
+	}
+}
+
+// report periodically sends the number of public peers to a validator.
+func (r *Relay) report(rw p2p.MsgWriter, quit <-chan struct{}) {
+	ticker := time.NewTicker(reportInterval)
+	defer ticker.Stop()
+	for {
+		if err := p2p.Send(rw, PeersMsg, &PeersPacket{Peers: r.publicPeers()}); err != nil {
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-quit:
+			return
+		}
+	}
+}
+
+// publicPeers counts the connected peers which are not validators.
+func (r *Relay) publicPeers() uint64 {
+	var n uint64
+	for _, p := range r.server.Peers() {
+		if !r.links[p.ID()] {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package sentry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+type testServer []*p2p.Peer
+
+func (s testServer) Peers() []*p2p.Peer { return s }
+
+func newTestPeer(id byte) *p2p.Peer {
+	return p2p.NewPeer(enode.ID{id}, "test", nil)
+}
+
+// runLink runs the relay of both ends of a link, returning the errors of both.
+func runLink(a, b *Relay, peerA, peerB *p2p.Peer) (rwA *p2p.MsgPipeRW, errs chan error) {
+	rwA, rwB := p2p.MsgPipe()
+	errs = make(chan error, 2)
+	go func() { errs <- a.run(peerB, rwA) }()
+	go func() { errs <- b.run(peerA, rwB) }()
+	return rwA, errs
+}
+
+func TestRelayLink(t *testing.T) {
+	var (
+		validatorPeer = newTestPeer(1)
+		sentryPeer    = newTestPeer(2)
+		public        = testServer{newTestPeer(3), newTestPeer(4), validatorPeer}
+	)
+	validator := New(RoleValidator, testServer{sentryPeer}, []*enode.Node{sentryPeer.Node()})
+	sentry := New(RoleSentry, public, []*enode.Node{validatorPeer.Node()})
+
+	rw, errs := runLink(validator, sentry, validatorPeer, sentryPeer)
+	defer rw.Close()
+
+	// Wait for the sentry's report to arrive at the validator.
+	info := validator.Protocols()[0].PeerInfo
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if pi, _ := info(sentryPeer.ID()).(*PeerInfo); pi != nil && pi.PublicPeers == 2 {
+			if pi.Role != "sentry" {
+				t.Fatalf("wrong role %q", pi.Role)
+			}
+			break
+		}
+		if time.Since(start) > 2*time.Second {
+			t.Fatal("no peers report received")
+		}
+		select {
+		case err := <-errs:
+			t.Fatal("link failed:", err)
+		default:
+		}
+	}
+}
+
+func TestRelayRoleMismatch(t *testing.T) {
+	var (
+		peerA = newTestPeer(1)
+		peerB = newTestPeer(2)
+	)
+	a := New(RoleValidator, testServer{}, []*enode.Node{peerB.Node()})
+	b := New(RoleValidator, testServer{}, []*enode.Node{peerA.Node()})
+
+	rw, errs := runLink(a, b, peerA, peerB)
+	defer rw.Close()
+	if err := <-errs; !errors.Is(err, errUnexpectedRole) {
+		t.Fatalf("wrong error %v", err)
+	}
+}
+
+// Nodes which are not configured as links must not be disconnected.
+func TestRelayIgnoresOtherNodes(t *testing.T) {
+	var (
+		peerA = newTestPeer(1)
+		peerB = newTestPeer(2)
+	)
+	a := New(RoleSentry, testServer{}, nil)
+	b := New(RoleValidator, testServer{}, []*enode.Node{peerA.Node()})
+
+	rw, errs := runLink(a, b, peerA, peerB)
+	defer rw.Close()
+	select {
+	case err := <-errs:
+		t.Fatal("link terminated:", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -37,10 +37,12 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/discover"
+	"github.com/ethereum/go-ethereum/p2p/dnsdisc"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/p2p/nat"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -51,6 +53,11 @@ const (
 	// sources.
 	discmixTimeout = 5 * time.Second
 
+	// Bootstrap nodes resolved from DNS node lists are limited by count and time
+	// spent resolving them, so startup doesn't block on large trees.
+	dnsBootstrapNodes   = 32
+	dnsBootstrapTimeout = 10 * time.Second
+
 	// Connectivity defaults.
 	defaultMaxPendingPeers = 50
 	defaultDialRatio       = 3
@@ -70,8 +77,14 @@ var (
 	errServerStopped       = errors.New("server stopped")
 	errEncHandshakeError   = errors.New("rlpx enc error")
 	errProtoHandshakeError = errors.New("rlpx proto error")
+	errNotPermitted        = errors.New("node not permitted")
 )
 
+// NodePermissions decides which nodes may be connected to.
+type NodePermissions interface {
+	Permitted(n *enode.Node) bool
+}
+
 // Config holds Server options.
 type Config struct {
 	// This field must be set to a valid secp256k1 private key.
@@ -91,6 +104,31 @@ type Config struct {
 	// Setting DialRatio to zero defaults it to 3.
 	DialRatio int `toml:",omitempty"`
 
+	// Dial scheduling policy, zero values select the defaults.
+	//
+	// RedialInterval is the minimum time between dials to the same node. Remote
+	// nodes throttle inbound connections from the same IP for 30s, so shorter
+	// intervals are only useful on LANs.
+	//
+	// MaxRedialBackoff caps the backoff of static nodes which repeatedly fail to
+	// connect. Their redial interval doubles with every failure, up to this value.
+	// Zero disables the backoff.
+	//
+	// MaxResolveDelay caps the backoff of endpoint lookups for static nodes
+	// without IP. It defaults to one hour.
+	//
+	// DialPriority orders the sources of dial candidates: "static", "bootnodes" and
+	// "discovery". Bootstrap nodes are dialed directly only if they are listed. The
+	// default is static nodes first, then discovered ones.
+	//
+	// KeepStaticPeers redials static nodes immediately when they disconnect, and
+	// lets static dials bypass the dial slot limit.
+	RedialInterval   time.Duration `toml:",omitempty"`
+	MaxRedialBackoff time.Duration `toml:",omitempty"`
+	MaxResolveDelay  time.Duration `toml:",omitempty"`
+	DialPriority     []string      `toml:",omitempty"`
+	KeepStaticPeers  bool          `toml:",omitempty"`
+
 	// NoDiscovery can be used to disable the peer discovery mechanism.
 	// Disabling is useful for protocol debugging (manual topology).
 	NoDiscovery bool
@@ -102,6 +140,10 @@ type Config struct {
 	// protocol should be started or not.
 	DiscoveryV5 bool `toml:",omitempty"`
 
+	// DiscoveryTopics are the topics advertised via V5 discovery. Nodes advertising
+	// them are preferred dial candidates. It has no effect unless DiscoveryV5 is set.
+	DiscoveryTopics []string `toml:",omitempty"`
+
 	// Name sets the node name of this server.
 	Name string `toml:"-"`
 
@@ -114,6 +156,10 @@ type Config struct {
 	// protocol.
 	BootstrapNodesV5 []*enode.Node `toml:",omitempty"`
 
+	// BootstrapDNS are enrtree:// URLs of EIP-1459 node lists. Nodes resolved from
+	// them at startup are added to the bootstrap nodes of both discovery protocols.
+	BootstrapDNS []string `toml:",omitempty"`
+
 	// Static nodes are used as pre-configured connections which are always
 	// maintained and re-connected on disconnects.
 	StaticNodes []*enode.Node
@@ -122,6 +168,32 @@ type Config struct {
 	// allowed to connect, even above the peer limit.
 	TrustedNodes []*enode.Node
 
+	// Sentries puts the server into validator mode if set. A validator connects
+	// only to its sentry nodes, which handle the public network on its behalf.
+	// Discovery is disabled and connections to any other node are rejected.
+	Sentries []*enode.Node `toml:",omitempty"`
+
+	// SentryValidators are the validators behind this node if it is a sentry.
+	// They are kept connected as trusted peers.
+	SentryValidators []*enode.Node `toml:",omitempty"`
+
+	// PeerClasses reserve peer slots for important nodes, which can connect even
+	// when MaxPeers is reached. The reserved slots are taken out of MaxPeers.
+	PeerClasses []PeerClass `toml:",omitempty"`
+
+	// Bandwidth limits in bytes per second, zero meaning unlimited. The peer limits
+	// apply to each connection, the global ones to all connections combined.
+	MaxPeerIngress int `toml:",omitempty"`
+	MaxPeerEgress  int `toml:",omitempty"`
+	MaxIngress     int `toml:",omitempty"`
+	MaxEgress      int `toml:",omitempty"`
+
+	// Permissions, if set, restricts connectivity to the nodes it permits. It is
+	// consulted for both inbound and outbound connections once the remote node's
+	// identity is known. Trusted and static peers and boot nodes are always
+	// permitted.
+	Permissions NodePermissions `toml:"-"`
+
 	// Connectivity can be restricted to certain IP networks.
 	// If this option is set to a non-nil value, only hosts which match one of the
 	// IP networks contained in the list are considered.
@@ -144,6 +216,30 @@ type Config struct {
 	// the server is started.
 	ListenAddr string
 
+	// If WebSocketAddr is set, the server also accepts connections over WebSocket
+	// on this address and announces the endpoint in the node record. This allows
+	// nodes which can only reach the network through HTTP(S) to connect.
+	WebSocketAddr string `toml:",omitempty"`
+
+	// WebSocketCert and WebSocketKey are the TLS certificate and key files of the
+	// WebSocket endpoint. If unset, the endpoint doesn't use TLS.
+	WebSocketCert string `toml:",omitempty"`
+	WebSocketKey  string `toml:",omitempty"`
+
+	// PreferWebSocket makes the server dial nodes over WebSocket whenever they
+	// announce a WebSocket endpoint. By default, WebSocket is only used for nodes
+	// without a TCP endpoint.
+	PreferWebSocket bool `toml:",omitempty"`
+
+	// If AdvertiseIP is set, it is announced as the IP of the node in its record,
+	// regardless of the IP reported by the NAT interface or discovery. Likewise,
+	// non-zero AdvertiseTCP and AdvertiseUDP are announced instead of the listening
+	// ports, and these ports aren't mapped through NAT. This is meant for nodes
+	// behind load balancers or port forwarding not detectable by the node.
+	AdvertiseIP  net.IP `toml:",omitempty"`
+	AdvertiseTCP int    `toml:",omitempty"`
+	AdvertiseUDP int    `toml:",omitempty"`
+
 	// If DiscAddr is set to a non-nil value, the server will use ListenAddr
 	// for TCP and DiscAddr for the UDP discovery protocol.
 	DiscAddr string
@@ -185,6 +281,7 @@ type Server struct {
 	running bool
 
 	listener     net.Listener
+	wsListener   *wsListener
 	ourHandshake *protoHandshake
 	loopWG       sync.WaitGroup // loop, listenLoop
 	peerFeed     event.Feed
@@ -212,6 +309,17 @@ type Server struct {
 
 	// State of run loop and listenLoop.
 	inboundHistory expHeap
+	reputation     *reputation
+	slots          *peerSlots
+
+	// Global bandwidth limiters, nil if unlimited.
+	ingress, egress *rate.Limiter
+
+	// netrestrict is the current netrestrict list, see SetNetRestrict.
+	netrestrict atomic.Pointer[netutil.Netlist]
+
+	// connectivity records connection events for ConnectivityReport.
+	connectivity *connectivityLog
 }
 
 type peerOpFunc func(map[enode.ID]*Peer)
@@ -229,6 +337,7 @@ const (
 	staticDialedConn
 	inboundConn
 	trustedConn
+	reservedConn
 )
 
 // conn wraps a network connection with information gathered
@@ -241,6 +350,7 @@ type conn struct {
 	cont  chan error // The run loop uses cont to signal errors to SetupConn.
 	caps  []Cap      // valid after the protocol handshake
 	name  string     // valid after the protocol handshake
+	class *slotClass // peer class of the node, set by the run loop
 }
 
 type transport interface {
@@ -280,6 +390,9 @@ func (f connFlag) String() string {
 	if f&inboundConn != 0 {
 		s += "-inbound"
 	}
+	if f&reservedConn != 0 {
+		s += "-reserved"
+	}
 	if s != "" {
 		s = s[1:]
 	}
@@ -385,6 +498,52 @@ func (srv *Server) RemoveTrustedPeer(node *enode.Node) {
 	}
 }
 
+// CurrentNetRestrict returns the list of IP networks connectivity is currently
+// restricted to, nil if unrestricted.
+func (srv *Server) CurrentNetRestrict() *netutil.Netlist {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+
+	if !srv.running {
+		return srv.NetRestrict
+	}
+	return srv.netrestrict.Load()
+}
+
+// SetNetRestrict replaces the list of IP networks connectivity is restricted to,
+// nil removing the restriction. It applies to discovery, dialing and inbound
+// connections. Connected peers outside of the new list are disconnected unless
+// they are trusted.
+func (srv *Server) SetNetRestrict(list *netutil.Netlist) {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+
+	if !srv.running {
+		srv.NetRestrict = list
+		return
+	}
+	srv.netrestrict.Store(list)
+	if srv.discv4 != nil {
+		srv.discv4.SetNetRestrict(list)
+	}
+	if srv.discv5 != nil {
+		srv.discv5.SetNetRestrict(list)
+	}
+	srv.dialsched.setNetRestrict(list)
+	if list == nil {
+		return
+	}
+	srv.doPeerOp(func(peers map[enode.ID]*Peer) {
+		for _, p := range peers {
+			if p.rw.is(trustedConn) || list.ContainsAddr(netutil.AddrAddr(p.RemoteAddr())) {
+				continue
+			}
+			p.log.Debug("Disconnecting peer outside of netrestrict list")
+			p.Disconnect(DiscUselessPeer)
+		}
+	})
+}
+
 // SubscribeEvents subscribes the given channel to peer events
 func (srv *Server) SubscribeEvents(ch chan *PeerEvent) event.Subscription {
 	return srv.peerFeed.Subscribe(ch)
@@ -425,6 +584,9 @@ func (srv *Server) Stop() {
 		// this unblocks listener Accept
 		srv.listener.Close()
 	}
+	if srv.wsListener != nil {
+		srv.wsListener.Close()
+	}
 	close(srv.quit)
 	srv.lock.Unlock()
 	srv.loopWG.Wait()
@@ -472,7 +634,18 @@ func (srv *Server) Start() (err error) {
 	if srv.clock == nil {
 		srv.clock = mclock.System{}
 	}
-	if srv.NoDial && srv.ListenAddr == "" {
+	srv.reputation = newReputation(srv.clock)
+	if srv.slots, err = newPeerSlots(srv.PeerClasses); err != nil {
+		return err
+	}
+	if srv.slots.reserved() > srv.MaxPeers {
+		return fmt.Errorf("peer classes reserve %d slots, more than MaxPeers (%d)", srv.slots.reserved(), srv.MaxPeers)
+	}
+	srv.connectivity = newConnectivityLog(srv.clock)
+	srv.netrestrict.Store(srv.NetRestrict)
+	srv.ingress = newBandwidthLimiter(srv.MaxIngress)
+	srv.egress = newBandwidthLimiter(srv.MaxEgress)
+	if srv.NoDial && srv.ListenAddr == "" && srv.WebSocketAddr == "" {
 		srv.log.Warn("P2P server will be useless, neither dialing nor listening")
 	}
 
@@ -505,10 +678,17 @@ func (srv *Server) Start() (err error) {
 			return err
 		}
 	}
+	if srv.WebSocketAddr != "" {
+		if err := srv.setupWebSocketListening(); err != nil {
+			return err
+		}
+	}
 	if err := srv.setupDiscovery(); err != nil {
 		return err
 	}
-	srv.setupDialScheduler()
+	if err := srv.setupDialScheduler(); err != nil {
+		return err
+	}
 
 	srv.loopWG.Add(1)
 	go srv.run()
@@ -532,6 +712,9 @@ func (srv *Server) setupLocalNode() error {
 	srv.nodedb = db
 	srv.localnode = enode.NewLocalNode(db, srv.PrivateKey)
 	srv.localnode.SetFallbackIP(net.IP{127, 0, 0, 1})
+	if srv.AdvertiseIP != nil {
+		srv.localnode.SetStaticIP(srv.AdvertiseIP)
+	}
 	// TODO: check conflicts
 	for _, p := range srv.Protocols {
 		for _, e := range p.Attributes {
@@ -544,8 +727,9 @@ func (srv *Server) setupLocalNode() error {
 func (srv *Server) setupDiscovery() error {
 	srv.discmix = enode.NewFairMix(discmixTimeout)
 
-	// Don't listen on UDP endpoint if DHT is disabled.
-	if srv.NoDiscovery {
+	// Don't listen on UDP endpoint if DHT is disabled. Validators behind sentries
+	// don't take part in discovery, so they can't be found.
+	if srv.NoDiscovery || len(srv.Sentries) > 0 {
 		return nil
 	}
 	conn, err := srv.setupUDPListening()
@@ -564,12 +748,22 @@ func (srv *Server) setupDiscovery() error {
 		sconn = &sharedUDPConn{conn, unhandled}
 	}
 
+	// Resolve the DNS node lists.
+	var dnsNodes []*enode.Node
+	if len(srv.BootstrapDNS) > 0 {
+		client := dnsdisc.NewClient(dnsdisc.Config{Logger: srv.log})
+		if dnsNodes, err = resolveBootstrapDNS(client, srv.BootstrapDNS, dnsBootstrapNodes, dnsBootstrapTimeout); err != nil {
+			return err
+		}
+		srv.log.Info("Resolved DNS bootstrap nodes", "count", len(dnsNodes))
+	}
+
 	// Start discovery services.
 	if srv.Config.DiscoveryV4 {
 		cfg := discover.Config{
 			PrivateKey:  srv.PrivateKey,
 			NetRestrict: srv.NetRestrict,
-			Bootnodes:   srv.BootstrapNodes,
+			Bootnodes:   append(slices.Clip(srv.BootstrapNodes), dnsNodes...),
 			Unhandled:   unhandled,
 			Log:         srv.log,
 		}
@@ -584,13 +778,18 @@ func (srv *Server) setupDiscovery() error {
 		cfg := discover.Config{
 			PrivateKey:  srv.PrivateKey,
 			NetRestrict: srv.NetRestrict,
-			Bootnodes:   srv.BootstrapNodesV5,
+			Bootnodes:   append(slices.Clip(srv.BootstrapNodesV5), dnsNodes...),
 			Log:         srv.log,
 		}
 		srv.discv5, err = discover.ListenV5(sconn, srv.localnode, cfg)
 		if err != nil {
 			return err
 		}
+		srv.discmix.AddSource(srv.discv5.RandomNodes())
+		for _, topic := range srv.DiscoveryTopics {
+			srv.discv5.RegisterTopic(topic)
+			srv.discmix.AddSource(srv.discv5.TopicNodes(topic))
+		}
 	}
 
 	// Add protocol-specific discovery sources.
@@ -604,40 +803,113 @@ func (srv *Server) setupDiscovery() error {
 	return nil
 }
 
-func (srv *Server) setupDialScheduler() {
+// resolveBootstrapDNS reads up to limit nodes from the given DNS node lists, giving up
+// on the remaining ones after timeout.
+func resolveBootstrapDNS(client *dnsdisc.Client, urls []string, limit int, timeout time.Duration) ([]*enode.Node, error) {
+	it, err := client.NewIterator(urls...)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	timer := time.AfterFunc(timeout, it.Close)
+	defer timer.Stop()
+	return enode.ReadNodes(it, limit), nil
+}
+
+func (srv *Server) setupDialScheduler() error {
+	priority, err := parseDialPriority(srv.DialPriority)
+	if err != nil {
+		return err
+	}
 	config := dialConfig{
-		self:           srv.localnode.ID(),
-		maxDialPeers:   srv.maxDialedConns(),
-		maxActiveDials: srv.MaxPendingPeers,
-		log:            srv.Logger,
-		netRestrict:    srv.NetRestrict,
-		dialer:         srv.Dialer,
-		clock:          srv.clock,
+		self:             srv.localnode.ID(),
+		maxDialPeers:     srv.maxDialedConns(),
+		maxActiveDials:   srv.MaxPendingPeers,
+		redialInterval:   srv.RedialInterval,
+		maxRedialBackoff: srv.MaxRedialBackoff,
+		maxResolveDelay:  srv.MaxResolveDelay,
+		priority:         priority,
+		keepStatic:       srv.KeepStaticPeers,
+		reserved:         make(map[enode.ID]bool),
+		bootnodes:        srv.BootstrapNodes,
+		log:              srv.Logger,
+		netRestrict:      srv.NetRestrict,
+		dialer:           srv.Dialer,
+		clock:            srv.clock,
 	}
 	if srv.discv4 != nil {
 		config.resolver = srv.discv4
 	}
 	if config.dialer == nil {
-		config.dialer = tcpDialer{&net.Dialer{Timeout: defaultDialTimeout}}
+		config.dialer = transportDialer{
+			tcp:      tcpDialer{&net.Dialer{Timeout: defaultDialTimeout}},
+			ws:       newWSDialer(defaultDialTimeout),
+			preferWS: srv.PreferWebSocket,
+		}
+	}
+	for _, n := range reservedDials(srv.PeerClasses) {
+		config.reserved[n.ID()] = true
 	}
+	config.dialer = trackedDialer{config.dialer, srv.connectivity}
 	srv.dialsched = newDialScheduler(config, srv.discmix, srv.SetupConn)
 	for _, n := range srv.StaticNodes {
 		srv.dialsched.addStatic(n)
 	}
+	for _, n := range srv.sentryLinks() {
+		srv.dialsched.addStatic(n)
+	}
+	for _, n := range reservedDials(srv.PeerClasses) {
+		srv.dialsched.addStatic(n)
+	}
+	return nil
+}
+
+// sentryLinks returns the sentries or validators this node is linked to.
+func (srv *Server) sentryLinks() []*enode.Node {
+	return append(slices.Clip(srv.Sentries), srv.SentryValidators...)
+}
+
+// permitted reports whether a connection is allowed. Trusted and static peers as
+// well as boot nodes are chosen by the operator, so they are exempt from the
+// Permissions. This lets a node join the network before its permission source is
+// usable, e.g. while it syncs the state of an allowlist contract.
+func (srv *Server) permitted(c *conn) bool {
+	id := c.node.ID()
+	if len(srv.Sentries) > 0 {
+		isSentry := slices.ContainsFunc(srv.Sentries, func(s *enode.Node) bool { return s.ID() == id })
+		if !isSentry {
+			return false
+		}
+	}
+	if srv.Permissions == nil || c.is(trustedConn|staticDialedConn) {
+		return true
+	}
+	if slices.ContainsFunc(srv.BootstrapNodes, func(b *enode.Node) bool { return b.ID() == id }) {
+		return true
+	}
+	return srv.Permissions.Permitted(c.node)
+}
+
+// maxSharedConns returns the number of peer slots which aren't reserved for a
+// peer class.
+func (srv *Server) maxSharedConns() int {
+	return srv.MaxPeers - srv.slots.reserved()
 }
 
 func (srv *Server) maxInboundConns() int {
-	return srv.MaxPeers - srv.maxDialedConns()
+	return srv.maxSharedConns() - srv.maxDialedConns()
 }
 
 func (srv *Server) maxDialedConns() (limit int) {
-	if srv.NoDial || srv.MaxPeers == 0 {
+	shared := srv.maxSharedConns()
+	if srv.NoDial || shared <= 0 {
 		return 0
 	}
 	if srv.DialRatio == 0 {
-		limit = srv.MaxPeers / defaultDialRatio
+		limit = shared / defaultDialRatio
 	} else {
-		limit = srv.MaxPeers / srv.DialRatio
+		limit = shared / srv.DialRatio
 	}
 	if limit == 0 {
 		limit = 1
@@ -656,19 +928,51 @@ func (srv *Server) setupListening() error {
 
 	// Update the local node record and map the TCP listening port if NAT is configured.
 	tcp, isTCP := listener.Addr().(*net.TCPAddr)
-	if isTCP {
+	if srv.AdvertiseTCP != 0 {
+		srv.localnode.Set(enr.TCP(srv.AdvertiseTCP))
+	} else if isTCP {
 		srv.localnode.Set(enr.TCP(tcp.Port))
 		if !tcp.IP.IsLoopback() && !tcp.IP.IsPrivate() {
 			srv.portMappingRegister <- &portMapping{
 				protocol: "TCP",
 				name:     "ethereum p2p",
 				port:     tcp.Port,
+				entry:    func(port int) enr.Entry { return enr.TCP(port) },
 			}
 		}
 	}
 
 	srv.loopWG.Add(1)
-	go srv.listenLoop()
+	go srv.listenLoop(listener)
+	return nil
+}
+
+func (srv *Server) setupWebSocketListening() error {
+	listener, err := listenWebSocket(srv.WebSocketAddr, srv.WebSocketCert, srv.WebSocketKey)
+	if err != nil {
+		return err
+	}
+	srv.wsListener = listener
+	srv.WebSocketAddr = listener.Addr().String()
+
+	// Announce the endpoint and map the port if NAT is configured.
+	tcp := listener.Addr().(*net.TCPAddr)
+	entry := func(port int) enr.Entry { return enr.WS(port) }
+	if listener.secure {
+		entry = func(port int) enr.Entry { return enr.WSS(port) }
+	}
+	srv.localnode.Set(entry(tcp.Port))
+	if !tcp.IP.IsLoopback() && !tcp.IP.IsPrivate() {
+		srv.portMappingRegister <- &portMapping{
+			protocol: "TCP",
+			name:     "ethereum p2p websocket",
+			port:     tcp.Port,
+			entry:    entry,
+		}
+	}
+
+	srv.loopWG.Add(1)
+	go srv.listenLoop(listener)
 	return nil
 }
 
@@ -689,13 +993,17 @@ func (srv *Server) setupUDPListening() (*net.UDPConn, error) {
 		return nil, err
 	}
 	laddr := conn.LocalAddr().(*net.UDPAddr)
-	srv.localnode.SetFallbackUDP(laddr.Port)
 	srv.log.Debug("UDP listener up", "addr", laddr)
-	if !laddr.IP.IsLoopback() && !laddr.IP.IsPrivate() {
-		srv.portMappingRegister <- &portMapping{
-			protocol: "UDP",
-			name:     "ethereum peer discovery",
-			port:     laddr.Port,
+	if srv.AdvertiseUDP != 0 {
+		srv.localnode.SetFallbackUDP(srv.AdvertiseUDP)
+	} else {
+		srv.localnode.SetFallbackUDP(laddr.Port)
+		if !laddr.IP.IsLoopback() && !laddr.IP.IsPrivate() {
+			srv.portMappingRegister <- &portMapping{
+				protocol: "UDP",
+				name:     "ethereum peer discovery",
+				port:     laddr.Port,
+			}
 		}
 	}
 
@@ -729,6 +1037,9 @@ func (srv *Server) run() {
 	for _, n := range srv.TrustedNodes {
 		trusted[n.ID()] = true
 	}
+	for _, n := range srv.sentryLinks() {
+		trusted[n.ID()] = true
+	}
 
 running:
 	for {
@@ -767,6 +1078,7 @@ running:
 				// Ensure that the trusted flag is set before checking against MaxPeers.
 				c.flags |= trustedConn
 			}
+			c.class = srv.slots.classify(srv.peerRecord(c))
 			// TODO: track in-progress inbound node IDs (pre-Peer) to avoid dialing them.
 			c.cont <- srv.postHandshakeChecks(peers, inboundCount, c)
 
@@ -776,6 +1088,9 @@ running:
 			err := srv.addPeerChecks(peers, inboundCount, c)
 			if err == nil {
 				// The handshakes are done and it passed all checks.
+				if srv.slots.add(c) {
+					c.set(reservedConn, true)
+				}
 				p := srv.launchPeer(c)
 				peers[c.node.ID()] = p
 				srv.log.Debug("Adding p2p peer", "peercount", len(peers), "id", p.ID(), "conn", c.flags, "addr", p.RemoteAddr(), "name", p.Name())
@@ -797,6 +1112,7 @@ running:
 			d := common.PrettyDuration(mclock.Now() - pd.created)
 			delete(peers, pd.ID())
 			srv.log.Debug("Removing p2p peer", "peercount", len(peers), "id", pd.ID(), "duration", d, "req", pd.requested, "err", pd.err)
+			srv.slots.remove(pd.rw)
 			srv.dialsched.peerRemoved(pd.rw)
 			if pd.Inbound() {
 				inboundCount--
@@ -833,19 +1149,31 @@ running:
 
 func (srv *Server) postHandshakeChecks(peers map[enode.ID]*Peer, inboundCount int, c *conn) error {
 	switch {
-	case !c.is(trustedConn) && len(peers) >= srv.MaxPeers:
-		return DiscTooManyPeers
-	case !c.is(trustedConn) && c.is(inboundConn) && inboundCount >= srv.maxInboundConns():
+	case !c.is(trustedConn) && !srv.slots.admit(c, len(peers), inboundCount, srv.maxSharedConns(), srv.maxInboundConns()):
 		return DiscTooManyPeers
 	case peers[c.node.ID()] != nil:
 		return DiscAlreadyConnected
 	case c.node.ID() == srv.localnode.ID():
 		return DiscSelf
+	case !c.is(trustedConn) && srv.reputation.banned(c.node.ID()):
+		return DiscUselessPeer
 	default:
 		return nil
 	}
 }
 
+// peerRecord returns the most complete record known for the node of a connection.
+// Inbound connections only carry the node's public key, so the record found by
+// discovery is used instead, if any.
+func (srv *Server) peerRecord(c *conn) *enode.Node {
+	if c.is(inboundConn) {
+		if n := srv.nodedb.Node(c.node.ID()); n != nil {
+			return n
+		}
+	}
+	return c.node
+}
+
 func (srv *Server) addPeerChecks(peers map[enode.ID]*Peer, inboundCount int, c *conn) error {
 	// Drop connections with no matching protocols.
 	if len(srv.Protocols) > 0 && countMatchingProtocols(srv.Protocols, c.caps) == 0 {
@@ -858,8 +1186,12 @@ func (srv *Server) addPeerChecks(peers map[enode.ID]*Peer, inboundCount int, c *
 
 // listenLoop runs in its own goroutine and accepts
 // inbound connections.
-func (srv *Server) listenLoop() {
-	srv.log.Debug("TCP listener up", "addr", srv.listener.Addr())
+func (srv *Server) listenLoop(listener net.Listener) {
+	if _, ok := listener.(*wsListener); ok {
+		srv.log.Debug("WebSocket listener up", "addr", listener.Addr())
+	} else {
+		srv.log.Debug("TCP listener up", "addr", listener.Addr())
+	}
 
 	// The slots channel limits accepts of new connections.
 	tokens := defaultMaxPendingPeers
@@ -890,7 +1222,7 @@ func (srv *Server) listenLoop() {
 			lastLog time.Time
 		)
 		for {
-			fd, err = srv.listener.Accept()
+			fd, err = listener.Accept()
 			if netutil.IsTemporaryError(err) {
 				if time.Since(lastLog) > 1*time.Second {
 					srv.log.Debug("Temporary read error", "err", err)
@@ -909,10 +1241,12 @@ func (srv *Server) listenLoop() {
 		remoteIP := netutil.AddrAddr(fd.RemoteAddr())
 		if err := srv.checkInboundConn(remoteIP); err != nil {
 			srv.log.Debug("Rejected inbound connection", "addr", fd.RemoteAddr(), "err", err)
+			srv.connectivity.add(eventInboundRejected, err.Error())
 			fd.Close()
 			slots <- struct{}{}
 			continue
 		}
+		srv.connectivity.add(eventInboundAccepted, "")
 		if remoteIP.IsValid() {
 			fd = newMeteredConn(fd)
 			serveMeter.Mark(1)
@@ -931,7 +1265,7 @@ func (srv *Server) checkInboundConn(remoteIP netip.Addr) error {
 		return nil
 	}
 	// Reject connections that do not match NetRestrict.
-	if srv.NetRestrict != nil && !srv.NetRestrict.ContainsAddr(remoteIP) {
+	if nr := srv.netrestrict.Load(); nr != nil && !nr.ContainsAddr(remoteIP) {
 		return errors.New("not in netrestrict list")
 	}
 	// Reject Internet peers that try too often.
@@ -960,7 +1294,10 @@ func (srv *Server) SetupConn(fd net.Conn, flags connFlag, dialDest *enode.Node)
 		if !c.is(inboundConn) {
 			markDialError(err)
 		}
+		srv.connectivity.add(eventHandshakeFailed, handshakeFailureReason(err))
 		c.close(err)
+	} else {
+		srv.connectivity.add(eventPeerAdded, "")
 	}
 	return err
 }
@@ -1001,6 +1338,11 @@ func (srv *Server) setupConn(c *conn, dialDest *enode.Node) error {
 		clog.Trace("Rejected peer", "err", err)
 		return err
 	}
+	// The check runs after the checkpoint, which marks trusted peers.
+	if !srv.permitted(c) {
+		clog.Debug("Rejected peer", "err", errNotPermitted)
+		return errNotPermitted
+	}
 
 	// Run the capability negotiation handshake.
 	phs, err := c.doProtoHandshake(srv.ourHandshake)
@@ -1044,7 +1386,9 @@ func (srv *Server) checkpoint(c *conn, stage chan<- *conn) error {
 }
 
 func (srv *Server) launchPeer(c *conn) *Peer {
+	srv.throttle(c)
 	p := newPeer(srv.log, c, srv.Protocols)
+	p.rep = srv.reputation
 	if srv.EnableMsgEvents {
 		// If message events are enabled, pass the peerFeed
 		// to the peer.
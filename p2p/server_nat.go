@@ -27,10 +27,11 @@ import (
 )
 
 const (
-	portMapDuration        = 10 * time.Minute
-	portMapRefreshInterval = 8 * time.Minute
-	portMapRetryInterval   = 5 * time.Minute
-	extipRetryInterval     = 2 * time.Minute
+	portMapDuration           = 10 * time.Minute
+	portMapRefreshInterval    = 8 * time.Minute
+	portMapMinRefreshInterval = 30 * time.Second
+	portMapRetryInterval      = 5 * time.Minute
+	extipRetryInterval        = 2 * time.Minute
 )
 
 type portMapping struct {
@@ -38,6 +39,10 @@ type portMapping struct {
 	name     string
 	port     int
 
+	// entry creates the ENR entry announcing the mapped port. If nil, the port
+	// is set as the fallback UDP port.
+	entry func(port int) enr.Entry
+
 	// for use by the portMappingLoop goroutine:
 	extPort  int // the mapped port returned by the NAT interface
 	nextTime mclock.AbsTime
@@ -46,11 +51,11 @@ type portMapping struct {
 // setupPortMapping starts the port mapping loop if necessary.
 // Note: this needs to be called after the LocalNode instance has been set on the server.
 func (srv *Server) setupPortMapping() {
-	// portMappingRegister will receive up to two values: one for the TCP port if
-	// listening is enabled, and one more for enabling UDP port mapping if discovery is
-	// enabled. We make it buffered to avoid blocking setup while a mapping request is in
-	// progress.
-	srv.portMappingRegister = make(chan *portMapping, 2)
+	// portMappingRegister will receive up to three values: one for the TCP port if
+	// listening is enabled, one for the WebSocket port if enabled, and one more for
+	// enabling UDP port mapping if discovery is enabled. We make it buffered to avoid
+	// blocking setup while a mapping request is in progress.
+	srv.portMappingRegister = make(chan *portMapping, 3)
 
 	switch srv.NAT.(type) {
 	case nil:
@@ -60,8 +65,11 @@ func (srv *Server) setupPortMapping() {
 
 	case nat.ExtIP:
 		// ExtIP doesn't block, set the IP right away.
-		ip, _ := srv.NAT.ExternalIP()
-		srv.localnode.SetStaticIP(ip)
+		ip, err := srv.NAT.ExternalIP()
+		srv.connectivity.setExternalIP(ip, err)
+		if srv.AdvertiseIP == nil {
+			srv.localnode.SetStaticIP(ip)
+		}
 		srv.loopWG.Add(1)
 		go srv.consumePortMappingRequests()
 
@@ -71,6 +79,18 @@ func (srv *Server) setupPortMapping() {
 	}
 }
 
+// portMapRefreshInterval returns the time until a port mapping is refreshed. It is
+// shortened if the gateway granted a shorter lifetime than requested.
+func (srv *Server) portMapRefreshInterval(m *portMapping) time.Duration {
+	interval := portMapRefreshInterval
+	if r, ok := srv.NAT.(nat.Refresher); ok {
+		if lifetime := r.MappingLifetime(m.protocol, m.port); lifetime > 0 && lifetime*4/5 < interval {
+			interval = max(lifetime*4/5, portMapMinRefreshInterval)
+		}
+	}
+	return interval
+}
+
 func (srv *Server) consumePortMappingRequests() {
 	defer srv.loopWG.Done()
 	for {
@@ -91,7 +111,7 @@ func (srv *Server) portMappingLoop() {
 	}
 
 	var (
-		mappings  = make(map[string]*portMapping, 2)
+		mappings  = make(map[string]*portMapping, 3)
 		refresh   = mclock.NewAlarm(srv.clock)
 		extip     = mclock.NewAlarm(srv.clock)
 		lastExtIP net.IP
@@ -122,6 +142,13 @@ func (srv *Server) portMappingLoop() {
 		case <-extip.C():
 			extip.Schedule(srv.clock.Now().Add(extipRetryInterval))
 			ip, err := srv.NAT.ExternalIP()
+			srv.connectivity.setExternalIP(ip, err)
+			if r, ok := srv.NAT.(nat.Refresher); ok && r.MappingsLost() {
+				log.Info("NAT gateway lost port mappings, refreshing", "interface", srv.NAT)
+				for _, m := range mappings {
+					m.nextTime = srv.clock.Now()
+				}
+			}
 			if err != nil {
 				log.Debug("Couldn't get external IP", "err", err, "interface", srv.NAT)
 			} else if !ip.Equal(lastExtIP) {
@@ -131,7 +158,9 @@ func (srv *Server) portMappingLoop() {
 			}
 			// Here, we either failed to get the external IP, or it has changed.
 			lastExtIP = ip
-			srv.localnode.SetStaticIP(ip)
+			if srv.AdvertiseIP == nil {
+				srv.localnode.SetStaticIP(ip)
+			}
 			// Ensure port mappings are refreshed in case we have moved to a new network.
 			for _, m := range mappings {
 				m.nextTime = srv.clock.Now()
@@ -141,7 +170,7 @@ func (srv *Server) portMappingLoop() {
 			if m.protocol != "TCP" && m.protocol != "UDP" {
 				panic("unknown NAT protocol name: " + m.protocol)
 			}
-			mappings[m.protocol] = m
+			mappings[m.name] = m
 			m.nextTime = srv.clock.Now()
 
 		case <-refresh.C():
@@ -161,12 +190,14 @@ func (srv *Server) portMappingLoop() {
 				if err != nil {
 					log.Debug("Couldn't add port mapping", "err", err)
 					m.extPort = 0
+					srv.connectivity.setMapping(m, err)
 					m.nextTime = srv.clock.Now().Add(portMapRetryInterval)
 					continue
 				}
 				// It was mapped!
 				m.extPort = int(p)
-				m.nextTime = srv.clock.Now().Add(portMapRefreshInterval)
+				srv.connectivity.setMapping(m, nil)
+				m.nextTime = srv.clock.Now().Add(srv.portMapRefreshInterval(m))
 				if external != m.extPort {
 					log = newLogger(m.protocol, m.extPort, m.port)
 					log.Info("NAT mapped alternative port")
@@ -175,10 +206,9 @@ func (srv *Server) portMappingLoop() {
 				}
 
 				// Update port in local ENR.
-				switch m.protocol {
-				case "TCP":
-					srv.localnode.Set(enr.TCP(m.extPort))
-				case "UDP":
+				if m.entry != nil {
+					srv.localnode.Set(m.entry(m.extPort))
+				} else {
 					srv.localnode.SetFallbackUDP(m.extPort)
 				}
 			}
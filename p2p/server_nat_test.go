@@ -76,13 +76,107 @@ func TestServerPortMapping(t *testing.T) {
 	}
 }
 
+// This test checks that advertised endpoints override the NAT-detected ones.
+func TestServerAdvertisedEndpoint(t *testing.T) {
+	clock := new(mclock.Simulated)
+	mockNAT := &mockNAT{mappedPort: 30000}
+	srv := Server{
+		Config: Config{
+			PrivateKey:   newkey(),
+			NoDial:       true,
+			ListenAddr:   ":0",
+			NAT:          mockNAT,
+			AdvertiseIP:  net.ParseIP("203.0.113.7"),
+			AdvertiseTCP: 443,
+			AdvertiseUDP: 30399,
+			Logger:       testlog.Logger(t, log.LvlTrace),
+			clock:        clock,
+		},
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	deadline := clock.Now().Add(portMapRefreshInterval)
+	for clock.Now() < deadline && mockNAT.ipRequests.Load() == 0 {
+		time.Sleep(10 * time.Millisecond)
+		clock.Run(1 * time.Second)
+	}
+	if n := mockNAT.mapRequests.Load(); n != 0 {
+		t.Error("advertised ports were mapped, requests:", n)
+	}
+	enr := srv.LocalNode().Node()
+	if enr.IPAddr() != netip.MustParseAddr("203.0.113.7") {
+		t.Error("wrong IP in ENR:", enr.IPAddr())
+	}
+	if enr.TCP() != 443 {
+		t.Error("wrong TCP port in ENR:", enr.TCP())
+	}
+	if enr.UDP() != 30399 {
+		t.Error("wrong UDP port in ENR:", enr.UDP())
+	}
+}
+
+// This test checks that port mappings are refreshed before the lifetime granted
+// by the gateway ends, and when the gateway lost them.
+func TestServerPortMappingRefresh(t *testing.T) {
+	clock := new(mclock.Simulated)
+	mockNAT := &mockNAT{mappedPort: 30000, lifetime: time.Minute}
+	srv := Server{
+		Config: Config{
+			PrivateKey:  newkey(),
+			NoDial:      true,
+			NoDiscovery: true,
+			ListenAddr:  ":0",
+			NAT:         mockNAT,
+			Logger:      testlog.Logger(t, log.LvlTrace),
+			clock:       clock,
+		},
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	waitRequests := func(n int32, timeout time.Duration) {
+		t.Helper()
+		deadline := clock.Now().Add(timeout)
+		for clock.Now() < deadline && mockNAT.mapRequests.Load() < n {
+			time.Sleep(10 * time.Millisecond)
+			clock.Run(1 * time.Second)
+		}
+		if have := mockNAT.mapRequests.Load(); have != n {
+			t.Fatalf("wrong request count %d, want %d", have, n)
+		}
+	}
+	waitRequests(1, 10*time.Second)
+
+	// The mapping must be refreshed within the granted lifetime.
+	waitRequests(2, time.Minute)
+
+	// Losing the mappings triggers a refresh on the next external IP check.
+	mockNAT.lost.Store(true)
+	waitRequests(3, extipRetryInterval+time.Second)
+}
+
 type mockNAT struct {
 	mappedPort    uint16
+	lifetime      time.Duration
+	lost          atomic.Bool
 	mapRequests   atomic.Int32
 	unmapRequests atomic.Int32
 	ipRequests    atomic.Int32
 }
 
+func (m *mockNAT) MappingLifetime(protocol string, intport int) time.Duration {
+	return m.lifetime
+}
+
+func (m *mockNAT) MappingsLost() bool {
+	return m.lost.Swap(false)
+}
+
 func (m *mockNAT) AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) (uint16, error) {
 	m.mapRequests.Add(1)
 	return m.mappedPort, nil
@@ -17,6 +17,7 @@
 package p2p
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"crypto/sha256"
 	"errors"
@@ -32,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/internal/testlog"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/dnsdisc"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/p2p/rlpx"
@@ -310,6 +312,104 @@ func TestServerAtCap(t *testing.T) {
 	}
 }
 
+func TestServerReservedSlots(t *testing.T) {
+	member := newNode(randomID(), "")
+	srv := &Server{
+		Config: Config{
+			PrivateKey:  newkey(),
+			MaxPeers:    6,
+			NoDial:      true,
+			NoDiscovery: true,
+			PeerClasses: []PeerClass{
+				{Name: "validators", Nodes: []*enode.Node{member}, Attrs: []string{"validator=yes"}, Inbound: 2},
+			},
+			Logger: testlog.Logger(t, log.LvlTrace),
+		},
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("could not start: %v", err)
+	}
+	defer srv.Stop()
+
+	newconn := func(id enode.ID, entries ...enr.Entry) *conn {
+		fd, _ := net.Pipe()
+		tx := newTestTransport(&newkey().PublicKey, fd, nil)
+		var r enr.Record
+		for _, e := range entries {
+			r.Set(e)
+		}
+		node := enode.SignNull(&r, id)
+		return &conn{fd: fd, transport: tx, flags: inboundConn, node: node, cont: make(chan error)}
+	}
+
+	// Fill the shared slots.
+	for i := 0; i < 4; i++ {
+		if err := srv.checkpoint(newconn(randomID()), srv.checkpointAddPeer); err != nil {
+			t.Fatalf("could not add conn %d: %v", i, err)
+		}
+	}
+	if err := srv.checkpoint(newconn(randomID()), srv.checkpointPostHandshake); err != DiscTooManyPeers {
+		t.Fatal("wrong error for shared conn:", err)
+	}
+	// Members of the class take the reserved slots.
+	members := []*conn{
+		newconn(member.ID()),
+		newconn(randomID(), enr.WithEntry("validator", "yes")),
+	}
+	for i, c := range members {
+		if err := srv.checkpoint(c, srv.checkpointPostHandshake); err != nil {
+			t.Fatalf("member %d rejected @posthandshake: %v", i, err)
+		}
+		if err := srv.checkpoint(c, srv.checkpointAddPeer); err != nil {
+			t.Fatalf("member %d rejected @addpeer: %v", i, err)
+		}
+		if !c.is(reservedConn) {
+			t.Errorf("member %d not in reserved slot", i)
+		}
+	}
+	// The reserved slots are full now.
+	c := newconn(randomID(), enr.WithEntry("validator", "yes"))
+	if err := srv.checkpoint(c, srv.checkpointPostHandshake); err != DiscTooManyPeers {
+		t.Fatal("wrong error for member beyond reserved slots:", err)
+	}
+	c = newconn(randomID(), enr.WithEntry("validator", "no"))
+	if err := srv.checkpoint(c, srv.checkpointPostHandshake); err != DiscTooManyPeers {
+		t.Fatal("wrong error for non-member:", err)
+	}
+}
+
+func TestServerPeerClassConfig(t *testing.T) {
+	tests := []struct {
+		classes []PeerClass
+		err     bool
+	}{
+		{classes: []PeerClass{{Name: "sentries", Attrs: []string{"sentry"}, Inbound: 2, Outbound: 2}}},
+		{classes: []PeerClass{{Attrs: []string{"sentry"}, Inbound: 1}}, err: true},
+		{classes: []PeerClass{{Name: "sentries", Inbound: 1}}, err: true},
+		{classes: []PeerClass{{Name: "sentries", Attrs: []string{"=x"}, Inbound: 1}}, err: true},
+		{classes: []PeerClass{{Name: "sentries", Attrs: []string{"sentry"}, Inbound: -1}}, err: true},
+		{classes: []PeerClass{{Name: "sentries", Attrs: []string{"sentry"}, Inbound: 11}}, err: true},
+		{classes: []PeerClass{{Name: "a", Attrs: []string{"a"}}, {Name: "a", Attrs: []string{"b"}}}, err: true},
+	}
+	for i, test := range tests {
+		srv := &Server{Config: Config{
+			PrivateKey:  newkey(),
+			MaxPeers:    10,
+			NoDial:      true,
+			NoDiscovery: true,
+			PeerClasses: test.classes,
+			Logger:      testlog.Logger(t, log.LvlTrace),
+		}}
+		err := srv.Start()
+		if err == nil {
+			srv.Stop()
+		}
+		if (err != nil) != test.err {
+			t.Errorf("test %d: unexpected start error: %v", i, err)
+		}
+	}
+}
+
 func TestServerPeerLimits(t *testing.T) {
 	srvkey := newkey()
 	clientkey := newkey()
@@ -375,6 +475,10 @@ func TestServerPeerLimits(t *testing.T) {
 	conn.Close()
 }
 
+type permitFunc func(*enode.Node) bool
+
+func (f permitFunc) Permitted(n *enode.Node) bool { return f(n) }
+
 func TestServerSetupConn(t *testing.T) {
 	var (
 		clientkey, srvkey = newkey(), newkey()
@@ -386,6 +490,9 @@ func TestServerSetupConn(t *testing.T) {
 		tt        *setupTransport
 		flags     connFlag
 		dialDest  *enode.Node
+		permitted NodePermissions
+		sentries  []*enode.Node
+		trusted   []*enode.Node
 
 		wantCloseErr error
 		wantCalls    string
@@ -422,6 +529,36 @@ func TestServerSetupConn(t *testing.T) {
 			wantCalls:    "doEncHandshake,close,",
 			wantCloseErr: DiscSelf,
 		},
+		{
+			tt:           &setupTransport{pubkey: clientpub, phs: protoHandshake{ID: crypto.FromECDSAPub(clientpub)[1:]}},
+			flags:        inboundConn,
+			permitted:    permitFunc(func(*enode.Node) bool { return false }),
+			wantCalls:    "doEncHandshake,close,",
+			wantCloseErr: errNotPermitted,
+		},
+		{
+			tt:           &setupTransport{pubkey: clientpub, phs: protoHandshake{ID: crypto.FromECDSAPub(clientpub)[1:]}},
+			flags:        inboundConn,
+			permitted:    permitFunc(func(*enode.Node) bool { return false }),
+			trusted:      []*enode.Node{enode.NewV4(clientpub, nil, 0, 0)},
+			wantCalls:    "doEncHandshake,doProtoHandshake,close,",
+			wantCloseErr: DiscUselessPeer,
+		},
+		{
+			tt:           &setupTransport{pubkey: clientpub, phs: protoHandshake{ID: crypto.FromECDSAPub(clientpub)[1:]}},
+			dialDest:     enode.NewV4(clientpub, nil, 0, 0),
+			flags:        staticDialedConn,
+			permitted:    permitFunc(func(*enode.Node) bool { return false }),
+			wantCalls:    "doEncHandshake,doProtoHandshake,close,",
+			wantCloseErr: DiscUselessPeer,
+		},
+		{
+			tt:           &setupTransport{pubkey: clientpub, phs: protoHandshake{ID: crypto.FromECDSAPub(clientpub)[1:]}},
+			flags:        inboundConn,
+			sentries:     []*enode.Node{enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 1}, 30303, 0)},
+			wantCalls:    "doEncHandshake,close,",
+			wantCloseErr: errNotPermitted,
+		},
 		{
 			tt:           &setupTransport{pubkey: clientpub, phs: protoHandshake{ID: crypto.FromECDSAPub(clientpub)[1:]}},
 			flags:        inboundConn,
@@ -433,12 +570,15 @@ func TestServerSetupConn(t *testing.T) {
 	for i, test := range tests {
 		t.Run(test.wantCalls, func(t *testing.T) {
 			cfg := Config{
-				PrivateKey:  srvkey,
-				MaxPeers:    10,
-				NoDial:      true,
-				NoDiscovery: true,
-				Protocols:   []Protocol{discard},
-				Logger:      testlog.Logger(t, log.LvlTrace),
+				PrivateKey:   srvkey,
+				MaxPeers:     10,
+				NoDial:       true,
+				NoDiscovery:  true,
+				Protocols:    []Protocol{discard},
+				Permissions:  test.permitted,
+				Sentries:     test.sentries,
+				TrustedNodes: test.trusted,
+				Logger:       testlog.Logger(t, log.LvlTrace),
 			}
 			srv := &Server{
 				Config:       cfg,
@@ -629,3 +769,55 @@ func syncAddPeer(srv *Server, node *enode.Node) bool {
 		}
 	}
 }
+
+type mapResolver map[string]string
+
+func (mr mapResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if record, ok := mr[name]; ok {
+		return []string{record}, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func TestResolveBootstrapDNS(t *testing.T) {
+	var nodes []*enode.Node
+	for i := 0; i < 10; i++ {
+		var r enr.Record
+		r.Set(enr.IPv4{127, 0, 0, 1})
+		r.Set(enr.UDP(30303 + i))
+		key := newkey()
+		enode.SignV4(&r, key)
+		n, _ := enode.New(enode.ValidSchemes, &r)
+		nodes = append(nodes, n)
+	}
+	tree, err := dnsdisc.MakeTree(1, nodes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	url, err := tree.Sign(newkey(), "nodes.example.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := dnsdisc.NewClient(dnsdisc.Config{Resolver: mapResolver(tree.ToTXT("nodes.example.org"))})
+
+	// All nodes are found before the timeout when the limit allows for it.
+	found, err := resolveBootstrapDNS(client, []string{url}, 100, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != len(nodes) {
+		t.Fatalf("wrong number of nodes: have %d, want %d", len(found), len(nodes))
+	}
+	// Unresolvable trees don't block past the timeout.
+	start := time.Now()
+	found, err = resolveBootstrapDNS(client, []string{"enrtree://AKA3AM6LPBYEUDMVNU3BSVQJ5AD45Y7YPOHJLEF6W26QOE4VTUDPE@unknown.example.org"}, 10, 200*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 0 || time.Since(start) > 2*time.Second {
+		t.Fatalf("unexpected result %v after %v", found, time.Since(start))
+	}
+	if _, err := resolveBootstrapDNS(client, []string{"enode://foo"}, 10, time.Second); err == nil {
+		t.Fatal("invalid URL accepted")
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// PeerClass reserves peer slots for a class of important nodes, e.g. validators or
+// sentries. The reserved slots are taken out of MaxPeers: other nodes can't use
+// them, so members of the class can always connect, even when the node is full.
+// Members which find the reserved slots taken compete for the shared ones.
+type PeerClass struct {
+	Name string
+
+	// Nodes are members of the class. The ones with outbound slots are dialed like
+	// static nodes.
+	Nodes []*enode.Node `toml:",omitempty"`
+
+	// Attrs select members by the entries of their node record, in the form "key"
+	// or "key=value". A value matches entries holding that string. The record of
+	// inbound peers is taken from the node database, so only nodes found through
+	// discovery can match.
+	Attrs []string `toml:",omitempty"`
+
+	// Inbound and Outbound are the numbers of reserved slots by direction.
+	Inbound  int `toml:",omitempty"`
+	Outbound int `toml:",omitempty"`
+}
+
+// peerSlots assigns peers to the reserved slots of the peer classes. The counters
+// are only accessed by the server's run loop.
+type peerSlots struct {
+	classes     []*slotClass
+	reservedIn  int
+	reservedOut int
+	usedIn      int // peers in reserved inbound slots
+	usedOut     int // peers in reserved outbound slots
+}
+
+type slotClass struct {
+	name    string
+	nodes   map[enode.ID]bool
+	attrs   []slotAttr
+	in, out int // reserved slots
+	usedIn  int
+	usedOut int
+}
+
+type slotAttr struct {
+	key, value string
+	hasValue   bool
+}
+
+func newPeerSlots(classes []PeerClass) (*peerSlots, error) {
+	s := new(peerSlots)
+	names := make(map[string]bool)
+	for _, pc := range classes {
+		switch {
+		case pc.Name == "":
+			return nil, errors.New("peer class without name")
+		case names[pc.Name]:
+			return nil, fmt.Errorf("duplicate peer class %q", pc.Name)
+		case pc.Inbound < 0 || pc.Outbound < 0:
+			return nil, fmt.Errorf("negative slot count in peer class %q", pc.Name)
+		case len(pc.Nodes) == 0 && len(pc.Attrs) == 0:
+			return nil, fmt.Errorf("peer class %q has no members", pc.Name)
+		}
+		names[pc.Name] = true
+		c := &slotClass{name: pc.Name, nodes: make(map[enode.ID]bool), in: pc.Inbound, out: pc.Outbound}
+		for _, n := range pc.Nodes {
+			c.nodes[n.ID()] = true
+		}
+		for _, attr := range pc.Attrs {
+			key, value, hasValue := strings.Cut(attr, "=")
+			if key == "" {
+				return nil, fmt.Errorf("invalid attribute %q in peer class %q", attr, pc.Name)
+			}
+			c.attrs = append(c.attrs, slotAttr{key, value, hasValue})
+		}
+		s.classes = append(s.classes, c)
+		s.reservedIn += c.in
+		s.reservedOut += c.out
+	}
+	return s, nil
+}
+
+// reserved returns the total number of reserved slots.
+func (s *peerSlots) reserved() int {
+	if s == nil {
+		return 0
+	}
+	return s.reservedIn + s.reservedOut
+}
+
+// classify returns the class of a node, or nil if it isn't a member of any class.
+// Nodes belonging to several classes are assigned to the first one.
+func (s *peerSlots) classify(n *enode.Node) *slotClass {
+	for _, c := range s.classes {
+		if c.matches(n) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (c *slotClass) matches(n *enode.Node) bool {
+	if c.nodes[n.ID()] {
+		return true
+	}
+	for _, attr := range c.attrs {
+		var raw rlp.RawValue
+		if n.Load(enr.WithEntry(attr.key, &raw)) != nil {
+			continue
+		}
+		if !attr.hasValue {
+			return true
+		}
+		var value []byte
+		if rlp.DecodeBytes(raw, &value) == nil && string(value) == attr.value {
+			return true
+		}
+	}
+	return false
+}
+
+// free reports whether the class has a free slot in the direction of the connection.
+func (c *slotClass) free(inbound bool) bool {
+	if inbound {
+		return c.usedIn < c.in
+	}
+	return c.usedOut < c.out
+}
+
+// admit checks whether a connection fits into the peer limits, given the number
+// of connected peers. The reserved slots of its class are tried first.
+func (s *peerSlots) admit(c *conn, peers, inbound, maxShared, maxSharedInbound int) bool {
+	if c.class != nil && c.class.free(c.is(inboundConn)) {
+		return true
+	}
+	if peers-s.usedIn-s.usedOut >= maxShared {
+		return false
+	}
+	return !c.is(inboundConn) || inbound-s.usedIn < maxSharedInbound
+}
+
+// add assigns a new peer to a reserved slot of its class if one is free, reporting
+// whether it did.
+func (s *peerSlots) add(c *conn) bool {
+	if c.class == nil || !c.class.free(c.is(inboundConn)) {
+		return false
+	}
+	if c.is(inboundConn) {
+		c.class.usedIn++
+		s.usedIn++
+	} else {
+		c.class.usedOut++
+		s.usedOut++
+	}
+	return true
+}
+
+// remove releases the reserved slot of a disconnected peer.
+func (s *peerSlots) remove(c *conn) {
+	if !c.is(reservedConn) {
+		return
+	}
+	if c.is(inboundConn) {
+		c.class.usedIn--
+		s.usedIn--
+	} else {
+		c.class.usedOut--
+		s.usedOut--
+	}
+}
+
+// reservedDials returns the class members to keep connected through outbound slots.
+func reservedDials(classes []PeerClass) []*enode.Node {
+	var nodes []*enode.Node
+	for _, pc := range classes {
+		if pc.Outbound > 0 {
+			nodes = append(nodes, pc.Nodes...)
+		}
+	}
+	return nodes
+}
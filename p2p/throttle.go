@@ -0,0 +1,109 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// minThrottleBurst is the minimum burst size of bandwidth limiters, so small
+// limits don't delay every single message.
+const minThrottleBurst = 64 * 1024
+
+// newBandwidthLimiter creates a limiter for the given number of bytes per second.
+// It returns nil if the limit is zero, i.e. bandwidth is unlimited.
+func newBandwidthLimiter(limit int) *rate.Limiter {
+	if limit <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(limit), max(limit, minThrottleBurst))
+}
+
+// throttledTransport limits the bandwidth used by a connection. Each direction is
+// limited by both the per-peer limiter and the global one shared by all peers.
+type throttledTransport struct {
+	transport
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	ingress, egress             *rate.Limiter
+	globalIngress, globalEgress *rate.Limiter
+}
+
+// throttle wraps the transport of the connection if any bandwidth limit is set.
+func (srv *Server) throttle(c *conn) {
+	if srv.MaxPeerIngress <= 0 && srv.MaxPeerEgress <= 0 && srv.ingress == nil && srv.egress == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.transport = &throttledTransport{
+		transport:     c.transport,
+		ctx:           ctx,
+		cancel:        cancel,
+		ingress:       newBandwidthLimiter(srv.MaxPeerIngress),
+		egress:        newBandwidthLimiter(srv.MaxPeerEgress),
+		globalIngress: srv.ingress,
+		globalEgress:  srv.egress,
+	}
+}
+
+// ReadMsg reads a message, delaying the next read until the bandwidth used by it
+// is available. This makes the remote end slow down through TCP flow control.
+func (t *throttledTransport) ReadMsg() (Msg, error) {
+	msg, err := t.transport.ReadMsg()
+	if err != nil {
+		return msg, err
+	}
+	if err := t.wait(t.ingress, t.globalIngress, int(msg.Size)); err != nil {
+		return msg, err
+	}
+	return msg, nil
+}
+
+// WriteMsg writes a message once the bandwidth needed for it is available.
+func (t *throttledTransport) WriteMsg(msg Msg) error {
+	if err := t.wait(t.egress, t.globalEgress, int(msg.Size)); err != nil {
+		return err
+	}
+	return t.transport.WriteMsg(msg)
+}
+
+func (t *throttledTransport) close(err error) {
+	t.cancel()
+	t.transport.close(err)
+}
+
+// wait blocks until n bytes are available from both limiters or the transport is
+// closed.
+func (t *throttledTransport) wait(peer, global *rate.Limiter, n int) error {
+	for _, lim := range []*rate.Limiter{peer, global} {
+		if lim == nil {
+			continue
+		}
+		// Messages larger than the burst size are waited for in chunks.
+		for remaining := n; remaining > 0; {
+			chunk := min(remaining, lim.Burst())
+			if err := lim.WaitN(t.ctx, chunk); err != nil {
+				return err
+			}
+			remaining -= chunk
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// discardTransport accepts all writes.
+type discardTransport struct{ transport }
+
+func (discardTransport) WriteMsg(Msg) error { return nil }
+func (discardTransport) close(error)        {}
+
+func TestThrottledTransport(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	tr := &throttledTransport{
+		transport: discardTransport{},
+		ctx:       ctx,
+		cancel:    cancel,
+		egress:    rate.NewLimiter(10000, 1000),
+	}
+	write := func(size int) error {
+		return tr.WriteMsg(Msg{Size: uint32(size), Payload: bytes.NewReader(make([]byte, size))})
+	}
+	// A message larger than the burst is delayed until the bandwidth is available.
+	start := time.Now()
+	if err := write(3000); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("message not throttled, took %v", elapsed)
+	}
+	// Closing the transport aborts waiting writes.
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		tr.close(nil)
+	}()
+	start = time.Now()
+	if err := write(100000); err == nil {
+		t.Fatal("write succeeded after close")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("close didn't abort write, took %v", elapsed)
+	}
+}
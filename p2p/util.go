@@ -51,6 +51,17 @@ func (h expHeap) contains(item string) bool {
 	return false
 }
 
+// remove removes all entries of an item.
+func (h *expHeap) remove(item string) {
+	for i := 0; i < h.Len(); {
+		if (*h)[i].item == item {
+			heap.Remove(h, i)
+		} else {
+			i++
+		}
+	}
+}
+
 // expire removes items with expiry time before 'now'.
 func (h *expHeap) expire(now mclock.AbsTime, onExp func(string)) {
 	for h.Len() > 0 && h.nextExpiry() < now {
@@ -0,0 +1,229 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/gorilla/websocket"
+)
+
+// The WebSocket transport carries the RLPx stream in binary WebSocket messages. It
+// allows nodes behind firewalls and proxies which only pass HTTP(S) traffic to join
+// the network. Nodes announce their WebSocket endpoint using the "ws" or "wss" ENR
+// entries.
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	// devp2p clients aren't browsers, the origin has no meaning.
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// wsConn adapts a WebSocket connection to the net.Conn interface.
+type wsConn struct {
+	*websocket.Conn
+	reader io.Reader // current message being read
+}
+
+func (c *wsConn) Read(b []byte) (int, error) {
+	for {
+		if c.reader == nil {
+			typ, r, err := c.NextReader()
+			if err != nil {
+				return 0, err
+			}
+			if typ != websocket.BinaryMessage {
+				continue
+			}
+			c.reader = r
+		}
+		n, err := c.reader.Read(b)
+		if err == io.EOF {
+			c.reader = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (c *wsConn) Write(b []byte) (int, error) {
+	if err := c.WriteMessage(websocket.BinaryMessage, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c *wsConn) SetDeadline(t time.Time) error {
+	if err := c.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.SetWriteDeadline(t)
+}
+
+// wsListener accepts devp2p connections over WebSocket.
+type wsListener struct {
+	ln     net.Listener
+	server *http.Server
+	conns  chan net.Conn
+	secure bool // whether TLS is used
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// listenWebSocket starts a WebSocket listener on addr. If certFile and keyFile are
+// set, connections are secured by TLS.
+func listenWebSocket(addr, certFile, keyFile string) (*wsListener, error) {
+	var config *tls.Config
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't load WebSocket TLS certificate: %v", err)
+		}
+		config = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	l := &wsListener{
+		ln:     ln,
+		conns:  make(chan net.Conn),
+		closed: make(chan struct{}),
+		secure: config != nil,
+	}
+	l.server = &http.Server{Handler: l, ReadHeaderTimeout: handshakeTimeout}
+	if config != nil {
+		ln = tls.NewListener(ln, config)
+	}
+	go l.server.Serve(ln)
+	return l, nil
+}
+
+func (l *wsListener) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	select {
+	case l.conns <- &wsConn{Conn: c}:
+	case <-l.closed:
+		c.Close()
+	}
+}
+
+// Accept waits for the next WebSocket connection.
+func (l *wsListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close stops the listener. Established connections are not affected.
+func (l *wsListener) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		close(l.closed)
+		err = l.server.Close()
+	})
+	return err
+}
+
+// Addr returns the listening address.
+func (l *wsListener) Addr() net.Addr {
+	return l.ln.Addr()
+}
+
+// wsEndpoint returns the URL of the WebSocket endpoint announced by a node.
+func wsEndpoint(n *enode.Node) (string, bool) {
+	ip := n.IPAddr()
+	if !ip.IsValid() {
+		return "", false
+	}
+	var wss enr.WSS
+	if n.Load(&wss) == nil && wss != 0 {
+		return "wss://" + netip.AddrPortFrom(ip, uint16(wss)).String() + "/", true
+	}
+	var ws enr.WS
+	if n.Load(&ws) == nil && ws != 0 {
+		return "ws://" + netip.AddrPortFrom(ip, uint16(ws)).String() + "/", true
+	}
+	return "", false
+}
+
+// wsDialer implements NodeDialer using WebSocket connections. Proxies are taken
+// from the environment.
+type wsDialer struct {
+	d *websocket.Dialer
+}
+
+func newWSDialer(timeout time.Duration) wsDialer {
+	return wsDialer{&websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: timeout,
+		ReadBufferSize:   4096,
+		WriteBufferSize:  4096,
+		// Certificates of nodes can't be verified because they're known by IP only.
+		// This is safe because the RLPx handshake authenticates the remote node.
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+}
+
+func (t wsDialer) Dial(ctx context.Context, dest *enode.Node) (net.Conn, error) {
+	url, ok := wsEndpoint(dest)
+	if !ok {
+		return nil, errNoPort
+	}
+	c, _, err := t.d.DialContext(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &wsConn{Conn: c}, nil
+}
+
+// transportDialer connects to nodes over TCP or WebSocket, depending on the
+// endpoints in their record. WebSocket is used if the node has no TCP endpoint, or
+// if preferWS is set.
+type transportDialer struct {
+	tcp      NodeDialer
+	ws       NodeDialer
+	preferWS bool
+}
+
+func (t transportDialer) Dial(ctx context.Context, dest *enode.Node) (net.Conn, error) {
+	if _, ok := wsEndpoint(dest); ok && (t.preferWS || dest.TCP() == 0) {
+		return t.ws.Dial(ctx, dest)
+	}
+	return t.tcp.Dial(ctx, dest)
+}
@@ -0,0 +1,129 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+)
+
+// This test checks that a node can connect to a node which is only reachable over
+// WebSocket.
+func TestWebSocketTransport(t *testing.T) {
+	wsOnly := &Server{Config: Config{
+		Name:          "ws",
+		MaxPeers:      10,
+		WebSocketAddr: "127.0.0.1:0",
+		NoDiscovery:   true,
+		PrivateKey:    newkey(),
+		Logger:        testlog.Logger(t, log.LvlTrace).New("server", "ws"),
+	}}
+	if err := wsOnly.Start(); err != nil {
+		t.Fatal("can't start server:", err)
+	}
+	defer wsOnly.Stop()
+
+	target := wsOnly.Self()
+	if target.TCP() != 0 {
+		t.Fatal("WebSocket-only node announces TCP port", target.TCP())
+	}
+	if _, ok := wsEndpoint(target); !ok {
+		t.Fatal("node record has no WebSocket endpoint")
+	}
+
+	dialer := &Server{Config: Config{
+		Name:        "dialer",
+		MaxPeers:    10,
+		NoDiscovery: true,
+		PrivateKey:  newkey(),
+		Logger:      testlog.Logger(t, log.LvlTrace).New("server", "dialer"),
+	}}
+	if err := dialer.Start(); err != nil {
+		t.Fatal("can't start server:", err)
+	}
+	defer dialer.Stop()
+
+	if !syncAddPeer(dialer, target) {
+		t.Fatal("peer not connected over WebSocket")
+	}
+}
+
+type recordingDialer struct{ dialed *string }
+
+func (d recordingDialer) Dial(ctx context.Context, n *enode.Node) (net.Conn, error) {
+	*d.dialed = n.ID().TerminalString()
+	return nil, errors.New("not dialing")
+}
+
+func TestTransportDialerSelection(t *testing.T) {
+	var tcp, ws string
+	dialer := transportDialer{tcp: recordingDialer{&tcp}, ws: recordingDialer{&ws}}
+
+	newNode := func(entries ...enr.Entry) *enode.Node {
+		var r enr.Record
+		r.Set(enr.IPv4{127, 0, 0, 1})
+		for _, e := range entries {
+			r.Set(e)
+		}
+		key, _ := crypto.GenerateKey()
+		enode.SignV4(&r, key)
+		n, _ := enode.New(enode.ValidSchemes, &r)
+		return n
+	}
+	tests := []struct {
+		node     *enode.Node
+		preferWS bool
+		wantWS   bool
+	}{
+		{node: newNode(enr.TCP(30303)), wantWS: false},
+		{node: newNode(enr.TCP(30303), enr.WS(8546)), wantWS: false},
+		{node: newNode(enr.TCP(30303), enr.WSS(443)), preferWS: true, wantWS: true},
+		{node: newNode(enr.WS(8546)), wantWS: true},
+		{node: newNode(enr.TCP(30303)), preferWS: true, wantWS: false},
+	}
+	for i, test := range tests {
+		tcp, ws = "", ""
+		dialer.preferWS = test.preferWS
+		dialer.Dial(context.Background(), test.node)
+		if gotWS := ws != ""; gotWS != test.wantWS {
+			t.Errorf("test %d: dialed over WebSocket: %v, want %v", i, gotWS, test.wantWS)
+		}
+	}
+}
+
+func TestWSEndpoint(t *testing.T) {
+	var r enr.Record
+	r.Set(enr.IPv6{0xfe, 0x80, 15: 1})
+	r.Set(enr.WS(8546))
+	r.Set(enr.WSS(443))
+	key, _ := crypto.GenerateKey()
+	enode.SignV4(&r, key)
+	n, _ := enode.New(enode.ValidSchemes, &r)
+
+	url, ok := wsEndpoint(n)
+	if !ok || url != "wss://[fe80::1]:443/" {
+		t.Fatalf("wrong endpoint %q", url)
+	}
+}
@@ -354,6 +354,11 @@ type ChainConfig struct {
 	PragueTime   *uint64 `json:"pragueTime,omitempty"`   // Prague switch time (nil = no fork, 0 = already on prague)
 	VerkleTime   *uint64 `json:"verkleTime,omitempty"`   // Verkle switch time (nil = no fork, 0 = already on verkle)
 
+	// Network specific extensions, which are not part of the canonical fork
+	// sequence and may be enabled independently on private deployments.
+
+	ContentHashBlock *big.Int `json:"contentHashBlock,omitempty"` // Content-hash verification precompile switch block (nil = disabled)
+
 	// TerminalTotalDifficulty is the amount of total difficulty reached by
 	// the network that triggers the consensus upgrade.
 	TerminalTotalDifficulty *big.Int `json:"terminalTotalDifficulty,omitempty"`
@@ -478,6 +483,11 @@ func (c *ChainConfig) Description() string {
 	if c.VerkleTime != nil {
 		banner += fmt.Sprintf(" - Verkle:                      @%-10v\n", *c.VerkleTime)
 	}
+	if c.ContentHashBlock != nil {
+		banner += "\n"
+		banner += "Network specific extensions:\n"
+		banner += fmt.Sprintf(" - Content-hash precompile:     #%-8v\n", c.ContentHashBlock)
+	}
 	return banner
 }
 
@@ -581,6 +591,12 @@ func (c *ChainConfig) IsVerkle(num *big.Int, time uint64) bool {
 	return c.IsLondon(num) && isTimestampForked(c.VerkleTime, time)
 }
 
+// IsContentHash returns whether num is either equal to the content-hash
+// verification precompile activation block or greater.
+func (c *ChainConfig) IsContentHash(num *big.Int) bool {
+	return isBlockForked(c.ContentHashBlock, num)
+}
+
 // IsEIP4762 returns whether eip 4762 has been activated at given block.
 func (c *ChainConfig) IsEIP4762(num *big.Int, time uint64) bool {
 	return c.IsVerkle(num, time)
@@ -747,6 +763,9 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, headNumber *big.Int,
 	if isForkTimestampIncompatible(c.VerkleTime, newcfg.VerkleTime, headTimestamp) {
 		return newTimestampCompatError("Verkle fork timestamp", c.VerkleTime, newcfg.VerkleTime)
 	}
+	if isForkBlockIncompatible(c.ContentHashBlock, newcfg.ContentHashBlock, headNumber) {
+		return newBlockCompatError("Content-hash precompile block", c.ContentHashBlock, newcfg.ContentHashBlock)
+	}
 	return nil
 }
 
@@ -919,6 +938,7 @@ type Rules struct {
 	IsBerlin, IsLondon                                      bool
 	IsMerge, IsShanghai, IsCancun, IsPrague                 bool
 	IsVerkle                                                bool
+	IsContentHash                                           bool
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -949,5 +969,6 @@ func (c *ChainConfig) Rules(num *big.Int, isMerge bool, timestamp uint64) Rules
 		IsPrague:         isMerge && c.IsPrague(num, timestamp),
 		IsVerkle:         isVerkle,
 		IsEIP4762:        isVerkle,
+		IsContentHash:    c.IsContentHash(num),
 	}
 }
@@ -142,6 +142,10 @@ const (
 	IdentityBaseGas     uint64 = 15   // Base price for a data copy operation
 	IdentityPerWordGas  uint64 = 3    // Per-work price for a data copy operation
 
+	ContentProofBaseGas     uint64 = 600 // Base price for a content-hash proof verification
+	ContentProofPerLevelGas uint64 = 84  // Per-level price for a content-hash proof verification (one 64 byte SHA256)
+	ContentProofMaxDepth           = 64  // Maximum number of levels in a content-hash proof
+
 	Bn256AddGasByzantium             uint64 = 500    // Byzantium gas needed for an elliptic curve addition
 	Bn256AddGasIstanbul              uint64 = 150    // Gas needed for an elliptic curve addition
 	Bn256ScalarMulGasByzantium       uint64 = 40000  // Byzantium gas needed for an elliptic curve scalar multiplication
@@ -0,0 +1,113 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// APIKeyHeader is the HTTP header clients use to present their API key. The key
+// is available to method handlers through PeerInfo and is used to select the
+// access rules applying to the client.
+const APIKeyHeader = "X-Api-Key"
+
+// AccessRule is a list of allowed and denied methods. Entries may either be a
+// full method name ("eth_call"), a namespace ("eth" or "eth_*") or the wildcard
+// "*" matching every method. Denied entries take precedence over allowed ones,
+// and an empty allow list permits every method not explicitly denied.
+type AccessRule struct {
+	Allow []string `toml:",omitempty"`
+	Deny  []string `toml:",omitempty"`
+}
+
+// permits reports whether the rule allows calling the given method.
+func (r *AccessRule) permits(method string) bool {
+	for _, pattern := range r.Deny {
+		if matchMethod(pattern, method) {
+			return false
+		}
+	}
+	if len(r.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range r.Allow {
+		if matchMethod(pattern, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessControl restricts the methods clients of a server may invoke. Clients
+// presenting an API key listed in Keys are subject to the rule configured for
+// that key, all other clients are subject to the default rule.
+type AccessControl struct {
+	Default AccessRule            `toml:",omitempty"`
+	Keys    map[string]AccessRule `toml:",omitempty"`
+}
+
+// Permits reports whether a client with the given peer info may call method.
+func (ac *AccessControl) Permits(info PeerInfo, method string) bool {
+	if ac == nil {
+		return true
+	}
+	if info.APIKey != "" {
+		if rule, ok := ac.Keys[info.APIKey]; ok {
+			return rule.permits(method)
+		}
+	}
+	return ac.Default.permits(method)
+}
+
+// scopesPermit reports whether the scopes granted to a client allow calling the
+// given method. Nil scopes don't restrict the client.
+func scopesPermit(scopes []string, method string) bool {
+	if scopes == nil {
+		return true
+	}
+	for _, pattern := range scopes {
+		if matchMethod(pattern, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchMethod reports whether the method name matches the access pattern.
+func matchMethod(pattern, method string) bool {
+	switch {
+	case pattern == "*":
+		return true
+	case strings.HasSuffix(pattern, "_*"):
+		return strings.HasPrefix(method, pattern[:len(pattern)-1])
+	case !strings.Contains(pattern, serviceMethodSeparator):
+		return strings.HasPrefix(method, pattern+serviceMethodSeparator)
+	default:
+		return pattern == method
+	}
+}
+
+// accessDeniedError is returned when the access rules of the server prohibit the
+// client from calling a method.
+type accessDeniedError struct{ method string }
+
+func (e *accessDeniedError) ErrorCode() int { return errcodeAccessDenied }
+
+func (e *accessDeniedError) Error() string {
+	return fmt.Sprintf("access to method %s denied", e.method)
+}
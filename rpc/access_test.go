@@ -0,0 +1,131 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccessRuleMatching(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		rule   AccessRule
+		method string
+		want   bool
+	}{
+		{AccessRule{}, "eth_call", true},
+		{AccessRule{Allow: []string{"*"}}, "debug_traceBlock", true},
+		{AccessRule{Allow: []string{"eth"}}, "eth_call", true},
+		{AccessRule{Allow: []string{"eth"}}, "ethx_call", false},
+		{AccessRule{Allow: []string{"eth_*"}}, "eth_call", true},
+		{AccessRule{Allow: []string{"eth_*"}}, "debug_traceBlock", false},
+		{AccessRule{Allow: []string{"eth_call"}}, "eth_call", true},
+		{AccessRule{Allow: []string{"eth_call"}}, "eth_callMany", false},
+		{AccessRule{Deny: []string{"debug"}}, "debug_traceBlock", false},
+		{AccessRule{Deny: []string{"debug"}}, "eth_call", true},
+		{AccessRule{Allow: []string{"eth"}, Deny: []string{"eth_sendRawTransaction"}}, "eth_sendRawTransaction", false},
+		{AccessRule{Allow: []string{"eth"}, Deny: []string{"eth_sendRawTransaction"}}, "eth_call", true},
+		{AccessRule{Allow: []string{"eth_call"}, Deny: []string{"*"}}, "eth_call", false},
+	}
+	for i, tt := range tests {
+		if have := tt.rule.permits(tt.method); have != tt.want {
+			t.Errorf("test %d: rule %+v, method %s: have %v, want %v", i, tt.rule, tt.method, have, tt.want)
+		}
+	}
+}
+
+func TestServerAccessControl(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetAccessControl(&AccessControl{
+		Default: AccessRule{Allow: []string{"test_echo"}},
+		Keys: map[string]AccessRule{
+			"admin-key": {Allow: []string{"test"}},
+		},
+	})
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	// Anonymous clients are subject to the default rule
+	client, err := Dial(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var res echoResult
+	if err := client.Call(&res, "test_echo", "x", 1); err != nil {
+		t.Fatalf("permitted call failed: %v", err)
+	}
+	var info PeerInfo
+	err = client.Call(&info, "test_peerInfo")
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeAccessDenied {
+		t.Fatalf("expected access denied error, got %v", err)
+	}
+	// Unknown keys fall back to the default rule
+	client.SetHeader(APIKeyHeader, "unknown-key")
+	if err := client.Call(&info, "test_peerInfo"); err == nil {
+		t.Fatal("expected access denied error for unknown key")
+	}
+	// Known keys use their own rule
+	client.SetHeader(APIKeyHeader, "admin-key")
+	if err := client.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatalf("permitted call failed: %v", err)
+	}
+	if info.APIKey != "admin-key" {
+		t.Errorf("wrong APIKey %q", info.APIKey)
+	}
+	// Rules may be replaced at runtime
+	server.SetAccessControl(nil)
+	client.SetHeader(APIKeyHeader, "")
+	if err := client.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatalf("call failed after lifting access rules: %v", err)
+	}
+}
+
+func TestAccessControlInProcAs(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.SetAccessControl(&AccessControl{
+		Default: AccessRule{Allow: []string{"test_echo"}},
+		Keys: map[string]AccessRule{
+			"admin-key": {Allow: []string{"test"}},
+		},
+	})
+
+	// Calls are attributed to the given client.
+	anon := DialInProcAs(server, PeerInfo{Transport: "grpc", RemoteAddr: "10.0.0.1:1234"})
+	defer anon.Close()
+	var info PeerInfo
+	if err := anon.Call(&info, "test_peerInfo"); err == nil {
+		t.Fatal("expected access denied error for anonymous client")
+	}
+	admin := DialInProcAs(server, PeerInfo{Transport: "grpc", RemoteAddr: "10.0.0.1:1234", APIKey: "admin-key"})
+	defer admin.Close()
+	if err := admin.Call(&info, "test_peerInfo"); err != nil {
+		t.Fatalf("permitted call failed: %v", err)
+	}
+	if info.Transport != "grpc" || info.RemoteAddr != "10.0.0.1:1234" || info.APIKey != "admin-key" {
+		t.Errorf("wrong peer info %+v", info)
+	}
+}
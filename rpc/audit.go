@@ -0,0 +1,183 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// redacted replaces the values of sensitive parameters in the audit log.
+const redacted = "[redacted]"
+
+// sensitiveParams lists the positional arguments of methods which carry secrets.
+var sensitiveParams = map[string][]int{
+	"personal_newAccount":      {0},
+	"personal_importRawKey":    {0, 1},
+	"personal_importMnemonic":  {0, 1, 4},
+	"personal_unlockAccount":   {1},
+	"personal_openWallet":      {1},
+	"personal_sendTransaction": {1},
+	"personal_signTransaction": {1},
+	"personal_sign":            {2},
+	"account_sendTransaction":  {1},
+	"account_signTransaction":  {1},
+	"account_sign":             {2},
+}
+
+// sensitiveFields are the keys of object parameters whose values are redacted,
+// regardless of the method. Keys are matched case-insensitively.
+var sensitiveFields = map[string]bool{
+	"password":   true,
+	"passphrase": true,
+	"privatekey": true,
+	"secret":     true,
+	"mnemonic":   true,
+}
+
+// AuditRecord is an entry of the audit log, describing a served call.
+type AuditRecord struct {
+	Time       time.Time       `json:"time"`
+	Method     string          `json:"method"`
+	Params     json.RawMessage `json:"params,omitempty"`
+	Transport  string          `json:"transport"`
+	RemoteAddr string          `json:"remoteAddr"`
+	UserAgent  string          `json:"userAgent,omitempty"`
+	Origin     string          `json:"origin,omitempty"`
+	APIKey     string          `json:"apiKey,omitempty"` // fingerprint of the API key, if any
+	Duration   time.Duration   `json:"duration"`         // in nanoseconds
+	Error      string          `json:"error,omitempty"`
+	ErrorCode  int             `json:"errorCode,omitempty"`
+}
+
+// AuditLog writes a record of the calls served by a server as JSON lines, for
+// reviewing which clients access which methods. Secrets contained in the call
+// parameters, such as account passwords, are redacted.
+type AuditLog struct {
+	namespaces map[string]bool
+
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewAuditLog creates an audit log writing to w. If namespaces are given, only
+// calls of methods in these namespaces are recorded.
+func NewAuditLog(w io.Writer, namespaces []string) *AuditLog {
+	l := &AuditLog{enc: json.NewEncoder(w)}
+	if len(namespaces) > 0 {
+		l.namespaces = make(map[string]bool, len(namespaces))
+		for _, ns := range namespaces {
+			l.namespaces[ns] = true
+		}
+	}
+	return l
+}
+
+// audits reports whether calls of the given method are recorded.
+func (l *AuditLog) audits(method string) bool {
+	if l.namespaces == nil {
+		return true
+	}
+	ns, _, _ := strings.Cut(method, serviceMethodSeparator)
+	return l.namespaces[ns]
+}
+
+// record writes the audit record of a call message and its answer.
+func (l *AuditLog) record(ctx context.Context, msg, answer *jsonrpcMessage, start time.Time) {
+	if !l.audits(msg.Method) {
+		return
+	}
+	info := PeerInfoFromContext(ctx)
+	rec := &AuditRecord{
+		Time:       start.UTC(),
+		Method:     msg.Method,
+		Params:     redactParams(msg.Method, msg.Params),
+		Transport:  info.Transport,
+		RemoteAddr: info.RemoteAddr,
+		UserAgent:  info.HTTP.UserAgent,
+		Origin:     info.HTTP.Origin,
+		Duration:   time.Since(start),
+	}
+	if info.APIKey != "" {
+		hash := sha256.Sum256([]byte(info.APIKey))
+		rec.APIKey = hex.EncodeToString(hash[:8])
+	}
+	if answer != nil && answer.Error != nil {
+		rec.Error, rec.ErrorCode = answer.Error.Message, answer.Error.Code
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.enc.Encode(rec); err != nil {
+		log.Warn("Failed to write RPC audit record", "method", msg.Method, "err", err)
+	}
+}
+
+// redactParams returns the parameters of a call with the values of sensitive
+// arguments and fields replaced. Parameters which cannot be decoded are redacted
+// entirely, as they cannot be checked for secrets.
+func redactParams(method string, params json.RawMessage) json.RawMessage {
+	if len(params) == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.UseNumber()
+	var args []interface{}
+	if err := dec.Decode(&args); err != nil {
+		return json.RawMessage(`"` + redacted + `"`)
+	}
+	for _, i := range sensitiveParams[method] {
+		if i < len(args) {
+			args[i] = redacted
+		}
+	}
+	for i := range args {
+		args[i] = redactFields(args[i])
+	}
+	blob, err := json.Marshal(args)
+	if err != nil {
+		return nil
+	}
+	return blob
+}
+
+// redactFields replaces the values of sensitive fields in decoded JSON objects.
+func redactFields(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if sensitiveFields[strings.ToLower(key)] {
+				v[key] = redacted
+			} else {
+				v[key] = redactFields(value)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactFields(v[i])
+		}
+	}
+	return v
+}
@@ -0,0 +1,120 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestAuditLog(t *testing.T) {
+	t.Parallel()
+
+	var (
+		buf    bytes.Buffer
+		server = newTestServer()
+	)
+	server.SetAuditLog(NewAuditLog(&buf, []string{"test"}))
+	defer server.Stop()
+
+	client := DialInProc(server)
+	defer client.Close()
+
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "hello", 1, &echoArgs{S: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(nil, "test_returnError"); err == nil {
+		t.Fatal("expected error")
+	}
+	// Calls outside of the audited namespaces are not recorded.
+	if err := client.Call(nil, "nftest_echo", 1); err != nil {
+		t.Fatal(err)
+	}
+	var records []AuditRecord
+	for scanner := bufio.NewScanner(&buf); scanner.Scan(); {
+		var rec AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("invalid audit record %q: %v", scanner.Text(), err)
+		}
+		records = append(records, rec)
+	}
+	if len(records) != 2 {
+		t.Fatalf("wrong number of records: have %d, want 2", len(records))
+	}
+	if rec := records[0]; rec.Method != "test_echo" || string(rec.Params) != `["hello",1,{"S":"x"}]` || rec.Transport != "ipc" || rec.Error != "" {
+		t.Errorf("wrong record of successful call: %+v", rec)
+	}
+	if rec := records[1]; rec.Method != "test_returnError" || rec.Error != (testError{}).Error() || rec.ErrorCode != (testError{}).ErrorCode() {
+		t.Errorf("wrong record of failed call: %+v", rec)
+	}
+}
+
+func TestAuditLogRedaction(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		method, params, want string
+	}{
+		{"eth_blockNumber", ``, ``},
+		{"eth_call", `[{"to":"0x01"},"latest"]`, `[{"to":"0x01"},"latest"]`},
+		{"personal_unlockAccount", `["0x01","hunter2",300]`, `["0x01","[redacted]",300]`},
+		{"personal_sendTransaction", `[{"from":"0x01"},"hunter2"]`, `[{"from":"0x01"},"[redacted]"]`},
+		{"personal_importRawKey", `["0xkey","hunter2"]`, `["[redacted]","[redacted]"]`},
+		{"foo_bar", `[{"Password":"hunter2","nested":[{"mnemonic":"a b c"}]}]`, `[{"Password":"[redacted]","nested":[{"mnemonic":"[redacted]"}]}]`},
+		{"foo_bar", `{"password":"hunter2"}`, `"[redacted]"`},
+	}
+	for i, test := range tests {
+		have := string(redactParams(test.method, json.RawMessage(test.params)))
+		if have != test.want {
+			t.Errorf("test %d: wrong redaction of %s params: have %s, want %s", i, test.method, have, test.want)
+		}
+	}
+}
+
+// Tests that the passwords of the signing methods never reach the audit log.
+func TestAuditLogSecrets(t *testing.T) {
+	t.Parallel()
+
+	calls := []struct {
+		method, params string
+	}{
+		{"personal_importMnemonic", `["hunter2 word list","hunter2","m/44'/60'/0'/0/0",1,"hunter2"]`},
+		{"personal_sign", `["0xdeadbeef","0x01","hunter2"]`},
+		{"personal_signTransaction", `[{"from":"0x01"},"hunter2"]`},
+		{"personal_sendTransaction", `[{"from":"0x01"},"hunter2"]`},
+		{"account_sign", `["0xdeadbeef","0x01","hunter2"]`},
+		{"account_signTransaction", `[{"from":"0x01"},"hunter2"]`},
+		{"account_sendTransaction", `[{"from":"0x01"},"hunter2"]`},
+	}
+	var buf bytes.Buffer
+	l := NewAuditLog(&buf, nil)
+	for _, call := range calls {
+		msg := &jsonrpcMessage{Method: call.method, Params: json.RawMessage(call.params)}
+		l.record(context.Background(), msg, nil, time.Now())
+	}
+	if n := bytes.Count(buf.Bytes(), []byte("\n")); n != len(calls) {
+		t.Fatalf("wrong number of records: have %d, want %d", n, len(calls))
+	}
+	if bytes.Contains(buf.Bytes(), []byte("hunter2")) {
+		t.Fatalf("password written to the audit log:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// DefaultSubscriptionBuffer is the number of notifications queued per subscription
+// if a backpressure policy is configured without a buffer size.
+const DefaultSubscriptionBuffer = 1024
+
+// BackpressurePolicy determines how notifications are handled when a subscriber
+// reads them slower than they are produced.
+type BackpressurePolicy string
+
+const (
+	// BackpressureNone writes notifications synchronously, stalling the producer
+	// of the notifications until the client has received them. This is the default.
+	BackpressureNone BackpressurePolicy = ""
+
+	// BackpressureBuffer queues notifications and only stalls the producer once
+	// the queue of the subscription is full.
+	BackpressureBuffer BackpressurePolicy = "buffer"
+
+	// BackpressureDropOldest queues notifications and discards the oldest queued
+	// one when the queue of the subscription is full.
+	BackpressureDropOldest BackpressurePolicy = "drop-oldest"
+
+	// BackpressureDisconnect queues notifications and closes the connection when
+	// the queue of the subscription is full.
+	BackpressureDisconnect BackpressurePolicy = "disconnect"
+)
+
+var (
+	droppedNotificationsMeter = metrics.NewRegisteredMeter("rpc/subscriptions/dropped", nil)
+
+	errSubscriberTooSlow = errors.New("subscriber too slow, connection closed")
+)
+
+// ParseBackpressurePolicy parses the name of a backpressure policy. The names
+// "none" and "" both select BackpressureNone.
+func ParseBackpressurePolicy(name string) (BackpressurePolicy, error) {
+	switch policy := BackpressurePolicy(name); policy {
+	case BackpressureNone, BackpressureBuffer, BackpressureDropOldest, BackpressureDisconnect:
+		return policy, nil
+	case "none":
+		return BackpressureNone, nil
+	default:
+		return "", fmt.Errorf("unknown backpressure policy %q", name)
+	}
+}
+
+// subscriptionQueue holds the notifications of a subscription which have not been
+// written to the connection yet. It is guarded by the mutex of the notifier.
+type subscriptionQueue struct {
+	items   []any
+	writing bool       // whether a goroutine is writing out the items
+	space   *sync.Cond // signaled when items are removed
+	dropped uint64     // number of discarded notifications
+	failed  error      // write error terminating the subscription
+}
+
+// enqueue queues a notification, applying the backpressure policy of the connection
+// if the queue is full. It must be called with n.mu held.
+func (n *Notifier) enqueue(data any) error {
+	q := &n.queue
+	if q.space == nil {
+		q.space = sync.NewCond(&n.mu)
+	}
+	limit := n.h.subBuffer
+	if limit <= 0 {
+		limit = DefaultSubscriptionBuffer
+	}
+	for q.failed == nil && len(q.items) >= limit {
+		switch n.h.subPolicy {
+		case BackpressureDropOldest:
+			q.items[0] = nil
+			q.items = q.items[1:]
+			q.dropped++
+			droppedNotificationsMeter.Mark(1)
+
+		case BackpressureDisconnect:
+			q.dropped++
+			droppedNotificationsMeter.Mark(1)
+			q.failed, q.items = errSubscriberTooSlow, nil
+			n.h.log.Warn("Closing connection of slow subscriber", "id", n.sub.ID, "queued", limit)
+			if c, ok := n.h.conn.(interface{ close() }); ok {
+				c.close()
+			}
+
+		default:
+			q.space.Wait()
+		}
+	}
+	if q.failed != nil {
+		return q.failed
+	}
+	q.items = append(q.items, data)
+	if !q.writing {
+		q.writing = true
+		go n.writeQueue()
+	}
+	return nil
+}
+
+// writeQueue writes out the queued notifications until the queue is empty. Every
+// notification carries the number of notifications dropped before it, so clients
+// are able to detect gaps.
+func (n *Notifier) writeQueue() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	q := &n.queue
+	for len(q.items) > 0 && q.failed == nil {
+		data := q.items[0]
+		q.items[0] = nil
+		q.items = q.items[1:]
+		dropped := q.dropped
+		q.space.Broadcast()
+
+		n.mu.Unlock()
+		err := n.send(n.sub, data, dropped)
+		n.mu.Lock()
+
+		if err != nil {
+			q.failed, q.items = err, nil
+			q.space.Broadcast()
+		}
+	}
+	q.writing = false
+}
+
+// Dropped returns the number of notifications of the subscription which have been
+// discarded because the client did not keep up with them.
+func (n *Notifier) Dropped() uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.queue.dropped
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	cacheHitMeter  = metrics.NewRegisteredMeter("rpc/cache/hit", nil)
+	cacheMissMeter = metrics.NewRegisteredMeter("rpc/cache/miss", nil)
+)
+
+// CachePolicy decides which calls may be answered from the response cache of a
+// server. Only calls whose result can never change, e.g. because they reference
+// a finalized block, should be reported as cacheable.
+type CachePolicy interface {
+	Cacheable(ctx context.Context, method string, params json.RawMessage) bool
+}
+
+// responseCache holds the results of immutable calls, keyed by the method name
+// and the raw call parameters.
+type responseCache struct {
+	policy  CachePolicy
+	results *lru.SizeConstrainedCache[string, json.RawMessage]
+}
+
+func newResponseCache(size uint64, policy CachePolicy) *responseCache {
+	return &responseCache{
+		policy:  policy,
+		results: lru.NewSizeConstrainedCache[string, json.RawMessage](size),
+	}
+}
+
+// key returns the cache key of the given call, or false if its result must not
+// be cached.
+func (c *responseCache) key(ctx context.Context, method string, params json.RawMessage) (string, bool) {
+	if !c.policy.Cacheable(ctx, method, params) {
+		return "", false
+	}
+	return method + "\x00" + string(params), true
+}
+
+// get retrieves the cached result stored under key.
+func (c *responseCache) get(key string) (json.RawMessage, bool) {
+	result, ok := c.results.Get(key)
+	if ok {
+		cacheHitMeter.Mark(1)
+	} else {
+		cacheMissMeter.Mark(1)
+	}
+	return result, ok
+}
+
+// put stores a call result. Empty results are not retained, since they usually
+// indicate that the referenced object is not yet known to the node.
+func (c *responseCache) put(key string, result json.RawMessage) {
+	if len(result) == 0 || bytes.Equal(result, null) {
+		return
+	}
+	c.results.Add(key, result)
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+)
+
+// cacheTestService counts the calls reaching it.
+type cacheTestService struct {
+	calls atomic.Int32
+}
+
+func (s *cacheTestService) Get(n int) *int {
+	s.calls.Add(1)
+	if n < 0 {
+		return nil
+	}
+	return &n
+}
+
+// cacheTestPolicy treats the results for numbers up to a limit as immutable.
+type cacheTestPolicy struct {
+	limit int
+}
+
+func (p *cacheTestPolicy) Cacheable(ctx context.Context, method string, params json.RawMessage) bool {
+	var args []int
+	if method != "cache_get" || json.Unmarshal(params, &args) != nil || len(args) != 1 {
+		return false
+	}
+	return args[0] <= p.limit
+}
+
+func TestServerResponseCache(t *testing.T) {
+	t.Parallel()
+
+	var (
+		server  = NewServer()
+		service = new(cacheTestService)
+	)
+	defer server.Stop()
+	if err := server.RegisterName("cache", service); err != nil {
+		t.Fatal(err)
+	}
+	server.SetResponseCache(1024, &cacheTestPolicy{limit: 10})
+
+	client := DialInProc(server)
+	defer client.Close()
+
+	tests := []struct {
+		arg   int
+		want  *int
+		calls int32 // Total number of calls served by the service afterwards
+	}{
+		{arg: 5, want: intPtr(5), calls: 1},
+		{arg: 5, want: intPtr(5), calls: 1},   // immutable result served from cache
+		{arg: 20, want: intPtr(20), calls: 2}, // mutable result not cached
+		{arg: 20, want: intPtr(20), calls: 3},
+		{arg: -1, want: nil, calls: 4}, // null results are not cached
+		{arg: -1, want: nil, calls: 5},
+	}
+	for i, tt := range tests {
+		var have *int
+		if err := client.Call(&have, "cache_get", tt.arg); err != nil {
+			t.Fatalf("test %d: call failed: %v", i, err)
+		}
+		if (have == nil) != (tt.want == nil) || (have != nil && *have != *tt.want) {
+			t.Errorf("test %d: result mismatch: have %v, want %v", i, have, tt.want)
+		}
+		if calls := service.calls.Load(); calls != tt.calls {
+			t.Errorf("test %d: call count mismatch: have %d, want %d", i, calls, tt.calls)
+		}
+	}
+}
+
+func intPtr(n int) *int { return &n }
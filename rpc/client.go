@@ -88,6 +88,17 @@ type Client struct {
 	// config fields
 	batchItemLimit       int
 	batchResponseMaxSize int
+	batchTimeout         time.Duration
+	accessControl        *atomic.Pointer[AccessControl]
+	rateLimiter          *rateLimiter
+	responseCache        *responseCache
+	methodTimeouts       map[string]time.Duration
+	subPolicy            BackpressurePolicy
+	subBuffer            int
+	auditLog             *AuditLog
+	maxSubscriptions     int
+	msgRate              float64
+	msgBurst             int
 
 	// writeConn is used for writing to the connection on the caller's goroutine. It should
 	// only be accessed outside of dispatch, with the write lock held. The write lock is
@@ -120,6 +131,16 @@ func (c *Client) newClientConn(conn ServerCodec) *clientConn {
 	ctx = context.WithValue(ctx, clientContextKey{}, c)
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, conn.peerInfo())
 	handler := newHandler(ctx, conn, c.idgen, c.services, c.batchItemLimit, c.batchResponseMaxSize)
+	handler.batchTimeout = c.batchTimeout
+	handler.accessControl = c.accessControl
+	handler.rateLimiter = c.rateLimiter
+	handler.responseCache = c.responseCache
+	handler.methodTimeouts = c.methodTimeouts
+	handler.subPolicy = c.subPolicy
+	handler.subBuffer = c.subBuffer
+	handler.auditLog = c.auditLog
+	handler.maxSubscriptions = c.maxSubscriptions
+	handler.msgLimiter = newLimiter(c.msgRate, c.msgBurst)
 	return &clientConn{conn, handler}
 }
 
@@ -247,6 +268,17 @@ func initClient(conn ServerCodec, services *serviceRegistry, cfg *clientConfig)
 		idgen:                cfg.idgen,
 		batchItemLimit:       cfg.batchItemLimit,
 		batchResponseMaxSize: cfg.batchResponseLimit,
+		batchTimeout:         cfg.batchTimeout,
+		accessControl:        cfg.accessControl,
+		rateLimiter:          cfg.rateLimiter,
+		responseCache:        cfg.responseCache,
+		methodTimeouts:       cfg.methodTimeouts,
+		subPolicy:            cfg.subPolicy,
+		subBuffer:            cfg.subBuffer,
+		auditLog:             cfg.auditLog,
+		maxSubscriptions:     cfg.maxSubscriptions,
+		msgRate:              cfg.msgRate,
+		msgBurst:             cfg.msgBurst,
 		writeConn:            conn,
 		close:                make(chan struct{}),
 		closing:              make(chan struct{}),
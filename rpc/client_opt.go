@@ -18,6 +18,8 @@ package rpc
 
 import (
 	"net/http"
+	"sync/atomic"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
@@ -41,6 +43,17 @@ type clientConfig struct {
 	idgen              func() ID
 	batchItemLimit     int
 	batchResponseLimit int
+	batchTimeout       time.Duration
+	accessControl      *atomic.Pointer[AccessControl]
+	rateLimiter        *rateLimiter
+	responseCache      *responseCache
+	methodTimeouts     map[string]time.Duration
+	subPolicy          BackpressurePolicy
+	subBuffer          int
+	auditLog           *AuditLog
+	maxSubscriptions   int
+	msgRate            float64
+	msgBurst           int
 }
 
 func (cfg *clientConfig) initHeaders() {
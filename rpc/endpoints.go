@@ -43,10 +43,21 @@ func StartIPCEndpoint(ipcEndpoint string, apis []API) (net.Listener, *Server, er
 	}
 	log.Debug("IPCs registered", "namespaces", strings.Join(registered, ","))
 	// All APIs registered, start the IPC listener.
-	listener, err := ipcListen(ipcEndpoint)
+	listener, err := handler.ServeIPCEndpoint(ipcEndpoint)
 	if err != nil {
 		return nil, nil, err
 	}
-	go handler.ServeListener(listener)
 	return listener, handler, nil
 }
+
+// ServeIPCEndpoint opens an IPC listener on the given endpoint and starts serving
+// the registered APIs on it in the background. Closing the returned listener stops
+// accepting new connections.
+func (s *Server) ServeIPCEndpoint(ipcEndpoint string) (net.Listener, error) {
+	listener, err := ipcListen(ipcEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	go s.ServeListener(listener)
+	return listener, nil
+}
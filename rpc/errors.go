@@ -55,12 +55,16 @@ var (
 	_ Error = new(invalidMessageError)
 	_ Error = new(invalidParamsError)
 	_ Error = new(internalServerError)
+	_ Error = new(accessDeniedError)
+	_ Error = new(rateLimitedError)
 )
 
 const (
 	errcodeDefault          = -32000
 	errcodeTimeout          = -32002
 	errcodeResponseTooLarge = -32003
+	errcodeAccessDenied     = -32004
+	errcodeLimitExceeded    = -32005
 	errcodePanic            = -32603
 	errcodeMarshalError     = -32603
 
@@ -19,13 +19,16 @@ package rpc
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"reflect"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/time/rate"
 )
 
 // handler handles JSON-RPC messages. There is one handler per connection. Note that
@@ -62,9 +65,20 @@ type handler struct {
 	allowSubscribe       bool
 	batchRequestLimit    int
 	batchResponseMaxSize int
-
-	subLock    sync.Mutex
-	serverSubs map[ID]*Subscription
+	batchTimeout         time.Duration                  // optional execution time limit of batches
+	accessControl        *atomic.Pointer[AccessControl] // optional method access restrictions
+	rateLimiter          *rateLimiter                   // optional per-client request quotas
+	responseCache        *responseCache                 // optional cache of immutable results
+	methodTimeouts       map[string]time.Duration       // optional per-method execution deadlines
+	subPolicy            BackpressurePolicy             // handling of notifications to slow subscribers
+	subBuffer            int                            // notifications queued per subscription
+	auditLog             *AuditLog                      // optional record of served calls
+	maxSubscriptions     int                            // optional limit of active subscriptions
+	msgLimiter           *rate.Limiter                  // optional limit of the incoming message rate
+
+	subLock     sync.Mutex
+	serverSubs  map[ID]*Subscription
+	pendingSubs int // subscribe calls in progress
 }
 
 type callProc struct {
@@ -191,6 +205,12 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 	if len(calls) == 0 {
 		return
 	}
+	if h.throttled() {
+		h.startCallProc(func(cp *callProc) {
+			h.respondThrottled(cp, calls, true)
+		})
+		return
+	}
 
 	// Process calls on a goroutine because they may block indefinitely:
 	h.startCallProc(func(cp *callProc) {
@@ -205,8 +225,9 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 
 		// Cancel the request context after timeout and send an error response. Since the
 		// currently-running method might not return immediately on timeout, we must wait
-		// for the timeout concurrently with processing the request.
-		if timeout, ok := ContextRequestTimeout(cp.ctx); ok {
+		// for the timeout concurrently with processing the request. The responses of the
+		// calls completed until then are still delivered.
+		if timeout, ok := h.batchRequestTimeout(cp.ctx); ok {
 			timer = time.AfterFunc(timeout, func() {
 				cancel()
 				err := &internalServerError{errcodeTimeout, errMsgTimeout}
@@ -247,6 +268,16 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 	})
 }
 
+// batchRequestTimeout returns the time left for processing a batch, taking both
+// the transport timeouts and the configured batch timeout into account.
+func (h *handler) batchRequestTimeout(ctx context.Context) (time.Duration, bool) {
+	timeout, ok := ContextRequestTimeout(ctx)
+	if h.batchTimeout > 0 && (!ok || h.batchTimeout < timeout) {
+		return h.batchTimeout, true
+	}
+	return timeout, ok
+}
+
 func (h *handler) respondWithBatchTooLarge(cp *callProc, batch []*jsonrpcMessage) {
 	resp := errorMessage(&invalidRequestError{errMsgBatchTooLarge})
 	// Find the first call and add its "id" field to the error.
@@ -265,12 +296,42 @@ func (h *handler) respondWithBatchTooLarge(cp *callProc, batch []*jsonrpcMessage
 func (h *handler) handleMsg(msg *jsonrpcMessage) {
 	msgs := []*jsonrpcMessage{msg}
 	h.handleResponses(msgs, func(msg *jsonrpcMessage) {
+		if h.throttled() {
+			h.startCallProc(func(cp *callProc) {
+				h.respondThrottled(cp, msgs, false)
+			})
+			return
+		}
 		h.startCallProc(func(cp *callProc) {
 			h.handleNonBatchCall(cp, msg)
 		})
 	})
 }
 
+// throttled reports whether an incoming message exceeds the message rate limit
+// of the connection.
+func (h *handler) throttled() bool {
+	return h.msgLimiter != nil && !h.msgLimiter.Allow()
+}
+
+// respondThrottled answers the calls among msgs with a 'limit exceeded' error,
+// without executing them.
+func (h *handler) respondThrottled(cp *callProc, msgs []*jsonrpcMessage, batch bool) {
+	err := &rateLimitedError{"message rate limit exceeded"}
+	resp := make([]*jsonrpcMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		if msg.isCall() {
+			resp = append(resp, msg.errorResponse(err))
+		}
+	}
+	switch {
+	case batch && len(resp) > 0:
+		h.conn.writeJSON(cp.ctx, resp, false)
+	case !batch && len(resp) == 1:
+		h.conn.writeJSON(cp.ctx, resp[0], false)
+	}
+}
+
 func (h *handler) handleNonBatchCall(cp *callProc, msg *jsonrpcMessage) {
 	var (
 		responded sync.Once
@@ -358,6 +419,7 @@ func (h *handler) addSubscriptions(nn []*Notifier) {
 	h.subLock.Lock()
 	defer h.subLock.Unlock()
 
+	h.pendingSubs -= len(nn)
 	for _, n := range nn {
 		if sub := n.takeSubscription(); sub != nil {
 			h.serverSubs[sub.ID] = sub
@@ -453,7 +515,7 @@ func (h *handler) handleSubscriptionResult(msg *jsonrpcMessage) {
 		return
 	}
 	if h.clientSubs[result.ID] != nil {
-		h.clientSubs[result.ID].deliver(result.Result)
+		h.clientSubs[result.ID].deliver(result.Result, result.Dropped)
 	}
 }
 
@@ -463,11 +525,17 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 	switch {
 	case msg.isNotification():
 		h.handleCall(ctx, msg)
+		if h.auditLog != nil {
+			h.auditLog.record(ctx.ctx, msg, nil, start)
+		}
 		h.log.Debug("Served "+msg.Method, "duration", time.Since(start))
 		return nil
 
 	case msg.isCall():
 		resp := h.handleCall(ctx, msg)
+		if h.auditLog != nil {
+			h.auditLog.record(ctx.ctx, msg, resp, start)
+		}
 		var ctx []interface{}
 		ctx = append(ctx, "reqid", idForLog{msg.ID}, "duration", time.Since(start))
 		if resp.Error != nil {
@@ -491,6 +559,16 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	if !msg.isUnsubscribe() && !h.permits(cp.ctx, msg.Method) {
+		return msg.errorResponse(&accessDeniedError{method: msg.Method})
+	}
+	if h.rateLimiter != nil && !msg.isUnsubscribe() {
+		release, err := h.rateLimiter.acquire(PeerInfoFromContext(cp.ctx), msg.Method)
+		if err != nil {
+			return msg.errorResponse(err)
+		}
+		defer release()
+	}
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
@@ -509,7 +587,12 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
 	start := time.Now()
-	answer := h.runMethod(cp.ctx, msg, callb, args)
+	ctx, cancel := h.methodContext(cp.ctx, msg.Method)
+	defer cancel()
+	answer := h.runCachedMethod(ctx, msg, callb, args)
+	if answer.Error != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && cp.ctx.Err() == nil {
+		answer = msg.errorResponse(&internalServerError{errcodeTimeout, errMsgTimeout})
+	}
 
 	// Collect the statistics for RPC calls if metrics is enabled.
 	// We only care about pure rpc call. Filter out subscription.
@@ -522,11 +605,34 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 		}
 		rpcServingTimer.UpdateSince(start)
 		updateServeTimeHistogram(msg.Method, answer.Error == nil, time.Since(start))
+		updateMethodMetrics(msg.Method, answer.Error == nil, len(msg.Params), len(answer.Result))
 	}
 
 	return answer
 }
 
+// methodContext derives the context for executing a call of method, applying the
+// execution deadline configured for the method.
+func (h *handler) methodContext(ctx context.Context, method string) (context.Context, context.CancelFunc) {
+	if timeout := h.methodTimeouts[method]; timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return ctx, func() {}
+}
+
+// permits reports whether the access rules of the server and the scopes of the
+// client's token allow the client to call the given method.
+func (h *handler) permits(ctx context.Context, method string) bool {
+	info := PeerInfoFromContext(ctx)
+	if !scopesPermit(info.Scopes, method) {
+		return false
+	}
+	if h.accessControl == nil {
+		return true
+	}
+	return h.accessControl.Load().Permits(info, method)
+}
+
 // handleSubscribe processes *_subscribe method calls.
 func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
 	if !h.allowSubscribe {
@@ -552,6 +658,12 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	}
 	args = args[1:]
 
+	// Reserve a slot for the subscription, it's released or taken over by
+	// addSubscriptions once the call has finished.
+	if !h.reserveSubscription() {
+		return msg.errorResponse(&rateLimitedError{"too many subscriptions"})
+	}
+
 	// Install notifier in context so the subscription handler can find it.
 	n := &Notifier{h: h, namespace: namespace}
 	cp.notifiers = append(cp.notifiers, n)
@@ -560,6 +672,19 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	return h.runMethod(ctx, msg, callb, args)
 }
 
+// reserveSubscription accounts a subscribe call in progress, unless the limit of
+// subscriptions is reached.
+func (h *handler) reserveSubscription() bool {
+	h.subLock.Lock()
+	defer h.subLock.Unlock()
+
+	if h.maxSubscriptions > 0 && len(h.serverSubs)+h.pendingSubs >= h.maxSubscriptions {
+		return false
+	}
+	h.pendingSubs++
+	return true
+}
+
 // runMethod runs the Go callback for an RPC method.
 func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value) *jsonrpcMessage {
 	result, err := callb.call(ctx, msg.Method, args)
@@ -569,6 +694,26 @@ func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *cal
 	return msg.response(result)
 }
 
+// runCachedMethod runs the method like runMethod, but answers calls which have
+// an immutable result from the response cache if possible.
+func (h *handler) runCachedMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value) *jsonrpcMessage {
+	if h.responseCache == nil || callb == h.unsubscribeCb {
+		return h.runMethod(ctx, msg, callb, args)
+	}
+	key, ok := h.responseCache.key(ctx, msg.Method, msg.Params)
+	if !ok {
+		return h.runMethod(ctx, msg, callb, args)
+	}
+	if result, ok := h.responseCache.get(key); ok {
+		return msg.response(result)
+	}
+	answer := h.runMethod(ctx, msg, callb, args)
+	if answer.Error == nil {
+		h.responseCache.put(key, answer.Result)
+	}
+	return answer
+}
+
 // unsubscribe is the callback function for all *_unsubscribe calls.
 func (h *handler) unsubscribe(ctx context.Context, id ID) (bool, error) {
 	h.subLock.Lock()
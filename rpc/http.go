@@ -318,11 +318,13 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create request-scoped context.
-	connInfo := PeerInfo{Transport: "http", RemoteAddr: r.RemoteAddr}
+	connInfo := PeerInfo{Transport: "http", RemoteAddr: s.trustedProxies.clientAddr(r)}
 	connInfo.HTTP.Version = r.Proto
 	connInfo.HTTP.Host = r.Host
 	connInfo.HTTP.Origin = r.Header.Get("Origin")
 	connInfo.HTTP.UserAgent = r.Header.Get("User-Agent")
+	connInfo.APIKey = r.Header.Get(APIKeyHeader)
+	connInfo.Scopes = scopesFromContext(r.Context())
 	ctx := r.Context()
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, connInfo)
 
@@ -32,3 +32,29 @@ func DialInProc(handler *Server) *Client {
 	})
 	return c
 }
+
+// DialInProcAs attaches an in-process connection to the given RPC server on behalf
+// of the client described by info. The access rules, rate limits and audit log of
+// the server apply to calls made through the connection as if they were sent by
+// that client. This is meant for servers of other protocols which relay their
+// requests to an RPC server.
+func DialInProcAs(handler *Server, info PeerInfo) *Client {
+	initctx := context.Background()
+	cfg := new(clientConfig)
+	c, _ := newClient(initctx, cfg, func(context.Context) (ServerCodec, error) {
+		p1, p2 := net.Pipe()
+		go handler.ServeCodec(&relayCodec{ServerCodec: NewCodec(p1), info: info}, 0)
+		return NewCodec(p2), nil
+	})
+	return c
+}
+
+// relayCodec attributes the messages of an in-process connection to a client.
+type relayCodec struct {
+	ServerCodec
+	info PeerInfo
+}
+
+func (c *relayCodec) peerInfo() PeerInfo { return c.info }
+
+func (c *relayCodec) remoteAddr() string { return c.info.RemoteAddr }
@@ -42,13 +42,15 @@ const (
 var null = json.RawMessage("null")
 
 type subscriptionResult struct {
-	ID     string          `json:"subscription"`
-	Result json.RawMessage `json:"result,omitempty"`
+	ID      string          `json:"subscription"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Dropped uint64          `json:"dropped,omitempty"`
 }
 
 type subscriptionResultEnc struct {
-	ID     string `json:"subscription"`
-	Result any    `json:"result"`
+	ID      string `json:"subscription"`
+	Result  any    `json:"result"`
+	Dropped uint64 `json:"dropped,omitempty"` // notifications discarded before this one
 }
 
 type jsonrpcSubscriptionNotification struct {
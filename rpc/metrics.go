@@ -32,6 +32,12 @@ var (
 	serveTimeHistName = "rpc/duration"
 
 	rpcServingTimer = metrics.NewRegisteredTimer("rpc/duration/all", nil)
+
+	// callsMeterName is the prefix of the per-method call rate meters.
+	callsMeterName = "rpc/calls"
+
+	// payloadSizeHistName is the prefix of the per-method payload size histograms.
+	payloadSizeHistName = "rpc/size"
 )
 
 // updateServeTimeHistogram tracks the serving time of a remote RPC call.
@@ -48,3 +54,22 @@ func updateServeTimeHistogram(method string, success bool, elapsed time.Duration
 	}
 	metrics.GetOrRegisterHistogramLazy(h, nil, sampler).Update(elapsed.Nanoseconds())
 }
+
+// updateMethodMetrics tracks the call rate, error rate and the request and response
+// payload sizes of a remote RPC call.
+func updateMethodMetrics(method string, success bool, requestSize, responseSize int) {
+	if !metrics.Enabled {
+		return
+	}
+	metrics.GetOrRegisterMeter(fmt.Sprintf("%s/%s/all", callsMeterName, method), nil).Mark(1)
+	if !success {
+		metrics.GetOrRegisterMeter(fmt.Sprintf("%s/%s/failure", callsMeterName, method), nil).Mark(1)
+	}
+	sampler := func() metrics.Sample {
+		return metrics.ResettingSample(
+			metrics.NewExpDecaySample(1028, 0.015),
+		)
+	}
+	metrics.GetOrRegisterHistogramLazy(fmt.Sprintf("%s/%s/request", payloadSizeHistName, method), nil, sampler).Update(int64(requestSize))
+	metrics.GetOrRegisterHistogramLazy(fmt.Sprintf("%s/%s/response", payloadSizeHistName, method), nil, sampler).Update(int64(responseSize))
+}
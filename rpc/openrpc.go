@@ -0,0 +1,269 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// openRPCVersion is the version of the OpenRPC specification the documents
+// returned by rpc_discover conform to.
+const openRPCVersion = "1.2.6"
+
+var (
+	bigIntType        = reflect.TypeOf(big.Int{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	// invalidSchemaName matches the characters not permitted in the keys of
+	// the schema components of an OpenRPC document.
+	invalidSchemaName = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+)
+
+// OpenRPCDocument is an OpenRPC service description of the methods provided by
+// a server.
+type OpenRPCDocument struct {
+	OpenRPC    string            `json:"openrpc"`
+	Info       OpenRPCInfo       `json:"info"`
+	Methods    []OpenRPCMethod   `json:"methods"`
+	Components OpenRPCComponents `json:"components"`
+}
+
+// OpenRPCInfo is the metadata of an OpenRPC document.
+type OpenRPCInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenRPCMethod describes a single method of the server.
+type OpenRPCMethod struct {
+	Name   string                     `json:"name"`
+	Params []OpenRPCContentDescriptor `json:"params"`
+	Result *OpenRPCContentDescriptor  `json:"result,omitempty"`
+}
+
+// OpenRPCContentDescriptor describes a parameter or the result of a method.
+type OpenRPCContentDescriptor struct {
+	Name     string      `json:"name"`
+	Required bool        `json:"required,omitempty"`
+	Schema   *JSONSchema `json:"schema"`
+}
+
+// OpenRPCComponents holds the schemas referenced by the methods of a document.
+type OpenRPCComponents struct {
+	Schemas map[string]*JSONSchema `json:"schemas"`
+}
+
+// JSONSchema is the subset of JSON Schema used to describe the values accepted
+// and returned by methods. An empty schema matches any value.
+type JSONSchema struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+}
+
+// Discover returns an OpenRPC document describing the methods currently
+// registered on the server.
+func (s *RPCService) Discover() *OpenRPCDocument {
+	s.server.services.mu.Lock()
+	defer s.server.services.mu.Unlock()
+
+	gen := &schemaGenerator{schemas: make(map[string]*JSONSchema)}
+	doc := &OpenRPCDocument{
+		OpenRPC: openRPCVersion,
+		Info: OpenRPCInfo{
+			Title:   "Go Ethereum JSON-RPC API",
+			Version: params.VersionWithMeta,
+		},
+		Methods: []OpenRPCMethod{},
+	}
+	for name, svc := range s.server.services.services {
+		for method, cb := range svc.callbacks {
+			doc.Methods = append(doc.Methods, gen.method(name+serviceMethodSeparator+method, cb))
+		}
+		if len(svc.subscriptions) > 0 {
+			doc.Methods = append(doc.Methods, subscribeMethods(name, svc.subscriptions)...)
+		}
+	}
+	sort.Slice(doc.Methods, func(i, j int) bool {
+		return doc.Methods[i].Name < doc.Methods[j].Name
+	})
+	doc.Components.Schemas = gen.schemas
+	return doc
+}
+
+// subscribeMethods describes the subscribe and unsubscribe methods of a service
+// providing the given subscriptions.
+func subscribeMethods(service string, subscriptions map[string]*callback) []OpenRPCMethod {
+	names := make([]string, 0, len(subscriptions))
+	for name := range subscriptions {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return []OpenRPCMethod{
+		{
+			Name: service + subscribeMethodSuffix,
+			Params: []OpenRPCContentDescriptor{{
+				Name:     "subscription",
+				Required: true,
+				Schema:   &JSONSchema{Type: "string", Enum: names},
+			}},
+			Result: &OpenRPCContentDescriptor{Name: "subscriptionId", Schema: &JSONSchema{Type: "string"}},
+		},
+		{
+			Name: service + unsubscribeMethodSuffix,
+			Params: []OpenRPCContentDescriptor{{
+				Name:     "subscriptionId",
+				Required: true,
+				Schema:   &JSONSchema{Type: "string"},
+			}},
+			Result: &OpenRPCContentDescriptor{Name: "result", Schema: &JSONSchema{Type: "boolean"}},
+		},
+	}
+}
+
+// schemaGenerator derives JSON schemas from the Go types of method arguments and
+// results. Named struct types are collected as reusable schema components.
+type schemaGenerator struct {
+	schemas map[string]*JSONSchema
+}
+
+// method describes the given method callback. Since parameter names are not
+// available through reflection, parameters are named by their position.
+func (g *schemaGenerator) method(name string, cb *callback) OpenRPCMethod {
+	m := OpenRPCMethod{Name: name, Params: []OpenRPCContentDescriptor{}}
+	for i, typ := range cb.argTypes {
+		m.Params = append(m.Params, OpenRPCContentDescriptor{
+			Name:     fmt.Sprintf("param%d", i+1),
+			Required: typ.Kind() != reflect.Ptr,
+			Schema:   g.schema(typ),
+		})
+	}
+	result := &JSONSchema{Type: "null"}
+	fntype := cb.fn.Type()
+	for i := 0; i < fntype.NumOut(); i++ {
+		if i != cb.errPos {
+			result = g.schema(fntype.Out(i))
+			break
+		}
+	}
+	m.Result = &OpenRPCContentDescriptor{Name: "result", Schema: result}
+	return m
+}
+
+// schema returns the JSON schema of values encoding the given type.
+func (g *schemaGenerator) schema(typ reflect.Type) *JSONSchema {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	// Types with custom encodings are either textual or opaque.
+	ptr := reflect.PointerTo(typ)
+	switch {
+	case typ == bigIntType:
+		return &JSONSchema{Type: "integer"}
+	case typ.Implements(jsonMarshalerType) || ptr.Implements(jsonMarshalerType):
+		if typ.Implements(textMarshalerType) || ptr.Implements(textMarshalerType) {
+			return &JSONSchema{Type: "string"}
+		}
+		return &JSONSchema{}
+	case typ.Implements(textMarshalerType) || ptr.Implements(textMarshalerType):
+		return &JSONSchema{Type: "string"}
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return &JSONSchema{Type: "string"} // base64 encoded
+		}
+		return &JSONSchema{Type: "array", Items: g.schema(typ.Elem())}
+	case reflect.Array:
+		return &JSONSchema{Type: "array", Items: g.schema(typ.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: g.schema(typ.Elem())}
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return g.structSchema(typ)
+		}
+		name := invalidSchemaName.ReplaceAllString(typ.String(), "_")
+		if _, ok := g.schemas[name]; !ok {
+			// Register a placeholder before descending to terminate recursion.
+			g.schemas[name] = &JSONSchema{Type: "object"}
+			g.schemas[name] = g.structSchema(typ)
+		}
+		return &JSONSchema{Ref: "#/components/schemas/" + name}
+	default:
+		return &JSONSchema{}
+	}
+}
+
+// structSchema returns the object schema of a struct type, following the field
+// naming rules of encoding/json.
+func (g *schemaGenerator) structSchema(typ reflect.Type) *JSONSchema {
+	schema := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema)}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for prop, s := range g.structSchema(embedded).Properties {
+					if _, ok := schema.Properties[prop]; !ok {
+						schema.Properties[prop] = s
+					}
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = g.schema(field.Type)
+	}
+	return schema
+}
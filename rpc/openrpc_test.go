@@ -0,0 +1,78 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiscover(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	var doc OpenRPCDocument
+	if err := client.Call(&doc, "rpc_discover"); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenRPC != openRPCVersion {
+		t.Errorf("wrong OpenRPC version %q", doc.OpenRPC)
+	}
+	methods := make(map[string]OpenRPCMethod)
+	for _, m := range doc.Methods {
+		methods[m.Name] = m
+	}
+	for _, name := range []string{"rpc_modules", "rpc_discover", "test_echo", "nftest_echo", "nftest_subscribe", "nftest_unsubscribe"} {
+		if _, ok := methods[name]; !ok {
+			t.Errorf("method %s missing from document", name)
+		}
+	}
+	// Check the description of test_echo(string, int, *echoArgs) echoResult.
+	echo := methods["test_echo"]
+	wantParams := []OpenRPCContentDescriptor{
+		{Name: "param1", Required: true, Schema: &JSONSchema{Type: "string"}},
+		{Name: "param2", Required: true, Schema: &JSONSchema{Type: "integer"}},
+		{Name: "param3", Required: false, Schema: &JSONSchema{Ref: "#/components/schemas/rpc.echoArgs"}},
+	}
+	if !reflect.DeepEqual(echo.Params, wantParams) {
+		t.Errorf("wrong test_echo params: %+v", echo.Params)
+	}
+	if echo.Result == nil || echo.Result.Schema.Ref != "#/components/schemas/rpc.echoResult" {
+		t.Errorf("wrong test_echo result: %+v", echo.Result)
+	}
+	args := doc.Components.Schemas["rpc.echoArgs"]
+	if args == nil || args.Type != "object" || args.Properties["S"] == nil || args.Properties["S"].Type != "string" {
+		t.Errorf("wrong echoArgs schema: %+v", args)
+	}
+	// Subscriptions are listed as the names accepted by the subscribe method.
+	sub := methods["nftest_subscribe"]
+	if len(sub.Params) == 0 || !reflect.DeepEqual(sub.Params[0].Schema.Enum, []string{"hangSubscription", "someSubscription"}) {
+		t.Errorf("wrong subscription names: %+v", sub.Params)
+	}
+	// Unregistered services disappear from the document.
+	server.UnregisterName("test")
+	if err := client.Call(&doc, "rpc_discover"); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range doc.Methods {
+		if m.Name == "test_echo" {
+			t.Fatal("unregistered method still listed")
+		}
+	}
+}
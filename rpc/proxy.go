@@ -0,0 +1,270 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// proxyHeaderTimeout is the time allowed for a proxy to send the PROXY
+	// protocol header after connecting.
+	proxyHeaderTimeout = 5 * time.Second
+
+	// proxyV1MaxLength is the maximum length of a PROXY protocol v1 header.
+	proxyV1MaxLength = 107
+)
+
+var (
+	proxyV1Prefix    = []byte("PROXY ")
+	proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+	errInvalidProxyHeader = errors.New("invalid PROXY protocol header")
+)
+
+// TrustedProxies is a set of networks whose forwarding information, i.e. the
+// X-Forwarded-For header and the PROXY protocol header, is trusted to identify
+// the clients of the server.
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies parses a list of IP addresses and CIDR ranges.
+func ParseTrustedProxies(specs []string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		if !strings.Contains(spec, "/") {
+			ip := net.ParseIP(spec)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy address %q", spec)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy range %q: %v", spec, err)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+// contains reports whether the IP address belongs to a trusted proxy.
+func (p TrustedProxies) contains(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range p {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAddr reports whether the network address belongs to a trusted proxy.
+func (p TrustedProxies) containsAddr(addr string) bool {
+	return p.contains(parseHopIP(addr))
+}
+
+// clientAddr returns the address of the client which issued the request. If the
+// request was relayed by trusted proxies, the client is the last untrusted hop
+// listed in the X-Forwarded-For header, or the address in the X-Real-Ip header.
+func (p TrustedProxies) clientAddr(r *http.Request) string {
+	if len(p) == 0 || !p.containsAddr(r.RemoteAddr) {
+		return r.RemoteAddr
+	}
+	var hops []string
+	for _, value := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := parseHopIP(hops[i])
+		if ip == nil {
+			// Don't trust anything beyond a malformed entry.
+			return r.RemoteAddr
+		}
+		if i == 0 || !p.contains(ip) {
+			return ip.String()
+		}
+	}
+	if ip := parseHopIP(r.Header.Get("X-Real-Ip")); ip != nil {
+		return ip.String()
+	}
+	return r.RemoteAddr
+}
+
+// parseHopIP parses an IP address which might carry a port.
+func parseHopIP(s string) net.IP {
+	s = strings.TrimSpace(s)
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	return net.ParseIP(strings.Trim(s, "[]"))
+}
+
+// NewProxyProtocolListener wraps a listener to accept connections from trusted
+// proxies which announce the address of the client using a PROXY protocol (v1 or
+// v2) header. The remote address of such connections is the announced client.
+// The header is optional, connections from untrusted peers are never inspected.
+func NewProxyProtocolListener(l net.Listener, trusted TrustedProxies) net.Listener {
+	return &proxyProtocolListener{Listener: l, trusted: trusted}
+}
+
+type proxyProtocolListener struct {
+	net.Listener
+	trusted TrustedProxies
+}
+
+func (l *proxyProtocolListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if !l.trusted.containsAddr(conn.RemoteAddr().String()) {
+		return conn, nil
+	}
+	return &proxyProtocolConn{Conn: conn, r: bufio.NewReader(conn)}, nil
+}
+
+// proxyProtocolConn is a connection of a trusted proxy. The PROXY protocol header
+// is read lazily, in order not to block the accept loop of the listener.
+type proxyProtocolConn struct {
+	net.Conn
+	r *bufio.Reader
+
+	once   sync.Once
+	remote net.Addr
+	err    error
+}
+
+func (c *proxyProtocolConn) init() {
+	c.once.Do(func() {
+		c.Conn.SetReadDeadline(time.Now().Add(proxyHeaderTimeout))
+		c.remote, c.err = readProxyHeader(c.r)
+		c.Conn.SetReadDeadline(time.Time{})
+	})
+}
+
+func (c *proxyProtocolConn) Read(b []byte) (int, error) {
+	c.init()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.r.Read(b)
+}
+
+func (c *proxyProtocolConn) RemoteAddr() net.Addr {
+	c.init()
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// readProxyHeader consumes the PROXY protocol header at the start of the stream,
+// returning the announced client address. If the stream doesn't begin with a
+// header, nothing is consumed and the returned address is nil.
+func readProxyHeader(r *bufio.Reader) (net.Addr, error) {
+	if head, _ := r.Peek(len(proxyV1Prefix)); bytes.Equal(head, proxyV1Prefix) {
+		return readProxyHeaderV1(r)
+	}
+	if head, _ := r.Peek(len(proxyV2Signature)); bytes.Equal(head, proxyV2Signature) {
+		return readProxyHeaderV2(r)
+	}
+	return nil, nil
+}
+
+// readProxyHeaderV1 reads a header of the human-readable protocol version, e.g.
+// "PROXY TCP4 192.0.2.1 192.0.2.2 56324 8545\r\n".
+func readProxyHeaderV1(r *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		if len(line) >= proxyV1MaxLength {
+			return nil, errInvalidProxyHeader
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, b)
+	}
+	fields := strings.Fields(string(line))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, errInvalidProxyHeader
+	}
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if ip == nil || err != nil {
+		return nil, errInvalidProxyHeader
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// readProxyHeaderV2 reads a header of the binary protocol version.
+func readProxyHeaderV2(r *bufio.Reader) (net.Addr, error) {
+	var head [16]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return nil, err
+	}
+	if head[12]>>4 != 2 {
+		return nil, errInvalidProxyHeader
+	}
+	body := make([]byte, binary.BigEndian.Uint16(head[14:]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	// LOCAL commands are sent by the proxy itself, e.g. for health checks.
+	if head[12]&0x0f != 1 {
+		return nil, nil
+	}
+	switch head[13] {
+	case 0x11: // TCP over IPv4
+		if len(body) < 12 {
+			return nil, errInvalidProxyHeader
+		}
+		return &net.TCPAddr{IP: net.IP(body[0:4]), Port: int(binary.BigEndian.Uint16(body[8:10]))}, nil
+	case 0x21: // TCP over IPv6
+		if len(body) < 36 {
+			return nil, errInvalidProxyHeader
+		}
+		return &net.TCPAddr{IP: net.IP(body[0:16]), Port: int(binary.BigEndian.Uint16(body[32:34]))}, nil
+	default:
+		return nil, nil
+	}
+}
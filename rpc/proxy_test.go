@@ -0,0 +1,132 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTrustedProxiesClientAddr(t *testing.T) {
+	t.Parallel()
+
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseTrustedProxies([]string{"10.0.0.0/33"}); err == nil {
+		t.Fatal("invalid range accepted")
+	}
+	tests := []struct {
+		remote    string
+		forwarded []string
+		realIP    string
+		want      string
+	}{
+		// Requests of untrusted peers are attributed to the peer.
+		{"1.2.3.4:1000", []string{"5.6.7.8"}, "", "1.2.3.4:1000"},
+		// Requests of trusted proxies are attributed to the forwarded client.
+		{"10.0.0.1:1000", []string{"5.6.7.8"}, "", "5.6.7.8"},
+		{"192.168.1.1:1000", []string{"5.6.7.8:4444"}, "", "5.6.7.8"},
+		{"10.0.0.1:1000", nil, "5.6.7.8", "5.6.7.8"},
+		{"10.0.0.1:1000", nil, "", "10.0.0.1:1000"},
+		// The last untrusted hop is the client, spoofed entries before it are ignored.
+		{"10.0.0.1:1000", []string{"9.9.9.9, 5.6.7.8, 10.0.0.2"}, "", "5.6.7.8"},
+		{"10.0.0.1:1000", []string{"9.9.9.9", "5.6.7.8"}, "", "5.6.7.8"},
+		// If all hops are trusted, the first one is the client.
+		{"10.0.0.1:1000", []string{"10.0.0.3, 10.0.0.2"}, "", "10.0.0.3"},
+		// Malformed entries are not trusted.
+		{"10.0.0.1:1000", []string{"garbage, 5.6.7.8"}, "", "5.6.7.8"},
+		{"10.0.0.1:1000", []string{"5.6.7.8, garbage"}, "", "10.0.0.1:1000"},
+		{"10.0.0.1:1000", []string{"[2001:db8::1]:80"}, "", "2001:db8::1"},
+	}
+	for i, test := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.RemoteAddr = test.remote
+		for _, value := range test.forwarded {
+			r.Header.Add("X-Forwarded-For", value)
+		}
+		if test.realIP != "" {
+			r.Header.Set("X-Real-Ip", test.realIP)
+		}
+		if have := proxies.clientAddr(r); have != test.want {
+			t.Errorf("test %d: wrong client address: have %s, want %s", i, have, test.want)
+		}
+	}
+}
+
+func TestProxyProtocolListener(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		trusted    string
+		header     string
+		wantRemote string
+	}{
+		{"127.0.0.1", "PROXY TCP4 5.6.7.8 127.0.0.1 4444 8545\r\n", "5.6.7.8:4444"},
+		{"127.0.0.1", "PROXY TCP6 2001:db8::1 ::1 4444 8545\r\n", "[2001:db8::1]:4444"},
+		{"127.0.0.1", "PROXY UNKNOWN\r\n", "127.0.0.1"},
+		{"127.0.0.1", "\r\n\r\n\x00\r\nQUIT\n\x21\x11\x00\x0c\x05\x06\x07\x08\x7f\x00\x00\x01\x11\x5c\x21\x61", "5.6.7.8:4444"},
+		// Connections without header are passed through.
+		{"127.0.0.1", "", "127.0.0.1"},
+		// Headers of untrusted peers are not interpreted.
+		{"10.0.0.1", "PROXY TCP4 5.6.7.8 127.0.0.1 4444 8545\r\n", "127.0.0.1"},
+	}
+	for i, test := range tests {
+		proxies, _ := ParseTrustedProxies([]string{test.trusted})
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		listener := NewProxyProtocolListener(l, proxies)
+
+		client, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload := test.header + "POST / HTTP/1.1\r\n"
+		go func() {
+			client.Write([]byte(payload))
+			client.Close()
+		}()
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Fatal(err)
+		}
+		remote := conn.RemoteAddr().String()
+		if !strings.HasPrefix(remote, test.wantRemote) {
+			t.Errorf("test %d: wrong remote address: have %s, want %s", i, remote, test.wantRemote)
+		}
+		data, err := io.ReadAll(conn)
+		if err != nil {
+			t.Errorf("test %d: read error: %v", i, err)
+		}
+		want := "POST / HTTP/1.1\r\n"
+		if !proxies.containsAddr(client.LocalAddr().String()) {
+			want = payload
+		}
+		if string(data) != want {
+			t.Errorf("test %d: wrong payload: have %q, want %q", i, data, want)
+		}
+		conn.Close()
+		listener.Close()
+	}
+}
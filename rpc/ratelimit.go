@@ -0,0 +1,176 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"net"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/lru"
+	"golang.org/x/time/rate"
+)
+
+// rateLimitTrackedClients is the maximum number of clients whose limiter state
+// is retained. The least recently seen clients are forgotten beyond this.
+const rateLimitTrackedClients = 4096
+
+// RateLimit configures the limits applied to each client of a server. Clients are
+// identified by their API key if it is a known one, listed in Keys or in the access
+// rules of the server, or their IP address otherwise. Zero values disable the
+// respective limit.
+type RateLimit struct {
+	RequestsPerSecond float64  `toml:",omitempty"` // Sustained number of calls per second
+	Burst             int      `toml:",omitempty"` // Maximum number of calls permitted at once
+	MaxConcurrent     int      `toml:",omitempty"` // Maximum number of calls in flight
+	Keys              []string `toml:",omitempty"` // API keys accounted separately from their IP
+
+	// Methods defines additional, usually stricter, quotas for individual
+	// expensive methods such as eth_getLogs.
+	Methods map[string]MethodRateLimit `toml:",omitempty"`
+}
+
+// MethodRateLimit is the quota of a single method, applied on top of the general
+// request limits.
+type MethodRateLimit struct {
+	RequestsPerSecond float64 `toml:",omitempty"`
+	Burst             int     `toml:",omitempty"`
+}
+
+// rateLimiter tracks the request quotas of the clients of a server.
+type rateLimiter struct {
+	cfg     RateLimit
+	access  *atomic.Pointer[AccessControl] // access rules whose keys are known, may be nil
+	mu      sync.Mutex
+	clients lru.BasicLRU[string, *clientLimiter]
+}
+
+// clientLimiter is the limiter state of a single client.
+type clientLimiter struct {
+	requests *rate.Limiter
+	methods  map[string]*rate.Limiter
+	inflight int
+}
+
+func newRateLimiter(cfg RateLimit, access *atomic.Pointer[AccessControl]) *rateLimiter {
+	return &rateLimiter{
+		cfg:     cfg,
+		access:  access,
+		clients: lru.NewBasicLRU[string, *clientLimiter](rateLimitTrackedClients),
+	}
+}
+
+// newLimiter creates a token bucket for the given rate. A zero rate yields
+// a nil limiter which permits everything.
+func newLimiter(perSecond float64, burst int) *rate.Limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = max(1, int(perSecond))
+	}
+	return rate.NewLimiter(rate.Limit(perSecond), burst)
+}
+
+// acquire checks whether the client identified by info may call method. If so,
+// the returned function must be called once the call has finished.
+func (l *rateLimiter) acquire(info PeerInfo, method string) (func(), error) {
+	key := l.clientKey(info)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	client, ok := l.clients.Get(key)
+	if !ok {
+		client = &clientLimiter{
+			requests: newLimiter(l.cfg.RequestsPerSecond, l.cfg.Burst),
+			methods:  make(map[string]*rate.Limiter),
+		}
+		for name, quota := range l.cfg.Methods {
+			client.methods[name] = newLimiter(quota.RequestsPerSecond, quota.Burst)
+		}
+		l.clients.Add(key, client)
+	}
+	if l.cfg.MaxConcurrent > 0 && client.inflight >= l.cfg.MaxConcurrent {
+		return nil, &rateLimitedError{"too many concurrent requests"}
+	}
+	// Check the method quota first, handing its token back if the general quota
+	// rejects the call, so that rejected calls don't consume either budget.
+	var (
+		now      = time.Now()
+		reserved *rate.Reservation
+	)
+	if limiter := client.methods[method]; limiter != nil {
+		if reserved = limiter.ReserveN(now, 1); reserved.DelayFrom(now) > 0 {
+			reserved.CancelAt(now)
+			return nil, &rateLimitedError{"rate limit exceeded for method " + method}
+		}
+	}
+	if client.requests != nil && !client.requests.AllowN(now, 1) {
+		if reserved != nil {
+			reserved.CancelAt(now)
+		}
+		return nil, &rateLimitedError{"request rate limit exceeded"}
+	}
+	client.inflight++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			client.inflight--
+			l.mu.Unlock()
+		})
+	}, nil
+}
+
+// clientKey returns the identity used to account the requests of a client. Unknown
+// API keys are ignored, so that clients can't evade the limit of their IP address
+// by presenting a new key with every request.
+func (l *rateLimiter) clientKey(info PeerInfo) string {
+	if info.APIKey != "" && l.knownKey(info.APIKey) {
+		return "key:" + info.APIKey
+	}
+	if host, _, err := net.SplitHostPort(info.RemoteAddr); err == nil {
+		return "ip:" + host
+	}
+	return "ip:" + info.RemoteAddr
+}
+
+// knownKey reports whether the API key is configured on the server.
+func (l *rateLimiter) knownKey(key string) bool {
+	if slices.Contains(l.cfg.Keys, key) {
+		return true
+	}
+	if l.access != nil {
+		if ac := l.access.Load(); ac != nil {
+			_, ok := ac.Keys[key]
+			return ok
+		}
+	}
+	return false
+}
+
+// rateLimitedError is returned when a client exceeds its request quota. It is the
+// JSON-RPC analogue of the HTTP 429 status.
+type rateLimitedError struct{ message string }
+
+func (e *rateLimitedError) ErrorCode() int { return errcodeLimitExceeded }
+
+func (e *rateLimitedError) Error() string { return e.message }
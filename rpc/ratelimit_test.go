@@ -0,0 +1,146 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRateLimiterQuotas(t *testing.T) {
+	t.Parallel()
+
+	limiter := newRateLimiter(RateLimit{
+		RequestsPerSecond: 0.001,
+		Burst:             3,
+		MaxConcurrent:     2,
+		Methods: map[string]MethodRateLimit{
+			"eth_getLogs": {RequestsPerSecond: 0.001, Burst: 1},
+		},
+		Keys: []string{"key"},
+	}, nil)
+	alice := PeerInfo{RemoteAddr: "10.0.0.1:1234"}
+	bob := PeerInfo{RemoteAddr: "10.0.0.2:1234"}
+
+	// Concurrency limit
+	release1, err := limiter.acquire(alice, "eth_call")
+	if err != nil {
+		t.Fatalf("call 1 rejected: %v", err)
+	}
+	release2, err := limiter.acquire(alice, "eth_call")
+	if err != nil {
+		t.Fatalf("call 2 rejected: %v", err)
+	}
+	if _, err := limiter.acquire(alice, "eth_call"); err == nil {
+		t.Fatal("call exceeding concurrency limit accepted")
+	}
+	release1()
+	release1() // releasing twice must not free another slot
+	release2()
+
+	// Request rate limit (burst of 3, two already spent, one failed attempt
+	// rejected before consuming tokens)
+	release, err := limiter.acquire(alice, "eth_call")
+	if err != nil {
+		t.Fatalf("call within burst rejected: %v", err)
+	}
+	release()
+	if _, err := limiter.acquire(alice, "eth_call"); err == nil {
+		t.Fatal("call exceeding rate limit accepted")
+	}
+	// Other clients have their own quota, with method specific limits applied
+	release, err = limiter.acquire(bob, "eth_getLogs")
+	if err != nil {
+		t.Fatalf("getLogs call rejected: %v", err)
+	}
+	release()
+	if _, err := limiter.acquire(bob, "eth_getLogs"); err == nil {
+		t.Fatal("call exceeding method limit accepted")
+	}
+	// Calls rejected by the method limit don't consume the request quota
+	for i := 0; i < 2; i++ {
+		release, err := limiter.acquire(bob, "eth_call")
+		if err != nil {
+			t.Fatalf("call %d after method rejection rejected: %v", i, err)
+		}
+		release()
+	}
+	if _, err := limiter.acquire(bob, "eth_call"); err == nil {
+		t.Fatal("call exceeding rate limit accepted")
+	}
+	// The same IP with a different port is the same client, known API keys are
+	// separate and unknown ones are ignored
+	if _, err := limiter.acquire(PeerInfo{RemoteAddr: "10.0.0.1:4321"}, "eth_call"); err == nil {
+		t.Fatal("client not identified by IP address")
+	}
+	if _, err := limiter.acquire(PeerInfo{RemoteAddr: "10.0.0.1:4321", APIKey: "unknown"}, "eth_call"); err == nil {
+		t.Fatal("unknown API key evaded the IP address limit")
+	}
+	if _, err := limiter.acquire(PeerInfo{RemoteAddr: "10.0.0.1:4321", APIKey: "key"}, "eth_call"); err != nil {
+		t.Fatalf("API key client rejected: %v", err)
+	}
+}
+
+func TestRateLimiterAccessKeys(t *testing.T) {
+	t.Parallel()
+
+	var access atomic.Pointer[AccessControl]
+	limiter := newRateLimiter(RateLimit{RequestsPerSecond: 0.001, Burst: 1}, &access)
+	alice := PeerInfo{RemoteAddr: "10.0.0.1:1234"}
+	if _, err := limiter.acquire(alice, "eth_call"); err != nil {
+		t.Fatalf("call rejected: %v", err)
+	}
+	alice.APIKey = "key"
+	if _, err := limiter.acquire(alice, "eth_call"); err == nil {
+		t.Fatal("unknown API key evaded the IP address limit")
+	}
+	// Keys of the access rules are known.
+	access.Store(&AccessControl{Keys: map[string]AccessRule{"key": {}}})
+	if _, err := limiter.acquire(alice, "eth_call"); err != nil {
+		t.Fatalf("API key client rejected: %v", err)
+	}
+}
+
+func TestServerRateLimit(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	server.SetRateLimit(RateLimit{RequestsPerSecond: 0.001, Burst: 2})
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client, err := Dial(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var res echoResult
+	for i := 0; i < 2; i++ {
+		if err := client.Call(&res, "test_echo", "x", 1); err != nil {
+			t.Fatalf("call %d failed: %v", i, err)
+		}
+	}
+	err = client.Call(&res, "test_echo", "x", 1)
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeLimitExceeded {
+		t.Fatalf("expected limit exceeded error, got %v", err)
+	}
+}
@@ -19,8 +19,10 @@ package rpc
 import (
 	"context"
 	"io"
+	"maps"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 )
@@ -51,7 +53,19 @@ type Server struct {
 	run                atomic.Bool
 	batchItemLimit     int
 	batchResponseLimit int
+	batchTimeout       time.Duration
 	httpBodyLimit      int
+	accessControl      atomic.Pointer[AccessControl]
+	rateLimiter        *rateLimiter
+	responseCache      *responseCache
+	methodTimeouts     map[string]time.Duration
+	subPolicy          BackpressurePolicy
+	subBuffer          int
+	wsCompression      *wsCompressionConfig
+	wsTimeouts         WebsocketTimeouts
+	wsLimits           WebsocketLimits
+	auditLog           *AuditLog
+	trustedProxies     TrustedProxies
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -80,6 +94,56 @@ func (s *Server) SetBatchLimits(itemLimit, maxResponseSize int) {
 	s.batchResponseLimit = maxResponseSize
 }
 
+// SetBatchTimeout sets the maximum time allowed for executing all requests in a
+// batch. Calls which did not finish in time are answered with a timeout error,
+// while the results of completed calls are still returned. A zero timeout only
+// applies the timeouts of the underlying transport.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetBatchTimeout(timeout time.Duration) {
+	s.batchTimeout = timeout
+}
+
+// SetMethodTimeouts sets execution deadlines for individual methods. The context
+// passed to a method is canceled once its deadline expires, and calls which fail
+// due to the deadline are answered with a timeout error.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetMethodTimeouts(timeouts map[string]time.Duration) {
+	s.methodTimeouts = maps.Clone(timeouts)
+}
+
+// SetSubscriptionBackpressure sets the policy applied to subscribers which read
+// notifications slower than they are produced. The buffer size is the number of
+// notifications queued per subscription, zero selects DefaultSubscriptionBuffer.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetSubscriptionBackpressure(policy BackpressurePolicy, buffer int) {
+	s.subPolicy = policy
+	s.subBuffer = buffer
+}
+
+// SetAuditLog configures the log recording the calls served by the server.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetAuditLog(l *AuditLog) {
+	s.auditLog = l
+}
+
+// SetTrustedProxies configures the proxies whose X-Forwarded-For headers identify
+// the clients of HTTP and WebSocket requests, which is reflected in the PeerInfo
+// used for access control, rate limiting and audit logging.
+//
+// This method should be called before processing any requests via ServeHTTP or
+// the handler returned by WebsocketHandler.
+func (s *Server) SetTrustedProxies(proxies TrustedProxies) {
+	s.trustedProxies = proxies
+}
+
 // SetHTTPBodyLimit sets the size limit for HTTP requests.
 //
 // This method should be called before processing any requests via ServeHTTP.
@@ -87,6 +151,36 @@ func (s *Server) SetHTTPBodyLimit(limit int) {
 	s.httpBodyLimit = limit
 }
 
+// SetAccessControl restricts the methods clients may invoke on this server. A nil
+// value permits all methods. The rules may be replaced while the server is running
+// and apply to all subsequent calls, including those on established connections.
+func (s *Server) SetAccessControl(ac *AccessControl) {
+	s.accessControl.Store(ac)
+}
+
+// SetRateLimit configures per-client request quotas. Calls exceeding them are
+// rejected with a 'limit exceeded' error.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetRateLimit(cfg RateLimit) {
+	s.rateLimiter = newRateLimiter(cfg, &s.accessControl)
+}
+
+// SetResponseCache enables caching the results of calls which the given policy
+// reports as immutable, retaining up to size bytes of results. Cached results are
+// shared between all clients of the server.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetResponseCache(size uint64, policy CachePolicy) {
+	if size == 0 || policy == nil {
+		s.responseCache = nil
+		return
+	}
+	s.responseCache = newResponseCache(size, policy)
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either an RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
@@ -95,12 +189,25 @@ func (s *Server) RegisterName(name string, receiver interface{}) error {
 	return s.services.registerName(name, receiver)
 }
 
+// UnregisterName removes the service registered under the given name, reporting
+// whether it existed. Subsequent calls to its methods fail with a 'method not
+// found' error, while subscriptions which were already established stay active.
+func (s *Server) UnregisterName(name string) bool {
+	return s.services.unregisterName(name)
+}
+
 // ServeCodec reads incoming requests from codec, calls the appropriate callback and writes
 // the response back using the given codec. It will block until the codec is closed or the
 // server is stopped. In either case the codec is closed.
 //
 // Note that codec options are no longer supported.
 func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
+	s.serveCodec(codec, nil)
+}
+
+// serveCodec serves the codec, applying the given per-connection limits if
+// they're non-nil.
+func (s *Server) serveCodec(codec ServerCodec, limits *WebsocketLimits) {
 	defer codec.close()
 
 	if !s.trackCodec(codec) {
@@ -112,6 +219,19 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 		idgen:              s.idgen,
 		batchItemLimit:     s.batchItemLimit,
 		batchResponseLimit: s.batchResponseLimit,
+		batchTimeout:       s.batchTimeout,
+		accessControl:      &s.accessControl,
+		rateLimiter:        s.rateLimiter,
+		responseCache:      s.responseCache,
+		methodTimeouts:     s.methodTimeouts,
+		subPolicy:          s.subPolicy,
+		subBuffer:          s.subBuffer,
+		auditLog:           s.auditLog,
+	}
+	if limits != nil {
+		cfg.maxSubscriptions = limits.MaxSubscriptions
+		cfg.msgRate = limits.MessagesPerSecond
+		cfg.msgBurst = limits.MessageBurst
 	}
 	c := initClient(codec, &s.services, cfg)
 	<-codec.closed()
@@ -147,6 +267,12 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 
 	h := newHandler(ctx, codec, s.idgen, &s.services, s.batchItemLimit, s.batchResponseLimit)
 	h.allowSubscribe = false
+	h.batchTimeout = s.batchTimeout
+	h.accessControl = &s.accessControl
+	h.rateLimiter = s.rateLimiter
+	h.responseCache = s.responseCache
+	h.methodTimeouts = s.methodTimeouts
+	h.auditLog = s.auditLog
 	defer h.close(io.EOF, nil)
 
 	reqs, batch, err := codec.readBatch()
@@ -210,6 +336,15 @@ type PeerInfo struct {
 	// Address of client. This will usually contain the IP address and port.
 	RemoteAddr string
 
+	// APIKey is the key presented by the client in the APIKeyHeader of HTTP and
+	// WebSocket connections. It is empty if the client didn't present one.
+	APIKey string
+
+	// Scopes are the access patterns granted to the client by its authentication
+	// token, restricting the methods it may call (see AccessRule for the pattern
+	// syntax). It is nil if the client is not restricted by a token.
+	Scopes []string
+
 	// Additional information for HTTP and WebSocket connections.
 	HTTP struct {
 		// Protocol version, i.e. "HTTP/1.1". This is not set for WebSocket.
@@ -223,6 +358,21 @@ type PeerInfo struct {
 
 type peerInfoContextKey struct{}
 
+type scopesContextKey struct{}
+
+// NewContextWithScopes returns a copy of ctx carrying the scopes granted to a
+// client by its authentication token. HTTP and WebSocket servers expose the
+// scopes attached to the context of a request in the PeerInfo of its calls.
+func NewContextWithScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, scopesContextKey{}, scopes)
+}
+
+// scopesFromContext returns the scopes attached to ctx by NewContextWithScopes.
+func scopesFromContext(ctx context.Context) []string {
+	scopes, _ := ctx.Value(scopesContextKey{}).([]string)
+	return scopes
+}
+
 // PeerInfoFromContext returns information about the client's network connection.
 // Use this with the context passed to RPC method handler functions.
 //
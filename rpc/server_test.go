@@ -26,6 +26,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
 )
 
 func TestServerRegisterName(t *testing.T) {
@@ -52,6 +54,35 @@ func TestServerRegisterName(t *testing.T) {
 	}
 }
 
+func TestServerUnregisterName(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatalf("call failed before unregistering: %v", err)
+	}
+	if !server.UnregisterName("test") {
+		t.Fatal("registered service not found")
+	}
+	if server.UnregisterName("test") {
+		t.Fatal("service unregistered twice")
+	}
+	err := client.Call(&result, "test_echo", "x", 1)
+	if re, ok := err.(Error); !ok || re.ErrorCode() != (&methodNotFoundError{}).ErrorCode() {
+		t.Fatalf("wrong error after unregistering: %v", err)
+	}
+	// The service can be registered again on the running server.
+	if err := server.RegisterName("test", new(testService)); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatalf("call failed after registering again: %v", err)
+	}
+}
+
 func TestServer(t *testing.T) {
 	files, err := os.ReadDir("testdata")
 	if err != nil {
@@ -192,3 +223,100 @@ func TestServerBatchResponseSizeLimit(t *testing.T) {
 		}
 	}
 }
+
+func TestServerMethodMetrics(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	defer func() { metrics.Enabled = enabled }()
+
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	var res echoResult
+	if err := client.Call(&res, "test_echo", "x", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(nil, "test_returnError"); err == nil {
+		t.Fatal("expected error")
+	}
+	if m := metrics.DefaultRegistry.Get("rpc/calls/test_echo/all"); m == nil || m.(metrics.Meter).Snapshot().Count() == 0 {
+		t.Error("call meter not updated")
+	}
+	if m := metrics.DefaultRegistry.Get("rpc/calls/test_returnError/failure"); m == nil || m.(metrics.Meter).Snapshot().Count() == 0 {
+		t.Error("failure meter not updated")
+	}
+	if m := metrics.DefaultRegistry.Get("rpc/calls/test_echo/failure"); m != nil {
+		t.Error("failure meter registered for successful call")
+	}
+	for _, name := range []string{"rpc/size/test_echo/request", "rpc/size/test_echo/response"} {
+		if metrics.DefaultRegistry.Get(name) == nil {
+			t.Errorf("payload size histogram %s not registered", name)
+		}
+	}
+}
+
+func TestServerMethodTimeouts(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.SetMethodTimeouts(map[string]time.Duration{"test_block": 50 * time.Millisecond})
+	client := DialInProc(server)
+	defer client.Close()
+
+	// The deadline of the method cancels its context.
+	start := time.Now()
+	err := client.Call(nil, "test_block")
+	re, ok := err.(Error)
+	if !ok {
+		t.Fatalf("wrong error: %v", err)
+	}
+	if re.ErrorCode() != errcodeTimeout {
+		t.Errorf("wrong error code, have %d want %d", re.ErrorCode(), errcodeTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("call took too long: %v", elapsed)
+	}
+	// Other methods are not affected by the deadline.
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.Call(nil, "test_sleep", 100*time.Millisecond); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestServerBatchTimeout(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.SetBatchTimeout(100 * time.Millisecond)
+	client := DialInProc(server)
+	defer client.Close()
+
+	batch := []BatchElem{
+		{Method: "test_echo", Args: []any{"x", 1}, Result: new(echoResult)},
+		{Method: "test_sleep", Args: []any{time.Second}},
+		{Method: "test_echo", Args: []any{"y", 2}, Result: new(echoResult)},
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal("error sending batch:", err)
+	}
+	// The first call completed before the timeout and must be delivered.
+	if batch[0].Error != nil {
+		t.Fatalf("batch elem 0 has unexpected error: %v", batch[0].Error)
+	}
+	if res := batch[0].Result.(*echoResult); res.String != "x" {
+		t.Fatalf("batch elem 0 has wrong result: %v", res)
+	}
+	// The remaining calls must be answered with a timeout error.
+	for i := 1; i < len(batch); i++ {
+		re, ok := batch[i].Error.(Error)
+		if !ok {
+			t.Fatalf("batch elem %d has wrong error: %v", i, batch[i].Error)
+		}
+		if re.ErrorCode() != errcodeTimeout {
+			t.Errorf("batch elem %d wrong error code, have %d want %d", i, re.ErrorCode(), errcodeTimeout)
+		}
+	}
+}
@@ -91,6 +91,17 @@ func (r *serviceRegistry) registerName(name string, rcvr interface{}) error {
 	return nil
 }
 
+// unregisterName removes the service with the given name, reporting whether it
+// was registered.
+func (r *serviceRegistry) unregisterName(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.services[name]
+	delete(r.services, name)
+	return ok
+}
+
 // callback returns the callback corresponding to the given RPC method name.
 func (r *serviceRegistry) callback(method string) *callback {
 	before, after, found := strings.Cut(method, serviceMethodSeparator)
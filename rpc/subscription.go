@@ -28,6 +28,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -108,6 +109,7 @@ type Notifier struct {
 	buffer       []any
 	callReturned bool
 	activated    bool
+	queue        subscriptionQueue // outgoing notifications, unless BackpressureNone
 }
 
 // CreateSubscription returns a new subscription that is coupled to the
@@ -139,12 +141,21 @@ func (n *Notifier) Notify(id ID, data any) error {
 		panic("Notify with wrong ID")
 	}
 	if n.activated {
-		return n.send(n.sub, data)
+		return n.dispatch(data)
 	}
 	n.buffer = append(n.buffer, data)
 	return nil
 }
 
+// dispatch hands a notification to the connection, either writing it directly or
+// queueing it according to the backpressure policy of the connection.
+func (n *Notifier) dispatch(data any) error {
+	if n.h.subPolicy == BackpressureNone {
+		return n.send(n.sub, data, 0)
+	}
+	return n.enqueue(data)
+}
+
 // takeSubscription returns the subscription (if one has been created). No subscription can
 // be created after this call.
 func (n *Notifier) takeSubscription() *Subscription {
@@ -162,7 +173,7 @@ func (n *Notifier) activate() error {
 	defer n.mu.Unlock()
 
 	for _, data := range n.buffer {
-		if err := n.send(n.sub, data); err != nil {
+		if err := n.dispatch(data); err != nil {
 			return err
 		}
 	}
@@ -170,13 +181,14 @@ func (n *Notifier) activate() error {
 	return nil
 }
 
-func (n *Notifier) send(sub *Subscription, data any) error {
+func (n *Notifier) send(sub *Subscription, data any, dropped uint64) error {
 	msg := jsonrpcSubscriptionNotification{
 		Version: vsn,
 		Method:  n.namespace + notificationMethodSuffix,
 		Params: subscriptionResultEnc{
-			ID:     string(sub.ID),
-			Result: data,
+			ID:      string(sub.ID),
+			Result:  data,
+			Dropped: dropped,
 		},
 	}
 	return n.h.conn.writeJSON(context.Background(), &msg, false)
@@ -223,6 +235,10 @@ type ClientSubscription struct {
 	quit        chan error
 	forwardDone chan struct{}
 	unsubDone   chan struct{}
+
+	// dropped is the number of notifications the server discarded because they
+	// were not consumed in time, as reported by the latest notification.
+	dropped atomic.Uint64
 }
 
 // This is the sentinel value sent on sub.quit when Unsubscribe is called.
@@ -268,8 +284,18 @@ func (sub *ClientSubscription) Unsubscribe() {
 	})
 }
 
+// Dropped returns the number of notifications the server has discarded because
+// they were not consumed in time. A change of the value between two received
+// notifications indicates a gap.
+func (sub *ClientSubscription) Dropped() uint64 {
+	return sub.dropped.Load()
+}
+
 // deliver is called by the client's message dispatcher to send a notification value.
-func (sub *ClientSubscription) deliver(result json.RawMessage) (ok bool) {
+func (sub *ClientSubscription) deliver(result json.RawMessage, dropped uint64) (ok bool) {
+	if dropped > sub.dropped.Load() {
+		sub.dropped.Store(dropped)
+	}
 	select {
 	case sub.in <- result:
 		return true
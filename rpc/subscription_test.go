@@ -259,6 +259,88 @@ func BenchmarkNotify(b *testing.B) {
 	}
 }
 
+// This test checks that notifications of slow subscribers are discarded under the
+// drop-oldest policy, and that the gap is reported to the client.
+func TestSubscriptionDropOldest(t *testing.T) {
+	const notificationCount = 100
+	in, done := startSlowSubscriber(t, BackpressureDropOldest, notificationCount)
+	defer done()
+
+	var received []subscriptionResult
+	for len(received) == 0 || string(received[len(received)-1].Result) != fmt.Sprint(notificationCount-1) {
+		_, notification, err := readAndValidateMessage(in)
+		if err != nil {
+			t.Fatalf("failed to read notification: %v", err)
+		}
+		received = append(received, *notification)
+	}
+	dropped := received[len(received)-1].Dropped
+	if dropped == 0 {
+		t.Fatal("no notifications dropped")
+	}
+	if uint64(len(received))+dropped != notificationCount {
+		t.Fatalf("received %d notifications with %d dropped, want %d in total", len(received), dropped, notificationCount)
+	}
+	for i := 1; i < len(received); i++ {
+		if received[i].Dropped < received[i-1].Dropped {
+			t.Fatalf("drop counter decreased from %d to %d", received[i-1].Dropped, received[i].Dropped)
+		}
+	}
+}
+
+// This test checks that the connection of slow subscribers is closed under the
+// disconnect policy.
+func TestSubscriptionDisconnect(t *testing.T) {
+	const notificationCount = 100
+	in, done := startSlowSubscriber(t, BackpressureDisconnect, notificationCount)
+	defer done()
+
+	for i := 0; ; i++ {
+		if _, _, err := readAndValidateMessage(in); err != nil {
+			break
+		}
+		if i >= notificationCount {
+			t.Fatal("received all notifications, want connection closed")
+		}
+	}
+}
+
+// startSlowSubscriber subscribes to n notifications on a server applying the given
+// backpressure policy with a small buffer, and waits for the notifications to pile
+// up before returning the decoder of the connection.
+func startSlowSubscriber(t *testing.T, policy BackpressurePolicy, n int) (*json.Decoder, func()) {
+	var (
+		server                 = NewServer()
+		clientConn, serverConn = net.Pipe()
+		out                    = json.NewEncoder(clientConn)
+		in                     = json.NewDecoder(clientConn)
+	)
+	server.SetSubscriptionBackpressure(policy, 2)
+	if err := server.RegisterName("eth", &notificationTestService{}); err != nil {
+		t.Fatalf("unable to register test service %v", err)
+	}
+	go server.ServeCodec(NewCodec(serverConn), 0)
+
+	request := map[string]interface{}{
+		"id":      1,
+		"method":  "eth_subscribe",
+		"jsonrpc": "2.0",
+		"params":  []interface{}{"someSubscription", n, 0},
+	}
+	if err := out.Encode(&request); err != nil {
+		t.Fatalf("could not create subscription: %v", err)
+	}
+	if resp, _, err := readAndValidateMessage(in); err != nil || resp == nil {
+		t.Fatalf("failed to read subscription response: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	return in, func() {
+		clientConn.Close()
+		server.Stop()
+	}
+}
+
 func TestNotify(t *testing.T) {
 	out := new(bytes.Buffer)
 	id := ID("test")
@@ -17,17 +17,21 @@
 package rpc
 
 import (
+	"compress/flate"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/gorilla/websocket"
 )
@@ -35,12 +39,64 @@ import (
 const (
 	wsReadBuffer       = 1024
 	wsWriteBuffer      = 1024
-	wsPingInterval     = 30 * time.Second
 	wsPingWriteTimeout = 5 * time.Second
-	wsPongTimeout      = 30 * time.Second
 	wsDefaultReadLimit = 32 * 1024 * 1024
+
+	// DefaultWebsocketCompressionThreshold is the size in bytes below which
+	// messages are sent uncompressed, as deflating them isn't worth the effort.
+	DefaultWebsocketCompressionThreshold = 1024
+
+	// DefaultWebsocketPingInterval is the time after which a ping is sent on a
+	// websocket connection without outgoing messages.
+	DefaultWebsocketPingInterval = 30 * time.Second
+
+	// DefaultWebsocketPongTimeout is the time to wait for the pong answering a
+	// ping before the connection is considered dead.
+	DefaultWebsocketPongTimeout = 30 * time.Second
+
+	// DefaultWebsocketWriteTimeout is the deadline of writing a message to a
+	// websocket connection, unless the message is bound to a request deadline.
+	DefaultWebsocketWriteTimeout = defaultWriteTimeout
 )
 
+// WebsocketTimeouts configures the keepalive and timeout behavior of websocket
+// connections. Zero durations select the defaults, except for IdleTimeout, where
+// zero disables the reaping of idle connections.
+type WebsocketTimeouts struct {
+	PingInterval time.Duration // time without outgoing messages after which a ping is sent
+	PongTimeout  time.Duration // time to wait for the pong answering a ping
+	WriteTimeout time.Duration // deadline of writing a message
+	IdleTimeout  time.Duration // time without messages after which the connection is closed
+}
+
+// withDefaults returns a copy of the timeouts with zero values replaced by the
+// defaults.
+func (t WebsocketTimeouts) withDefaults() WebsocketTimeouts {
+	if t.PingInterval <= 0 {
+		t.PingInterval = DefaultWebsocketPingInterval
+	}
+	if t.PongTimeout <= 0 {
+		t.PongTimeout = DefaultWebsocketPongTimeout
+	}
+	if t.WriteTimeout <= 0 {
+		t.WriteTimeout = DefaultWebsocketWriteTimeout
+	}
+	if t.IdleTimeout < 0 {
+		t.IdleTimeout = 0
+	}
+	return t
+}
+
+// WebsocketLimits bounds the resources websocket clients may use, so a single
+// misbehaving client can't exhaust the server. Zero values disable the respective
+// limit.
+type WebsocketLimits struct {
+	MaxConnections    int     // concurrent connections served by a handler
+	MaxSubscriptions  int     // active subscriptions per connection
+	MessagesPerSecond float64 // sustained rate of incoming messages per connection
+	MessageBurst      int     // incoming messages permitted at once per connection
+}
+
 var wsBufferPool = new(sync.Pool)
 
 // WebsocketHandler returns a handler that serves JSON-RPC to WebSocket connections.
@@ -48,23 +104,85 @@ var wsBufferPool = new(sync.Pool)
 // allowedOrigins should be a comma-separated list of allowed origin URLs.
 // To allow connections with any origin, pass "*".
 func (s *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
-	var upgrader = websocket.Upgrader{
-		ReadBufferSize:  wsReadBuffer,
-		WriteBufferSize: wsWriteBuffer,
-		WriteBufferPool: wsBufferPool,
-		CheckOrigin:     wsHandshakeValidator(allowedOrigins),
-	}
+	var (
+		compression = s.wsCompression
+		timeouts    = s.wsTimeouts.withDefaults()
+		proxies     = s.trustedProxies
+		limits      = s.wsLimits
+		conns       atomic.Int64
+		upgrader    = websocket.Upgrader{
+			ReadBufferSize:    wsReadBuffer,
+			WriteBufferSize:   wsWriteBuffer,
+			WriteBufferPool:   wsBufferPool,
+			CheckOrigin:       wsHandshakeValidator(allowedOrigins),
+			EnableCompression: compression != nil,
+		}
+	)
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limits.MaxConnections > 0 {
+			if conns.Add(1) > int64(limits.MaxConnections) {
+				conns.Add(-1)
+				log.Debug("Rejected WebSocket connection", "addr", r.RemoteAddr, "reason", "too many connections")
+				http.Error(w, "too many websocket connections", http.StatusServiceUnavailable)
+				return
+			}
+			defer conns.Add(-1)
+		}
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			log.Debug("WebSocket upgrade failed", "err", err)
 			return
 		}
-		codec := newWebsocketCodec(conn, r.Host, r.Header, wsDefaultReadLimit)
-		s.ServeCodec(codec, 0)
+		codec := newWebsocketCodec(conn, r.Host, r.Header, wsDefaultReadLimit, timeouts)
+		codec.info.RemoteAddr = proxies.clientAddr(r)
+		codec.info.Scopes = scopesFromContext(r.Context())
+		if compression != nil {
+			codec.enableCompression(compression.level, compression.threshold)
+		}
+		s.serveCodec(codec, &limits)
 	})
 }
 
+// wsCompressionConfig holds the permessage-deflate settings of websocket handlers.
+type wsCompressionConfig struct {
+	level     int // compress/flate compression level
+	threshold int // minimum message size to compress
+}
+
+// SetWebsocketCompression enables negotiating permessage-deflate compression on
+// websocket connections. Messages smaller than threshold bytes are sent without
+// compression, and level is the compress/flate compression level to use.
+//
+// This method must be called before creating the handler via WebsocketHandler.
+func (s *Server) SetWebsocketCompression(level, threshold int) error {
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		return fmt.Errorf("invalid websocket compression level %d", level)
+	}
+	if threshold < 0 {
+		threshold = 0
+	}
+	s.wsCompression = &wsCompressionConfig{level: level, threshold: threshold}
+	return nil
+}
+
+// SetWebsocketTimeouts configures the keepalive pings, the write deadline and the
+// reaping of idle connections of websocket connections.
+//
+// This method must be called before creating the handler via WebsocketHandler.
+func (s *Server) SetWebsocketTimeouts(timeouts WebsocketTimeouts) {
+	s.wsTimeouts = timeouts
+}
+
+// SetWebsocketLimits configures the limits of concurrent connections, active
+// subscriptions per connection and the message rate per connection. Connections
+// beyond the limit are refused with HTTP status 503, while excess subscriptions
+// and messages are answered with a 'limit exceeded' error.
+//
+// This method must be called before creating the handler via WebsocketHandler.
+func (s *Server) SetWebsocketLimits(limits WebsocketLimits) {
+	s.wsLimits = limits
+}
+
 // wsHandshakeValidator returns a handler that verifies the origin during the
 // websocket upgrade process. When a '*' is specified as an allowed origins all
 // connections are accepted.
@@ -259,7 +377,7 @@ func newClientTransportWS(endpoint string, cfg *clientConfig) (reconnectFunc, er
 		if cfg.wsMessageSizeLimit != nil && *cfg.wsMessageSizeLimit >= 0 {
 			messageSizeLimit = *cfg.wsMessageSizeLimit
 		}
-		return newWebsocketCodec(conn, dialURL, header, messageSizeLimit), nil
+		return newWebsocketCodec(conn, dialURL, header, messageSizeLimit, WebsocketTimeouts{}.withDefaults()), nil
 	}
 	return connect, nil
 }
@@ -283,15 +401,17 @@ func wsClientHeaders(endpoint, origin string) (string, http.Header, error) {
 
 type websocketCodec struct {
 	*jsonCodec
-	conn *websocket.Conn
-	info PeerInfo
+	conn     *websocket.Conn
+	info     PeerInfo
+	timeouts WebsocketTimeouts
 
 	wg           sync.WaitGroup
 	pingReset    chan struct{}
 	pongReceived chan struct{}
+	lastActive   atomic.Int64 // time of the last message read or written, in unix nanoseconds
 }
 
-func newWebsocketCodec(conn *websocket.Conn, host string, req http.Header, readLimit int64) ServerCodec {
+func newWebsocketCodec(conn *websocket.Conn, host string, req http.Header, readLimit int64, timeouts WebsocketTimeouts) *websocketCodec {
 	conn.SetReadLimit(readLimit)
 	encode := func(v interface{}, isErrorResponse bool) error {
 		return conn.WriteJSON(v)
@@ -299,6 +419,7 @@ func newWebsocketCodec(conn *websocket.Conn, host string, req http.Header, readL
 	wc := &websocketCodec{
 		jsonCodec:    NewFuncCodec(conn, encode, conn.ReadJSON).(*jsonCodec),
 		conn:         conn,
+		timeouts:     timeouts,
 		pingReset:    make(chan struct{}, 1),
 		pongReceived: make(chan struct{}),
 		info: PeerInfo{
@@ -310,6 +431,8 @@ func newWebsocketCodec(conn *websocket.Conn, host string, req http.Header, readL
 	wc.info.HTTP.Host = host
 	wc.info.HTTP.Origin = req.Get("Origin")
 	wc.info.HTTP.UserAgent = req.Get("User-Agent")
+	wc.info.APIKey = req.Get(APIKeyHeader)
+	wc.lastActive.Store(time.Now().UnixNano())
 	// Start pinger.
 	conn.SetPongHandler(func(appData string) error {
 		select {
@@ -323,6 +446,22 @@ func newWebsocketCodec(conn *websocket.Conn, host string, req http.Header, readL
 	return wc
 }
 
+// enableCompression switches the codec to compress outgoing messages of at least
+// threshold bytes, if compression was negotiated with the remote side.
+func (wc *websocketCodec) enableCompression(level, threshold int) {
+	if err := wc.conn.SetCompressionLevel(level); err != nil {
+		log.Debug("Failed to set websocket compression level", "level", level, "err", err)
+	}
+	wc.jsonCodec.encode = func(v interface{}, isErrorResponse bool) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		wc.conn.EnableWriteCompression(len(data) >= threshold)
+		return wc.conn.WriteMessage(websocket.TextMessage, data)
+	}
+}
+
 func (wc *websocketCodec) close() {
 	wc.jsonCodec.close()
 	wc.wg.Wait()
@@ -332,9 +471,23 @@ func (wc *websocketCodec) peerInfo() PeerInfo {
 	return wc.info
 }
 
+func (wc *websocketCodec) readBatch() ([]*jsonrpcMessage, bool, error) {
+	messages, batch, err := wc.jsonCodec.readBatch()
+	if err == nil {
+		wc.lastActive.Store(time.Now().UnixNano())
+	}
+	return messages, batch, err
+}
+
 func (wc *websocketCodec) writeJSON(ctx context.Context, v interface{}, isError bool) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, wc.timeouts.WriteTimeout)
+		defer cancel()
+	}
 	err := wc.jsonCodec.writeJSON(ctx, v, isError)
 	if err == nil {
+		wc.lastActive.Store(time.Now().UnixNano())
 		// Notify pingLoop to delay the next idle ping.
 		select {
 		case wc.pingReset <- struct{}{}:
@@ -344,11 +497,22 @@ func (wc *websocketCodec) writeJSON(ctx context.Context, v interface{}, isError
 	return err
 }
 
-// pingLoop sends periodic ping frames when the connection is idle.
+// pingLoop sends periodic ping frames when the connection is idle. If an idle
+// timeout is configured, it also closes connections on which no messages have
+// been exchanged for that long.
 func (wc *websocketCodec) pingLoop() {
-	var pingTimer = time.NewTimer(wsPingInterval)
+	var (
+		pingTimer = time.NewTimer(wc.timeouts.PingInterval)
+		idleTimer *time.Timer
+		idleC     <-chan time.Time
+	)
 	defer wc.wg.Done()
 	defer pingTimer.Stop()
+	if wc.timeouts.IdleTimeout > 0 {
+		idleTimer = time.NewTimer(wc.timeouts.IdleTimeout)
+		idleC = idleTimer.C
+		defer idleTimer.Stop()
+	}
 
 	for {
 		select {
@@ -359,15 +523,25 @@ func (wc *websocketCodec) pingLoop() {
 			if !pingTimer.Stop() {
 				<-pingTimer.C
 			}
-			pingTimer.Reset(wsPingInterval)
+			pingTimer.Reset(wc.timeouts.PingInterval)
 
 		case <-pingTimer.C:
 			wc.jsonCodec.encMu.Lock()
 			wc.conn.SetWriteDeadline(time.Now().Add(wsPingWriteTimeout))
 			wc.conn.WriteMessage(websocket.PingMessage, nil)
-			wc.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+			wc.conn.SetReadDeadline(time.Now().Add(wc.timeouts.PongTimeout))
 			wc.jsonCodec.encMu.Unlock()
-			pingTimer.Reset(wsPingInterval)
+			pingTimer.Reset(wc.timeouts.PingInterval)
+
+		case <-idleC:
+			idle := time.Since(time.Unix(0, wc.lastActive.Load()))
+			if idle < wc.timeouts.IdleTimeout {
+				idleTimer.Reset(wc.timeouts.IdleTimeout - idle)
+				continue
+			}
+			log.Debug("Closing idle websocket connection", "remote", wc.conn.RemoteAddr(), "idle", common.PrettyDuration(idle))
+			wc.jsonCodec.close()
+			return
 
 		case <-wc.pongReceived:
 			wc.conn.SetReadDeadline(time.Time{})
@@ -22,6 +22,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -113,6 +114,166 @@ func TestWebsocketLargeCall(t *testing.T) {
 	}
 }
 
+// This test checks that permessage-deflate compression is negotiated and
+// that compressed messages are delivered intact.
+func TestWebsocketCompression(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer()
+	defer srv.Stop()
+	if err := srv.SetWebsocketCompression(42, 0); err == nil {
+		t.Fatal("invalid compression level accepted")
+	}
+	if err := srv.SetWebsocketCompression(1, 128); err != nil {
+		t.Fatalf("can't enable compression: %v", err)
+	}
+	var (
+		httpsrv = httptest.NewServer(srv.WebsocketHandler([]string{"*"}))
+		wsURL   = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+	)
+	defer httpsrv.Close()
+
+	// Check that compression is negotiated if the client asks for it.
+	dialer := websocket.Dialer{EnableCompression: true}
+	conn, resp, err := dialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("can't dial: %v", err)
+	}
+	conn.Close()
+	if ext := resp.Header.Get("Sec-Websocket-Extensions"); !strings.Contains(ext, "permessage-deflate") {
+		t.Fatalf("compression not negotiated, extensions %q", ext)
+	}
+
+	// Check that small and large responses arrive intact.
+	client, err := DialOptions(context.Background(), wsURL, WithWebsocketDialer(dialer))
+	if err != nil {
+		t.Fatalf("can't dial: %v", err)
+	}
+	defer client.Close()
+
+	for _, size := range []int{10, 64 * 1024} {
+		var result echoResult
+		arg := strings.Repeat("x", size)
+		if err := client.Call(&result, "test_echo", arg, 1); err != nil {
+			t.Fatalf("call with %d byte payload failed: %v", size, err)
+		}
+		if result.String != arg {
+			t.Fatalf("wrong string echoed for %d byte payload", size)
+		}
+	}
+}
+
+// This test checks that idle websocket connections are closed by the server,
+// while connections exchanging messages are kept open.
+func TestWebsocketIdleTimeout(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer()
+	defer srv.Stop()
+	srv.SetWebsocketTimeouts(WebsocketTimeouts{IdleTimeout: 300 * time.Millisecond})
+
+	var (
+		httpsrv = httptest.NewServer(srv.WebsocketHandler([]string{"*"}))
+		wsURL   = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+	)
+	defer httpsrv.Close()
+
+	client, err := DialWebsocket(context.Background(), wsURL, "")
+	if err != nil {
+		t.Fatalf("can't dial: %v", err)
+	}
+	defer client.Close()
+
+	// Keep the connection busy for longer than the idle timeout.
+	for i := 0; i < 8; i++ {
+		var result echoResult
+		if err := client.Call(&result, "test_echo", "x", i); err != nil {
+			t.Fatalf("call %d failed on active connection: %v", i, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	// Stop sending, the connection should be closed.
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("can't dial: %v", err)
+	}
+	defer conn.Close()
+
+	start := time.Now()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, _, err := conn.ReadMessage(); err == nil {
+		t.Fatal("unexpected message on idle connection")
+	} else if errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatal("idle connection was not closed")
+	}
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Fatalf("idle connection closed too early, after %v", elapsed)
+	}
+}
+
+// This test checks that the connection, subscription and message rate limits of
+// websocket handlers are enforced.
+func TestWebsocketLimits(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer()
+	defer srv.Stop()
+	srv.SetWebsocketLimits(WebsocketLimits{
+		MaxConnections:    1,
+		MaxSubscriptions:  2,
+		MessagesPerSecond: 0.001,
+		MessageBurst:      4,
+	})
+	var (
+		httpsrv = httptest.NewServer(srv.WebsocketHandler([]string{"*"}))
+		wsURL   = "ws:" + strings.TrimPrefix(httpsrv.URL, "http:")
+	)
+	defer httpsrv.Close()
+
+	client, err := DialWebsocket(context.Background(), wsURL, "")
+	if err != nil {
+		t.Fatalf("can't dial: %v", err)
+	}
+	defer client.Close()
+
+	// Connections beyond the limit are refused.
+	if _, resp, err := websocket.DefaultDialer.Dial(wsURL, nil); err == nil {
+		t.Fatal("connection beyond the limit accepted")
+	} else if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("wrong response to connection beyond the limit: %v", err)
+	}
+
+	// Subscriptions beyond the limit are rejected.
+	ch := make(chan int)
+	for i := 0; i < 2; i++ {
+		if _, err := client.Subscribe(context.Background(), "nftest", ch, "someSubscription", 0, i); err != nil {
+			t.Fatalf("subscription %d failed: %v", i, err)
+		}
+	}
+	_, err = client.Subscribe(context.Background(), "nftest", ch, "someSubscription", 0, 2)
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeLimitExceeded {
+		t.Fatalf("wrong error for subscription beyond the limit: %v", err)
+	}
+
+	// Messages beyond the burst are rejected, three were sent so far.
+	var result echoResult
+	if err := client.Call(&result, "test_echo", "x", 1); err != nil {
+		t.Fatalf("call within burst failed: %v", err)
+	}
+	err = client.Call(&result, "test_echo", "x", 2)
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeLimitExceeded {
+		t.Fatalf("wrong error for message beyond the limit: %v", err)
+	}
+	batch := []BatchElem{{Method: "test_echo", Args: []any{"x", 3}, Result: &result}}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	if !errors.As(batch[0].Error, &rpcErr) || rpcErr.ErrorCode() != errcodeLimitExceeded {
+		t.Fatalf("wrong error for batch beyond the limit: %v", batch[0].Error)
+	}
+}
+
 // This test checks whether the wsMessageSizeLimit option is obeyed.
 func TestWebsocketLargeRead(t *testing.T) {
 	t.Parallel()
@@ -24,6 +24,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 func TestBytesPadding(t *testing.T) {
@@ -240,3 +241,75 @@ func TestConvertAddressDataToSlice(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestEncodeNestedArrays(t *testing.T) {
+	t.Parallel()
+	td := TypedData{
+		Types: Types{
+			"EIP712Domain": {{Name: "name", Type: "string"}},
+			"Inner":        {{Name: "value", Type: "uint8"}},
+			"Outer": {
+				{Name: "matrix", Type: "uint8[2][]"},
+				{Name: "inner", Type: "Inner"},
+			},
+		},
+		PrimaryType: "Outer",
+		Domain:      TypedDataDomain{Name: "test"},
+	}
+	word := func(v int64) []byte { return math.U256Bytes(big.NewInt(v)) }
+	concat := func(parts ...[]byte) []byte { return bytes.Join(parts, nil) }
+
+	// Rows are hashed, then the hashes of the rows. Absent structs are zero.
+	row1 := crypto.Keccak256(concat(word(1), word(2)))
+	row2 := crypto.Keccak256(concat(word(3), word(4)))
+	want := concat(td.TypeHash("Outer"), crypto.Keccak256(concat(row1, row2)), make([]byte, 32))
+
+	message := map[string]interface{}{
+		"matrix": []interface{}{[]interface{}{"1", "2"}, []interface{}{"3", "4"}},
+	}
+	have, err := td.EncodeData("Outer", message, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(have, want) {
+		t.Fatalf("wrong encoding: have %x, want %x", have, want)
+	}
+	// Fixed size dimensions are enforced.
+	message["matrix"] = []interface{}{[]interface{}{"1", "2", "3"}}
+	if _, err := td.EncodeData("Outer", message, 1); err == nil {
+		t.Fatal("no error for array of wrong size")
+	}
+}
+
+func TestFormatArrays(t *testing.T) {
+	t.Parallel()
+	td := TypedData{
+		Types: Types{
+			"EIP712Domain": {{Name: "name", Type: "string"}},
+			"Person":       {{Name: "name", Type: "string"}},
+			"Group": {
+				{Name: "members", Type: "Person[]"},
+				{Name: "ids", Type: "uint8[][1]"},
+			},
+		},
+		PrimaryType: "Group",
+		Domain:      TypedDataDomain{Name: "test"},
+		Message: map[string]interface{}{
+			"members": []interface{}{map[string]interface{}{"name": "Alice"}, map[string]interface{}{"name": "Bob"}},
+			"ids":     []interface{}{[]interface{}{"7"}},
+		},
+	}
+	nvts, err := td.Format()
+	if err != nil {
+		t.Fatal(err)
+	}
+	group := nvts[1].Value.([]*NameValueType)
+	members := group[0].Value.([]*NameValueType)
+	if len(members) != 2 || members[1].Name != "[1]" || members[1].Value.([]*NameValueType)[0].Value != "Bob" {
+		t.Errorf("wrong struct array formatting: %s", nvts[1].Pprint(0))
+	}
+	ids := group[1].Value.([]*NameValueType)
+	if len(ids) != 1 || ids[0].Typ != "uint8[]" || ids[0].Value.([]*NameValueType)[0].Value != "7 (0x7)" {
+		t.Errorf("wrong nested array formatting: %s", nvts[1].Pprint(0))
+	}
+}
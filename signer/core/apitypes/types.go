@@ -40,7 +40,10 @@ import (
 	"github.com/holiman/uint256"
 )
 
-var typedDataReferenceTypeRegexp = regexp.MustCompile(`^[A-Za-z](\w*)(\[\])?$`)
+var (
+	typedDataReferenceTypeRegexp = regexp.MustCompile(`^[A-Za-z](\w*)(\[\d*\])*$`)
+	typedDataArraySuffixRegexp   = regexp.MustCompile(`(\[\d*\])+$`)
+)
 
 type ValidationInfo struct {
 	Typ     string `json:"type"`
@@ -326,16 +329,37 @@ type Type struct {
 }
 
 func (t *Type) isArray() bool {
-	return strings.HasSuffix(t.Type, "[]")
+	return strings.HasSuffix(t.Type, "]")
 }
 
-// typeName returns the canonical name of the type. If the type is 'Person[]', then
-// this method returns 'Person'
+// typeName returns the canonical name of the type. If the type is 'Person[]' or
+// 'Person[2][]', then this method returns 'Person'
 func (t *Type) typeName() string {
-	if strings.HasSuffix(t.Type, "[]") {
-		return strings.TrimSuffix(t.Type, "[]")
+	return baseTypeName(t.Type)
+}
+
+// baseTypeName strips all array dimensions from a type.
+func baseTypeName(encType string) string {
+	return typedDataArraySuffixRegexp.ReplaceAllString(encType, "")
+}
+
+// parseArrayType splits the outermost dimension off an array type, returning the
+// type of the elements and the length of the array, or -1 for dynamic arrays. For
+// 'uint256[2][]', the element type is 'uint256[2]'.
+func parseArrayType(encType string) (string, int, error) {
+	open := strings.LastIndexByte(encType, '[')
+	if open < 0 || !strings.HasSuffix(encType, "]") {
+		return "", 0, fmt.Errorf("type '%s' is not an array", encType)
+	}
+	elemType, size := encType[:open], encType[open+1:len(encType)-1]
+	if size == "" {
+		return elemType, -1, nil
 	}
-	return t.Type
+	length, err := strconv.Atoi(size)
+	if err != nil || length <= 0 {
+		return "", 0, fmt.Errorf("invalid array length in type '%s'", encType)
+	}
+	return elemType, length, nil
 }
 
 type Types map[string][]Type
@@ -386,7 +410,7 @@ func (typedData *TypedData) HashStruct(primaryType string, data TypedDataMessage
 
 // Dependencies returns an array of custom types ordered by their hierarchical reference tree
 func (typedData *TypedData) Dependencies(primaryType string, found []string) []string {
-	primaryType = strings.TrimSuffix(primaryType, "[]")
+	primaryType = baseTypeName(primaryType)
 
 	if slices.Contains(found, primaryType) {
 		return found
@@ -463,36 +487,18 @@ func (typedData *TypedData) EncodeData(primaryType string, data map[string]inter
 	for _, field := range typedData.Types[primaryType] {
 		encType := field.Type
 		encValue := data[field.Name]
-		if encType[len(encType)-1:] == "]" {
-			arrayValue, err := convertDataToSlice(encValue)
+		if field.isArray() {
+			arrayHash, err := typedData.encodeArrayValue(encType, encValue, depth)
 			if err != nil {
-				return nil, dataMismatchError(encType, encValue)
-			}
-
-			arrayBuffer := bytes.Buffer{}
-			parsedType := strings.Split(encType, "[")[0]
-			for _, item := range arrayValue {
-				if typedData.Types[parsedType] != nil {
-					mapValue, ok := item.(map[string]interface{})
-					if !ok {
-						return nil, dataMismatchError(parsedType, item)
-					}
-					encodedData, err := typedData.EncodeData(parsedType, mapValue, depth+1)
-					if err != nil {
-						return nil, err
-					}
-					arrayBuffer.Write(crypto.Keccak256(encodedData))
-				} else {
-					bytesValue, err := typedData.EncodePrimitiveValue(parsedType, item, depth)
-					if err != nil {
-						return nil, err
-					}
-					arrayBuffer.Write(bytesValue)
-				}
+				return nil, err
 			}
-
-			buffer.Write(crypto.Keccak256(arrayBuffer.Bytes()))
+			buffer.Write(arrayHash)
 		} else if typedData.Types[field.Type] != nil {
+			// Absent structs are encoded as zero, as done by signTypedData_v4
+			if encValue == nil {
+				buffer.Write(make([]byte, 32))
+				continue
+			}
 			mapValue, ok := encValue.(map[string]interface{})
 			if !ok {
 				return nil, dataMismatchError(encType, encValue)
@@ -513,6 +519,51 @@ func (typedData *TypedData) EncodeData(primaryType string, data map[string]inter
 	return buffer.Bytes(), nil
 }
 
+// encodeArrayValue generates the keccak256 hash of the concatenated encodings of
+// the elements of an array. Elements of nested arrays are hashes of their own
+// elements, elements of struct arrays are struct hashes.
+func (typedData *TypedData) encodeArrayValue(encType string, encValue interface{}, depth int) (hexutil.Bytes, error) {
+	elemType, length, err := parseArrayType(encType)
+	if err != nil {
+		return nil, err
+	}
+	arrayValue, err := convertDataToSlice(encValue)
+	if err != nil {
+		return nil, dataMismatchError(encType, encValue)
+	}
+	if length >= 0 && len(arrayValue) != length {
+		return nil, fmt.Errorf("provided array of %d elements doesn't match type '%s'", len(arrayValue), encType)
+	}
+	arrayBuffer := bytes.Buffer{}
+	for _, item := range arrayValue {
+		switch {
+		case strings.HasSuffix(elemType, "]"):
+			encodedData, err := typedData.encodeArrayValue(elemType, item, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			arrayBuffer.Write(encodedData)
+		case typedData.Types[elemType] != nil:
+			mapValue, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, dataMismatchError(elemType, item)
+			}
+			encodedData, err := typedData.EncodeData(elemType, mapValue, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			arrayBuffer.Write(crypto.Keccak256(encodedData))
+		default:
+			bytesValue, err := typedData.EncodePrimitiveValue(elemType, item, depth)
+			if err != nil {
+				return nil, err
+			}
+			arrayBuffer.Write(bytesValue)
+		}
+	}
+	return crypto.Keccak256(arrayBuffer.Bytes()), nil
+}
+
 // Attempt to parse bytes in different formats: byte array, hex string, hexutil.Bytes.
 func parseBytes(encType interface{}) ([]byte, bool) {
 	// Handle array types.
@@ -742,24 +793,11 @@ func (typedData *TypedData) formatData(primaryType string, data map[string]inter
 			Typ:  field.Type,
 		}
 		if field.isArray() {
-			arrayValue, _ := convertDataToSlice(encValue)
-			parsedType := field.typeName()
-			for _, v := range arrayValue {
-				if typedData.Types[parsedType] != nil {
-					mapValue, _ := v.(map[string]interface{})
-					mapOutput, err := typedData.formatData(parsedType, mapValue)
-					if err != nil {
-						return nil, err
-					}
-					item.Value = mapOutput
-				} else {
-					primitiveOutput, err := formatPrimitiveValue(field.Type, encValue)
-					if err != nil {
-						return nil, err
-					}
-					item.Value = primitiveOutput
-				}
+			arrayOutput, err := typedData.formatArray(field.Type, encValue)
+			if err != nil {
+				return nil, err
 			}
+			item.Value = arrayOutput
 		} else if typedData.Types[field.Type] != nil {
 			if mapValue, ok := encValue.(map[string]interface{}); ok {
 				mapOutput, err := typedData.formatData(field.Type, mapValue)
@@ -782,6 +820,45 @@ func (typedData *TypedData) formatData(primaryType string, data map[string]inter
 	return output, nil
 }
 
+// formatArray formats the elements of an array, named by their index.
+func (typedData *TypedData) formatArray(encType string, encValue interface{}) ([]*NameValueType, error) {
+	elemType, _, err := parseArrayType(encType)
+	if err != nil {
+		return nil, err
+	}
+	arrayValue, _ := convertDataToSlice(encValue)
+	output := make([]*NameValueType, 0, len(arrayValue))
+	for i, v := range arrayValue {
+		item := &NameValueType{
+			Name: fmt.Sprintf("[%d]", i),
+			Typ:  elemType,
+		}
+		switch {
+		case strings.HasSuffix(elemType, "]"):
+			arrayOutput, err := typedData.formatArray(elemType, v)
+			if err != nil {
+				return nil, err
+			}
+			item.Value = arrayOutput
+		case typedData.Types[elemType] != nil:
+			mapValue, _ := v.(map[string]interface{})
+			mapOutput, err := typedData.formatData(elemType, mapValue)
+			if err != nil {
+				return nil, err
+			}
+			item.Value = mapOutput
+		default:
+			primitiveOutput, err := formatPrimitiveValue(elemType, v)
+			if err != nil {
+				return nil, err
+			}
+			item.Value = primitiveOutput
+		}
+		output = append(output, item)
+	}
+	return output, nil
+}
+
 func formatPrimitiveValue(encType string, encValue interface{}) (string, error) {
 	switch encType {
 	case "address":
@@ -843,35 +920,28 @@ func (t Types) validate() error {
 	return nil
 }
 
-// Checks if the primitive value is valid
+// Checks if the primitive value is valid. Arrays of any dimensions of valid
+// primitive types are valid too.
 func isPrimitiveTypeValid(primitiveType string) bool {
+	primitiveType = baseTypeName(primitiveType)
 	if primitiveType == "address" ||
-		primitiveType == "address[]" ||
 		primitiveType == "bool" ||
-		primitiveType == "bool[]" ||
 		primitiveType == "string" ||
-		primitiveType == "string[]" ||
 		primitiveType == "bytes" ||
-		primitiveType == "bytes[]" ||
 		primitiveType == "int" ||
-		primitiveType == "int[]" ||
-		primitiveType == "uint" ||
-		primitiveType == "uint[]" {
+		primitiveType == "uint" {
 		return true
 	}
-	// For 'bytesN', 'bytesN[]', we allow N from 1 to 32
+	// For 'bytesN', we allow N from 1 to 32
 	for n := 1; n <= 32; n++ {
-		// e.g. 'bytes28' or 'bytes28[]'
-		if primitiveType == fmt.Sprintf("bytes%d", n) || primitiveType == fmt.Sprintf("bytes%d[]", n) {
+		// e.g. 'bytes28'
+		if primitiveType == fmt.Sprintf("bytes%d", n) {
 			return true
 		}
 	}
-	// For 'intN','intN[]' and 'uintN','uintN[]' we allow N in increments of 8, from 8 up to 256
+	// For 'intN' and 'uintN' we allow N in increments of 8, from 8 up to 256
 	for n := 8; n <= 256; n += 8 {
-		if primitiveType == fmt.Sprintf("int%d", n) || primitiveType == fmt.Sprintf("int%d[]", n) {
-			return true
-		}
-		if primitiveType == fmt.Sprintf("uint%d", n) || primitiveType == fmt.Sprintf("uint%d[]", n) {
+		if primitiveType == fmt.Sprintf("int%d", n) || primitiveType == fmt.Sprintf("uint%d", n) {
 			return true
 		}
 	}
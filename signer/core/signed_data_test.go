@@ -1014,3 +1014,78 @@ func TestComplexTypedDataWithLowercaseReftype(t *testing.T) {
 		t.Fatalf("Error, got %x, wanted %x", sighash, expSigHash)
 	}
 }
+
+// v4TypedData is the signTypedData_v4 example of eth-sig-util, using arrays of
+// structs holding arrays.
+var v4TypedData = `
+{
+    "types": {
+        "EIP712Domain": [
+            {"name": "name", "type": "string"},
+            {"name": "version", "type": "string"},
+            {"name": "chainId", "type": "uint256"},
+            {"name": "verifyingContract", "type": "address"}
+        ],
+        "Person": [
+            {"name": "name", "type": "string"},
+            {"name": "wallets", "type": "address[]"}
+        ],
+        "Mail": [
+            {"name": "from", "type": "Person"},
+            {"name": "to", "type": "Person[]"},
+            {"name": "contents", "type": "string"}
+        ],
+        "Group": [
+            {"name": "name", "type": "string"},
+            {"name": "members", "type": "Person[]"}
+        ]
+    },
+    "primaryType": "Mail",
+    "domain": {
+        "name": "Ether Mail",
+        "version": "1",
+        "chainId": "1",
+        "verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+    },
+    "message": {
+        "from": {
+            "name": "Cow",
+            "wallets": [
+                "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826",
+                "0xDeaDbeefdEAdbeefdEadbEEFdeadbeEFdEaDbeeF"
+            ]
+        },
+        "to": [
+            {
+                "name": "Bob",
+                "wallets": [
+                    "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB",
+                    "0xB0BdaBea57B0BDABeA57b0bdABEA57b0BDabEa57",
+                    "0xB0B0b0b0b0b0B000000000000000000000000000"
+                ]
+            }
+        ],
+        "contents": "Hello, Bob!"
+    }
+}
+`
+
+func TestTypedDataV4(t *testing.T) {
+	t.Parallel()
+	var td apitypes.TypedData
+	if err := json.Unmarshal([]byte(v4TypedData), &td); err != nil {
+		t.Fatalf("unmarshalling failed '%v'", err)
+	}
+	wantType := "Mail(Person from,Person[] to,string contents)Person(string name,address[] wallets)"
+	if have := string(td.EncodeType("Mail")); have != wantType {
+		t.Errorf("wrong type encoding: have %q, want %q", have, wantType)
+	}
+	_, sighash, err := sign(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expSigHash := common.FromHex("0xa85c2e2b118698e88db68a8105b794a8cc7cec074e89ef991cb4f5f533819cc2")
+	if !bytes.Equal(expSigHash, sighash) {
+		t.Fatalf("Error, got %x, wanted %x", sighash, expSigHash)
+	}
+}
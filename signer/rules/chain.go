@@ -0,0 +1,105 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// chainCallTimeout is the maximum time a rule waits for a node request.
+const chainCallTimeout = 5 * time.Second
+
+// ChainBackend is the node the rules query chain and txpool state from, usually
+// an *rpc.Client connected to it.
+type ChainBackend interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// chainMethod is a read-only node method exposed to the rules.
+type chainMethod struct {
+	name     string        // Name of the function in the js object
+	method   string        // RPC method called
+	defaults []interface{} // Values of the parameters not given by the rule, nil if required
+}
+
+var (
+	// chainMethods are the functions of the js 'chain' object. The block parameter
+	// defaults to "latest", use "pending" to e.g. get the pending nonce.
+	chainMethods = []chainMethod{
+		{name: "blockNumber", method: "eth_blockNumber"},
+		{name: "getBlockByNumber", method: "eth_getBlockByNumber", defaults: []interface{}{"latest", false}},
+		{name: "getBalance", method: "eth_getBalance", defaults: []interface{}{nil, "latest"}},
+		{name: "getTransactionCount", method: "eth_getTransactionCount", defaults: []interface{}{nil, "latest"}},
+		{name: "getCode", method: "eth_getCode", defaults: []interface{}{nil, "latest"}},
+		{name: "call", method: "eth_call", defaults: []interface{}{nil, "latest"}},
+		{name: "gasPrice", method: "eth_gasPrice"},
+		{name: "feeHistory", method: "eth_feeHistory", defaults: []interface{}{nil, "latest", []interface{}{}}},
+	}
+	// txpoolMethods are the functions of the js 'txpool' object.
+	txpoolMethods = []chainMethod{
+		{name: "status", method: "txpool_status"},
+		{name: "content", method: "txpool_content"},
+		{name: "contentFrom", method: "txpool_contentFrom", defaults: []interface{}{nil}},
+	}
+)
+
+// setChainObjects adds the 'chain' and 'txpool' objects, giving read-only access
+// to the state of the node, to the vm.
+func setChainObjects(vm *goja.Runtime, backend ChainBackend) {
+	for name, methods := range map[string][]chainMethod{"chain": chainMethods, "txpool": txpoolMethods} {
+		obj := vm.NewObject()
+		for _, m := range methods {
+			obj.Set(m.name, chainFunction(vm, backend, m))
+		}
+		vm.Set(name, obj)
+	}
+}
+
+// chainFunction creates the js function calling a node method. The result is
+// returned as decoded JSON, errors are thrown as exceptions.
+func chainFunction(vm *goja.Runtime, backend ChainBackend, m chainMethod) func(call goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		args := make([]interface{}, len(m.defaults))
+		for i, def := range m.defaults {
+			arg := call.Argument(i)
+			switch {
+			case !goja.IsUndefined(arg) && !goja.IsNull(arg):
+				args[i] = arg.Export()
+			case def != nil:
+				args[i] = def
+			default:
+				panic(vm.NewTypeError("%s: missing argument %d", m.name, i))
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), chainCallTimeout)
+		defer cancel()
+
+		var result json.RawMessage
+		if err := backend.CallContext(ctx, &result, m.method, args...); err != nil {
+			panic(vm.NewGoError(err))
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(result, &decoded); err != nil {
+			panic(vm.NewGoError(err))
+		}
+		return vm.ToValue(decoded)
+	}
+}
@@ -47,7 +47,8 @@ func consoleOutput(call goja.FunctionCall) goja.Value {
 type rulesetUI struct {
 	next    core.UIClientAPI // The next handler, for manual processing
 	storage storage.Storage
-	jsRules string // The rules to use
+	chain   ChainBackend // Node to query chain and txpool state from, if any
+	jsRules string       // The rules to use
 }
 
 func NewRuleEvaluator(next core.UIClientAPI, jsbackend storage.Storage) (*rulesetUI, error) {
@@ -68,6 +69,13 @@ func (r *rulesetUI) Init(javascriptRules string) error {
 	r.jsRules = javascriptRules
 	return nil
 }
+
+// SetChainBackend gives the rules read-only access to the chain and txpool state
+// of a node, through the 'chain' and 'txpool' objects.
+func (r *rulesetUI) SetChainBackend(backend ChainBackend) {
+	r.chain = backend
+}
+
 func (r *rulesetUI) execute(jsfunc string, jsarg interface{}) (goja.Value, error) {
 	// Instantiate a fresh vm engine every time
 	vm := goja.New()
@@ -95,6 +103,10 @@ func (r *rulesetUI) execute(jsfunc string, jsarg interface{}) (goja.Value, error
 	})
 	vm.Set("storage", storageObj)
 
+	if r.chain != nil {
+		setChainObjects(vm, r.chain)
+	}
+
 	// Load bootstrap libraries
 	script, err := goja.Compile("bignumber.js", deps.BigNumberJS, true)
 	if err != nil {
@@ -17,6 +17,8 @@
 package rules
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"strings"
@@ -624,3 +626,69 @@ function ApproveSignData(r){
 		t.Fatalf("Expected approved")
 	}
 }
+
+// testChain is a ChainBackend answering from canned results.
+type testChain struct {
+	results map[string]string // JSON result by method
+	calls   []string
+}
+
+func (c *testChain) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	c.calls = append(c.calls, fmt.Sprintf("%s%v", method, args))
+	res, ok := c.results[method]
+	if !ok {
+		return fmt.Errorf("method %s not found", method)
+	}
+	return json.Unmarshal([]byte(res), result)
+}
+
+func TestChainContext(t *testing.T) {
+	t.Parallel()
+	js := `
+	function ApproveTx(r){
+		var nonce = chain.getTransactionCount(r.transaction.from, "pending");
+		if (nonce != r.transaction.nonce) { return "Reject" }
+		var pending = txpool.contentFrom(r.transaction.from).pending;
+		if (Object.keys(pending).length > 0) { return "Reject" }
+		if (chain.getCode(r.transaction.to) == "0x") { return "Reject" }
+		return "Approve"
+	}`
+	r, err := initRuleEngine(js)
+	if err != nil {
+		t.Fatalf("Couldn't create evaluator %v", err)
+	}
+	chain := &testChain{results: map[string]string{
+		"eth_getTransactionCount": `"0x5"`,
+		"eth_getCode":             `"0x6001"`,
+		"txpool_contentFrom":      `{"pending": {}, "queued": {}}`,
+	}}
+	r.SetChainBackend(chain)
+
+	from, _ := mixAddr("0x0000000000000000000000000000000000001337")
+	to, _ := mixAddr("0x000000000000000000000000000000000000dead")
+	approve := func(nonce uint64) bool {
+		resp, err := r.ApproveTx(&core.SignTxRequest{
+			Transaction: apitypes.SendTxArgs{From: *from, To: to, Nonce: hexutil.Uint64(nonce)},
+			Meta:        core.Metadata{Remote: "remoteip", Local: "localip", Scheme: "inproc"},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+		return resp.Approved
+	}
+	if !approve(5) {
+		t.Errorf("Expected transaction with the pending nonce to be approved")
+	}
+	if approve(6) {
+		t.Errorf("Expected nonce gap to be rejected")
+	}
+	want := "eth_getTransactionCount[0x0000000000000000000000000000000000001337 pending]"
+	if len(chain.calls) == 0 || chain.calls[0] != want {
+		t.Errorf("Wrong node request: have %v, want %v", chain.calls, want)
+	}
+	// Failing node requests are exceptions, handing the request to the next UI.
+	delete(chain.results, "eth_getCode")
+	if approve(5) {
+		t.Errorf("Expected failing request not to be approved")
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package webhook implements a clef UI forwarding approval requests to a webhook,
+// such as a chat-ops bot or a policy service.
+//
+// Every request is POSTed as a JSON Request, and answered with a JSON Response.
+// Both bodies are authenticated with an HMAC-SHA256 over the body, keyed with a
+// shared secret and sent hex encoded in the SignatureHeader. The response must
+// answer the ID of the request, so that approvals can't be replayed.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/signer/core"
+)
+
+// SignatureHeader is the HTTP header carrying the HMAC of requests and responses.
+const SignatureHeader = "X-Clef-Signature"
+
+// maxResponseSize is the maximum size of a webhook response.
+const maxResponseSize = 64 * 1024
+
+// DefaultTimeout is the time waited for a decision by default.
+const DefaultTimeout = 30 * time.Second
+
+// Request is the body of an approval request.
+type Request struct {
+	ID        string      `json:"id"`        // Random ID, echoed by the response
+	Method    string      `json:"method"`    // Method of the UI, e.g. "ApproveTx"
+	Timestamp int64       `json:"timestamp"` // Unix time of the request
+	Request   interface{} `json:"request"`   // Request of the method, as given to UIs
+}
+
+// Response is the body of the answer to an approval request.
+type Response struct {
+	ID       string `json:"id"`
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason,omitempty"` // Logged if the request is denied
+}
+
+// Sign computes the value of the SignatureHeader for a body.
+func Sign(secret, body []byte) string {
+	return hex.EncodeToString(computeMAC(secret, body))
+}
+
+// Verify reports whether the value of the SignatureHeader is valid for a body.
+func Verify(secret, body []byte, signature string) bool {
+	mac, err := hex.DecodeString(signature)
+	return err == nil && hmac.Equal(mac, computeMAC(secret, body))
+}
+
+func computeMAC(secret, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// Config is the configuration of a webhook.
+type Config struct {
+	URL     string        // Endpoint, must be HTTPS unless on a loopback address
+	Secret  []byte        // Shared secret of the request and response signatures
+	Timeout time.Duration // Time waited for a decision, denied afterwards
+}
+
+// webhookUI is a clef UI asking a webhook for the approval of signing and listing
+// requests. Requests are denied if the webhook can't be reached, answers with an
+// invalid response or times out. Everything else is left to the next UI.
+type webhookUI struct {
+	next   core.UIClientAPI
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// New creates a UI asking the webhook for approvals, using next for the requests
+// the webhook can't handle, such as passwords.
+func New(next core.UIClientAPI, config Config) (core.UIClientAPI, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook URL: %v", err)
+	}
+	switch {
+	case u.Scheme == "https":
+	case u.Scheme == "http" && isLoopback(u.Hostname()):
+	default:
+		return nil, fmt.Errorf("webhook URL %s is not HTTPS", config.URL)
+	}
+	if len(config.Secret) == 0 {
+		return nil, errors.New("no webhook secret")
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &webhookUI{
+		next:   next,
+		url:    config.URL,
+		secret: config.Secret,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// approve asks the webhook for a decision on a request, denying it on failure.
+func (ui *webhookUI) approve(method string, request interface{}) bool {
+	approved, err := ui.call(method, request)
+	if err != nil {
+		log.Warn("Webhook approval failed, denying request", "method", method, "err", err)
+		return false
+	}
+	return approved
+}
+
+func (ui *webhookUI) call(method string, request interface{}) (bool, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return false, err
+	}
+	body, err := json.Marshal(&Request{
+		ID:        hex.EncodeToString(id[:]),
+		Method:    method,
+		Timestamp: time.Now().Unix(),
+		Request:   request,
+	})
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequest(http.MethodPost, ui.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(ui.secret, body))
+
+	resp, err := ui.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("webhook responded with status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return false, err
+	}
+	if !Verify(ui.secret, data, resp.Header.Get(SignatureHeader)) {
+		return false, errors.New("invalid response signature")
+	}
+	var res Response
+	if err := json.Unmarshal(data, &res); err != nil {
+		return false, fmt.Errorf("invalid response: %v", err)
+	}
+	if res.ID != hex.EncodeToString(id[:]) {
+		return false, fmt.Errorf("response to request %q, want %x", res.ID, id)
+	}
+	if !res.Approved {
+		log.Info("Webhook denied request", "method", method, "reason", res.Reason)
+	}
+	return res.Approved, nil
+}
+
+func (ui *webhookUI) ApproveTx(request *core.SignTxRequest) (core.SignTxResponse, error) {
+	if ui.approve("ApproveTx", request) {
+		return core.SignTxResponse{Transaction: request.Transaction, Approved: true}, nil
+	}
+	return core.SignTxResponse{Approved: false}, nil
+}
+
+func (ui *webhookUI) ApproveSignData(request *core.SignDataRequest) (core.SignDataResponse, error) {
+	return core.SignDataResponse{Approved: ui.approve("ApproveSignData", request)}, nil
+}
+
+func (ui *webhookUI) ApproveListing(request *core.ListRequest) (core.ListResponse, error) {
+	if ui.approve("ApproveListing", request) {
+		return core.ListResponse{Accounts: request.Accounts}, nil
+	}
+	return core.ListResponse{}, nil
+}
+
+// ApproveNewAccount is left to the next UI, as it requires setting a password.
+func (ui *webhookUI) ApproveNewAccount(request *core.NewAccountRequest) (core.NewAccountResponse, error) {
+	return ui.next.ApproveNewAccount(request)
+}
+
+// OnInputRequired is left to the next UI, as the webhook can't provide secrets.
+func (ui *webhookUI) OnInputRequired(info core.UserInputRequest) (core.UserInputResponse, error) {
+	return ui.next.OnInputRequired(info)
+}
+
+func (ui *webhookUI) ShowError(message string) {
+	ui.next.ShowError(message)
+}
+
+func (ui *webhookUI) ShowInfo(message string) {
+	ui.next.ShowInfo(message)
+}
+
+func (ui *webhookUI) OnApprovedTx(tx ethapi.SignTransactionResult) {
+	ui.next.OnApprovedTx(tx)
+}
+
+func (ui *webhookUI) OnSignerStartup(info core.StartupInfo) {
+	ui.next.OnSignerStartup(info)
+}
+
+func (ui *webhookUI) RegisterUIServer(api *core.UIServerAPI) {
+	ui.next.RegisterUIServer(api)
+}
@@ -0,0 +1,148 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+var testSecret = []byte("secret")
+
+// testHook is a policy service approving transactions to a single address.
+type testHook struct {
+	allowed  common.Address
+	delay    time.Duration
+	badSig   bool // Sign responses with the wrong secret
+	staleID  bool // Respond to another request
+	requests []Request
+}
+
+func (h *testHook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if !Verify(testSecret, body, r.Header.Get(SignatureHeader)) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	var (
+		req    Request
+		signTx struct {
+			Request core.SignTxRequest `json:"request"`
+		}
+	)
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	json.Unmarshal(body, &signTx)
+	h.requests = append(h.requests, req)
+	time.Sleep(h.delay)
+
+	res := Response{ID: req.ID, Approved: req.Method == "ApproveTx"}
+	if to := signTx.Request.Transaction.To; to == nil || to.Address() != h.allowed {
+		res.Approved, res.Reason = false, "recipient not allowed"
+	}
+	if h.staleID {
+		res.ID = "0000"
+	}
+	data, _ := json.Marshal(res)
+	secret := testSecret
+	if h.badSig {
+		secret = []byte("other")
+	}
+	w.Header().Set(SignatureHeader, Sign(secret, data))
+	w.Write(data)
+}
+
+func newTestUI(t *testing.T, hook *testHook, timeout time.Duration) core.UIClientAPI {
+	server := httptest.NewServer(hook)
+	t.Cleanup(server.Close)
+
+	ui, err := New(nil, Config{URL: server.URL, Secret: testSecret, Timeout: timeout})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ui
+}
+
+func txRequest(to common.Address) *core.SignTxRequest {
+	recipient := common.NewMixedcaseAddress(to)
+	return &core.SignTxRequest{Transaction: apitypes.SendTxArgs{To: &recipient}}
+}
+
+func TestApproval(t *testing.T) {
+	hook := &testHook{allowed: common.Address{1}}
+	ui := newTestUI(t, hook, time.Second)
+
+	if res, err := ui.ApproveTx(txRequest(common.Address{1})); err != nil || !res.Approved {
+		t.Fatalf("transaction not approved: %v", err)
+	}
+	if res, _ := ui.ApproveTx(txRequest(common.Address{2})); res.Approved {
+		t.Fatal("transaction to other recipient approved")
+	}
+	if res, _ := ui.ApproveSignData(&core.SignDataRequest{}); res.Approved {
+		t.Fatal("data signing approved")
+	}
+	if len(hook.requests) != 3 || hook.requests[0].Method != "ApproveTx" || hook.requests[2].Method != "ApproveSignData" {
+		t.Fatalf("wrong requests %+v", hook.requests)
+	}
+	if hook.requests[0].ID == hook.requests[1].ID {
+		t.Fatal("request IDs reused")
+	}
+}
+
+func TestApprovalDeniedOnFailure(t *testing.T) {
+	tests := map[string]*testHook{
+		"timeout":       {delay: 200 * time.Millisecond},
+		"bad signature": {badSig: true},
+		"stale ID":      {staleID: true},
+	}
+	for name, hook := range tests {
+		hook.allowed = common.Address{1}
+		ui := newTestUI(t, hook, 50*time.Millisecond)
+		if res, _ := ui.ApproveTx(txRequest(common.Address{1})); res.Approved {
+			t.Errorf("%s: transaction approved", name)
+		}
+	}
+	// Requests signed with the wrong secret are refused by the webhook
+	server := httptest.NewServer(&testHook{allowed: common.Address{1}})
+	defer server.Close()
+	ui, _ := New(nil, Config{URL: server.URL, Secret: []byte("other")})
+	if res, _ := ui.ApproveTx(txRequest(common.Address{1})); res.Approved {
+		t.Error("transaction approved with wrong secret")
+	}
+}
+
+func TestNewRequiresHTTPS(t *testing.T) {
+	if _, err := New(nil, Config{URL: "http://example.com/approve", Secret: testSecret}); err == nil {
+		t.Fatal("plain HTTP webhook accepted")
+	}
+	if _, err := New(nil, Config{URL: "https://example.com/approve"}); err == nil {
+		t.Fatal("webhook without secret accepted")
+	}
+	if _, err := New(nil, Config{URL: "http://127.0.0.1:8080", Secret: testSecret}); err != nil {
+		t.Fatalf("loopback webhook rejected: %v", err)
+	}
+}